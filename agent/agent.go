@@ -49,6 +49,7 @@ const (
 	StorageAddr      = "STORAGE_ADDR"
 	AgentServiceName = "AGENT_SERVICE_NAME"
 	MongoOplogSize   = "MONGO_OPLOG_SIZE"
+	PasswordChanged  = "PASSWORD_CHANGED"
 )
 
 // The Config interface is the sole way that the agent gets access to the
@@ -150,6 +151,11 @@ type ConfigSetterOnly interface {
 	// SetAPIHostPorts sets the API host/port addresses to connect to.
 	SetAPIHostPorts(servers [][]network.HostPort)
 
+	// SetCACert sets the CA certificate used to validate the state
+	// and API servers. It may hold several PEM-formatted
+	// certificates while the environment's CA is being replaced.
+	SetCACert(caCert string)
+
 	// Migrate takes an existing agent config and applies the given
 	// parameters to change it.
 	//
@@ -450,6 +456,10 @@ func (c *configInternal) SetAPIHostPorts(servers [][]network.HostPort) {
 	c.apiDetails.addresses = addrs
 }
 
+func (c *configInternal) SetCACert(caCert string) {
+	c.caCert = caCert
+}
+
 func (c *configInternal) SetValue(key, value string) {
 	if value == "" {
 		delete(c.values, key)
@@ -535,6 +535,16 @@ func (*suite) TestSetUpgradedToVersion(c *gc.C) {
 	c.Assert(conf.UpgradedToVersion(), gc.Equals, expectVers)
 }
 
+func (*suite) TestSetCACert(c *gc.C) {
+	conf, err := agent.NewAgentConfig(attributeParams)
+	c.Assert(err, gc.IsNil)
+	c.Assert(conf.CACert(), gc.Equals, attributeParams.CACert)
+
+	conf.SetCACert("new ca cert")
+	c.Assert(conf.CACert(), gc.Equals, "new ca cert")
+	c.Assert(conf.APIInfo().CACert, gc.Equals, "new ca cert")
+}
+
 func (*suite) TestSetAPIHostPorts(c *gc.C) {
 	conf, err := agent.NewAgentConfig(attributeParams)
 	c.Assert(err, gc.IsNil)
@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"sort"
 
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
 	agenttools "github.com/juju/juju/agent/tools"
@@ -207,8 +209,27 @@ func (t *ToolsSuite) TestChangeAgentTools(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(*gotTools, gc.Equals, *tools2)
 
-	assertDirNames(c, t.toolsDir(), []string{"1.2.3-foo-bar", "1.2.4-foo-bar", "testagent"})
+	assertDirNames(c, t.toolsDir(), []string{"1.2.3-foo-bar", "1.2.4-foo-bar", "previous-testagent", "testagent"})
 	assertDirNames(c, agenttools.ToolsDir(t.dataDir, "testagent"), []string{"foo", "bar", toolsFile})
+
+	// The previous tools are recorded.
+	previousTools, err := agenttools.PreviousAgentTools(t.dataDir, "testagent")
+	c.Assert(err, gc.IsNil)
+	c.Assert(*previousTools, gc.Equals, *testTools)
+
+	// Switching back records the tools switched from.
+	gotTools, err = agenttools.ChangeAgentTools(t.dataDir, "testagent", testTools.Version)
+	c.Assert(err, gc.IsNil)
+	c.Assert(*gotTools, gc.Equals, *testTools)
+	previousTools, err = agenttools.PreviousAgentTools(t.dataDir, "testagent")
+	c.Assert(err, gc.IsNil)
+	c.Assert(*previousTools, gc.Equals, *tools2)
+}
+
+func (t *ToolsSuite) TestPreviousAgentToolsNotFound(c *gc.C) {
+	_, err := agenttools.PreviousAgentTools(t.dataDir, "testagent")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "previous tools of testagent not found")
 }
 
 func (t *ToolsSuite) TestSharedToolsDir(c *gc.C) {
@@ -168,20 +168,62 @@ func ReadTools(dataDir string, vers version.Binary) (*coretools.Tools, error) {
 
 // ChangeAgentTools atomically replaces the agent-specific symlink
 // under dataDir so it points to the previously unpacked
-// version vers. It returns the new tools read.
+// version vers. It returns the new tools read. The tools the agent
+// used before are recorded, so that it can switch back to them;
+// see PreviousAgentTools.
 func ChangeAgentTools(dataDir string, agentName string, vers version.Binary) (*coretools.Tools, error) {
 	tools, err := ReadTools(dataDir, vers)
 	if err != nil {
 		return nil, err
 	}
+	current, err := os.Readlink(ToolsDir(dataDir, agentName))
+	if err == nil && current != tools.Version.String() {
+		if err := replaceSymlink(dataDir, agentName, previousLinkName(agentName), current); err != nil {
+			return nil, fmt.Errorf("cannot record previous tools: %v", err)
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot read tools symlink: %v", err)
+	}
+	if err := replaceSymlink(dataDir, agentName, agentName, tools.Version.String()); err != nil {
+		return nil, err
+	}
+	return tools, nil
+}
+
+// replaceSymlink atomically replaces the symlink with the given name
+// in the tools directory under dataDir so it points to target.
+func replaceSymlink(dataDir, agentName, name, target string) error {
 	tmpName := ToolsDir(dataDir, "tmplink-"+agentName)
-	err = os.Symlink(tools.Version.String(), tmpName)
+	err := os.Symlink(target, tmpName)
 	if err != nil {
-		return nil, fmt.Errorf("cannot create tools symlink: %v", err)
+		return fmt.Errorf("cannot create tools symlink: %v", err)
 	}
-	err = os.Rename(tmpName, ToolsDir(dataDir, agentName))
+	err = os.Rename(tmpName, ToolsDir(dataDir, name))
 	if err != nil {
-		return nil, fmt.Errorf("cannot update tools symlink: %v", err)
+		return fmt.Errorf("cannot update tools symlink: %v", err)
 	}
-	return tools, nil
+	return nil
+}
+
+func previousLinkName(agentName string) string {
+	return "previous-" + agentName
+}
+
+// PreviousAgentTools returns the tools the given agent used before
+// it last changed its tools with ChangeAgentTools. The tools are kept
+// on disk so that the agent can switch back to them if its upgrade is
+// aborted. It returns an error satisfying errors.IsNotFound if the
+// agent has never changed its tools.
+func PreviousAgentTools(dataDir, agentName string) (*coretools.Tools, error) {
+	target, err := os.Readlink(ToolsDir(dataDir, previousLinkName(agentName)))
+	if os.IsNotExist(err) {
+		return nil, errors.NotFoundf("previous tools of %s", agentName)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read previous tools symlink: %v", err)
+	}
+	vers, err := version.ParseBinary(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous tools symlink: %v", err)
+	}
+	return ReadTools(dataDir, vers)
 }
@@ -38,6 +38,47 @@ func ParseCert(certPEM string) (*x509.Certificate, error) {
 	return nil, errors.New("no certificates found")
 }
 
+// ParseCerts parses all the PEM-formatted X509 certificates in the
+// given data. A bundle of several CA certificates is used to trust
+// both the old and new CA while the CA is being replaced.
+func ParseCerts(certsPEM string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	certPEMData := []byte(certsPEM)
+	for len(certPEMData) > 0 {
+		var certBlock *pem.Block
+		certBlock, certPEMData = pem.Decode(certPEMData)
+		if certBlock == nil {
+			break
+		}
+		if certBlock.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
+// NewCertPool returns a certificate pool holding all the certificates
+// in the given PEM-formatted data.
+func NewCertPool(certsPEM string) (*x509.CertPool, error) {
+	certs, err := ParseCerts(certsPEM)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
 // ParseCertAndKey parses the given PEM-formatted X509 certificate
 // and RSA private key.
 func ParseCertAndKey(certPEM, keyPEM string) (*x509.Certificate, *rsa.PrivateKey, error) {
@@ -59,9 +100,12 @@ func ParseCertAndKey(certPEM, keyPEM string) (*x509.Certificate, *rsa.PrivateKey
 }
 
 // Verify verifies that the given server certificate is valid with
-// respect to the given CA certificate at the given time.
+// respect to the given CA certificate at the given time. The CA
+// certificate may be a bundle of several certificates, in which
+// case the server certificate must be valid with respect to
+// one of them.
 func Verify(srvCertPEM, caCertPEM string, when time.Time) error {
-	caCert, err := ParseCert(caCertPEM)
+	pool, err := NewCertPool(caCertPEM)
 	if err != nil {
 		return errors.Annotate(err, "cannot parse CA certificate")
 	}
@@ -69,8 +113,6 @@ func Verify(srvCertPEM, caCertPEM string, when time.Time) error {
 	if err != nil {
 		return errors.Annotate(err, "cannot parse server certificate")
 	}
-	pool := x509.NewCertPool()
-	pool.AddCert(caCert)
 	opts := x509.VerifyOptions{
 		DNSName:     "anyServer",
 		Roots:       pool,
@@ -186,6 +186,41 @@ func (certSuite) TestVerify(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, "x509: certificate signed by unknown authority")
 }
 
+func (certSuite) TestVerifyBundle(c *gc.C) {
+	now := time.Now()
+	var noHostnames []string
+	caCert, caKey, err := cert.NewCA("foo", now.Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	srvCert, _, err := cert.NewServer(caCert, caKey, now.Add(time.Minute), noHostnames)
+	c.Assert(err, gc.IsNil)
+	caCert2, caKey2, err := cert.NewCA("bar", now.Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	srvCert2, _, err := cert.NewServer(caCert2, caKey2, now.Add(time.Minute), noHostnames)
+	c.Assert(err, gc.IsNil)
+
+	// Server certificates signed by either CA in a bundle are valid.
+	bundle := caCert + caCert2
+	err = cert.Verify(srvCert, bundle, now)
+	c.Assert(err, gc.IsNil)
+	err = cert.Verify(srvCert2, bundle, now)
+	c.Assert(err, gc.IsNil)
+}
+
+func (certSuite) TestParseCerts(c *gc.C) {
+	caCert2, _, err := cert.NewCA("foo", time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+
+	certs, err := cert.ParseCerts(caCertPEM + caKeyPEM + caCert2)
+	c.Assert(err, gc.IsNil)
+	c.Assert(certs, gc.HasLen, 2)
+	c.Assert(certs[0].Subject.CommonName, gc.Equals, "juju testing")
+	c.Assert(certs[1].Subject.CommonName, gc.Equals, `juju-generated CA for environment "foo"`)
+
+	certs, err = cert.ParseCerts(caKeyPEM)
+	c.Check(certs, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "no certificates found")
+}
+
 // checkTLSConnection checks that we can correctly perform a TLS
 // handshake using the given credentials.
 func checkTLSConnection(c *gc.C, caCert, srvCert *x509.Certificate, srvKey *rsa.PrivateKey) (caName string) {
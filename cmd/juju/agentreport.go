@@ -0,0 +1,142 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const agentReportCommandDoc = `
+Show a report on the running agent of a machine or unit: the state of
+each of its workers, including any that are waiting to be restarted
+after failing and the last error each returned, and a summary of the
+agent's configuration.
+
+If --goroutines is given, the stacks of all the agent's goroutines are
+included too.
+
+Examples:
+    juju agent-report 0
+    juju agent-report --goroutines mysql/0
+`
+
+// AgentReportCommand shows a report on a running agent.
+type AgentReportCommand struct {
+	envcmd.EnvCommandBase
+	out        cmd.Output
+	agent      string
+	goroutines bool
+}
+
+// agentReportEntry holds the report on an agent that is shown by
+// "juju agent-report".
+type agentReportEntry struct {
+	Agent      string                 `yaml:"agent" json:"agent"`
+	Version    string                 `yaml:"version" json:"version"`
+	Started    string                 `yaml:"started,omitempty" json:"started,omitempty"`
+	Config     map[string]string      `yaml:"config,omitempty" json:"config,omitempty"`
+	Workers    map[string]workerEntry `yaml:"workers,omitempty" json:"workers,omitempty"`
+	Goroutines string                 `yaml:"goroutines,omitempty" json:"goroutines,omitempty"`
+}
+
+type workerEntry struct {
+	State         string                 `yaml:"state" json:"state"`
+	Started       string                 `yaml:"started,omitempty" json:"started,omitempty"`
+	Restarts      int                    `yaml:"restarts,omitempty" json:"restarts,omitempty"`
+	RestartAt     string                 `yaml:"restart-at,omitempty" json:"restart-at,omitempty"`
+	LastError     string                 `yaml:"last-error,omitempty" json:"last-error,omitempty"`
+	LastErrorTime string                 `yaml:"last-error-time,omitempty" json:"last-error-time,omitempty"`
+	Workers       map[string]workerEntry `yaml:"workers,omitempty" json:"workers,omitempty"`
+}
+
+func (c *AgentReportCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "agent-report",
+		Args:    "<machine|unit>",
+		Purpose: "show a report on a running agent",
+		Doc:     agentReportCommandDoc,
+	}
+}
+
+func (c *AgentReportCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.goroutines, "goroutines", false, "include the stacks of all the agent's goroutines")
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+}
+
+func (c *AgentReportCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no machine or unit specified")
+	}
+	c.agent, args = args[0], args[1:]
+	if !names.IsMachine(c.agent) && !names.IsUnit(c.agent) {
+		return fmt.Errorf("invalid machine or unit %q", c.agent)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+type agentReportAPI interface {
+	AgentReport(agent string, goroutines bool) (params.AgentReport, error)
+	Close() error
+}
+
+var getAgentReportAPI = func(envName string) (agentReportAPI, error) {
+	return juju.NewAPIClientFromName(envName)
+}
+
+func (c *AgentReportCommand) Run(ctx *cmd.Context) error {
+	client, err := getAgentReportAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	report, err := client.AgentReport(c.agent, c.goroutines)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, agentReportEntry{
+		Agent:      report.Agent,
+		Version:    report.Version,
+		Started:    formatReportTime(report.Started),
+		Config:     report.Config,
+		Workers:    workerEntries(report.Workers),
+		Goroutines: report.Goroutines,
+	})
+}
+
+func workerEntries(workers []params.WorkerStatus) map[string]workerEntry {
+	if len(workers) == 0 {
+		return nil
+	}
+	entries := make(map[string]workerEntry)
+	for _, w := range workers {
+		entries[w.Id] = workerEntry{
+			State:         w.State,
+			Started:       formatReportTime(w.Started),
+			Restarts:      w.Restarts,
+			RestartAt:     formatReportTime(w.RestartAt),
+			LastError:     w.LastError,
+			LastErrorTime: formatReportTime(w.LastErrorTime),
+			Workers:       workerEntries(w.Workers),
+		}
+	}
+	return entries
+}
+
+// formatReportTime formats the given time, or returns "" if it is
+// zero.
+func formatReportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
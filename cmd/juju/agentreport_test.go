@@ -0,0 +1,134 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type AgentReportSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockAgentReportAPI
+}
+
+var _ = gc.Suite(&AgentReportSuite{})
+
+func (s *AgentReportSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockAgentReportAPI{}
+	s.PatchValue(&getAgentReportAPI, func(envName string) (agentReportAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *AgentReportSuite) TestAgentReport(c *gc.C) {
+	started := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	s.mockAPI.report = params.AgentReport{
+		Agent:   "machine-1",
+		Version: "1.20.0-trusty-amd64",
+		Started: started,
+		Config:  map[string]string{"data-dir": "/var/lib/juju"},
+		Workers: []params.WorkerStatus{{
+			Id:      "api",
+			State:   "running",
+			Started: started,
+			Workers: []params.WorkerStatus{{
+				Id:            "upgrader",
+				State:         "restarting",
+				Restarts:      2,
+				RestartAt:     started.Add(time.Minute),
+				LastError:     "connection refused",
+				LastErrorTime: started.Add(57 * time.Second),
+			}},
+		}},
+	}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&AgentReportCommand{}), "1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.args, gc.Equals, "1 false")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+agent: machine-1
+version: 1.20.0-trusty-amd64
+started: "2014-07-01T12:00:00Z"
+config:
+  data-dir: /var/lib/juju
+workers:
+  api:
+    state: running
+    started: "2014-07-01T12:00:00Z"
+    workers:
+      upgrader:
+        state: restarting
+        restarts: 2
+        restart-at: "2014-07-01T12:01:00Z"
+        last-error: connection refused
+        last-error-time: "2014-07-01T12:00:57Z"
+`[1:])
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *AgentReportSuite) TestAgentReportGoroutines(c *gc.C) {
+	s.mockAPI.report = params.AgentReport{
+		Agent:      "unit-mysql-0",
+		Version:    "1.20.0-trusty-amd64",
+		Goroutines: "goroutine 1 [running]:\n",
+	}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&AgentReportCommand{}), "--goroutines", "mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.args, gc.Equals, "mysql/0 true")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+agent: unit-mysql-0
+version: 1.20.0-trusty-amd64
+goroutines: |
+  goroutine 1 [running]:
+`[1:])
+}
+
+func (s *AgentReportSuite) TestAgentReportError(c *gc.C) {
+	s.mockAPI.err = fmt.Errorf("cannot get report from machine-1: connection refused")
+	_, err := testing.RunCommand(c, envcmd.Wrap(&AgentReportCommand{}), "1")
+	c.Assert(err, gc.ErrorMatches, "cannot get report from machine-1: connection refused")
+}
+
+func (s *AgentReportSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no machine or unit specified",
+	}, {
+		args: []string{"mysql"},
+		err:  `invalid machine or unit "mysql"`,
+	}, {
+		args: []string{"1", "2"},
+		err:  `unrecognized args: \["2"\]`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := testing.RunCommand(c, envcmd.Wrap(&AgentReportCommand{}), test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+type mockAgentReportAPI struct {
+	report params.AgentReport
+	args   string
+	err    error
+	closed bool
+}
+
+func (m *mockAgentReportAPI) AgentReport(agent string, goroutines bool) (params.AgentReport, error) {
+	m.args = fmt.Sprintf("%s %v", agent, goroutines)
+	return m.report, m.err
+}
+
+func (m *mockAgentReportAPI) Close() error {
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const costCommandDoc = `
+Show the instance type and estimated hourly cost, in US dollars, of
+each machine in the environment, the share of that cost attributed to
+each service, and the total for the environment.
+
+Costs are estimated from the provider's list prices for instance types,
+and are only available for providers that have them (currently ec2 and
+azure). On joyent, the package of each machine is shown, but Joyent does
+not publish package prices, so costs are unknown. The cost of a machine
+is divided equally between the principal units on it and in its
+containers. Machines whose cost is not known are shown with a cost of
+"unknown" and are left out of the totals.
+`
+
+// CostCommand shows the estimated cost of the environment.
+type CostCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+}
+
+// costEntry holds the information about the environment's cost that
+// is shown by "juju cost".
+type costEntry struct {
+	Machines   map[string]machineCostEntry `yaml:"machines,omitempty" json:"machines,omitempty"`
+	Services   map[string]serviceCostEntry `yaml:"services,omitempty" json:"services,omitempty"`
+	HourlyCost string                      `yaml:"hourly-cost" json:"hourly-cost"`
+}
+
+type machineCostEntry struct {
+	InstanceId   string   `yaml:"instance-id" json:"instance-id"`
+	InstanceType string   `yaml:"instance-type,omitempty" json:"instance-type,omitempty"`
+	Hardware     string   `yaml:"hardware,omitempty" json:"hardware,omitempty"`
+	HourlyCost   string   `yaml:"hourly-cost" json:"hourly-cost"`
+	Units        []string `yaml:"units,omitempty" json:"units,omitempty"`
+}
+
+type serviceCostEntry struct {
+	HourlyCost string `yaml:"hourly-cost" json:"hourly-cost"`
+}
+
+func (c *CostCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "cost",
+		Purpose: "show the estimated cost of the environment",
+		Doc:     costCommandDoc,
+	}
+}
+
+func (c *CostCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+}
+
+func (c *CostCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+type costAPI interface {
+	EnvironmentCost() (params.EnvironmentCost, error)
+	Close() error
+}
+
+var getCostAPI = func(envName string) (costAPI, error) {
+	return juju.NewAPIClientFromName(envName)
+}
+
+func (c *CostCommand) Run(ctx *cmd.Context) error {
+	client, err := getCostAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	cost, err := client.EnvironmentCost()
+	if err != nil {
+		return err
+	}
+	result := costEntry{
+		HourlyCost: formatCost(cost.HourlyCost),
+	}
+	for _, m := range cost.Machines {
+		if result.Machines == nil {
+			result.Machines = make(map[string]machineCostEntry)
+		}
+		entry := machineCostEntry{
+			InstanceId:   string(m.InstanceId),
+			InstanceType: m.InstanceType,
+			Hardware:     m.Hardware,
+			HourlyCost:   "unknown",
+			Units:        m.Units,
+		}
+		if m.HourlyCost > 0 {
+			entry.HourlyCost = formatCost(m.HourlyCost)
+		}
+		result.Machines[m.Machine] = entry
+	}
+	for _, s := range cost.Services {
+		if result.Services == nil {
+			result.Services = make(map[string]serviceCostEntry)
+		}
+		result.Services[s.Service] = serviceCostEntry{
+			HourlyCost: formatCost(s.HourlyCost),
+		}
+	}
+	return c.out.Write(ctx, result)
+}
+
+// formatCost formats a cost in dollars to the nearest tenth of a cent.
+func formatCost(cost float64) string {
+	return fmt.Sprintf("%.3f", cost)
+}
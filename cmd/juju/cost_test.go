@@ -0,0 +1,105 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type CostSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockCostAPI
+}
+
+var _ = gc.Suite(&CostSuite{})
+
+func (s *CostSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockCostAPI{}
+	s.PatchValue(&getCostAPI, func(envName string) (costAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *CostSuite) TestCost(c *gc.C) {
+	s.mockAPI.cost = params.EnvironmentCost{
+		Machines: []params.MachineCost{{
+			Machine:      "1",
+			InstanceId:   "i-1",
+			InstanceType: "m1.large",
+			Hardware:     "arch=amd64 cpu-cores=2 mem=7680M",
+			HourlyCost:   0.24,
+			Units:        []string{"mysql/0", "wordpress/0"},
+		}, {
+			Machine:    "2",
+			InstanceId: "i-2",
+		}},
+		Services: []params.ServiceCost{
+			{Service: "mysql", HourlyCost: 0.12},
+			{Service: "wordpress", HourlyCost: 0.12},
+		},
+		HourlyCost: 0.24,
+	}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&CostCommand{}))
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+machines:
+  "1":
+    instance-id: i-1
+    instance-type: m1.large
+    hardware: arch=amd64 cpu-cores=2 mem=7680M
+    hourly-cost: "0.240"
+    units:
+    - mysql/0
+    - wordpress/0
+  "2":
+    instance-id: i-2
+    hourly-cost: unknown
+services:
+  mysql:
+    hourly-cost: "0.120"
+  wordpress:
+    hourly-cost: "0.120"
+hourly-cost: "0.240"
+`[1:])
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *CostSuite) TestCostJSON(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&CostCommand{}), "--format", "json")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `{"hourly-cost":"0.000"}`+"\n")
+}
+
+func (s *CostSuite) TestCostError(c *gc.C) {
+	s.mockAPI.err = fmt.Errorf("cost reporting for provider \"local\" not supported")
+	_, err := testing.RunCommand(c, envcmd.Wrap(&CostCommand{}))
+	c.Assert(err, gc.ErrorMatches, `cost reporting for provider "local" not supported`)
+}
+
+func (s *CostSuite) TestInitErrors(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&CostCommand{}), "extra")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+type mockCostAPI struct {
+	cost   params.EnvironmentCost
+	err    error
+	closed bool
+}
+
+func (m *mockCostAPI) EnvironmentCost() (params.EnvironmentCost, error) {
+	return m.cost, m.err
+}
+
+func (m *mockCostAPI) Close() error {
+	m.closed = true
+	return nil
+}
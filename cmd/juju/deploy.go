@@ -6,7 +6,9 @@ package main
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/juju/charm"
@@ -31,6 +33,8 @@ type DeployCommand struct {
 	Config       cmd.FileVar
 	Constraints  constraints.Value
 	Networks     string
+	Bindings     string
+	Storage      map[string]params.StorageDirective
 	BumpRevision bool   // Remove this once the 1.16 support is dropped.
 	RepoPath     string // defaults to JUJU_REPOSITORY
 }
@@ -103,6 +107,31 @@ to configure on it. The --networks argument instructs juju to add all the
 networks specified with it to all new machines deployed to host units of
 the service. Not supported on all providers.
 
+Relation endpoints of the service can be bound to networks with the --bind
+argument, which takes a comma-delimited list of endpoint=network pairs. The
+unit's address on the bound network is then used as its private-address in
+that relation, and the network-get hook tool reports the binding. Bound
+networks are added to the service's networks, as if given with --networks.
+
+   juju deploy mysql --bind server=dbnet
+   (bind mysql's "server" endpoint to the "dbnet" network)
+
+Each unit of the service can be given durable block storage volumes with
+the --storage argument, which takes a name=pool,size triple and may be
+repeated. Volumes are created in the named storage pool by the provider
+and attached to the unit's machine as unformatted block devices; the
+storage-get hook tool reports the device of each volume. Volumes are
+detached, but not destroyed, when their unit is removed. Sizes take the
+same suffixes as constraints (M, G, T, P). The only pool is "loop",
+supported by the local provider with lxc containers. No other provider
+can create volumes yet: there is no support for EBS, Cinder or any other
+cloud block storage, charms cannot declare the storage they need, and
+volumes are never formatted or mounted. A pool that the environment's
+provider does not support is rejected when the service is deployed.
+
+   juju deploy mysql --storage data=loop,10G
+   (give each mysql unit a 10GiB volume named "data")
+
 See Also:
    juju help constraints
    juju help set-constraints
@@ -126,6 +155,8 @@ func (c *DeployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(&c.Config, "config", "path to yaml-formatted service config")
 	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "constraints", "set service constraints")
 	f.StringVar(&c.Networks, "networks", "", "bind the service to specific networks")
+	f.StringVar(&c.Bindings, "bind", "", "bind relation endpoints to networks")
+	f.Var(storageFlag{&c.Storage}, "storage", "require a block storage volume for each unit")
 	f.StringVar(&c.RepoPath, "repository", os.Getenv(osenv.JujuRepositoryEnvKey), "local charm repository")
 }
 
@@ -201,6 +232,10 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 	haveNetworks := len(requestedNetworks) > 0 || c.Constraints.HaveNetworks()
+	bindings, err := parseBindings(c.Bindings)
+	if err != nil {
+		return err
+	}
 
 	charmInfo, err := client.CharmInfo(curl.String())
 	if err != nil {
@@ -230,6 +265,39 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 			return err
 		}
 	}
+	if len(c.Storage) > 0 {
+		err = client.ServiceDeployWithStorage(
+			curl.String(),
+			serviceName,
+			numUnits,
+			string(configYAML),
+			c.Constraints,
+			c.ToMachineSpec,
+			requestedNetworks,
+			bindings,
+			c.Storage,
+		)
+		if params.IsCodeNotImplemented(err) {
+			return errors.New("cannot use --storage: not supported by the API server")
+		}
+		return err
+	}
+	if len(bindings) > 0 {
+		err = client.ServiceDeployWithBindings(
+			curl.String(),
+			serviceName,
+			numUnits,
+			string(configYAML),
+			c.Constraints,
+			c.ToMachineSpec,
+			requestedNetworks,
+			bindings,
+		)
+		if params.IsCodeNotImplemented(err) {
+			return errors.New("cannot use --bind: not supported by the API server")
+		}
+		return err
+	}
 	err = client.ServiceDeployWithNetworks(
 		curl.String(),
 		serviceName,
@@ -302,6 +370,99 @@ func parseNetworks(networksValue string) []string {
 	return networks
 }
 
+// parseBindings returns a map of relation endpoint names to network
+// tags by parsing the comma-delimited endpoint=network pairs of the
+// --bind argument.
+func parseBindings(bindingsValue string) (map[string]string, error) {
+	bindings := make(map[string]string)
+	for _, part := range strings.Split(bindingsValue, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf(`invalid binding %q: expected "endpoint=network"`, part)
+		}
+		tags, err := networkNamesToTags([]string{kv[1]})
+		if err != nil {
+			return nil, err
+		}
+		bindings[kv[0]] = tags[0]
+	}
+	return bindings, nil
+}
+
+// storageFlag is a gnuflag.Value that records the storage directives
+// given with repeated --storage arguments.
+type storageFlag struct {
+	directives *map[string]params.StorageDirective
+}
+
+// Set implements gnuflag.Value.Set.
+func (f storageFlag) Set(value string) error {
+	name, directive, err := parseStorageDirective(value)
+	if err != nil {
+		return err
+	}
+	if *f.directives == nil {
+		*f.directives = make(map[string]params.StorageDirective)
+	}
+	if _, ok := (*f.directives)[name]; ok {
+		return fmt.Errorf("storage %q specified more than once", name)
+	}
+	(*f.directives)[name] = directive
+	return nil
+}
+
+// String implements gnuflag.Value.String.
+func (f storageFlag) String() string {
+	var values []string
+	for name, directive := range *f.directives {
+		values = append(values, fmt.Sprintf("%s=%s,%dM", name, directive.Pool, directive.Size))
+	}
+	return strings.Join(values, " ")
+}
+
+// storageSizeSuffixes holds the multipliers, relative to MiB, of the
+// suffixes allowed for storage sizes.
+var storageSizeSuffixes = map[string]float64{
+	"M": 1,
+	"G": 1024,
+	"T": 1024 * 1024,
+	"P": 1024 * 1024 * 1024,
+}
+
+// parseStorageDirective parses a --storage argument of the form
+// name=pool,size.
+func parseStorageDirective(value string) (string, params.StorageDirective, error) {
+	invalid := func() (string, params.StorageDirective, error) {
+		return "", params.StorageDirective{}, fmt.Errorf(`invalid storage %q: expected "name=pool,size"`, value)
+	}
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return invalid()
+	}
+	fields := strings.Split(kv[1], ",")
+	if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+		return invalid()
+	}
+	size := fields[1]
+	mult := 1.0
+	if m, ok := storageSizeSuffixes[size[len(size)-1:]]; ok {
+		size = size[:len(size)-1]
+		mult = m
+	}
+	val, err := strconv.ParseFloat(size, 64)
+	if err != nil || val <= 0 {
+		return "", params.StorageDirective{}, fmt.Errorf("invalid storage %q: size must be a positive number with an optional M, G, T or P suffix", value)
+	}
+	return kv[0], params.StorageDirective{
+		Pool: fields[0],
+		Size: uint64(math.Ceil(val * mult)),
+	}, nil
+}
+
 // networkNamesToTags returns the given network names converted to
 // tags, or an error.
 func networkNamesToTags(networks []string) ([]string, error) {
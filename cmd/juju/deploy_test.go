@@ -185,6 +185,68 @@ func (s *DeploySuite) TestNetworks(c *gc.C) {
 	c.Assert(cons, jc.DeepEquals, constraints.MustParse("mem=2G cpu-cores=2 networks=net1,net0,^net3,^net4"))
 }
 
+func (s *DeploySuite) TestBindings(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "mysql")
+	err := runDeploy(c, "local:mysql", "--networks", "net1", "--bind", "server=dbnet")
+	c.Assert(err, gc.IsNil)
+	curl := charm.MustParseURL("local:trusty/mysql-1")
+	service, _ := s.AssertService(c, "mysql", curl, 1, 0)
+	networks, err := service.Networks()
+	c.Assert(err, gc.IsNil)
+	c.Assert(networks, jc.DeepEquals, []string{"net1", "dbnet"})
+	c.Assert(service.EndpointBindings(), jc.DeepEquals, map[string]string{"server": "dbnet"})
+}
+
+func (s *DeploySuite) TestBindingsErrors(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "mysql")
+	err := runDeploy(c, "local:mysql", "--bind", "server")
+	c.Assert(err, gc.ErrorMatches, `invalid binding "server": expected "endpoint=network"`)
+	err = runDeploy(c, "local:mysql", "--bind", "server=$bad")
+	c.Assert(err, gc.ErrorMatches, `"\$bad" is not a valid network name`)
+	err = runDeploy(c, "local:mysql", "--bind", "nonsense=dbnet")
+	c.Assert(err, gc.ErrorMatches, `cannot bind endpoint "nonsense": charm "local:trusty/mysql-1" has no such relation`)
+	_, err = s.State.Service("mysql")
+	c.Assert(err, gc.ErrorMatches, `service "mysql" not found`)
+}
+
+func (s *DeploySuite) TestStorage(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "mysql")
+	err := runDeploy(c, "local:mysql", "--storage", "data=loop,10G", "--storage", "logs=loop,512")
+	c.Assert(err, gc.IsNil)
+	curl := charm.MustParseURL("local:trusty/mysql-1")
+	service, _ := s.AssertService(c, "mysql", curl, 1, 0)
+	c.Assert(service.StorageDirectives(), jc.DeepEquals, map[string]state.StorageDirective{
+		"data": {Pool: "loop", Size: 10240},
+		"logs": {Pool: "loop", Size: 512},
+	})
+}
+
+func (s *DeploySuite) TestStorageErrors(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "mysql")
+	for _, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--storage", "data"},
+		err:  `invalid value "data" for flag --storage: invalid storage "data": expected "name=pool,size"`,
+	}, {
+		args: []string{"--storage", "data=loop"},
+		err:  `invalid value "data=loop" for flag --storage: invalid storage "data=loop": expected "name=pool,size"`,
+	}, {
+		args: []string{"--storage", "data=loop,big"},
+		err:  `invalid value "data=loop,big" for flag --storage: invalid storage "data=loop,big": size must be a positive number with an optional M, G, T or P suffix`,
+	}, {
+		args: []string{"--storage", "data=loop,1G", "--storage", "data=loop,2G"},
+		err:  `invalid value "data=loop,2G" for flag --storage: storage "data" specified more than once`,
+	}, {
+		args: []string{"--storage", "Data=loop,1G"},
+		err:  `cannot set storage for service "mysql": invalid storage name "Data"`,
+	}} {
+		err := runDeploy(c, append([]string{"local:mysql"}, test.args...)...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
 func (s *DeploySuite) TestSubordinateConstraints(c *gc.C) {
 	charmtesting.Charms.BundlePath(s.SeriesPath, "logging")
 	err := runDeploy(c, "local:logging", "--constraints", "mem=1G")
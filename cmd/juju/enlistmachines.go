@@ -0,0 +1,110 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/manual"
+)
+
+const enlistMachinesDoc = `
+enlist-machines manually provisions a number of existing hosts over SSH,
+as "juju add-machine ssh:[user@]host" does for a single host. The hosts
+are read from a file, one [user@]host per line; blank lines and lines
+starting with "#" are ignored.
+
+The series and hardware of each host are detected separately, and
+several hosts are provisioned at once. If a password is needed to set
+up a host, you are prompted for each host in turn.
+
+A host that fails to be provisioned does not stop the others; the
+command reports each host's outcome and fails if any host failed.
+
+Removing a manually provisioned machine with "juju remove-machine"
+uninstalls the machine agent, its upstart jobs, /var/lib/juju and juju's
+rsyslog configuration from the host.
+
+Example:
+   juju enlist-machines --parallel 10 hosts.txt
+
+See Also:
+   juju help add-machine
+   juju help remove-machine
+`
+
+// EnlistMachinesCommand manually provisions a list of existing hosts.
+type EnlistMachinesCommand struct {
+	envcmd.EnvCommandBase
+	HostsFile   string
+	Parallelism int
+}
+
+func (c *EnlistMachinesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "enlist-machines",
+		Args:    "<hosts file>",
+		Purpose: "manually provision the existing hosts listed in a file",
+		Doc:     enlistMachinesDoc,
+	}
+}
+
+func (c *EnlistMachinesCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.IntVar(&c.Parallelism, "parallel", manual.DefaultParallelism, "the number of hosts to provision at once")
+}
+
+func (c *EnlistMachinesCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no hosts file specified")
+	}
+	if c.Parallelism < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	c.HostsFile = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+// provisionMachines is a variable so that tests can intercept it.
+var provisionMachines = manual.ProvisionMachines
+
+func (c *EnlistMachinesCommand) Run(ctx *cmd.Context) error {
+	f, err := os.Open(ctx.AbsPath(c.HostsFile))
+	if err != nil {
+		return err
+	}
+	hosts, err := manual.ParseHosts(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("cannot read hosts file: %v", err)
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no hosts found in %s", c.HostsFile)
+	}
+	results := provisionMachines(manual.ProvisionMachinesArgs{
+		Hosts:       hosts,
+		Parallelism: c.Parallelism,
+		EnvName:     c.EnvName,
+		Stdin:       ctx.Stdin,
+		Stdout:      ctx.Stdout,
+		Stderr:      ctx.Stderr,
+	})
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			ctx.Infof("%s: failed: %v", result.Host, result.Error)
+			failed++
+			continue
+		}
+		ctx.Infof("%s: created machine %v", result.Host, result.MachineId)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d hosts failed to be provisioned", failed, len(results))
+	}
+	return nil
+}
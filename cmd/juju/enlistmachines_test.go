@@ -0,0 +1,70 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/testing"
+)
+
+type EnlistMachinesSuite struct {
+	testing.FakeJujuHomeSuite
+}
+
+var _ = gc.Suite(&EnlistMachinesSuite{})
+
+func runEnlistMachines(c *gc.C, args ...string) (*cmd.Context, error) {
+	return testing.RunCommand(c, envcmd.Wrap(&EnlistMachinesCommand{}), args...)
+}
+
+func (s *EnlistMachinesSuite) writeHosts(c *gc.C, content string) string {
+	path := filepath.Join(c.MkDir(), "hosts")
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, gc.IsNil)
+	return path
+}
+
+func (s *EnlistMachinesSuite) TestInit(c *gc.C) {
+	_, err := runEnlistMachines(c)
+	c.Assert(err, gc.ErrorMatches, "no hosts file specified")
+	_, err = runEnlistMachines(c, "--parallel", "0", "hosts")
+	c.Assert(err, gc.ErrorMatches, "--parallel must be at least 1")
+	_, err = runEnlistMachines(c, "hosts", "extra")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *EnlistMachinesSuite) TestEnlistMachines(c *gc.C) {
+	var called manual.ProvisionMachinesArgs
+	s.PatchValue(&provisionMachines, func(args manual.ProvisionMachinesArgs) []manual.ProvisionResult {
+		called = args
+		return []manual.ProvisionResult{
+			{Host: "host1", MachineId: "1"},
+			{Host: "ubuntu@host2", Error: fmt.Errorf("no route to host")},
+		}
+	})
+	path := s.writeHosts(c, "# hosts\nhost1\nubuntu@host2\n")
+	context, err := runEnlistMachines(c, "--parallel", "3", path)
+	c.Assert(err, gc.ErrorMatches, "1 of 2 hosts failed to be provisioned")
+	c.Assert(called.Hosts, gc.DeepEquals, []string{"host1", "ubuntu@host2"})
+	c.Assert(called.Parallelism, gc.Equals, 3)
+	c.Assert(called.EnvName, gc.Equals, "erewhemos")
+	c.Assert(testing.Stderr(context), gc.Equals, ""+
+		"host1: created machine 1\n"+
+		"ubuntu@host2: failed: no route to host\n",
+	)
+}
+
+func (s *EnlistMachinesSuite) TestEnlistMachinesEmptyFile(c *gc.C) {
+	path := s.writeHosts(c, "# nothing here\n")
+	_, err := runEnlistMachines(c, path)
+	c.Assert(err, gc.ErrorMatches, "no hosts found in .*")
+}
@@ -28,6 +28,9 @@ type EnsureAvailabilityCommand struct {
 	// If specified, these constraints will be merged with those
 	// already in the environment when creating new machines.
 	Constraints constraints.Value
+	// Remove holds the ids of state server machines to remove.
+	Remove []string
+	remove string
 }
 
 const ensureAvailabilityDoc = `
@@ -37,6 +40,13 @@ to ensure that the specified number of state servers are made available.
 
 An odd number of state servers is required.
 
+State servers are removed when a lower number is specified, and those
+given with --remove are replaced. A state server that is removed loses
+its vote once another state server can take it, then it stops being a
+state server and its machine is destroyed. The state servers that are
+removed first are those that have not yet been given a vote, then the
+most recently added ones.
+
 Examples:
  juju ensure-availability
      Ensure that the system is still in highly available mode. If
@@ -50,6 +60,10 @@ Examples:
      Ensure that 7 state servers are available, with newly created
      state server machines having the default series, and at least
      8GB RAM.
+ juju ensure-availability -n 3
+     When there are 5 state servers, remove 2 of them.
+ juju ensure-availability --remove 2
+     Replace the state server on machine 2 with a new one.
 `
 
 // formatSimple marshals value to a yaml-formatted []byte, unless value is nil.
@@ -110,6 +124,7 @@ func (c *EnsureAvailabilityCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.NumStateServers, "n", 0, "number of state servers to make available")
 	f.StringVar(&c.Series, "series", "", "the charm series")
 	f.Var(constraints.ConstraintsValue{&c.Constraints}, "constraints", "additional machine constraints")
+	f.StringVar(&c.remove, "remove", "", "comma-separated ids of state server machines to remove")
 	c.out.AddFlags(f, "simple", map[string]cmd.Formatter{
 		"yaml":   cmd.FormatYaml,
 		"json":   cmd.FormatJson,
@@ -122,6 +137,14 @@ func (c *EnsureAvailabilityCommand) Init(args []string) error {
 	if c.NumStateServers < 0 || (c.NumStateServers%2 != 1 && c.NumStateServers != 0) {
 		return fmt.Errorf("must specify a number of state servers odd and non-negative")
 	}
+	if c.remove != "" {
+		for _, id := range strings.Split(c.remove, ",") {
+			if !names.IsMachine(id) {
+				return fmt.Errorf("invalid machine id %q", id)
+			}
+			c.Remove = append(c.Remove, id)
+		}
+	}
 	return cmd.CheckEmpty(args)
 }
 
@@ -141,7 +164,7 @@ func (c *EnsureAvailabilityCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 	defer client.Close()
-	ensureAvailabilityResult, err := client.EnsureAvailability(c.NumStateServers, c.Constraints, c.Series)
+	ensureAvailabilityResult, err := client.EnsureAvailability(c.NumStateServers, c.Constraints, c.Series, c.Remove...)
 	if err != nil {
 		return err
 	}
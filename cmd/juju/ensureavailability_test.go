@@ -196,8 +196,46 @@ adding machines: 1, 2
 
 `)
 
-	_, err = runEnsureAvailability(c, "-n", "1")
-	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: cannot reduce state server count")
+	_, err = runEnsureAvailability(c, "--remove", "1,foo")
+	c.Assert(err, gc.ErrorMatches, `invalid machine id "foo"`)
+	_, err = runEnsureAvailability(c, "--remove", "42")
+	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: machine 42 is not a state server")
+}
+
+func (s *EnsureAvailabilitySuite) TestEnsureAvailabilityReducesCount(c *gc.C) {
+	_, err := runEnsureAvailability(c, "-n", "3")
+	c.Assert(err, gc.IsNil)
+
+	// Machines 1 and 2 are not available, so they are demoted.
+	ctx, err := runEnsureAvailability(c, "-n", "1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals,
+		`maintaining machines: 0
+demoting machines 1, 2
+
+`)
+	info, err := s.State.StateServerInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.VotingMachineIds, gc.DeepEquals, []string{"0"})
+}
+
+func (s *EnsureAvailabilitySuite) TestEnsureAvailabilityRemove(c *gc.C) {
+	_, err := runEnsureAvailability(c, "-n", "3")
+	c.Assert(err, gc.IsNil)
+
+	ctx, err := runEnsureAvailability(c, "--remove", "1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals,
+		`maintaining machines: 0
+adding machines: 3, 4
+removing machines 1
+demoting machines 2
+
+`)
+	m, err := s.State.Machine("1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.IsManager(), jc.IsFalse)
+	c.Assert(m.Life(), gc.Equals, state.Dying)
 }
 
 func (s *EnsureAvailabilitySuite) TestEnsureAvailabilityAllows0(c *gc.C) {
@@ -97,6 +97,17 @@ full containers and not overlays by specifying the following in the provider
 configuration:
   lxc-clone-aufs: false
 
+LXC containers are limited to the memory and CPU given by their mem,
+cpu-cores and cpu-power constraints. To stop machines being
+oversubscribed, you can limit the total memory and cpu cores allocated to
+the containers on each machine to a multiple of the machine's own with:
+  container-overcommit-ratio: 1.5
+Requests for containers beyond that limit are refused, and the machine is
+left in an error state explaining why. Docker containers are checked in the
+same way. A container without mem or cpu-cores constraints is charged 512M
+of memory and 1 cpu core. The resources charged to each container are shown
+as its hardware in juju status.
+
 
 References:
 
@@ -119,6 +130,20 @@ Here's an example OpenStack configuration:
     # Openstack security group assigned.
     # use-default-secgroup: false
 
+    # The pool that floating IP addresses are allocated from, when
+    # use-floating-ip is true. The default pool is used if omitted.
+    # floating-ip-pool: <pool name>
+
+    # The network label(s) or UUID(s) to attach new machines to, as a
+    # comma-separated list. The first network holds the primary interface.
+    # network: <network label or uuid>
+
+    # Existing security groups to start new machines in, as a
+    # comma-separated list. Juju then creates no security groups and does
+    # not open or close ports, so the groups must already allow SSH, the
+    # API and state ports, and the ports your services need.
+    # security-groups: <group names>
+
     # Usually set via the env variable OS_AUTH_URL, but can be specified here
     # auth-url: https://yourkeystoneurl:443/v2.0/
 
@@ -321,8 +346,9 @@ cpu-power
 
 tags
    Tags defines the list of tags that the machine must have applied to it.
-   Multiple tags must be delimited by a comma. Tags are currently only supported
-   by the MaaS environment.
+   Multiple tags must be delimited by a comma. A tag with a "^" prefix is one
+   the machine must not have. Tags are currently only supported by the MaaS
+   environment. Example: tags=ssd,^gpu
 
 networks
    Networks defines the list of networks to ensure are available or not on the
@@ -333,6 +359,14 @@ networks
    network. Positive network constraints do not imply the networks will be enabled,
    use the --networks argument for that, just that they could be enabled.
 
+spot-price
+   Spot-price is a decimal number that defines the maximum hourly price, in US
+   dollars, to bid for a spot instance. If it is set, a spot instance is
+   requested instead of an on-demand instance. Spot instances may be terminated
+   by the cloud at any time; a machine whose instance is interrupted is marked
+   as being in error. Spot-price is currently only supported by the Amazon EC2
+   environment. Example: spot-price=0.05
+
 Example:
 
    juju add-machine --constraints "arch=amd64 mem=8G tags=foo,bar"
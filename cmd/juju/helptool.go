@@ -10,6 +10,7 @@ import (
 	"github.com/juju/cmd"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/worker/uniter/jujuc"
 )
 
@@ -52,6 +53,10 @@ func (dummyHookContext) OwnerTag() string {
 	return ""
 }
 
+func (dummyHookContext) StorageVolumes() ([]params.UnitVolume, error) {
+	return nil, nil
+}
+
 type HelpToolCommand struct {
 	cmd.CommandBase
 	tool string
@@ -91,6 +91,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	// Creation commands.
 	r.Register(wrapEnvCommand(&BootstrapCommand{}))
 	r.Register(wrapEnvCommand(&AddMachineCommand{}))
+	r.Register(wrapEnvCommand(&EnlistMachinesCommand{}))
 	r.Register(wrapEnvCommand(&DeployCommand{}))
 	r.Register(wrapEnvCommand(&AddRelationCommand{}))
 	r.Register(wrapEnvCommand(&AddUnitCommand{}))
@@ -106,6 +107,8 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(wrapEnvCommand(&StatusCommand{}))
 	r.Register(&SwitchCommand{})
 	r.Register(wrapEnvCommand(&EndpointCommand{}))
+	r.Register(wrapEnvCommand(&CostCommand{}))
+	r.Register(wrapEnvCommand(&AgentReportCommand{}))
 
 	// Error resolution and debugging commands.
 	r.Register(wrapEnvCommand(&RunCommand{}))
@@ -145,8 +148,18 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	// Disable for 1.20 release
 	// r.Register(NewUserCommand())
 
+	// Manage scheduled commands.
+	r.Register(NewScheduleCommand())
+
 	// Manage state server availability.
 	r.Register(wrapEnvCommand(&EnsureAvailabilityCommand{}))
+	r.Register(wrapEnvCommand(&StateServersCommand{}))
+
+	// Manage the environment's certificates.
+	r.Register(wrapEnvCommand(&RotateCertsCommand{}))
+
+	// Manage agent credentials.
+	r.Register(wrapEnvCommand(&RotateAgentPasswordsCommand{}))
 }
 
 // envCmdWrapper is a struct that wraps an environment command and lets us handle
@@ -190,10 +190,12 @@ var commandNames = []string{
 	"add-machine",
 	"add-relation",
 	"add-unit",
+	"agent-report",
 	"api-endpoints",
 	"authorised-keys", // alias for authorized-keys
 	"authorized-keys",
 	"bootstrap",
+	"cost",
 	"debug-hooks",
 	"debug-log",
 	"deploy",
@@ -202,6 +204,7 @@ var commandNames = []string{
 	"destroy-relation",
 	"destroy-service",
 	"destroy-unit",
+	"enlist-machines",
 	"ensure-availability",
 	"env", // alias for switch
 	"expose",
@@ -220,7 +223,10 @@ var commandNames = []string{
 	"remove-unit",     // alias for destroy-unit
 	"resolved",
 	"retry-provisioning",
+	"rotate-agent-passwords",
+	"rotate-certs",
 	"run",
+	"schedule",
 	"scp",
 	"set",
 	"set-constraints",
@@ -228,6 +234,7 @@ var commandNames = []string{
 	"set-environment",
 	"ssh",
 	"stat", // alias for status
+	"state-servers",
 	"status",
 	"switch",
 	"sync-tools",
@@ -27,6 +27,10 @@ running units or containers can only be removed with the --force flag; doing
 so will also remove all those units and containers without giving them any
 opportunity to shut down cleanly.
 
+When a manually provisioned machine is removed, its machine agent uninstalls
+itself: jujud, the upstart jobs of the machine and any remaining unit agents,
+/var/lib/juju and juju's rsyslog configuration are removed from the host.
+
 Examples:
 	# Remove machine number 5 which has no running units or containers
 	$ juju remove-machine 5
@@ -0,0 +1,80 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+)
+
+const rotateAgentPasswordsDoc = `
+Ask the agents of the given machines and units to change their
+passwords, as when a machine may have been compromised. The agents of
+the units and containers on a given machine are asked too.
+
+Each agent generates a new password and records it in its
+configuration before setting it, so an agent interrupted while changing
+its password falls back to its old one. Agents also change their
+passwords every 30 days of their own accord.
+
+The agents asked are printed, one per line.
+`
+
+// RotateAgentPasswordsCommand asks agents to change their passwords.
+type RotateAgentPasswordsCommand struct {
+	envcmd.EnvCommandBase
+	Agents []string
+}
+
+func (c *RotateAgentPasswordsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "rotate-agent-passwords",
+		Args:    "<machine>|<unit> ...",
+		Purpose: "change the passwords of machine and unit agents",
+		Doc:     rotateAgentPasswordsDoc,
+	}
+}
+
+func (c *RotateAgentPasswordsCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no machines or units specified")
+	}
+	for _, arg := range args {
+		if !names.IsMachine(arg) && !names.IsUnit(arg) {
+			return fmt.Errorf("invalid machine or unit %q", arg)
+		}
+	}
+	c.Agents = args
+	return nil
+}
+
+type rotateAgentPasswordsAPI interface {
+	RotateAgentPasswords(agents ...string) ([]string, error)
+	Close() error
+}
+
+var getRotateAgentPasswordsAPI = func(envName string) (rotateAgentPasswordsAPI, error) {
+	return juju.NewAPIClientFromName(envName)
+}
+
+func (c *RotateAgentPasswordsCommand) Run(ctx *cmd.Context) error {
+	client, err := getRotateAgentPasswordsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	agents, err := client.RotateAgentPasswords(c.Agents...)
+	if err != nil {
+		return err
+	}
+	for _, agent := range agents {
+		fmt.Fprintln(ctx.Stdout, agent)
+	}
+	return nil
+}
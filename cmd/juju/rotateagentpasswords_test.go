@@ -0,0 +1,67 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type RotateAgentPasswordsSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockRotateAgentPasswordsAPI
+}
+
+var _ = gc.Suite(&RotateAgentPasswordsSuite{})
+
+func (s *RotateAgentPasswordsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockRotateAgentPasswordsAPI{}
+	s.PatchValue(&getRotateAgentPasswordsAPI, func(envName string) (rotateAgentPasswordsAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *RotateAgentPasswordsSuite) TestRotateAgentPasswords(c *gc.C) {
+	s.mockAPI.result = []string{"machine-1", "unit-wordpress-0", "unit-mysql-0"}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&RotateAgentPasswordsCommand{}), "1", "mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.agents, jc.DeepEquals, []string{"1", "mysql/0"})
+	c.Assert(testing.Stdout(ctx), gc.Equals, "machine-1\nunit-wordpress-0\nunit-mysql-0\n")
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *RotateAgentPasswordsSuite) TestRotateAgentPasswordsError(c *gc.C) {
+	s.mockAPI.err = &params.Error{Message: "machine 1 not found", Code: params.CodeNotFound}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&RotateAgentPasswordsCommand{}), "1")
+	c.Assert(err, gc.ErrorMatches, "machine 1 not found")
+}
+
+func (s *RotateAgentPasswordsSuite) TestInitErrors(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&RotateAgentPasswordsCommand{}))
+	c.Assert(err, gc.ErrorMatches, "no machines or units specified")
+	_, err = testing.RunCommand(c, envcmd.Wrap(&RotateAgentPasswordsCommand{}), "1", "foo")
+	c.Assert(err, gc.ErrorMatches, `invalid machine or unit "foo"`)
+}
+
+type mockRotateAgentPasswordsAPI struct {
+	agents []string
+	result []string
+	err    error
+	closed bool
+}
+
+func (m *mockRotateAgentPasswordsAPI) RotateAgentPasswords(agents ...string) ([]string, error) {
+	m.agents = agents
+	return m.result, m.err
+}
+
+func (m *mockRotateAgentPasswordsAPI) Close() error {
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const rotateCertsCommandDoc = `
+Replace the certificates used to secure connections to the state
+servers before they expire.
+
+With no options, a new state server certificate is signed with the
+environment's CA, whose private key is held in the environment's .jenv
+file, and given to all the state servers, which restart to use it.
+
+Replacing the CA itself takes two steps, so that agents keep trusting
+the state servers throughout:
+
+    juju rotate-certs --new-ca
+
+generates a new CA and has all agents trust both the old and the new
+CA. The new CA's certificate and private key are recorded in the
+.jenv file. Once every agent has picked up the new CA (see
+"juju agent-report"),
+
+    juju rotate-certs --finish
+
+gives the state servers a certificate signed by the new CA and has
+agents stop trusting the old one.
+
+"juju status" warns when any of the certificates is close to expiry.
+`
+
+// certValidity holds how long the certificates generated by
+// rotate-certs are valid for.
+var certValidity = 10 * 365 * 24 * time.Hour
+
+// RotateCertsCommand replaces the environment's CA and state server
+// certificates.
+type RotateCertsCommand struct {
+	envcmd.EnvCommandBase
+	newCA  bool
+	finish bool
+}
+
+func (c *RotateCertsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "rotate-certs",
+		Purpose: "replace the environment's certificates",
+		Doc:     rotateCertsCommandDoc,
+	}
+}
+
+func (c *RotateCertsCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.newCA, "new-ca", false, "start replacing the environment's CA")
+	f.BoolVar(&c.finish, "finish", false, "finish replacing the environment's CA")
+}
+
+func (c *RotateCertsCommand) Init(args []string) error {
+	if c.newCA && c.finish {
+		return fmt.Errorf("cannot specify both --new-ca and --finish")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+type rotateCertsAPI interface {
+	RotateCertificates(args params.RotateCertificatesArgs) error
+	Close() error
+}
+
+var getRotateCertsAPI = func(envName string) (rotateCertsAPI, error) {
+	return juju.NewAPIClientFromName(envName)
+}
+
+func (c *RotateCertsCommand) Run(ctx *cmd.Context) error {
+	store, err := configstore.Default()
+	if err != nil {
+		return errors.Annotate(err, "cannot open environment info storage")
+	}
+	info, err := store.ReadInfo(c.EnvName)
+	if err != nil {
+		return err
+	}
+	cfg, _, err := environs.ConfigForName(c.EnvName, store)
+	if err != nil {
+		return err
+	}
+	caCert, _ := cfg.CACert()
+	caKey, ok := cfg.CAPrivateKey()
+	if !ok {
+		return fmt.Errorf("environment %q has no CA private key", c.EnvName)
+	}
+	client, err := getRotateCertsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	expiry := time.Now().UTC().Add(certValidity)
+	endpoint := info.APIEndpoint()
+	if c.newCA {
+		newCACert, newCAKey, err := cert.NewCA(cfg.Name(), expiry)
+		if err != nil {
+			return errors.Annotate(err, "cannot generate CA certificate")
+		}
+		bundle := endpoint.CACert + newCACert
+		if err := client.RotateCertificates(params.RotateCertificatesArgs{CACert: bundle}); err != nil {
+			return err
+		}
+		info.UpdateBootstrapConfig(map[string]interface{}{
+			"ca-cert":        newCACert,
+			"ca-private-key": newCAKey,
+		})
+		endpoint.CACert = bundle
+		info.SetAPIEndpoint(endpoint)
+		if err := info.Write(); err != nil {
+			return errors.Annotate(err, "cannot save new CA")
+		}
+		fmt.Fprintf(ctx.Stderr, "agents now trust the new CA; run juju rotate-certs --finish once they have all picked it up\n")
+		return nil
+	}
+
+	var noHostnames []string
+	srvCert, srvKey, err := cert.NewServer(caCert, caKey, expiry, noHostnames)
+	if err != nil {
+		return errors.Annotate(err, "cannot generate state server certificate")
+	}
+	args := params.RotateCertificatesArgs{
+		Cert:       srvCert,
+		PrivateKey: srvKey,
+	}
+	if c.finish {
+		args.CACert = caCert
+	}
+	if err := client.RotateCertificates(args); err != nil {
+		return err
+	}
+	if c.finish {
+		endpoint.CACert = caCert
+		info.SetAPIEndpoint(endpoint)
+		if err := info.Write(); err != nil {
+			return errors.Annotate(err, "cannot save CA certificate")
+		}
+	}
+	fmt.Fprintf(ctx.Stderr, "state servers will restart with the new certificate\n")
+	return nil
+}
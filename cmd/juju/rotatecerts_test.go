@@ -0,0 +1,126 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type RotateCertsSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockRotateCertsAPI
+}
+
+var _ = gc.Suite(&RotateCertsSuite{})
+
+func (s *RotateCertsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockRotateCertsAPI{}
+	s.PatchValue(&getRotateCertsAPI, func(envName string) (rotateCertsAPI, error) {
+		return s.mockAPI, nil
+	})
+	store, err := configstore.Default()
+	c.Assert(err, gc.IsNil)
+	info, err := store.CreateInfo("erewhemos")
+	c.Assert(err, gc.IsNil)
+	info.SetBootstrapConfig(dummy.SampleConfig().Merge(testing.Attrs{"name": "erewhemos"}))
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses: []string{"localhost:12345"},
+		CACert:    testing.CACert,
+	})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *RotateCertsSuite) readInfo(c *gc.C) configstore.EnvironInfo {
+	store, err := configstore.Default()
+	c.Assert(err, gc.IsNil)
+	info, err := store.ReadInfo("erewhemos")
+	c.Assert(err, gc.IsNil)
+	return info
+}
+
+func (s *RotateCertsSuite) TestRotateStateServerCert(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&RotateCertsCommand{}))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.args, gc.HasLen, 1)
+	args := s.mockAPI.args[0]
+	c.Assert(args.CACert, gc.Equals, "")
+	err = cert.Verify(args.Cert, testing.CACert, time.Now())
+	c.Assert(err, gc.IsNil)
+	_, _, err = cert.ParseCertAndKey(args.Cert, args.PrivateKey)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *RotateCertsSuite) TestRotateCA(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&RotateCertsCommand{}), "--new-ca")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.args, gc.HasLen, 1)
+	args := s.mockAPI.args[0]
+	c.Assert(args.Cert, gc.Equals, "")
+	caCerts, err := cert.ParseCerts(args.CACert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(caCerts, gc.HasLen, 2)
+
+	// The new CA is recorded, and both CAs are trusted
+	// when connecting to the API.
+	info := s.readInfo(c)
+	c.Assert(info.APIEndpoint().CACert, gc.Equals, args.CACert)
+	newCACert := info.BootstrapConfig()["ca-cert"].(string)
+	c.Assert(args.CACert, gc.Equals, testing.CACert+newCACert)
+
+	_, err = testing.RunCommand(c, envcmd.Wrap(&RotateCertsCommand{}), "--finish")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.args, gc.HasLen, 2)
+	args = s.mockAPI.args[1]
+	c.Assert(args.CACert, gc.Equals, newCACert)
+	err = cert.Verify(args.Cert, newCACert, time.Now())
+	c.Assert(err, gc.IsNil)
+	info = s.readInfo(c)
+	c.Assert(info.APIEndpoint().CACert, gc.Equals, newCACert)
+}
+
+func (s *RotateCertsSuite) TestRotateCAError(c *gc.C) {
+	s.mockAPI.err = &params.Error{Message: "state server certificate not valid"}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&RotateCertsCommand{}), "--new-ca")
+	c.Assert(err, gc.ErrorMatches, "state server certificate not valid")
+
+	// Nothing is recorded if the API call fails.
+	info := s.readInfo(c)
+	c.Assert(info.APIEndpoint().CACert, gc.Equals, testing.CACert)
+	c.Assert(info.BootstrapConfig()["ca-cert"], gc.Equals, testing.CACert)
+}
+
+func (s *RotateCertsSuite) TestInitErrors(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&RotateCertsCommand{}), "--new-ca", "--finish")
+	c.Assert(err, gc.ErrorMatches, "cannot specify both --new-ca and --finish")
+	_, err = testing.RunCommand(c, envcmd.Wrap(&RotateCertsCommand{}), "foo")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
+
+type mockRotateCertsAPI struct {
+	args   []params.RotateCertificatesArgs
+	err    error
+	closed bool
+}
+
+func (m *mockRotateCertsAPI) RotateCertificates(args params.RotateCertificatesArgs) error {
+	m.args = append(m.args, args)
+	return m.err
+}
+
+func (m *mockRotateCertsAPI) Close() error {
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+type ScheduleCommand struct {
+	*cmd.SuperCommand
+}
+
+const scheduleCommandDoc = `
+"juju schedule" is used to manage commands that are run periodically on
+units, in a hook context, at times given by a cron-style specification.
+`
+
+const scheduleCommandPurpose = "manage scheduled commands on units"
+
+func NewScheduleCommand() cmd.Command {
+	schedulecmd := &ScheduleCommand{
+		SuperCommand: cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:        "schedule",
+			Doc:         scheduleCommandDoc,
+			UsagePrefix: "juju",
+			Purpose:     scheduleCommandPurpose,
+		}),
+	}
+	// Define each subcommand in a separate "schedule_FOO.go" source
+	// file and wire in here.
+	schedulecmd.Register(envcmd.Wrap(&ScheduleAddCommand{}))
+	schedulecmd.Register(envcmd.Wrap(&ScheduleListCommand{}))
+	schedulecmd.Register(envcmd.Wrap(&SchedulePauseCommand{}))
+	schedulecmd.Register(envcmd.Wrap(&ScheduleResumeCommand{}))
+	schedulecmd.Register(envcmd.Wrap(&ScheduleRemoveCommand{}))
+	return schedulecmd
+}
+
+type scheduleAPI interface {
+	AddSchedule(target, spec, command string) (string, error)
+	ListSchedules() ([]params.ScheduleInfo, error)
+	PauseSchedule(id string) error
+	ResumeSchedule(id string) error
+	RemoveSchedule(id string) error
+	Close() error
+}
+
+var getScheduleAPI = func(envName string) (scheduleAPI, error) {
+	return juju.NewAPIClientFromName(envName)
+}
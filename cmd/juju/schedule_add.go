@@ -0,0 +1,85 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/utils/cron"
+)
+
+const scheduleAddCommandDoc = `
+Add a command to be run periodically on a unit, or on every unit of a
+service. The command is run by the unit agent in a hook context, so hook
+tools such as config-get and juju-log may be used; the result of the
+most recent run on each unit is shown by "juju schedule list".
+
+The schedule is given in the five-field format used by cron (minute,
+hour, day of month, month, day of week), or as one of @yearly,
+@monthly, @weekly, @daily or @hourly. Times are in UTC.
+
+Only shell commands can be scheduled. Charm actions cannot: although
+actions can be queued for a unit, nothing runs them yet, so scheduling
+one would have no effect.
+
+Examples:
+  juju schedule add mysql "0 3 * * *" /var/lib/juju/backup.sh
+  juju schedule add wordpress/0 @hourly "logrotate -f /etc/logrotate.conf"
+`
+
+// ScheduleAddCommand adds a scheduled command.
+type ScheduleAddCommand struct {
+	envcmd.EnvCommandBase
+	Target  string
+	Spec    string
+	Command string
+}
+
+func (c *ScheduleAddCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add",
+		Args:    "<unit | service> <schedule> <command>",
+		Purpose: "run a command periodically on a unit or service",
+		Doc:     scheduleAddCommandDoc,
+	}
+}
+
+func (c *ScheduleAddCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("no unit or service specified")
+	case 1:
+		return fmt.Errorf("no schedule specified")
+	case 2:
+		return fmt.Errorf("no command specified")
+	}
+	c.Target, c.Spec = args[0], args[1]
+	c.Command = strings.Join(args[2:], " ")
+	if !names.IsUnit(c.Target) && !names.IsService(c.Target) {
+		return fmt.Errorf("invalid unit or service name %q", c.Target)
+	}
+	if _, err := cron.Parse(c.Spec); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ScheduleAddCommand) Run(ctx *cmd.Context) error {
+	client, err := getScheduleAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	id, err := client.AddSchedule(c.Target, c.Spec, c.Command)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "added schedule %s\n", id)
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const scheduleListCommandDoc = `
+List the scheduled commands in the environment, along with the result
+of the most recent run of each on every unit.
+`
+
+// ScheduleListCommand lists the scheduled commands.
+type ScheduleListCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+}
+
+// scheduleListEntry holds the information about a schedule that is
+// shown by "juju schedule list".
+type scheduleListEntry struct {
+	Target   string                      `yaml:"target" json:"target"`
+	Schedule string                      `yaml:"schedule" json:"schedule"`
+	Command  string                      `yaml:"command" json:"command"`
+	Paused   bool                        `yaml:"paused,omitempty" json:"paused,omitempty"`
+	LastRuns map[string]scheduleRunEntry `yaml:"last-runs,omitempty" json:"last-runs,omitempty"`
+}
+
+// scheduleRunEntry holds the result of running a scheduled command on
+// a unit.
+type scheduleRunEntry struct {
+	Time   string `yaml:"time" json:"time"`
+	Code   int    `yaml:"code" json:"code"`
+	Output string `yaml:"output,omitempty" json:"output,omitempty"`
+	Error  string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+func (c *ScheduleListCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list",
+		Purpose: "list scheduled commands",
+		Doc:     scheduleListCommandDoc,
+	}
+}
+
+func (c *ScheduleListCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+}
+
+func (c *ScheduleListCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ScheduleListCommand) Run(ctx *cmd.Context) error {
+	client, err := getScheduleAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	schedules, err := client.ListSchedules()
+	if err != nil {
+		return err
+	}
+	result := make(map[string]scheduleListEntry)
+	for _, schedule := range schedules {
+		entry := scheduleListEntry{
+			Target:   schedule.Target,
+			Schedule: schedule.Spec,
+			Command:  schedule.Command,
+			Paused:   schedule.Paused,
+		}
+		for _, run := range schedule.Runs {
+			if entry.LastRuns == nil {
+				entry.LastRuns = make(map[string]scheduleRunEntry)
+			}
+			entry.LastRuns[run.Unit] = scheduleRunEntry{
+				Time:   run.Time.UTC().Format(time.RFC3339),
+				Code:   run.Code,
+				Output: run.Output,
+				Error:  run.Error,
+			}
+		}
+		result[schedule.Id] = entry
+	}
+	return c.out.Write(ctx, result)
+}
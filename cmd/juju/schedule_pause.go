@@ -0,0 +1,89 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const schedulePauseCommandDoc = `
+Stop a scheduled command being run until the schedule is resumed with
+"juju schedule resume". The schedule id is shown by "juju schedule list".
+`
+
+// SchedulePauseCommand pauses a scheduled command.
+type SchedulePauseCommand struct {
+	envcmd.EnvCommandBase
+	Id string
+}
+
+func (c *SchedulePauseCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "pause",
+		Args:    "<schedule id>",
+		Purpose: "stop a scheduled command being run",
+		Doc:     schedulePauseCommandDoc,
+	}
+}
+
+func (c *SchedulePauseCommand) Init(args []string) (err error) {
+	c.Id, err = scheduleIdArg(args)
+	return err
+}
+
+func (c *SchedulePauseCommand) Run(_ *cmd.Context) error {
+	client, err := getScheduleAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.PauseSchedule(c.Id)
+}
+
+const scheduleResumeCommandDoc = `
+Resume a scheduled command paused with "juju schedule pause". The
+command is next run at the first scheduled time after it is resumed.
+`
+
+// ScheduleResumeCommand resumes a paused scheduled command.
+type ScheduleResumeCommand struct {
+	envcmd.EnvCommandBase
+	Id string
+}
+
+func (c *ScheduleResumeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "resume",
+		Args:    "<schedule id>",
+		Purpose: "resume a paused scheduled command",
+		Doc:     scheduleResumeCommandDoc,
+	}
+}
+
+func (c *ScheduleResumeCommand) Init(args []string) (err error) {
+	c.Id, err = scheduleIdArg(args)
+	return err
+}
+
+func (c *ScheduleResumeCommand) Run(_ *cmd.Context) error {
+	client, err := getScheduleAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.ResumeSchedule(c.Id)
+}
+
+// scheduleIdArg returns the schedule id held in args, which must
+// contain nothing else.
+func scheduleIdArg(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("no schedule id specified")
+	}
+	return args[0], cmd.CheckEmpty(args[1:])
+}
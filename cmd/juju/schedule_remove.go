@@ -0,0 +1,44 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const scheduleRemoveCommandDoc = `
+Remove a scheduled command, along with the record of its runs. The
+schedule id is shown by "juju schedule list".
+`
+
+// ScheduleRemoveCommand removes a scheduled command.
+type ScheduleRemoveCommand struct {
+	envcmd.EnvCommandBase
+	Id string
+}
+
+func (c *ScheduleRemoveCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "remove",
+		Args:    "<schedule id>",
+		Purpose: "remove a scheduled command",
+		Doc:     scheduleRemoveCommandDoc,
+	}
+}
+
+func (c *ScheduleRemoveCommand) Init(args []string) (err error) {
+	c.Id, err = scheduleIdArg(args)
+	return err
+}
+
+func (c *ScheduleRemoveCommand) Run(_ *cmd.Context) error {
+	client, err := getScheduleAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.RemoveSchedule(c.Id)
+}
@@ -0,0 +1,196 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	goyaml "gopkg.in/yaml.v1"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+// All of the functionality of the schedule api calls is contained
+// elsewhere. This suite provides basic tests for the "schedule"
+// subcommands.
+type ScheduleCommandSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockScheduleAPI
+}
+
+var _ = gc.Suite(&ScheduleCommandSuite{})
+
+func (s *ScheduleCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockScheduleAPI{}
+	s.PatchValue(&getScheduleAPI, func(envName string) (scheduleAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *ScheduleCommandSuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, NewScheduleCommand(), "--help")
+	c.Assert(err, gc.IsNil)
+	out := testing.Stdout(ctx)
+	for _, name := range []string{"add", "list", "pause", "remove", "resume"} {
+		c.Check(out, gc.Matches, fmt.Sprintf("(?s).*\n    %s +- .*", name))
+	}
+}
+
+func (s *ScheduleCommandSuite) TestAdd(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&ScheduleAddCommand{}),
+		"mysql", "0 3 * * *", "backup", "--all")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.calls, jc.DeepEquals, []string{"AddSchedule mysql|0 3 * * *|backup --all", "Close"})
+	c.Assert(testing.Stdout(ctx), gc.Equals, "added schedule 7\n")
+}
+
+func (s *ScheduleCommandSuite) TestAddError(c *gc.C) {
+	s.mockAPI.err = fmt.Errorf("kaboom")
+	_, err := testing.RunCommand(c, envcmd.Wrap(&ScheduleAddCommand{}), "mysql/0", "@hourly", "ls")
+	c.Assert(err, gc.ErrorMatches, "kaboom")
+}
+
+var scheduleAddInitErrorTests = []struct {
+	args []string
+	err  string
+}{{
+	err: "no unit or service specified",
+}, {
+	args: []string{"mysql"},
+	err:  "no schedule specified",
+}, {
+	args: []string{"mysql", "@hourly"},
+	err:  "no command specified",
+}, {
+	args: []string{"machine-0", "@hourly", "ls"},
+	err:  `invalid unit or service name "machine-0"`,
+}, {
+	args: []string{"mysql", "* * *", "ls"},
+	err:  `invalid schedule "\* \* \*": expected 5 fields, got 3`,
+}}
+
+func (s *ScheduleCommandSuite) TestAddInitErrors(c *gc.C) {
+	for i, test := range scheduleAddInitErrorTests {
+		c.Logf("test %d: %q", i, test.args)
+		err := testing.InitCommand(&ScheduleAddCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ScheduleCommandSuite) TestList(c *gc.C) {
+	s.mockAPI.schedules = []params.ScheduleInfo{{
+		Id:      "1",
+		Target:  "mysql",
+		Spec:    "@daily",
+		Command: "backup",
+		Runs: []params.ScheduleRunInfo{{
+			Unit:   "mysql/0",
+			Time:   time.Date(2014, 7, 1, 3, 0, 0, 0, time.UTC),
+			Code:   1,
+			Output: "disk full",
+		}},
+	}, {
+		Id:      "2",
+		Target:  "wordpress/0",
+		Spec:    "*/5 * * * *",
+		Command: "ls",
+		Paused:  true,
+	}}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&ScheduleListCommand{}))
+	c.Assert(err, gc.IsNil)
+	var result map[string]scheduleListEntry
+	err = goyaml.Unmarshal(ctx.Stdout.(*bytes.Buffer).Bytes(), &result)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, jc.DeepEquals, map[string]scheduleListEntry{
+		"1": {
+			Target:   "mysql",
+			Schedule: "@daily",
+			Command:  "backup",
+			LastRuns: map[string]scheduleRunEntry{
+				"mysql/0": {
+					Time:   "2014-07-01T03:00:00Z",
+					Code:   1,
+					Output: "disk full",
+				},
+			},
+		},
+		"2": {
+			Target:   "wordpress/0",
+			Schedule: "*/5 * * * *",
+			Command:  "ls",
+			Paused:   true,
+		},
+	})
+}
+
+func (s *ScheduleCommandSuite) TestPauseResumeRemove(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&SchedulePauseCommand{}), "3")
+	c.Assert(err, gc.IsNil)
+	_, err = testing.RunCommand(c, envcmd.Wrap(&ScheduleResumeCommand{}), "3")
+	c.Assert(err, gc.IsNil)
+	_, err = testing.RunCommand(c, envcmd.Wrap(&ScheduleRemoveCommand{}), "3")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.calls, jc.DeepEquals, []string{
+		"PauseSchedule 3", "Close",
+		"ResumeSchedule 3", "Close",
+		"RemoveSchedule 3", "Close",
+	})
+}
+
+func (s *ScheduleCommandSuite) TestIdInitErrors(c *gc.C) {
+	for _, command := range []cmd.Command{
+		&SchedulePauseCommand{},
+		&ScheduleResumeCommand{},
+		&ScheduleRemoveCommand{},
+	} {
+		err := testing.InitCommand(command, nil)
+		c.Check(err, gc.ErrorMatches, "no schedule id specified")
+		err = testing.InitCommand(command, []string{"1", "2"})
+		c.Check(err, gc.ErrorMatches, `unrecognized args: \["2"\]`)
+	}
+}
+
+type mockScheduleAPI struct {
+	calls     []string
+	schedules []params.ScheduleInfo
+	err       error
+}
+
+func (m *mockScheduleAPI) AddSchedule(target, spec, command string) (string, error) {
+	m.calls = append(m.calls, fmt.Sprintf("AddSchedule %s|%s|%s", target, spec, command))
+	return "7", m.err
+}
+
+func (m *mockScheduleAPI) ListSchedules() ([]params.ScheduleInfo, error) {
+	m.calls = append(m.calls, "ListSchedules")
+	return m.schedules, m.err
+}
+
+func (m *mockScheduleAPI) PauseSchedule(id string) error {
+	m.calls = append(m.calls, "PauseSchedule "+id)
+	return m.err
+}
+
+func (m *mockScheduleAPI) ResumeSchedule(id string) error {
+	m.calls = append(m.calls, "ResumeSchedule "+id)
+	return m.err
+}
+
+func (m *mockScheduleAPI) RemoveSchedule(id string) error {
+	m.calls = append(m.calls, "RemoveSchedule "+id)
+	return m.err
+}
+
+func (m *mockScheduleAPI) Close() error {
+	m.calls = append(m.calls, "Close")
+	return nil
+}
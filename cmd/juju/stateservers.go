@@ -0,0 +1,167 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const stateServersCommandDoc = `
+Show the state of each state server's member of the mongo replica set
+that holds the environment's state, and the most recent decision made
+by the peer grouper, the worker that maintains the replica set, with
+its reasons.
+
+For each member, the mongo state (PRIMARY, SECONDARY, ...), its health,
+whether it votes and how far it lags behind the primary are shown.
+Members of the replica set with no state server machine are shown
+without a machine id.
+
+Two operations on the replica set are supported:
+
+    juju state-servers --step-down
+
+asks the primary to step down, so that another state server is elected
+primary; the old primary does not stand for election for a minute.
+
+    juju state-servers --demote <machine>
+
+removes the vote of the given state server. The peer grouper removes
+the vote once another state server is ready to take it, so that the
+number of votes stays odd; until then, its decision explains why the
+vote has not been removed.
+`
+
+// StateServersCommand shows the state of the state servers' replica
+// set, and steps down its primary or demotes a state server.
+type StateServersCommand struct {
+	envcmd.EnvCommandBase
+	out      cmd.Output
+	stepDown bool
+	demote   string
+}
+
+// stateServersEntry holds the information about the state servers
+// that is shown by "juju state-servers".
+type stateServersEntry struct {
+	Members  []stateServerMemberEntry `yaml:"members" json:"members"`
+	Decision *peerGroupDecisionEntry  `yaml:"last-decision,omitempty" json:"last-decision,omitempty"`
+}
+
+type stateServerMemberEntry struct {
+	Machine   string `yaml:"machine,omitempty" json:"machine,omitempty"`
+	Address   string `yaml:"address,omitempty" json:"address,omitempty"`
+	State     string `yaml:"mongo-state,omitempty" json:"mongo-state,omitempty"`
+	Healthy   bool   `yaml:"healthy" json:"healthy"`
+	Message   string `yaml:"message,omitempty" json:"message,omitempty"`
+	Voting    bool   `yaml:"voting" json:"voting"`
+	WantsVote bool   `yaml:"wants-vote" json:"wants-vote"`
+	HasVote   bool   `yaml:"has-vote" json:"has-vote"`
+	OptimeLag string `yaml:"optime-lag,omitempty" json:"optime-lag,omitempty"`
+}
+
+type peerGroupDecisionEntry struct {
+	Time    string   `yaml:"time" json:"time"`
+	Changed bool     `yaml:"changed-replica-set" json:"changed-replica-set"`
+	Reasons []string `yaml:"reasons,omitempty" json:"reasons,omitempty"`
+	Error   string   `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+func (c *StateServersCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "state-servers",
+		Purpose: "show and manage the state servers' replica set",
+		Doc:     stateServersCommandDoc,
+	}
+}
+
+func (c *StateServersCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+	f.BoolVar(&c.stepDown, "step-down", false, "ask the primary to step down")
+	f.StringVar(&c.demote, "demote", "", "remove the vote of the given state server")
+}
+
+func (c *StateServersCommand) Init(args []string) error {
+	if c.stepDown && c.demote != "" {
+		return fmt.Errorf("cannot specify both --step-down and --demote")
+	}
+	if c.demote != "" && !names.IsMachine(c.demote) {
+		return fmt.Errorf("invalid machine id %q", c.demote)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+type stateServersAPI interface {
+	StateServers() (params.StateServersStatus, error)
+	StepDownPrimary() error
+	DemoteStateServer(machineId string) error
+	Close() error
+}
+
+var getStateServersAPI = func(envName string) (stateServersAPI, error) {
+	return juju.NewAPIClientFromName(envName)
+}
+
+func (c *StateServersCommand) Run(ctx *cmd.Context) error {
+	client, err := getStateServersAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	switch {
+	case c.stepDown:
+		if err := client.StepDownPrimary(); err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Stderr, "primary stepped down\n")
+		return nil
+	case c.demote != "":
+		if err := client.DemoteStateServer(c.demote); err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Stderr, "state server %s will lose its vote once another state server can take it\n", c.demote)
+		return nil
+	}
+	status, err := client.StateServers()
+	if err != nil {
+		return err
+	}
+	result := stateServersEntry{
+		Members: make([]stateServerMemberEntry, len(status.Members)),
+	}
+	for i, m := range status.Members {
+		entry := stateServerMemberEntry{
+			Machine:   m.MachineId,
+			Address:   m.Address,
+			State:     m.State,
+			Healthy:   m.Healthy,
+			Message:   m.Message,
+			Voting:    m.Voting,
+			WantsVote: m.WantsVote,
+			HasVote:   m.HasVote,
+		}
+		if m.OptimeLag > 0 {
+			entry.OptimeLag = m.OptimeLag.String()
+		}
+		result.Members[i] = entry
+	}
+	if d := status.Decision; d != nil {
+		result.Decision = &peerGroupDecisionEntry{
+			Time:    d.Time.UTC().Format(time.RFC3339),
+			Changed: d.Changed,
+			Reasons: d.Reasons,
+			Error:   d.Error,
+		}
+	}
+	return c.out.Write(ctx, result)
+}
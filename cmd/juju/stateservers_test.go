@@ -0,0 +1,145 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type StateServersSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockStateServersAPI
+}
+
+var _ = gc.Suite(&StateServersSuite{})
+
+func (s *StateServersSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockStateServersAPI{}
+	s.PatchValue(&getStateServersAPI, func(envName string) (stateServersAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *StateServersSuite) TestStateServers(c *gc.C) {
+	s.mockAPI.status = params.StateServersStatus{
+		Members: []params.StateServerMember{{
+			MachineId: "0",
+			Address:   "10.0.0.1:37017",
+			State:     "PRIMARY",
+			Healthy:   true,
+			Voting:    true,
+			WantsVote: true,
+			HasVote:   true,
+		}, {
+			MachineId: "1",
+			Address:   "10.0.0.2:37017",
+			State:     "SECONDARY",
+			Healthy:   true,
+			WantsVote: true,
+			OptimeLag: 2 * time.Second,
+		}, {
+			Address: "10.0.0.3:37017",
+			State:   "DOWN",
+			Message: "no route to host",
+		}},
+		Decision: &params.PeerGroupDecision{
+			Time:    time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC),
+			Reasons: []string{`adding machine "1" to the peer group`},
+		},
+	}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&StateServersCommand{}))
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+members:
+- machine: "0"
+  address: 10.0.0.1:37017
+  mongo-state: PRIMARY
+  healthy: true
+  voting: true
+  wants-vote: true
+  has-vote: true
+- machine: "1"
+  address: 10.0.0.2:37017
+  mongo-state: SECONDARY
+  healthy: true
+  voting: false
+  wants-vote: true
+  has-vote: false
+  optime-lag: 2s
+- address: 10.0.0.3:37017
+  mongo-state: DOWN
+  healthy: false
+  message: no route to host
+  voting: false
+  wants-vote: false
+  has-vote: false
+last-decision:
+  time: "2014-06-01T12:00:00Z"
+  changed-replica-set: false
+  reasons:
+  - adding machine "1" to the peer group
+`[1:])
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *StateServersSuite) TestStepDown(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&StateServersCommand{}), "--step-down")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.steppedDown, gc.Equals, true)
+}
+
+func (s *StateServersSuite) TestDemote(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&StateServersCommand{}), "--demote", "2")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.demoted, gc.Equals, "2")
+}
+
+func (s *StateServersSuite) TestDemoteError(c *gc.C) {
+	s.mockAPI.err = &params.Error{Message: "cannot demote state server 2: machine is the only voting state server"}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&StateServersCommand{}), "--demote", "2")
+	c.Assert(err, gc.ErrorMatches, "cannot demote state server 2: machine is the only voting state server")
+}
+
+func (s *StateServersSuite) TestInitErrors(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&StateServersCommand{}), "--step-down", "--demote", "1")
+	c.Assert(err, gc.ErrorMatches, "cannot specify both --step-down and --demote")
+	_, err = testing.RunCommand(c, envcmd.Wrap(&StateServersCommand{}), "--demote", "foo")
+	c.Assert(err, gc.ErrorMatches, `invalid machine id "foo"`)
+	_, err = testing.RunCommand(c, envcmd.Wrap(&StateServersCommand{}), "foo")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
+
+type mockStateServersAPI struct {
+	status      params.StateServersStatus
+	steppedDown bool
+	demoted     string
+	err         error
+	closed      bool
+}
+
+func (m *mockStateServersAPI) StateServers() (params.StateServersStatus, error) {
+	return m.status, m.err
+}
+
+func (m *mockStateServersAPI) StepDownPrimary() error {
+	m.steppedDown = true
+	return m.err
+}
+
+func (m *mockStateServersAPI) DemoteStateServer(machineId string) error {
+	m.demoted = machineId
+	return m.err
+}
+
+func (m *mockStateServersAPI) Close() error {
+	m.closed = true
+	return nil
+}
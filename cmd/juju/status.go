@@ -6,6 +6,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"launchpad.net/gnuflag"
@@ -37,6 +40,11 @@ will be displayed.
 Wildcards ('*') may be specified in service/unit names to match any sequence
 of characters. For example, 'nova-*' will match any service whose name begins
 with 'nova-': 'nova-compute', 'nova-volume', etc.
+
+Units that are blocked waiting for another unit on the same machine to
+finish running a hook report it in their hook-lock field, along with the
+hooks they have queued. With --verbose, every such wait is also listed
+before the status output.
 `
 
 func (c *StatusCommand) Info() *cmd.Info {
@@ -99,14 +107,42 @@ func (c *StatusCommand) Run(ctx *cmd.Context) error {
 		fmt.Fprintf(ctx.Stderr, "%v\n", err)
 	}
 	result := newStatusFormatter(status).format()
+	reportHookLockWaits(ctx, result)
 	return c.out.Write(ctx, result)
 }
 
+// reportHookLockWaits logs, in verbose mode, every unit that is
+// blocked waiting for the hook execution lock on its machine.
+func reportHookLockWaits(ctx *cmd.Context, status formattedStatus) {
+	waits := make(map[string]string)
+	var collect func(units map[string]unitStatus)
+	collect = func(units map[string]unitStatus) {
+		for name, unit := range units {
+			if unit.HookLock != "" {
+				waits[name] = unit.HookLock
+			}
+			collect(unit.Subordinates)
+		}
+	}
+	for _, service := range status.Services {
+		collect(service.Units)
+	}
+	names := make([]string, 0, len(waits))
+	for name := range waits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ctx.Verbosef("%s: %s", name, waits[name])
+	}
+}
+
 type formattedStatus struct {
 	Environment string                   `json:"environment"`
 	Machines    map[string]machineStatus `json:"machines"`
 	Services    map[string]serviceStatus `json:"services"`
 	Networks    map[string]networkStatus `json:"networks,omitempty" yaml:",omitempty"`
+	Warnings    []string                 `json:"warnings,omitempty" yaml:",omitempty"`
 }
 
 type errorStatus struct {
@@ -160,9 +196,16 @@ type serviceStatus struct {
 	Relations     map[string][]string   `json:"relations,omitempty" yaml:"relations,omitempty"`
 	Networks      map[string][]string   `json:"networks,omitempty" yaml:"networks,omitempty"`
 	SubordinateTo []string              `json:"subordinate-to,omitempty" yaml:"subordinate-to,omitempty"`
+	CharmUpgrade  *charmUpgradeStatus   `json:"charm-upgrade,omitempty" yaml:"charm-upgrade,omitempty"`
 	Units         map[string]unitStatus `json:"units,omitempty" yaml:"units,omitempty"`
 }
 
+type charmUpgradeStatus struct {
+	BatchSize int    `json:"batch-size" yaml:"batch-size"`
+	Halted    bool   `json:"halted,omitempty" yaml:"halted,omitempty"`
+	Message   string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
 type serviceStatusNoMarshal serviceStatus
 
 func (s serviceStatus) MarshalJSON() ([]byte, error) {
@@ -184,6 +227,8 @@ func (s serviceStatus) GetYAML() (tag string, value interface{}) {
 type unitStatus struct {
 	Err            error                 `json:"-" yaml:",omitempty"`
 	Charm          string                `json:"upgrading-from,omitempty" yaml:"upgrading-from,omitempty"`
+	CharmUpgrade   string                `json:"charm-upgrade,omitempty" yaml:"charm-upgrade,omitempty"`
+	HookLock       string                `json:"hook-lock,omitempty" yaml:"hook-lock,omitempty"`
 	AgentState     params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
 	AgentStateInfo string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
 	AgentVersion   string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
@@ -239,12 +284,18 @@ func (n networkStatus) GetYAML() (tag string, value interface{}) {
 type statusFormatter struct {
 	status    *api.Status
 	relations map[int]api.RelationStatus
+	now       time.Time
 }
 
+// statusNow returns the time that waits reported in status are
+// measured against.
+var statusNow = time.Now
+
 func newStatusFormatter(status *api.Status) *statusFormatter {
 	sf := statusFormatter{
 		status:    status,
 		relations: make(map[int]api.RelationStatus),
+		now:       statusNow(),
 	}
 	for _, relation := range status.Relations {
 		sf.relations[relation.Id] = relation
@@ -260,6 +311,7 @@ func (sf *statusFormatter) format() formattedStatus {
 		Environment: sf.status.EnvironmentName,
 		Machines:    make(map[string]machineStatus),
 		Services:    make(map[string]serviceStatus),
+		Warnings:    sf.status.Warnings,
 	}
 	for k, m := range sf.status.Machines {
 		out.Machines[k] = sf.formatMachine(m)
@@ -340,6 +392,13 @@ func (sf *statusFormatter) formatService(name string, service api.ServiceStatus)
 		SubordinateTo: service.SubordinateTo,
 		Units:         make(map[string]unitStatus),
 	}
+	if service.CharmUpgrade != nil {
+		out.CharmUpgrade = &charmUpgradeStatus{
+			BatchSize: service.CharmUpgrade.BatchSize,
+			Halted:    service.CharmUpgrade.Halted,
+			Message:   service.CharmUpgrade.Message,
+		}
+	}
 	if len(service.Networks.Enabled) > 0 {
 		out.Networks["enabled"] = service.Networks.Enabled
 	}
@@ -363,14 +422,49 @@ func (sf *statusFormatter) formatUnit(unit api.UnitStatus, serviceName string) u
 		OpenedPorts:    unit.OpenedPorts,
 		PublicAddress:  unit.PublicAddress,
 		Charm:          unit.Charm,
+		CharmUpgrade:   unit.CharmUpgrade,
 		Subordinates:   make(map[string]unitStatus),
 	}
+	if unit.HookLockWait != nil {
+		out.HookLock = sf.formatHookLockWait(unit.HookLockWait)
+	}
 	for k, m := range unit.Subordinates {
 		out.Subordinates[k] = sf.formatUnit(m, serviceName)
 	}
 	return out
 }
 
+// formatHookLockWait describes a unit's wait for its machine's hook
+// execution lock, and the hooks it has queued, for example "waiting for
+// hook lock held by wordpress/3 (config-changed, 4m); queued hooks:
+// install, db-relation-joined".
+func (sf *statusFormatter) formatHookLockWait(wait *api.HookLockWaitStatus) string {
+	waited := sf.now.Sub(wait.Since)
+	if waited < 0 {
+		waited = 0
+	}
+	var elapsed string
+	if waited >= time.Minute {
+		elapsed = fmt.Sprintf("%dm", int(waited/time.Minute))
+	} else {
+		elapsed = fmt.Sprintf("%ds", int(waited/time.Second))
+	}
+	held := elapsed
+	if wait.HolderHook != "" {
+		held = wait.HolderHook + ", " + elapsed
+	}
+	out := fmt.Sprintf("waiting for hook lock held by %s (%s)", wait.Holder, held)
+	var queued []string
+	if wait.Hook != "" {
+		queued = append(queued, wait.Hook)
+	}
+	queued = append(queued, wait.Queued...)
+	if len(queued) > 0 {
+		out += "; queued hooks: " + strings.Join(queued, ", ")
+	}
+	return out
+}
+
 func (sf *statusFormatter) getUnitStatusInfo(unit api.UnitStatus, serviceName string) string {
 	if unit.Agent.Status == "" {
 		// Old server that doesn't support this field and others.
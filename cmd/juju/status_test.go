@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/juju/charm"
 	charmtesting "github.com/juju/charm/testing"
@@ -2311,3 +2312,58 @@ func (s *StatusSuite) TestStatusWithPreRelationsServer(c *gc.C) {
 	defer s.resetContext(c, ctx)
 	ctx.run(c, []stepper{expected})
 }
+
+func (s *StatusSuite) TestStatusHookLockWait(c *gc.C) {
+	now := time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.PatchValue(&statusNow, func() time.Time { return now })
+	client := newFakeApiClient(&api.Status{
+		EnvironmentName: "dummyenv",
+		Services: map[string]api.ServiceStatus{
+			"wordpress": api.ServiceStatus{
+				Charm: "local:quantal/wordpress-3",
+				Units: map[string]api.UnitStatus{
+					"wordpress/0": api.UnitStatus{
+						AgentState: "started",
+						Machine:    "1",
+						HookLockWait: &api.HookLockWaitStatus{
+							Holder:     "wordpress/3",
+							HolderHook: "config-changed",
+							Hook:       "install",
+							Queued:     []string{"db-relation-joined", "db-relation-changed"},
+							Since:      now.Add(-4*time.Minute - 10*time.Second),
+						},
+					},
+					"wordpress/1": api.UnitStatus{
+						AgentState: "started",
+						Machine:    "1",
+						HookLockWait: &api.HookLockWaitStatus{
+							Holder: "wordpress/3",
+							Hook:   "start",
+							Since:  now.Add(-20 * time.Second),
+						},
+					},
+				},
+			},
+		},
+	})
+	s.PatchValue(&newApiClientForStatus, func(_ string) (statusAPI, error) {
+		return &client, nil
+	})
+	code, stdout, stderr := runStatus(c, "--format", "json")
+	c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", stderr))
+	var result struct {
+		Services map[string]struct {
+			Units map[string]struct {
+				HookLock string `json:"hook-lock"`
+			}
+		}
+	}
+	err := json.Unmarshal(stdout, &result)
+	c.Assert(err, gc.IsNil)
+	units := result.Services["wordpress"].Units
+	c.Assert(units["wordpress/0"].HookLock, gc.Equals,
+		"waiting for hook lock held by wordpress/3 (config-changed, 4m); "+
+			"queued hooks: install, db-relation-joined, db-relation-changed")
+	c.Assert(units["wordpress/1"].HookLock, gc.Equals,
+		"waiting for hook lock held by wordpress/3 (20s); queued hooks: start")
+}
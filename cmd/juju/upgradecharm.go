@@ -25,6 +25,8 @@ type UpgradeCharmCommand struct {
 	RepoPath    string // defaults to JUJU_REPOSITORY
 	SwitchURL   string
 	Revision    int // defaults to -1 (latest)
+	BatchSize   int // defaults to 0 (all units at once)
+	Resume      bool
 }
 
 const upgradeCharmDoc = `
@@ -63,6 +65,14 @@ would specify revision number 5 of the wordpress charm.
 Use of the --force flag is not generally recommended; units upgraded while in an
 error state will not have upgrade-charm hooks executed, and may cause unexpected
 behavior.
+
+By default all units of the service are upgraded at once. The --batch-size
+flag requests a rolling upgrade instead: units are upgraded at most that many
+at a time, and each batch is started only once the previous batch is running
+the new charm with its agents started. If any unit fails to upgrade, the
+rolling upgrade halts; once the failure has been dealt with, the upgrade can
+be continued with --resume. The progress of a rolling upgrade is shown by
+juju status. Units added during a rolling upgrade run the new charm.
 `
 
 func (c *UpgradeCharmCommand) Info() *cmd.Info {
@@ -79,6 +89,8 @@ func (c *UpgradeCharmCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.RepoPath, "repository", os.Getenv("JUJU_REPOSITORY"), "local charm repository path")
 	f.StringVar(&c.SwitchURL, "switch", "", "crossgrade to a different charm")
 	f.IntVar(&c.Revision, "revision", -1, "explicit revision of current charm")
+	f.IntVar(&c.BatchSize, "batch-size", 0, "upgrade at most this many units at a time")
+	f.BoolVar(&c.Resume, "resume", false, "resume a halted rolling upgrade")
 }
 
 func (c *UpgradeCharmCommand) Init(args []string) error {
@@ -96,6 +108,12 @@ func (c *UpgradeCharmCommand) Init(args []string) error {
 	if c.SwitchURL != "" && c.Revision != -1 {
 		return fmt.Errorf("--switch and --revision are mutually exclusive")
 	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("--batch-size must not be negative")
+	}
+	if c.Resume && (c.SwitchURL != "" || c.Revision != -1 || c.BatchSize != 0 || c.Force) {
+		return fmt.Errorf("--resume cannot be combined with other upgrade options")
+	}
 	return nil
 }
 
@@ -107,6 +125,9 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 	defer client.Close()
+	if c.Resume {
+		return client.ServiceResumeCharmUpgrade(c.ServiceName)
+	}
 	oldURL, err := client.ServiceGetCharmURL(c.ServiceName)
 	if err != nil {
 		return err
@@ -165,5 +186,8 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 
+	if c.BatchSize > 0 {
+		return client.ServiceSetCharmRolling(c.ServiceName, addedURL.String(), c.Force, c.BatchSize)
+	}
 	return client.ServiceSetCharm(c.ServiceName, addedURL.String(), c.Force)
 }
@@ -94,6 +94,24 @@ func (s *UpgradeCharmErrorsSuite) TestInvalidRevision(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `invalid value "blah" for flag --revision: strconv.ParseInt: parsing "blah": invalid syntax`)
 }
 
+func (s *UpgradeCharmErrorsSuite) TestBadBatchSize(c *gc.C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--batch-size=-1")
+	c.Assert(err, gc.ErrorMatches, "--batch-size must not be negative")
+}
+
+func (s *UpgradeCharmErrorsSuite) TestResumeWithOtherOptionsFails(c *gc.C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--resume", "--batch-size=2")
+	c.Assert(err, gc.ErrorMatches, "--resume cannot be combined with other upgrade options")
+}
+
+func (s *UpgradeCharmErrorsSuite) TestResumeNotInProgress(c *gc.C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--resume")
+	c.Assert(err, gc.ErrorMatches, `cannot resume charm upgrade for service "riak": no charm upgrade in progress`)
+}
+
 type UpgradeCharmSuccessSuite struct {
 	jujutesting.RepoSuite
 	path string
@@ -178,6 +196,15 @@ func (s *UpgradeCharmSuccessSuite) TestForcedUpgrade(c *gc.C) {
 	s.assertLocalRevision(c, 7, s.path)
 }
 
+func (s *UpgradeCharmSuccessSuite) TestRollingUpgrade(c *gc.C) {
+	err := runUpgradeCharm(c, "riak", "--batch-size=2")
+	c.Assert(err, gc.IsNil)
+	s.assertUpgraded(c, 8, false)
+	upgrade, ok := s.riak.CharmUpgrade()
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(upgrade.BatchSize, gc.Equals, 2)
+}
+
 var myriakMeta = []byte(`
 name: myriak
 summary: "K/V storage engine"
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/juju/cmd"
+	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
@@ -34,6 +35,17 @@ type UpgradeJujuCommand struct {
 	UploadTools bool
 	DryRun      bool
 	Series      []string
+
+	// ShowProgress holds whether to show the progress of the
+	// upgrade until it completes or a step fails.
+	ShowProgress bool
+
+	// SkipFailedStep holds the id of a machine whose failed upgrade
+	// step should be skipped.
+	SkipFailedStep string
+
+	// Abort holds whether to abort the upgrade under way.
+	Abort bool
 }
 
 var upgradeJujuDoc = `
@@ -67,6 +79,47 @@ value of the environment's agent-version setting:
 Both of these depend on tools availability, which some situations (no
 outgoing internet access) and provider types (such as maas) require that
 you manage yourself; see the documentation for "sync-tools".
+
+With --dry-run, upgrade-juju reports the version it would upgrade to,
+and the upgrade steps each machine would run for each of its roles
+(state server or unit host) to reach that version, and the steps that
+upgrade the database, without changing anything. The steps listed are
+those known to this juju client, so they are only accurate when the
+client is itself at the version being upgraded to.
+
+The state servers upgrade together: each waits until all of them have
+reached the new version; then one of them upgrades the database, and
+only then do the state servers run their own upgrade steps and accept
+connections from the other agents.
+
+Agents fetch the new tools through the API servers. In a large
+environment, set tools-download-concurrency with set-env to limit the
+number of tools downloads each API server serves at once; other agents
+wait their turn.
+
+Each machine agent records the progress of its upgrade steps in the
+environment. With --show-progress, upgrade-juju shows a table of the
+status of each step on each machine, and its error if it has failed,
+whenever the progress changes, until all the machines have completed
+their upgrades or a step has failed. It may be run again with just
+--show-progress to keep watching an upgrade under way.
+
+An agent whose upgrade step has failed retries its upgrade until every
+step succeeds. If a step keeps failing, and you have made sure that it
+is safe for the machine to continue without it, run
+
+    juju upgrade-juju --skip-failed-step <machine>
+
+and the machine agent skips the failed step when it next retries. The
+step is skipped only for the upgrade under way.
+
+Until a state server has started to upgrade the database, an upgrade may
+instead be aborted with
+
+    juju upgrade-juju --abort
+
+which reverts the environment to the version it was upgrading from. The
+agents keep the tools they ran before upgrading, and switch back to them.
 `
 
 func (c *UpgradeJujuCommand) Info() *cmd.Info {
@@ -82,6 +135,9 @@ func (c *UpgradeJujuCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.UploadTools, "upload-tools", false, "upload local version of tools")
 	f.BoolVar(&c.DryRun, "dry-run", false, "don't change anything, just report what would change")
 	f.Var(newSeriesValue(nil, &c.Series), "series", "upload tools for supplied comma-separated series list")
+	f.BoolVar(&c.ShowProgress, "show-progress", false, "show the progress of the upgrade until it completes")
+	f.StringVar(&c.SkipFailedStep, "skip-failed-step", "", "skip the failed upgrade step of the given machine")
+	f.BoolVar(&c.Abort, "abort", false, "abort the upgrade under way and roll the agents back")
 }
 
 func (c *UpgradeJujuCommand) Init(args []string) error {
@@ -107,6 +163,22 @@ func (c *UpgradeJujuCommand) Init(args []string) error {
 	if len(c.Series) > 0 && !c.UploadTools {
 		return fmt.Errorf("--series requires --upload-tools")
 	}
+	if c.ShowProgress && c.DryRun {
+		return fmt.Errorf("cannot specify both --show-progress and --dry-run")
+	}
+	if c.SkipFailedStep != "" {
+		if !names.IsMachine(c.SkipFailedStep) {
+			return fmt.Errorf("invalid machine id %q", c.SkipFailedStep)
+		}
+		if c.vers != "" || c.UploadTools || c.DryRun || c.ShowProgress {
+			return fmt.Errorf("--skip-failed-step cannot be combined with other options")
+		}
+	}
+	if c.Abort {
+		if c.vers != "" || c.UploadTools || c.DryRun || c.ShowProgress || c.SkipFailedStep != "" {
+			return fmt.Errorf("--abort cannot be combined with other options")
+		}
+	}
 	return cmd.CheckEmpty(args)
 }
 
@@ -127,10 +199,30 @@ func (c *UpgradeJujuCommand) Run(ctx *cmd.Context) (err error) {
 		return err
 	}
 	defer client.Close()
+	if c.Abort {
+		previousVersion, err := client.AbortUpgrade()
+		if err != nil {
+			return err
+		}
+		ctx.Infof("upgrade aborted; agents will roll back to %s", previousVersion)
+		return nil
+	}
+	if c.SkipFailedStep != "" {
+		step, err := client.SkipUpgradeStep(c.SkipFailedStep)
+		if err != nil {
+			return err
+		}
+		ctx.Infof("machine %s will skip upgrade step %q when it next retries its upgrade", c.SkipFailedStep, step)
+		return nil
+	}
 	defer func() {
 		if err == errUpToDate {
 			ctx.Infof(err.Error())
 			err = nil
+			// The upgrade may already be under way.
+			if c.ShowProgress {
+				err = showUpgradeProgress(ctx, client)
+			}
 		}
 	}()
 
@@ -162,12 +254,18 @@ func (c *UpgradeJujuCommand) Run(ctx *cmd.Context) (err error) {
 	ctx.Infof("available tools:\n%s", formatTools(context.tools))
 	ctx.Infof("best version:\n    %s", context.chosen)
 	if c.DryRun {
+		if err := reportUpgradeSteps(ctx, client, context.agent, context.chosen); err != nil {
+			return err
+		}
 		ctx.Infof("upgrade to this version by running\n    juju upgrade-juju --version=\"%s\"\n", context.chosen)
 	} else {
 		if err := client.SetEnvironAgentVersion(context.chosen); err != nil {
 			return err
 		}
 		logger.Infof("started upgrade to %s", context.chosen)
+		if c.ShowProgress {
+			return showUpgradeProgress(ctx, client)
+		}
 	}
 	return nil
 }
@@ -95,6 +95,26 @@ var upgradeJujuTests = []struct {
 	currentVersion: "3.2.7-quantal-amd64",
 	args:           []string{"--upload-tools", "--version", "3.2.8.4"},
 	expectInitErr:  "cannot specify build number when uploading tools",
+}, {
+	about:          "--show-progress with --dry-run",
+	currentVersion: "3.2.7-quantal-amd64",
+	args:           []string{"--show-progress", "--dry-run"},
+	expectInitErr:  "cannot specify both --show-progress and --dry-run",
+}, {
+	about:          "--skip-failed-step with invalid machine id",
+	currentVersion: "3.2.7-quantal-amd64",
+	args:           []string{"--skip-failed-step", "foo"},
+	expectInitErr:  `invalid machine id "foo"`,
+}, {
+	about:          "--skip-failed-step with other options",
+	currentVersion: "3.2.7-quantal-amd64",
+	args:           []string{"--skip-failed-step", "0", "--version", "3.2.8"},
+	expectInitErr:  "--skip-failed-step cannot be combined with other options",
+}, {
+	about:          "--abort with other options",
+	currentVersion: "3.2.7-quantal-amd64",
+	args:           []string{"--abort", "--dry-run"},
+	expectInitErr:  "--abort cannot be combined with other options",
 }, {
 	about:          "latest supported stable release",
 	tools:          []string{"2.1.0-quantal-amd64", "2.1.2-quantal-i386", "2.1.3-quantal-amd64", "2.1-dev1-quantal-amd64"},
@@ -430,6 +450,9 @@ func (s *UpgradeJujuSuite) TestUpgradeDryRun(c *gc.C) {
     2.2.3-quantal-amd64
 best version:
     2.1.3
+warning: this client (version 2.0.0) only knows the upgrade steps up to its own version;
+the steps below may not match those run by 2.1.3
+no upgrade steps to run
 upgrade to this version by running
     juju upgrade-juju --version="2.1.3"
 `,
@@ -448,6 +471,9 @@ upgrade to this version by running
     2.2.3-quantal-amd64
 best version:
     2.1.3
+warning: this client (version 2.0.0) only knows the upgrade steps up to its own version;
+the steps below may not match those run by 2.1.3
+no upgrade steps to run
 upgrade to this version by running
     juju upgrade-juju --version="2.1.3"
 `,
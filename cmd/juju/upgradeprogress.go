@@ -0,0 +1,189 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/upgrades"
+	"github.com/juju/juju/version"
+)
+
+// pendingUpgradeSteps returns the upgrade steps a machine would run;
+// it is a variable so that it can be replaced in tests.
+var pendingUpgradeSteps = upgrades.PendingSteps
+
+// upgradeProgressPollInterval holds how often the progress of an
+// upgrade is polled by "juju upgrade-juju --show-progress".
+var upgradeProgressPollInterval = 5 * time.Second
+
+// upgradeTargets returns the upgrade targets of a machine with the
+// given jobs.
+func upgradeTargets(jobs []params.MachineJob) []upgrades.Target {
+	var targets []upgrades.Target
+	for _, job := range jobs {
+		switch job {
+		case params.JobManageEnviron:
+			targets = append(targets, upgrades.StateServer)
+		case params.JobHostUnits:
+			targets = append(targets, upgrades.HostMachine)
+		}
+	}
+	return targets
+}
+
+// reportUpgradeSteps writes the upgrade steps each machine would run
+// to upgrade from its current version to the given version.
+//
+// The steps are taken from this client's own table of upgrade steps,
+// because the steps for the target version are only known to the
+// tools of that version. The list is therefore only accurate when the
+// client is at the target version; otherwise a warning is written.
+func reportUpgradeSteps(ctx *cmd.Context, client *api.Client, agentVersion, to version.Number) error {
+	if version.Current.Number != to {
+		ctx.Infof("warning: this client (version %s) only knows the upgrade steps up to its own version;\n"+
+			"the steps below may not match those run by %s", version.Current.Number, to)
+	}
+	status, err := client.Status(nil)
+	if err != nil {
+		return err
+	}
+	machines := make(map[string]api.MachineStatus)
+	var addMachines func(map[string]api.MachineStatus)
+	addMachines = func(statuses map[string]api.MachineStatus) {
+		for id, m := range statuses {
+			machines[id] = m
+			addMachines(m.Containers)
+		}
+	}
+	addMachines(status.Machines)
+	ids := make([]string, 0, len(machines))
+	for id := range machines {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	hasStateServer := false
+	for _, id := range ids {
+		m := machines[id]
+		from := agentVersion
+		if m.AgentVersion != "" {
+			if from, err = version.Parse(m.AgentVersion); err != nil {
+				return err
+			}
+		}
+		for _, target := range upgradeTargets(m.Jobs) {
+			if target == upgrades.StateServer {
+				hasStateServer = true
+			}
+			steps := pendingUpgradeSteps(from, to, target)
+			if len(steps) == 0 {
+				continue
+			}
+			fmt.Fprintf(&buf, "    machine %s (%s):\n", id, target)
+			for _, step := range steps {
+				fmt.Fprintf(&buf, "        %s\n", step.Description())
+			}
+		}
+	}
+	if hasStateServer {
+		// The database is upgraded once, by whichever state server
+		// takes the database upgrade lock.
+		if steps := pendingUpgradeSteps(agentVersion, to, upgrades.DatabaseMaster); len(steps) > 0 {
+			fmt.Fprintf(&buf, "    database (run by one state server):\n")
+			for _, step := range steps {
+				fmt.Fprintf(&buf, "        %s\n", step.Description())
+			}
+		}
+	}
+	if buf.Len() == 0 {
+		ctx.Infof("no upgrade steps to run")
+		return nil
+	}
+	ctx.Infof("upgrade steps to run:\n%s", bytes.TrimRight(buf.Bytes(), "\n"))
+	return nil
+}
+
+// showUpgradeProgress writes a table of the progress of each machine
+// agent's upgrade whenever it changes, until all the agents have
+// completed their upgrades or a step has failed.
+func showUpgradeProgress(ctx *cmd.Context, client *api.Client) error {
+	var last string
+	for {
+		agents, err := client.UpgradeProgress()
+		if err != nil {
+			return err
+		}
+		table, completed, failed := formatUpgradeProgress(agents)
+		if table != last {
+			fmt.Fprint(ctx.Stdout, table)
+			last = table
+		}
+		if len(failed) > 0 {
+			for _, agent := range failed {
+				id := agent
+				if tag, err := names.ParseMachineTag(agent); err == nil {
+					id = tag.Id()
+				}
+				ctx.Infof("an upgrade step has failed on %s; the agent will retry it, or skip it after running\n"+
+					"    juju upgrade-juju --skip-failed-step %s", agent, id)
+			}
+			return fmt.Errorf("upgrade step failed")
+		}
+		if completed {
+			ctx.Infof("upgrade completed")
+			return nil
+		}
+		time.Sleep(upgradeProgressPollInterval)
+	}
+}
+
+// formatUpgradeProgress returns a table of the progress of the given
+// agents' upgrades, whether they have all completed, and the agents on
+// which a step has failed.
+func formatUpgradeProgress(agents []params.UpgradeProgress) (table string, completed bool, failed []string) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT\tTARGET\tSTEP\tSTATUS\tERROR")
+	completed = true
+	for _, agent := range agents {
+		if !agent.Completed {
+			completed = false
+		}
+		if len(agent.Steps) == 0 {
+			status := "waiting"
+			if agent.Completed {
+				status = "completed"
+			}
+			fmt.Fprintf(tw, "%s\t\t\t%s\t\n", agent.Agent, status)
+			continue
+		}
+		// A failed step the user has asked to skip does not stop
+		// the upgrade: the agent skips it when it next retries.
+		skip := set.NewStrings(agent.Skip...)
+		agentFailed := false
+		for _, step := range agent.Steps {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", agent.Agent, step.Target, step.Description, step.Status, step.Error)
+			if step.Status == params.UpgradeStepFailed && !skip.Contains(step.Description) {
+				agentFailed = true
+			}
+		}
+		if agentFailed {
+			failed = append(failed, agent.Agent)
+		}
+	}
+	tw.Flush()
+	return buf.String(), completed, failed
+}
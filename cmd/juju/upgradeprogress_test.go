@@ -0,0 +1,198 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/filestorage"
+	envtesting "github.com/juju/juju/environs/testing"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/upgrades"
+	"github.com/juju/juju/version"
+)
+
+type UpgradeProgressSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&UpgradeProgressSuite{})
+
+func (s *UpgradeProgressSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(&upgradeProgressPollInterval, coretesting.ShortWait)
+}
+
+func (s *UpgradeProgressSuite) addMachine(c *gc.C, job state.MachineJob) *state.Machine {
+	m, err := s.State.AddMachine("quantal", job)
+	c.Assert(err, gc.IsNil)
+	err = m.SetProvisioned(instance.Id("i-"+m.Id()), "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	return m
+}
+
+func (s *UpgradeProgressSuite) agentVersion(c *gc.C) version.Number {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	agentVersion, ok := cfg.AgentVersion()
+	c.Assert(ok, jc.IsTrue)
+	return agentVersion
+}
+
+func (s *UpgradeProgressSuite) setProgress(c *gc.C, m *state.Machine, completed bool, steps ...state.UpgradeStep) {
+	err := s.State.SetUpgradeProgress(state.UpgradeProgress{
+		Agent:     m.Tag().String(),
+		ToVersion: s.agentVersion(c),
+		Steps:     steps,
+		Completed: completed,
+	})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *UpgradeProgressSuite) runShowProgress(c *gc.C) (*cmd.Context, error) {
+	return coretesting.RunCommand(c, envcmd.Wrap(&UpgradeJujuCommand{}),
+		"--show-progress", "--version", s.agentVersion(c).String())
+}
+
+func (s *UpgradeProgressSuite) TestShowProgressCompleted(c *gc.C) {
+	m0 := s.addMachine(c, state.JobManageEnviron)
+	m1 := s.addMachine(c, state.JobHostUnits)
+	s.setProgress(c, m0, true, state.UpgradeStep{
+		Target:      "stateServer",
+		Description: "ensure system ssh key",
+		Status:      params.UpgradeStepDone,
+	})
+	s.setProgress(c, m1, true)
+
+	ctx, err := s.runShowProgress(c)
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		"AGENT      TARGET       STEP                   STATUS     ERROR\n"+
+		"machine-0  stateServer  ensure system ssh key  done       \n"+
+		"machine-1                                      completed  \n",
+	)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, "no upgrades available\nupgrade completed\n")
+}
+
+func (s *UpgradeProgressSuite) TestShowProgressFailed(c *gc.C) {
+	m0 := s.addMachine(c, state.JobManageEnviron)
+	s.setProgress(c, m0, false, state.UpgradeStep{
+		Target:      "stateServer",
+		Description: "ensure system ssh key",
+		Status:      params.UpgradeStepFailed,
+		Error:       "boom",
+	})
+
+	ctx, err := s.runShowProgress(c)
+	c.Assert(err, gc.ErrorMatches, "upgrade step failed")
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		"AGENT      TARGET       STEP                   STATUS  ERROR\n"+
+		"machine-0  stateServer  ensure system ssh key  failed  boom\n",
+	)
+	c.Assert(coretesting.Stderr(ctx), gc.Matches, "(?s).*juju upgrade-juju --skip-failed-step 0\n")
+}
+
+func (s *UpgradeProgressSuite) TestSkipFailedStep(c *gc.C) {
+	m0 := s.addMachine(c, state.JobManageEnviron)
+	s.setProgress(c, m0, false, state.UpgradeStep{
+		Target:      "stateServer",
+		Description: "ensure system ssh key",
+		Status:      params.UpgradeStepFailed,
+		Error:       "boom",
+	})
+
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&UpgradeJujuCommand{}), "--skip-failed-step", "0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals,
+		"machine 0 will skip upgrade step \"ensure system ssh key\" when it next retries its upgrade\n")
+	progress, err := s.State.UpgradeProgress(m0.Tag().String())
+	c.Assert(err, gc.IsNil)
+	c.Assert(progress.Skip, jc.DeepEquals, []string{"ensure system ssh key"})
+
+	_, err = coretesting.RunCommand(c, envcmd.Wrap(&UpgradeJujuCommand{}), "--skip-failed-step", "0")
+	c.Assert(err, gc.ErrorMatches, "cannot skip upgrade step of machine-0: no upgrade step has failed")
+}
+
+func (s *UpgradeProgressSuite) TestAbortUpgrade(c *gc.C) {
+	m0 := s.addMachine(c, state.JobManageEnviron)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	from, ok := cfg.AgentVersion()
+	c.Assert(ok, jc.IsTrue)
+	to := from
+	to.Minor++
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{"agent-version": to.String()}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.EnsureUpgradeInfo(m0.Id(), from, to)
+	c.Assert(err, gc.IsNil)
+
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&UpgradeJujuCommand{}), "--abort")
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, "upgrade aborted; agents will roll back to "+from.String()+"\n")
+	cfg, err = s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	agentVersion, ok := cfg.AgentVersion()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(agentVersion, gc.Equals, from)
+
+	_, err = coretesting.RunCommand(c, envcmd.Wrap(&UpgradeJujuCommand{}), "--abort")
+	c.Assert(err, gc.ErrorMatches, "cannot abort upgrade: upgrade to "+to.String()+" has already been aborted")
+}
+
+type fakeUpgradeStep string
+
+func (step fakeUpgradeStep) Description() string                { return string(step) }
+func (step fakeUpgradeStep) Targets() []upgrades.Target         { return nil }
+func (step fakeUpgradeStep) Run(context upgrades.Context) error { return nil }
+
+func (s *UpgradeProgressSuite) TestDryRunReportsSteps(c *gc.C) {
+	s.addMachine(c, state.JobManageEnviron)
+	s.addMachine(c, state.JobHostUnits)
+	s.PatchValue(&pendingUpgradeSteps, func(from, to version.Number, target upgrades.Target) []upgrades.Step {
+		c.Check(from, gc.Equals, s.agentVersion(c))
+		switch target {
+		case upgrades.StateServer:
+			return []upgrades.Step{fakeUpgradeStep("step 1"), fakeUpgradeStep("step 2")}
+		case upgrades.HostMachine:
+			return []upgrades.Step{fakeUpgradeStep("step 3")}
+		}
+		return []upgrades.Step{fakeUpgradeStep("step 4")}
+	})
+	s.PatchValue(&version.Current, version.MustParseBinary("2.0.0-quantal-amd64"))
+	toolsDir := c.MkDir()
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"agent-version":      "2.0.0",
+		"tools-metadata-url": "file://" + toolsDir,
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	envtesting.RemoveTools(c, s.Conn.Environ.Storage())
+	vers := version.MustParseBinary("2.1.3-quantal-amd64")
+	envtesting.MustUploadFakeToolsVersions(s.Conn.Environ.Storage(), vers)
+	stor, err := filestorage.NewFileStorageWriter(toolsDir)
+	c.Assert(err, gc.IsNil)
+	envtesting.MustUploadFakeToolsVersions(stor, vers)
+
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&UpgradeJujuCommand{}), "--dry-run")
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Matches, `(?s).*
+warning: this client \(version 2.0.0\) only knows the upgrade steps up to its own version;
+the steps below may not match those run by 2.1.3
+upgrade steps to run:
+    machine 0 \(stateServer\):
+        step 1
+        step 2
+    machine 1 \(hostMachine\):
+        step 3
+    database \(run by one state server\):
+        step 4
+upgrade to this version by running
+.*`)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +29,8 @@ import (
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/deployer"
+	"github.com/juju/juju/worker/introspection"
+	"github.com/juju/juju/worker/passwordrotator"
 	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/upgrader"
 )
@@ -100,6 +103,18 @@ func (a *AgentConf) SetAPIHostPorts(servers [][]network.HostPort) error {
 	})
 }
 
+// CACert satisfies worker/certupdater/CACertSetter.
+func (a *AgentConf) CACert() string {
+	return a.CurrentConfig().CACert()
+}
+
+// SetCACert satisfies worker/certupdater/CACertSetter.
+func (a *AgentConf) SetCACert(caCert string) error {
+	return a.ChangeConfig(func(c agent.ConfigSetter) {
+		c.SetCACert(caCert)
+	})
+}
+
 func importance(err error) int {
 	switch {
 	case err == nil:
@@ -150,7 +165,7 @@ func (e *fatalError) Error() string {
 }
 
 func isFatal(err error) bool {
-	if err == worker.ErrTerminateAgent {
+	if err == worker.ErrTerminateAgent || err == worker.ErrRestartAgent {
 		return true
 	}
 	if isUpgraded(err) {
@@ -259,25 +274,10 @@ func openAPIState(agentConfig agent.Config, a Agent) (*api.State, *apiagent.Enti
 		// We succeeded in connecting with the fallback
 		// password, so we need to create a new password
 		// for the future.
-
-		newPassword, err := utils.RandomPassword()
+		newPassword, err := passwordrotator.ChangePassword(a, entity, info.Password)
 		if err != nil {
 			return nil, nil, err
 		}
-		// Change the configuration *before* setting the entity
-		// password, so that we avoid the possibility that
-		// we might successfully change the entity's
-		// password but fail to write the configuration,
-		// thus locking us out completely.
-		if err := a.ChangeConfig(func(c agent.ConfigSetter) {
-			c.SetPassword(newPassword)
-			c.SetOldPassword(info.Password)
-		}); err != nil {
-			return nil, nil, err
-		}
-		if err := entity.SetPassword(newPassword); err != nil {
-			return nil, nil, err
-		}
 
 		st.Close()
 		info.Password = newPassword
@@ -323,6 +323,15 @@ func (c *closeWorker) Kill() {
 	c.worker.Kill()
 }
 
+// WorkerStatus implements worker.Reporter by reporting the status of
+// the wrapped worker's own workers, if it has any.
+func (c *closeWorker) WorkerStatus() []worker.WorkerStatus {
+	if reporter, ok := c.worker.(worker.Reporter); ok {
+		return reporter.WorkerStatus()
+	}
+	return nil
+}
+
 func (c *closeWorker) Wait() error {
 	err := c.worker.Wait()
 	if err := c.closer.Close(); err != nil {
@@ -352,6 +361,50 @@ var newRsyslogConfigWorker = func(st *apirsyslog.State, agentConfig agent.Config
 	return rsyslog.NewRsyslogConfigWorker(st, mode, tag, namespace, addrs)
 }
 
+// newIntrospectionWorker returns a worker that serves reports on the
+// agent, including the status of the workers run by the given runner,
+// over a unix socket in the agent's directory.
+func newIntrospectionWorker(a interface {
+	CurrentConfig() agent.Config
+}, runner worker.Runner) (worker.Worker, error) {
+	agentConfig := a.CurrentConfig()
+	reporter, _ := runner.(worker.Reporter)
+	return introspection.NewWorker(introspection.Config{
+		Agent:      agentConfig.Tag(),
+		SocketPath: filepath.Join(agentConfig.Dir(), introspection.SocketFile),
+		Reporter:   reporter,
+		ConfigSummary: func() map[string]string {
+			return agentConfigSummary(a.CurrentConfig())
+		},
+	})
+}
+
+// agentConfigSummary returns the parts of the given agent
+// configuration that are useful when diagnosing problems. It does not
+// include any secrets.
+func agentConfigSummary(agentConfig agent.Config) map[string]string {
+	summary := map[string]string{
+		"tag":                 agentConfig.Tag(),
+		"data-dir":            agentConfig.DataDir(),
+		"log-dir":             agentConfig.LogDir(),
+		"upgraded-to-version": agentConfig.UpgradedToVersion().String(),
+	}
+	if addrs, err := agentConfig.APIAddresses(); err == nil {
+		summary["api-addresses"] = strings.Join(addrs, " ")
+	}
+	if jobs := agentConfig.Jobs(); len(jobs) > 0 {
+		jobNames := make([]string, len(jobs))
+		for i, job := range jobs {
+			jobNames[i] = string(job)
+		}
+		summary["jobs"] = strings.Join(jobNames, " ")
+	}
+	if _, ok := agentConfig.StateServingInfo(); ok {
+		summary["state-server"] = "true"
+	}
+	return summary
+}
+
 // hookExecutionLock returns an *fslock.Lock suitable for use as a unit
 // hook execution lock. Other workers may also use this lock if they
 // require isolation from hook execution.
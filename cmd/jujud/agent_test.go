@@ -61,6 +61,9 @@ var isFatalTests = []struct {
 }{{
 	err:     worker.ErrTerminateAgent,
 	isFatal: true,
+}, {
+	err:     worker.ErrRestartAgent,
+	isFatal: true,
 }, {
 	err:     &upgrader.UpgradeReadyError{},
 	isFatal: true,
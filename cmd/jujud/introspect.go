@@ -0,0 +1,80 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/worker/introspection"
+)
+
+const introspectCommandDoc = `
+Show a report on an agent running on this machine: the state of its
+workers, a summary of its configuration and, if --goroutines is given,
+the stacks of all its goroutines.
+
+The agent may be given as a tag (machine-0, unit-mysql-0), a machine
+id or a unit name.
+`
+
+// IntrospectCommand reports on a running agent by way of its
+// introspection socket.
+type IntrospectCommand struct {
+	cmd.CommandBase
+	dataDir    string
+	agent      string
+	goroutines bool
+	out        cmd.Output
+}
+
+func (c *IntrospectCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "introspect",
+		Args:    "<agent>",
+		Purpose: "report on a running agent",
+		Doc:     introspectCommandDoc,
+	}
+}
+
+func (c *IntrospectCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.dataDir, "data-dir", agent.DefaultDataDir, "directory for juju data")
+	f.BoolVar(&c.goroutines, "goroutines", false, "include the stacks of all the agent's goroutines")
+	c.out.AddFlags(f, "json", cmd.DefaultFormatters)
+}
+
+func (c *IntrospectCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no agent specified")
+	}
+	c.agent, args = args[0], args[1:]
+	switch {
+	case names.IsMachine(c.agent):
+		c.agent = names.NewMachineTag(c.agent).String()
+	case names.IsUnit(c.agent):
+		c.agent = names.NewUnitTag(c.agent).String()
+	default:
+		kind, err := names.TagKind(c.agent)
+		if err != nil || kind != names.MachineTagKind && kind != names.UnitTagKind {
+			return fmt.Errorf("invalid agent %q", c.agent)
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *IntrospectCommand) Run(ctx *cmd.Context) error {
+	socketPath := filepath.Join(agent.Dir(c.dataDir, c.agent), introspection.SocketFile)
+	report, err := introspection.Query(socketPath, introspection.ReportArgs{
+		Goroutines: c.goroutines,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get report from agent %q: %v", c.agent, err)
+	}
+	return c.out.Write(ctx, report)
+}
@@ -0,0 +1,85 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/introspection"
+)
+
+type IntrospectSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&IntrospectSuite{})
+
+func (*IntrospectSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		agent    string
+		errMatch string
+	}{{
+		errMatch: "no agent specified",
+	}, {
+		args:  []string{"0"},
+		agent: "machine-0",
+	}, {
+		args:  []string{"mysql/0"},
+		agent: "unit-mysql-0",
+	}, {
+		args:  []string{"unit-mysql-0"},
+		agent: "unit-mysql-0",
+	}, {
+		args:     []string{"service-mysql"},
+		errMatch: `invalid agent "service-mysql"`,
+	}, {
+		args:     []string{"0", "1"},
+		errMatch: `unrecognized args: \["1"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		introspectCmd := &IntrospectCommand{}
+		err := testing.InitCommand(introspectCmd, test.args)
+		if test.errMatch != "" {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(introspectCmd.agent, gc.Equals, test.agent)
+		c.Check(introspectCmd.dataDir, gc.Equals, agent.DefaultDataDir)
+	}
+}
+
+func (*IntrospectSuite) TestRun(c *gc.C) {
+	dataDir := c.MkDir()
+	agentDir := agent.Dir(dataDir, "machine-0")
+	err := os.MkdirAll(agentDir, 0755)
+	c.Assert(err, gc.IsNil)
+	w, err := introspection.NewWorker(introspection.Config{
+		Agent:      "machine-0",
+		SocketPath: filepath.Join(agentDir, introspection.SocketFile),
+	})
+	c.Assert(err, gc.IsNil)
+	defer worker.Stop(w)
+
+	ctx, err := testing.RunCommand(c, &IntrospectCommand{}, "--data-dir", dataDir, "0")
+	c.Assert(err, gc.IsNil)
+	var report introspection.Report
+	err = json.Unmarshal([]byte(testing.Stdout(ctx)), &report)
+	c.Assert(err, gc.IsNil)
+	c.Assert(report.Agent, gc.Equals, "machine-0")
+	c.Assert(report.Goroutines, gc.Equals, "")
+}
+
+func (*IntrospectSuite) TestRunNoAgent(c *gc.C) {
+	_, err := testing.RunCommand(c, &IntrospectCommand{}, "--data-dir", c.MkDir(), "0")
+	c.Assert(err, gc.ErrorMatches, `cannot get report from agent "machine-0": .*`)
+}
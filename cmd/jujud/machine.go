@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/container/docker"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
@@ -38,25 +40,32 @@ import (
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/upgrades"
 	"github.com/juju/juju/upstart"
+	"github.com/juju/juju/utils/syslog"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/authenticationworker"
+	"github.com/juju/juju/worker/certupdater"
 	"github.com/juju/juju/worker/charmrevisionworker"
+	"github.com/juju/juju/worker/charmupgrader"
 	"github.com/juju/juju/worker/cleaner"
 	"github.com/juju/juju/worker/deployer"
 	"github.com/juju/juju/worker/firewaller"
 	"github.com/juju/juju/worker/instancepoller"
+	"github.com/juju/juju/worker/instancetagger"
+	"github.com/juju/juju/worker/interruptionwatcher"
 	"github.com/juju/juju/worker/localstorage"
 	workerlogger "github.com/juju/juju/worker/logger"
 	"github.com/juju/juju/worker/machineenvironmentworker"
 	"github.com/juju/juju/worker/machiner"
 	"github.com/juju/juju/worker/minunitsworker"
+	"github.com/juju/juju/worker/passwordrotator"
 	"github.com/juju/juju/worker/peergrouper"
 	"github.com/juju/juju/worker/provisioner"
 	"github.com/juju/juju/worker/resumer"
 	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/singular"
+	"github.com/juju/juju/worker/storageprovisioner"
 	"github.com/juju/juju/worker/terminationworker"
 	"github.com/juju/juju/worker/upgrader"
 )
@@ -73,6 +82,7 @@ type eitherState interface{}
 var (
 	retryDelay      = 3 * time.Second
 	jujuRun         = "/usr/local/bin/juju-run"
+	rsyslogConfDir  = "/etc/rsyslog.d"
 	useMultipleCPUs = utils.UseMultipleCPUs
 
 	// The following are defined as variables to
@@ -82,6 +92,7 @@ var (
 	ensureMongoAdminUser     = mongo.EnsureAdminUser
 	newSingularRunner        = singular.New
 	peergrouperNew           = peergrouper.New
+	restartRsyslog           = syslog.Restart
 
 	// reportOpenedAPI is exposed for tests to know when
 	// the State has been successfully opened.
@@ -107,6 +118,11 @@ type MachineAgent struct {
 
 	mongoInitMutex   sync.Mutex
 	mongoInitialized bool
+
+	// upgradeWaitReason describes what a state server's upgrade
+	// is waiting for, if anything.
+	upgradeWaitMutex  sync.Mutex
+	upgradeWaitReason string
 }
 
 // Info returns usage information for the command.
@@ -181,6 +197,9 @@ func (a *MachineAgent) Run(_ *cmd.Context) error {
 	a.runner.StartWorker("termination", func() (worker.Worker, error) {
 		return terminationworker.NewWorker(), nil
 	})
+	a.runner.StartWorker("introspection", func() (worker.Worker, error) {
+		return newIntrospectionWorker(a, a.runner)
+	})
 	// At this point, all workers will have been configured to start
 	close(a.workersStarted)
 	err := a.runner.Wait()
@@ -309,6 +328,12 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 	a.startWorkerAfterUpgrade(runner, "apiaddressupdater", func() (worker.Worker, error) {
 		return apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), a), nil
 	})
+	a.startWorkerAfterUpgrade(runner, "cacertupdater", func() (worker.Worker, error) {
+		return certupdater.NewCACertUpdater(st.Agent(), a), nil
+	})
+	a.startWorkerAfterUpgrade(runner, "passwordrotator", func() (worker.Worker, error) {
+		return passwordrotator.NewPasswordRotator(st.Agent(), entity, a), nil
+	})
 	a.startWorkerAfterUpgrade(runner, "logger", func() (worker.Worker, error) {
 		return workerlogger.NewLogger(st.Logger(), agentConfig), nil
 	})
@@ -345,6 +370,9 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 				return deployer.NewDeployer(apiDeployer, context), nil
 			})
 		case params.JobManageEnviron:
+			a.startWorkerAfterUpgrade(runner, "stateservinginfoupdater", func() (worker.Worker, error) {
+				return certupdater.NewStateServingInfoUpdater(st.Agent(), a), nil
+			})
 			a.startWorkerAfterUpgrade(singularRunner, "environ-provisioner", func() (worker.Worker, error) {
 				return provisioner.NewEnvironProvisioner(st.Provisioner(), agentConfig), nil
 			})
@@ -383,6 +411,14 @@ func (a *MachineAgent) setupContainerSupport(runner worker.Runner, st *api.State
 	if err == nil && supportsKvm {
 		supportedContainers = append(supportedContainers, instance.KVM)
 	}
+	// Docker cannot run inside an lxc or docker container.
+	switch entity.ContainerType() {
+	case instance.LXC, instance.DOCKER:
+	default:
+		if docker.IsDockerSupported() {
+			supportedContainers = append(supportedContainers, instance.DOCKER)
+		}
+	}
 	return a.updateSupportedContainers(runner, st, entity.Tag(), supportedContainers, agentConfig)
 }
 
@@ -526,6 +562,18 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 			a.startWorkerAfterUpgrade(singularRunner, "minunitsworker", func() (worker.Worker, error) {
 				return minunitsworker.NewMinUnitsWorker(st), nil
 			})
+			a.startWorkerAfterUpgrade(singularRunner, "charmupgrader", func() (worker.Worker, error) {
+				return charmupgrader.NewCharmUpgrader(st), nil
+			})
+			a.startWorkerAfterUpgrade(singularRunner, "storageprovisioner", func() (worker.Worker, error) {
+				return storageprovisioner.NewStorageProvisioner(st), nil
+			})
+			a.startWorkerAfterUpgrade(singularRunner, "instancetagger", func() (worker.Worker, error) {
+				return instancetagger.NewInstanceTagger(st), nil
+			})
+			a.startWorkerAfterUpgrade(singularRunner, "interruptionwatcher", func() (worker.Worker, error) {
+				return interruptionwatcher.NewInterruptionWatcher(st), nil
+			})
 		case state.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
 		default:
@@ -555,6 +603,9 @@ func (a *MachineAgent) limitLoginsDuringUpgrade(creds params.Creds) error {
 				return nil // allow logins from the local machine
 			}
 		}
+		if reason := a.getUpgradeWaitReason(); reason != "" {
+			return errors.Errorf("login for %q blocked because upgrade is in progress (%s)", authTag, reason)
+		}
 		return errors.Errorf("login for %q blocked because upgrade is in progress", authTag)
 	}
 }
@@ -801,8 +852,10 @@ func (a *MachineAgent) upgradeWorker(
 			}
 			defer st.Close()
 		}
-		err := a.runUpgrades(st, apiState, jobs, agentConfig)
-		if err != nil {
+		err := a.runUpgrades(stop, st, apiState, jobs, agentConfig)
+		if err == errUpgradeStopped {
+			return nil
+		} else if err != nil {
 			return err
 		}
 		logger.Infof("upgrade to %v completed.", version.Current)
@@ -815,7 +868,10 @@ func (a *MachineAgent) upgradeWorker(
 var upgradesPerformUpgrade = upgrades.PerformUpgrade // Allow patching for tests
 
 // runUpgrades runs the upgrade operations for each job type and updates the updatedToVersion on success.
+// A state server first waits for all the state servers to reach the current version; the one that
+// takes the database upgrade lock runs the database upgrade steps before the others run their steps.
 func (a *MachineAgent) runUpgrades(
+	stop <-chan struct{},
 	st *state.State,
 	apiState *api.State,
 	jobs []params.MachineJob,
@@ -825,27 +881,58 @@ func (a *MachineAgent) runUpgrades(
 	from.Number = agentConfig.UpgradedToVersion()
 	if from == version.Current {
 		logger.Infof("upgrade to %v already completed.", version.Current)
+		recordUpgradeCompleted(apiState.Agent(), version.Current.Number)
 		return nil
 	}
+	var info *state.UpgradeInfo
+	var targets []upgrades.Target
+	if st != nil {
+		var isMaster bool
+		var err error
+		info, isMaster, err = a.waitForStateServers(stop, st, from.Number)
+		if err != nil {
+			return err
+		}
+		if isMaster {
+			targets = append(targets, upgrades.DatabaseMaster)
+		}
+	}
+	for _, job := range jobs {
+		if target := upgradeTarget(job); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	progress := newUpgradeProgress(apiState.Agent(), from.Number, version.Current.Number, targets)
 	var err error
 	writeErr := a.ChangeConfig(func(agentConfig agent.ConfigSetter) {
 		context := upgrades.NewContext(agentConfig, apiState, st)
-		for _, job := range jobs {
-			target := upgradeTarget(job)
-			if target == "" {
-				continue
-			}
+		for _, target := range targets {
 			logger.Infof("starting upgrade from %v to %v for %v %q", from, version.Current, target, a.Tag())
-			if err = upgradesPerformUpgrade(from.Number, target, context); err != nil {
+			if err = upgradesPerformUpgrade(from.Number, target, context, progress); err != nil {
 				err = fmt.Errorf("cannot perform upgrade from %v to %v for %v %q: %v", from, version.Current, target, a.Tag(), err)
 				return
 			}
+			if target == upgrades.DatabaseMaster {
+				// Let the other state servers run their steps.
+				if err = info.SetDatabaseUpgradeDone(a.MachineId); err != nil {
+					return
+				}
+			}
+		}
+		if info != nil {
+			if err = info.SetStateServerDone(a.MachineId); err != nil {
+				return
+			}
 		}
 		agentConfig.SetUpgradedToVersion(version.Current.Number)
 	})
 	if writeErr != nil {
 		return fmt.Errorf("cannot write updated agent configuration: %v", writeErr)
 	}
+	if err != nil {
+		return err
+	}
+	progress.complete()
 	return nil
 }
 
@@ -859,6 +946,18 @@ func upgradeTarget(job params.MachineJob) upgrades.Target {
 	return ""
 }
 
+// StateServingInfo satisfies worker/certupdater/StateServingInfoSetter.
+func (a *MachineAgent) StateServingInfo() (params.StateServingInfo, bool) {
+	return a.CurrentConfig().StateServingInfo()
+}
+
+// SetStateServingInfo satisfies worker/certupdater/StateServingInfoSetter.
+func (a *MachineAgent) SetStateServingInfo(info params.StateServingInfo) error {
+	return a.ChangeConfig(func(config agent.ConfigSetter) {
+		config.SetStateServingInfo(info)
+	})
+}
+
 // WorkersStarted returns a channel that's closed once all top level workers
 // have been started. This is provided for testing purposes.
 func (a *MachineAgent) WorkersStarted() <-chan struct{} {
@@ -900,6 +999,12 @@ func (a *MachineAgent) uninstallAgent(agentConfig agent.Config) error {
 	if err := mongo.RemoveService(namespace); err != nil {
 		errors = append(errors, fmt.Errorf("cannot stop/remove mongo service with namespace %q: %v", namespace, err))
 	}
+	// Manually provisioned machines have a nonce prefixed with
+	// "manual:". They outlive the agent, so everything else juju
+	// installed on them is removed too.
+	if strings.HasPrefix(agentConfig.Nonce(), "manual:") {
+		errors = append(errors, uninstallManualMachine(agentServiceName)...)
+	}
 	if err := os.RemoveAll(agentConfig.DataDir()); err != nil {
 		errors = append(errors, err)
 	}
@@ -909,6 +1014,42 @@ func (a *MachineAgent) uninstallAgent(agentConfig agent.Config) error {
 	return fmt.Errorf("uninstall failed: %v", errors)
 }
 
+// uninstallManualMachine removes the upstart jobs of any unit agents
+// left on the machine, and juju's rsyslog configuration. The machine
+// agent's own job, named by agentServiceName, is left to the caller.
+func uninstallManualMachine(agentServiceName string) []error {
+	var errors []error
+	confs, err := filepath.Glob(filepath.Join(upstart.InitDir, "jujud-*.conf"))
+	if err != nil {
+		errors = append(errors, err)
+	}
+	for _, conf := range confs {
+		name := strings.TrimSuffix(filepath.Base(conf), ".conf")
+		if name == agentServiceName {
+			continue
+		}
+		logger.Infof("removing service %q", name)
+		if err := upstart.NewService(name).StopAndRemove(); err != nil {
+			errors = append(errors, fmt.Errorf("cannot stop/remove service %q: %v", name, err))
+		}
+	}
+	rsyslogConfs, err := filepath.Glob(filepath.Join(rsyslogConfDir, "*juju*"))
+	if err != nil {
+		errors = append(errors, err)
+	}
+	for _, conf := range rsyslogConfs {
+		if err := os.Remove(conf); err != nil && !os.IsNotExist(err) {
+			errors = append(errors, err)
+		}
+	}
+	if len(rsyslogConfs) > 0 {
+		if err := restartRsyslog(); err != nil {
+			errors = append(errors, fmt.Errorf("cannot restart rsyslog: %v", err))
+		}
+	}
+	return errors
+}
+
 // singularAPIConn implements singular.Conn on
 // top of an API connection.
 type singularAPIConn struct {
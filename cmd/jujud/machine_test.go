@@ -49,6 +49,7 @@ import (
 	"github.com/juju/juju/worker/authenticationworker"
 	"github.com/juju/juju/worker/deployer"
 	"github.com/juju/juju/worker/instancepoller"
+	"github.com/juju/juju/worker/introspection"
 	"github.com/juju/juju/worker/machineenvironmentworker"
 	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/singular"
@@ -260,6 +261,36 @@ func (s *MachineSuite) TestDyingMachine(c *gc.C) {
 	c.Assert(m.Life(), gc.Equals, state.Dead)
 }
 
+func (s *MachineSuite) TestIntrospectionReportsNestedWorkers(c *gc.C) {
+	m, _, _ := s.primeAgent(c, version.Current, state.JobHostUnits)
+	a := s.newAgent(c, m)
+	go func() { c.Check(a.Run(nil), gc.IsNil) }()
+	defer func() { c.Check(a.Stop(), gc.IsNil) }()
+
+	// The workers run on the API connection are wrapped so that the
+	// connection is closed when they stop; the report must still
+	// descend into them.
+	socketPath := filepath.Join(a.CurrentConfig().Dir(), introspection.SocketFile)
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		report, err := introspection.Query(socketPath, introspection.ReportArgs{})
+		if err != nil {
+			c.Logf("cannot query agent: %v", err)
+			continue
+		}
+		for _, status := range report.Workers {
+			if status.Id != "api" {
+				continue
+			}
+			for _, nested := range status.Workers {
+				if nested.Id == "upgrader" {
+					return
+				}
+			}
+		}
+	}
+	c.Fatalf("upgrader never reported under the api worker")
+}
+
 func (s *MachineSuite) TestHostUnits(c *gc.C) {
 	m, _, _ := s.primeAgent(c, version.Current, state.JobHostUnits)
 	a := s.newAgent(c, m)
@@ -398,11 +429,15 @@ func (s *MachineSuite) TestManageEnviron(c *gc.C) {
 
 	c.Assert(s.singularRecord.started(), jc.DeepEquals, []string{
 		"charm-revision-updater",
+		"charmupgrader",
 		"cleaner",
 		"environ-provisioner",
 		"firewaller",
+		"instancetagger",
+		"interruptionwatcher",
 		"minunitsworker",
 		"resumer",
+		"storageprovisioner",
 	})
 }
 
@@ -907,6 +942,40 @@ func (s *MachineSuite) TestMachineAgentUninstall(c *gc.C) {
 	c.Assert(err, jc.Satisfies, os.IsNotExist)
 }
 
+func (s *MachineSuite) TestUninstallManualMachine(c *gc.C) {
+	for _, name := range []string{"jujud-machine-1", "jujud-unit-wordpress-0", "other"} {
+		err := ioutil.WriteFile(filepath.Join(upstart.InitDir, name+".conf"), nil, 0644)
+		c.Assert(err, gc.IsNil)
+	}
+	confDir := c.MkDir()
+	s.agentSuite.PatchValue(&rsyslogConfDir, confDir)
+	for _, name := range []string{"25-juju.conf", "26-juju-unit-wordpress-0.conf", "50-default.conf"} {
+		err := ioutil.WriteFile(filepath.Join(confDir, name), nil, 0644)
+		c.Assert(err, gc.IsNil)
+	}
+	restarted := false
+	s.agentSuite.PatchValue(&restartRsyslog, func() error {
+		restarted = true
+		return nil
+	})
+
+	errs := uninstallManualMachine("jujud-machine-1")
+	c.Assert(errs, gc.HasLen, 0)
+
+	// The unit agent's job is removed; the machine agent's job is
+	// left to the caller, and other jobs are left alone.
+	confs, err := filepath.Glob(filepath.Join(upstart.InitDir, "*.conf"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(confs, jc.SameContents, []string{
+		filepath.Join(upstart.InitDir, "jujud-machine-1.conf"),
+		filepath.Join(upstart.InitDir, "other.conf"),
+	})
+	confs, err = filepath.Glob(filepath.Join(confDir, "*"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(confs, gc.DeepEquals, []string{filepath.Join(confDir, "50-default.conf")})
+	c.Assert(restarted, jc.IsTrue)
+}
+
 func (s *MachineSuite) TestMachineAgentRsyslogManageEnviron(c *gc.C) {
 	s.testMachineAgentRsyslogConfigWorker(c, state.JobManageEnviron, rsyslog.RsyslogModeAccumulate)
 }
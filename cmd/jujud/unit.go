@@ -18,7 +18,9 @@ import (
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/apiaddressupdater"
+	"github.com/juju/juju/worker/certupdater"
 	workerlogger "github.com/juju/juju/worker/logger"
+	"github.com/juju/juju/worker/passwordrotator"
 	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/uniter"
 	"github.com/juju/juju/worker/upgrader"
@@ -77,6 +79,9 @@ func (a *UnitAgent) Run(ctx *cmd.Context) error {
 	}
 	agentLogger.Infof("unit agent %v start (%s [%s])", a.Tag().String(), version.Current, runtime.Compiler)
 	a.runner.StartWorker("api", a.APIWorkers)
+	a.runner.StartWorker("introspection", func() (worker.Worker, error) {
+		return newIntrospectionWorker(a, a.runner)
+	})
 	err := agentDone(a.runner.Wait())
 	a.tomb.Kill(err)
 	return err
@@ -109,11 +114,17 @@ func (a *UnitAgent) APIWorkers() (worker.Worker, error) {
 		return workerlogger.NewLogger(st.Logger(), agentConfig), nil
 	})
 	runner.StartWorker("uniter", func() (worker.Worker, error) {
-		return uniter.NewUniter(st.Uniter(), entity.Tag(), dataDir, hookLock), nil
+		return uniter.NewUniter(st.Uniter(), entity.Tag(), dataDir, agentConfig.APIInfo(), hookLock), nil
 	})
 	runner.StartWorker("apiaddressupdater", func() (worker.Worker, error) {
 		return apiaddressupdater.NewAPIAddressUpdater(st.Uniter(), a), nil
 	})
+	runner.StartWorker("cacertupdater", func() (worker.Worker, error) {
+		return certupdater.NewCACertUpdater(st.Agent(), a), nil
+	})
+	runner.StartWorker("passwordrotator", func() (worker.Worker, error) {
+		return passwordrotator.NewPasswordRotator(st.Agent(), entity, a), nil
+	})
 	runner.StartWorker("rsyslog", func() (worker.Worker, error) {
 		return newRsyslogConfigWorker(st.Rsyslog(), agentConfig, rsyslog.RsyslogModeForwarding)
 	})
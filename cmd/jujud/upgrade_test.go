@@ -4,9 +4,11 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"time"
 
 	jc "github.com/juju/testing/checkers"
@@ -15,8 +17,10 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/upgrades"
 	"github.com/juju/juju/version"
@@ -55,6 +59,8 @@ func (s *UpgradeSuite) SetUpTest(c *gc.C) {
 func (s *UpgradeSuite) TestUpgradeStepsStateServer(c *gc.C) {
 	s.assertUpgradeSteps(c, state.JobManageEnviron)
 	s.assertStateServerUpgrades(c)
+	s.assertUpgradeInfoCompleted(c)
+	s.assertUpgradeProgressCompleted(c)
 }
 
 func (s *UpgradeSuite) TestUpgradeStepsHostMachine(c *gc.C) {
@@ -72,7 +78,11 @@ func (s *UpgradeSuite) TestClientLoginsOnlyDuringUpgrade(c *gc.C) {
 	// Override the main upgrade entry point so that the test can
 	// control when upgrades start and finish.
 	upgradeCh := make(chan bool)
-	fakePerformUpgrade := func(_ version.Number, _ upgrades.Target, _ upgrades.Context) error {
+	fakePerformUpgrade := func(_ version.Number, target upgrades.Target, _ upgrades.Context, _ upgrades.Progress) error {
+		if target != upgrades.StateServer {
+			// The agent also runs the database upgrade steps.
+			return nil
+		}
 		upgradeCh <- true // signal that upgrade has started
 		<-upgradeCh       // wait for signal that upgrades should finish
 		return nil
@@ -117,7 +127,7 @@ func (s *UpgradeSuite) TestClientLoginsOnlyDuringUpgrade(c *gc.C) {
 func (s *UpgradeSuite) TestUpgradeSkippedIfNoUpgradeRequired(c *gc.C) {
 	attemptCount := 0
 	upgradeCh := make(chan bool)
-	fakePerformUpgrade := func(_ version.Number, _ upgrades.Target, _ upgrades.Context) error {
+	fakePerformUpgrade := func(_ version.Number, _ upgrades.Target, _ upgrades.Context, _ upgrades.Progress) error {
 		// Note: this shouldn't run.
 		attemptCount++
 		// If execution ends up here, wait so it can be detected (by
@@ -155,6 +165,150 @@ func (s *UpgradeSuite) TestUpgradeSkippedIfNoUpgradeRequired(c *gc.C) {
 	c.Assert(a.CurrentConfig().UpgradedToVersion(), gc.Equals, version.Current.Number)
 }
 
+func (s *UpgradeSuite) TestStateServersWaitForEachOther(c *gc.C) {
+	s.PatchValue(&upgradeBarrierPollInterval, coretesting.ShortWait)
+	machines := s.addStateServers(c, 2)
+	from := version.MustParse("1.20.0")
+	a := &MachineAgent{
+		MachineId:       machines[0].Id(),
+		upgradeComplete: make(chan struct{}),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	done := s.startWaitingForStateServers(c, a, stop, from)
+
+	expectReason := fmt.Sprintf("waiting for state servers %s to reach version %v", machines[1].Id(), version.Current.Number)
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		if a.getUpgradeWaitReason() != "" {
+			break
+		}
+	}
+	c.Assert(a.getUpgradeWaitReason(), gc.Equals, expectReason)
+	err := a.limitLoginsDuringUpgrade(params.Creds{AuthTag: "machine-42"})
+	c.Assert(err, gc.ErrorMatches, regexp.QuoteMeta(
+		fmt.Sprintf(`login for "machine-42" blocked because upgrade is in progress (%s)`, expectReason),
+	))
+
+	_, err = s.State.EnsureUpgradeInfo(machines[1].Id(), from, version.Current.Number)
+	c.Assert(err, gc.IsNil)
+	select {
+	case err := <-done:
+		c.Assert(err, gc.IsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("state server did not stop waiting")
+	}
+	c.Assert(a.getUpgradeWaitReason(), gc.Equals, "")
+	info, err := s.State.UpgradeInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.DatabaseMaster(), gc.Equals, machines[0].Id())
+}
+
+func (s *UpgradeSuite) addStateServers(c *gc.C, n int) []*state.Machine {
+	var machines []*state.Machine
+	for i := 0; i < n; i++ {
+		m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+		c.Assert(err, gc.IsNil)
+		err = m.SetProvisioned(instance.Id("i-"+m.Id()), "fake_nonce", nil)
+		c.Assert(err, gc.IsNil)
+		machines = append(machines, m)
+	}
+	return machines
+}
+
+// startWaitingForStateServers runs waitForStateServers for the given
+// machine, returning a channel that receives its error, or an error if
+// the database upgrade lock was not acquired.
+func (s *UpgradeSuite) startWaitingForStateServers(c *gc.C, a *MachineAgent, stop <-chan struct{}, from version.Number) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, isMaster, err := a.waitForStateServers(stop, s.State, from)
+		if err == nil && !isMaster {
+			err = fmt.Errorf("database upgrade lock not acquired")
+		}
+		done <- err
+	}()
+	return done
+}
+
+func (s *UpgradeSuite) TestStateServersDoNotWaitForDownStateServers(c *gc.C) {
+	s.PatchValue(&upgradeBarrierPollInterval, coretesting.ShortWait)
+	s.PatchValue(&upgradeBarrierTimeout, coretesting.ShortWait)
+	machines := s.addStateServers(c, 2)
+	a := &MachineAgent{
+		MachineId:       machines[0].Id(),
+		upgradeComplete: make(chan struct{}),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	done := s.startWaitingForStateServers(c, a, stop, version.MustParse("1.20.0"))
+
+	// The agent of the other state server never comes up.
+	select {
+	case err := <-done:
+		c.Assert(err, gc.IsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("state server did not stop waiting")
+	}
+	info, err := s.State.UpgradeInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.DatabaseMaster(), gc.Equals, machines[0].Id())
+	c.Assert(info.StateServersReady(), jc.DeepEquals, []string{machines[0].Id()})
+}
+
+func (s *UpgradeSuite) TestStateServerTakesOverStaleDatabaseLock(c *gc.C) {
+	s.PatchValue(&upgradeBarrierPollInterval, coretesting.ShortWait)
+	s.PatchValue(&upgradeBarrierTimeout, coretesting.ShortWait)
+	machines := s.addStateServers(c, 2)
+	from := version.MustParse("1.20.0")
+	info, err := s.State.EnsureUpgradeInfo(machines[1].Id(), from, version.Current.Number)
+	c.Assert(err, gc.IsNil)
+	acquired, err := info.AcquireDatabaseLock(machines[1].Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(acquired, jc.IsTrue)
+	pinger, err := machines[1].SetAgentPresence()
+	c.Assert(err, gc.IsNil)
+	defer pinger.Stop()
+	s.State.StartSync()
+	err = machines[1].WaitAgentPresence(coretesting.LongWait)
+	c.Assert(err, gc.IsNil)
+
+	a := &MachineAgent{
+		MachineId:       machines[0].Id(),
+		upgradeComplete: make(chan struct{}),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	done := s.startWaitingForStateServers(c, a, stop, from)
+
+	// The lock is not taken from a holder that is alive.
+	expectReason := fmt.Sprintf("waiting for state server %s to upgrade the database", machines[1].Id())
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		if a.getUpgradeWaitReason() != "" {
+			break
+		}
+	}
+	c.Assert(a.getUpgradeWaitReason(), gc.Equals, expectReason)
+	select {
+	case err := <-done:
+		c.Fatalf("state server stopped waiting for a live lock holder: %v", err)
+	case <-time.After(10 * coretesting.ShortWait):
+	}
+
+	err = pinger.Kill()
+	c.Assert(err, gc.IsNil)
+	s.State.StartSync()
+	select {
+	case err := <-done:
+		c.Assert(err, gc.IsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("state server did not take over the database upgrade lock")
+	}
+	err = info.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Status(), gc.Equals, state.UpgradeRunning)
+	c.Assert(info.DatabaseMaster(), gc.Equals, machines[0].Id())
+}
+
 func waitForUpgradeToStart(upgradeCh chan bool) bool {
 	select {
 	case <-upgradeCh:
@@ -245,6 +399,32 @@ func (s *UpgradeSuite) waitForUpgradeToFinish(c *gc.C) {
 	c.Assert(success, jc.IsTrue)
 }
 
+func (s *UpgradeSuite) assertUpgradeInfoCompleted(c *gc.C) {
+	info, err := s.State.UpgradeInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.TargetVersion(), gc.Equals, s.upgradeToVersion.Number)
+	c.Assert(info.Status(), gc.Equals, state.UpgradeComplete)
+	c.Assert(info.DatabaseMaster(), gc.Equals, s.machine0.Id())
+	c.Assert(info.StateServersDone(), jc.DeepEquals, []string{s.machine0.Id()})
+}
+
+func (s *UpgradeSuite) assertUpgradeProgressCompleted(c *gc.C) {
+	var progress state.UpgradeProgress
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		var err error
+		progress, err = s.State.UpgradeProgress(s.machine0.Tag().String())
+		if err == nil && progress.Completed {
+			break
+		}
+	}
+	c.Assert(progress.Completed, jc.IsTrue)
+	c.Assert(progress.ToVersion, gc.Equals, s.upgradeToVersion.Number)
+	c.Assert(progress.Steps, gc.Not(gc.HasLen), 0)
+	for _, step := range progress.Steps {
+		c.Check(step.Status, gc.Equals, params.UpgradeStepDone)
+	}
+}
+
 func (s *UpgradeSuite) canLoginToAPIAsUser(c *gc.C) bool {
 	info := s.machine0Config.APIInfo()
 	defaultInfo := s.APIInfo(c)
@@ -0,0 +1,173 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/version"
+)
+
+// upgradeBarrierPollInterval holds how often a state server waiting
+// for the other state servers to reach the new version checks them,
+// besides when the upgrade info changes, as the set of state servers
+// may change too.
+var upgradeBarrierPollInterval = 5 * time.Second
+
+// upgradeBarrierTimeout holds how long a state server waits for
+// another state server whose agent is down, either to reach the new
+// version or to finish the database upgrade steps, before going on
+// without it.
+var upgradeBarrierTimeout = 10 * time.Minute
+
+// errUpgradeStopped is returned when the upgrade worker is stopped
+// while waiting for the other state servers.
+var errUpgradeStopped = stderrors.New("upgrade stopped")
+
+// waitForStateServers records that this state server has reached the
+// current version and waits until all the provisioned state servers
+// have, so that they do not run different versions against the same
+// database. It returns the coordinated upgrade, and whether this state
+// server holds the database upgrade lock, in which case it must run the
+// database upgrade steps; otherwise the database has been upgraded by
+// another state server when it returns.
+//
+// State servers whose agents have been down for upgradeBarrierTimeout
+// are not waited for, and the database upgrade lock is taken over from
+// a holder that has been down for as long.
+func (a *MachineAgent) waitForStateServers(stop <-chan struct{}, st *state.State, from version.Number) (*state.UpgradeInfo, bool, error) {
+	info, err := st.EnsureUpgradeInfo(a.MachineId, from, version.Current.Number)
+	if err != nil {
+		return nil, false, err
+	}
+	defer a.setUpgradeWaitReason("")
+	w := info.Watch()
+	defer w.Stop()
+	// downSince records when each state server being waited for was
+	// first seen down.
+	downSince := make(map[string]time.Time)
+	for {
+		if err := info.Refresh(); err != nil {
+			return nil, false, err
+		}
+		var reason string
+		switch info.Status() {
+		case state.UpgradePending:
+			pending, err := info.PendingStateServers()
+			if err != nil {
+				return nil, false, err
+			}
+			pending, err = excludeDownStateServers(st, pending, downSince)
+			if err != nil {
+				return nil, false, err
+			}
+			if len(pending) == 0 {
+				acquired, err := info.AcquireDatabaseLock(a.MachineId)
+				if err != nil {
+					return nil, false, err
+				}
+				if acquired {
+					logger.Infof("all state servers have reached %v; upgrading the database", info.TargetVersion())
+					return info, true, nil
+				}
+				continue
+			}
+			reason = fmt.Sprintf("waiting for state servers %s to reach version %v",
+				strings.Join(pending, ", "), info.TargetVersion())
+		case state.UpgradeRunning:
+			if info.DatabaseMaster() == a.MachineId {
+				// We took the lock before being restarted.
+				return info, true, nil
+			}
+			master, err := excludeDownStateServers(st, []string{info.DatabaseMaster()}, downSince)
+			if err != nil {
+				return nil, false, err
+			}
+			if len(master) == 0 {
+				logger.Warningf("state server %s holding the database upgrade lock is down; taking over the lock", info.DatabaseMaster())
+				acquired, err := info.TakeOverDatabaseLock(a.MachineId)
+				if err != nil {
+					return nil, false, err
+				}
+				if acquired {
+					return info, true, nil
+				}
+				continue
+			}
+			reason = fmt.Sprintf("waiting for state server %s to upgrade the database", info.DatabaseMaster())
+		case state.UpgradeAborted:
+			// The upgrader will switch the agent back to its
+			// previous tools.
+			return nil, false, fmt.Errorf("upgrade to %v has been aborted", info.TargetVersion())
+		default:
+			return info, false, nil
+		}
+		logger.Infof("%s", reason)
+		a.setUpgradeWaitReason(reason)
+		select {
+		case <-stop:
+			return nil, false, errUpgradeStopped
+		case _, ok := <-w.Changes():
+			if !ok {
+				return nil, false, watcher.MustErr(w)
+			}
+		case <-time.After(upgradeBarrierPollInterval):
+		}
+	}
+}
+
+// excludeDownStateServers returns the given state servers, less those
+// whose agents have been down for at least upgradeBarrierTimeout, and
+// updates downSince with the times the state servers were first seen
+// down.
+func excludeDownStateServers(st *state.State, ids []string, downSince map[string]time.Time) ([]string, error) {
+	var up []string
+	for _, id := range ids {
+		alive := false
+		m, err := st.Machine(id)
+		if err == nil {
+			alive, err = m.AgentPresence()
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if alive {
+			delete(downSince, id)
+			up = append(up, id)
+			continue
+		}
+		since, ok := downSince[id]
+		if !ok {
+			since = time.Now()
+			downSince[id] = since
+		}
+		if time.Since(since) < upgradeBarrierTimeout {
+			up = append(up, id)
+			continue
+		}
+		logger.Warningf("state server %s has been down since %v; not waiting for it", id, since)
+	}
+	return up, nil
+}
+
+// setUpgradeWaitReason records why the upgrade is waiting, so that
+// logins refused during the upgrade can report it.
+func (a *MachineAgent) setUpgradeWaitReason(reason string) {
+	a.upgradeWaitMutex.Lock()
+	defer a.upgradeWaitMutex.Unlock()
+	a.upgradeWaitReason = reason
+}
+
+func (a *MachineAgent) getUpgradeWaitReason() string {
+	a.upgradeWaitMutex.Lock()
+	defer a.upgradeWaitMutex.Unlock()
+	return a.upgradeWaitReason
+}
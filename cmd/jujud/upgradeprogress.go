@@ -0,0 +1,117 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/upgrades"
+	"github.com/juju/juju/version"
+)
+
+// upgradeProgressRecorder is implemented by the agent API facade; it
+// records the progress of the agent's upgrade in state.
+type upgradeProgressRecorder interface {
+	UpgradeProgress() (params.UpgradeProgress, error)
+	SetUpgradeProgress(progress params.UpgradeProgress) error
+}
+
+// upgradeProgress implements upgrades.Progress by recording the
+// status of each upgrade step in state, so that "juju upgrade-juju"
+// can report it. Failures to record progress are logged rather than
+// returned, as they should not stop the upgrade.
+type upgradeProgress struct {
+	recorder upgradeProgressRecorder
+	progress params.UpgradeProgress
+	skip     set.Strings
+}
+
+// newUpgradeProgress returns an upgradeProgress that records the
+// progress of the upgrade from the "from" version to the "to" version
+// on the given targets. All the steps that will be run are recorded as
+// pending. The steps the user has asked to skip are those recorded for
+// an earlier attempt at the same upgrade.
+func newUpgradeProgress(recorder upgradeProgressRecorder, from, to version.Number, targets []upgrades.Target) *upgradeProgress {
+	p := &upgradeProgress{
+		recorder: recorder,
+		progress: params.UpgradeProgress{ToVersion: to},
+		skip:     set.NewStrings(),
+	}
+	if current, err := recorder.UpgradeProgress(); err != nil {
+		logger.Warningf("cannot get upgrade progress: %v", err)
+	} else if current.ToVersion == to {
+		p.skip = set.NewStrings(current.Skip...)
+	}
+	for _, target := range targets {
+		for _, step := range upgrades.PendingSteps(from, to, target) {
+			p.progress.Steps = append(p.progress.Steps, params.UpgradeStep{
+				Target:      string(target),
+				Description: step.Description(),
+				Status:      params.UpgradeStepPending,
+			})
+		}
+	}
+	p.save()
+	return p
+}
+
+// SkipStep is defined on the upgrades.Progress interface.
+func (p *upgradeProgress) SkipStep(target upgrades.Target, step upgrades.Step) bool {
+	return p.skip.Contains(step.Description())
+}
+
+// SetStepStatus is defined on the upgrades.Progress interface.
+func (p *upgradeProgress) SetStepStatus(target upgrades.Target, step upgrades.Step, status params.UpgradeStepStatus, err error) {
+	entry := params.UpgradeStep{
+		Target:      string(target),
+		Description: step.Description(),
+		Status:      status,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	found := false
+	for i, s := range p.progress.Steps {
+		if s.Target == entry.Target && s.Description == entry.Description {
+			p.progress.Steps[i] = entry
+			found = true
+			break
+		}
+	}
+	if !found {
+		p.progress.Steps = append(p.progress.Steps, entry)
+	}
+	p.save()
+}
+
+// complete records that the upgrade has completed.
+func (p *upgradeProgress) complete() {
+	p.progress.Completed = true
+	p.save()
+}
+
+func (p *upgradeProgress) save() {
+	if err := p.recorder.SetUpgradeProgress(p.progress); err != nil {
+		logger.Warningf("cannot record upgrade progress: %v", err)
+	}
+}
+
+// recordUpgradeCompleted records that the agent has completed its
+// upgrade to the given version, if that has not been recorded already.
+// It is used when the agent finds it has no upgrade to perform.
+func recordUpgradeCompleted(recorder upgradeProgressRecorder, to version.Number) {
+	current, err := recorder.UpgradeProgress()
+	if err != nil {
+		logger.Warningf("cannot get upgrade progress: %v", err)
+		return
+	}
+	if current.ToVersion == to && current.Completed {
+		return
+	}
+	progress := params.UpgradeProgress{ToVersion: to, Completed: true}
+	if err := recorder.SetUpgradeProgress(progress); err != nil {
+		logger.Warningf("cannot record upgrade progress: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/upgrades"
+	"github.com/juju/juju/version"
+)
+
+type upgradeProgressSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&upgradeProgressSuite{})
+
+type fakeUpgradeProgressRecorder struct {
+	current params.UpgradeProgress
+	saved   []params.UpgradeProgress
+}
+
+func (r *fakeUpgradeProgressRecorder) UpgradeProgress() (params.UpgradeProgress, error) {
+	return r.current, nil
+}
+
+func (r *fakeUpgradeProgressRecorder) SetUpgradeProgress(progress params.UpgradeProgress) error {
+	progress.Steps = append([]params.UpgradeStep(nil), progress.Steps...)
+	r.saved = append(r.saved, progress)
+	return nil
+}
+
+type fakeUpgradeStep string
+
+func (step fakeUpgradeStep) Description() string                { return string(step) }
+func (step fakeUpgradeStep) Targets() []upgrades.Target         { return nil }
+func (step fakeUpgradeStep) Run(context upgrades.Context) error { return nil }
+
+func (s *upgradeProgressSuite) TestUpgradeProgress(c *gc.C) {
+	to := version.MustParse("1.21.0")
+	recorder := &fakeUpgradeProgressRecorder{
+		current: params.UpgradeProgress{
+			ToVersion: to,
+			Skip:      []string{"step 2"},
+		},
+	}
+	p := newUpgradeProgress(recorder, version.MustParse("1.20.0"), to, nil)
+	c.Assert(recorder.saved, gc.HasLen, 1)
+	c.Assert(p.SkipStep(upgrades.StateServer, fakeUpgradeStep("step 1")), jc.IsFalse)
+	c.Assert(p.SkipStep(upgrades.StateServer, fakeUpgradeStep("step 2")), jc.IsTrue)
+
+	p.SetStepStatus(upgrades.StateServer, fakeUpgradeStep("step 1"), params.UpgradeStepRunning, nil)
+	p.SetStepStatus(upgrades.StateServer, fakeUpgradeStep("step 1"), params.UpgradeStepFailed, errors.New("boom"))
+	c.Assert(recorder.saved, gc.HasLen, 3)
+	c.Assert(recorder.saved[2], jc.DeepEquals, params.UpgradeProgress{
+		ToVersion: to,
+		Steps: []params.UpgradeStep{{
+			Target:      "stateServer",
+			Description: "step 1",
+			Status:      params.UpgradeStepFailed,
+			Error:       "boom",
+		}},
+	})
+
+	p.complete()
+	c.Assert(recorder.saved[3].Completed, jc.IsTrue)
+}
+
+func (s *upgradeProgressSuite) TestUpgradeProgressForgetsSkipsOfOtherVersions(c *gc.C) {
+	recorder := &fakeUpgradeProgressRecorder{
+		current: params.UpgradeProgress{
+			ToVersion: version.MustParse("1.20.0"),
+			Skip:      []string{"step 2"},
+		},
+	}
+	p := newUpgradeProgress(recorder, version.MustParse("1.20.0"), version.MustParse("1.21.0"), nil)
+	c.Assert(p.SkipStep(upgrades.StateServer, fakeUpgradeStep("step 2")), jc.IsFalse)
+}
+
+func (s *upgradeProgressSuite) TestRecordUpgradeCompleted(c *gc.C) {
+	to := version.MustParse("1.21.0")
+	recorder := &fakeUpgradeProgressRecorder{}
+	recordUpgradeCompleted(recorder, to)
+	c.Assert(recorder.saved, jc.DeepEquals, []params.UpgradeProgress{{
+		ToVersion: to,
+		Completed: true,
+	}})
+
+	recorder = &fakeUpgradeProgressRecorder{current: recorder.saved[0]}
+	recordUpgradeCompleted(recorder, to)
+	c.Assert(recorder.saved, gc.HasLen, 0)
+}
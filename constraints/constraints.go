@@ -29,6 +29,7 @@ const (
 	Tags         = "tags"
 	InstanceType = "instance-type"
 	Networks     = "networks"
+	SpotPrice    = "spot-price"
 )
 
 // Value describes a user's requirements of the hardware on which units
@@ -78,6 +79,11 @@ type Value struct {
 	// negative values are accepted, and the difference is the latter
 	// have a "^" prefix to the name.
 	Networks *[]string `json:"networks,omitempty" yaml:"networks,omitempty"`
+
+	// SpotPrice, if not nil, indicates that the machine should be a
+	// spot instance, bid for at no more than the given hourly price in
+	// US dollars. Only valid for clouds which support spot instances.
+	SpotPrice *string `json:"spot-price,omitempty" yaml:"spot-price,omitempty"`
 }
 
 // fieldNames records a mapping from the constraint tag to struct field name.
@@ -115,6 +121,11 @@ func (v *Value) HasInstanceType() bool {
 	return v.InstanceType != nil && *v.InstanceType != ""
 }
 
+// HasSpotPrice returns true if the constraints.Value specifies a spot price.
+func (v *Value) HasSpotPrice() bool {
+	return v.SpotPrice != nil && *v.SpotPrice != ""
+}
+
 // extractNetworks returns the list of networks to include or exclude
 // (without the "^" prefixes).
 func (v *Value) extractNetworks() (include, exclude []string) {
@@ -191,6 +202,9 @@ func (v Value) String() string {
 		s := strings.Join(*v.Networks, ",")
 		strs = append(strs, "networks="+s)
 	}
+	if v.SpotPrice != nil {
+		strs = append(strs, "spot-price="+*v.SpotPrice)
+	}
 	return strings.Join(strs, " ")
 }
 
@@ -330,6 +344,8 @@ func (v *Value) setRaw(raw string) error {
 		err = v.setInstanceType(str)
 	case Networks:
 		err = v.setNetworks(str)
+	case SpotPrice:
+		err = v.setSpotPrice(str)
 	default:
 		return fmt.Errorf("unknown constraint %q", name)
 	}
@@ -376,6 +392,8 @@ func (v *Value) SetYAML(tag string, value interface{}) bool {
 			if err == nil {
 				err = v.validateNetworks(networks)
 			}
+		case SpotPrice:
+			v.SpotPrice, err = parseSpotPrice(vstr)
 		default:
 			return false
 		}
@@ -467,6 +485,14 @@ func (v *Value) setTags(str string) error {
 	return nil
 }
 
+func (v *Value) setSpotPrice(str string) (err error) {
+	if v.SpotPrice != nil {
+		return fmt.Errorf("already set")
+	}
+	v.SpotPrice, err = parseSpotPrice(str)
+	return
+}
+
 func (v *Value) setNetworks(str string) error {
 	if v.Networks != nil {
 		return fmt.Errorf("already set")
@@ -506,6 +532,18 @@ func parseUint64(str string) (*uint64, error) {
 	return &value, nil
 }
 
+// parseSpotPrice checks that str is a positive decimal price, and
+// returns it unchanged so that no precision is lost.
+func parseSpotPrice(str string) (*string, error) {
+	if str != "" {
+		val, err := strconv.ParseFloat(str, 64)
+		if err != nil || val <= 0 || strings.ContainsAny(str, "eE") {
+			return nil, fmt.Errorf("must be a positive decimal number")
+		}
+	}
+	return &str, nil
+}
+
 func parseSize(str string) (*uint64, error) {
 	var value uint64
 	if str != "" {
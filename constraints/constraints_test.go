@@ -289,6 +289,31 @@ var parseConstraintsTests = []struct {
 		args:    []string{"instance-type="},
 	},
 
+	// spot price
+	{
+		summary: "set spot price",
+		args:    []string{"spot-price=0.05"},
+	}, {
+		summary: "spot price empty",
+		args:    []string{"spot-price="},
+	}, {
+		summary: "set nonsense spot price 1",
+		args:    []string{"spot-price=cheap"},
+		err:     `bad "spot-price" constraint: must be a positive decimal number`,
+	}, {
+		summary: "set nonsense spot price 2",
+		args:    []string{"spot-price=0"},
+		err:     `bad "spot-price" constraint: must be a positive decimal number`,
+	}, {
+		summary: "set nonsense spot price 3",
+		args:    []string{"spot-price=5e-2"},
+		err:     `bad "spot-price" constraint: must be a positive decimal number`,
+	}, {
+		summary: "double set spot price",
+		args:    []string{"spot-price=0.05", "spot-price=0.1"},
+		err:     `bad "spot-price" constraint: already set`,
+	},
+
 	// Everything at once.
 	{
 		summary: "kitchen sink together",
@@ -461,6 +486,8 @@ var constraintsRoundtripTests = []roundTrip{
 	{"Networks3", constraints.Value{Networks: &[]string{"net1", "^net2"}}},
 	{"InstanceType1", constraints.Value{InstanceType: strp("")}},
 	{"InstanceType2", constraints.Value{InstanceType: strp("foo")}},
+	{"SpotPrice1", constraints.Value{SpotPrice: strp("")}},
+	{"SpotPrice2", constraints.Value{SpotPrice: strp("0.05")}},
 	{"All", constraints.Value{
 		Arch:         strp("i386"),
 		Container:    ctypep("lxc"),
@@ -548,6 +575,13 @@ func (s *ConstraintsSuite) TestHasInstanceType(c *gc.C) {
 	c.Check(cons.HasInstanceType(), jc.IsTrue)
 }
 
+func (s *ConstraintsSuite) TestHasSpotPrice(c *gc.C) {
+	cons := constraints.MustParse("spot-price=")
+	c.Check(cons.HasSpotPrice(), jc.IsFalse)
+	cons = constraints.MustParse("arch=amd64 spot-price=0.05")
+	c.Check(cons.HasSpotPrice(), jc.IsTrue)
+}
+
 const initialWithoutCons = "root-disk=8G mem=4G arch=amd64 cpu-power=1000 cpu-cores=4 networks=net1,^net2 tags=foo container=lxc instance-type=bar"
 
 var withoutTests = []struct {
@@ -0,0 +1,167 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package container
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/juju/utils"
+	goyaml "gopkg.in/yaml.v1"
+)
+
+// allocationFile is the name of the file, in a container's directory,
+// that records the host resources allocated to the container.
+const allocationFile = "allocation.yaml"
+
+// Resources describes an amount of host memory and CPU.
+type Resources struct {
+	// Mem is the amount of memory in megabytes.
+	Mem uint64 `yaml:"mem,omitempty"`
+
+	// CpuCores is the number of CPU cores.
+	CpuCores uint64 `yaml:"cpu-cores,omitempty"`
+}
+
+func (r Resources) String() string {
+	return fmt.Sprintf("mem=%dM cpu-cores=%d", r.Mem, r.CpuCores)
+}
+
+// DefaultAllocation holds the host resources charged for a container
+// whose constraints do not specify its memory or CPU cores; it matches
+// the size of a KVM container started without constraints.
+var DefaultAllocation = Resources{Mem: 512, CpuCores: 1}
+
+// WithDefaults returns r with any unspecified resource taken from
+// DefaultAllocation.
+func (r Resources) WithDefaults() Resources {
+	if r.Mem == 0 {
+		r.Mem = DefaultAllocation.Mem
+	}
+	if r.CpuCores == 0 {
+		r.CpuCores = DefaultAllocation.CpuCores
+	}
+	return r
+}
+
+// HostResources returns the memory and CPU cores of the host machine.
+// It is a variable so that it can be replaced in tests.
+var HostResources = func() (Resources, error) {
+	mem, err := hostMemory("/proc/meminfo")
+	if err != nil {
+		return Resources{}, err
+	}
+	return Resources{Mem: mem, CpuCores: uint64(runtime.NumCPU())}, nil
+}
+
+// hostMemory returns the total memory, in megabytes, reported in the
+// given meminfo file.
+func hostMemory(meminfo string) (uint64, error) {
+	f, err := os.Open(meminfo)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// The line is of the form "MemTotal: NNN kB".
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kB, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemTotal %q", fields[1])
+		}
+		return kB / 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("MemTotal not found in %s", meminfo)
+}
+
+// SetAllocation records the host resources allocated to the container
+// with the given name in its directory, which must exist. Resources
+// left unspecified are recorded as in DefaultAllocation. Allocations
+// are forgotten when the directory is removed.
+func SetAllocation(containerName string, allocation Resources) error {
+	data, err := goyaml.Marshal(allocation.WithDefaults())
+	if err != nil {
+		return err
+	}
+	return utils.AtomicWriteFile(filepath.Join(dirForName(containerName), allocationFile), data, 0644)
+}
+
+// Allocated returns the total host resources allocated to all the
+// containers, of any type, on this machine.
+func Allocated() (Resources, error) {
+	var total Resources
+	dirs, err := ioutil.ReadDir(ContainerDir)
+	if os.IsNotExist(err) {
+		return total, nil
+	} else if err != nil {
+		return total, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(ContainerDir, dir.Name(), allocationFile))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return total, err
+		}
+		var allocation Resources
+		if err := goyaml.Unmarshal(data, &allocation); err != nil {
+			return total, fmt.Errorf("invalid allocation for container %q: %v", dir.Name(), err)
+		}
+		total.Mem += allocation.Mem
+		total.CpuCores += allocation.CpuCores
+	}
+	return total, nil
+}
+
+// CheckCapacity returns an error if allocating the requested resources
+// to a new container would take the total allocated to the containers
+// on this machine beyond the machine's own resources multiplied by the
+// given overcommit ratio. Resources left unspecified in the request are
+// charged as in DefaultAllocation. A ratio of zero or less disables the
+// check.
+func CheckCapacity(request Resources, ratio float64) error {
+	if ratio <= 0 {
+		return nil
+	}
+	request = request.WithDefaults()
+	host, err := HostResources()
+	if err != nil {
+		return fmt.Errorf("cannot determine host resources: %v", err)
+	}
+	allocated, err := Allocated()
+	if err != nil {
+		return fmt.Errorf("cannot determine allocated resources: %v", err)
+	}
+	logger.Debugf("host resources %v, allocated %v, requested %v", host, allocated, request)
+	check := func(what, unit string, requested, allocated, available uint64) error {
+		limit := uint64(float64(available) * ratio)
+		if allocated+requested <= limit {
+			return nil
+		}
+		return fmt.Errorf(
+			"insufficient host capacity: %d%s %s requested, %d%s of %d%s already allocated (overcommit ratio %v)",
+			requested, unit, what, allocated, unit, limit, unit, ratio,
+		)
+	}
+	if err := check("memory", "M", request.Mem, allocated.Mem, host.Mem); err != nil {
+		return err
+	}
+	return check("cpu cores", "", request.CpuCores, allocated.CpuCores, host.CpuCores)
+}
@@ -0,0 +1,120 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package container_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/testing"
+)
+
+type CapacitySuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&CapacitySuite{})
+
+func (s *CapacitySuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(&container.ContainerDir, c.MkDir())
+	s.PatchValue(&container.HostResources, func() (container.Resources, error) {
+		return container.Resources{Mem: 4096, CpuCores: 4}, nil
+	})
+}
+
+func (s *CapacitySuite) allocate(c *gc.C, name string, allocation container.Resources) {
+	_, err := container.NewDirectory(name)
+	c.Assert(err, gc.IsNil)
+	err = container.SetAllocation(name, allocation)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *CapacitySuite) TestAllocated(c *gc.C) {
+	allocated, err := container.Allocated()
+	c.Assert(err, gc.IsNil)
+	c.Assert(allocated, gc.Equals, container.Resources{})
+
+	s.allocate(c, "one", container.Resources{Mem: 1024, CpuCores: 1})
+	// Unspecified resources are recorded as in the default allocation.
+	s.allocate(c, "two", container.Resources{Mem: 512})
+	// Containers without a recorded allocation are not counted.
+	_, err = container.NewDirectory("three")
+	c.Assert(err, gc.IsNil)
+
+	allocated, err = container.Allocated()
+	c.Assert(err, gc.IsNil)
+	c.Assert(allocated, gc.Equals, container.Resources{Mem: 1536, CpuCores: 2})
+}
+
+func (s *CapacitySuite) TestAllocationRemovedWithDirectory(c *gc.C) {
+	s.PatchValue(&container.RemovedContainerDir, c.MkDir())
+	s.allocate(c, "one", container.Resources{Mem: 1024, CpuCores: 1})
+	err := container.RemoveDirectory("one")
+	c.Assert(err, gc.IsNil)
+
+	allocated, err := container.Allocated()
+	c.Assert(err, gc.IsNil)
+	c.Assert(allocated, gc.Equals, container.Resources{})
+}
+
+func (s *CapacitySuite) TestCheckCapacity(c *gc.C) {
+	s.allocate(c, "one", container.Resources{Mem: 3072, CpuCores: 2})
+
+	err := container.CheckCapacity(container.Resources{Mem: 1024, CpuCores: 2}, 1)
+	c.Assert(err, gc.IsNil)
+	err = container.CheckCapacity(container.Resources{Mem: 2048}, 1)
+	c.Assert(err, gc.ErrorMatches, `insufficient host capacity: 2048M memory requested, 3072M of 4096M already allocated \(overcommit ratio 1\)`)
+	err = container.CheckCapacity(container.Resources{CpuCores: 3}, 1)
+	c.Assert(err, gc.ErrorMatches, `insufficient host capacity: 3 cpu cores requested, 2 of 4 already allocated \(overcommit ratio 1\)`)
+
+	// Overcommitting is allowed up to the ratio.
+	err = container.CheckCapacity(container.Resources{Mem: 2048, CpuCores: 3}, 1.5)
+	c.Assert(err, gc.IsNil)
+	err = container.CheckCapacity(container.Resources{Mem: 4096}, 1.5)
+	c.Assert(err, gc.ErrorMatches, `insufficient host capacity: 4096M memory requested, 3072M of 6144M already allocated \(overcommit ratio 1.5\)`)
+
+	// Containers without limits are charged the default allocation.
+	err = container.CheckCapacity(container.Resources{}, 1)
+	c.Assert(err, gc.IsNil)
+	s.allocate(c, "two", container.Resources{CpuCores: 2})
+	err = container.CheckCapacity(container.Resources{}, 1)
+	c.Assert(err, gc.ErrorMatches, `insufficient host capacity: 1 cpu cores requested, 4 of 4 already allocated \(overcommit ratio 1\)`)
+}
+
+func (s *CapacitySuite) TestCheckCapacityDisabled(c *gc.C) {
+	s.PatchValue(&container.HostResources, func() (container.Resources, error) {
+		c.Fatalf("host resources should not be checked")
+		return container.Resources{}, nil
+	})
+	err := container.CheckCapacity(container.Resources{Mem: 1 << 20}, 0)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *CapacitySuite) TestHostMemory(c *gc.C) {
+	meminfo := filepath.Join(c.MkDir(), "meminfo")
+	err := ioutil.WriteFile(meminfo, []byte("MemTotal:        8167848 kB\nMemFree:         1234567 kB\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	mem, err := container.HostMemory(meminfo)
+	c.Assert(err, gc.IsNil)
+	c.Assert(mem, gc.Equals, uint64(7976))
+}
+
+func (s *CapacitySuite) TestParseOvercommitRatio(c *gc.C) {
+	ratio, err := container.ParseOvercommitRatio(container.ManagerConfig{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(ratio, gc.Equals, 0.0)
+
+	conf := container.ManagerConfig{container.ConfigOvercommitRatio: "1.5"}
+	ratio, err = container.ParseOvercommitRatio(conf)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ratio, gc.Equals, 1.5)
+	c.Assert(conf, gc.HasLen, 0)
+
+	_, err = container.ParseOvercommitRatio(container.ManagerConfig{container.ConfigOvercommitRatio: "lots"})
+	c.Assert(err, gc.ErrorMatches, `invalid overcommit-ratio "lots"`)
+}
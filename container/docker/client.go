@@ -0,0 +1,115 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+// This file contains wrappers around the docker command line client,
+// found in the docker.io package, which provides Juju's interface to
+// docker containers.
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juju/utils"
+)
+
+// containerInitDir is the directory inside a container where the
+// host's container directory, holding the init script, is mounted.
+const containerInitDir = "/var/lib/juju/container-init"
+
+// run runs the docker client with the given arguments and returns its
+// combined output.
+func run(args ...string) (output string, err error) {
+	logger.Tracef("docker %v", args)
+	output, err = utils.RunCommand("docker", args...)
+	logger.Tracef("output: %v", output)
+	if err != nil {
+		return output, fmt.Errorf("docker %s failed: %v (%s)", args[0], err, strings.TrimSpace(output))
+	}
+	return output, nil
+}
+
+// RunContainer creates and starts a container with the given name from
+// the given image, with the given host directory mounted read-only at
+// containerInitDir, and then runs the init script from that directory
+// in the background. The container is restarted by docker whenever it
+// stops, including when the host reboots.
+func RunContainer(name, image, initDir string) error {
+	if _, err := run(
+		"run", "--detach",
+		"--restart", "always",
+		"--name", name,
+		"--hostname", name,
+		"--volume", initDir+":"+containerInitDir+":ro",
+		image,
+	); err != nil {
+		return err
+	}
+	_, err := run("exec", "--detach", name, "/bin/bash", path.Join(containerInitDir, initScriptName))
+	return err
+}
+
+var versionPattern = regexp.MustCompile(`^Docker version (\d+)\.(\d+)`)
+
+// Version returns the major and minor version of the docker client.
+func Version() (major, minor int, err error) {
+	output, err := run("--version")
+	if err != nil {
+		return 0, 0, err
+	}
+	output = strings.TrimSpace(output)
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, 0, fmt.Errorf("cannot parse docker version from %q", output)
+	}
+	major, _ = strconv.Atoi(match[1])
+	minor, _ = strconv.Atoi(match[2])
+	return major, minor, nil
+}
+
+// RemoveContainer stops and removes the container with the given name.
+func RemoveContainer(name string) error {
+	_, err := run("rm", "--force", name)
+	return err
+}
+
+// ListContainers returns a map from the names of all the containers,
+// running or not, to whether they are running.
+func ListContainers() (map[string]bool, error) {
+	output, err := run("ps", "--all", "--quiet", "--no-trunc")
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Fields(output)
+	result := make(map[string]bool)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	args := append([]string{"inspect", "--format", "{{.Name}} {{.State.Running}}"}, ids...)
+	output, err = run(args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		result[strings.TrimPrefix(fields[0], "/")] = fields[1] == "true"
+	}
+	return result, nil
+}
+
+// inspect returns the value of the given Go template when applied to
+// the details of the container with the given name.
+func inspect(name, format string) (string, error) {
+	output, err := run("inspect", "--format", format, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
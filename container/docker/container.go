@@ -0,0 +1,95 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"fmt"
+	"net"
+)
+
+type dockerContainer struct {
+	factory *containerFactory
+	name    string
+	// started is a three state boolean, true, false, or unknown
+	// this allows for checking when we don't know, but using a
+	// value if we already know it (like in the list situation).
+	started *bool
+}
+
+var _ Container = (*dockerContainer)(nil)
+
+func (c *dockerContainer) Name() string {
+	return c.name
+}
+
+func (c *dockerContainer) Start(params StartParams) error {
+	logger.Debugf("run container %s from %s", c.name, params.Image)
+	if err := RunContainer(c.name, params.Image, params.InitDir); err != nil {
+		return err
+	}
+	c.started = nil
+	return nil
+}
+
+func (c *dockerContainer) Stop() error {
+	// Make started state unknown again.
+	c.started = nil
+	logger.Debugf("remove container %s", c.name)
+	return RemoveContainer(c.name)
+}
+
+func (c *dockerContainer) IsRunning() bool {
+	if c.started != nil {
+		return *c.started
+	}
+	running, err := inspect(c.name, "{{.State.Running}}")
+	if err != nil {
+		return false
+	}
+	started := running == "true"
+	c.started = &started
+	return started
+}
+
+func (c *dockerContainer) Address() (string, error) {
+	address, err := inspect(c.name, "{{.NetworkSettings.IPAddress}}")
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(address) == nil {
+		return "", fmt.Errorf("container %q has no address", c.name)
+	}
+	return address, nil
+}
+
+func (c *dockerContainer) String() string {
+	return fmt.Sprintf("<Docker container %s>", c.name)
+}
+
+type containerFactory struct{}
+
+var _ ContainerFactory = (*containerFactory)(nil)
+
+func (factory *containerFactory) New(name string) Container {
+	return &dockerContainer{
+		factory: factory,
+		name:    name,
+	}
+}
+
+func (factory *containerFactory) List() (result []Container, err error) {
+	containers, err := ListContainers()
+	if err != nil {
+		return nil, err
+	}
+	for name, running := range containers {
+		started := running
+		result = append(result, &dockerContainer{
+			factory: factory,
+			name:    name,
+			started: &started,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/agent"
+	coreCloudinit "github.com/juju/juju/cloudinit"
+	"github.com/juju/juju/cloudinit/sshinit"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/arch"
+	"github.com/juju/juju/version"
+)
+
+var (
+	logger = loggo.GetLogger("juju.container.docker")
+
+	DockerObjectFactory ContainerFactory = &containerFactory{}
+
+	// DefaultImage is the repository of the image that containers are
+	// started from, tagged with the series. Its init process is
+	// upstart, which the machine agent's service requires.
+	DefaultImage = "ubuntu-upstart"
+)
+
+const (
+	// ConfigImage is the name of the container manager setting that
+	// overrides DefaultImage.
+	ConfigImage = "image"
+
+	// initScriptName is the name of the script, in the container's
+	// directory, that bootstraps the machine agent. It does inside the
+	// container what cloud-init does on other machines.
+	initScriptName = "container-init.sh"
+)
+
+// IsDockerSupported reports whether docker containers can be run on
+// this machine. Docker is only supported on amd64 hosts.
+var IsDockerSupported = func() bool {
+	return version.Current.Arch == arch.AMD64
+}
+
+// NewContainerManager returns a manager object that can start and stop
+// docker containers. The containers that are created are namespaced by
+// the name parameter.
+func NewContainerManager(conf container.ManagerConfig) (container.Manager, error) {
+	name := conf.PopValue(container.ConfigName)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	logDir := conf.PopValue(container.ConfigLogDir)
+	if logDir == "" {
+		logDir = agent.DefaultLogDir
+	}
+	image := conf.PopValue(ConfigImage)
+	if image == "" {
+		image = DefaultImage
+	}
+	overcommitRatio, err := container.ParseOvercommitRatio(conf)
+	if err != nil {
+		return nil, err
+	}
+	conf.WarnAboutUnused()
+	return &containerManager{
+		name:            name,
+		logdir:          logDir,
+		image:           image,
+		overcommitRatio: overcommitRatio,
+	}, nil
+}
+
+// containerManager handles all of the business logic at the juju specific
+// level. It makes sure that the necessary directories are in place, and
+// that the init script is written out in the right place.
+type containerManager struct {
+	name            string
+	logdir          string
+	image           string
+	overcommitRatio float64
+}
+
+var _ container.Manager = (*containerManager)(nil)
+
+func (manager *containerManager) CreateContainer(
+	machineConfig *cloudinit.MachineConfig,
+	series string,
+	network *container.NetworkConfig) (instance.Instance, *instance.HardwareCharacteristics, error) {
+
+	name := names.NewMachineTag(machineConfig.MachineId).String()
+	if manager.name != "" {
+		name = fmt.Sprintf("%s-%s", manager.name, name)
+	}
+	// Note here that the DockerObjectFactory only returns a valid
+	// container object, and doesn't actually create the container.
+	dockerContainer := DockerObjectFactory.New(name)
+
+	var allocation container.Resources
+	if cons := machineConfig.Constraints; cons.Mem != nil {
+		allocation.Mem = *cons.Mem
+	}
+	if cons := machineConfig.Constraints; cons.CpuCores != nil {
+		allocation.CpuCores = *cons.CpuCores
+	}
+	if err := container.CheckCapacity(allocation, manager.overcommitRatio); err != nil {
+		return nil, nil, err
+	}
+
+	directory, err := container.NewDirectory(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create container directory: %v", err)
+	}
+	logger.Tracef("write init script")
+	if err := writeInitScript(machineConfig, directory); err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "failed to write init script: %v", err)
+	}
+	if network != nil && network.Device != "" {
+		logger.Debugf("docker containers use the docker bridge, not %q", network.Device)
+	}
+
+	logger.Tracef("create the container")
+	if err := dockerContainer.Start(StartParams{
+		Image:   manager.image + ":" + series,
+		InitDir: directory,
+	}); err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "docker container creation failed: %v", err)
+	}
+	if err := container.SetAllocation(name, allocation); err != nil {
+		return nil, nil, err
+	}
+	logger.Tracef("docker container created")
+	// As for LXC, the resources charged to the container are reported
+	// as its hardware.
+	allocated := allocation.WithDefaults()
+	hardware := instance.HardwareCharacteristics{
+		Arch:     &version.Current.Arch,
+		Mem:      &allocated.Mem,
+		CpuCores: &allocated.CpuCores,
+	}
+	return &dockerInstance{dockerContainer, name}, &hardware, nil
+}
+
+// writeInitScript writes out the script that bootstraps the machine
+// agent inside the container. Docker images do not run cloud-init, so
+// the cloud-init configuration for the machine is rendered as a
+// script instead, as it is for manually provisioned machines.
+func writeInitScript(machineConfig *cloudinit.MachineConfig, directory string) error {
+	cloudConfig := coreCloudinit.New()
+	if err := cloudinit.Configure(machineConfig, cloudConfig); err != nil {
+		return err
+	}
+	// The image is kept up to date by its maintainers.
+	cloudConfig.SetAptUpgrade(false)
+	script, err := sshinit.ConfigureScript(cloudConfig)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(directory, initScriptName), []byte(script), 0755)
+}
+
+func (manager *containerManager) DestroyContainer(id instance.Id) error {
+	name := string(id)
+	dockerContainer := DockerObjectFactory.New(name)
+	if err := dockerContainer.Stop(); err != nil {
+		logger.Errorf("failed to stop docker container: %v", err)
+		return err
+	}
+	return container.RemoveDirectory(name)
+}
+
+func (manager *containerManager) ListContainers() (result []instance.Instance, err error) {
+	containers, err := DockerObjectFactory.List()
+	if err != nil {
+		logger.Errorf("failed getting all instances: %v", err)
+		return
+	}
+	managerPrefix := fmt.Sprintf("%s-", manager.name)
+	for _, container := range containers {
+		// Filter out those not starting with our name.
+		name := container.Name()
+		if !strings.HasPrefix(name, managerPrefix) {
+			continue
+		}
+		if container.IsRunning() {
+			result = append(result, &dockerInstance{container, name})
+		}
+	}
+	return
+}
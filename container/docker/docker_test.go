@@ -0,0 +1,227 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/docker"
+	dockertesting "github.com/juju/juju/container/docker/testing"
+	containertesting "github.com/juju/juju/container/testing"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type DockerSuite struct {
+	dockertesting.TestSuite
+	manager container.Manager
+}
+
+var _ = gc.Suite(&DockerSuite{})
+
+func (s *DockerSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	var err error
+	s.manager, err = docker.NewContainerManager(container.ManagerConfig{container.ConfigName: "test"})
+	c.Assert(err, gc.IsNil)
+}
+
+func (*DockerSuite) TestManagerNameNeeded(c *gc.C) {
+	manager, err := docker.NewContainerManager(container.ManagerConfig{container.ConfigName: ""})
+	c.Assert(err, gc.ErrorMatches, "name is required")
+	c.Assert(manager, gc.IsNil)
+}
+
+func (*DockerSuite) TestManagerWarnsAboutUnknownOption(c *gc.C) {
+	_, err := docker.NewContainerManager(container.ManagerConfig{
+		container.ConfigName: "BillyBatson",
+		"shazam":             "Captain Marvel",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(c.GetTestLog(), jc.Contains, `WARNING juju.container unused config option: "shazam" -> "Captain Marvel"`)
+}
+
+func (s *DockerSuite) TestCreateContainerOvercommitted(c *gc.C) {
+	s.PatchValue(&container.HostResources, func() (container.Resources, error) {
+		return container.Resources{Mem: 2048, CpuCores: 4}, nil
+	})
+	manager, err := docker.NewContainerManager(container.ManagerConfig{
+		container.ConfigName:            "test",
+		container.ConfigOvercommitRatio: "1",
+	})
+	c.Assert(err, gc.IsNil)
+	machineConfig := containertesting.MockMachineConfig("1/docker/0")
+	machineConfig.Constraints = constraints.MustParse("mem=1536M")
+	_, hardware, err := manager.CreateContainer(machineConfig, "trusty", nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(*hardware.Mem, gc.Equals, uint64(1536))
+	c.Assert(*hardware.CpuCores, gc.Equals, uint64(1))
+	allocated, err := container.Allocated()
+	c.Assert(err, gc.IsNil)
+	c.Assert(allocated, gc.Equals, container.Resources{Mem: 1536, CpuCores: 1})
+
+	// A container without constraints is charged the default memory,
+	// which no longer fits.
+	machineConfig = containertesting.MockMachineConfig("1/docker/1")
+	_, _, err = manager.CreateContainer(machineConfig, "trusty", nil)
+	c.Assert(err, gc.ErrorMatches, `insufficient host capacity: 512M memory requested, 1536M of 2048M already allocated \(overcommit ratio 1\)`)
+}
+
+func (s *DockerSuite) createRunningContainer(c *gc.C, name string) docker.Container {
+	dockerContainer := s.ContainerFactory.New(name)
+	c.Assert(dockerContainer.Start(docker.StartParams{Image: "ubuntu-upstart:trusty"}), gc.IsNil)
+	return dockerContainer
+}
+
+func (s *DockerSuite) TestListMatchesManagerNameAndRunning(c *gc.C) {
+	s.createRunningContainer(c, "test-match1")
+	s.createRunningContainer(c, "test-match2")
+	s.createRunningContainer(c, "testNoMatch")
+	s.createRunningContainer(c, "other")
+	s.ContainerFactory.New("test-stopped")
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(containers, gc.HasLen, 2)
+	ids := []instance.Id{containers[0].Id(), containers[1].Id()}
+	c.Assert(ids, jc.SameContents, []instance.Id{"test-match1", "test-match2"})
+}
+
+func (s *DockerSuite) TestCreateContainer(c *gc.C) {
+	inst := containertesting.CreateContainer(c, s.manager, "1/docker/0")
+	name := string(inst.Id())
+	c.Assert(name, gc.Equals, "test-machine-1-docker-0")
+
+	script := filepath.Join(s.ContainerDir, name, "container-init.sh")
+	c.Assert(script, jc.IsNonEmptyFile)
+	info, err := os.Stat(script)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Mode().Perm(), gc.Equals, os.FileMode(0755))
+	data, err := ioutil.ReadFile(script)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), jc.HasPrefix, "#!/bin/bash\n")
+	c.Assert(string(data), jc.Contains, "jujud-machine-1-docker-0")
+
+	addrs, err := inst.Addresses()
+	c.Assert(err, gc.IsNil)
+	c.Assert(addrs, gc.DeepEquals, []network.Address{
+		network.NewAddress("172.17.0.2", network.ScopeCloudLocal),
+	})
+}
+
+func (s *DockerSuite) TestDestroyContainer(c *gc.C) {
+	inst := containertesting.CreateContainer(c, s.manager, "1/docker/0")
+
+	err := s.manager.DestroyContainer(inst.Id())
+	c.Assert(err, gc.IsNil)
+
+	name := string(inst.Id())
+	c.Assert(filepath.Join(s.ContainerDir, name), jc.DoesNotExist)
+	c.Assert(filepath.Join(s.RemovedDir, name), jc.IsDirectory)
+}
+
+type ClientSuite struct {
+	coretesting.BaseSuite
+	logFile string
+}
+
+var _ = gc.Suite(&ClientSuite{})
+
+// fakeDocker records its arguments, one invocation per line, and
+// answers the queries that the client makes.
+const fakeDocker = `#!/bin/bash
+echo "$@" >> %s
+case "$1" in
+--version) echo "Docker version ${FAKE_DOCKER_VERSION:-1.3.0}, build c78088f";;
+ps) echo abc123; echo def456;;
+inspect)
+    case "$3" in
+    "{{.Name}} {{.State.Running}}") echo "/juju-machine-1-docker-0 true"; echo "/other false";;
+    "{{.NetworkSettings.IPAddress}}") echo 172.17.0.5;;
+    "{{.State.Running}}") echo true;;
+    esac;;
+esac
+`
+
+func (s *ClientSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	dir := c.MkDir()
+	s.logFile = filepath.Join(dir, "docker.log")
+	script := strings.Replace(fakeDocker, "%s", s.logFile, 1)
+	err := ioutil.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755)
+	c.Assert(err, gc.IsNil)
+	s.PatchEnvironment("PATH", dir+":"+os.Getenv("PATH"))
+}
+
+func (s *ClientSuite) calls(c *gc.C) []string {
+	data, err := ioutil.ReadFile(s.logFile)
+	c.Assert(err, gc.IsNil)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func (s *ClientSuite) TestStartStop(c *gc.C) {
+	dockerContainer := docker.DockerObjectFactory.New("juju-machine-1-docker-0")
+	err := dockerContainer.Start(docker.StartParams{
+		Image:   "ubuntu-upstart:trusty",
+		InitDir: "/var/lib/juju/containers/juju-machine-1-docker-0",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(dockerContainer.IsRunning(), jc.IsTrue)
+	address, err := dockerContainer.Address()
+	c.Assert(err, gc.IsNil)
+	c.Assert(address, gc.Equals, "172.17.0.5")
+	err = dockerContainer.Stop()
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(s.calls(c), gc.DeepEquals, []string{
+		"run --detach --restart always --name juju-machine-1-docker-0 --hostname juju-machine-1-docker-0" +
+			" --volume /var/lib/juju/containers/juju-machine-1-docker-0:/var/lib/juju/container-init:ro" +
+			" ubuntu-upstart:trusty",
+		"exec --detach juju-machine-1-docker-0 /bin/bash /var/lib/juju/container-init/container-init.sh",
+		"inspect --format {{.State.Running}} juju-machine-1-docker-0",
+		"inspect --format {{.NetworkSettings.IPAddress}} juju-machine-1-docker-0",
+		"rm --force juju-machine-1-docker-0",
+	})
+}
+
+func (s *ClientSuite) TestList(c *gc.C) {
+	containers, err := docker.DockerObjectFactory.List()
+	c.Assert(err, gc.IsNil)
+	running := make(map[string]bool)
+	for _, container := range containers {
+		running[container.Name()] = container.IsRunning()
+	}
+	c.Assert(running, gc.DeepEquals, map[string]bool{
+		"juju-machine-1-docker-0": true,
+		"other":                   false,
+	})
+	c.Assert(s.calls(c), gc.DeepEquals, []string{
+		"ps --all --quiet --no-trunc",
+		"inspect --format {{.Name}} {{.State.Running}} abc123 def456",
+	})
+}
+
+func (s *ClientSuite) TestVerifyVersion(c *gc.C) {
+	err := docker.VerifyVersion()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.calls(c), gc.DeepEquals, []string{"--version"})
+
+	s.PatchEnvironment("FAKE_DOCKER_VERSION", "1.10.1")
+	err = docker.VerifyVersion()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *ClientSuite) TestVerifyVersionTooOld(c *gc.C) {
+	s.PatchEnvironment("FAKE_DOCKER_VERSION", "0.9.1")
+	err := docker.VerifyVersion()
+	c.Assert(err, gc.ErrorMatches, `docker 0.9 is installed, but docker 1.3 or later is required`)
+}
@@ -0,0 +1,59 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"fmt"
+
+	"github.com/juju/utils/apt"
+
+	"github.com/juju/juju/container"
+)
+
+var requiredPackages = []string{
+	"docker.io",
+}
+
+// The earliest docker release that can run juju's containers. Earlier
+// releases have neither "docker exec" nor restart policies.
+const (
+	minimumMajorVersion = 1
+	minimumMinorVersion = 3
+)
+
+// VerifyVersion returns an error if the installed docker is too old to
+// run juju's containers. It is a variable so that it can be replaced
+// in tests.
+var VerifyVersion = func() error {
+	major, minor, err := Version()
+	if err != nil {
+		return err
+	}
+	if major < minimumMajorVersion || major == minimumMajorVersion && minor < minimumMinorVersion {
+		return fmt.Errorf(
+			"docker %d.%d is installed, but docker %d.%d or later is required",
+			major, minor, minimumMajorVersion, minimumMinorVersion,
+		)
+	}
+	return nil
+}
+
+type containerInitialiser struct{}
+
+// containerInitialiser implements container.Initialiser.
+var _ container.Initialiser = (*containerInitialiser)(nil)
+
+// NewContainerInitialiser returns an instance used to perform the steps
+// required to allow a host machine to run a docker container.
+func NewContainerInitialiser() container.Initialiser {
+	return &containerInitialiser{}
+}
+
+// Initialise is specified on the container.Initialiser interface.
+func (ci *containerInitialiser) Initialise() error {
+	if err := apt.GetInstall(requiredPackages...); err != nil {
+		return err
+	}
+	return VerifyVersion()
+}
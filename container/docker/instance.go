@@ -0,0 +1,63 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+type dockerInstance struct {
+	container Container
+	id        string
+}
+
+var _ instance.Instance = (*dockerInstance)(nil)
+
+// Id implements instance.Instance.Id.
+func (docker *dockerInstance) Id() instance.Id {
+	return instance.Id(docker.id)
+}
+
+// Status implements instance.Instance.Status.
+func (docker *dockerInstance) Status() string {
+	if docker.container.IsRunning() {
+		return "running"
+	}
+	return "stopped"
+}
+
+func (*dockerInstance) Refresh() error {
+	return nil
+}
+
+func (docker *dockerInstance) Addresses() ([]network.Address, error) {
+	address, err := docker.container.Address()
+	if err != nil {
+		return nil, err
+	}
+	return []network.Address{network.NewAddress(address, network.ScopeCloudLocal)}, nil
+}
+
+// OpenPorts implements instance.Instance.OpenPorts.
+func (docker *dockerInstance) OpenPorts(machineId string, ports []network.Port) error {
+	return fmt.Errorf("not implemented")
+}
+
+// ClosePorts implements instance.Instance.ClosePorts.
+func (docker *dockerInstance) ClosePorts(machineId string, ports []network.Port) error {
+	return fmt.Errorf("not implemented")
+}
+
+// Ports implements instance.Instance.Ports.
+func (docker *dockerInstance) Ports(machineId string) ([]network.Port, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// Add a string representation of the id.
+func (docker *dockerInstance) String() string {
+	return fmt.Sprintf("docker:%s", docker.id)
+}
@@ -0,0 +1,50 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+// StartParams is a simple parameter struct for Container.Start.
+type StartParams struct {
+	// Image is the image, including its tag, that the container is
+	// started from.
+	Image string
+
+	// InitDir is the host directory holding the script that
+	// bootstraps the machine agent inside the container.
+	InitDir string
+}
+
+// Container represents a docker container and provides operations to
+// create, maintain and destroy the container.
+type Container interface {
+
+	// Name returns the name of the container.
+	Name() string
+
+	// Start creates and runs the container, and starts the
+	// bootstrapping of the machine agent inside it.
+	Start(params StartParams) error
+
+	// Stop terminates and removes the container.
+	Stop() error
+
+	// IsRunning returns whether or not the container is running.
+	IsRunning() bool
+
+	// Address returns the container's address on the docker bridge.
+	Address() (string, error)
+
+	// String returns information about the container.
+	String() string
+}
+
+// ContainerFactory represents the methods used to create Containers. This
+// wraps the docker command line client.
+type ContainerFactory interface {
+	// New returns a container instance which can then be used for operations
+	// like Start() and Stop()
+	New(string) Container
+
+	// List returns all the existing containers on the system.
+	List() ([]Container, error)
+}
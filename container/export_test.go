@@ -8,3 +8,5 @@ package container
 func IsLocked(lock *Lock) bool {
 	return lock.lockFile != nil
 }
+
+var HostMemory = hostMemory
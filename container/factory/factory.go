@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/docker"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/instance"
@@ -22,6 +23,8 @@ func NewContainerManager(forType instance.ContainerType, conf container.ManagerC
 		return lxc.NewContainerManager(conf)
 	case instance.KVM:
 		return kvm.NewContainerManager(conf)
+	case instance.DOCKER:
+		return docker.NewContainerManager(conf)
 	}
 	return nil, fmt.Errorf("unknown container type: %q", forType)
 }
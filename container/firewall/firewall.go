@@ -0,0 +1,243 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package firewall restricts inbound access to containers on a host
+// to the ports that have been opened on them, using iptables.
+//
+// Each container has its own chain, to which traffic forwarded over
+// the bridge to the container's address is sent. The chain accepts
+// established connections, connections from other containers on the
+// same bridge and connections to the container's open ports, and
+// drops everything else.
+package firewall
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/network"
+)
+
+var logger = loggo.GetLogger("juju.container.firewall")
+
+const (
+	chainPrefix = "juju-"
+
+	// maxChainLen is the maximum length of an iptables chain name.
+	maxChainLen = 28
+)
+
+// runIPTables runs iptables with the given arguments and returns its
+// combined output. It is a variable so that it can be replaced in
+// tests.
+var runIPTables = func(args ...string) (string, error) {
+	logger.Tracef("iptables %v", args)
+	return utils.RunCommand("iptables", args...)
+}
+
+// ChainName returns the name of the iptables chain holding the rules
+// for the container with the given name.
+func ChainName(container string) string {
+	name := chainPrefix + container
+	if len(name) <= maxChainLen {
+		return name
+	}
+	sum := fmt.Sprintf("%x", sha1.Sum([]byte(container)))
+	return chainPrefix + sum[:maxChainLen-len(chainPrefix)]
+}
+
+// Init sets up the firewall for the container with the given name and
+// address, attached to the given bridge, so that all inbound
+// connections to it from outside the bridge are dropped until ports
+// are opened on it. It should be called when the container starts.
+func Init(container, bridge, address string) error {
+	chain := ChainName(container)
+	if err := ensureChain(chain, bridge); err != nil {
+		return fmt.Errorf("cannot create firewall chain for %q: %v", container, err)
+	}
+	if err := ensureJump(chain, bridge, address); err != nil {
+		return fmt.Errorf("cannot direct traffic for %q to firewall chain: %v", container, err)
+	}
+	return nil
+}
+
+// OpenPorts opens the given ports on the container with the given
+// name and address, attached to the given bridge. All other inbound
+// connections to the container from outside the bridge are dropped.
+func OpenPorts(container, bridge, address string, ports []network.Port) error {
+	if err := Init(container, bridge, address); err != nil {
+		return err
+	}
+	chain := ChainName(container)
+	for _, port := range ports {
+		rule := portRule(chain, port)
+		if _, err := runIPTables(append([]string{"-C"}, rule...)...); err == nil {
+			continue
+		}
+		// Insert the rule at the head of the chain, ahead of the
+		// final rule that drops everything else.
+		args := append([]string{"-I", chain, "1"}, rule[1:]...)
+		if _, err := runIPTables(args...); err != nil {
+			return fmt.Errorf("cannot open port %v on %q: %v", port, container, err)
+		}
+	}
+	return nil
+}
+
+// ClosePorts closes the given ports on the container with the given
+// name.
+func ClosePorts(container string, ports []network.Port) error {
+	chain := ChainName(container)
+	if _, exists, err := listRules(chain); err != nil {
+		return err
+	} else if !exists {
+		return nil
+	}
+	for _, port := range ports {
+		rule := portRule(chain, port)
+		if _, err := runIPTables(append([]string{"-C"}, rule...)...); err != nil {
+			continue
+		}
+		if _, err := runIPTables(append([]string{"-D"}, rule...)...); err != nil {
+			return fmt.Errorf("cannot close port %v on %q: %v", port, container, err)
+		}
+	}
+	return nil
+}
+
+// Ports returns the ports opened on the container with the given
+// name, sorted.
+func Ports(container string) ([]network.Port, error) {
+	rules, _, err := listRules(ChainName(container))
+	if err != nil {
+		return nil, err
+	}
+	var ports []network.Port
+	for _, rule := range rules {
+		var port network.Port
+		for i := 0; i < len(rule)-1; i++ {
+			switch rule[i] {
+			case "-p":
+				port.Protocol = rule[i+1]
+			case "--dport":
+				port.Number, _ = strconv.Atoi(rule[i+1])
+			}
+		}
+		if port.Protocol != "" && port.Number != 0 {
+			ports = append(ports, port)
+		}
+	}
+	network.SortPorts(ports)
+	return ports, nil
+}
+
+// Remove removes all the firewall rules for the container with the
+// given name. It is not an error if there are none.
+func Remove(container string) error {
+	chain := ChainName(container)
+	if err := removeJumps(chain); err != nil {
+		return err
+	}
+	if _, exists, err := listRules(chain); err != nil {
+		return err
+	} else if !exists {
+		return nil
+	}
+	if _, err := runIPTables("-F", chain); err != nil {
+		return err
+	}
+	_, err := runIPTables("-X", chain)
+	return err
+}
+
+// ensureChain creates the given chain, with the rules that accept
+// established connections and connections from the bridge and drop
+// everything else, if it does not already exist.
+func ensureChain(chain, bridge string) error {
+	if _, exists, err := listRules(chain); err != nil || exists {
+		return err
+	}
+	for _, args := range [][]string{
+		{"-N", chain},
+		{"-A", chain, "-m", "state", "--state", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+		{"-A", chain, "-i", bridge, "-j", "ACCEPT"},
+		{"-A", chain, "-j", "DROP"},
+	} {
+		if _, err := runIPTables(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureJump makes sure that traffic forwarded to the given address
+// over the bridge is sent to the given chain, removing any rules that
+// send traffic for other addresses there, which are left behind when
+// the container's address changes.
+func ensureJump(chain, bridge, address string) error {
+	rule := []string{"FORWARD", "-o", bridge, "-d", address, "-j", chain}
+	if _, err := runIPTables(append([]string{"-C"}, rule...)...); err == nil {
+		return nil
+	}
+	if err := removeJumps(chain); err != nil {
+		return err
+	}
+	_, err := runIPTables(append([]string{"-I"}, rule...)...)
+	return err
+}
+
+// removeJumps removes all the rules that send forwarded traffic to the
+// given chain.
+func removeJumps(chain string) error {
+	rules, _, err := listRules("FORWARD")
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if len(rule) < 2 || rule[len(rule)-2] != "-j" || rule[len(rule)-1] != chain {
+			continue
+		}
+		if _, err := runIPTables(append([]string{"-D"}, rule...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listRules returns the rules in the given chain, each as the
+// arguments that follow -A in the output of "iptables -S", and
+// reports whether the chain exists.
+func listRules(chain string) (rules [][]string, exists bool, err error) {
+	output, err := runIPTables("-S", chain)
+	if err != nil {
+		if strings.Contains(output, "No chain") {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("cannot list firewall rules: %v", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "-A" {
+			continue
+		}
+		rules = append(rules, fields[1:])
+	}
+	return rules, true, nil
+}
+
+// portRule returns the rule, starting with the chain name, that
+// accepts connections to the given port.
+func portRule(chain string, port network.Port) []string {
+	return []string{
+		chain,
+		"-p", port.Protocol,
+		"-m", port.Protocol,
+		"--dport", strconv.Itoa(port.Number),
+		"-j", "ACCEPT",
+	}
+}
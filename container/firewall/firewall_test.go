@@ -0,0 +1,199 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package firewall_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/container/firewall"
+	"github.com/juju/juju/network"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type FirewallSuite struct {
+	coretesting.BaseSuite
+	iptables *fakeIPTables
+}
+
+var _ = gc.Suite(&FirewallSuite{})
+
+func (s *FirewallSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.iptables = &fakeIPTables{
+		chains: map[string][][]string{"FORWARD": nil},
+	}
+	s.PatchValue(firewall.RunIPTables, s.iptables.run)
+}
+
+func (s *FirewallSuite) TestChainName(c *gc.C) {
+	c.Assert(firewall.ChainName("machine-1"), gc.Equals, "juju-machine-1")
+	long := firewall.ChainName("fred-local-machine-1-kvm-12")
+	c.Assert(long, gc.HasLen, 28)
+	c.Assert(long, jc.HasPrefix, "juju-")
+	c.Assert(firewall.ChainName("fred-local-machine-1-kvm-13"), gc.Not(gc.Equals), long)
+}
+
+func (s *FirewallSuite) TestOpenPorts(c *gc.C) {
+	ports := []network.Port{{Protocol: "tcp", Number: 80}, {Protocol: "udp", Number: 53}}
+	err := firewall.OpenPorts("machine-1", "lxcbr0", "10.0.3.5", ports)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(s.iptables.chains["FORWARD"], jc.DeepEquals, [][]string{
+		{"-o", "lxcbr0", "-d", "10.0.3.5", "-j", "juju-machine-1"},
+	})
+	c.Assert(s.iptables.chains["juju-machine-1"], jc.DeepEquals, [][]string{
+		{"-p", "udp", "-m", "udp", "--dport", "53", "-j", "ACCEPT"},
+		{"-p", "tcp", "-m", "tcp", "--dport", "80", "-j", "ACCEPT"},
+		{"-m", "state", "--state", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+		{"-i", "lxcbr0", "-j", "ACCEPT"},
+		{"-j", "DROP"},
+	})
+
+	// Opening ports again is harmless.
+	err = firewall.OpenPorts("machine-1", "lxcbr0", "10.0.3.5", ports)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.iptables.chains["juju-machine-1"], gc.HasLen, 5)
+
+	got, err := firewall.Ports("machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, jc.DeepEquals, []network.Port{{Protocol: "tcp", Number: 80}, {Protocol: "udp", Number: 53}})
+}
+
+func (s *FirewallSuite) TestInit(c *gc.C) {
+	err := firewall.Init("machine-1", "lxcbr0", "10.0.3.5")
+	c.Assert(err, gc.IsNil)
+
+	// With no ports open, everything from outside the bridge is dropped.
+	c.Assert(s.iptables.chains["FORWARD"], jc.DeepEquals, [][]string{
+		{"-o", "lxcbr0", "-d", "10.0.3.5", "-j", "juju-machine-1"},
+	})
+	c.Assert(s.iptables.chains["juju-machine-1"], jc.DeepEquals, [][]string{
+		{"-m", "state", "--state", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+		{"-i", "lxcbr0", "-j", "ACCEPT"},
+		{"-j", "DROP"},
+	})
+	ports, err := firewall.Ports("machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+
+	// Initialising again is harmless.
+	err = firewall.Init("machine-1", "lxcbr0", "10.0.3.5")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.iptables.chains["FORWARD"], gc.HasLen, 1)
+	c.Assert(s.iptables.chains["juju-machine-1"], gc.HasLen, 3)
+}
+
+func (s *FirewallSuite) TestOpenPortsAddressChanged(c *gc.C) {
+	err := firewall.OpenPorts("machine-1", "lxcbr0", "10.0.3.5", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+	err = firewall.OpenPorts("machine-1", "lxcbr0", "10.0.3.6", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.iptables.chains["FORWARD"], jc.DeepEquals, [][]string{
+		{"-o", "lxcbr0", "-d", "10.0.3.6", "-j", "juju-machine-1"},
+	})
+}
+
+func (s *FirewallSuite) TestClosePorts(c *gc.C) {
+	err := firewall.OpenPorts("machine-1", "lxcbr0", "10.0.3.5", []network.Port{{Protocol: "tcp", Number: 80}, {Protocol: "tcp", Number: 443}})
+	c.Assert(err, gc.IsNil)
+	err = firewall.ClosePorts("machine-1", []network.Port{{Protocol: "tcp", Number: 80}, {Protocol: "tcp", Number: 8080}})
+	c.Assert(err, gc.IsNil)
+
+	got, err := firewall.Ports("machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, jc.DeepEquals, []network.Port{{Protocol: "tcp", Number: 443}})
+}
+
+func (s *FirewallSuite) TestNoChain(c *gc.C) {
+	ports, err := firewall.Ports("machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+	err = firewall.ClosePorts("machine-1", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+	err = firewall.Remove("machine-1")
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *FirewallSuite) TestRemove(c *gc.C) {
+	err := firewall.OpenPorts("machine-1", "lxcbr0", "10.0.3.5", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+	err = firewall.OpenPorts("machine-2", "lxcbr0", "10.0.3.6", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+
+	err = firewall.Remove("machine-1")
+	c.Assert(err, gc.IsNil)
+	_, ok := s.iptables.chains["juju-machine-1"]
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(s.iptables.chains["FORWARD"], jc.DeepEquals, [][]string{
+		{"-o", "lxcbr0", "-d", "10.0.3.6", "-j", "juju-machine-2"},
+	})
+}
+
+func (s *FirewallSuite) TestListError(c *gc.C) {
+	s.PatchValue(firewall.RunIPTables, func(args ...string) (string, error) {
+		return "iptables: Permission denied (you must be root).", fmt.Errorf("exit status 4")
+	})
+	_, err := firewall.Ports("machine-1")
+	c.Assert(err, gc.ErrorMatches, "cannot list firewall rules: exit status 4")
+}
+
+// fakeIPTables implements just enough of iptables for the firewall
+// package's use of it.
+type fakeIPTables struct {
+	chains map[string][][]string
+}
+
+func (f *fakeIPTables) run(args ...string) (string, error) {
+	cmd, chain := args[0], args[1]
+	rules, exists := f.chains[chain]
+	if !exists && cmd != "-N" {
+		return "iptables: No chain/target/match by that name.", fmt.Errorf("exit status 1")
+	}
+	rule := args[2:]
+	switch cmd {
+	case "-N":
+		if exists {
+			return "iptables: Chain already exists.", fmt.Errorf("exit status 1")
+		}
+		f.chains[chain] = nil
+	case "-X":
+		delete(f.chains, chain)
+	case "-F":
+		f.chains[chain] = nil
+	case "-A":
+		f.chains[chain] = append(rules, rule)
+	case "-I":
+		pos := 0
+		if n, err := strconv.Atoi(rule[0]); err == nil {
+			pos, rule = n-1, rule[1:]
+		}
+		rules = append(rules[:pos], append([][]string{rule}, rules[pos:]...)...)
+		f.chains[chain] = rules
+	case "-C", "-D":
+		for i, r := range rules {
+			if strings.Join(r, " ") != strings.Join(rule, " ") {
+				continue
+			}
+			if cmd == "-D" {
+				f.chains[chain] = append(rules[:i], rules[i+1:]...)
+			}
+			return "", nil
+		}
+		return "iptables: Bad rule (does a matching rule exist in that chain?).", fmt.Errorf("exit status 1")
+	case "-S":
+		lines := []string{"-N " + chain}
+		for _, r := range rules {
+			lines = append(lines, "-A "+chain+" "+strings.Join(r, " "))
+		}
+		return strings.Join(lines, "\n") + "\n", nil
+	default:
+		return "", fmt.Errorf("unexpected iptables command %v", args)
+	}
+	return "", nil
+}
@@ -4,6 +4,9 @@
 package container
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/instance"
 )
@@ -12,6 +15,10 @@ const (
 	ConfigName     = "name"
 	ConfigLogDir   = "log-dir"
 	ConfigToolsDir = "tools-dir"
+
+	// ConfigOvercommitRatio is the ratio of the host's resources that
+	// may be allocated to its containers. See CheckCapacity.
+	ConfigOvercommitRatio = "overcommit-ratio"
 )
 
 // ManagerConfig contains the initialization parameters for the ContainerManager.
@@ -60,3 +67,18 @@ func (m ManagerConfig) WarnAboutUnused() {
 		logger.Warningf("unused config option: %q -> %q", key, value)
 	}
 }
+
+// ParseOvercommitRatio pops the overcommit ratio from the config map.
+// It returns zero, which disables capacity checks, if the ratio is
+// not set.
+func ParseOvercommitRatio(m ManagerConfig) (float64, error) {
+	value := m.PopValue(ConfigOvercommitRatio)
+	if value == "" {
+		return 0, nil
+	}
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 {
+		return 0, fmt.Errorf("invalid %s %q", ConfigOvercommitRatio, value)
+	}
+	return ratio, nil
+}
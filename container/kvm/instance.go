@@ -34,9 +34,15 @@ func (*kvmInstance) Refresh() error {
 	return nil
 }
 
+// machineAddress is a variable so that it can be replaced in tests.
+var machineAddress = MachineAddress
+
 func (kvm *kvmInstance) Addresses() ([]network.Address, error) {
-	logger.Errorf("kvmInstance.Addresses not implemented")
-	return nil, nil
+	address, err := machineAddress(kvm.id)
+	if err != nil {
+		return nil, err
+	}
+	return []network.Address{network.NewAddress(address, network.ScopeCloudLocal)}, nil
 }
 
 // OpenPorts implements instance.Instance.OpenPorts.
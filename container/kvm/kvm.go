@@ -82,16 +82,21 @@ func NewContainerManager(conf container.ManagerConfig) (container.Manager, error
 	if logDir == "" {
 		logDir = agent.DefaultLogDir
 	}
+	overcommitRatio, err := container.ParseOvercommitRatio(conf)
+	if err != nil {
+		return nil, err
+	}
 	conf.WarnAboutUnused()
-	return &containerManager{name: name, logdir: logDir}, nil
+	return &containerManager{name: name, logdir: logDir, overcommitRatio: overcommitRatio}, nil
 }
 
 // containerManager handles all of the business logic at the juju specific
 // level. It makes sure that the necessary directories are in place, that the
 // user-data is written out in the right place.
 type containerManager struct {
-	name   string
-	logdir string
+	name            string
+	logdir          string
+	overcommitRatio float64
 }
 
 var _ container.Manager = (*containerManager)(nil)
@@ -110,6 +115,15 @@ func (manager *containerManager) CreateContainer(
 	// disk.
 	kvmContainer := KvmObjectFactory.New(name)
 
+	startParams := ParseConstraintsToStartParams(machineConfig.Constraints)
+	allocation := container.Resources{
+		Mem:      startParams.Memory,
+		CpuCores: startParams.CpuCores,
+	}
+	if err := container.CheckCapacity(allocation, manager.overcommitRatio); err != nil {
+		return nil, nil, err
+	}
+
 	// Create the cloud-init.
 	directory, err := container.NewDirectory(name)
 	if err != nil {
@@ -121,7 +135,6 @@ func (manager *containerManager) CreateContainer(
 		return nil, nil, errors.LoggedErrorf(logger, "failed to write user data: %v", err)
 	}
 	// Create the container.
-	startParams := ParseConstraintsToStartParams(machineConfig.Constraints)
 	startParams.Arch = version.Current.Arch
 	startParams.Series = series
 	startParams.Network = network
@@ -139,6 +152,9 @@ func (manager *containerManager) CreateContainer(
 	if err := kvmContainer.Start(startParams); err != nil {
 		return nil, nil, errors.LoggedErrorf(logger, "kvm container creation failed: %v", err)
 	}
+	if err := container.SetAllocation(name, allocation); err != nil {
+		return nil, nil, err
+	}
 	logger.Tracef("kvm container created")
 	return &kvmInstance{kvmContainer, name}, &hardware, nil
 }
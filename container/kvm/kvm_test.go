@@ -109,6 +109,26 @@ func (s *KVMSuite) TestDestroyContainer(c *gc.C) {
 	c.Assert(filepath.Join(s.RemovedDir, name), jc.IsDirectory)
 }
 
+func (s *KVMSuite) TestCreateContainerOvercommitted(c *gc.C) {
+	s.PatchValue(&container.HostResources, func() (container.Resources, error) {
+		return container.Resources{Mem: 2048, CpuCores: 4}, nil
+	})
+	manager, err := kvm.NewContainerManager(container.ManagerConfig{
+		container.ConfigName:            "test",
+		container.ConfigOvercommitRatio: "1",
+	})
+	c.Assert(err, gc.IsNil)
+	containertesting.CreateContainerWithConstraints(c, manager, "1/kvm/0", constraints.MustParse("mem=1536M"))
+	allocated, err := container.Allocated()
+	c.Assert(err, gc.IsNil)
+	c.Assert(allocated, gc.Equals, container.Resources{Mem: 1536, CpuCores: 1})
+
+	// The default memory for a kvm container no longer fits.
+	machineConfig := containertesting.MockMachineConfig("1/kvm/1")
+	_, _, err = manager.CreateContainer(machineConfig, "series", container.BridgeNetworkConfig("nic42"))
+	c.Assert(err, gc.ErrorMatches, `insufficient host capacity: 512M memory requested, 1536M of 2048M already allocated \(overcommit ratio 1\)`)
+}
+
 type ConstraintsSuite struct {
 	coretesting.BaseSuite
 }
@@ -292,3 +312,24 @@ func (s *KVMSuite) TestIsKVMSupportedOnlyPath(c *gc.C) {
 func (s *KVMSuite) TestKVMPathIsCorrect(c *gc.C) {
 	c.Assert(*kvm.KVMPath, gc.Equals, "/usr/sbin")
 }
+
+// patchExecutable writes an executable shell script with the given
+// name and body to a directory that replaces $PATH.
+func (s *KVMSuite) patchExecutable(c *gc.C, dir, name, body string) {
+	err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/bash\n"+body), 0777)
+	c.Assert(err, gc.IsNil)
+	s.PatchEnvironment("PATH", dir)
+}
+
+func (s *KVMSuite) TestMachineAddress(c *gc.C) {
+	s.patchExecutable(c, c.MkDir(), "uvt-kvm", `test "$1 $2" = "ip test-machine-1" && echo 192.168.122.17`)
+	address, err := kvm.MachineAddress("test-machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(address, gc.Equals, "192.168.122.17")
+}
+
+func (s *KVMSuite) TestMachineAddressInvalid(c *gc.C) {
+	s.patchExecutable(c, c.MkDir(), "uvt-kvm", `echo "no lease"`)
+	_, err := kvm.MachineAddress("test-machine-1")
+	c.Assert(err, gc.ErrorMatches, `cannot parse address of "test-machine-1" from "no lease"`)
+}
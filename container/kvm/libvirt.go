@@ -16,6 +16,7 @@ package kvm
 
 import (
 	"fmt"
+	"net"
 	"regexp"
 	"strings"
 
@@ -130,3 +131,17 @@ func ListMachines() (map[string]string, error) {
 	}
 	return result, nil
 }
+
+// MachineAddress returns the IP address of the virtual machine
+// identified by hostname, as leased to it by libvirt's DHCP server.
+func MachineAddress(hostname string) (string, error) {
+	output, err := run("uvt-kvm", "ip", hostname)
+	if err != nil {
+		return "", err
+	}
+	address := strings.TrimSpace(output)
+	if net.ParseIP(address) == nil {
+		return "", fmt.Errorf("cannot parse address of %q from %q", hostname, address)
+	}
+	return address, nil
+}
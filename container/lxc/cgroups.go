@@ -0,0 +1,69 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+)
+
+const (
+	// cpuPeriod is the CFS scheduler period, in microseconds, over
+	// which a container's CPU quota is enforced.
+	cpuPeriod = 100000
+
+	// sharesPerCore is the cgroup cpu.shares value that corresponds
+	// to a cpu-power of 100, that is, one core.
+	sharesPerCore = 1024
+
+	// minShares is the smallest cpu.shares value the kernel accepts.
+	minShares = 2
+)
+
+// ParseConstraintsToCgroups returns the lxc configuration lines that
+// limit a container's memory and CPU according to the given
+// constraints, and the host resources that the limits allocate to the
+// container. Constraints that cannot be applied to an lxc container
+// cause an info message to be logged.
+func ParseConstraintsToCgroups(cons constraints.Value) (string, container.Resources) {
+	var lines []string
+	var allocation container.Resources
+	if cons.Mem != nil && *cons.Mem > 0 {
+		allocation.Mem = *cons.Mem
+		lines = append(lines, fmt.Sprintf("lxc.cgroup.memory.limit_in_bytes = %dM", *cons.Mem))
+	}
+	if cons.CpuCores != nil && *cons.CpuCores > 0 {
+		allocation.CpuCores = *cons.CpuCores
+		lines = append(lines,
+			fmt.Sprintf("lxc.cgroup.cpu.cfs_period_us = %d", cpuPeriod),
+			fmt.Sprintf("lxc.cgroup.cpu.cfs_quota_us = %d", *cons.CpuCores*cpuPeriod),
+		)
+	}
+	if cons.CpuPower != nil && *cons.CpuPower > 0 {
+		shares := *cons.CpuPower * sharesPerCore / 100
+		if shares < minShares {
+			shares = minShares
+		}
+		lines = append(lines, fmt.Sprintf("lxc.cgroup.cpu.shares = %d", shares))
+	}
+	if cons.RootDisk != nil {
+		logger.Infof("root-disk constraint of %vM being ignored as not supported", *cons.RootDisk)
+	}
+	if cons.Arch != nil {
+		logger.Infof("arch constraint of %q being ignored as not supported", *cons.Arch)
+	}
+	if cons.Container != nil {
+		logger.Infof("container constraint of %q being ignored as not supported", *cons.Container)
+	}
+	if cons.Tags != nil {
+		logger.Infof("tags constraint of %q being ignored as not supported", strings.Join(*cons.Tags, ","))
+	}
+	if len(lines) == 0 {
+		return "", allocation
+	}
+	return strings.Join(lines, "\n") + "\n", allocation
+}
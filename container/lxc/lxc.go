@@ -6,6 +6,7 @@ package lxc
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/juju/loggo"
 	"github.com/juju/names"
+	"github.com/juju/utils"
 	"launchpad.net/golxc"
 
 	"github.com/juju/juju/agent"
@@ -50,6 +52,26 @@ func DefaultNetworkConfig() *container.NetworkConfig {
 // we can test what *would* be run without actually executing another program
 var FsCommandOutput = (*exec.Cmd).CombinedOutput
 
+// ContainerAddress returns the IPv4 address of the running container
+// with the given name.
+func ContainerAddress(name string) (string, error) {
+	output, err := utils.RunCommand("lxc-info", "-n", name, "-i")
+	if err != nil {
+		return "", fmt.Errorf("cannot get address of %q: %v", name, err)
+	}
+	// Each address is reported on a line of the form "IP: <address>".
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "IP:" {
+			continue
+		}
+		if ip := net.ParseIP(fields[1]); ip != nil && ip.To4() != nil {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("container %q has no IPv4 address", name)
+}
+
 func containerDirFilesystem() (string, error) {
 	cmd := exec.Command("df", "--output=fstype", LxcContainerDir)
 	out, err := FsCommandOutput(cmd)
@@ -72,6 +94,7 @@ type containerManager struct {
 	createWithClone   bool
 	useAUFS           bool
 	backingFilesystem string
+	overcommitRatio   float64
 }
 
 // containerManager implements container.Manager.
@@ -104,6 +127,10 @@ func NewContainerManager(conf container.ManagerConfig) (container.Manager, error
 		useClone = preferFastLXC(releaseVersion())
 	}
 	useAUFS, _ := strconv.ParseBool(conf.PopValue("use-aufs"))
+	overcommitRatio, err := container.ParseOvercommitRatio(conf)
+	if err != nil {
+		return nil, err
+	}
 	backingFS, err := containerDirFilesystem()
 	if err != nil {
 		// Especially in tests, or a bot, the lxc dir may not exist
@@ -121,6 +148,7 @@ func NewContainerManager(conf container.ManagerConfig) (container.Manager, error
 		createWithClone:   useClone,
 		useAUFS:           useAUFS,
 		backingFilesystem: backingFS,
+		overcommitRatio:   overcommitRatio,
 	}, nil
 }
 
@@ -151,6 +179,10 @@ func (manager *containerManager) CreateContainer(
 	if manager.name != "" {
 		name = fmt.Sprintf("%s-%s", manager.name, name)
 	}
+	cgroupConfig, allocation := ParseConstraintsToCgroups(machineConfig.Constraints)
+	if err := container.CheckCapacity(allocation, manager.overcommitRatio); err != nil {
+		return nil, nil, err
+	}
 	// Create the cloud-init.
 	directory, err := container.NewDirectory(name)
 	if err != nil {
@@ -236,6 +268,12 @@ func (manager *containerManager) CreateContainer(
 	if err := mountHostToolsDir(name, manager.toolsdir); err != nil {
 		return nil, nil, err
 	}
+	if cgroupConfig != "" {
+		logger.Tracef("limit the container's resources")
+		if err := appendToContainerConfig(name, cgroupConfig); err != nil {
+			return nil, nil, err
+		}
+	}
 	// Start the lxc container with the appropriate settings for grabbing the
 	// console output and a log file.
 	consoleFile := filepath.Join(directory, "console.log")
@@ -249,9 +287,18 @@ func (manager *containerManager) CreateContainer(
 		logger.Errorf("container failed to start: %v", err)
 		return nil, nil, err
 	}
+	if err := container.SetAllocation(name, allocation); err != nil {
+		return nil, nil, err
+	}
+	// The resources charged to the container against the host are
+	// reported as its hardware, so that they are recorded in state
+	// and shown in status.
 	arch := version.Current.Arch
+	allocated := allocation.WithDefaults()
 	hardware := &instance.HardwareCharacteristics{
-		Arch: &arch,
+		Arch:     &arch,
+		Mem:      &allocated.Mem,
+		CpuCores: &allocated.CpuCores,
 	}
 	logger.Tracef("container %q started: %v", name, time.Now().Sub(start))
 	return &lxcInstance{lxcContainer, name}, hardware, nil
@@ -20,6 +20,7 @@ import (
 	"launchpad.net/golxc"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/container/lxc/mock"
@@ -36,10 +37,11 @@ func Test(t *stdtesting.T) {
 type LxcSuite struct {
 	lxctesting.TestSuite
 
-	events        chan mock.Event
-	useClone      bool
-	useAUFS       bool
-	mountToolsDir bool
+	events          chan mock.Event
+	useClone        bool
+	useAUFS         bool
+	mountToolsDir   bool
+	overcommitRatio string
 }
 
 var _ = gc.Suite(&LxcSuite{})
@@ -165,6 +167,9 @@ func (s *LxcSuite) makeManager(c *gc.C, name string) container.Manager {
 	if s.mountToolsDir {
 		params["tools-dir"] = "/mount/tools"
 	}
+	if s.overcommitRatio != "" {
+		params[container.ConfigOvercommitRatio] = s.overcommitRatio
+	}
 	manager, err := lxc.NewContainerManager(params)
 	c.Assert(err, gc.IsNil)
 	return manager
@@ -474,6 +479,99 @@ lxc.mount.entry=/mount/tools tmp/juju/tools none defaults,bind 0 0
 	c.Assert(string(config), gc.Equals, expected)
 }
 
+func (s *LxcSuite) TestCreateContainerWithConstraints(c *gc.C) {
+	err := os.Remove(s.RestartDir)
+	c.Assert(err, gc.IsNil)
+
+	manager := s.makeManager(c, "test")
+	cons := constraints.MustParse("mem=2G cpu-cores=2")
+	instance := containertesting.CreateContainerWithConstraints(c, manager, "1/lxc/0", cons)
+	name := string(instance.Id())
+	config, err := ioutil.ReadFile(lxc.ContainerConfigFilename(name))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(config), jc.HasSuffix, `
+lxc.cgroup.memory.limit_in_bytes = 2048M
+lxc.cgroup.cpu.cfs_period_us = 100000
+lxc.cgroup.cpu.cfs_quota_us = 200000
+`)
+	allocated, err := container.Allocated()
+	c.Assert(err, gc.IsNil)
+	c.Assert(allocated, gc.Equals, container.Resources{Mem: 2048, CpuCores: 2})
+}
+
+func (s *LxcSuite) TestCreateContainerReportsAllocation(c *gc.C) {
+	manager := s.makeManager(c, "test")
+	machineConfig := containertesting.MockMachineConfig("1/lxc/0")
+	machineConfig.Constraints = constraints.MustParse("cpu-cores=2")
+	_, hardware, err := manager.CreateContainer(machineConfig, "series", lxc.DefaultNetworkConfig())
+	c.Assert(err, gc.IsNil)
+	// Memory is not constrained, so the default is charged.
+	c.Assert(*hardware.Mem, gc.Equals, uint64(512))
+	c.Assert(*hardware.CpuCores, gc.Equals, uint64(2))
+}
+
+func (s *LxcSuite) TestCreateContainerOvercommitted(c *gc.C) {
+	s.PatchValue(&container.HostResources, func() (container.Resources, error) {
+		return container.Resources{Mem: 4096, CpuCores: 4}, nil
+	})
+	s.overcommitRatio = "1"
+	manager := s.makeManager(c, "test")
+	containertesting.CreateContainerWithConstraints(c, manager, "1/lxc/0", constraints.MustParse("mem=3G"))
+
+	machineConfig := containertesting.MockMachineConfig("1/lxc/1")
+	machineConfig.Constraints = constraints.MustParse("mem=2G")
+	_, _, err := manager.CreateContainer(machineConfig, "series", lxc.DefaultNetworkConfig())
+	c.Assert(err, gc.ErrorMatches, `insufficient host capacity: 2048M memory requested, 3072M of 4096M already allocated \(overcommit ratio 1\)`)
+	c.Assert(filepath.Join(s.ContainerDir, "test-machine-1-lxc-1"), jc.DoesNotExist)
+}
+
+type CgroupsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&CgroupsSuite{})
+
+func (*CgroupsSuite) TestParseConstraintsToCgroups(c *gc.C) {
+	for i, test := range []struct {
+		cons       string
+		config     string
+		allocation container.Resources
+		infoLog    []string
+	}{{
+		cons: "",
+	}, {
+		cons:       "mem=512M",
+		config:     "lxc.cgroup.memory.limit_in_bytes = 512M\n",
+		allocation: container.Resources{Mem: 512},
+	}, {
+		cons: "cpu-cores=3",
+		config: "lxc.cgroup.cpu.cfs_period_us = 100000\n" +
+			"lxc.cgroup.cpu.cfs_quota_us = 300000\n",
+		allocation: container.Resources{CpuCores: 3},
+	}, {
+		cons:   "cpu-power=50",
+		config: "lxc.cgroup.cpu.shares = 512\n",
+	}, {
+		cons:   "cpu-power=0",
+		config: "",
+	}, {
+		cons:    "root-disk=8G",
+		infoLog: []string{"root-disk constraint of 8192M being ignored as not supported"},
+	}, {
+		cons:    "arch=armhf",
+		infoLog: []string{`arch constraint of "armhf" being ignored as not supported`},
+	}} {
+		c.Logf("test %d: %s", i, test.cons)
+		tw := &loggo.TestWriter{}
+		c.Assert(loggo.RegisterWriter("constraint-tester", tw, loggo.DEBUG), gc.IsNil)
+		config, allocation := lxc.ParseConstraintsToCgroups(constraints.MustParse(test.cons))
+		c.Check(config, gc.Equals, test.config)
+		c.Check(allocation, gc.Equals, test.allocation)
+		c.Check(tw.Log, jc.LogMatches, test.infoLog)
+		loggo.RemoveWriter("constraint-tester")
+	}
+}
+
 type NetworkSuite struct {
 	coretesting.BaseSuite
 }
@@ -527,3 +625,24 @@ func (*NetworkSuite) TestNetworkConfigTemplate(c *gc.C) {
 	}
 	c.Assert(obtained, gc.DeepEquals, expected)
 }
+
+func (s *LxcSuite) patchLxcInfo(c *gc.C, output string) {
+	dir := c.MkDir()
+	script := fmt.Sprintf("#!/bin/bash\ntest \"$*\" = \"-n juju-machine-1 -i\" || exit 1\necho '%s'\n", output)
+	err := ioutil.WriteFile(filepath.Join(dir, "lxc-info"), []byte(script), 0777)
+	c.Assert(err, gc.IsNil)
+	s.PatchEnvironment("PATH", dir)
+}
+
+func (s *LxcSuite) TestContainerAddress(c *gc.C) {
+	s.patchLxcInfo(c, "IP:             fe80::216:3eff:fe6b:8b1c\nIP:             10.0.3.140")
+	address, err := lxc.ContainerAddress("juju-machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(address, gc.Equals, "10.0.3.140")
+}
+
+func (s *LxcSuite) TestContainerAddressNone(c *gc.C) {
+	s.patchLxcInfo(c, "")
+	_, err := lxc.ContainerAddress("juju-machine-1")
+	c.Assert(err, gc.ErrorMatches, `container "juju-machine-1" has no IPv4 address`)
+}
@@ -9,15 +9,19 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/instance"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
 )
 
-func CreateContainer(c *gc.C, manager container.Manager, machineId string) instance.Instance {
+// MockMachineConfig returns a machine configuration for the machine
+// with the given id, suitable for creating a container in tests.
+func MockMachineConfig(machineId string) *cloudinit.MachineConfig {
 	stateInfo := jujutesting.FakeStateInfo(machineId)
 	apiInfo := jujutesting.FakeAPIInfo(machineId)
 	machineConfig := environs.NewMachineConfig(machineId, "fake-nonce", nil, stateInfo, apiInfo)
@@ -25,6 +29,16 @@ func CreateContainer(c *gc.C, manager container.Manager, machineId string) insta
 		Version: version.MustParseBinary("2.3.4-foo-bar"),
 		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
 	}
+	return machineConfig
+}
+
+func CreateContainer(c *gc.C, manager container.Manager, machineId string) instance.Instance {
+	return CreateContainerWithConstraints(c, manager, machineId, constraints.Value{})
+}
+
+func CreateContainerWithConstraints(c *gc.C, manager container.Manager, machineId string, cons constraints.Value) instance.Instance {
+	machineConfig := MockMachineConfig(machineId)
+	machineConfig.Constraints = cons
 
 	series := "series"
 	network := container.BridgeNetworkConfig("nic42")
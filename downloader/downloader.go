@@ -4,11 +4,15 @@
 package downloader
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
@@ -25,11 +29,89 @@ type Status struct {
 	Err error
 }
 
+// Options holds optional parameters for a download.
+type Options struct {
+	// Size holds the expected size of the downloaded data, if known.
+	Size int64
+
+	// SHA256 holds the expected SHA256 hash of the downloaded data,
+	// if known. When it is set, the data is verified before the
+	// download completes, and a download that is interrupted is
+	// resumed from the data already received, whether by the same
+	// download or by a later download into the same directory.
+	SHA256 string
+
+	// Progress, if not nil, is called as data is received with the
+	// number of bytes received so far and the total size, or -1 if
+	// the total size is not known.
+	Progress func(received, total int64)
+
+	// Attempts holds how many times a resumable download is
+	// attempted before it fails. If it is zero, the download is
+	// attempted once.
+	Attempts int
+
+	// Limiter, if not nil, limits the number of downloads that run
+	// at once.
+	Limiter Limiter
+
+	// Header holds additional headers to send with each request,
+	// such as credentials for the API server.
+	Header http.Header
+
+	// Client, if not nil, is used to make the requests, in place of
+	// a client chosen according to the hostname verification given
+	// to NewWithOptions. It allows the server's certificate to be
+	// verified against a particular CA certificate.
+	Client *http.Client
+}
+
+// Limiter limits the number of downloads that run at once.
+type Limiter interface {
+	// Acquire waits until a download may start, and reports
+	// whether it may; it returns false if stop is closed first.
+	Acquire(stop <-chan struct{}) bool
+
+	// Release records that a download started after a successful
+	// call to Acquire has finished.
+	Release()
+}
+
+// NewLimiter returns a Limiter that lets at most n of the downloads
+// that share it run at once.
+func NewLimiter(n int) Limiter {
+	return make(limiter, n)
+}
+
+type limiter chan struct{}
+
+// Acquire implements Limiter.Acquire.
+func (l limiter) Acquire(stop <-chan struct{}) bool {
+	select {
+	case l <- struct{}{}:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// Release implements Limiter.Release.
+func (l limiter) Release() {
+	<-l
+}
+
+// retryDelay holds how long to wait before resuming an interrupted
+// download.
+var retryDelay = 5 * time.Second
+
+var errStopped = errors.New("download stopped")
+
 // Download can download a file from the network.
 type Download struct {
 	tomb                 tomb.Tomb
 	done                 chan Status
 	hostnameVerification utils.SSLHostnameVerification
+	opts                 Options
 }
 
 // New returns a new Download instance downloading from the given URL
@@ -37,9 +119,16 @@ type Download struct {
 // os.TempDir(). If disableSSLHostnameVerification is true then a non-
 // validating http client will be used.
 func New(url, dir string, hostnameVerification utils.SSLHostnameVerification) *Download {
+	return NewWithOptions(url, dir, hostnameVerification, Options{})
+}
+
+// NewWithOptions is like New, but the download is verified, resumed,
+// reported and limited according to the given options.
+func NewWithOptions(url, dir string, hostnameVerification utils.SSLHostnameVerification, opts Options) *Download {
 	d := &Download{
 		done:                 make(chan Status),
 		hostnameVerification: hostnameVerification,
+		opts:                 opts,
 	}
 	go d.run(url, dir)
 	return d
@@ -63,7 +152,7 @@ func (d *Download) run(url, dir string) {
 	// TODO(dimitern) 2013-10-03 bug #1234715
 	// Add a testing HTTPS storage to verify the
 	// disableSSLHostnameVerification behavior here.
-	file, err := download(url, dir, d.hostnameVerification)
+	file, err := d.download(url, dir)
 	if err != nil {
 		err = fmt.Errorf("cannot download %q: %v", url, err)
 	}
@@ -78,37 +167,195 @@ func (d *Download) run(url, dir string) {
 	}
 }
 
-func download(url, dir string, hostnameVerification utils.SSLHostnameVerification) (file *os.File, err error) {
+func (d *Download) download(url, dir string) (file *os.File, err error) {
 	if dir == "" {
 		dir = os.TempDir()
 	}
-	tempFile, err := ioutil.TempFile(dir, "inprogress-")
+	resumable := d.opts.SHA256 != ""
+	if resumable {
+		// Data received by an earlier download is kept in
+		// a file named after the hash of the data.
+		file, err = os.OpenFile(filepath.Join(dir, "partial-"+d.opts.SHA256), os.O_RDWR|os.O_CREATE, 0644)
+	} else {
+		file, err = ioutil.TempFile(dir, "inprogress-")
+	}
 	if err != nil {
 		return nil, err
 	}
+	keepPartial := resumable
 	defer func() {
-		if err != nil {
-			cleanTempFile(tempFile)
+		if err == nil {
+			return
+		}
+		if keepPartial {
+			file.Close()
+		} else {
+			cleanTempFile(file)
 		}
 	}()
-	// TODO(rog) make the download operation interruptible.
-	client := utils.GetHTTPClient(hostnameVerification)
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
+	if d.opts.Limiter != nil {
+		if !d.opts.Limiter.Acquire(d.tomb.Dying()) {
+			return nil, errStopped
+		}
+		defer d.opts.Limiter.Release()
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad http response: %v", resp.Status)
+	client := d.opts.Client
+	if client == nil {
+		client = utils.GetHTTPClient(d.hostnameVerification)
 	}
-	_, err = io.Copy(tempFile, resp.Body)
-	if err != nil {
+	for attempt := 1; ; attempt++ {
+		retry, err := d.fetch(client, url, file)
+		if err == nil {
+			break
+		}
+		if !resumable || !retry || attempt >= d.opts.Attempts {
+			return nil, err
+		}
+		logger.Warningf("download of %q interrupted, resuming: %v", url, err)
+		select {
+		case <-d.tomb.Dying():
+			return nil, errStopped
+		case <-time.After(retryDelay):
+		}
+	}
+	if err := d.verify(file); err != nil {
+		// The data is corrupt, so it must be downloaded afresh.
+		keepPartial = false
 		return nil, err
 	}
-	if _, err := tempFile.Seek(0, 0); err != nil {
+	if _, err := file.Seek(0, 0); err != nil {
 		return nil, err
 	}
-	return tempFile, nil
+	return file, nil
+}
+
+// fetch appends the data from url that is missing from file. It
+// returns whether an error may be recovered from by trying again.
+func (d *Download) fetch(client *http.Client, url string, file *os.File) (retry bool, err error) {
+	offset, err := file.Seek(0, 2)
+	if err != nil {
+		return false, err
+	}
+	if d.opts.Size > 0 && offset >= d.opts.Size {
+		// All the data has already been received.
+		return false, nil
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	for key, values := range d.opts.Header {
+		req.Header[key] = values
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			// The server has ignored the range, so start over.
+			logger.Infof("server does not support resuming %q; restarting download", url)
+			if err := file.Truncate(0); err != nil {
+				return false, err
+			}
+			if offset, err = file.Seek(0, 0); err != nil {
+				return false, err
+			}
+		}
+	case http.StatusPartialContent:
+		contentRange := resp.Header.Get("Content-Range")
+		if !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", offset)) {
+			// Start over rather than risk corrupting the data.
+			if err := file.Truncate(0); err != nil {
+				return false, err
+			}
+			return true, fmt.Errorf("unexpected content range %q", contentRange)
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset > 0 {
+			// All the data has already been received.
+			return false, nil
+		}
+		fallthrough
+	default:
+		return resp.StatusCode >= 500, fmt.Errorf("bad http response: %v", resp.Status)
+	}
+	total := d.opts.Size
+	if total <= 0 {
+		total = -1
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	}
+	body := &progressReader{
+		reader:   resp.Body,
+		stop:     d.tomb.Dying(),
+		received: offset,
+		total:    total,
+		progress: d.opts.Progress,
+	}
+	if _, err := io.Copy(file, body); err != nil {
+		return err != errStopped, err
+	}
+	return false, nil
+}
+
+// verify checks the downloaded data against the expected size and
+// hash, if known.
+func (d *Download) verify(file *os.File) error {
+	if d.opts.Size > 0 {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() != d.opts.Size {
+			return fmt.Errorf("size mismatch: expected %d bytes, got %d", d.opts.Size, info.Size())
+		}
+	}
+	if d.opts.SHA256 != "" {
+		if _, err := file.Seek(0, 0); err != nil {
+			return err
+		}
+		sha256, _, err := utils.ReadSHA256(file)
+		if err != nil {
+			return err
+		}
+		if sha256 != d.opts.SHA256 {
+			return fmt.Errorf("sha256 mismatch: expected %q, got %q", d.opts.SHA256, sha256)
+		}
+	}
+	return nil
+}
+
+// progressReader reports the progress of reading a download, and
+// stops reading when the download is stopped.
+type progressReader struct {
+	reader   io.Reader
+	stop     <-chan struct{}
+	received int64
+	total    int64
+	progress func(received, total int64)
+}
+
+func (r *progressReader) Read(buf []byte) (int, error) {
+	select {
+	case <-r.stop:
+		return 0, errStopped
+	default:
+	}
+	n, err := r.reader.Read(buf)
+	if n > 0 {
+		r.received += int64(n)
+		if r.progress != nil {
+			r.progress(r.received, r.total)
+		}
+	}
+	return n, err
 }
 
 func cleanTempFile(f *os.File) {
@@ -5,12 +5,15 @@ package downloader_test
 
 import (
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	stdtesting "testing"
 	"time"
 
 	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
@@ -94,6 +97,176 @@ func (s *suite) TestStopDownload(c *gc.C) {
 	c.Assert(infos, gc.HasLen, 0)
 }
 
+func sha256Of(data string) string {
+	sum, _, err := utils.ReadSHA256(strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return sum
+}
+
+func (s *suite) download(c *gc.C, dir string, opts downloader.Options) downloader.Status {
+	d := downloader.NewWithOptions(s.URL("/archive.tgz"), dir, utils.VerifySSLHostnames, opts)
+	defer d.Stop()
+	select {
+	case status := <-d.Done():
+		return status
+	case <-time.After(testing.LongWait):
+		c.Fatalf("download did not complete")
+	}
+	panic("unreachable")
+}
+
+func (s *suite) assertDownloaded(c *gc.C, status downloader.Status, expect string) {
+	c.Assert(status.Err, gc.IsNil)
+	c.Assert(status.File, gc.NotNil)
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	assertFileContents(c, status.File, expect)
+}
+
+func (s *suite) TestDownloadVerifiesAndReportsProgress(c *gc.C) {
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	var received, total int64
+	status := s.download(c, c.MkDir(), downloader.Options{
+		Size:   7,
+		SHA256: sha256Of("archive"),
+		Progress: func(r, t int64) {
+			received, total = r, t
+		},
+	})
+	s.assertDownloaded(c, status, "archive")
+	c.Assert(received, gc.Equals, int64(7))
+	c.Assert(total, gc.Equals, int64(7))
+}
+
+func (s *suite) TestDownloadResumes(c *gc.C) {
+	dir := c.MkDir()
+	sha256 := sha256Of("archive")
+	err := ioutil.WriteFile(filepath.Join(dir, "partial-"+sha256), []byte("arch"), 0644)
+	c.Assert(err, gc.IsNil)
+	gitjujutesting.Server.Response(206, map[string]string{"Content-Range": "bytes 4-6/7"}, []byte("ive"))
+
+	status := s.download(c, dir, downloader.Options{Size: 7, SHA256: sha256})
+	s.assertDownloaded(c, status, "archive")
+	req := gitjujutesting.Server.WaitRequest()
+	c.Assert(req.Header.Get("Range"), gc.Equals, "bytes=4-")
+}
+
+func (s *suite) TestDownloadSendsHeader(c *gc.C) {
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	header := make(http.Header)
+	header.Set("Authorization", "Basic Zm9vOmJhcg==")
+
+	status := s.download(c, c.MkDir(), downloader.Options{Header: header})
+	s.assertDownloaded(c, status, "archive")
+	req := gitjujutesting.Server.WaitRequest()
+	c.Assert(req.Header.Get("Authorization"), gc.Equals, "Basic Zm9vOmJhcg==")
+}
+
+func (s *suite) TestDownloadUsesClient(c *gc.C) {
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	transport := &countingTransport{}
+	client := &http.Client{Transport: transport}
+
+	status := s.download(c, c.MkDir(), downloader.Options{Client: client})
+	s.assertDownloaded(c, status, "archive")
+	c.Assert(transport.count, gc.Equals, 1)
+}
+
+// countingTransport is an http.RoundTripper that counts the requests
+// made through it.
+type countingTransport struct {
+	count int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *suite) TestDownloadRestartsWhenRangeIgnored(c *gc.C) {
+	dir := c.MkDir()
+	sha256 := sha256Of("archive")
+	err := ioutil.WriteFile(filepath.Join(dir, "partial-"+sha256), []byte("arch"), 0644)
+	c.Assert(err, gc.IsNil)
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+
+	status := s.download(c, dir, downloader.Options{SHA256: sha256})
+	s.assertDownloaded(c, status, "archive")
+}
+
+func (s *suite) TestDownloadRetriesServerErrors(c *gc.C) {
+	s.PatchValue(downloader.RetryDelay, time.Duration(0))
+	gitjujutesting.Server.Response(503, nil, nil)
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+
+	status := s.download(c, c.MkDir(), downloader.Options{
+		SHA256:   sha256Of("archive"),
+		Attempts: 2,
+	})
+	s.assertDownloaded(c, status, "archive")
+}
+
+func (s *suite) TestDownloadGivesUpAfterMaxAttempts(c *gc.C) {
+	s.PatchValue(downloader.RetryDelay, time.Duration(0))
+	gitjujutesting.Server.Response(503, nil, nil)
+	gitjujutesting.Server.Response(503, nil, nil)
+
+	status := s.download(c, c.MkDir(), downloader.Options{
+		SHA256:   sha256Of("archive"),
+		Attempts: 2,
+	})
+	c.Assert(status.File, gc.IsNil)
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": bad http response: 503 Service Unavailable`)
+}
+
+func (s *suite) TestDownloadVerificationFailure(c *gc.C) {
+	dir := c.MkDir()
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	status := s.download(c, dir, downloader.Options{Size: 7, SHA256: sha256Of("another")})
+	c.Assert(status.File, gc.IsNil)
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": sha256 mismatch: .*`)
+
+	// The corrupt data is not kept.
+	infos, err := ioutil.ReadDir(dir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(infos, gc.HasLen, 0)
+
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	status = s.download(c, dir, downloader.Options{Size: 8})
+	c.Assert(status.File, gc.IsNil)
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": size mismatch: expected 8 bytes, got 7`)
+}
+
+func (s *suite) TestDownloadLimiter(c *gc.C) {
+	limiter := downloader.NewLimiter(1)
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	opts := downloader.Options{Limiter: limiter}
+
+	// Take the only slot, so that no download can start.
+	c.Assert(limiter.Acquire(nil), jc.IsTrue)
+	d := downloader.NewWithOptions(s.URL("/archive.tgz"), c.MkDir(), utils.VerifySSLHostnames, opts)
+	defer d.Stop()
+	select {
+	case status := <-d.Done():
+		c.Fatalf("download completed while limited: %#v", status)
+	case <-time.After(testing.ShortWait):
+	}
+	limiter.Release()
+	select {
+	case status := <-d.Done():
+		s.assertDownloaded(c, status, "archive")
+	case <-time.After(testing.LongWait):
+		c.Fatalf("download did not complete")
+	}
+
+	// The slot is released when the download completes.
+	status := s.download(c, c.MkDir(), opts)
+	s.assertDownloaded(c, status, "archive")
+}
+
 func assertFileContents(c *gc.C, f *os.File, expect string) {
 	got, err := ioutil.ReadAll(f)
 	c.Assert(err, gc.IsNil)
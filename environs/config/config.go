@@ -324,6 +324,23 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	// If resource tags are set, make sure they are valid.
+	if v, ok := cfg.defined["resource-tags"].(string); ok {
+		if _, err := parseResourceTags(v); err != nil {
+			return err
+		}
+	}
+
+	// The container overcommit ratio, if set, must be positive.
+	if v, ok := cfg.defined["container-overcommit-ratio"].(float64); ok && v <= 0 {
+		return fmt.Errorf("container-overcommit-ratio must be greater than zero, got %v", v)
+	}
+
+	// The tools download concurrency, if set, must not be negative.
+	if v, ok := cfg.defined["tools-download-concurrency"].(int); ok && v < 0 {
+		return fmt.Errorf("tools-download-concurrency must not be negative, got %d", v)
+	}
+
 	// Check firewall mode.
 	if mode := cfg.FirewallMode(); mode != FwInstance && mode != FwGlobal {
 		return fmt.Errorf("invalid firewall mode in environment configuration: %q", mode)
@@ -725,6 +742,23 @@ func (c *Config) LXCUseCloneAUFS() (bool, bool) {
 	return v, ok
 }
 
+// ContainerOvercommitRatio returns the ratio of a machine's memory and
+// CPU cores that may be allocated to the containers on it, and whether
+// it has been set. When it is not set, container requests are not
+// checked against the machine's resources.
+func (c *Config) ContainerOvercommitRatio() (float64, bool) {
+	v, ok := c.defined["container-overcommit-ratio"].(float64)
+	return v, ok
+}
+
+// ToolsDownloadConcurrency returns the number of tools downloads that
+// each API server serves at once; agents asking for tools beyond that
+// number wait their turn. If it is zero, the number is not limited.
+func (c *Config) ToolsDownloadConcurrency() int {
+	v, _ := c.defined["tools-download-concurrency"].(int)
+	return v
+}
+
 // DisableNetworkManagement reports whether Juju is allowed to
 // configure and manage networking inside the environment.
 func (c *Config) DisableNetworkManagement() (bool, bool) {
@@ -732,6 +766,39 @@ func (c *Config) DisableNetworkManagement() (bool, bool) {
 	return v, ok
 }
 
+// ResourceTagPrefix is the prefix of the names of the tags that juju
+// itself sets on provider resources. User-defined resource tags may
+// not have names with this prefix.
+const ResourceTagPrefix = "juju-"
+
+// ResourceTags returns the user-defined tags that should be set on
+// resources, such as instances, created by the provider.
+func (c *Config) ResourceTags() map[string]string {
+	// The tags are checked when the configuration is validated.
+	tags, _ := parseResourceTags(c.asString("resource-tags"))
+	return tags
+}
+
+// parseResourceTags parses a space-separated list of key=value resource
+// tags.
+func parseResourceTags(s string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, tag := range strings.Fields(s) {
+		parts := strings.SplitN(tag, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid resource tag %q: expected key=value", tag)
+		}
+		if strings.HasPrefix(parts[0], ResourceTagPrefix) {
+			return nil, fmt.Errorf("invalid resource tag %q: keys with prefix %q are reserved", tag, ResourceTagPrefix)
+		}
+		if _, ok := tags[parts[0]]; ok {
+			return nil, fmt.Errorf("resource tag %q specified more than once", parts[0])
+		}
+		tags[parts[0]] = parts[1]
+	}
+	return tags, nil
+}
+
 // UnknownAttrs returns a copy of the raw configuration attributes
 // that are supposedly specific to the environment type. They could
 // also be wrong attributes, though. Only the specific environment
@@ -811,6 +878,9 @@ var fields = schema.Fields{
 	"lxc-clone":                  schema.Bool(),
 	"lxc-clone-aufs":             schema.Bool(),
 	"disable-network-management": schema.Bool(),
+	"resource-tags":              schema.String(),
+	"container-overcommit-ratio": schema.Float(),
+	"tools-download-concurrency": schema.ForceInt(),
 
 	// Deprecated fields, retain for backwards compatibility.
 	"tools-url":     schema.String(),
@@ -847,6 +917,9 @@ var alwaysOptional = schema.Defaults{
 	"apt-ftp-proxy":              schema.Omit,
 	"lxc-clone":                  schema.Omit,
 	"disable-network-management": schema.Omit,
+	"resource-tags":              schema.Omit,
+	"container-overcommit-ratio": schema.Omit,
+	"tools-download-concurrency": schema.Omit,
 
 	// Deprecated fields, retain for backwards compatibility.
 	"tools-url":     "",
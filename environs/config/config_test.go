@@ -692,6 +692,59 @@ var configTests = []configTest{
 			"name":      "my-name",
 			"test-mode": true,
 		},
+	}, {
+		about:       "Valid resource tags",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"resource-tags": "team=ops cost-centre=4242",
+		}),
+	}, {
+		about:       "Invalid resource tag",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"resource-tags": "team=ops cost-centre",
+		}),
+		err: `invalid resource tag "cost-centre": expected key=value`,
+	}, {
+		about:       "Reserved resource tag",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"resource-tags": "juju-machine-id=0",
+		}),
+		err: `invalid resource tag "juju-machine-id=0": keys with prefix "juju-" are reserved`,
+	}, {
+		about:       "Duplicate resource tag",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"resource-tags": "team=ops team=dev",
+		}),
+		err: `resource tag "team" specified more than once`,
+	}, {
+		about:       "Valid container overcommit ratio",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"container-overcommit-ratio": 1.5,
+		}),
+	}, {
+		about:       "Invalid container overcommit ratio",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"container-overcommit-ratio": 0.0,
+		}),
+		err: `container-overcommit-ratio must be greater than zero, got 0`,
+	}, {
+		about:       "Valid tools download concurrency",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"tools-download-concurrency": 5,
+		}),
+	}, {
+		about:       "Invalid tools download concurrency",
+		useDefaults: config.UseDefaults,
+		attrs: sampleConfig.Merge(testing.Attrs{
+			"tools-download-concurrency": -1,
+		}),
+		err: `tools-download-concurrency must not be negative, got -1`,
 	},
 	authTokenConfigTest("token=value, tokensecret=value", true),
 	authTokenConfigTest("token=value, ", true),
@@ -1301,6 +1354,32 @@ func (s *ConfigSuite) TestLoggingConfigFromEnvironment(c *gc.C) {
 	c.Assert(config.LoggingConfig(), gc.Equals, "<root>=INFO;unit=DEBUG")
 }
 
+func (s *ConfigSuite) TestResourceTags(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{
+		"resource-tags": "team=ops  cost-centre=4242 empty=",
+	})
+	c.Assert(config.ResourceTags(), jc.DeepEquals, map[string]string{
+		"team":        "ops",
+		"cost-centre": "4242",
+		"empty":       "",
+	})
+
+	config = newTestConfig(c, nil)
+	c.Assert(config.ResourceTags(), gc.HasLen, 0)
+}
+
+func (s *ConfigSuite) TestToolsDownloadConcurrency(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{
+		"tools-download-concurrency": 5,
+	})
+	c.Assert(config.ToolsDownloadConcurrency(), gc.Equals, 5)
+
+	config = newTestConfig(c, nil)
+	c.Assert(config.ToolsDownloadConcurrency(), gc.Equals, 0)
+}
+
 func (s *ConfigSuite) TestProxyValuesWithFallback(c *gc.C) {
 	s.addJujuFiles(c)
 
@@ -171,6 +171,18 @@ func (info *environInfo) SetBootstrapConfig(attrs map[string]interface{}) {
 	info.EnvInfo.Config = attrs
 }
 
+// UpdateBootstrapConfig implements EnvironInfo.UpdateBootstrapConfig.
+func (info *environInfo) UpdateBootstrapConfig(attrs map[string]interface{}) {
+	newConfig := make(map[string]interface{})
+	for k, v := range info.EnvInfo.Config {
+		newConfig[k] = v
+	}
+	for k, v := range attrs {
+		newConfig[k] = v
+	}
+	info.EnvInfo.Config = newConfig
+}
+
 // SetAPIEndpoint implements EnvironInfo.SetAPIEndpoint.
 func (info *environInfo) SetAPIEndpoint(endpoint APIEndpoint) {
 	info.EnvInfo.StateServers = endpoint.Addresses
@@ -74,6 +74,13 @@ type EnvironInfo interface {
 	// obtained using ConfigStorage.CreateInfo.
 	SetBootstrapConfig(map[string]interface{})
 
+	// UpdateBootstrapConfig updates the given configuration
+	// attributes in the bootstrap configuration. Unlike
+	// SetBootstrapConfig, it may be called on an EnvironInfo
+	// for an environment that has already been bootstrapped;
+	// it is used to record a replacement CA certificate and key.
+	UpdateBootstrapConfig(attrs map[string]interface{})
+
 	// SetAPIEndpoint sets the API endpoint information
 	// currently associated with the environment.
 	SetAPIEndpoint(APIEndpoint)
@@ -176,3 +176,28 @@ func (s *interfaceSuite) TestSetBootstrapConfigPanicsWhenNotCreated(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(func() { info.SetBootstrapConfig(nil) }, gc.PanicMatches, "bootstrap config set on environment info that has not just been created")
 }
+
+func (s *interfaceSuite) TestUpdateBootstrapConfig(c *gc.C) {
+	store := s.NewStore(c)
+
+	info, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	attrs := map[string]interface{}{"foo": "bar", "baz": "qux"}
+	info.SetBootstrapConfig(attrs)
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+
+	info, err = store.ReadInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	info.UpdateBootstrapConfig(map[string]interface{}{"foo": "different"})
+	c.Assert(attrs["foo"], gc.Equals, "bar")
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+
+	info, err = store.ReadInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.BootstrapConfig(), gc.DeepEquals, map[string]interface{}{
+		"foo": "different",
+		"baz": "qux",
+	})
+}
@@ -0,0 +1,32 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/instance"
+)
+
+// InstanceCost describes the instance type of an instance and what it
+// is estimated to cost.
+type InstanceCost struct {
+	// InstanceType is the name of the instance's type.
+	InstanceType string
+
+	// Cost is the estimated cost of running the instance, in
+	// thousandths of a US dollar per hour. It is zero if the cost
+	// is not known.
+	Cost uint64
+}
+
+// InstanceCoster is implemented by environments whose instances are of
+// fixed instance types with known prices.
+type InstanceCoster interface {
+	// InstanceCosts returns the instance type and estimated cost of
+	// each of the instances with the given ids. As with
+	// Environ.Instances, the error is ErrNoInstances if none of the
+	// instances were found, or ErrPartialInstances if only some of
+	// them were found, in which case the entries for the others are
+	// left empty.
+	InstanceCosts(ids []instance.Id) ([]InstanceCost, error)
+}
@@ -0,0 +1,19 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/instance"
+)
+
+// InterruptionReporter is implemented by environments whose instances
+// may be terminated by the cloud itself, such as EC2 spot instances
+// that are outbid.
+type InterruptionReporter interface {
+	// InterruptedInstances returns those of the given instance ids
+	// whose instances the cloud has terminated or is about to
+	// terminate, mapped to a description of the reason. Instances
+	// that have already been terminated are included.
+	InterruptedInstances(ids []instance.Id) (map[instance.Id]string, error)
+}
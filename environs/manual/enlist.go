@@ -0,0 +1,115 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// DefaultParallelism is the number of machines provisioned at once
+// by ProvisionMachines when no other number is specified.
+const DefaultParallelism = 5
+
+// ProvisionMachinesArgs holds the arguments to ProvisionMachines.
+type ProvisionMachinesArgs struct {
+	// Hosts holds the SSH hosts to provision, each as [user@]host.
+	Hosts []string
+
+	// Parallelism is the maximum number of hosts to provision at
+	// once. If it is zero, DefaultParallelism is used.
+	Parallelism int
+
+	// EnvName, Stdin, Stdout and Stderr are as for
+	// ProvisionMachineArgs, and are shared by all hosts.
+	EnvName string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+// ProvisionResult holds the result of provisioning a single host.
+type ProvisionResult struct {
+	Host      string
+	MachineId string
+	Error     error
+}
+
+// provisionMachine is called by ProvisionMachines for each host; it
+// is a variable so that tests can intercept it.
+var provisionMachine = ProvisionMachine
+
+// ProvisionMachines provisions machine agents to a number of existing
+// hosts in parallel. The series and hardware characteristics of each
+// host are detected separately. Any prompts for a password while
+// initialising a host are made one host at a time.
+//
+// The results are returned in the order of the given hosts; a failure
+// to provision one host does not affect the others.
+func ProvisionMachines(args ProvisionMachinesArgs) []ProvisionResult {
+	parallelism := args.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	results := make([]ProvisionResult, len(args.Hosts))
+	var initLock sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+	for i, host := range args.Hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			logger.Infof("provisioning %s", host)
+			machineId, err := provisionMachine(ProvisionMachineArgs{
+				Host:     host,
+				EnvName:  args.EnvName,
+				Stdin:    args.Stdin,
+				Stdout:   args.Stdout,
+				Stderr:   args.Stderr,
+				initLock: &initLock,
+			})
+			results[i] = ProvisionResult{
+				Host:      host,
+				MachineId: machineId,
+				Error:     err,
+			}
+		}(i, host)
+	}
+	wg.Wait()
+	return results
+}
+
+// ParseHosts reads a list of hosts to provision, one [user@]host per
+// line. An "ssh:" prefix, as accepted by "juju add-machine", is
+// allowed. Blank lines and lines starting with "#" are ignored.
+func ParseHosts(r io.Reader) ([]string, error) {
+	var hosts []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		host := strings.TrimPrefix(line, "ssh:")
+		if host == "" || strings.ContainsAny(host, " \t") {
+			return nil, fmt.Errorf("line %d: invalid host %q", lineNum, line)
+		}
+		_, hostname := splitUserHost(host)
+		if seen[hostname] {
+			return nil, fmt.Errorf("line %d: duplicate host %q", lineNum, hostname)
+		}
+		seen[hostname] = true
+		hosts = append(hosts, host)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
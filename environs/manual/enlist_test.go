@@ -0,0 +1,92 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/testing"
+)
+
+type enlistSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&enlistSuite{})
+
+func (s *enlistSuite) TestProvisionMachines(c *gc.C) {
+	var mu sync.Mutex
+	var running, maxRunning int
+	s.PatchValue(manual.ProvisionMachineFunc, func(args manual.ProvisionMachineArgs) (string, error) {
+		c.Check(args.EnvName, gc.Equals, "envname")
+		c.Check(manual.InitLock(args), gc.NotNil)
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		if args.Host == "ubuntu@host2" {
+			return "", fmt.Errorf("no route to host")
+		}
+		return strings.TrimPrefix(args.Host, "ubuntu@host"), nil
+	})
+	results := manual.ProvisionMachines(manual.ProvisionMachinesArgs{
+		Hosts:       []string{"ubuntu@host1", "ubuntu@host2", "ubuntu@host3", "ubuntu@host4"},
+		Parallelism: 2,
+		EnvName:     "envname",
+	})
+	c.Assert(results, gc.HasLen, 4)
+	for i, result := range results {
+		c.Check(result.Host, gc.Equals, fmt.Sprintf("ubuntu@host%d", i+1))
+	}
+	c.Check(results[0].MachineId, gc.Equals, "1")
+	c.Check(results[0].Error, gc.IsNil)
+	c.Check(results[1].MachineId, gc.Equals, "")
+	c.Check(results[1].Error, gc.ErrorMatches, "no route to host")
+	c.Check(results[3].MachineId, gc.Equals, "4")
+	c.Check(maxRunning <= 2, gc.Equals, true)
+}
+
+func (s *enlistSuite) TestParseHosts(c *gc.C) {
+	hosts, err := manual.ParseHosts(strings.NewReader(`
+# web servers
+web1.example.com
+  ubuntu@web2.example.com
+ssh:10.0.0.3
+
+`))
+	c.Assert(err, gc.IsNil)
+	c.Assert(hosts, gc.DeepEquals, []string{"web1.example.com", "ubuntu@web2.example.com", "10.0.0.3"})
+}
+
+func (s *enlistSuite) TestParseHostsErrors(c *gc.C) {
+	for i, test := range []struct {
+		input string
+		err   string
+	}{{
+		input: "web1 web2",
+		err:   `line 1: invalid host "web1 web2"`,
+	}, {
+		input: "# comment\nssh:",
+		err:   `line 2: invalid host "ssh:"`,
+	}, {
+		input: "web1\nubuntu@web1",
+		err:   `line 2: duplicate host "web1"`,
+	}} {
+		c.Logf("test %d: %q", i, test.input)
+		_, err := manual.ParseHosts(strings.NewReader(test.input))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
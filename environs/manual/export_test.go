@@ -3,9 +3,14 @@
 
 package manual
 
+import (
+	"sync"
+)
+
 var (
 	NetLookupHost         = &netLookupHost
 	ProvisionMachineAgent = &provisionMachineAgent
+	ProvisionMachineFunc  = &provisionMachine
 	CheckProvisioned      = checkProvisioned
 )
 
@@ -13,3 +18,7 @@ const (
 	DetectionScript        = detectionScript
 	CheckProvisionedScript = checkProvisionedScript
 )
+
+func InitLock(args ProvisionMachineArgs) sync.Locker {
+	return args.initLock
+}
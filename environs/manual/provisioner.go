@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
@@ -55,6 +56,11 @@ type ProvisionMachineArgs struct {
 
 	// Stderr is required to present machine provisioning progress to the user.
 	Stderr io.Writer
+
+	// initLock, if not nil, is held while initialising the ubuntu
+	// user, so that sudo prompts for several machines being
+	// provisioned at once are presented one at a time.
+	initLock sync.Locker
 }
 
 // ErrProvisioned is returned by ProvisionMachine if the target
@@ -89,7 +95,14 @@ func ProvisionMachine(args ProvisionMachineArgs) (machineId string, err error) {
 	// ubuntu user's authorized_keys.
 	user, hostname := splitUserHost(args.Host)
 	authorizedKeys, err := config.ReadAuthorizedKeys("")
-	if err := InitUbuntuUser(hostname, user, authorizedKeys, args.Stdin, args.Stdout); err != nil {
+	if args.initLock != nil {
+		args.initLock.Lock()
+	}
+	err = InitUbuntuUser(hostname, user, authorizedKeys, args.Stdin, args.Stdout)
+	if args.initLock != nil {
+		args.initLock.Unlock()
+	}
+	if err != nil {
 		return "", err
 	}
 
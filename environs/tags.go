@@ -0,0 +1,104 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+)
+
+const (
+	// TagEnvironUUID is the name of the instance tag holding the UUID
+	// of the environment the instance belongs to.
+	TagEnvironUUID = config.ResourceTagPrefix + "env-uuid"
+
+	// TagMachineId is the name of the instance tag holding the id of
+	// the machine the instance was started for.
+	TagMachineId = config.ResourceTagPrefix + "machine-id"
+
+	// TagUnits is the name of the instance tag holding the
+	// comma-separated names of the principal units hosted by the
+	// instance's machine.
+	TagUnits = config.ResourceTagPrefix + "units"
+
+	// TagResourceTags is the name of the instance tag holding the
+	// space-separated names of the user-defined resource tags set on
+	// the instance, so that they can be removed from the instance
+	// once they are no longer wanted.
+	TagResourceTags = config.ResourceTagPrefix + "resource-tags"
+)
+
+// InstanceTagger is implemented by environments that can attach tags
+// (key/value metadata) to their instances, so that the instances may be
+// identified in the cloud's own tools and billing reports.
+type InstanceTagger interface {
+	// TagInstance sets the given tags on the instance with the given
+	// id, replacing the values of any existing tags with the same
+	// names, and removes the tags previously set by juju that are not
+	// named, as reported by StaleInstanceTags. Other tags are left
+	// alone.
+	TagInstance(id instance.Id, tags map[string]string) error
+}
+
+// InstanceTags returns the tags that should be set on the instance of
+// the machine with the given id, in the environment with the given UUID
+// and configuration, hosting the given principal units.
+func InstanceTags(envUUID, machineId string, units []string, cfg *config.Config) map[string]string {
+	tags := cfg.ResourceTags()
+	var names []string
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tags[TagResourceTags] = strings.Join(names, " ")
+	tags[TagEnvironUUID] = envUUID
+	tags[TagMachineId] = machineId
+	tags[TagUnits] = strings.Join(units, ",")
+	return tags
+}
+
+// StaleInstanceTags returns the sorted names of the tags, out of those
+// currently set on an instance, that were set by juju but are not among
+// the given tags, and so should be removed from the instance. The tags
+// set by juju are those with the reserved prefix, and the user-defined
+// tags named by the current TagResourceTags tag.
+func StaleInstanceTags(current, tags map[string]string) []string {
+	managed := strings.Fields(current[TagResourceTags])
+	for name := range current {
+		if strings.HasPrefix(name, config.ResourceTagPrefix) {
+			managed = append(managed, name)
+		}
+	}
+	var stale []string
+	seen := make(map[string]bool)
+	for _, name := range managed {
+		if _, ok := current[name]; !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, ok := tags[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// TruncateTagValue returns the given tag value, truncated if necessary
+// to at most maxLen bytes without splitting a UTF-8 encoded character.
+func TruncateTagValue(value string, maxLen int) string {
+	if len(value) <= maxLen {
+		return value
+	}
+	// Cut before the character that the byte after the limit belongs
+	// to.
+	for maxLen > 0 && !utf8.RuneStart(value[maxLen]) {
+		maxLen--
+	}
+	return value[:maxLen]
+}
@@ -0,0 +1,87 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/testing"
+)
+
+type TagsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&TagsSuite{})
+
+func (s *TagsSuite) TestInstanceTags(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{
+		"resource-tags": "team=ops",
+	})
+	tags := environs.InstanceTags("env-uuid", "1", []string{"mysql/0", "wordpress/1"}, cfg)
+	c.Assert(tags, jc.DeepEquals, map[string]string{
+		"team":               "ops",
+		"juju-resource-tags": "team",
+		"juju-env-uuid":      "env-uuid",
+		"juju-machine-id":    "1",
+		"juju-units":         "mysql/0,wordpress/1",
+	})
+}
+
+func (s *TagsSuite) TestInstanceTagsNoUnits(c *gc.C) {
+	tags := environs.InstanceTags("env-uuid", "0", nil, testing.EnvironConfig(c))
+	c.Assert(tags, jc.DeepEquals, map[string]string{
+		"juju-resource-tags": "",
+		"juju-env-uuid":      "env-uuid",
+		"juju-machine-id":    "0",
+		"juju-units":         "",
+	})
+}
+
+func (s *TagsSuite) TestStaleInstanceTags(c *gc.C) {
+	current := map[string]string{
+		"team":               "ops",
+		"cost-centre":        "42",
+		"owner":              "bob",
+		"juju-resource-tags": "cost-centre team",
+		"juju-env-uuid":      "env-uuid",
+		"juju-machine-id":    "1",
+		"juju-units":         "",
+		"juju-obsolete":      "x",
+	}
+	tags := map[string]string{
+		"team":               "dev",
+		"juju-resource-tags": "team",
+		"juju-env-uuid":      "env-uuid",
+		"juju-machine-id":    "1",
+		"juju-units":         "mysql/0",
+	}
+	// Tags not set by juju, such as "owner", are left alone.
+	stale := environs.StaleInstanceTags(current, tags)
+	c.Assert(stale, jc.DeepEquals, []string{"cost-centre", "juju-obsolete"})
+
+	c.Assert(environs.StaleInstanceTags(nil, tags), gc.HasLen, 0)
+}
+
+func (s *TagsSuite) TestTruncateTagValue(c *gc.C) {
+	for i, test := range []struct {
+		value  string
+		maxLen int
+		expect string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 5, "abcde"},
+		// "é" is encoded in two bytes.
+		{"abcdé", 5, "abcd"},
+		{"abcdé", 6, "abcdé"},
+		// "€" is encoded in three bytes.
+		{"ab€", 4, "ab"},
+		{"€€", 2, ""},
+	} {
+		c.Logf("test %d: %q, %d", i, test.value, test.maxLen)
+		c.Assert(environs.TruncateTagValue(test.value, test.maxLen), gc.Equals, test.expect)
+	}
+}
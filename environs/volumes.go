@@ -0,0 +1,83 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/instance"
+)
+
+// VolumeParams holds parameters for the VolumeSource.CreateVolume
+// method.
+type VolumeParams struct {
+	// Name is a name for the volume that is unique within the
+	// environment, which the provider may use to label it.
+	Name string
+
+	// Pool holds the name of the storage pool the volume is
+	// created in.
+	Pool string
+
+	// Size holds the minimum size of the volume in MiB.
+	Size uint64
+
+	// InstanceId holds the id of the instance the volume will be
+	// attached to, so that the provider may create the volume
+	// somewhere it can be attached, such as the same availability
+	// zone.
+	InstanceId instance.Id
+}
+
+// Volume describes a block storage volume created by a VolumeSource.
+type Volume struct {
+	// Id holds the provider-specific id of the volume.
+	Id string
+
+	// Size holds the actual size of the volume in MiB.
+	Size uint64
+}
+
+// VolumeSource is implemented by environments that can provide
+// persistent block storage volumes that are attached to instances.
+// Volumes are not destroyed when they are detached.
+type VolumeSource interface {
+	// StoragePools returns the names of the storage pools in which
+	// volumes can be created.
+	StoragePools() []string
+
+	// CreateVolume creates a new volume.
+	CreateVolume(params VolumeParams) (Volume, error)
+
+	// AttachVolume attaches the volume with the given id to the
+	// instance, and returns the name of the block device through
+	// which it may be accessed on the instance.
+	AttachVolume(volumeId string, instId instance.Id) (string, error)
+
+	// DetachVolume detaches the volume with the given id from the
+	// instance. It is not an error to detach a volume that is not
+	// attached.
+	DetachVolume(volumeId string, instId instance.Id) error
+}
+
+// ValidateStoragePool returns an error if the environment cannot
+// create volumes in the named storage pool.
+func ValidateStoragePool(env Environ, pool string) error {
+	source, ok := env.(VolumeSource)
+	if !ok {
+		return fmt.Errorf("storage volumes not supported by %q provider", env.Config().Type())
+	}
+	for _, supported := range source.StoragePools() {
+		if pool == supported {
+			return nil
+		}
+	}
+	return UnsupportedPoolError(pool)
+}
+
+// UnsupportedPoolError returns an error reporting that volumes cannot be
+// created in the given storage pool.
+func UnsupportedPoolError(pool string) error {
+	return fmt.Errorf("storage pool %q not supported", pool)
+}
@@ -10,15 +10,17 @@ import (
 type ContainerType string
 
 const (
-	NONE = ContainerType("none")
-	LXC  = ContainerType("lxc")
-	KVM  = ContainerType("kvm")
+	NONE   = ContainerType("none")
+	LXC    = ContainerType("lxc")
+	KVM    = ContainerType("kvm")
+	DOCKER = ContainerType("docker")
 )
 
 // ContainerTypes is used to validate add-machine arguments.
 var ContainerTypes []ContainerType = []ContainerType{
 	LXC,
 	KVM,
+	DOCKER,
 }
 
 // ParseContainerTypeOrNone converts the specified string into a supported
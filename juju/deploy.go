@@ -32,6 +32,12 @@ type DeployServiceParams struct {
 	ToMachineSpec string
 	// Networks holds a list of networks to required to start on boot.
 	Networks []string
+	// Bindings maps relation endpoint names to the networks they are
+	// bound to. Bound networks are added to Networks if necessary.
+	Bindings map[string]string
+	// Storage holds the block storage volumes required by each unit
+	// of the service, keyed by storage name.
+	Storage map[string]state.StorageDirective
 }
 
 // DeployService takes a charm and various parameters and deploys it.
@@ -56,6 +62,14 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 	}
 	// TODO(fwereade): transactional State.AddService including settings, constraints
 	// (minimumUnitCount, initialMachineIds?).
+	for endpoint, network := range args.Bindings {
+		if !hasRelation(args.Charm.Meta(), endpoint) {
+			return nil, fmt.Errorf("cannot bind endpoint %q: charm %q has no such relation", endpoint, args.Charm.URL())
+		}
+		if !containsString(args.Networks, network) {
+			args.Networks = append(args.Networks, network)
+		}
+	}
 	if len(args.Networks) > 0 || args.Constraints.HaveNetworks() {
 		conf, err := st.EnvironConfig()
 		if err != nil {
@@ -83,6 +97,16 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 			return nil, err
 		}
 	}
+	if len(args.Bindings) > 0 {
+		if err := service.SetEndpointBindings(args.Bindings); err != nil {
+			return nil, err
+		}
+	}
+	if len(args.Storage) > 0 {
+		if err := service.SetStorageDirectives(args.Storage); err != nil {
+			return nil, err
+		}
+	}
 	if args.Charm.Meta().Subordinate {
 		return service, nil
 	}
@@ -99,6 +123,30 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 	return service, nil
 }
 
+// hasRelation reports whether the charm metadata declares a relation
+// with the given name.
+func hasRelation(meta *charm.Meta, name string) bool {
+	if name == "juju-info" {
+		// Every charm has an implicit juju-info relation.
+		return true
+	}
+	for _, relations := range []map[string]charm.Relation{meta.Provides, meta.Requires, meta.Peers} {
+		if _, ok := relations[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // AddUnits starts n units of the given service and allocates machines
 // to them as necessary.
 func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string) ([]*state.Unit, error) {
@@ -8,6 +8,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"os/exec"
@@ -197,15 +198,27 @@ func EnsureServer(args EnsureServerParams) error {
 		return err
 	}
 	svc := &upstartConf.Service
+	certKey := args.Cert + "\n" + args.PrivateKey
 	if upstartConfExists(upstartConf) {
 		logger.Debugf("mongo exists as expected")
+		changed, err := updateSSLKey(args.DataDir, certKey)
+		if err != nil {
+			return err
+		}
+		if changed && upstartServiceRunning(svc) {
+			// The state server certificate has been rotated;
+			// mongod only reads it at startup.
+			logger.Infof("state server certificate changed; restarting mongo")
+			if err := upstartServiceStop(svc); err != nil {
+				return fmt.Errorf("failed to stop mongo: %v", err)
+			}
+		}
 		if !upstartServiceRunning(svc) {
 			return upstartServiceStart(svc)
 		}
 		return nil
 	}
 
-	certKey := args.Cert + "\n" + args.PrivateKey
 	err = utils.AtomicWriteFile(sslKeyPath(args.DataDir), []byte(certKey), 0600)
 	if err != nil {
 		return fmt.Errorf("cannot write SSL key: %v", err)
@@ -275,6 +288,27 @@ func logVersion(mongoPath string) {
 	logger.Debugf("using mongod: %s --version: %q", mongoPath, output)
 }
 
+// updateSSLKey rewrites the SSL key file in the given data directory
+// if it exists and holds something other than the given certificate
+// and key, and reports whether it did so.
+func updateSSLKey(dataDir, certKey string) (bool, error) {
+	current, err := ioutil.ReadFile(sslKeyPath(dataDir))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot read SSL key: %v", err)
+	}
+	if string(current) == certKey {
+		return false, nil
+	}
+	err = utils.AtomicWriteFile(sslKeyPath(dataDir), []byte(certKey), 0600)
+	if err != nil {
+		return false, fmt.Errorf("cannot write SSL key: %v", err)
+	}
+	return true, nil
+}
+
 func sslKeyPath(dataDir string) string {
 	return filepath.Join(dataDir, "server.pem")
 }
@@ -207,6 +207,46 @@ func (s *MongoSuite) TestEnsureServerServerExistsAndRunning(c *gc.C) {
 	c.Assert(s.installed, gc.HasLen, 0)
 }
 
+func (s *MongoSuite) TestEnsureServerServerExistsCertChanged(c *gc.C) {
+	dataDir := c.MkDir()
+	namespace := "namespace"
+	mockShellCommand(c, &s.CleanupSuite, "apt-get")
+	err := ioutil.WriteFile(mongo.SSLKeyPath(dataDir), []byte("old-cert\nold-privkey"), 0600)
+	c.Assert(err, gc.IsNil)
+
+	running := true
+	s.PatchValue(mongo.UpstartConfExists, func(svc *upstart.Conf) bool {
+		return true
+	})
+	s.PatchValue(mongo.UpstartServiceRunning, func(svc *upstart.Service) bool {
+		return running
+	})
+	s.PatchValue(mongo.UpstartServiceStart, func(svc *upstart.Service) error {
+		running = true
+		return nil
+	})
+	var stops int
+	s.PatchValue(mongo.UpstartServiceStop, func(svc *upstart.Service) error {
+		stops++
+		running = false
+		return nil
+	})
+
+	err = mongo.EnsureServer(makeEnsureServerParams(dataDir, namespace))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.installed, gc.HasLen, 0)
+	c.Assert(stops, gc.Equals, 1)
+	c.Assert(running, jc.IsTrue)
+	contents, err := ioutil.ReadFile(mongo.SSLKeyPath(dataDir))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(contents), gc.Equals, testInfo.Cert+"\n"+testInfo.PrivateKey)
+
+	// A second call with the same certificate leaves mongo alone.
+	err = mongo.EnsureServer(makeEnsureServerParams(dataDir, namespace))
+	c.Assert(err, gc.IsNil)
+	c.Assert(stops, gc.Equals, 1)
+}
+
 func (s *MongoSuite) TestEnsureServerServerExistsNotRunningIsStarted(c *gc.C) {
 	dataDir := c.MkDir()
 	namespace := "namespace"
@@ -5,7 +5,6 @@ package mongo
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	stderrors "errors"
 	"fmt"
 	"net"
@@ -80,12 +79,10 @@ func DialInfo(info Info, opts DialOpts) (*mgo.DialInfo, error) {
 	if len(info.CACert) == 0 {
 		return nil, stderrors.New("missing CA certificate")
 	}
-	xcert, err := cert.ParseCert(info.CACert)
+	pool, err := cert.NewCertPool(info.CACert)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse CA certificate: %v", err)
 	}
-	pool := x509.NewCertPool()
-	pool.AddCert(xcert)
 	tlsConfig := &tls.Config{
 		RootCAs:    pool,
 		ServerName: "anything",
@@ -99,6 +99,13 @@ var _ simplestreams.HasRegion = (*azureEnviron)(nil)
 var _ imagemetadata.SupportsCustomSources = (*azureEnviron)(nil)
 var _ envtools.SupportsCustomSources = (*azureEnviron)(nil)
 var _ state.Prechecker = (*azureEnviron)(nil)
+var _ environs.InstanceCoster = (*azureEnviron)(nil)
+
+// azureEnviron does not implement environs.InstanceTagger. Each instance
+// is a role in a hosted service, and roles cannot carry metadata; the
+// hosted service's extended properties are shared by all the roles in
+// it, which is every instance of a service when availability sets are
+// enabled, so they cannot hold per-instance tags.
 
 // NewEnviron creates a new azureEnviron.
 func NewEnviron(cfg *config.Config) (*azureEnviron, error) {
@@ -550,6 +557,7 @@ func (env *azureEnviron) selectInstanceTypeAndImage(constraint *instances.Instan
 var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.Tags,
+	constraints.SpotPrice,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -1081,6 +1089,39 @@ func (env *azureEnviron) Instances(ids []instance.Id) ([]instance.Instance, erro
 	return instances, err
 }
 
+// InstanceCosts implements environs.InstanceCoster.InstanceCosts.
+func (env *azureEnviron) InstanceCosts(ids []instance.Id) ([]environs.InstanceCost, error) {
+	insts, err := env.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	location := env.getSnapshot().ecfg.location()
+	costs := make([]environs.InstanceCost, len(insts))
+	for i, inst := range insts {
+		if inst != nil {
+			costs[i] = instanceCost(location, inst.(*azureInstance))
+		}
+	}
+	return costs, err
+}
+
+// instanceCost returns the role size of the given instance and its
+// cost in the given location.
+func instanceCost(location string, inst *azureInstance) environs.InstanceCost {
+	size := inst.instanceSize()
+	if size == "" {
+		return environs.InstanceCost{}
+	}
+	cost, err := roleSizeCost(location, size)
+	if err != nil {
+		logger.Warningf("cannot determine cost of role size %q in %q: %v", size, location, err)
+	}
+	return environs.InstanceCost{
+		InstanceType: size,
+		Cost:         cost,
+	}
+}
+
 // AllocateAddress requests a new address to be allocated for the
 // given instance on the given network. This is not implemented on the
 // Azure provider yet.
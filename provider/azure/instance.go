@@ -56,6 +56,17 @@ func (azInstance *azureInstance) Status() string {
 	return azInstance.roleInstance.InstanceStatus
 }
 
+// instanceSize returns the role size of the instance, or "" if
+// it is not known.
+func (azInstance *azureInstance) instanceSize() string {
+	azInstance.mu.Lock()
+	defer azInstance.mu.Unlock()
+	if azInstance.roleInstance == nil {
+		return ""
+	}
+	return azInstance.roleInstance.InstanceSize
+}
+
 func (azInstance *azureInstance) serviceName() string {
 	return azInstance.hostedService.ServiceName
 }
@@ -12,6 +12,7 @@ import (
 	gc "launchpad.net/gocheck"
 	"launchpad.net/gwacl"
 
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/testing"
@@ -386,3 +387,17 @@ func (s *instanceSuite) testPorts(c *gc.C, maskStateServerPorts bool) {
 	}
 	c.Check(ports, gc.DeepEquals, expected)
 }
+
+func (s *instanceSuite) TestInstanceCost(c *gc.C) {
+	s.PatchValue(&roleSizeCost, func(region, roleSize string) (uint64, error) {
+		c.Check(region, gc.Equals, "West US")
+		c.Check(roleSize, gc.Equals, "Small")
+		return 60, nil
+	})
+	c.Check(instanceCost("West US", s.instance), gc.Equals, environs.InstanceCost{})
+	s.instance.roleInstance = &gwacl.RoleInstance{InstanceSize: "Small"}
+	c.Check(instanceCost("West US", s.instance), gc.Equals, environs.InstanceCost{
+		InstanceType: "Small",
+		Cost:         60,
+	})
+}
@@ -189,6 +189,8 @@ type environState struct {
 	maxId        int // maximum instance id allocated so far.
 	maxAddr      int // maximum allocated address last byte
 	insts        map[instance.Id]*dummyInstance
+	maxVolume    int // maximum volume id allocated so far.
+	volumes      map[string]*dummyVolume
 	globalPorts  map[network.Port]bool
 	bootstrapped bool
 	storageDelay time.Duration
@@ -211,6 +213,10 @@ type environ struct {
 var _ imagemetadata.SupportsCustomSources = (*environ)(nil)
 var _ tools.SupportsCustomSources = (*environ)(nil)
 var _ environs.Environ = (*environ)(nil)
+var _ environs.VolumeSource = (*environ)(nil)
+var _ environs.InstanceTagger = (*environ)(nil)
+var _ environs.InterruptionReporter = (*environ)(nil)
+var _ environs.InstanceCoster = (*environ)(nil)
 
 // discardOperations discards all Operations written to it.
 var discardOperations chan<- Operation
@@ -301,6 +307,7 @@ func newState(name string, ops chan<- Operation, policy state.Policy) *environSt
 		ops:         ops,
 		statePolicy: policy,
 		insts:       make(map[instance.Id]*dummyInstance),
+		volumes:     make(map[string]*dummyVolume),
 		globalPorts: make(map[network.Port]bool),
 	}
 	s.storage = newStorageServer(s, "/"+name+"/private")
@@ -927,6 +934,219 @@ func (env *environ) AllocateAddress(instId instance.Id, netId network.Id) (netwo
 	return newAddress, nil
 }
 
+// dummyVolume represents a volume created by the dummy environment,
+// which behaves like a loop device.
+type dummyVolume struct {
+	size     uint64
+	instance instance.Id
+	device   string
+}
+
+// StoragePools implements environs.VolumeSource.StoragePools.
+func (env *environ) StoragePools() []string {
+	return []string{"loop"}
+}
+
+// CreateVolume implements environs.VolumeSource.CreateVolume. Volumes
+// may only be created in the "loop" pool.
+func (env *environ) CreateVolume(args environs.VolumeParams) (environs.Volume, error) {
+	if err := env.checkBroken("CreateVolume"); err != nil {
+		return environs.Volume{}, err
+	}
+	if args.Pool != "loop" {
+		return environs.Volume{}, environs.UnsupportedPoolError(args.Pool)
+	}
+	estate, err := env.state()
+	if err != nil {
+		return environs.Volume{}, err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	id := fmt.Sprintf("vol-%d", estate.maxVolume)
+	estate.maxVolume++
+	estate.volumes[id] = &dummyVolume{size: args.Size}
+	return environs.Volume{Id: id, Size: args.Size}, nil
+}
+
+// AttachVolume implements environs.VolumeSource.AttachVolume.
+func (env *environ) AttachVolume(volumeId string, instId instance.Id) (string, error) {
+	if err := env.checkBroken("AttachVolume"); err != nil {
+		return "", err
+	}
+	estate, err := env.state()
+	if err != nil {
+		return "", err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	volume := estate.volumes[volumeId]
+	if volume == nil {
+		return "", fmt.Errorf("volume %q not found", volumeId)
+	}
+	if estate.insts[instId] == nil {
+		return "", fmt.Errorf("instance %q not found", instId)
+	}
+	if volume.instance != "" && volume.instance != instId {
+		return "", fmt.Errorf("volume %q is attached to instance %q", volumeId, volume.instance)
+	}
+	volume.instance = instId
+	volume.device = "/dev/loop" + strings.TrimPrefix(volumeId, "vol-")
+	return volume.device, nil
+}
+
+// DetachVolume implements environs.VolumeSource.DetachVolume.
+func (env *environ) DetachVolume(volumeId string, instId instance.Id) error {
+	if err := env.checkBroken("DetachVolume"); err != nil {
+		return err
+	}
+	estate, err := env.state()
+	if err != nil {
+		return err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	volume := estate.volumes[volumeId]
+	if volume == nil {
+		return fmt.Errorf("volume %q not found", volumeId)
+	}
+	if volume.instance == instId {
+		volume.instance = ""
+		volume.device = ""
+	}
+	return nil
+}
+
+// VolumeAttachment returns the id of the instance the volume with the
+// given id is attached to, or the empty string if it is not attached.
+// It is intended for use in tests.
+func VolumeAttachment(env environs.Environ, volumeId string) (instance.Id, error) {
+	estate, err := env.(*environ).state()
+	if err != nil {
+		return "", err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	volume := estate.volumes[volumeId]
+	if volume == nil {
+		return "", fmt.Errorf("volume %q not found", volumeId)
+	}
+	return volume.instance, nil
+}
+
+// TagInstance implements environs.InstanceTagger.TagInstance.
+func (env *environ) TagInstance(id instance.Id, tags map[string]string) error {
+	if err := env.checkBroken("TagInstance"); err != nil {
+		return err
+	}
+	estate, err := env.state()
+	if err != nil {
+		return err
+	}
+	estate.mu.Lock()
+	inst := estate.insts[id]
+	estate.mu.Unlock()
+	if inst == nil {
+		return fmt.Errorf("instance %q not found", id)
+	}
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if inst.tags == nil {
+		inst.tags = make(map[string]string)
+	}
+	for _, k := range environs.StaleInstanceTags(inst.tags, tags) {
+		delete(inst.tags, k)
+	}
+	for k, v := range tags {
+		inst.tags[k] = v
+	}
+	return nil
+}
+
+// InstanceTags returns the tags set on the given instance, which must
+// have been started by the dummy provider. It is intended for use in
+// tests.
+func InstanceTags(inst instance.Instance) map[string]string {
+	dinst := inst.(*dummyInstance)
+	dinst.mu.Lock()
+	defer dinst.mu.Unlock()
+	tags := make(map[string]string)
+	for k, v := range dinst.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// InterruptedInstances implements environs.InterruptionReporter.InterruptedInstances.
+func (env *environ) InterruptedInstances(ids []instance.Id) (map[instance.Id]string, error) {
+	if err := env.checkBroken("InterruptedInstances"); err != nil {
+		return nil, err
+	}
+	estate, err := env.state()
+	if err != nil {
+		return nil, err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	result := make(map[instance.Id]string)
+	for _, id := range ids {
+		inst := estate.insts[id]
+		if inst == nil {
+			continue
+		}
+		inst.mu.Lock()
+		if inst.interrupted != "" {
+			result[id] = inst.interrupted
+		}
+		inst.mu.Unlock()
+	}
+	return result, nil
+}
+
+// InterruptInstance marks the given instance, which must have been
+// started by the dummy provider, as interrupted by the provider for
+// the given reason. It is intended for use in tests.
+func InterruptInstance(inst instance.Instance, reason string) {
+	dinst := inst.(*dummyInstance)
+	dinst.mu.Lock()
+	defer dinst.mu.Unlock()
+	dinst.interrupted = reason
+}
+
+// InstanceCosts implements environs.InstanceCoster.InstanceCosts.
+func (env *environ) InstanceCosts(ids []instance.Id) ([]environs.InstanceCost, error) {
+	if err := env.checkBroken("InstanceCosts"); err != nil {
+		return nil, err
+	}
+	insts, err := env.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	costs := make([]environs.InstanceCost, len(insts))
+	for i, inst := range insts {
+		if inst == nil {
+			continue
+		}
+		dinst := inst.(*dummyInstance)
+		dinst.mu.Lock()
+		costs[i] = dinst.cost
+		dinst.mu.Unlock()
+	}
+	return costs, err
+}
+
+// SetInstanceCost sets the instance type and cost reported by
+// InstanceCosts for the given instance, which must have been started
+// by the dummy provider. It is intended for use in tests.
+func SetInstanceCost(inst instance.Instance, instanceType string, cost uint64) {
+	dinst := inst.(*dummyInstance)
+	dinst.mu.Lock()
+	defer dinst.mu.Unlock()
+	dinst.cost = environs.InstanceCost{
+		InstanceType: instanceType,
+		Cost:         cost,
+	}
+}
+
 // ListNetworks implements environs.Environ.ListNetworks.
 func (env *environ) ListNetworks() ([]network.BasicInfo, error) {
 	if err := env.checkBroken("ListNetworks"); err != nil {
@@ -1031,8 +1251,11 @@ type dummyInstance struct {
 	series       string
 	firewallMode string
 
-	mu        sync.Mutex
-	addresses []network.Address
+	mu          sync.Mutex
+	addresses   []network.Address
+	tags        map[string]string
+	interrupted string
+	cost        environs.InstanceCost
 }
 
 func (inst *dummyInstance) Id() instance.Id {
@@ -5,6 +5,7 @@ package ec2
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/schema"
 	"launchpad.net/goamz/aws"
@@ -17,14 +18,22 @@ var configFields = schema.Fields{
 	"secret-key":     schema.String(),
 	"region":         schema.String(),
 	"control-bucket": schema.String(),
+	"spot-timeout":   schema.ForceInt(),
+	"spot-fallback":  schema.Bool(),
 }
 
 var configDefaults = schema.Defaults{
-	"access-key": "",
-	"secret-key": "",
-	"region":     "us-east-1",
+	"access-key":    "",
+	"secret-key":    "",
+	"region":        "us-east-1",
+	"spot-timeout":  defaultSpotTimeout,
+	"spot-fallback": false,
 }
 
+// defaultSpotTimeout is the default time, in seconds, to wait for a
+// spot instance request to be fulfilled.
+const defaultSpotTimeout = 300
+
 type environConfig struct {
 	*config.Config
 	attrs map[string]interface{}
@@ -46,6 +55,18 @@ func (c *environConfig) secretKey() string {
 	return c.attrs["secret-key"].(string)
 }
 
+// spotTimeout returns how long to wait for a spot instance request to
+// be fulfilled.
+func (c *environConfig) spotTimeout() time.Duration {
+	return time.Duration(c.attrs["spot-timeout"].(int)) * time.Second
+}
+
+// spotFallback reports whether an on-demand instance should be started
+// when a spot instance request is not fulfilled in time.
+func (c *environConfig) spotFallback() bool {
+	return c.attrs["spot-fallback"].(bool)
+}
+
 func (p environProvider) newConfig(cfg *config.Config) (*environConfig, error) {
 	valid, err := p.Validate(cfg, nil)
 	if err != nil {
@@ -72,6 +93,9 @@ func (p environProvider) Validate(cfg, old *config.Config) (valid *config.Config
 		ecfg.attrs["access-key"] = auth.AccessKey
 		ecfg.attrs["secret-key"] = auth.SecretKey
 	}
+	if ecfg.spotTimeout() <= 0 {
+		return nil, fmt.Errorf("spot-timeout must be a positive number of seconds")
+	}
 	if _, ok := aws.Regions[ecfg.region()]; !ok {
 		return nil, fmt.Errorf("invalid region name %q", ecfg.region())
 	}
@@ -229,6 +229,26 @@ var configTests = []configTest{
 		expect: attrs{
 			"future": "hammerstein",
 		},
+	}, {
+		config: attrs{},
+		expect: attrs{
+			"spot-timeout":  300,
+			"spot-fallback": false,
+		},
+	}, {
+		config: attrs{
+			"spot-timeout":  60,
+			"spot-fallback": true,
+		},
+		expect: attrs{
+			"spot-timeout":  60,
+			"spot-fallback": true,
+		},
+	}, {
+		config: attrs{
+			"spot-timeout": 0,
+		},
+		err: "spot-timeout must be a positive number of seconds",
 	},
 }
 
@@ -5,6 +5,7 @@ package ec2
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -77,6 +78,8 @@ var _ imagemetadata.SupportsCustomSources = (*environ)(nil)
 var _ envtools.SupportsCustomSources = (*environ)(nil)
 var _ state.Prechecker = (*environ)(nil)
 var _ state.InstanceDistributor = (*environ)(nil)
+var _ environs.InstanceTagger = (*environ)(nil)
+var _ environs.InstanceCoster = (*environ)(nil)
 
 type ec2Instance struct {
 	e *environ
@@ -196,6 +199,17 @@ amazon:
     #
     # image-stream: "released"
 
+    # spot-timeout is the number of seconds to wait for a spot instance
+    # request, made for a machine with the spot-price constraint, to be
+    # fulfilled. It defaults to 300.
+    #
+    # spot-timeout: 300
+
+    # spot-fallback specifies whether an on-demand instance is started
+    # when a spot instance request is not fulfilled in time.
+    #
+    # spot-fallback: false
+
 `[1:]
 }
 
@@ -613,7 +627,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 
 	device, diskSize := getDiskSize(args.Constraints)
 	for _, availZone := range availabilityZones {
-		instResp, err = runInstances(e.ec2(), &ec2.RunInstances{
+		ri := &ec2.RunInstances{
 			AvailZone:           availZone,
 			ImageId:             spec.Image.Id,
 			MinCount:            1,
@@ -622,7 +636,12 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 			InstanceType:        spec.InstanceType.Name,
 			SecurityGroups:      groups,
 			BlockDeviceMappings: []ec2.BlockDeviceMapping{device},
-		})
+		}
+		if args.Constraints.HasSpotPrice() {
+			instResp, err = e.runSpotInstance(*args.Constraints.SpotPrice, ri)
+		} else {
+			instResp, err = runInstances(e.ec2(), ri)
+		}
 		if isZoneConstrainedError(err) {
 			logger.Infof("%q is constrained, trying another availability zone", availZone)
 		} else {
@@ -668,6 +687,70 @@ func _runInstances(e *ec2.EC2, ri *ec2.RunInstances) (resp *ec2.RunInstancesResp
 	return resp, err
 }
 
+// maxTagValueLength is the maximum length of an EC2 tag value.
+const maxTagValueLength = 255
+
+// TagInstance implements environs.InstanceTagger.TagInstance.
+// Tag values longer than EC2 allows are truncated.
+func (e *environ) TagInstance(id instance.Id, tags map[string]string) error {
+	insts, err := e.Instances([]instance.Id{id})
+	if err != nil {
+		return fmt.Errorf("cannot tag instance %q: %v", id, err)
+	}
+	current := make(map[string]string)
+	for _, tag := range insts[0].(*ec2Instance).getInstance().Tags {
+		current[tag.Key] = tag.Value
+	}
+	// Stale tags are removed first, so that they are still listed
+	// by the current tags if removing them fails.
+	if stale := environs.StaleInstanceTags(current, tags); len(stale) > 0 {
+		staleTags := make([]ec2.Tag, len(stale))
+		for i, key := range stale {
+			staleTags[i] = ec2.Tag{Key: key}
+		}
+		if _, err := e.ec2().DeleteTags([]string{string(id)}, staleTags); err != nil {
+			return fmt.Errorf("cannot remove tags from instance %q: %v", id, err)
+		}
+	}
+	var keys []string
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	ec2Tags := make([]ec2.Tag, len(keys))
+	for i, key := range keys {
+		value := environs.TruncateTagValue(tags[key], maxTagValueLength)
+		ec2Tags[i] = ec2.Tag{Key: key, Value: value}
+	}
+	if _, err := e.ec2().CreateTags([]string{string(id)}, ec2Tags); err != nil {
+		return fmt.Errorf("cannot tag instance %q: %v", id, err)
+	}
+	return nil
+}
+
+// InstanceCosts implements environs.InstanceCoster.InstanceCosts.
+// The cost of an instance is the on-demand price of its type in the
+// environment's region, even if it is a spot instance.
+func (e *environ) InstanceCosts(ids []instance.Id) ([]environs.InstanceCost, error) {
+	insts, err := e.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	regionCosts := allRegionCosts[e.ecfg().region()]
+	costs := make([]environs.InstanceCost, len(insts))
+	for i, inst := range insts {
+		if inst == nil {
+			continue
+		}
+		instanceType := inst.(*ec2Instance).InstanceType
+		costs[i] = environs.InstanceCost{
+			InstanceType: instanceType,
+			Cost:         regionCosts[instanceType],
+		}
+	}
+	return costs, err
+}
+
 func (e *environ) StopInstances(ids ...instance.Id) error {
 	return e.terminateInstances(ids)
 }
@@ -337,6 +337,71 @@ func (t *localServerSuite) TestStartInstanceHardwareCharacteristics(c *gc.C) {
 	c.Assert(*hc.CpuPower, gc.Equals, uint64(100))
 }
 
+func (t *localServerSuite) TestInstanceCosts(c *gc.C) {
+	env := t.Prepare(c)
+	envtesting.UploadFakeTools(c, env.Storage())
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "1")
+
+	coster := env.(environs.InstanceCoster)
+	costs, err := coster.InstanceCosts([]instance.Id{inst.Id(), "i-missing"})
+	c.Assert(err, gc.Equals, environs.ErrPartialInstances)
+	c.Assert(costs, gc.DeepEquals, []environs.InstanceCost{
+		{InstanceType: "m1.small", Cost: 60},
+		{},
+	})
+}
+
+func (t *localServerSuite) instanceTags(c *gc.C, env environs.Environ, id instance.Id) map[string]string {
+	insts, err := env.Instances([]instance.Id{id})
+	c.Assert(err, gc.IsNil)
+	tags := make(map[string]string)
+	for _, tag := range ec2.InstanceEC2(insts[0]).Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
+
+func (t *localServerSuite) TestTagInstance(c *gc.C) {
+	env := t.Prepare(c)
+	envtesting.UploadFakeTools(c, env.Storage())
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "1")
+	tagger := env.(environs.InstanceTagger)
+
+	// "é" is encoded in two bytes, so the long value is truncated
+	// before the character that crosses the limit.
+	long := strings.Repeat("x", 254) + "é"
+	err = tagger.TagInstance(inst.Id(), map[string]string{
+		"team":               "ops",
+		"long":               long,
+		"juju-resource-tags": "long team",
+		"juju-machine-id":    "1",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(t.instanceTags(c, env, inst.Id()), jc.DeepEquals, map[string]string{
+		"team":               "ops",
+		"long":               strings.Repeat("x", 254),
+		"juju-resource-tags": "long team",
+		"juju-machine-id":    "1",
+	})
+
+	// Tags set by juju that are no longer wanted are removed.
+	err = tagger.TagInstance(inst.Id(), map[string]string{
+		"team":               "dev",
+		"juju-resource-tags": "team",
+		"juju-machine-id":    "1",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(t.instanceTags(c, env, inst.Id()), jc.DeepEquals, map[string]string{
+		"team":               "dev",
+		"juju-resource-tags": "team",
+		"juju-machine-id":    "1",
+	})
+}
+
 func (t *localServerSuite) TestStartInstanceAvailZone(c *gc.C) {
 	inst, err := t.testStartInstanceAvailZone(c, "test-available")
 	c.Assert(err, gc.IsNil)
@@ -0,0 +1,360 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/utils"
+	"github.com/juju/utils/set"
+	"launchpad.net/goamz/aws"
+	"launchpad.net/goamz/ec2"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+// The version of goamz in use has no support for spot instances, so
+// the spot instance API calls are made directly, signed in the same
+// way as goamz signs its own requests.
+
+// spotAPIVersion is the EC2 API version used for spot instance calls.
+const spotAPIVersion = "2014-06-15"
+
+// spotPollDelay is the time to wait between checks of an unfulfilled
+// spot instance request.
+var spotPollDelay = 5 * time.Second
+
+// spotInterruptionCodes holds the spot request status codes that
+// indicate that the request's instance has been, or is about to be,
+// terminated by EC2.
+var spotInterruptionCodes = set.NewStrings(
+	"marked-for-termination",
+	"instance-terminated-by-price",
+	"instance-terminated-no-capacity",
+	"instance-terminated-capacity-oversubscribed",
+	"instance-terminated-launch-group-constraint",
+)
+
+var _ environs.InterruptionReporter = (*environ)(nil)
+
+// spotRequest describes an EC2 spot instance request.
+type spotRequest struct {
+	Id            string `xml:"spotInstanceRequestId"`
+	SpotPrice     string `xml:"spotPrice"`
+	State         string `xml:"state"`
+	StatusCode    string `xml:"status>code"`
+	StatusMessage string `xml:"status>message"`
+	InstanceId    string `xml:"instanceId"`
+}
+
+// spotRequestsResp holds the response to the RequestSpotInstances and
+// DescribeSpotInstanceRequests calls.
+type spotRequestsResp struct {
+	RequestId string        `xml:"requestId"`
+	Requests  []spotRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+// spotErrorsResp holds an error response to a spot instance call.
+type spotErrorsResp struct {
+	RequestId string `xml:"RequestID"`
+	Errors    []struct {
+		Code    string
+		Message string
+	} `xml:"Errors>Error"`
+}
+
+// runSpotInstance requests a spot instance with the given maximum hourly
+// price and launch specification, and waits for the request to be
+// fulfilled. If it is not fulfilled in time the request is cancelled and,
+// if the environment is so configured, an on-demand instance is started
+// instead.
+func (e *environ) runSpotInstance(price string, ri *ec2.RunInstances) (*ec2.RunInstancesResp, error) {
+	req, err := e.requestSpotInstance(price, ri)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("requested spot instance %s at up to $%s an hour", req.Id, price)
+	instId, err := e.waitSpotRequest(req.Id)
+	if err != nil {
+		// The request may be fulfilled while it is being cancelled,
+		// in which case its instance is used anyway.
+		req, cancelErr := e.cancelSpotRequest(req.Id)
+		if cancelErr != nil {
+			return nil, fmt.Errorf("%v (cannot cancel request: %v)", err, cancelErr)
+		}
+		if req.InstanceId != "" {
+			return e.describeSpotInstance(req.InstanceId)
+		}
+		if !e.ecfg().spotFallback() {
+			return nil, err
+		}
+		logger.Warningf("%v; starting on-demand instance instead", err)
+		return runInstances(e.ec2(), ri)
+	}
+	return e.describeSpotInstance(instId)
+}
+
+// describeSpotInstance returns the details of the instance started for
+// a spot request, in the form returned when starting an instance.
+func (e *environ) describeSpotInstance(instId string) (*ec2.RunInstancesResp, error) {
+	resp := &ec2.RunInstancesResp{}
+	for a := shortAttempt.Start(); a.Next(); {
+		instsResp, err := e.ec2().Instances([]string{instId}, nil)
+		if err != nil && ec2ErrCode(err) != "InvalidInstanceID.NotFound" {
+			return nil, err
+		}
+		if err == nil && len(instsResp.Reservations) > 0 {
+			resp.Instances = instsResp.Reservations[0].Instances
+			return resp, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find spot instance %q", instId)
+}
+
+// waitSpotRequest waits for the spot request with the given id to be
+// fulfilled, and returns the id of its instance.
+func (e *environ) waitSpotRequest(id string) (string, error) {
+	timeout := e.ecfg().spotTimeout()
+	attempt := utils.AttemptStrategy{
+		Total: timeout,
+		Delay: spotPollDelay,
+	}
+	var req spotRequest
+	for a := attempt.Start(); a.Next(); {
+		reqs, err := e.describeSpotRequests([]string{id}, nil)
+		if ec2ErrCode(err) == "InvalidSpotInstanceRequestID.NotFound" {
+			// The request is not yet visible.
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		if len(reqs) != 1 {
+			return "", fmt.Errorf("expected 1 spot request, got %d", len(reqs))
+		}
+		req = reqs[0]
+		switch req.State {
+		case "active":
+			if req.InstanceId != "" {
+				return req.InstanceId, nil
+			}
+		case "cancelled", "closed", "failed":
+			return "", fmt.Errorf("spot request %s %s: %s", id, req.State, req.StatusMessage)
+		}
+	}
+	return "", fmt.Errorf("spot request %s not fulfilled after %v (status %q)", id, timeout, req.StatusCode)
+}
+
+// InterruptedInstances implements environs.InterruptionReporter.InterruptedInstances.
+func (e *environ) InterruptedInstances(ids []instance.Id) (map[instance.Id]string, error) {
+	result := make(map[instance.Id]string)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	instIds := make([]string, len(ids))
+	for i, id := range ids {
+		instIds[i] = string(id)
+	}
+	// The instances are described by id, without the filter on
+	// instance state used by AllInstances, so that spot instances
+	// EC2 has already terminated are found too.
+	resp, err := e.ec2().Instances(instIds, nil)
+	switch {
+	case ec2ErrCode(err) == "InvalidInstanceID.NotFound":
+		// EC2 forgets terminated instances after a while, but not
+		// their spot requests, so look for requests for all of them.
+	case err != nil:
+		return nil, err
+	default:
+		instIds = instIds[:0]
+		for _, r := range resp.Reservations {
+			for _, inst := range r.Instances {
+				instIds = append(instIds, inst.InstanceId)
+			}
+		}
+		if len(instIds) == 0 {
+			return result, nil
+		}
+	}
+	reqs, err := e.describeSpotRequests(nil, instIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range reqs {
+		if req.InstanceId == "" || !spotInterruptionCodes.Contains(req.StatusCode) {
+			continue
+		}
+		reason := req.StatusMessage
+		if reason == "" {
+			reason = req.StatusCode
+		}
+		result[instance.Id(req.InstanceId)] = reason
+	}
+	return result, nil
+}
+
+// requestSpotInstance requests a single one-time spot instance with the
+// given maximum hourly price and launch specification.
+func (e *environ) requestSpotInstance(price string, ri *ec2.RunInstances) (*spotRequest, error) {
+	params := map[string]string{
+		"Action":                           "RequestSpotInstances",
+		"SpotPrice":                        price,
+		"InstanceCount":                    "1",
+		"Type":                             "one-time",
+		"LaunchSpecification.ImageId":      ri.ImageId,
+		"LaunchSpecification.InstanceType": ri.InstanceType,
+		"LaunchSpecification.UserData":     base64.StdEncoding.EncodeToString(ri.UserData),
+		"LaunchSpecification.Placement.AvailabilityZone": ri.AvailZone,
+	}
+	if ri.AvailZone == "" {
+		delete(params, "LaunchSpecification.Placement.AvailabilityZone")
+	}
+	for i, g := range ri.SecurityGroups {
+		n := strconv.Itoa(i + 1)
+		if g.Id != "" {
+			params["LaunchSpecification.SecurityGroupId."+n] = g.Id
+		} else {
+			params["LaunchSpecification.SecurityGroup."+n] = g.Name
+		}
+	}
+	for i, d := range ri.BlockDeviceMappings {
+		prefix := "LaunchSpecification.BlockDeviceMapping." + strconv.Itoa(i+1) + "."
+		params[prefix+"DeviceName"] = d.DeviceName
+		if d.VolumeSize != 0 {
+			params[prefix+"Ebs.VolumeSize"] = strconv.FormatInt(d.VolumeSize, 10)
+		}
+	}
+	var resp spotRequestsResp
+	if err := e.spotQuery(params, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Requests) != 1 {
+		return nil, fmt.Errorf("expected 1 spot request, got %d", len(resp.Requests))
+	}
+	return &resp.Requests[0], nil
+}
+
+// describeSpotRequests returns the spot requests with the given ids,
+// or for the given instances.
+func (e *environ) describeSpotRequests(ids, instIds []string) ([]spotRequest, error) {
+	params := map[string]string{
+		"Action": "DescribeSpotInstanceRequests",
+	}
+	for i, id := range ids {
+		params["SpotInstanceRequestId."+strconv.Itoa(i+1)] = id
+	}
+	if len(instIds) > 0 {
+		params["Filter.1.Name"] = "instance-id"
+		for i, id := range instIds {
+			params["Filter.1.Value."+strconv.Itoa(i+1)] = id
+		}
+	}
+	var resp spotRequestsResp
+	if err := e.spotQuery(params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Requests, nil
+}
+
+// cancelSpotRequest cancels the spot request with the given id, and
+// returns its final state. Cancelling a request does not terminate any
+// instance already started for it.
+func (e *environ) cancelSpotRequest(id string) (spotRequest, error) {
+	params := map[string]string{
+		"Action":                  "CancelSpotInstanceRequests",
+		"SpotInstanceRequestId.1": id,
+	}
+	if err := e.spotQuery(params, nil); err != nil {
+		return spotRequest{}, err
+	}
+	reqs, err := e.describeSpotRequests([]string{id}, nil)
+	if err != nil {
+		return spotRequest{}, err
+	}
+	if len(reqs) != 1 {
+		return spotRequest{}, fmt.Errorf("expected 1 spot request, got %d", len(reqs))
+	}
+	return reqs[0], nil
+}
+
+// spotQuery makes a signed EC2 API call with the given parameters, and
+// decodes the XML response into resp, if it is not nil. Error responses
+// are returned as *ec2.Error.
+func (e *environ) spotQuery(params map[string]string, resp interface{}) error {
+	ec2inst := e.ec2()
+	params["Version"] = spotAPIVersion
+	params["Timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	endpoint, err := url.Parse(ec2inst.Region.EC2Endpoint)
+	if err != nil {
+		return err
+	}
+	if endpoint.Path == "" {
+		endpoint.Path = "/"
+	}
+	endpoint.RawQuery = signV2(ec2inst.Auth, "GET", endpoint, params)
+	r, err := http.Get(endpoint.String())
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		var errResp spotErrorsResp
+		xml.NewDecoder(r.Body).Decode(&errResp)
+		ec2err := &ec2.Error{
+			StatusCode: r.StatusCode,
+			RequestId:  errResp.RequestId,
+		}
+		if len(errResp.Errors) > 0 {
+			ec2err.Code = errResp.Errors[0].Code
+			ec2err.Message = errResp.Errors[0].Message
+		} else {
+			ec2err.Message = r.Status
+		}
+		return ec2err
+	}
+	if resp == nil {
+		return nil
+	}
+	return xml.NewDecoder(r.Body).Decode(resp)
+}
+
+// signV2 adds the authentication parameters to params, and returns
+// them as a query string signed with AWS signature version 2.
+func signV2(auth aws.Auth, method string, endpoint *url.URL, params map[string]string) string {
+	params["AWSAccessKeyId"] = auth.AccessKey
+	params["SignatureVersion"] = "2"
+	params["SignatureMethod"] = "HmacSHA256"
+	var keys []string
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = awsEscape(k) + "=" + awsEscape(params[k])
+	}
+	query := strings.Join(pairs, "&")
+	payload := method + "\n" + endpoint.Host + "\n" + endpoint.Path + "\n" + query
+	hash := hmac.New(sha256.New, []byte(auth.SecretKey))
+	hash.Write([]byte(payload))
+	signature := base64.StdEncoding.EncodeToString(hash.Sum(nil))
+	return query + "&Signature=" + awsEscape(signature)
+}
+
+// awsEscape escapes s as required by AWS request signing (RFC 3986).
+func awsEscape(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.Replace(s, "+", "%20", -1)
+	return strings.Replace(s, "%7E", "~", -1)
+}
@@ -0,0 +1,285 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"launchpad.net/goamz/aws"
+	"launchpad.net/goamz/ec2/ec2test"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/bootstrap"
+	envtesting "github.com/juju/juju/environs/testing"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/provider/ec2"
+	coretesting "github.com/juju/juju/testing"
+)
+
+// spotServer serves the EC2 spot instance calls, which the ec2test
+// server does not implement, and passes all other calls on to the
+// ec2test server.
+type spotServer struct {
+	*httptest.Server
+	ec2srv *ec2test.Server
+	proxy  *httputil.ReverseProxy
+
+	mu       sync.Mutex
+	fulfil   bool
+	requests []*spotItem
+}
+
+type spotItem struct {
+	Id            string `xml:"spotInstanceRequestId"`
+	SpotPrice     string `xml:"spotPrice"`
+	State         string `xml:"state"`
+	StatusCode    string `xml:"status>code"`
+	StatusMessage string `xml:"status>message"`
+	InstanceId    string `xml:"instanceId,omitempty"`
+	InstanceType  string `xml:"launchSpecification>instanceType"`
+}
+
+type spotResp struct {
+	XMLName   xml.Name
+	RequestId string      `xml:"requestId"`
+	Items     []*spotItem `xml:"spotInstanceRequestSet>item"`
+}
+
+func newSpotServer(c *gc.C, ec2srv *ec2test.Server) *spotServer {
+	target, err := url.Parse(ec2srv.URL())
+	c.Assert(err, gc.IsNil)
+	srv := &spotServer{
+		ec2srv: ec2srv,
+		proxy:  httputil.NewSingleHostReverseProxy(target),
+		fulfil: true,
+	}
+	srv.Server = httptest.NewServer(srv)
+	return srv
+}
+
+func (srv *spotServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	form := req.URL.Query()
+	action := form.Get("Action")
+	if !strings.Contains(action, "Spot") {
+		srv.proxy.ServeHTTP(w, req)
+		return
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var items []*spotItem
+	switch action {
+	case "RequestSpotInstances":
+		item := &spotItem{
+			Id:            fmt.Sprintf("sir-%d", len(srv.requests)),
+			SpotPrice:     form.Get("SpotPrice"),
+			State:         "open",
+			StatusCode:    "pending-evaluation",
+			StatusMessage: "Your Spot request has been submitted for review.",
+			InstanceType:  form.Get("LaunchSpecification.InstanceType"),
+		}
+		if srv.fulfil {
+			ids := srv.ec2srv.NewInstances(
+				1,
+				item.InstanceType,
+				form.Get("LaunchSpecification.ImageId"),
+				ec2test.Running,
+				nil,
+			)
+			item.InstanceId = ids[0]
+			item.State = "active"
+			item.StatusCode = "fulfilled"
+			item.StatusMessage = "Your Spot request is fulfilled."
+		}
+		srv.requests = append(srv.requests, item)
+		items = []*spotItem{item}
+	case "DescribeSpotInstanceRequests":
+		for _, item := range srv.requests {
+			if srv.matches(form, item) {
+				items = append(items, item)
+			}
+		}
+	case "CancelSpotInstanceRequests":
+		for _, item := range srv.requests {
+			if item.Id == form.Get("SpotInstanceRequestId.1") {
+				item.State = "cancelled"
+				item.StatusCode = "canceled-before-fulfillment"
+			}
+		}
+	}
+	xml.NewEncoder(w).Encode(spotResp{
+		XMLName:   xml.Name{Local: action + "Response"},
+		RequestId: "req-0",
+		Items:     items,
+	})
+}
+
+// matches reports whether the given spot request matches the
+// identifiers and instance id filter in the given request parameters.
+func (srv *spotServer) matches(form url.Values, item *spotItem) bool {
+	var ids, instIds []string
+	for key, values := range form {
+		switch {
+		case strings.HasPrefix(key, "SpotInstanceRequestId."):
+			ids = append(ids, values...)
+		case strings.HasPrefix(key, "Filter.1.Value."):
+			instIds = append(instIds, values...)
+		}
+	}
+	if len(ids) > 0 && !contains(ids, item.Id) {
+		return false
+	}
+	if len(instIds) > 0 && !contains(instIds, item.InstanceId) {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (srv *spotServer) setFulfil(fulfil bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.fulfil = fulfil
+}
+
+func (srv *spotServer) setStatus(instId instance.Id, code, message string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	for _, item := range srv.requests {
+		if item.InstanceId == string(instId) {
+			item.StatusCode = code
+			item.StatusMessage = message
+		}
+	}
+}
+
+func (srv *spotServer) spotRequests() []spotItem {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var items []spotItem
+	for _, item := range srv.requests {
+		items = append(items, *item)
+	}
+	return items
+}
+
+// prepareSpot starts a spot server in front of the local EC2 server,
+// and bootstraps an environment that uses it.
+func (t *localServerSuite) prepareSpot(c *gc.C, attrs coretesting.Attrs) (environs.Environ, *spotServer) {
+	spotsrv := newSpotServer(c, t.srv.ec2srv)
+	t.AddCleanup(func(*gc.C) { spotsrv.Close() })
+	region := aws.Regions["test"]
+	region.EC2Endpoint = spotsrv.URL
+	aws.Regions["test"] = region
+	t.PatchValue(ec2.SpotPollDelay, time.Millisecond)
+
+	t.PatchValue(&t.TestConfig, t.TestConfig.Merge(attrs))
+	env := t.Prepare(c)
+	envtesting.UploadFakeTools(c, env.Storage())
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+	return env, spotsrv
+}
+
+func (t *localServerSuite) TestStartInstanceSpot(c *gc.C) {
+	env, spotsrv := t.prepareSpot(c, nil)
+	cons := constraints.MustParse("spot-price=0.05")
+	inst, _ := testing.AssertStartInstanceWithConstraints(c, env, "1", cons)
+
+	reqs := spotsrv.spotRequests()
+	c.Assert(reqs, gc.HasLen, 1)
+	c.Check(reqs[0].SpotPrice, gc.Equals, "0.05")
+	c.Check(reqs[0].InstanceId, gc.Equals, string(inst.Id()))
+	c.Check(t.srv.ec2srv.Instance(string(inst.Id())), gc.NotNil)
+}
+
+func (t *localServerSuite) TestStartInstanceSpotNotFulfilled(c *gc.C) {
+	env, spotsrv := t.prepareSpot(c, coretesting.Attrs{"spot-timeout": 1})
+	spotsrv.setFulfil(false)
+	cons := constraints.MustParse("spot-price=0.05")
+	_, _, _, err := testing.StartInstanceWithConstraints(env, "1", cons)
+	c.Assert(err, gc.ErrorMatches, `cannot run instances: spot request sir-0 not fulfilled after 1s \(status "pending-evaluation"\)`)
+
+	reqs := spotsrv.spotRequests()
+	c.Assert(reqs, gc.HasLen, 1)
+	c.Check(reqs[0].State, gc.Equals, "cancelled")
+}
+
+func (t *localServerSuite) TestStartInstanceSpotFallback(c *gc.C) {
+	env, spotsrv := t.prepareSpot(c, coretesting.Attrs{
+		"spot-timeout":  1,
+		"spot-fallback": true,
+	})
+	spotsrv.setFulfil(false)
+	cons := constraints.MustParse("spot-price=0.05")
+	inst, _ := testing.AssertStartInstanceWithConstraints(c, env, "1", cons)
+	c.Check(t.srv.ec2srv.Instance(string(inst.Id())), gc.NotNil)
+
+	reqs := spotsrv.spotRequests()
+	c.Assert(reqs, gc.HasLen, 1)
+	c.Check(reqs[0].State, gc.Equals, "cancelled")
+	c.Check(reqs[0].InstanceId, gc.Equals, "")
+}
+
+func (t *localServerSuite) TestInterruptedInstances(c *gc.C) {
+	env, spotsrv := t.prepareSpot(c, nil)
+	cons := constraints.MustParse("spot-price=0.05")
+	inst0, _ := testing.AssertStartInstanceWithConstraints(c, env, "1", cons)
+	inst1, _ := testing.AssertStartInstanceWithConstraints(c, env, "2", cons)
+	reporter := env.(environs.InterruptionReporter)
+	ids := []instance.Id{inst0.Id(), inst1.Id()}
+
+	interrupted, err := reporter.InterruptedInstances(ids)
+	c.Assert(err, gc.IsNil)
+	c.Assert(interrupted, gc.HasLen, 0)
+
+	spotsrv.setStatus(inst1.Id(), "marked-for-termination", "Your Spot Instance is marked for termination.")
+	interrupted, err = reporter.InterruptedInstances(ids)
+	c.Assert(err, gc.IsNil)
+	c.Assert(interrupted, gc.DeepEquals, map[instance.Id]string{
+		inst1.Id(): "Your Spot Instance is marked for termination.",
+	})
+	c.Assert(interrupted[inst0.Id()], gc.Equals, "")
+}
+
+func (t *localServerSuite) TestInterruptedInstancesAlreadyTerminated(c *gc.C) {
+	env, spotsrv := t.prepareSpot(c, nil)
+	cons := constraints.MustParse("spot-price=0.05")
+	inst0, _ := testing.AssertStartInstanceWithConstraints(c, env, "1", cons)
+	inst1, _ := testing.AssertStartInstanceWithConstraints(c, env, "2", cons)
+
+	// EC2 has already terminated the instance, so it is no longer
+	// one of the environment's running instances.
+	err := env.StopInstances(inst1.Id())
+	c.Assert(err, gc.IsNil)
+	insts, err := env.AllInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(insts, gc.HasLen, 1)
+	spotsrv.setStatus(inst1.Id(), "instance-terminated-by-price", "Your Spot Instance was terminated because the Spot price rose.")
+
+	reporter := env.(environs.InterruptionReporter)
+	interrupted, err := reporter.InterruptedInstances([]instance.Id{inst0.Id(), inst1.Id()})
+	c.Assert(err, gc.IsNil)
+	c.Assert(interrupted, gc.DeepEquals, map[instance.Id]string{
+		inst1.Id(): "Your Spot Instance was terminated because the Spot price rose.",
+	})
+}
@@ -43,6 +43,7 @@ type joyentEnviron struct {
 
 var _ environs.Environ = (*joyentEnviron)(nil)
 var _ state.Prechecker = (*joyentEnviron)(nil)
+var _ environs.InstanceCoster = (*joyentEnviron)(nil)
 
 // newEnviron create a new Joyent environ instance from config.
 func newEnviron(cfg *config.Config) (*joyentEnviron, error) {
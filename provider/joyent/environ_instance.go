@@ -57,6 +57,7 @@ func (env *joyentEnviron) machineFullName(machineId string) string {
 var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.Tags,
+	constraints.SpotPrice,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -225,6 +226,27 @@ func (env *joyentEnviron) Instances(ids []instance.Id) ([]instance.Instance, err
 	return instances, nil
 }
 
+// InstanceCosts implements environs.InstanceCoster.InstanceCosts.
+// The instance type of a machine is the name of its package. Joyent
+// does not publish the prices of packages through its API, so their
+// costs are reported as unknown.
+func (env *joyentEnviron) InstanceCosts(ids []instance.Id) ([]environs.InstanceCost, error) {
+	insts, err := env.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	costs := make([]environs.InstanceCost, len(insts))
+	for i, inst := range insts {
+		if inst == nil {
+			continue
+		}
+		costs[i] = environs.InstanceCost{
+			InstanceType: inst.(*joyentInstance).machine.Package,
+		}
+	}
+	return costs, err
+}
+
 // AllocateAddress requests a new address to be allocated for the
 // given instance on the given network. This is not implemented on the
 // Joyent provider yet.
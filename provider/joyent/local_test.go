@@ -301,6 +301,24 @@ func (s *localServerSuite) TestInstancesGathering(c *gc.C) {
 }
 
 // It should be moved to environs.jujutests.Tests.
+func (s *localServerSuite) TestInstanceCosts(c *gc.C) {
+	env := s.Prepare(c)
+	s.Tests.UploadFakeTools(c, env.Storage())
+	inst, _ := testing.AssertStartInstance(c, env, "100")
+	defer func() {
+		err := env.StopInstances(inst.Id())
+		c.Assert(err, gc.IsNil)
+	}()
+
+	costs, err := env.(environs.InstanceCoster).InstanceCosts([]instance.Id{inst.Id(), "unknown"})
+	c.Assert(err, gc.Equals, environs.ErrPartialInstances)
+	c.Assert(costs, gc.HasLen, 2)
+	// The package is reported, but Joyent publishes no prices.
+	c.Assert(costs[0].InstanceType, gc.Not(gc.Equals), "")
+	c.Assert(costs[0].Cost, gc.Equals, uint64(0))
+	c.Assert(costs[1], gc.Equals, environs.InstanceCost{})
+}
+
 func (s *localServerSuite) TestBootstrapInstanceUserDataAndState(c *gc.C) {
 	env := s.Prepare(c)
 	s.Tests.UploadFakeTools(c, env.Storage())
@@ -77,6 +77,10 @@ func (c *environConfig) storageDir() string {
 	return filepath.Join(c.rootDir(), "storage")
 }
 
+func (c *environConfig) volumesDir() string {
+	return filepath.Join(c.rootDir(), "volumes")
+}
+
 func (c *environConfig) mongoDir() string {
 	return filepath.Join(c.rootDir(), "db")
 }
@@ -13,8 +13,10 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/utils"
 	"github.com/juju/utils/proxy"
 	"github.com/juju/utils/shell"
 
@@ -24,6 +26,9 @@ import (
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/factory"
+	"github.com/juju/juju/container/firewall"
+	"github.com/juju/juju/container/kvm"
+	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/bootstrap"
 	"github.com/juju/juju/environs/cloudinit"
@@ -256,6 +261,9 @@ func (env *localEnviron) SetConfig(cfg *config.Config) error {
 			managerConfig["use-aufs"] = fmt.Sprint(useLxcCloneAufs)
 		}
 	}
+	if ratio, ok := cfg.ContainerOvercommitRatio(); ok {
+		managerConfig[container.ConfigOvercommitRatio] = fmt.Sprint(ratio)
+	}
 	env.containerManager, err = factory.NewContainerManager(
 		containerType, managerConfig)
 	if err != nil {
@@ -326,6 +334,7 @@ var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.InstanceType,
 	constraints.Tags,
+	constraints.SpotPrice,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -375,9 +384,75 @@ func (env *localEnviron) StartInstance(args environs.StartInstanceParams) (insta
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	// Inbound traffic to the container is dropped until ports are
+	// opened on it. A container that cannot be firewalled is not left
+	// running.
+	if err := env.initFirewall(inst.Id()); err != nil {
+		if err := env.containerManager.DestroyContainer(inst.Id()); err != nil {
+			logger.Errorf("cannot destroy container %q: %v", inst.Id(), err)
+		}
+		return nil, nil, nil, err
+	}
 	return inst, hardware, nil, nil
 }
 
+// containerAddressAttempt holds how long initFirewall waits for a new
+// container to be given an address.
+var containerAddressAttempt = utils.AttemptStrategy{
+	Total: 5 * time.Minute,
+	Delay: 2 * time.Second,
+}
+
+// initFirewall sets up the firewall for the newly started container with
+// the given id, once it has been given an address.
+func (env *localEnviron) initFirewall(id instance.Id) error {
+	var address string
+	var err error
+	for a := containerAddressAttempt.Start(); a.Next(); {
+		if address, err = env.containerAddress(id); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot set up firewall for %q: %v", id, err)
+	}
+	return firewall.Init(string(id), env.config.networkBridge(), address)
+}
+
+// restoreFirewalls sets up the firewall again for every existing
+// container. The rules do not survive a reboot of the host, and the
+// firewaller only revisits containers that have open ports, so
+// unexposed containers would otherwise be left unfiltered. Each
+// container is handled in the background, because containers that
+// are started with the host may not yet have an address.
+func (env *localEnviron) restoreFirewalls() error {
+	containers, err := env.containerManager.ListContainers()
+	if err != nil {
+		return fmt.Errorf("cannot restore container firewalls: %v", err)
+	}
+	var wg sync.WaitGroup
+	for _, inst := range containers {
+		wg.Add(1)
+		go func(id instance.Id) {
+			defer wg.Done()
+			if err := env.initFirewall(id); err != nil {
+				logger.Errorf("%v", err)
+			}
+		}(inst.Id())
+	}
+	wg.Wait()
+	return nil
+}
+
+// containerAddress returns the address of the container with the
+// given id.
+func (env *localEnviron) containerAddress(id instance.Id) (string, error) {
+	if env.config.container() == instance.KVM {
+		return kvm.MachineAddress(string(id))
+	}
+	return lxc.ContainerAddress(string(id))
+}
+
 // Override for testing.
 var createContainer = func(env *localEnviron, args environs.StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, error) {
 	series := args.Tools.OneSeries()
@@ -398,10 +473,20 @@ func (env *localEnviron) StopInstances(ids ...instance.Id) error {
 		if err := env.containerManager.DestroyContainer(id); err != nil {
 			return err
 		}
+		removeFirewall(id)
 	}
 	return nil
 }
 
+// removeFirewall removes the firewall rules of the container with the
+// given id. Failure is logged rather than returned, because the
+// container itself has already gone.
+func removeFirewall(id instance.Id) {
+	if err := firewall.Remove(string(id)); err != nil {
+		logger.Warningf("cannot remove firewall rules for %q: %v", id, err)
+	}
+}
+
 // Instances is specified in the Environ interface.
 func (env *localEnviron) Instances(ids []instance.Id) ([]instance.Instance, error) {
 	if len(ids) == 0 {
@@ -509,6 +594,7 @@ func (env *localEnviron) Destroy() error {
 		if err := env.containerManager.DestroyContainer(inst.Id()); err != nil {
 			return err
 		}
+		removeFirewall(inst.Id())
 	}
 	cmd := exec.Command(
 		"pkill",
@@ -12,11 +12,13 @@ import (
 	"time"
 
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
 	coreCloudinit "github.com/juju/juju/cloudinit"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/firewall"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/container/lxc"
 	containertesting "github.com/juju/juju/container/testing"
@@ -31,6 +33,7 @@ import (
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/mongo"
+	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/local"
 	"github.com/juju/juju/state/api/params"
 	coretesting "github.com/juju/juju/testing"
@@ -130,6 +133,14 @@ func (s *localJujuTestSuite) SetUpTest(c *gc.C) {
 	err = ioutil.WriteFile(s.fakesudo, []byte(echoCommandScript), 0755)
 	c.Assert(err, gc.IsNil)
 
+	// Write a fake "iptables" which records its args, and which has
+	// no chains or rules.
+	s.MakeTool(c, "iptables", `
+echo "$@" >> $0.args
+case "$1" in
+-S|-C) echo "iptables: No chain/target/match by that name."; exit 1;;
+esac`)
+
 	// Add in an admin secret
 	s.Tests.TestConfig["admin-secret"] = "sekrit"
 	s.PatchValue(local.CheckIfRoot, func() bool { return false })
@@ -296,6 +307,91 @@ func (s *localJujuTestSuite) TestDestroyRemovesContainers(c *gc.C) {
 	c.Assert(container.IsConstructed(), jc.IsFalse)
 }
 
+func (s *localJujuTestSuite) TestOpenPortsFirewallsContainer(c *gc.C) {
+	env := s.testBootstrap(c, minimalConfig(c))
+	namespace := env.Config().AllAttrs()["namespace"].(string)
+	manager, err := lxc.NewContainerManager(container.ManagerConfig{
+		container.ConfigName:   namespace,
+		container.ConfigLogDir: "logdir",
+		"use-clone":            "false",
+	})
+	c.Assert(err, gc.IsNil)
+	machine1 := containertesting.CreateContainer(c, manager, "1")
+	s.MakeTool(c, "lxc-info", `echo "IP:             10.0.3.140"`)
+
+	insts, err := env.Instances([]instance.Id{machine1.Id()})
+	c.Assert(err, gc.IsNil)
+	err = insts[0].OpenPorts("1", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+	addrs, err := insts[0].Addresses()
+	c.Assert(err, gc.IsNil)
+	c.Assert(addrs, jc.DeepEquals, []network.Address{network.NewAddress("10.0.3.140", network.ScopeCloudLocal)})
+
+	args, err := ioutil.ReadFile(filepath.Join(s.testPath, "iptables.args"))
+	c.Assert(err, gc.IsNil)
+	chain := firewall.ChainName(string(machine1.Id()))
+	c.Assert(string(args), jc.Contains, "-I FORWARD -o lxcbr0 -d 10.0.3.140 -j "+chain+"\n")
+	c.Assert(string(args), jc.Contains, "-I "+chain+" 1 -p tcp -m tcp --dport 80 -j ACCEPT\n")
+
+	// The bootstrap instance is the host, which is not firewalled.
+	insts, err = env.Instances([]instance.Id{"localhost"})
+	c.Assert(err, gc.IsNil)
+	err = insts[0].OpenPorts("0", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+	ports, err := insts[0].Ports("0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+}
+
+func (s *localJujuTestSuite) TestStopInstancesRemovesFirewall(c *gc.C) {
+	env := s.testBootstrap(c, minimalConfig(c))
+	namespace := env.Config().AllAttrs()["namespace"].(string)
+	manager, err := lxc.NewContainerManager(container.ManagerConfig{
+		container.ConfigName:   namespace,
+		container.ConfigLogDir: "logdir",
+		"use-clone":            "false",
+	})
+	c.Assert(err, gc.IsNil)
+	machine1 := containertesting.CreateContainer(c, manager, "1")
+
+	err = env.StopInstances(machine1.Id())
+	c.Assert(err, gc.IsNil)
+	args, err := ioutil.ReadFile(filepath.Join(s.testPath, "iptables.args"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(args), jc.Contains, "-S "+firewall.ChainName(string(machine1.Id()))+"\n")
+}
+
+func (s *localJujuTestSuite) TestOpenRestoresFirewalls(c *gc.C) {
+	env := s.testBootstrap(c, minimalConfig(c))
+	namespace := env.Config().AllAttrs()["namespace"].(string)
+	manager, err := lxc.NewContainerManager(container.ManagerConfig{
+		container.ConfigName:   namespace,
+		container.ConfigLogDir: "logdir",
+		"use-clone":            "false",
+	})
+	c.Assert(err, gc.IsNil)
+	machine1 := containertesting.CreateContainer(c, manager, "1")
+	s.MakeTool(c, "lxc-info", `echo "IP:             10.0.3.140"`)
+
+	// Opening the bootstrapped environment, as the agents do, puts
+	// back the rules that a reboot of the host would have removed.
+	local.ResetRestoreFirewalls()
+	s.AddCleanup(func(*gc.C) { local.ResetRestoreFirewalls() })
+	_, err = local.Provider.Open(env.Config())
+	c.Assert(err, gc.IsNil)
+
+	chain := firewall.ChainName(string(machine1.Id()))
+	jump := "-I FORWARD -o lxcbr0 -d 10.0.3.140 -j " + chain + "\n"
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		args, err := ioutil.ReadFile(filepath.Join(s.testPath, "iptables.args"))
+		if err == nil && strings.Contains(string(args), jump) {
+			c.Assert(string(args), jc.Contains, "-A "+chain+" -j DROP\n")
+			return
+		}
+	}
+	c.Fatalf("firewall not restored for %q", machine1.Id())
+}
+
 func (s *localJujuTestSuite) TestBootstrapRemoveLeftovers(c *gc.C) {
 	cfg := minimalConfig(c)
 	rootDir := cfg.AllAttrs()["root-dir"].(string)
@@ -342,11 +438,62 @@ func (s *localJujuTestSuite) TestToolsURLPatchedForLxc(c *gc.C) {
 	}
 
 	local.PatchCreateContainer(&s.CleanupSuite, c, "file:///tmp/juju/tools/juju-5.4.5-precise-amd64.tgz")
+	s.MakeTool(c, "lxc-info", `echo "IP:             10.0.3.140"`)
 	inst, _, _, err := env.StartInstance(params)
 	c.Assert(err, gc.IsNil)
 	c.Assert(inst.Id(), gc.Equals, instance.Id("mock"))
 }
 
+// startMockInstance starts an instance in a new lxc environment, with
+// the container creation mocked out.
+func (s *localJujuTestSuite) startMockInstance(c *gc.C) (instance.Instance, error) {
+	dir := c.MkDir()
+	err := os.MkdirAll(filepath.Join(dir, "storage", "tools", "releases"), 0755)
+	c.Assert(err, gc.IsNil)
+	config := localConfig(c, map[string]interface{}{
+		"root-dir":  dir,
+		"container": "lxc",
+	})
+	env, err := local.Provider.Prepare(coretesting.Context(c), config)
+	c.Assert(err, gc.IsNil)
+
+	machineId := "1"
+	stateInfo := testing.FakeStateInfo(machineId)
+	apiInfo := testing.FakeAPIInfo(machineId)
+	machineConfig := environs.NewMachineConfig(machineId, "", nil, stateInfo, apiInfo)
+	possibleTools := envtesting.AssertUploadFakeToolsVersions(
+		c, env.Storage(), version.MustParseBinary("5.4.5-precise-amd64"))
+	local.PatchCreateContainer(&s.CleanupSuite, c, "file:///tmp/juju/tools/juju-5.4.5-precise-amd64.tgz")
+	inst, _, _, err := env.StartInstance(environs.StartInstanceParams{
+		Tools:         possibleTools,
+		MachineConfig: machineConfig,
+	})
+	return inst, err
+}
+
+func (s *localJujuTestSuite) TestStartInstanceFirewallsContainer(c *gc.C) {
+	s.MakeTool(c, "lxc-info", `echo "IP:             10.0.3.140"`)
+	inst, err := s.startMockInstance(c)
+	c.Assert(err, gc.IsNil)
+
+	// No ports have been opened, so everything from outside the
+	// bridge is dropped.
+	args, err := ioutil.ReadFile(filepath.Join(s.testPath, "iptables.args"))
+	c.Assert(err, gc.IsNil)
+	chain := firewall.ChainName(string(inst.Id()))
+	c.Assert(string(args), jc.Contains, "-N "+chain+"\n")
+	c.Assert(string(args), jc.Contains, "-A "+chain+" -j DROP\n")
+	c.Assert(string(args), jc.Contains, "-I FORWARD -o lxcbr0 -d 10.0.3.140 -j "+chain+"\n")
+	c.Assert(string(args), gc.Not(jc.Contains), "--dport")
+}
+
+func (s *localJujuTestSuite) TestStartInstanceFailsWithoutFirewall(c *gc.C) {
+	s.PatchValue(local.ContainerAddressAttempt, utils.AttemptStrategy{})
+	s.MakeTool(c, "lxc-info", `exit 1`)
+	_, err := s.startMockInstance(c)
+	c.Assert(err, gc.ErrorMatches, `cannot set up firewall for "mock": cannot get address of "mock": .*`)
+}
+
 func (s *localJujuTestSuite) TestToolsURLNotPatchedForKvm(c *gc.C) {
 	s.PatchValue(&kvm.IsKVMSupported, func() (bool, error) {
 		return true, nil
@@ -380,6 +527,7 @@ func (s *localJujuTestSuite) TestToolsURLNotPatchedForKvm(c *gc.C) {
 	url, err := env.Storage().URL("tools/releases/juju-5.4.5-precise-amd64.tgz")
 	c.Assert(err, gc.IsNil)
 	local.PatchCreateContainer(&s.CleanupSuite, c, url)
+	s.MakeTool(c, "uvt-kvm", `echo "10.0.3.140"`)
 	inst, _, _, err := env.StartInstance(params)
 	c.Assert(err, gc.IsNil)
 	c.Assert(inst.Id(), gc.Equals, instance.Id("mock"))
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"os/user"
+	"sync"
 	"syscall"
 
 	"github.com/juju/loggo"
@@ -80,9 +81,24 @@ func (environProvider) Open(cfg *config.Config) (environs.Environ, error) {
 	if err := environ.SetConfig(cfg); err != nil {
 		return nil, fmt.Errorf("failure setting config: %v", err)
 	}
+	// Within the running environment, the container firewalls are
+	// restored once, since they are lost when the host reboots.
+	if localConfig.bootstrapIPAddress() != "" {
+		restoreFirewallsOnce.Do(func() {
+			go func() {
+				if err := environ.restoreFirewalls(); err != nil {
+					logger.Errorf("%v", err)
+				}
+			}()
+		})
+	}
 	return environ, nil
 }
 
+// restoreFirewallsOnce ensures that the container firewalls are
+// restored only the first time the environment is opened by an agent.
+var restoreFirewallsOnce sync.Once
+
 var detectAptProxies = apt.DetectProxies
 
 // Prepare implements environs.EnvironProvider.Prepare.
@@ -3,6 +3,8 @@
 package local
 
 import (
+	"sync"
+
 	"github.com/juju/testing"
 	gc "launchpad.net/gocheck"
 
@@ -74,3 +76,13 @@ func PatchCreateContainer(s *testing.CleanupSuite, c *gc.C, expectedURL string)
 	s.PatchValue(&createContainer, mockFunc)
 	return mockFunc
 }
+
+var RunVolumeCommand = &runVolumeCommand
+
+var ContainerAddressAttempt = &containerAddressAttempt
+
+// ResetRestoreFirewalls allows the container firewalls to be restored
+// again by the next Open.
+func ResetRestoreFirewalls() {
+	restoreFirewallsOnce = sync.Once{}
+}
@@ -6,8 +6,7 @@ package local
 import (
 	"fmt"
 
-	"github.com/juju/errors"
-
+	"github.com/juju/juju/container/firewall"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 )
@@ -46,24 +45,50 @@ func (inst *localInstance) Addresses() ([]network.Address, error) {
 		}}
 		return addrs, nil
 	}
-	return nil, errors.NotImplementedf("localInstance.Addresses")
+	address, err := inst.address()
+	if err != nil {
+		return nil, err
+	}
+	return []network.Address{network.NewAddress(address, network.ScopeCloudLocal)}, nil
+}
+
+// address returns the address of the container of a machine other
+// than the bootstrap machine.
+func (inst *localInstance) address() (string, error) {
+	return inst.env.containerAddress(inst.id)
 }
 
-// OpenPorts implements instance.Instance.OpenPorts.
+// OpenPorts implements instance.Instance.OpenPorts. Inbound traffic
+// forwarded to a container over the environment's bridge is restricted
+// to its open ports; see StartInstance. The bootstrap machine is the
+// host itself, and is not firewalled.
 func (inst *localInstance) OpenPorts(machineId string, ports []network.Port) error {
 	logger.Infof("OpenPorts called for %s:%v", machineId, ports)
-	return nil
+	if inst.id == bootstrapInstanceId {
+		return nil
+	}
+	address, err := inst.address()
+	if err != nil {
+		return fmt.Errorf("cannot open ports: %v", err)
+	}
+	return firewall.OpenPorts(string(inst.id), inst.env.config.networkBridge(), address, ports)
 }
 
 // ClosePorts implements instance.Instance.ClosePorts.
 func (inst *localInstance) ClosePorts(machineId string, ports []network.Port) error {
 	logger.Infof("ClosePorts called for %s:%v", machineId, ports)
-	return nil
+	if inst.id == bootstrapInstanceId {
+		return nil
+	}
+	return firewall.ClosePorts(string(inst.id), ports)
 }
 
 // Ports implements instance.Instance.Ports.
 func (inst *localInstance) Ports(machineId string) ([]network.Port, error) {
-	return nil, nil
+	if inst.id == bootstrapInstanceId {
+		return nil, nil
+	}
+	return firewall.Ports(string(inst.id))
 }
 
 // Add a string representation of the id.
@@ -0,0 +1,140 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+// localEnviron implements VolumeSource, providing volumes backed by
+// loop devices on the host.
+var _ environs.VolumeSource = (*localEnviron)(nil)
+
+// loopPool is the name of the only storage pool supported by the
+// local provider.
+const loopPool = "loop"
+
+// runVolumeCommand runs the named command, returning its trimmed
+// combined output. It is patched in tests.
+var runVolumeCommand = func(name string, args ...string) (string, error) {
+	logger.Debugf("running %s %s", name, strings.Join(args, " "))
+	out, err := exec.Command(name, args...).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output != "" {
+			err = fmt.Errorf("%v (%s)", err, output)
+		}
+		return "", fmt.Errorf("%s failed: %v", name, err)
+	}
+	return output, nil
+}
+
+// StoragePools implements environs.VolumeSource.StoragePools. Loop
+// volumes can only be attached to lxc containers.
+func (env *localEnviron) StoragePools() []string {
+	env.localMutex.Lock()
+	defer env.localMutex.Unlock()
+	if env.config.container() != instance.LXC {
+		return nil
+	}
+	return []string{loopPool}
+}
+
+// volumeFile returns the path of the file backing the volume with the
+// given id.
+func (env *localEnviron) volumeFile(volumeId string) string {
+	env.localMutex.Lock()
+	defer env.localMutex.Unlock()
+	return filepath.Join(env.config.volumesDir(), volumeId+".img")
+}
+
+// CreateVolume implements environs.VolumeSource.CreateVolume. The
+// volume is a sparse file in the environment's root directory, which
+// is attached to instances through a loop device.
+func (env *localEnviron) CreateVolume(args environs.VolumeParams) (environs.Volume, error) {
+	if args.Pool != loopPool {
+		return environs.Volume{}, environs.UnsupportedPoolError(args.Pool)
+	}
+	env.localMutex.Lock()
+	containerType := env.config.container()
+	env.localMutex.Unlock()
+	if containerType != instance.LXC {
+		return environs.Volume{}, fmt.Errorf("loop volumes are only supported for lxc containers")
+	}
+	path := env.volumeFile(args.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return environs.Volume{}, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return environs.Volume{}, fmt.Errorf("cannot create volume file: %v", err)
+	}
+	defer f.Close()
+	if err := f.Truncate(int64(args.Size) * 1024 * 1024); err != nil {
+		os.Remove(path)
+		return environs.Volume{}, fmt.Errorf("cannot create volume file: %v", err)
+	}
+	return environs.Volume{Id: args.Name, Size: args.Size}, nil
+}
+
+// AttachVolume implements environs.VolumeSource.AttachVolume. The
+// volume's file is associated with a free loop device, which is then
+// made available inside the container.
+func (env *localEnviron) AttachVolume(volumeId string, instId instance.Id) (string, error) {
+	path := env.volumeFile(volumeId)
+	device, err := loopDevice(path)
+	if err != nil {
+		return "", err
+	}
+	if device == "" {
+		device, err = runVolumeCommand("losetup", "--find", "--show", path)
+		if err != nil {
+			return "", err
+		}
+	}
+	if instId != bootstrapInstanceId {
+		if _, err := runVolumeCommand("lxc-device", "-n", string(instId), "add", device); err != nil {
+			return "", err
+		}
+	}
+	return device, nil
+}
+
+// DetachVolume implements environs.VolumeSource.DetachVolume. The
+// loop device is removed from the container before the volume's file
+// is released from it.
+func (env *localEnviron) DetachVolume(volumeId string, instId instance.Id) error {
+	device, err := loopDevice(env.volumeFile(volumeId))
+	if err != nil || device == "" {
+		return err
+	}
+	if instId != bootstrapInstanceId {
+		if _, err := runVolumeCommand("lxc-device", "-n", string(instId), "del", device); err != nil {
+			return err
+		}
+	}
+	_, err = runVolumeCommand("losetup", "--detach", device)
+	return err
+}
+
+// loopDevice returns the loop device associated with the given file,
+// or the empty string if there is none.
+func loopDevice(path string) (string, error) {
+	out, err := runVolumeCommand("losetup", "--associated", path)
+	if err != nil {
+		return "", err
+	}
+	// The output is of the form "/dev/loop0: [0801]:1234 (/path)".
+	if i := strings.Index(out, ":"); i > 0 {
+		return out[:i], nil
+	}
+	return "", nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/provider/local"
+)
+
+type volumesSuite struct {
+	baseProviderSuite
+	rootDir  string
+	commands []string
+	attached string
+}
+
+var _ = gc.Suite(&volumesSuite{})
+
+func (s *volumesSuite) SetUpTest(c *gc.C) {
+	s.baseProviderSuite.SetUpTest(c)
+	s.rootDir = c.MkDir()
+	s.commands = nil
+	s.attached = ""
+	s.PatchValue(local.RunVolumeCommand, func(name string, args ...string) (string, error) {
+		command := strings.Join(append([]string{name}, args...), " ")
+		s.commands = append(s.commands, command)
+		switch {
+		case strings.HasPrefix(command, "losetup --associated"):
+			if s.attached != "" {
+				return s.attached + ": [0801]:1234 (" + args[1] + ")", nil
+			}
+		case strings.HasPrefix(command, "losetup --find"):
+			s.attached = "/dev/loop3"
+			return s.attached, nil
+		case strings.HasPrefix(command, "losetup --detach"):
+			s.attached = ""
+		}
+		return "", nil
+	})
+}
+
+func (s *volumesSuite) volumeSource(c *gc.C) environs.VolumeSource {
+	env, err := local.Provider.Open(localConfig(c, map[string]interface{}{
+		"root-dir": s.rootDir,
+	}))
+	c.Assert(err, gc.IsNil)
+	return env.(environs.VolumeSource)
+}
+
+func (s *volumesSuite) TestCreateVolume(c *gc.C) {
+	source := s.volumeSource(c)
+	volume, err := source.CreateVolume(environs.VolumeParams{
+		Name: "volume-0",
+		Pool: "loop",
+		Size: 64,
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume, gc.Equals, environs.Volume{Id: "volume-0", Size: 64})
+	info, err := os.Stat(filepath.Join(s.rootDir, "volumes", "volume-0.img"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Size(), gc.Equals, int64(64*1024*1024))
+
+	_, err = source.CreateVolume(environs.VolumeParams{Name: "volume-1", Pool: "ebs", Size: 64})
+	c.Assert(err, gc.ErrorMatches, `storage pool "ebs" not supported`)
+}
+
+func (s *volumesSuite) TestStoragePools(c *gc.C) {
+	c.Assert(s.volumeSource(c).StoragePools(), gc.DeepEquals, []string{"loop"})
+}
+
+func (s *volumesSuite) TestAttachDetachVolume(c *gc.C) {
+	source := s.volumeSource(c)
+	path := filepath.Join(s.rootDir, "volumes", "volume-0.img")
+	device, err := source.AttachVolume("volume-0", "test-machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(device, gc.Equals, "/dev/loop3")
+
+	// Attaching again reuses the loop device.
+	device, err = source.AttachVolume("volume-0", "test-machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(device, gc.Equals, "/dev/loop3")
+
+	err = source.DetachVolume("volume-0", "test-machine-1")
+	c.Assert(err, gc.IsNil)
+	err = source.DetachVolume("volume-0", "test-machine-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.commands, jc.DeepEquals, []string{
+		"losetup --associated " + path,
+		"losetup --find --show " + path,
+		"lxc-device -n test-machine-1 add /dev/loop3",
+		"losetup --associated " + path,
+		"lxc-device -n test-machine-1 add /dev/loop3",
+		"losetup --associated " + path,
+		"lxc-device -n test-machine-1 del /dev/loop3",
+		"losetup --detach /dev/loop3",
+		"losetup --associated " + path,
+	})
+}
@@ -65,6 +65,10 @@ type maasEnviron struct {
 	// for which images can be instantiated.
 	supportedArchitectures []string
 
+	// availabilityZonesMutex protects availabilityZones.
+	availabilityZonesMutex sync.Mutex
+	availabilityZones      []common.AvailabilityZone
+
 	// ecfgMutex protects the *Unlocked fields below.
 	ecfgMutex sync.Mutex
 
@@ -74,6 +78,7 @@ type maasEnviron struct {
 }
 
 var _ environs.Environ = (*maasEnviron)(nil)
+var _ common.ZonedEnviron = (*maasEnviron)(nil)
 var _ imagemetadata.SupportsCustomSources = (*maasEnviron)(nil)
 var _ envtools.SupportsCustomSources = (*maasEnviron)(nil)
 
@@ -308,11 +313,111 @@ func (env *maasEnviron) SupportNetworks() bool {
 	return caps.Contains(capNetworksManagement)
 }
 
+// PrecheckInstance is defined on the state.Prechecker interface.
 func (env *maasEnviron) PrecheckInstance(series string, cons constraints.Value, placement string) error {
-	// We treat all placement directives as maas-name.
+	if placement != "" {
+		if _, err := env.parsePlacement(placement); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+type maasPlacement struct {
+	nodeName string
+	zoneName string
+}
+
+// parsePlacement parses a placement directive, which is either
+// "zone=<zone name>" or the hostname of the node to acquire.
+func (env *maasEnviron) parsePlacement(placement string) (*maasPlacement, error) {
+	pos := strings.IndexRune(placement, '=')
+	if pos == -1 {
+		// If there's no '=' delimiter, assume it's a node name.
+		return &maasPlacement{nodeName: placement}, nil
+	}
+	switch key, value := placement[:pos], placement[pos+1:]; key {
+	case "zone":
+		zones, err := env.AvailabilityZones()
+		if err != nil {
+			return nil, err
+		}
+		for _, z := range zones {
+			if z.Name() == value {
+				return &maasPlacement{zoneName: value}, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid availability zone %q", value)
+	}
+	return nil, fmt.Errorf("unknown placement directive: %v", placement)
+}
+
+type maasAvailabilityZone struct {
+	name string
+}
+
+func (z maasAvailabilityZone) Name() string {
+	return z.name
+}
+
+func (z maasAvailabilityZone) Available() bool {
+	// MAAS does not report the availability of zones.
+	return true
+}
+
+// AvailabilityZones returns a slice of availability zones, which
+// correspond to MAAS physical zones.
+func (env *maasEnviron) AvailabilityZones() ([]common.AvailabilityZone, error) {
+	env.availabilityZonesMutex.Lock()
+	defer env.availabilityZonesMutex.Unlock()
+	if env.availabilityZones == nil {
+		zonesObject := env.getMAASClient().GetSubObject("zones")
+		result, err := zonesObject.CallGet("", nil)
+		if err, ok := err.(*gomaasapi.ServerError); ok && err.StatusCode == 404 {
+			return nil, errors.NewNotImplemented(nil, "the MAAS server does not support zones")
+		}
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot query zones")
+		}
+		list, err := result.GetArray()
+		if err != nil {
+			return nil, err
+		}
+		logger.Debugf("availability zones: %+v", list)
+		availabilityZones := make([]common.AvailabilityZone, len(list))
+		for i, obj := range list {
+			zone, err := obj.GetMap()
+			if err != nil {
+				return nil, err
+			}
+			name, err := zone["name"].GetString()
+			if err != nil {
+				return nil, err
+			}
+			availabilityZones[i] = maasAvailabilityZone{name}
+		}
+		env.availabilityZones = availabilityZones
+	}
+	return env.availabilityZones, nil
+}
+
+// InstanceAvailabilityZoneNames returns the availability zone names for each
+// of the specified instances.
+func (env *maasEnviron) InstanceAvailabilityZoneNames(ids []instance.Id) ([]string, error) {
+	instances, err := env.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	zones := make([]string, len(instances))
+	for i, inst := range instances {
+		if inst == nil {
+			continue
+		}
+		zones[i] = inst.(*maasInstance).zone()
+	}
+	return zones, err
+}
+
 const capNetworksManagement = "networks-management"
 
 // getCapabilities asks the MAAS server for its capabilities, if
@@ -384,7 +489,13 @@ func convertConstraints(cons constraints.Value) url.Values {
 		params.Add("mem", fmt.Sprintf("%d", *cons.Mem))
 	}
 	if cons.Tags != nil && len(*cons.Tags) > 0 {
-		params.Add("tags", strings.Join(*cons.Tags, ","))
+		tags, notTags := parseTags(*cons.Tags)
+		if len(tags) > 0 {
+			params.Add("tags", strings.Join(tags, ","))
+		}
+		if len(notTags) > 0 {
+			params.Add("not_tags", strings.Join(notTags, ","))
+		}
 	}
 	// TODO(bug 1212689): ignore root-disk constraint for now.
 	if cons.RootDisk != nil {
@@ -396,6 +507,19 @@ func convertConstraints(cons constraints.Value) url.Values {
 	return params
 }
 
+// parseTags splits tags into those the node must have and those it
+// must not have, which are given with a "^" prefix to the name.
+func parseTags(tags []string) (include, exclude []string) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "^") {
+			exclude = append(exclude, strings.TrimPrefix(tag, "^"))
+		} else {
+			include = append(include, tag)
+		}
+	}
+	return include, exclude
+}
+
 // addNetworks converts networks include/exclude information into
 // url.Values object suitable to pass to MAAS when acquiring a node.
 func addNetworks(params url.Values, includeNetworks, excludeNetworks []string) {
@@ -413,13 +537,16 @@ func addNetworks(params url.Values, includeNetworks, excludeNetworks []string) {
 }
 
 // acquireNode allocates a node from the MAAS.
-func (environ *maasEnviron) acquireNode(nodeName string, cons constraints.Value, includeNetworks, excludeNetworks []string, possibleTools tools.List) (gomaasapi.MAASObject, *tools.Tools, error) {
+func (environ *maasEnviron) acquireNode(nodeName, zoneName string, cons constraints.Value, includeNetworks, excludeNetworks []string, possibleTools tools.List) (gomaasapi.MAASObject, *tools.Tools, error) {
 	acquireParams := convertConstraints(cons)
 	addNetworks(acquireParams, includeNetworks, excludeNetworks)
 	acquireParams.Add("agent_name", environ.ecfg().maasAgentName())
 	if nodeName != "" {
 		acquireParams.Add("name", nodeName)
 	}
+	if zoneName != "" {
+		acquireParams.Add("zone", zoneName)
+	}
 	var result gomaasapi.JSONObject
 	var err error
 	for a := shortAttempt.Start(); a.Next(); {
@@ -490,6 +617,7 @@ func setupJujuNetworking(primaryIface string) (string, error) {
 var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.InstanceType,
+	constraints.SpotPrice,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -524,10 +652,6 @@ func (environ *maasEnviron) setupNetworks(inst instance.Instance, networksToDisa
 	var tempNetworkInfo []network.Info
 	for _, netw := range networks {
 		disabled := networksToDisable.Contains(netw.Name)
-		netCIDR := &net.IPNet{
-			IP:   net.ParseIP(netw.IP),
-			Mask: net.IPMask(net.ParseIP(netw.Mask)),
-		}
 		macs, err := environ.getNetworkMACs(netw.Name)
 		if err != nil {
 			return nil, "", errors.Annotatef(err, "getNetworkMACs failed")
@@ -539,7 +663,7 @@ func (environ *maasEnviron) setupNetworks(inst instance.Instance, networksToDisa
 					MACAddress:    mac,
 					InterfaceName: ifinfo.InterfaceName,
 					DeviceIndex:   ifinfo.DeviceIndex,
-					CIDR:          netCIDR.String(),
+					CIDR:          netw.CIDR(),
 					VLANTag:       netw.VLANTag,
 					ProviderId:    network.Id(netw.Name),
 					NetworkName:   netw.Name,
@@ -572,12 +696,20 @@ func (environ *maasEnviron) StartInstance(args environs.StartInstanceParams) (
 ) {
 	var inst *maasInstance
 	var err error
-	nodeName := args.Placement
+	var placement maasPlacement
+	if args.Placement != "" {
+		p, err := environ.parsePlacement(args.Placement)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		placement = *p
+	}
 	requestedNetworks := args.MachineConfig.Networks
 	includeNetworks := append(args.Constraints.IncludeNetworks(), requestedNetworks...)
 	excludeNetworks := args.Constraints.ExcludeNetworks()
 	node, tools, err := environ.acquireNode(
-		nodeName,
+		placement.nodeName,
+		placement.zoneName,
 		args.Constraints,
 		includeNetworks,
 		excludeNetworks,
@@ -836,6 +968,15 @@ type networkDetails struct {
 	Description string
 }
 
+// CIDR returns the network's address range in CIDR notation.
+func (netw networkDetails) CIDR() string {
+	netCIDR := &net.IPNet{
+		IP:   net.ParseIP(netw.IP),
+		Mask: net.IPMask(net.ParseIP(netw.Mask)),
+	}
+	return netCIDR.String()
+}
+
 // getInstanceNetworks returns a list of all MAAS networks for a given node.
 func (environ *maasEnviron) getInstanceNetworks(inst instance.Instance) ([]networkDetails, error) {
 	maasInst := inst.(*maasInstance)
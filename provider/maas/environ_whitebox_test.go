@@ -279,7 +279,7 @@ func (suite *environSuite) TestAcquireNode(c *gc.C) {
 	env := suite.makeEnviron()
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 
-	_, _, err := env.acquireNode("", constraints.Value{}, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("", "", constraints.Value{}, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	operations := suite.testMAASObject.TestServer.NodeOperations()
@@ -299,7 +299,7 @@ func (suite *environSuite) TestAcquireNodeByName(c *gc.C) {
 	env := suite.makeEnviron()
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 
-	_, _, err := env.acquireNode("host0", constraints.Value{}, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("host0", "", constraints.Value{}, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	operations := suite.testMAASObject.TestServer.NodeOperations()
@@ -313,6 +313,19 @@ func (suite *environSuite) TestAcquireNodeByName(c *gc.C) {
 	c.Assert(nodeName, gc.Equals, "host0")
 }
 
+func (suite *environSuite) TestAcquireNodeInZone(c *gc.C) {
+	stor := NewStorage(suite.makeEnviron())
+	fakeTools := envtesting.MustUploadFakeToolsVersions(stor, version.Current)[0]
+	env := suite.makeEnviron()
+	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
+
+	_, _, err := env.acquireNode("", "zone1", constraints.Value{}, nil, nil, tools.List{fakeTools})
+
+	c.Check(err, gc.IsNil)
+	values := suite.testMAASObject.TestServer.NodeOperationRequestValues()["node0"][0]
+	c.Assert(values.Get("zone"), gc.Equals, "zone1")
+}
+
 func (suite *environSuite) TestAcquireNodeTakesConstraintsIntoAccount(c *gc.C) {
 	stor := NewStorage(suite.makeEnviron())
 	fakeTools := envtesting.MustUploadFakeToolsVersions(stor, version.Current)[0]
@@ -320,7 +333,7 @@ func (suite *environSuite) TestAcquireNodeTakesConstraintsIntoAccount(c *gc.C) {
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 	constraints := constraints.Value{Arch: stringp("arm"), Mem: uint64p(1024)}
 
-	_, _, err := env.acquireNode("", constraints, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("", "", constraints, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	requestValues := suite.testMAASObject.TestServer.NodeOperationRequestValues()
@@ -336,7 +349,7 @@ func (suite *environSuite) TestAcquireNodePassedAgentName(c *gc.C) {
 	env := suite.makeEnviron()
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 
-	_, _, err := env.acquireNode("", constraints.Value{}, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("", "", constraints.Value{}, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	requestValues := suite.testMAASObject.TestServer.NodeOperationRequestValues()
@@ -359,6 +372,10 @@ var testValues = []struct {
 	// RootDisk is ignored.
 	{constraints.Value{RootDisk: uint64p(8192)}, url.Values{}},
 	{constraints.Value{Tags: &[]string{"foo", "bar"}}, url.Values{"tags": {"foo,bar"}}},
+
+	// Tags with a "^" prefix are excluded.
+	{constraints.Value{Tags: &[]string{"foo", "^gpu", "^bar"}}, url.Values{"tags": {"foo"}, "not_tags": {"gpu,bar"}}},
+	{constraints.Value{Tags: &[]string{"^gpu"}}, url.Values{"not_tags": {"gpu"}}},
 	{constraints.Value{Arch: stringp("arm"), CpuCores: uint64p(4), Mem: uint64p(1024), CpuPower: uint64p(1024), RootDisk: uint64p(8192), Tags: &[]string{"foo", "bar"}}, url.Values{"arch": {"arm"}, "cpu_count": {"4"}, "mem": {"1024"}, "tags": {"foo,bar"}}},
 }
 
@@ -879,3 +896,28 @@ func (suite *environSuite) TestSupportNetworks(c *gc.C) {
 	env := suite.makeEnviron()
 	c.Assert(env.SupportNetworks(), jc.IsTrue)
 }
+
+func (suite *environSuite) TestAvailabilityZones(c *gc.C) {
+	suite.testMAASObject.TestServer.AddZone("zone1", "the grass is greener in zone1")
+	suite.testMAASObject.TestServer.AddZone("zone2", "")
+	env := suite.makeEnviron()
+	zones, err := env.AvailabilityZones()
+	c.Assert(err, gc.IsNil)
+	c.Assert(zones, gc.HasLen, 2)
+	c.Assert(zones[0].Name(), gc.Equals, "zone1")
+	c.Assert(zones[1].Name(), gc.Equals, "zone2")
+	c.Assert(zones[0].Available(), jc.IsTrue)
+}
+
+func (suite *environSuite) TestPrecheckInstancePlacement(c *gc.C) {
+	suite.testMAASObject.TestServer.AddZone("zone1", "")
+	env := suite.makeEnviron()
+	err := env.PrecheckInstance("precise", constraints.Value{}, "zone=zone1")
+	c.Assert(err, gc.IsNil)
+	err = env.PrecheckInstance("precise", constraints.Value{}, "host0")
+	c.Assert(err, gc.IsNil)
+	err = env.PrecheckInstance("precise", constraints.Value{}, "zone=zone2")
+	c.Assert(err, gc.ErrorMatches, `invalid availability zone "zone2"`)
+	err = env.PrecheckInstance("precise", constraints.Value{}, "rack=r1")
+	c.Assert(err, gc.ErrorMatches, `unknown placement directive: rack=r1`)
+}
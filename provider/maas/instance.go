@@ -202,6 +202,24 @@ func (mi *maasInstance) hostname() (string, error) {
 	return mi.getMaasObject().GetField("hostname")
 }
 
+// zone returns the name of the physical zone the node is in, or the
+// empty string if it is not known.
+func (mi *maasInstance) zone() string {
+	obj := mi.getMaasObject().GetMap()["zone"]
+	if obj.IsNil() {
+		return ""
+	}
+	zone, err := obj.GetMap()
+	if err != nil {
+		return ""
+	}
+	name, err := zone["name"].GetString()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
 // MAAS does not do firewalling so these port methods do nothing.
 func (mi *maasInstance) OpenPorts(machineId string, ports []network.Port) error {
 	logger.Debugf("unimplemented OpenPorts() called")
@@ -175,3 +175,17 @@ func (s *instanceTest) testHardwareCharacteristicsMissing(c *gc.C, json, expect
 	_, err := inst.hardwareCharacteristics()
 	c.Assert(err, gc.ErrorMatches, expect)
 }
+
+func (s *instanceTest) TestZone(c *gc.C) {
+	jsonValue := `{"system_id": "system_id", "zone": {"name": "zone1", "description": "the grass is greener in zone1"}}`
+	obj := s.testMAASObject.TestServer.NewNode(jsonValue)
+	inst := maasInstance{maasObject: &obj, environ: s.makeEnviron()}
+	c.Assert(inst.zone(), gc.Equals, "zone1")
+}
+
+func (s *instanceTest) TestZoneMissing(c *gc.C) {
+	jsonValue := `{"system_id": "system_id"}`
+	obj := s.testMAASObject.TestServer.NewNode(jsonValue)
+	inst := maasInstance{maasObject: &obj, environ: s.makeEnviron()}
+	c.Assert(inst.zone(), gc.Equals, "")
+}
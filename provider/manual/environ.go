@@ -285,6 +285,7 @@ var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.InstanceType,
 	constraints.Tags,
+	constraints.SpotPrice,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
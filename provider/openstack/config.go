@@ -6,6 +6,7 @@ package openstack
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/juju/schema"
 	"launchpad.net/goose/identity"
@@ -26,6 +27,8 @@ var configFields = schema.Fields{
 	"use-floating-ip":      schema.Bool(),
 	"use-default-secgroup": schema.Bool(),
 	"network":              schema.String(),
+	"floating-ip-pool":     schema.String(),
+	"security-groups":      schema.String(),
 }
 var configDefaults = schema.Defaults{
 	"username":             "",
@@ -40,6 +43,8 @@ var configDefaults = schema.Defaults{
 	"use-floating-ip":      false,
 	"use-default-secgroup": false,
 	"network":              "",
+	"floating-ip-pool":     "",
+	"security-groups":      "",
 }
 
 type environConfig struct {
@@ -91,8 +96,33 @@ func (c *environConfig) useDefaultSecurityGroup() bool {
 	return c.attrs["use-default-secgroup"].(bool)
 }
 
-func (c *environConfig) network() string {
-	return c.attrs["network"].(string)
+// networks returns the labels or ids of the networks to attach
+// instances to, in the order their interfaces should appear.
+func (c *environConfig) networks() []string {
+	return splitList(c.attrs["network"].(string))
+}
+
+func (c *environConfig) floatingIPPool() string {
+	return c.attrs["floating-ip-pool"].(string)
+}
+
+// securityGroups returns the names of the existing security groups
+// that instances should be started in, instead of the groups juju
+// would otherwise create.
+func (c *environConfig) securityGroups() []string {
+	return splitList(c.attrs["security-groups"].(string))
+}
+
+// splitList splits a comma-separated list, ignoring empty items and
+// white space around items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func (p environProvider) newConfig(cfg *config.Config) (*environConfig, error) {
@@ -186,6 +216,10 @@ func (p environProvider) Validate(cfg, old *config.Config) (valid *config.Config
 		ecfg.attrs["region"] = cred.Region
 	}
 
+	if ecfg.floatingIPPool() != "" && !ecfg.useFloatingIP() {
+		return nil, fmt.Errorf("floating-ip-pool is set but use-floating-ip is not")
+	}
+
 	if old != nil {
 		attrs := old.UnknownAttrs()
 		if region, _ := attrs["region"].(string); ecfg.region() != region {
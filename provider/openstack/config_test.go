@@ -55,7 +55,9 @@ type configTest struct {
 	controlBucket           string
 	useFloatingIP           bool
 	useDefaultSecurityGroup bool
-	network                 string
+	networks                []string
+	floatingIPPool          string
+	securityGroups          []string
 	username                string
 	password                string
 	tenantName              string
@@ -154,7 +156,9 @@ func (t configTest) check(c *gc.C) {
 	}
 	c.Assert(ecfg.useFloatingIP(), gc.Equals, t.useFloatingIP)
 	c.Assert(ecfg.useDefaultSecurityGroup(), gc.Equals, t.useDefaultSecurityGroup)
-	c.Assert(ecfg.network(), gc.Equals, t.network)
+	c.Assert(ecfg.networks(), gc.DeepEquals, t.networks)
+	c.Assert(ecfg.floatingIPPool(), gc.Equals, t.floatingIPPool)
+	c.Assert(ecfg.securityGroups(), gc.DeepEquals, t.securityGroups)
 	// Default should be true
 	expectedHostnameVerification := true
 	if t.sslHostnameSet {
@@ -424,13 +428,38 @@ var configTests = []configTest{
 		sslHostnameSet:          true,
 	}, {
 		summary: "default network",
-		network: "",
 	}, {
 		summary: "network",
 		config: attrs{
 			"network": "a-network-label",
 		},
-		network: "a-network-label",
+		networks: []string{"a-network-label"},
+	}, {
+		summary: "multiple networks",
+		config: attrs{
+			"network": "a-network-label, f81d4fae-7dec-11d0-a765-00a0c91e6bf6,",
+		},
+		networks: []string{"a-network-label", "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"},
+	}, {
+		summary: "floating ip pool",
+		config: attrs{
+			"use-floating-ip":  true,
+			"floating-ip-pool": "a-pool",
+		},
+		useFloatingIP:  true,
+		floatingIPPool: "a-pool",
+	}, {
+		summary: "floating ip pool without floating ips",
+		config: attrs{
+			"floating-ip-pool": "a-pool",
+		},
+		err: "floating-ip-pool is set but use-floating-ip is not",
+	}, {
+		summary: "security groups",
+		config: attrs{
+			"security-groups": "group-a,group-b",
+		},
+		securityGroups: []string{"group-a", "group-b"},
 	},
 }
 
@@ -345,3 +345,8 @@ func GetNovaClient(e environs.Environ) *nova.Client {
 func ResolveNetwork(e environs.Environ, networkName string) (string, error) {
 	return e.(*environ).resolveNetwork(networkName)
 }
+
+// AllocatePublicIP exposes environ helper function allocatePublicIP for testing
+func AllocatePublicIP(e environs.Environ) (*nova.FloatingIP, error) {
+	return e.(*environ).allocatePublicIP()
+}
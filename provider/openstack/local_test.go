@@ -5,11 +5,13 @@ package openstack_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strings"
 
 	jujuerrors "github.com/juju/errors"
@@ -392,6 +394,116 @@ func (s *localServerSuite) TestStartInstanceNetworkUnknownId(c *gc.C) {
 		"404; error info: .*itemNotFound.*")
 }
 
+func (s *localServerSuite) TestStartInstanceNetworkInfo(c *gc.C) {
+	// The nova test service does not implement os-interface, so
+	// serve it here and pass everything else on.
+	s.srv.Server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/os-interface") {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"interfaceAttachments": [
+				{"mac_addr": "aa:bb:cc:dd:ee:f0", "net_id": "1", "port_id": "port-0"},
+				{"mac_addr": "aa:bb:cc:dd:ee:f1", "net_id": "no-such-network", "port_id": "port-1"}
+			]}`)
+			return
+		}
+		s.srv.Mux.ServeHTTP(w, req)
+	})
+	defer func() {
+		s.srv.Server.Config.Handler = s.srv.Mux
+	}()
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"network": "net",
+	}))
+	c.Assert(err, gc.IsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, gc.IsNil)
+	inst, _, networks, err := testing.StartInstance(env, "100")
+	c.Assert(err, gc.IsNil)
+	// The interface on the unknown network is left out.
+	c.Assert(networks, gc.HasLen, 1)
+	c.Check(networks[0].DeviceIndex, gc.Equals, 0)
+	c.Check(networks[0].MACAddress, gc.Equals, "aa:bb:cc:dd:ee:f0")
+	c.Check(networks[0].NetworkName, gc.Equals, "net")
+	c.Check(networks[0].ProviderId, gc.Equals, network.Id("1"))
+	c.Check(networks[0].InterfaceName, gc.Equals, "eth0")
+	err = env.StopInstances(inst.Id())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *localServerSuite) TestStartInstanceExistingSecurityGroups(c *gc.C) {
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"firewall-mode":   "instance",
+		"security-groups": "existing-group",
+	}))
+	c.Assert(err, gc.IsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, gc.IsNil)
+	_, err = openstack.GetNovaClient(env).CreateSecurityGroup("existing-group", "not created by juju")
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "100")
+	// No juju groups are created.
+	assertSecurityGroups(c, env, []string{"default", "existing-group"})
+	// Ports are left alone.
+	err = inst.OpenPorts("100", []network.Port{{Protocol: "tcp", Number: 80}})
+	c.Assert(err, gc.IsNil)
+	ports, err := inst.Ports("100")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+	err = env.StopInstances(inst.Id())
+	c.Assert(err, gc.IsNil)
+	assertSecurityGroups(c, env, []string{"default", "existing-group"})
+}
+
+func (s *localServerSuite) TestStartInstanceUnknownSecurityGroup(c *gc.C) {
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"security-groups": "no-such-group",
+	}))
+	c.Assert(err, gc.IsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, gc.IsNil)
+	inst, _, _, err := testing.StartInstance(env, "100")
+	c.Check(inst, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, `cannot set up groups: loading security group "no-such-group": .*`)
+}
+
+func (s *localServerSuite) TestAllocatePublicIPFromPool(c *gc.C) {
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"use-floating-ip":  true,
+		"floating-ip-pool": "juju-pool",
+	}))
+	c.Assert(err, gc.IsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, gc.IsNil)
+	// An unassigned address from another pool is not used.
+	_, err = openstack.GetNovaClient(env).AllocateFloatingIP()
+	c.Assert(err, gc.IsNil)
+
+	// The nova test service ignores the requested pool, so
+	// serve the allocation here and pass everything else on.
+	var requestedPool string
+	s.srv.Server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/os-floating-ips") {
+			var body struct {
+				Pool string `json:"pool"`
+			}
+			c.Check(json.NewDecoder(req.Body).Decode(&body), gc.IsNil)
+			requestedPool = body.Pool
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"floating_ip": {"id": "99", "ip": "10.0.9.9", "pool": %q}}`, body.Pool)
+			return
+		}
+		s.srv.Mux.ServeHTTP(w, req)
+	})
+	defer func() {
+		s.srv.Server.Config.Handler = s.srv.Mux
+	}()
+	fip, err := openstack.AllocatePublicIP(env)
+	c.Assert(err, gc.IsNil)
+	c.Assert(requestedPool, gc.Equals, "juju-pool")
+	c.Assert(fip.IP, gc.Equals, "10.0.9.9")
+	c.Assert(fip.Pool, gc.Equals, "juju-pool")
+}
+
 func assertSecurityGroups(c *gc.C, env environs.Environ, expected []string) {
 	novaClient := openstack.GetNovaClient(env)
 	groups, err := novaClient.ListSecurityGroups()
@@ -1547,3 +1659,95 @@ func (t *localServerSuite) TestStartInstanceDistributionAZNotImplemented(c *gc.C
 	inst, _ := testing.AssertStartInstance(c, env, "1")
 	c.Assert(openstack.InstanceServerDetail(inst).AvailabilityZone, gc.Equals, "")
 }
+
+var serverMetadataPath = regexp.MustCompile("/servers/([^/]+)/metadata(?:/([^/]+))?$")
+
+// serverMetadata doubles the nova server metadata calls, which the
+// Openstack service double does not implement, passing other requests
+// on to the service double.
+type serverMetadata struct {
+	handler  http.Handler
+	metadata map[string]map[string]string
+}
+
+func (m *serverMetadata) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	match := serverMetadataPath.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		m.handler.ServeHTTP(w, req)
+		return
+	}
+	id, key := match[1], match[2]
+	if m.metadata[id] == nil {
+		m.metadata[id] = make(map[string]string)
+	}
+	switch {
+	case req.Method == "GET" && key == "":
+	case req.Method == "POST" && key == "":
+		var body struct {
+			Metadata map[string]string `json:"metadata"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for key, value := range body.Metadata {
+			m.metadata[id][key] = value
+		}
+	case req.Method == "DELETE" && key != "":
+		delete(m.metadata[id], key)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"metadata": m.metadata[id]})
+}
+
+func (t *localServerSuite) TestTagInstance(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "1")
+	metadata := &serverMetadata{
+		handler:  t.srv.Server.Config.Handler,
+		metadata: make(map[string]map[string]string),
+	}
+	t.srv.Server.Config.Handler = metadata
+	defer func() { t.srv.Server.Config.Handler = metadata.handler }()
+	tagger := env.(environs.InstanceTagger)
+
+	// "é" is encoded in two bytes, so the long value is truncated
+	// before the character that crosses the limit.
+	long := strings.Repeat("x", 254) + "é"
+	err = tagger.TagInstance(inst.Id(), map[string]string{
+		"team":               "ops",
+		"long":               long,
+		"juju-resource-tags": "long team",
+		"juju-machine-id":    "1",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(metadata.metadata[string(inst.Id())], jc.DeepEquals, map[string]string{
+		"team":               "ops",
+		"long":               strings.Repeat("x", 254),
+		"juju-resource-tags": "long team",
+		"juju-machine-id":    "1",
+	})
+
+	// Tags set by juju that are no longer wanted are removed, and
+	// other metadata is left alone.
+	metadata.metadata[string(inst.Id())]["owner"] = "bob"
+	err = tagger.TagInstance(inst.Id(), map[string]string{
+		"team":               "dev",
+		"juju-resource-tags": "team",
+		"juju-machine-id":    "1",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(metadata.metadata[string(inst.Id())], jc.DeepEquals, map[string]string{
+		"owner":              "bob",
+		"team":               "dev",
+		"juju-resource-tags": "team",
+		"juju-machine-id":    "1",
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/juju/utils"
 	"launchpad.net/goose/client"
 	gooseerrors "launchpad.net/goose/errors"
+	goosehttp "launchpad.net/goose/http"
 	"launchpad.net/goose/identity"
 	"launchpad.net/goose/nova"
 	"launchpad.net/goose/swift"
@@ -83,10 +84,25 @@ openstack:
 
     # network specifies the network label or uuid to bring machines up
     # on, in the case where multiple networks exist. It may be omitted
-    # otherwise.
+    # otherwise. A comma-separated list attaches machines to several
+    # networks, the first of which holds their primary interface.
     #
     # network: <your network label or uuid>
 
+    # floating-ip-pool names the pool that floating IP addresses are
+    # allocated from when use-floating-ip is true. When omitted, the
+    # default pool is used.
+    #
+    # floating-ip-pool: <your pool name>
+
+    # security-groups is a comma-separated list of existing security
+    # groups to start machines in. When set, juju does not create any
+    # security groups of its own, and does not open or close ports:
+    # the rules in the given groups must allow access to juju's API
+    # and state ports, SSH, and the ports used by your services.
+    #
+    # security-groups: <your security groups>
+
     # tools-metadata-url specifies the location of the Juju tools and
     # metadata. It defaults to the global public tools metadata
     # location https://streams.canonical.com/tools.
@@ -311,6 +327,7 @@ var _ envtools.SupportsCustomSources = (*environ)(nil)
 var _ simplestreams.HasRegion = (*environ)(nil)
 var _ state.Prechecker = (*environ)(nil)
 var _ state.InstanceDistributor = (*environ)(nil)
+var _ environs.InstanceTagger = (*environ)(nil)
 
 type openstackInstance struct {
 	e        *environ
@@ -448,6 +465,10 @@ func (inst *openstackInstance) OpenPorts(machineId string, ports []network.Port)
 		return fmt.Errorf("invalid firewall mode %q for opening ports on instance",
 			inst.e.Config().FirewallMode())
 	}
+	if inst.e.usingExistingGroups() {
+		logger.Warningf("not opening ports %v on %q: security groups are not managed by juju", ports, inst.Id())
+		return nil
+	}
 	name := inst.e.machineGroupName(machineId)
 	if err := inst.e.openPortsInGroup(name, ports); err != nil {
 		return err
@@ -461,6 +482,10 @@ func (inst *openstackInstance) ClosePorts(machineId string, ports []network.Port
 		return fmt.Errorf("invalid firewall mode %q for closing ports on instance",
 			inst.e.Config().FirewallMode())
 	}
+	if inst.e.usingExistingGroups() {
+		logger.Warningf("not closing ports %v on %q: security groups are not managed by juju", ports, inst.Id())
+		return nil
+	}
 	name := inst.e.machineGroupName(machineId)
 	if err := inst.e.closePortsInGroup(name, ports); err != nil {
 		return err
@@ -474,6 +499,9 @@ func (inst *openstackInstance) Ports(machineId string) ([]network.Port, error) {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance",
 			inst.e.Config().FirewallMode())
 	}
+	if inst.e.usingExistingGroups() {
+		return nil, nil
+	}
 	name := inst.e.machineGroupName(machineId)
 	return inst.e.portsInGroup(name)
 }
@@ -526,6 +554,7 @@ func (e *environ) SupportNetworks() bool {
 var unsupportedConstraints = []string{
 	constraints.Tags,
 	constraints.CpuPower,
+	constraints.SpotPrice,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -836,6 +865,7 @@ func (e *environ) allocatePublicIP() (*nova.FloatingIP, error) {
 	if err != nil {
 		return nil, err
 	}
+	pool := e.ecfg().floatingIPPool()
 	var newfip *nova.FloatingIP
 	for _, fip := range fips {
 		newfip = &fip
@@ -843,6 +873,10 @@ func (e *environ) allocatePublicIP() (*nova.FloatingIP, error) {
 			// unavailable, skip
 			newfip = nil
 			continue
+		} else if pool != "" && fip.Pool != pool {
+			// from another pool, skip
+			newfip = nil
+			continue
 		} else {
 			logger.Debugf("found unassigned public ip: %v", newfip.IP)
 			// unassigned, we can use it
@@ -851,7 +885,11 @@ func (e *environ) allocatePublicIP() (*nova.FloatingIP, error) {
 	}
 	if newfip == nil {
 		// allocate a new IP and use it
-		newfip, err = e.nova().AllocateFloatingIP()
+		if pool != "" {
+			newfip, err = e.allocateFloatingIPFromPool(pool)
+		} else {
+			newfip, err = e.nova().AllocateFloatingIP()
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -860,6 +898,29 @@ func (e *environ) allocatePublicIP() (*nova.FloatingIP, error) {
 	return newfip, nil
 }
 
+// allocateFloatingIPFromPool allocates a new floating IP address from
+// the named pool.
+func (e *environ) allocateFloatingIPFromPool(pool string) (*nova.FloatingIP, error) {
+	// The version of goose in use can only allocate from the
+	// default pool, so the request is made directly.
+	var req struct {
+		Pool string `json:"pool"`
+	}
+	req.Pool = pool
+	var resp struct {
+		FloatingIP nova.FloatingIP `json:"floating_ip"`
+	}
+	requestData := goosehttp.RequestData{
+		ReqValue:       &req,
+		RespValue:      &resp,
+		ExpectedStatus: []int{http.StatusOK},
+	}
+	if err := e.client.SendRequest(client.POST, "compute", "os-floating-ips", &requestData); err != nil {
+		return nil, fmt.Errorf("cannot allocate a floating ip from pool %q: %v", pool, err)
+	}
+	return &resp.FloatingIP, nil
+}
+
 // assignPublicIP tries to assign the given floating IP address to the
 // specified server, or returns an error.
 func (e *environ) assignPublicIP(fip *nova.FloatingIP, serverId string) (err error) {
@@ -962,8 +1023,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 	}
 	logger.Debugf("openstack user data; %d bytes", len(userData))
 	var networks = []nova.ServerNetworks{}
-	usingNetwork := e.ecfg().network()
-	if usingNetwork != "" {
+	for _, usingNetwork := range e.ecfg().networks() {
 		networkId, err := e.resolveNetwork(usingNetwork)
 		if err != nil {
 			return nil, nil, nil, err
@@ -1039,7 +1099,63 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 		inst.floatingIP = publicIP
 		logger.Infof("assigned public IP %s to %q", publicIP.IP, inst.Id())
 	}
-	return inst, inst.hardwareCharacteristics(), nil, nil
+	return inst, inst.hardwareCharacteristics(), e.networkInfo(inst.Id()), nil
+}
+
+// networkInfo returns information about the network interfaces of the
+// server with the given id. Failure to retrieve it is not fatal, as
+// not all OpenStack deployments support the os-interface extension, so
+// nil is returned in that case.
+func (e *environ) networkInfo(id instance.Id) []network.Info {
+	// The version of goose in use has no call for listing the
+	// interfaces of a server, so the request is made directly.
+	var resp struct {
+		InterfaceAttachments []struct {
+			MACAddress string `json:"mac_addr"`
+			NetworkId  string `json:"net_id"`
+			PortId     string `json:"port_id"`
+		} `json:"interfaceAttachments"`
+	}
+	requestData := goosehttp.RequestData{
+		RespValue:      &resp,
+		ExpectedStatus: []int{http.StatusOK},
+	}
+	apiCall := fmt.Sprintf("servers/%s/os-interface", id)
+	if err := e.client.SendRequest(client.GET, "compute", apiCall, &requestData); err != nil {
+		logger.Warningf("cannot get network interfaces of instance %q: %v", id, err)
+		return nil
+	}
+	networks, err := e.nova().ListNetworks()
+	if err != nil {
+		logger.Warningf("cannot list networks: %v", err)
+		return nil
+	}
+	byId := make(map[string]nova.Network)
+	for _, net := range networks {
+		byId[net.Id] = net
+	}
+	var info []network.Info
+	for _, iface := range resp.InterfaceAttachments {
+		net, ok := byId[iface.NetworkId]
+		if iface.MACAddress == "" || !ok || !names.IsNetwork(net.Label) {
+			logger.Debugf("ignoring network interface %+v of instance %q", iface, id)
+			continue
+		}
+		var cidr string
+		if net.Cidr != nil {
+			cidr = *net.Cidr
+		}
+		index := len(info)
+		info = append(info, network.Info{
+			DeviceIndex:   index,
+			MACAddress:    iface.MACAddress,
+			CIDR:          cidr,
+			NetworkName:   net.Label,
+			ProviderId:    network.Id(iface.NetworkId),
+			InterfaceName: fmt.Sprintf("eth%d", index),
+		})
+	}
+	return info
 }
 
 func isNoValidHostsError(err error) bool {
@@ -1047,10 +1163,58 @@ func isNoValidHostsError(err error) bool {
 	return ok && strings.Contains(gooseErr.Cause().Error(), "No valid host was found")
 }
 
+// maxMetadataValueLength is the maximum length of the value of an item
+// of server metadata.
+const maxMetadataValueLength = 255
+
+// TagInstance implements environs.InstanceTagger.TagInstance.
+// The tags are set as metadata on the instance's server. Tag values
+// longer than the metadata allows are truncated.
+func (e *environ) TagInstance(id instance.Id, tags map[string]string) error {
+	// The version of goose in use has no calls for the metadata of
+	// an existing server, so the requests are made directly.
+	apiCall := fmt.Sprintf("servers/%s/metadata", id)
+	var current struct {
+		Metadata map[string]string `json:"metadata"`
+	}
+	requestData := goosehttp.RequestData{
+		RespValue:      &current,
+		ExpectedStatus: []int{http.StatusOK},
+	}
+	if err := e.client.SendRequest(client.GET, "compute", apiCall, &requestData); err != nil {
+		return fmt.Errorf("cannot get tags of instance %q: %v", id, err)
+	}
+	// Stale tags are removed first, so that they are still listed
+	// by the current tags if removing them fails.
+	for _, key := range environs.StaleInstanceTags(current.Metadata, tags) {
+		requestData := goosehttp.RequestData{
+			ExpectedStatus: []int{http.StatusNoContent},
+		}
+		if err := e.client.SendRequest(client.DELETE, "compute", apiCall+"/"+key, &requestData); err != nil {
+			return fmt.Errorf("cannot remove tag %q from instance %q: %v", key, id, err)
+		}
+	}
+	var req struct {
+		Metadata map[string]string `json:"metadata"`
+	}
+	req.Metadata = make(map[string]string)
+	for key, value := range tags {
+		req.Metadata[key] = environs.TruncateTagValue(value, maxMetadataValueLength)
+	}
+	requestData = goosehttp.RequestData{
+		ReqValue:       &req,
+		ExpectedStatus: []int{http.StatusOK},
+	}
+	if err := e.client.SendRequest(client.POST, "compute", apiCall, &requestData); err != nil {
+		return fmt.Errorf("cannot tag instance %q: %v", id, err)
+	}
+	return nil
+}
+
 func (e *environ) StopInstances(ids ...instance.Id) error {
 	// If in instance firewall mode, gather the security group names.
 	var securityGroupNames []string
-	if e.Config().FirewallMode() == config.FwInstance {
+	if e.Config().FirewallMode() == config.FwInstance && !e.usingExistingGroups() {
 		instances, err := e.Instances(ids)
 		if err == environs.ErrNoInstances {
 			return nil
@@ -1337,6 +1501,10 @@ func (e *environ) OpenPorts(ports []network.Port) error {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on environment",
 			e.Config().FirewallMode())
 	}
+	if e.usingExistingGroups() {
+		logger.Warningf("not opening ports %v: security groups are not managed by juju", ports)
+		return nil
+	}
 	if err := e.openPortsInGroup(e.globalGroupName(), ports); err != nil {
 		return err
 	}
@@ -1349,6 +1517,10 @@ func (e *environ) ClosePorts(ports []network.Port) error {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on environment",
 			e.Config().FirewallMode())
 	}
+	if e.usingExistingGroups() {
+		logger.Warningf("not closing ports %v: security groups are not managed by juju", ports)
+		return nil
+	}
 	if err := e.closePortsInGroup(e.globalGroupName(), ports); err != nil {
 		return err
 	}
@@ -1361,6 +1533,9 @@ func (e *environ) Ports() ([]network.Port, error) {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment",
 			e.Config().FirewallMode())
 	}
+	if e.usingExistingGroups() {
+		return nil, nil
+	}
 	return e.portsInGroup(e.globalGroupName())
 }
 
@@ -1419,6 +1594,9 @@ func (e *environ) setUpGlobalGroup(groupName string, statePort, apiPort int) (no
 // people that happen to share an openstack account and name their environment
 // "openstack" don't end up destroying each other's machines.
 func (e *environ) setUpGroups(machineId string, statePort, apiPort int) ([]nova.SecurityGroup, error) {
+	if e.usingExistingGroups() {
+		return e.existingGroups()
+	}
 	jujuGroup, err := e.setUpGlobalGroup(e.jujuGroupName(), statePort, apiPort)
 	if err != nil {
 		return nil, err
@@ -1444,6 +1622,31 @@ func (e *environ) setUpGroups(machineId string, statePort, apiPort int) ([]nova.
 	return groups, nil
 }
 
+// usingExistingGroups reports whether instances are started in
+// security groups named in the environment configuration, rather
+// than in groups created and managed by juju.
+func (e *environ) usingExistingGroups() bool {
+	return len(e.ecfg().securityGroups()) > 0
+}
+
+// existingGroups returns the security groups named in the environment
+// configuration, and the default group if that is to be used too.
+func (e *environ) existingGroups() ([]nova.SecurityGroup, error) {
+	groupNames := e.ecfg().securityGroups()
+	if e.ecfg().useDefaultSecurityGroup() {
+		groupNames = append(groupNames, "default")
+	}
+	groups := make([]nova.SecurityGroup, len(groupNames))
+	for i, name := range groupNames {
+		group, err := e.nova().SecurityGroupByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("loading security group %q: %v", name, err)
+		}
+		groups[i] = *group
+	}
+	return groups, nil
+}
+
 // zeroGroup holds the zero security group.
 var zeroGroup nova.SecurityGroup
 
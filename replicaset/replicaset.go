@@ -35,6 +35,15 @@ const (
 	// when it thinks that replicaset members are unreachable. This can
 	// occur if replSetInitiate is executed shortly after starting up mongo.
 	rsMembersUnreachableError = "all members and seeds must be reachable to initiate set"
+
+	// stepDownSeconds is the number of seconds for which a primary
+	// that is asked to step down is ineligible to become primary
+	// again.
+	stepDownSeconds = 60
+
+	// JujuMachineKey is the key of the member tag that holds the id
+	// of the juju machine running the member.
+	JujuMachineKey = "juju-machine-id"
 )
 
 var logger = loggo.GetLogger("juju.replicaset")
@@ -272,6 +281,22 @@ func Set(session *mgo.Session, members []Member) error {
 	return applyRelSetConfig("Set", session, &oldconfig, config)
 }
 
+// StepDownPrimary asks the primary of the session's replica set to
+// step down, so that one of the secondaries is elected primary in its
+// place. The old primary does not stand for election again for a
+// minute.
+func StepDownPrimary(session *mgo.Session) error {
+	err := session.Run(bson.D{{"replSetStepDown", stepDownSeconds}}, nil)
+	if err == io.EOF {
+		// The primary drops all its connections when it steps
+		// down, so the session must be refreshed.
+		logger.Debugf("got EOF while stepping down primary, calling session.Refresh()")
+		session.Refresh()
+		return nil
+	}
+	return err
+}
+
 // Config reports information about the configuration of a given mongo node
 type IsMasterResults struct {
 	// The following fields hold information about the specific mongodb node.
@@ -399,6 +424,11 @@ type MemberStatus struct {
 	// between the remote member and the local instance.  It is zero for the
 	// member that the session is connected to.
 	Ping time.Duration `bson:"pingMS"`
+
+	// Optime holds the time of the last operation the member
+	// applied from the oplog. A secondary lags the primary by the
+	// difference between their optimes.
+	Optime time.Time `bson:"optimeDate"`
 }
 
 // MemberState represents the state of a replica set member.
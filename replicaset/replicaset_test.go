@@ -392,12 +392,29 @@ func (s *MongoSuite) TestCurrentStatus(c *gc.C) {
 		// ping is always going to be zero since we're on localhost
 		// so we can't really test it right now
 
-		// now overwrite Uptime so it won't throw off DeepEquals
+		// non-empty optime
+		c.Check(res.Members[x].Optime.IsZero(), gc.Equals, false)
+
+		// now overwrite Uptime and Optime so they won't throw off DeepEquals
 		res.Members[x].Uptime = 0
+		res.Members[x].Optime = time.Time{}
 	}
 	c.Check(res, jc.DeepEquals, expected)
 }
 
+func (s *MongoSuite) TestStepDownPrimaryWithoutSecondaries(c *gc.C) {
+	session := s.root.MustDial()
+	defer session.Close()
+
+	// There is no secondary to take over, so the primary refuses
+	// to step down.
+	err := StepDownPrimary(session)
+	c.Assert(err, gc.NotNil)
+	status, err := CurrentStatus(session)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Members[0].State, gc.Equals, MemberState(PrimaryState))
+}
+
 func closeEnough(expected, obtained time.Time) bool {
 	t := obtained.Sub(expected)
 	return (-500*time.Millisecond) < t && t < (500*time.Millisecond)
@@ -10,6 +10,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/names"
 	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils/set"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 
@@ -496,7 +497,14 @@ func (st *State) maintainStateServersOps(mdocs []*machineDoc, currentInfo *State
 // EnsureAvailability adds state server machines as necessary to make
 // the number of live state servers equal to numStateServers. The given
 // constraints and series will be attached to any new machines.
-func (st *State) EnsureAvailability(numStateServers int, cons constraints.Value, series string) (StateServersChanges, error) {
+//
+// If there are more voting state servers than numStateServers, the
+// excess state servers are removed, as are the state server machines
+// with the given ids. A state server that is removed is first demoted,
+// so that the worker that maintains the replica set removes its vote
+// when it is safe to do so; it then loses its JobManageEnviron job and
+// the machine is destroyed.
+func (st *State) EnsureAvailability(numStateServers int, cons constraints.Value, series string, remove ...string) (StateServersChanges, error) {
 	if numStateServers < 0 || (numStateServers != 0 && numStateServers%2 != 1) {
 		return StateServersChanges{}, fmt.Errorf("number of state servers must be odd and non-negative")
 	}
@@ -504,11 +512,19 @@ func (st *State) EnsureAvailability(numStateServers int, cons constraints.Value,
 		return StateServersChanges{}, fmt.Errorf("state server count is too large (allowed %d)", replicaset.MaxPeers)
 	}
 	var change StateServersChanges
+	var retired []*Machine
 	buildTxn := func(attempt int) ([]txn.Op, error) {
+		retired = nil
 		currentInfo, err := st.StateServerInfo()
 		if err != nil {
 			return nil, err
 		}
+		stateServerIds := set.NewStrings(currentInfo.MachineIds...)
+		for _, id := range remove {
+			if !stateServerIds.Contains(id) {
+				return nil, fmt.Errorf("machine %s is not a state server", id)
+			}
+		}
 		desiredStateServerCount := numStateServers
 		if desiredStateServerCount == 0 {
 			desiredStateServerCount = len(currentInfo.VotingMachineIds)
@@ -516,11 +532,8 @@ func (st *State) EnsureAvailability(numStateServers int, cons constraints.Value,
 				desiredStateServerCount = 3
 			}
 		}
-		if len(currentInfo.VotingMachineIds) > desiredStateServerCount {
-			return nil, fmt.Errorf("cannot reduce state server count")
-		}
 
-		intent, err := st.ensureAvailabilityIntentions(currentInfo)
+		intent, err := st.ensureAvailabilityIntentions(currentInfo, set.NewStrings(remove...))
 		if err != nil {
 			return nil, err
 		}
@@ -530,7 +543,15 @@ func (st *State) EnsureAvailability(numStateServers int, cons constraints.Value,
 				voteCount++
 			}
 		}
-		if voteCount == desiredStateServerCount && len(intent.remove) == 0 {
+		if voteCount > desiredStateServerCount {
+			intent.reduceVotes(voteCount - desiredStateServerCount)
+			voteCount = desiredStateServerCount
+		}
+		if voteCount == desiredStateServerCount &&
+			len(intent.remove) == 0 &&
+			len(intent.demote) == 0 &&
+			len(intent.retire) == 0 &&
+			len(intent.markRetiring) == 0 {
 			return nil, jujutxn.ErrNoOperations
 		}
 		// Promote as many machines as we can to fulfil the shortfall.
@@ -543,12 +564,16 @@ func (st *State) EnsureAvailability(numStateServers int, cons constraints.Value,
 
 		var ops []txn.Op
 		ops, change, err = st.ensureAvailabilityIntentionOps(intent, currentInfo, cons, series)
+		retired = intent.retire
 		return ops, err
 	}
 	if err := st.run(buildTxn); err != nil {
 		err = errors.Annotate(err, "failed to create new state server machines")
 		return StateServersChanges{}, err
 	}
+	for _, m := range retired {
+		st.destroyRetiredStateServer(m.doc.Id)
+	}
 	return change, nil
 }
 
@@ -602,6 +627,23 @@ func (st *State) ensureAvailabilityIntentionOps(
 		change.Removed = append(change.Removed, m.doc.Id)
 
 	}
+	for _, m := range intent.retire {
+		ops = append(ops, txn.Op{
+			C:      machinesC,
+			Id:     m.doc.Id,
+			Assert: bson.D{{"hasvote", false}},
+		})
+		ops = append(ops, retireStateServerOps(m.doc.Id)...)
+		change.Removed = append(change.Removed, m.doc.Id)
+	}
+	for _, m := range intent.markRetiring {
+		ops = append(ops, txn.Op{
+			C:      machinesC,
+			Id:     m.doc.Id,
+			Assert: bson.D{{"jobs", JobManageEnviron}},
+			Update: bson.D{{"$set", bson.D{{"retiring", true}}}},
+		})
+	}
 
 	for _, m := range intent.maintain {
 		tag, err := names.ParseTag(m.Tag().String())
@@ -633,6 +675,53 @@ var stateServerAvailable = func(m *Machine) (bool, error) {
 type ensureAvailabilityIntent struct {
 	newCount                          int
 	promote, maintain, demote, remove []*Machine
+
+	// retire holds the state servers, being removed, that
+	// have no vote; they lose their JobManageEnviron job at
+	// once, and are destroyed.
+	retire []*Machine
+
+	// markRetiring holds the state servers, being removed,
+	// that have a vote; they are retired when the worker that
+	// maintains the replica set removes it.
+	markRetiring []*Machine
+}
+
+// retireMachine records the intention to remove the given state
+// server machine.
+func (intent *ensureAvailabilityIntent) retireMachine(m *Machine) {
+	switch {
+	case !m.HasVote():
+		intent.retire = append(intent.retire, m)
+		return
+	case m.WantsVote():
+		intent.demote = append(intent.demote, m)
+	default:
+		// The machine has already been demoted, but still
+		// has a vote, so keep it around for now.
+		intent.maintain = append(intent.maintain, m)
+	}
+	if !m.IsRetiring() {
+		intent.markRetiring = append(intent.markRetiring, m)
+	}
+}
+
+// reduceVotes removes n of the maintained voting state servers,
+// preferring the most recently added ones that have not yet been
+// given a vote.
+func (intent *ensureAvailabilityIntent) reduceVotes(n int) {
+	for _, hasVote := range []bool{false, true} {
+		for i := len(intent.maintain) - 1; i >= 0 && n > 0; i-- {
+			m := intent.maintain[i]
+			if !m.WantsVote() || m.HasVote() != hasVote {
+				continue
+			}
+			intent.maintain = append(intent.maintain[:i], intent.maintain[i+1:]...)
+			intent.retireMachine(m)
+			n--
+		}
+	}
+	logger.Infof("reduced votes: maintain %v; demote %v; retire %v", intent.maintain, intent.demote, intent.retire)
 }
 
 // ensureAvailabilityIntentions returns what we would like
@@ -641,13 +730,21 @@ type ensureAvailabilityIntent struct {
 //   demoting unavailable, voting machines;
 //   removing unavailable, non-voting, non-vote-holding machines;
 //   gathering available, non-voting machines that may be promoted;
-func (st *State) ensureAvailabilityIntentions(info *StateServerInfo) (*ensureAvailabilityIntent, error) {
+//
+// The machines with the given ids, and those that are already
+// retiring, are removed.
+func (st *State) ensureAvailabilityIntentions(info *StateServerInfo, remove set.Strings) (*ensureAvailabilityIntent, error) {
 	var intent ensureAvailabilityIntent
 	for _, mid := range info.MachineIds {
 		m, err := st.Machine(mid)
 		if err != nil {
 			return nil, err
 		}
+		if remove.Contains(mid) || m.IsRetiring() {
+			logger.Infof("machine %q is being removed, wants vote %v, has vote %v", m, m.WantsVote(), m.HasVote())
+			intent.retireMachine(m)
+			continue
+		}
 		available, err := stateServerAvailable(m)
 		if err != nil {
 			return nil, err
@@ -677,7 +774,7 @@ func (st *State) ensureAvailabilityIntentions(info *StateServerInfo) (*ensureAva
 			intent.remove = append(intent.remove, m)
 		}
 	}
-	logger.Infof("initial intentions: promote %v; maintain %v; demote %v; remove %v", intent.promote, intent.maintain, intent.demote, intent.remove)
+	logger.Infof("initial intentions: promote %v; maintain %v; demote %v; remove %v; retire %v", intent.promote, intent.maintain, intent.demote, intent.remove, intent.retire)
 	return &intent, nil
 }
 
@@ -722,3 +819,39 @@ func removeStateServerOps(m *Machine) []txn.Op {
 		Update: bson.D{{"$pull", bson.D{{"machineids", m.doc.Id}}}},
 	}}
 }
+
+// retireStateServerOps returns the operations that remove the
+// JobManageEnviron job of the state server machine with the given id,
+// and remove it from the state servers. The caller must ensure that
+// the machine does not have a vote.
+func retireStateServerOps(id string) []txn.Op {
+	return []txn.Op{{
+		C:  machinesC,
+		Id: id,
+		Update: bson.D{
+			{"$pull", bson.D{{"jobs", JobManageEnviron}}},
+			{"$set", bson.D{{"novote", false}, {"retiring", false}}},
+		},
+	}, {
+		C:  stateServersC,
+		Id: environGlobalKey,
+		Update: bson.D{{"$pull", bson.D{
+			{"machineids", id},
+			{"votingmachineids", id},
+		}}},
+	}}
+}
+
+// destroyRetiredStateServer destroys the machine with the given id,
+// which is no longer a state server. A machine that cannot be
+// destroyed, because it hosts units or containers, is left in
+// place as an ordinary machine.
+func (st *State) destroyRetiredStateServer(id string) {
+	m, err := st.Machine(id)
+	if err == nil {
+		err = m.Destroy()
+	}
+	if err != nil {
+		logger.Warningf("cannot destroy retired state server machine %s: %v", id, err)
+	}
+}
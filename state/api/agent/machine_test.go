@@ -20,7 +20,9 @@ import (
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/params"
 	apiserveragent "github.com/juju/juju/state/apiserver/agent"
+	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
 )
 
 func TestAll(t *stdtesting.T) {
@@ -79,6 +81,52 @@ func (s *servingInfoSuite) TestIsMasterPermission(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "permission denied")
 }
 
+func (s *servingInfoSuite) TestWatchStateServingInfo(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobManageEnviron)
+
+	w, err := st.Agent().WatchStateServingInfo()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+	wc.AssertOneChange()
+
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	info.Cert = "Some other cert"
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
+
+func (s *servingInfoSuite) TestWatchStateServingInfoPermission(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c)
+
+	_, err := st.Agent().WatchStateServingInfo()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *servingInfoSuite) TestCACert(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c)
+
+	caCert, err := st.Agent().CACert()
+	c.Assert(err, gc.IsNil)
+	c.Assert(caCert, gc.Equals, coretesting.CACert)
+
+	w, err := st.Agent().WatchCACert()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+	wc.AssertOneChange()
+
+	bundle := coretesting.CACert + coretesting.CACert
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{"ca-cert": bundle}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+	caCert, err = st.Agent().CACert()
+	c.Assert(err, gc.IsNil)
+	c.Assert(caCert, gc.Equals, bundle)
+}
+
 type machineSuite struct {
 	testing.JujuConnSuite
 	machine *state.Machine
@@ -140,6 +188,49 @@ func (s *machineSuite) TestEntitySetPassword(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsUnauthorized)
 }
 
+func (s *machineSuite) TestPasswordRotation(c *gc.C) {
+	required, err := s.st.Agent().PasswordRotationRequired()
+	c.Assert(err, gc.IsNil)
+	c.Assert(required, gc.Equals, false)
+
+	w, err := s.st.Agent().WatchPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+	wc.AssertOneChange()
+
+	err = s.machine.RequestPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+	required, err = s.st.Agent().PasswordRotationRequired()
+	c.Assert(err, gc.IsNil)
+	c.Assert(required, gc.Equals, true)
+}
+
+func (s *machineSuite) TestUpgradeProgress(c *gc.C) {
+	progress, err := s.st.Agent().UpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(progress.Steps, gc.HasLen, 0)
+
+	steps := []params.UpgradeStep{{
+		Target:      "stateServer",
+		Description: "step 1",
+		Status:      params.UpgradeStepDone,
+	}}
+	err = s.st.Agent().SetUpgradeProgress(params.UpgradeProgress{
+		ToVersion: version.MustParse("1.21.0"),
+		Steps:     steps,
+		Completed: true,
+	})
+	c.Assert(err, gc.IsNil)
+	progress, err = s.st.Agent().UpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(progress.Agent, gc.Equals, s.machine.Tag().String())
+	c.Assert(progress.ToVersion, gc.Equals, version.MustParse("1.21.0"))
+	c.Assert(progress.Steps, gc.DeepEquals, steps)
+	c.Assert(progress.Completed, jc.IsTrue)
+}
+
 func tryOpenState(info *state.Info) error {
 	st, err := state.Open(info, mongo.DialOpts{}, environs.NewStatePolicy())
 	if err == nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state/api/base"
 	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
 )
 
 // State provides access to an agent's view of the state.
@@ -48,6 +49,74 @@ func (st *State) StateServingInfo() (params.StateServingInfo, error) {
 	return results, err
 }
 
+// WatchStateServingInfo returns a watcher that notifies when the
+// state serving information, including the state server certificate,
+// changes. This call will return an error if the connected agent is
+// not a machine agent with environment-manager privileges.
+func (st *State) WatchStateServingInfo() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := st.caller.Call("Agent", "", "WatchStateServingInfo", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return watcher.NewNotifyWatcher(st.caller, result), nil
+}
+
+// CACert returns the CA certificates that agents should trust,
+// in PEM format.
+func (st *State) CACert() (string, error) {
+	var result params.BytesResult
+	err := st.caller.Call("Agent", "", "CACert", nil, &result)
+	if err != nil {
+		return "", err
+	}
+	return string(result.Result), nil
+}
+
+// WatchCACert returns a watcher that notifies when the CA
+// certificates that agents should trust may have changed.
+func (st *State) WatchCACert() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := st.caller.Call("Agent", "", "WatchCACert", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return watcher.NewNotifyWatcher(st.caller, result), nil
+}
+
+// PasswordRotationRequired returns whether the connected agent
+// has been asked to change its password.
+func (st *State) PasswordRotationRequired() (bool, error) {
+	var result params.BoolResult
+	err := st.caller.Call("Agent", "", "PasswordRotationRequired", nil, &result)
+	return result.Result, err
+}
+
+// WatchPasswordRotation returns a watcher that notifies when the
+// connected agent may have been asked to change its password.
+func (st *State) WatchPasswordRotation() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := st.caller.Call("Agent", "", "WatchPasswordRotation", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return watcher.NewNotifyWatcher(st.caller, result), nil
+}
+
+// UpgradeProgress returns the recorded progress of the connected
+// machine agent's upgrade.
+func (st *State) UpgradeProgress() (params.UpgradeProgress, error) {
+	var result params.UpgradeProgress
+	err := st.caller.Call("Agent", "", "UpgradeProgress", nil, &result)
+	return result, err
+}
+
+// SetUpgradeProgress records the progress of the connected machine
+// agent's upgrade.
+func (st *State) SetUpgradeProgress(progress params.UpgradeProgress) error {
+	return st.caller.Call("Agent", "", "SetUpgradeProgress", progress, nil)
+}
+
 // IsMaster reports whether the connected machine
 // agent lives at the same network address as the primary
 // mongo server for the replica set.
@@ -126,12 +126,10 @@ func Open(info *Info, opts DialOpts) (*State, error) {
 	if len(info.Addrs) == 0 {
 		return nil, fmt.Errorf("no API addresses to connect to")
 	}
-	pool := x509.NewCertPool()
-	xcert, err := cert.ParseCert(info.CACert)
+	pool, err := cert.NewCertPool(info.CACert)
 	if err != nil {
 		return nil, err
 	}
-	pool.AddCert(xcert)
 
 	var environUUID string
 	if info.EnvironTag != "" {
@@ -19,6 +19,7 @@ import (
 	"github.com/juju/charm"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/names"
 	"github.com/juju/utils"
 
 	"github.com/juju/juju/constraints"
@@ -92,6 +93,17 @@ type ServiceStatus struct {
 	CanUpgradeTo  string
 	SubordinateTo []string
 	Units         map[string]UnitStatus
+
+	// CharmUpgrade holds details of a rolling charm upgrade in
+	// progress, if any.
+	CharmUpgrade *CharmUpgradeStatus
+}
+
+// CharmUpgradeStatus holds status info about a rolling charm upgrade.
+type CharmUpgradeStatus struct {
+	BatchSize int
+	Halted    bool
+	Message   string
 }
 
 // UnitStatus holds status info about a unit.
@@ -110,6 +122,24 @@ type UnitStatus struct {
 	PublicAddress string
 	Charm         string
 	Subordinates  map[string]UnitStatus
+
+	// CharmUpgrade holds the unit's progress in a rolling charm
+	// upgrade of its service, if any.
+	CharmUpgrade string
+
+	// HookLockWait holds details of the machine's hook execution
+	// lock, if the unit is waiting for it.
+	HookLockWait *HookLockWaitStatus
+}
+
+// HookLockWaitStatus holds status info about a unit waiting for the
+// hook execution lock on its machine.
+type HookLockWaitStatus struct {
+	Holder     string
+	HolderHook string
+	Hook       string
+	Queued     []string
+	Since      time.Time
 }
 
 // RelationStatus holds status info about a relation.
@@ -148,6 +178,7 @@ type Status struct {
 	Services        map[string]ServiceStatus
 	Networks        map[string]NetworkStatus
 	Relations       []RelationStatus
+	Warnings        []string
 }
 
 // Status returns the status of the juju environment.
@@ -356,6 +387,41 @@ func (c *Client) ServiceDeployWithNetworks(charmURL string, serviceName string,
 	return c.st.Call("Client", "", "ServiceDeployWithNetworks", params, nil)
 }
 
+// ServiceDeployWithBindings works exactly like ServiceDeployWithNetworks,
+// but also binds relation endpoints of the service to networks. The
+// keys of bindings are endpoint names and the values network tags.
+func (c *Client) ServiceDeployWithBindings(charmURL string, serviceName string, numUnits int, configYAML string, cons constraints.Value, toMachineSpec string, networks []string, bindings map[string]string) error {
+	params := params.ServiceDeploy{
+		ServiceName:   serviceName,
+		CharmUrl:      charmURL,
+		NumUnits:      numUnits,
+		ConfigYAML:    configYAML,
+		Constraints:   cons,
+		ToMachineSpec: toMachineSpec,
+		Networks:      networks,
+		Bindings:      bindings,
+	}
+	return c.st.Call("Client", "", "ServiceDeployWithBindings", params, nil)
+}
+
+// ServiceDeployWithStorage works exactly like ServiceDeployWithBindings,
+// but also requests block storage volumes for each unit of the service.
+// The keys of storage are storage names.
+func (c *Client) ServiceDeployWithStorage(charmURL string, serviceName string, numUnits int, configYAML string, cons constraints.Value, toMachineSpec string, networks []string, bindings map[string]string, storage map[string]params.StorageDirective) error {
+	params := params.ServiceDeploy{
+		ServiceName:   serviceName,
+		CharmUrl:      charmURL,
+		NumUnits:      numUnits,
+		ConfigYAML:    configYAML,
+		Constraints:   cons,
+		ToMachineSpec: toMachineSpec,
+		Networks:      networks,
+		Bindings:      bindings,
+		Storage:       storage,
+	}
+	return c.st.Call("Client", "", "ServiceDeployWithStorage", params, nil)
+}
+
 // ServiceDeploy obtains the charm, either locally or from the charm store,
 // and deploys it.
 func (c *Client) ServiceDeploy(charmURL string, serviceName string, numUnits int, configYAML string, cons constraints.Value, toMachineSpec string) error {
@@ -387,6 +453,27 @@ func (c *Client) ServiceSetCharm(serviceName string, charmUrl string, force bool
 	return c.call("ServiceSetCharm", args, nil)
 }
 
+// ServiceSetCharmRolling sets the charm for a given service, like
+// ServiceSetCharm, but existing units are upgraded at most batchSize
+// at a time. Each batch is started once the previous batch has
+// upgraded successfully.
+func (c *Client) ServiceSetCharmRolling(serviceName string, charmUrl string, force bool, batchSize int) error {
+	args := params.ServiceSetCharmRolling{
+		ServiceName: serviceName,
+		CharmUrl:    charmUrl,
+		Force:       force,
+		BatchSize:   batchSize,
+	}
+	return c.call("ServiceSetCharmRolling", args, nil)
+}
+
+// ServiceResumeCharmUpgrade resumes a rolling charm upgrade of the
+// given service that was halted because a unit failed to upgrade.
+func (c *Client) ServiceResumeCharmUpgrade(serviceName string) error {
+	args := params.ServiceResumeCharmUpgrade{ServiceName: serviceName}
+	return c.call("ServiceResumeCharmUpgrade", args, nil)
+}
+
 // ServiceGetCharmURL returns the charm URL the given service is
 // running at present.
 func (c *Client) ServiceGetCharmURL(serviceName string) (*charm.URL, error) {
@@ -585,6 +672,135 @@ func (c *Client) Run(run params.RunParams) ([]params.RunResult, error) {
 	return results.Results, err
 }
 
+// AddSchedule adds a schedule that runs the given command on the
+// target unit, or on every unit of the target service, at the times
+// described by the cron-style spec. It returns the id of the new
+// schedule.
+func (c *Client) AddSchedule(target, spec, command string) (string, error) {
+	var result params.AddScheduleResult
+	args := params.AddSchedule{Target: target, Spec: spec, Command: command}
+	if err := c.call("AddSchedule", args, &result); err != nil {
+		return "", err
+	}
+	return result.Id, nil
+}
+
+// ListSchedules returns all the schedules in the environment.
+func (c *Client) ListSchedules() ([]params.ScheduleInfo, error) {
+	var result params.ScheduleInfos
+	err := c.call("ListSchedules", nil, &result)
+	return result.Schedules, err
+}
+
+// PauseSchedule stops the command of the schedule with the given id
+// being run until the schedule is resumed.
+func (c *Client) PauseSchedule(id string) error {
+	return c.call("PauseSchedule", params.ScheduleId{Id: id}, nil)
+}
+
+// ResumeSchedule resumes the paused schedule with the given id.
+func (c *Client) ResumeSchedule(id string) error {
+	return c.call("ResumeSchedule", params.ScheduleId{Id: id}, nil)
+}
+
+// RemoveSchedule removes the schedule with the given id.
+func (c *Client) RemoveSchedule(id string) error {
+	return c.call("RemoveSchedule", params.ScheduleId{Id: id}, nil)
+}
+
+// EnvironmentCost returns the instance type and estimated hourly
+// cost of each machine in the environment, and the share of that cost
+// attributed to each service.
+func (c *Client) EnvironmentCost() (params.EnvironmentCost, error) {
+	var result params.EnvironmentCost
+	err := c.call("EnvironmentCost", nil, &result)
+	return result, err
+}
+
+// AgentReport returns a report on the running agent of the machine
+// or unit with the given id or name, optionally including the stacks
+// of all its goroutines.
+func (c *Client) AgentReport(agent string, goroutines bool) (params.AgentReport, error) {
+	var result params.AgentReport
+	args := params.AgentReportArgs{Agent: agent, Goroutines: goroutines}
+	err := c.call("AgentReport", args, &result)
+	return result, err
+}
+
+// RotateCertificates replaces the CA certificates trusted by agents,
+// the state server certificate, or both. Empty fields are left
+// unchanged.
+func (c *Client) RotateCertificates(args params.RotateCertificatesArgs) error {
+	return c.call("RotateCertificates", args, nil)
+}
+
+// StateServers returns the state of each state server's member of
+// the mongo replica set, and the most recent decision made by the
+// worker that maintains the replica set.
+func (c *Client) StateServers() (params.StateServersStatus, error) {
+	var result params.StateServersStatus
+	err := c.call("StateServers", nil, &result)
+	return result, err
+}
+
+// StepDownPrimary asks the primary of the state servers' mongo
+// replica set to step down, so that another state server is elected
+// primary.
+func (c *Client) StepDownPrimary() error {
+	return c.call("StepDownPrimary", nil, nil)
+}
+
+// DemoteStateServer removes the vote of the state server machine
+// with the given id in the mongo replica set.
+func (c *Client) DemoteStateServer(machineId string) error {
+	args := params.DemoteStateServerArgs{MachineId: machineId}
+	return c.call("DemoteStateServer", args, nil)
+}
+
+// RotateAgentPasswords asks the agents of the given machines and
+// units, and of the units and containers on those machines, to change
+// their passwords. It returns the tags of the agents asked.
+func (c *Client) RotateAgentPasswords(agents ...string) ([]string, error) {
+	var result params.RotateAgentPasswordsResults
+	args := params.RotateAgentPasswordsArgs{Agents: agents}
+	if err := c.call("RotateAgentPasswords", args, &result); err != nil {
+		return nil, err
+	}
+	return result.Agents, nil
+}
+
+// UpgradeProgress returns the progress of each machine agent's
+// upgrade to the environment's agent version.
+func (c *Client) UpgradeProgress() ([]params.UpgradeProgress, error) {
+	var result params.UpgradeProgressResults
+	if err := c.call("UpgradeProgress", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Agents, nil
+}
+
+// SkipUpgradeStep asks the agent of the machine with the given id to
+// skip its failed upgrade step. It returns the step's description.
+func (c *Client) SkipUpgradeStep(machineId string) (string, error) {
+	var result params.SkipUpgradeStepResult
+	args := params.SkipUpgradeStepArgs{Agent: machineId}
+	if err := c.call("SkipUpgradeStep", args, &result); err != nil {
+		return "", err
+	}
+	return result.Step, nil
+}
+
+// AbortUpgrade aborts the upgrade under way, if its database upgrade
+// steps have not been committed, and returns the agent version the
+// environment reverted to.
+func (c *Client) AbortUpgrade() (version.Number, error) {
+	var result params.AbortUpgradeResult
+	if err := c.call("AbortUpgrade", nil, &result); err != nil {
+		return version.Number{}, err
+	}
+	return result.Version, nil
+}
+
 // DestroyEnvironment puts the environment into a "dying" state,
 // and removes all non-manager machine instances. DestroyEnvironment
 // will fail if there are any manually-provisioned non-manager machines
@@ -770,15 +986,21 @@ func (c *Client) APIHostPorts() ([][]network.HostPort, error) {
 	return result.Servers, nil
 }
 
-// EnsureAvailability ensures the availability of Juju state servers.
-func (c *Client) EnsureAvailability(numStateServers int, cons constraints.Value, series string) (params.StateServersChanges, error) {
+// EnsureAvailability ensures the availability of Juju state servers,
+// removing the state server machines with the given ids.
+func (c *Client) EnsureAvailability(numStateServers int, cons constraints.Value, series string, remove ...string) (params.StateServersChanges, error) {
 	var results params.StateServersChangeResults
+	var removeTags []string
+	for _, id := range remove {
+		removeTags = append(removeTags, names.NewMachineTag(id).String())
+	}
 	arg := params.StateServersSpecs{
 		Specs: []params.StateServersSpec{{
 			EnvironTag:      c.st.EnvironTag(),
 			NumStateServers: numStateServers,
 			Constraints:     cons,
 			Series:          series,
+			RemoveMachines:  removeTags,
 		}}}
 	err := c.call("EnsureAvailability", arg, &results)
 	if err != nil {
@@ -0,0 +1,61 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/juju/charm"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/version"
+)
+
+// ToolsURL returns the URL from which the tools with the given version
+// can be downloaded through the API server at the given address. The
+// API server fetches the tools from the environment's tools source and
+// caches them, so agents should prefer it to the tools' storage URL.
+func ToolsURL(addr string, vers version.Binary) string {
+	return fmt.Sprintf("https://%s/tools?binaryVersion=%s", addr, vers)
+}
+
+// CharmArchiveURL returns the URL from which the archive of the charm
+// with the given URL can be downloaded through the API server at the
+// given address.
+func CharmArchiveURL(addr string, curl *charm.URL) string {
+	return fmt.Sprintf("https://%s/charms?url=%s&file=*", addr, url.QueryEscape(curl.String()))
+}
+
+// AuthHeader returns an HTTP header that authenticates requests to the
+// API server's HTTP endpoints as the entity with the given tag and
+// password.
+func AuthHeader(tag, password string) http.Header {
+	auth := base64.StdEncoding.EncodeToString([]byte(tag + ":" + password))
+	header := make(http.Header)
+	header.Set("Authorization", "Basic "+auth)
+	return header
+}
+
+// NewHTTPClient returns an HTTP client for downloading from the API
+// server described by info. As with Open, the server's certificate is
+// verified against info.CACert, so credentials sent with AuthHeader are
+// only ever sent to a genuine API server.
+func NewHTTPClient(info *Info) (*http.Client, error) {
+	pool, err := cert.NewCertPool(info.CACert)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse API server CA certificate: %v", err)
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				ServerName: "anything",
+			},
+		},
+	}, nil
+}
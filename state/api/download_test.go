@@ -0,0 +1,79 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juju/charm"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cert"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state/api"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type downloadSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&downloadSuite{})
+
+func (s *downloadSuite) TestToolsURL(c *gc.C) {
+	vers := version.MustParseBinary("1.2.3-trusty-amd64")
+	url := api.ToolsURL("0.1.2.3:17070", vers)
+	c.Assert(url, gc.Equals, "https://0.1.2.3:17070/tools?binaryVersion=1.2.3-trusty-amd64")
+}
+
+func (s *downloadSuite) TestCharmArchiveURL(c *gc.C) {
+	curl := charm.MustParseURL("cs:quantal/wordpress-3")
+	url := api.CharmArchiveURL("0.1.2.3:17070", curl)
+	c.Assert(url, gc.Equals, "https://0.1.2.3:17070/charms?url=cs%3Aquantal%2Fwordpress-3&file=*")
+}
+
+func (s *downloadSuite) TestAuthHeader(c *gc.C) {
+	req, err := http.NewRequest("GET", "https://0.1.2.3/tools", nil)
+	c.Assert(err, gc.IsNil)
+	req.SetBasicAuth("machine-0", "foo")
+	header := api.AuthHeader("machine-0", "foo")
+	c.Assert(header.Get("Authorization"), gc.Equals, req.Header.Get("Authorization"))
+}
+
+type downloadClientSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&downloadClientSuite{})
+
+func (s *downloadClientSuite) TestNewHTTPClientVerifiesServer(c *gc.C) {
+	info := s.APIInfo(c)
+	client, err := api.NewHTTPClient(info)
+	c.Assert(err, gc.IsNil)
+	req, err := http.NewRequest("GET", api.ToolsURL(info.Addrs[0], version.Current), nil)
+	c.Assert(err, gc.IsNil)
+	req.Header = api.AuthHeader(info.Tag, info.Password)
+	resp, err := client.Do(req)
+	c.Assert(err, gc.IsNil)
+	resp.Body.Close()
+
+	// A server whose certificate is not signed by the CA is refused
+	// before any credentials are sent.
+	otherCACert, _, err := cert.NewCA("other", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	info.CACert = otherCACert
+	client, err = api.NewHTTPClient(info)
+	c.Assert(err, gc.IsNil)
+	_, err = client.Do(req)
+	c.Assert(err, gc.ErrorMatches, ".*certificate signed by unknown authority")
+}
+
+func (s *downloadClientSuite) TestNewHTTPClientInvalidCACert(c *gc.C) {
+	info := s.APIInfo(c)
+	info.CACert = "bad"
+	_, err := api.NewHTTPClient(info)
+	c.Assert(err, gc.ErrorMatches, "cannot parse API server CA certificate: .*")
+}
@@ -13,6 +13,10 @@ const (
 	Dead  Life = "dead"
 )
 
+// MaxRelationSettingsSize is the maximum size, in bytes, of a unit's
+// settings within a relation, measured as their JSON encoding.
+const MaxRelationSettingsSize = 64 * 1024
+
 // MachineJob values define responsibilities that machines may be
 // expected to fulfil.
 type MachineJob string
@@ -4,6 +4,8 @@
 package params
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/juju/utils/exec"
@@ -149,8 +151,23 @@ type BoolResults struct {
 	Results []BoolResult
 }
 
-// RelationSettings holds relation settings names and values.
-type RelationSettings map[string]string
+// RelationSettings holds relation settings names and values. Values
+// are usually strings, but charms may also store structured values
+// (maps, lists, numbers and booleans).
+type RelationSettings map[string]interface{}
+
+// CheckSize returns an error if the JSON encoding of the settings
+// exceeds MaxRelationSettingsSize.
+func (s RelationSettings) CheckSize() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("cannot encode relation settings: %v", err)
+	}
+	if len(data) > MaxRelationSettingsSize {
+		return fmt.Errorf("relation settings too large: %d bytes exceeds limit of %d bytes", len(data), MaxRelationSettingsSize)
+	}
+	return nil
+}
 
 // RelationSettingsResult holds a relation settings map or an error.
 type RelationSettingsResult struct {
@@ -247,6 +264,42 @@ type RelationResults struct {
 	Results []RelationResult
 }
 
+// RelationNetwork describes the network a unit's relation endpoint
+// is bound to.
+type RelationNetwork struct {
+	// NetworkName is the juju-specific name of the network.
+	NetworkName string
+
+	// ProviderId is the provider-specific id of the network, if known.
+	ProviderId string
+
+	// CIDR holds the network's CIDR, if known.
+	CIDR string
+
+	// VLANTag is the network's VLAN tag, or 0 if it is not a VLAN.
+	VLANTag int
+
+	// InterfaceName is the name of the network interface on the
+	// unit's machine that is attached to the network, if known.
+	InterfaceName string
+
+	// Address is the unit's address on the network, if any.
+	Address string
+}
+
+// RelationNetworkResult holds the network a relation endpoint is
+// bound to, or an error.
+type RelationNetworkResult struct {
+	Error   *Error
+	Network RelationNetwork
+}
+
+// RelationNetworkResults holds the result of an API call that returns
+// the networks of multiple relation units.
+type RelationNetworkResults struct {
+	Results []RelationNetworkResult
+}
+
 // EntityPort holds an entity's tag, a protocol and a port.
 type EntityPort struct {
 	Tag      string
@@ -260,6 +313,93 @@ type EntitiesPorts struct {
 	Entities []EntityPort
 }
 
+// HookLockWait describes a unit waiting for the machine's hook
+// execution lock.
+type HookLockWait struct {
+	Tag        string
+	Holder     string
+	HolderHook string
+	Hook       string
+	Queued     []string
+	Since      time.Time
+}
+
+// HookLockWaits holds the parameters for making a SetHookLockWait
+// API call.
+type HookLockWaits struct {
+	Waits []HookLockWait
+}
+
+// UnitSchedule describes a schedule whose command should be run on a
+// unit.
+type UnitSchedule struct {
+	Id      string
+	Spec    string
+	Command string
+	Paused  bool
+}
+
+// UnitSchedulesResult holds the schedules that apply to a unit, or an
+// error.
+type UnitSchedulesResult struct {
+	Error     *Error
+	Schedules []UnitSchedule
+}
+
+// UnitSchedulesResults holds the results of a Schedules API call.
+type UnitSchedulesResults struct {
+	Results []UnitSchedulesResult
+}
+
+// ScheduleRun describes a run of a scheduled command on a unit.
+type ScheduleRun struct {
+	Tag        string
+	ScheduleId string
+	Time       time.Time
+	Code       int
+	Output     string
+	Error      string
+}
+
+// ScheduleRuns holds the parameters for making a SetScheduleRuns API
+// call.
+type ScheduleRuns struct {
+	Runs []ScheduleRun
+}
+
+// UnitVolume describes a block storage volume of a unit.
+type UnitVolume struct {
+	// Name holds the name of the unit's storage held by the volume.
+	Name string
+
+	// Pool holds the name of the storage pool the volume is created
+	// in.
+	Pool string
+
+	// Size holds the size of the volume in MiB.
+	Size uint64
+
+	// Status holds the status of the volume, such as "pending" or
+	// "attached", and StatusInfo the reason for any error.
+	Status     string
+	StatusInfo string
+
+	// Device holds the name of the block device through which the
+	// attached volume may be accessed.
+	Device string
+}
+
+// UnitVolumesResult holds the volumes of a unit, or an error.
+type UnitVolumesResult struct {
+	Error   *Error
+	Volumes []UnitVolume
+}
+
+// UnitVolumesResults holds the results of a Volumes API call.
+type UnitVolumesResults struct {
+	Results []UnitVolumesResult
+}
+
 // EntityCharmURL holds an entity's tag and a charm URL.
 type EntityCharmURL struct {
 	Tag      string
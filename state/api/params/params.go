@@ -158,6 +158,23 @@ type ServiceDeploy struct {
 	Constraints   constraints.Value
 	ToMachineSpec string
 	Networks      []string
+	// Bindings maps relation endpoint names to the tags of the
+	// networks they are bound to.
+	Bindings map[string]string
+	// Storage holds the block storage volumes required by each unit
+	// of the service, keyed by storage name.
+	Storage map[string]StorageDirective
+}
+
+// StorageDirective describes a block storage volume required by each
+// unit of a service.
+type StorageDirective struct {
+	// Pool holds the name of the storage pool the volume is created
+	// in. The only pool is "loop", supported by the local provider.
+	Pool string
+
+	// Size holds the size of the volume in MiB.
+	Size uint64
 }
 
 // ServiceUpdate holds the parameters for making the ServiceUpdate call.
@@ -178,6 +195,326 @@ type ServiceSetCharm struct {
 	Force       bool
 }
 
+// ServiceSetCharmRolling sets the charm for a given service, upgrading
+// existing units at most BatchSize at a time.
+type ServiceSetCharmRolling struct {
+	ServiceName string
+	CharmUrl    string
+	Force       bool
+	BatchSize   int
+}
+
+// ServiceResumeCharmUpgrade holds the parameters for resuming a halted
+// rolling charm upgrade.
+type ServiceResumeCharmUpgrade struct {
+	ServiceName string
+}
+
+// AddSchedule holds the parameters for adding a schedule that runs a
+// command periodically on a unit, or on every unit of a service.
+type AddSchedule struct {
+	// Target holds the name of a unit or service.
+	Target  string
+	Spec    string
+	Command string
+}
+
+// AddScheduleResult holds the result of an AddSchedule call.
+type AddScheduleResult struct {
+	Id string
+}
+
+// ScheduleId identifies a schedule.
+type ScheduleId struct {
+	Id string
+}
+
+// ScheduleRunInfo describes the most recent run of a scheduled
+// command on a unit.
+type ScheduleRunInfo struct {
+	Unit   string
+	Time   time.Time
+	Code   int
+	Output string
+	Error  string
+}
+
+// ScheduleInfo describes a schedule and its most recent runs.
+type ScheduleInfo struct {
+	Id      string
+	Target  string
+	Spec    string
+	Command string
+	Paused  bool
+	Runs    []ScheduleRunInfo
+}
+
+// ScheduleInfos holds the result of a ListSchedules call.
+type ScheduleInfos struct {
+	Schedules []ScheduleInfo
+}
+
+// MachineCost describes the instance used by a top level machine and
+// its estimated cost.
+type MachineCost struct {
+	Machine      string
+	InstanceId   instance.Id
+	InstanceType string
+	Hardware     string
+
+	// HourlyCost is the estimated cost of the machine's instance in
+	// US dollars per hour, or zero if it is not known.
+	HourlyCost float64
+
+	// Units holds the principal units on the machine and its
+	// containers, between which the cost is divided.
+	Units []string
+}
+
+// ServiceCost describes the estimated cost of a service, being its
+// share of the cost of the machines its units are on.
+type ServiceCost struct {
+	Service    string
+	HourlyCost float64
+}
+
+// EnvironmentCost holds the result of an EnvironmentCost call.
+type EnvironmentCost struct {
+	Machines   []MachineCost
+	Services   []ServiceCost
+	HourlyCost float64
+}
+
+// AgentReportArgs holds the arguments for making an AgentReport call.
+type AgentReportArgs struct {
+	// Agent holds the id of the machine or the name of the unit
+	// whose agent is reported on.
+	Agent string
+
+	// Goroutines specifies whether the stacks of all the agent's
+	// goroutines are included in the report.
+	Goroutines bool
+}
+
+// WorkerStatus describes a worker run by an agent.
+type WorkerStatus struct {
+	Id            string
+	State         string
+	Started       time.Time
+	Restarts      int
+	RestartAt     time.Time
+	LastError     string
+	LastErrorTime time.Time
+	Workers       []WorkerStatus
+}
+
+// AgentReport holds the result of an AgentReport call.
+type AgentReport struct {
+	Agent      string
+	Version    string
+	Started    time.Time
+	Config     map[string]string
+	Workers    []WorkerStatus
+	Goroutines string
+}
+
+// RotateCertificatesArgs holds the arguments for making a
+// RotateCertificates call.
+type RotateCertificatesArgs struct {
+	// CACert holds the PEM-formatted CA certificates that agents
+	// should trust. While the CA is being replaced, it holds both
+	// the old and the new CA certificates. The CA certificates are
+	// left unchanged if it is empty.
+	CACert string
+
+	// Cert and PrivateKey hold the new PEM-formatted state server
+	// certificate and key. The state server certificate is left
+	// unchanged if they are empty.
+	Cert       string
+	PrivateKey string
+}
+
+// StateServerMember describes a state server machine and its member
+// of the mongo replica set.
+type StateServerMember struct {
+	// MachineId holds the id of the state server machine. It is
+	// empty for a replica set member with no state server machine.
+	MachineId string
+
+	// Address holds the address of the replica set member. It is
+	// empty if the machine is not yet a member of the replica set.
+	Address string
+
+	// State holds the mongo state of the member, such as PRIMARY
+	// or SECONDARY.
+	State string
+
+	// Healthy holds whether the member is up.
+	Healthy bool
+
+	// Message holds the most recent error or status message
+	// reported for the member.
+	Message string
+
+	// WantsVote holds whether the machine should vote in the
+	// replica set, and HasVote whether it is recorded as voting.
+	WantsVote bool
+	HasVote   bool
+
+	// Voting holds whether the member is configured to vote in
+	// the replica set.
+	Voting bool
+
+	// OptimeLag holds how far the member lags behind the primary
+	// in applying operations.
+	OptimeLag time.Duration
+}
+
+// PeerGroupDecision describes the most recent decision made by the
+// worker that maintains the mongo replica set.
+type PeerGroupDecision struct {
+	Time    time.Time
+	Changed bool
+	Reasons []string
+	Error   string
+}
+
+// StateServersStatus holds the result of a StateServers call.
+type StateServersStatus struct {
+	Members []StateServerMember
+
+	// Decision holds the peer group worker's most recent
+	// decision. It is nil if no decision has been made.
+	Decision *PeerGroupDecision
+}
+
+// DemoteStateServerArgs holds the arguments for making a
+// DemoteStateServer call.
+type DemoteStateServerArgs struct {
+	MachineId string
+}
+
+// RotateAgentPasswordsArgs holds the arguments for making a
+// RotateAgentPasswords call.
+type RotateAgentPasswordsArgs struct {
+	// Agents holds the ids of the machines and the names of the
+	// units whose agents should change their passwords. The agents
+	// of a machine's units and containers are included with it.
+	Agents []string
+}
+
+// RotateAgentPasswordsResults holds the result of a
+// RotateAgentPasswords call.
+type RotateAgentPasswordsResults struct {
+	// Agents holds the tags of the agents that were asked to
+	// change their passwords.
+	Agents []string
+}
+
+// UpgradeStepStatus describes the progress of an upgrade step.
+type UpgradeStepStatus string
+
+const (
+	// UpgradeStepPending is the status of a step that has not
+	// yet been run.
+	UpgradeStepPending UpgradeStepStatus = "pending"
+
+	// UpgradeStepRunning is the status of a step that is running.
+	UpgradeStepRunning UpgradeStepStatus = "running"
+
+	// UpgradeStepDone is the status of a step that has completed
+	// successfully.
+	UpgradeStepDone UpgradeStepStatus = "done"
+
+	// UpgradeStepFailed is the status of a step that has failed.
+	// The upgrade is retried until the step succeeds, or until
+	// it is skipped at the user's request.
+	UpgradeStepFailed UpgradeStepStatus = "failed"
+
+	// UpgradeStepSkipped is the status of a failed step that
+	// was skipped at the user's request.
+	UpgradeStepSkipped UpgradeStepStatus = "skipped"
+)
+
+// UpgradeStep holds the progress of an upgrade step run by an agent.
+type UpgradeStep struct {
+	Target      string
+	Description string
+	Status      UpgradeStepStatus
+	Error       string
+}
+
+// UpgradeProgress holds the progress of an agent's upgrade.
+type UpgradeProgress struct {
+	Agent     string
+	ToVersion version.Number
+	Steps     []UpgradeStep
+	Completed bool
+	Updated   time.Time
+
+	// Skip holds the descriptions of the failed steps that the
+	// user has asked the agent to skip.
+	Skip []string
+}
+
+// UpgradeProgressResults holds the result of an UpgradeProgress call.
+type UpgradeProgressResults struct {
+	Agents []UpgradeProgress
+}
+
+// SkipUpgradeStepArgs holds the arguments for making a
+// SkipUpgradeStep call.
+type SkipUpgradeStepArgs struct {
+	Agent string
+}
+
+// SkipUpgradeStepResult holds the result of a SkipUpgradeStep call.
+type SkipUpgradeStepResult struct {
+	// Step holds the description of the step that will be skipped.
+	Step string
+}
+
+// ToolsDownloadProgress holds the progress of an agent's download of
+// the tools it is upgrading to.
+type ToolsDownloadProgress struct {
+	Tag     string
+	Version version.Binary
+
+	// Received holds the number of bytes received so far.
+	Received int64
+
+	// Total holds the size of the tools, or -1 if it is not known.
+	Total int64
+
+	// Done reports that the download has finished, successfully or
+	// not, and that the progress reported for it should be cleared.
+	Done bool
+}
+
+// ToolsDownloadProgressArgs holds the arguments for making a
+// SetToolsDownloadProgress call.
+type ToolsDownloadProgressArgs struct {
+	Agents []ToolsDownloadProgress
+}
+
+// AbortedUpgradeResult holds the result of an AbortedUpgrade call.
+type AbortedUpgradeResult struct {
+	// Aborted holds whether the most recent upgrade of the
+	// environment was aborted.
+	Aborted bool
+
+	// From and To hold the versions that the aborted upgrade was
+	// from and to.
+	From version.Number
+	To   version.Number
+}
+
+// AbortUpgradeResult holds the result of an AbortUpgrade call.
+type AbortUpgradeResult struct {
+	// Version holds the agent version the environment reverted to.
+	Version version.Number
+}
+
 // ServiceExpose holds the parameters for making the ServiceExpose call.
 type ServiceExpose struct {
 	ServiceName string
@@ -751,6 +1088,9 @@ type StateServersSpec struct {
 	// Series is the series to associate with new state server machines.
 	// If this is empty, then the environment's default series is used.
 	Series string `json:series,omitempty`
+	// RemoveMachines holds the tags of state server machines
+	// to remove.
+	RemoveMachines []string
 }
 
 // StateServersSpecs contains all the arguments
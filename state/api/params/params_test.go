@@ -5,6 +5,7 @@ package params_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/juju/charm"
@@ -165,3 +166,19 @@ func (s *MarshalSuite) TestDeltaMarshalJSONUnknownEntity(c *gc.C) {
 	err := json.Unmarshal([]byte(`["qwan","change",{}]`), new(params.Delta))
 	c.Check(err, gc.ErrorMatches, `Unexpected entity name "qwan"`)
 }
+
+type RelationSettingsSuite struct{}
+
+var _ = gc.Suite(&RelationSettingsSuite{})
+
+func (s *RelationSettingsSuite) TestCheckSize(c *gc.C) {
+	settings := params.RelationSettings{
+		"key":    "value",
+		"nested": map[string]interface{}{"list": []interface{}{1, "two"}},
+	}
+	c.Assert(settings.CheckSize(), gc.IsNil)
+
+	settings["big"] = strings.Repeat("x", params.MaxRelationSettingsSize)
+	err := settings.CheckSize()
+	c.Assert(err, gc.ErrorMatches, `relation settings too large: \d+ bytes exceeds limit of 65536 bytes`)
+}
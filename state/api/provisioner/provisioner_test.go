@@ -545,6 +545,25 @@ func (s *provisionerSuite) TestContainerManagerConfigKVM(c *gc.C) {
 	})
 }
 
+func (s *provisionerSuite) TestContainerManagerConfigOvercommitRatio(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"container-overcommit-ratio": 1.5,
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	for _, ctype := range []instance.ContainerType{instance.LXC, instance.KVM} {
+		args := params.ContainerManagerConfigParams{Type: ctype}
+		result, err := s.provisioner.ContainerManagerConfig(args)
+		c.Assert(err, gc.IsNil)
+		c.Assert(result.ManagerConfig[container.ConfigOvercommitRatio], gc.Equals, "1.5")
+	}
+	args := params.ContainerManagerConfigParams{Type: instance.DOCKER}
+	result, err := s.provisioner.ContainerManagerConfig(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.ManagerConfig, gc.DeepEquals, map[string]string{
+		container.ConfigName: "juju",
+	})
+}
+
 func (s *provisionerSuite) TestContainerManagerConfigLXC(c *gc.C) {
 	args := params.ContainerManagerConfigParams{Type: instance.LXC}
 	st, err := state.Open(s.StateInfo(c), mongo.DialOpts{}, state.Policy(nil))
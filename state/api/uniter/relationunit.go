@@ -41,13 +41,61 @@ func (ru *RelationUnit) Endpoint() Endpoint {
 }
 
 // PrivateAddress returns the private address of the unit and whether
-// it is valid.
+// it is valid. If the unit's relation endpoint is bound to a network,
+// the address is the unit's address on that network.
 //
 // NOTE: This differs from state.RelationUnit.PrivateAddress() by
 // returning an error instead of a bool, because it needs to make an
 // API call.
 func (ru *RelationUnit) PrivateAddress() (string, error) {
-	return ru.unit.PrivateAddress()
+	var results params.StringResults
+	args := params.RelationUnits{
+		RelationUnits: []params.RelationUnit{{
+			Relation: ru.relation.tag.String(),
+			Unit:     ru.unit.tag.String(),
+		}},
+	}
+	err := ru.st.call("RelationPrivateAddress", args, &results)
+	if params.IsCodeNotImplemented(err) {
+		// Older servers do not support network bindings.
+		return ru.unit.PrivateAddress()
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(results.Results) != 1 {
+		return "", fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Result, nil
+}
+
+// Network returns details of the network the unit's relation endpoint
+// is bound to. An error satisfying params.IsCodeNotFound is returned
+// if the endpoint is not bound.
+func (ru *RelationUnit) Network() (params.RelationNetwork, error) {
+	var results params.RelationNetworkResults
+	args := params.RelationUnits{
+		RelationUnits: []params.RelationUnit{{
+			Relation: ru.relation.tag.String(),
+			Unit:     ru.unit.tag.String(),
+		}},
+	}
+	err := ru.st.call("RelationNetwork", args, &results)
+	if err != nil {
+		return params.RelationNetwork{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.RelationNetwork{}, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.RelationNetwork{}, result.Error
+	}
+	return result.Network, nil
 }
 
 // EnterScope ensures that the unit has entered its scope in the relation.
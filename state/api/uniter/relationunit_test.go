@@ -103,6 +103,14 @@ func (s *relationUnitSuite) TestPrivateAddress(c *gc.C) {
 	c.Assert(address, gc.Equals, "1.2.3.4")
 }
 
+func (s *relationUnitSuite) TestNetworkNotBound(c *gc.C) {
+	_, apiRelUnit := s.getRelationUnits(c)
+
+	_, err := apiRelUnit.Network()
+	c.Assert(err, gc.ErrorMatches, `network binding for endpoint "db" not found`)
+	c.Assert(params.IsCodeNotFound(err), jc.IsTrue)
+}
+
 func (s *relationUnitSuite) TestEnterScopeSuccessfully(c *gc.C) {
 	// NOTE: This test is not as exhaustive as the ones in state.
 	// Here, we just check the success case, while the two error
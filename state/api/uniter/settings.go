@@ -30,12 +30,8 @@ func newSettings(st *State, relationTag, unitTag string, settings params.Relatio
 	}
 }
 
-// Map returns all keys and values of the node.
-//
-// TODO(dimitern): This differes from state.Settings.Map() - it does
-// not return map[string]interface{}, but since all values are
-// expected to be strings anyway, we need to fix the uniter code
-// accordingly when migrating to the API.
+// Map returns all keys and values of the node. Values are usually
+// strings, but may also be structured values set by the charm.
 func (s *Settings) Map() params.RelationSettings {
 	settingsCopy := make(params.RelationSettings)
 	for k, v := range s.settings {
@@ -47,11 +43,8 @@ func (s *Settings) Map() params.RelationSettings {
 	return settingsCopy
 }
 
-// Set sets key to value.
-//
-// TODO(dimitern): value must be a string. Change the code that uses
-// this accordingly.
-func (s *Settings) Set(key, value string) {
+// Set sets key to value. The value must be encodable as JSON.
+func (s *Settings) Set(key string, value interface{}) {
 	s.settings[key] = value
 }
 
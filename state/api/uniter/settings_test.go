@@ -131,3 +131,33 @@ func (s *settingsSuite) TestWrite(c *gc.C) {
 		"other": "days",
 	})
 }
+
+func (s *settingsSuite) TestWriteStructuredValues(c *gc.C) {
+	wpRelUnit, err := s.stateRelation.Unit(s.wordpressUnit)
+	c.Assert(err, gc.IsNil)
+	err = wpRelUnit.EnterScope(map[string]interface{}{"some": "stuff"})
+	c.Assert(err, gc.IsNil)
+	s.assertInScope(c, wpRelUnit, true)
+
+	apiUnit, err := s.uniter.Unit(s.wordpressUnit.Tag().String())
+	c.Assert(err, gc.IsNil)
+	apiRelation, err := s.uniter.Relation(s.stateRelation.Tag().String())
+	c.Assert(err, gc.IsNil)
+	apiRelUnit, err := apiRelation.Unit(apiUnit)
+	c.Assert(err, gc.IsNil)
+	settings, err := apiRelUnit.Settings()
+	c.Assert(err, gc.IsNil)
+
+	settings.Set("hosts", []interface{}{"a", "b"})
+	settings.Set("tls", map[string]interface{}{"enabled": true, "port": 443})
+	err = settings.Write()
+	c.Assert(err, gc.IsNil)
+	settings, err = apiRelUnit.Settings()
+	c.Assert(err, gc.IsNil)
+	// Numbers are decoded from JSON as float64.
+	c.Assert(settings.Map(), gc.DeepEquals, params.RelationSettings{
+		"some":  "stuff",
+		"hosts": []interface{}{"a", "b"},
+		"tls":   map[string]interface{}{"enabled": true, "port": float64(443)},
+	})
+}
@@ -6,6 +6,7 @@ package uniter
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/names"
@@ -376,6 +377,43 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 	return result.OneError()
 }
 
+// SetHookLockWait records that the unit is waiting for the machine's
+// hook execution lock, held by holder while running holderHook. The
+// unit will run hook once it acquires the lock, followed by the queued
+// hooks.
+func (u *Unit) SetHookLockWait(holder, holderHook, hook string, queued []string, since time.Time) error {
+	var result params.ErrorResults
+	args := params.HookLockWaits{
+		Waits: []params.HookLockWait{{
+			Tag:        u.tag.String(),
+			Holder:     holder,
+			HolderHook: holderHook,
+			Hook:       hook,
+			Queued:     queued,
+			Since:      since,
+		}},
+	}
+	err := u.st.call("SetHookLockWait", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// ClearHookLockWait records that the unit is no longer waiting for the
+// machine's hook execution lock.
+func (u *Unit) ClearHookLockWait() error {
+	var result params.ErrorResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.call("ClearHookLockWait", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // ClearResolved removes any resolved setting on the unit.
 func (u *Unit) ClearResolved() error {
 	var result params.ErrorResults
@@ -413,6 +451,90 @@ func (u *Unit) WatchConfigSettings() (watcher.NotifyWatcher, error) {
 	return w, nil
 }
 
+// WatchSchedules returns a watcher for observing changes to the
+// schedules that apply to the unit.
+func (u *Unit) WatchSchedules() (watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.call("WatchSchedules", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewNotifyWatcher(u.st.caller, result)
+	return w, nil
+}
+
+// Schedules returns the schedules whose commands should be run on the
+// unit.
+func (u *Unit) Schedules() ([]params.UnitSchedule, error) {
+	var results params.UnitSchedulesResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.call("Schedules", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Schedules, nil
+}
+
+// Volumes returns the block storage volumes of the unit.
+func (u *Unit) Volumes() ([]params.UnitVolume, error) {
+	var results params.UnitVolumesResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.call("Volumes", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Volumes, nil
+}
+
+// SetScheduleRun records the result of running the command of the
+// schedule with the given id on the unit.
+func (u *Unit) SetScheduleRun(scheduleId string, started time.Time, code int, output, errMessage string) error {
+	var result params.ErrorResults
+	args := params.ScheduleRuns{
+		Runs: []params.ScheduleRun{{
+			Tag:        u.tag.String(),
+			ScheduleId: scheduleId,
+			Time:       started,
+			Code:       code,
+			Output:     output,
+			Error:      errMessage,
+		}},
+	}
+	err := u.st.call("SetScheduleRuns", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // JoinedRelations returns the tags of the relations the unit has joined.
 func (u *Unit) JoinedRelations() ([]string, error) {
 	var results params.StringsResults
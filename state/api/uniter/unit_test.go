@@ -5,6 +5,7 @@ package uniter_test
 
 import (
 	"sort"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/errors"
@@ -64,6 +65,71 @@ func (s *unitSuite) TestSetStatus(c *gc.C) {
 	c.Assert(data, gc.HasLen, 0)
 }
 
+func (s *unitSuite) TestSetClearHookLockWait(c *gc.C) {
+	since := time.Now().Add(-time.Minute)
+	queued := []string{"db-relation-joined", "db-relation-changed"}
+	err := s.apiUnit.SetHookLockWait("mysql/0", "config-changed", "install", queued, since)
+	c.Assert(err, gc.IsNil)
+
+	wait, err := s.wordpressUnit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait, gc.NotNil)
+	c.Assert(wait.Holder, gc.Equals, "mysql/0")
+	c.Assert(wait.HolderHook, gc.Equals, "config-changed")
+	c.Assert(wait.Hook, gc.Equals, "install")
+	c.Assert(wait.Queued, gc.DeepEquals, queued)
+	c.Assert(wait.Since.Unix(), gc.Equals, since.Unix())
+
+	err = s.apiUnit.ClearHookLockWait()
+	c.Assert(err, gc.IsNil)
+	wait, err = s.wordpressUnit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait, gc.IsNil)
+}
+
+func (s *unitSuite) TestVolumes(c *gc.C) {
+	volumes, err := s.apiUnit.Volumes()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.HasLen, 0)
+}
+
+func (s *unitSuite) TestSchedules(c *gc.C) {
+	w, err := s.apiUnit.WatchSchedules()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+	schedules, err := s.apiUnit.Schedules()
+	c.Assert(err, gc.IsNil)
+	c.Assert(schedules, gc.HasLen, 0)
+
+	schedule, err := s.State.AddSchedule("wordpress", "*/5 * * * *", "ls")
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+	schedules, err = s.apiUnit.Schedules()
+	c.Assert(err, gc.IsNil)
+	c.Assert(schedules, gc.DeepEquals, []params.UnitSchedule{{
+		Id:      schedule.Id(),
+		Spec:    "*/5 * * * *",
+		Command: "ls",
+	}})
+
+	started := time.Now()
+	err = s.apiUnit.SetScheduleRun(schedule.Id(), started, 3, "output", "")
+	c.Assert(err, gc.IsNil)
+	runs, err := schedule.Runs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(runs, gc.HasLen, 1)
+	c.Assert(runs[0].Code, gc.Equals, 3)
+	c.Assert(runs[0].Output, gc.Equals, "output")
+	c.Assert(runs[0].Time.Unix(), gc.Equals, started.Unix())
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *unitSuite) TestEnsureDead(c *gc.C) {
 	c.Assert(s.wordpressUnit.Life(), gc.Equals, state.Alive)
 
@@ -49,6 +49,54 @@ func (st *State) SetVersion(tag string, v version.Binary) error {
 	return results.OneError()
 }
 
+// SetToolsDownloadProgress reports the progress of the download of
+// the given tools by the entity with the given tag, which must be the
+// tag of the entity that the upgrader is running on behalf of. The
+// total is -1 if the size of the tools is not known.
+func (st *State) SetToolsDownloadProgress(tag string, v version.Binary, received, total int64) error {
+	var results params.ErrorResults
+	args := params.ToolsDownloadProgressArgs{
+		Agents: []params.ToolsDownloadProgress{{
+			Tag:      tag,
+			Version:  v,
+			Received: received,
+			Total:    total,
+		}},
+	}
+	err := st.call("SetToolsDownloadProgress", args, &results)
+	if err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// ClearToolsDownloadProgress clears the progress reported for the
+// download of the given tools by the entity with the given tag, once
+// the download has finished, successfully or not.
+func (st *State) ClearToolsDownloadProgress(tag string, v version.Binary) error {
+	var results params.ErrorResults
+	args := params.ToolsDownloadProgressArgs{
+		Agents: []params.ToolsDownloadProgress{{
+			Tag:     tag,
+			Version: v,
+			Done:    true,
+		}},
+	}
+	err := st.call("SetToolsDownloadProgress", args, &results)
+	if err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// AbortedUpgrade reports whether the most recent upgrade of the
+// environment was aborted, and if so, the versions it was between.
+func (st *State) AbortedUpgrade() (params.AbortedUpgradeResult, error) {
+	var result params.AbortedUpgradeResult
+	err := st.call("AbortedUpgrade", nil, &result)
+	return result, err
+}
+
 func (st *State) DesiredVersion(tag string) (version.Number, error) {
 	var results params.VersionResults
 	args := params.Entities{
@@ -81,6 +81,50 @@ func (s *machineUpgraderSuite) TestSetVersion(c *gc.C) {
 	c.Check(agentTools.Version, gc.Equals, cur)
 }
 
+func (s *machineUpgraderSuite) TestSetToolsDownloadProgress(c *gc.C) {
+	err := s.rawMachine.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	vers := version.MustParseBinary("5.4.3-precise-amd64")
+	err = s.st.SetToolsDownloadProgress(s.rawMachine.Tag().String(), vers, 512, 2048)
+	c.Assert(err, gc.IsNil)
+	status, info, _, err := s.rawMachine.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusStarted)
+	c.Assert(info, gc.Equals, "downloading tools 5.4.3-precise-amd64: 25% of 2048 bytes")
+
+	err = s.st.ClearToolsDownloadProgress(s.rawMachine.Tag().String(), vers)
+	c.Assert(err, gc.IsNil)
+	status, info, _, err = s.rawMachine.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusStarted)
+	c.Assert(info, gc.Equals, "")
+
+	err = s.st.SetToolsDownloadProgress("machine-42", vers, 512, 2048)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *machineUpgraderSuite) TestAbortedUpgrade(c *gc.C) {
+	result, err := s.st.AbortedUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Aborted, jc.IsFalse)
+
+	stateServer, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	from, to := version.MustParse("1.20.0"), version.MustParse("1.21.0")
+	_, err = s.State.EnsureUpgradeInfo(stateServer.Id(), from, to)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AbortUpgrade()
+	c.Assert(err, gc.IsNil)
+	result, err = s.st.AbortedUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.AbortedUpgradeResult{
+		Aborted: true,
+		From:    from,
+		To:      to,
+	})
+}
+
 func (s *machineUpgraderSuite) TestToolsWrongMachine(c *gc.C) {
 	tools, _, err := s.st.Tools("machine-42")
 	c.Assert(err, gc.ErrorMatches, "permission denied")
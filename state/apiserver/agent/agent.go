@@ -6,10 +6,13 @@
 package agent
 
 import (
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/watcher"
 )
 
 func init() {
@@ -20,8 +23,9 @@ func init() {
 type API struct {
 	*common.PasswordChanger
 
-	st   *state.State
-	auth common.Authorizer
+	st        *state.State
+	resources *common.Resources
+	auth      common.Authorizer
 }
 
 // NewAPI returns an object implementing an agent API
@@ -37,6 +41,7 @@ func NewAPI(st *state.State, resources *common.Resources, auth common.Authorizer
 	return &API{
 		PasswordChanger: common.NewPasswordChanger(st, getCanChange),
 		st:              st,
+		resources:       resources,
 		auth:            auth,
 	}, nil
 }
@@ -86,6 +91,127 @@ func (api *API) StateServingInfo() (result params.StateServingInfo, err error) {
 	return api.st.StateServingInfo()
 }
 
+// WatchStateServingInfo returns a NotifyWatcher that notifies when
+// the state serving information, including the state server
+// certificate, changes.
+func (api *API) WatchStateServingInfo() (params.NotifyWatchResult, error) {
+	if !api.auth.AuthEnvironManager() {
+		return params.NotifyWatchResult{}, common.ErrPerm
+	}
+	return api.watch(api.st.WatchStateServingInfo())
+}
+
+// CACert returns the CA certificates that agents should trust.
+// While the environment's CA is being replaced, this holds both
+// the old and the new CA certificates.
+func (api *API) CACert() (params.BytesResult, error) {
+	envConfig, err := api.st.EnvironConfig()
+	if err != nil {
+		return params.BytesResult{}, err
+	}
+	caCert, _ := envConfig.CACert()
+	return params.BytesResult{Result: []byte(caCert)}, nil
+}
+
+// WatchCACert returns a NotifyWatcher that notifies when the CA
+// certificates that agents should trust may have changed.
+func (api *API) WatchCACert() (params.NotifyWatchResult, error) {
+	return api.watch(api.st.WatchForEnvironConfigChanges())
+}
+
+// passwordRotator is implemented by entities whose agents can be
+// asked to change their passwords.
+type passwordRotator interface {
+	PasswordRotationRequested() bool
+	Watch() state.NotifyWatcher
+}
+
+func (api *API) passwordRotator() (passwordRotator, error) {
+	entity, err := api.st.FindEntity(api.auth.GetAuthTag().String())
+	if err != nil {
+		return nil, err
+	}
+	rotator, ok := entity.(passwordRotator)
+	if !ok {
+		return nil, common.NotSupportedError(entity.Tag().String(), "password rotation")
+	}
+	return rotator, nil
+}
+
+// PasswordRotationRequired returns whether the connected agent has
+// been asked to change its password.
+func (api *API) PasswordRotationRequired() (params.BoolResult, error) {
+	rotator, err := api.passwordRotator()
+	if err != nil {
+		return params.BoolResult{}, err
+	}
+	return params.BoolResult{Result: rotator.PasswordRotationRequested()}, nil
+}
+
+// WatchPasswordRotation returns a NotifyWatcher that notifies when
+// the connected agent may have been asked to change its password.
+func (api *API) WatchPasswordRotation() (params.NotifyWatchResult, error) {
+	rotator, err := api.passwordRotator()
+	if err != nil {
+		return params.NotifyWatchResult{}, err
+	}
+	return api.watch(rotator.Watch())
+}
+
+func (api *API) watch(w state.NotifyWatcher) (params.NotifyWatchResult, error) {
+	// Consume the initial event.
+	if _, ok := <-w.Changes(); ok {
+		return params.NotifyWatchResult{
+			NotifyWatcherId: api.resources.Register(w),
+		}, nil
+	}
+	return params.NotifyWatchResult{}, watcher.MustErr(w)
+}
+
+// UpgradeProgress returns the recorded progress of the connected
+// machine agent's upgrade, including the failed steps the user has
+// asked it to skip. It returns no progress if none has been recorded.
+func (api *API) UpgradeProgress() (params.UpgradeProgress, error) {
+	if !api.auth.AuthMachineAgent() {
+		return params.UpgradeProgress{}, common.ErrPerm
+	}
+	tag := api.auth.GetAuthTag().String()
+	progress, err := api.st.UpgradeProgress(tag)
+	if errors.IsNotFound(err) {
+		return params.UpgradeProgress{Agent: tag}, nil
+	} else if err != nil {
+		return params.UpgradeProgress{}, err
+	}
+	result := params.UpgradeProgress{
+		Agent:     progress.Agent,
+		ToVersion: progress.ToVersion,
+		Completed: progress.Completed,
+		Updated:   progress.Updated,
+		Skip:      progress.Skip,
+	}
+	for _, step := range progress.Steps {
+		result.Steps = append(result.Steps, params.UpgradeStep(step))
+	}
+	return result, nil
+}
+
+// SetUpgradeProgress records the progress of the connected machine
+// agent's upgrade.
+func (api *API) SetUpgradeProgress(args params.UpgradeProgress) error {
+	if !api.auth.AuthMachineAgent() {
+		return common.ErrPerm
+	}
+	progress := state.UpgradeProgress{
+		Agent:     api.auth.GetAuthTag().String(),
+		ToVersion: args.ToVersion,
+		Completed: args.Completed,
+	}
+	for _, step := range args.Steps {
+		progress.Steps = append(progress.Steps, state.UpgradeStep(step))
+	}
+	return api.st.SetUpgradeProgress(progress)
+}
+
 // MongoIsMaster is called by the IsMaster API call
 // instead of mongo.IsMaster. It exists so it can
 // be overridden by tests.
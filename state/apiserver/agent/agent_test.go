@@ -3,6 +3,8 @@ package agent_test
 import (
 	stdtesting "testing"
 
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/instance"
@@ -12,7 +14,9 @@ import (
 	"github.com/juju/juju/state/apiserver/agent"
 	"github.com/juju/juju/state/apiserver/common"
 	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
 )
 
 func TestPackage(t *stdtesting.T) {
@@ -195,3 +199,129 @@ func (s *agentSuite) TestShortSetPasswords(c *gc.C) {
 	c.Assert(results.Results[0].Error, gc.ErrorMatches,
 		"password is only 3 bytes long, and is not a valid Agent password")
 }
+
+func (s *agentSuite) TestCACert(c *gc.C) {
+	result, err := s.agent.CACert()
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(result.Result), gc.Equals, coretesting.CACert)
+}
+
+func (s *agentSuite) TestWatchCACert(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	result, err := s.agent.WatchCACert()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+
+	// Verify the resource was registered and stop when done
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event ("returned" in
+	// the Watch call)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+}
+
+func (s *agentSuite) TestWatchStateServingInfo(c *gc.C) {
+	_, err := s.agent.WatchStateServingInfo()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	auth := s.authorizer
+	auth.Tag = s.machine0.Tag()
+	auth.EnvironManager = true
+	api, err := agent.NewAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.IsNil)
+	result, err := api.WatchStateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	info.Cert = coretesting.ServerCert + "\n"
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
+
+func (s *agentSuite) TestPasswordRotationRequired(c *gc.C) {
+	result, err := s.agent.PasswordRotationRequired()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Result, gc.Equals, false)
+
+	err = s.machine1.RequestPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	result, err = s.agent.PasswordRotationRequired()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Result, gc.Equals, true)
+}
+
+func (s *agentSuite) TestWatchPasswordRotation(c *gc.C) {
+	result, err := s.agent.WatchPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err = s.machine1.RequestPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
+
+func (s *agentSuite) TestUpgradeProgress(c *gc.C) {
+	progress, err := s.agent.UpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(progress, gc.DeepEquals, params.UpgradeProgress{Agent: "machine-1"})
+
+	toVersion := version.MustParse("1.21.0")
+	steps := []params.UpgradeStep{{
+		Target:      "hostMachine",
+		Description: "step 1",
+		Status:      params.UpgradeStepFailed,
+		Error:       "boom",
+	}}
+	err = s.agent.SetUpgradeProgress(params.UpgradeProgress{
+		// The agent is ignored.
+		Agent:     "machine-0",
+		ToVersion: toVersion,
+		Steps:     steps,
+	})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.SkipFailedUpgradeStep("machine-1")
+	c.Assert(err, gc.IsNil)
+
+	progress, err = s.agent.UpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(progress.Agent, gc.Equals, "machine-1")
+	c.Assert(progress.ToVersion, gc.Equals, toVersion)
+	c.Assert(progress.Steps, gc.DeepEquals, steps)
+	c.Assert(progress.Skip, gc.DeepEquals, []string{"step 1"})
+
+	_, err = s.State.UpgradeProgress("machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *agentSuite) TestUpgradeProgressRefusesUnitAgent(c *gc.C) {
+	auth := s.authorizer
+	auth.MachineAgent = false
+	auth.UnitAgent = true
+	api, err := agent.NewAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.IsNil)
+	_, err = api.UpgradeProgress()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	err = api.SetUpgradeProgress(params.UpgradeProgress{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -215,7 +215,9 @@ func (srv *Server) run(lis net.Listener) {
 	// tests currently assert that errors come back as application/json and
 	// pat only does "text/plain" responses.
 	handleAll(mux, "/environment/:envuuid/tools",
-		&toolsHandler{httpHandler{state: srv.state}},
+		&toolsHandler{
+			httpHandler: httpHandler{state: srv.state},
+			dataDir:     srv.dataDir},
 	)
 	handleAll(mux, "/environment/:envuuid/api", http.HandlerFunc(srv.apiHandler))
 	// For backwards compatibility we register all the old paths
@@ -230,7 +232,9 @@ func (srv *Server) run(lis net.Listener) {
 			dataDir:     srv.dataDir},
 	)
 	handleAll(mux, "/tools",
-		&toolsHandler{httpHandler{state: srv.state}},
+		&toolsHandler{
+			httpHandler: httpHandler{state: srv.state},
+			dataDir:     srv.dataDir},
 	)
 	handleAll(mux, "/", http.HandlerFunc(srv.apiHandler))
 	// The error from http.Serve is not interesting.
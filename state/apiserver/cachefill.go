@@ -0,0 +1,69 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// cacheFiller serialises the filling of the API server's download
+// caches, so that concurrent requests for a file missing from a cache
+// fetch it only once.
+type cacheFiller struct {
+	mu    sync.Mutex
+	locks map[string]*cacheFillLock
+}
+
+// cacheFillLock is held while the file at a given path is checked for
+// and fetched.
+type cacheFillLock struct {
+	sync.Mutex
+	refs int
+}
+
+// cacheFills is shared by all the handlers, as the tools and charms
+// handlers are each registered under several paths.
+var cacheFills = newCacheFiller()
+
+func newCacheFiller() *cacheFiller {
+	return &cacheFiller{locks: make(map[string]*cacheFillLock)}
+}
+
+// fill ensures that the file at the given path exists, calling fetch
+// to create it if it does not. Only one call for a given path runs at
+// a time, so that callers that find the file missing while another is
+// fetching it wait for it, and then use it.
+func (f *cacheFiller) fill(path string, fetch func() error) error {
+	unlock := f.lock(path)
+	defer unlock()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fetch()
+	} else if err != nil {
+		return fmt.Errorf("cannot access the cache: %v", err)
+	}
+	return nil
+}
+
+// lock locks the given path, and returns a function that unlocks it.
+func (f *cacheFiller) lock(path string) func() {
+	f.mu.Lock()
+	l := f.locks[path]
+	if l == nil {
+		l = &cacheFillLock{}
+		f.locks[path] = l
+	}
+	l.refs++
+	f.mu.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(f.locks, path)
+		}
+	}
+}
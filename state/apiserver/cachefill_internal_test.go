@@ -0,0 +1,94 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+)
+
+type cacheFillSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&cacheFillSuite{})
+
+func (s *cacheFillSuite) TestConcurrentMissesFetchOnce(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "tools.tgz")
+	f := newCacheFiller()
+	var mu sync.Mutex
+	fetches := 0
+	fetch := func() error {
+		mu.Lock()
+		fetches++
+		mu.Unlock()
+		// Give the other requests time to find the file missing.
+		time.Sleep(testing.ShortWait)
+		return ioutil.WriteFile(path, []byte("tools"), 0644)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Check(f.fill(path, fetch), gc.IsNil)
+		}()
+	}
+	wg.Wait()
+	c.Assert(fetches, gc.Equals, 1)
+	c.Assert(f.locks, gc.HasLen, 0)
+}
+
+func (s *cacheFillSuite) TestFailedFetchIsRetried(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "tools.tgz")
+	f := newCacheFiller()
+	err := f.fill(path, func() error {
+		return fmt.Errorf("boom")
+	})
+	c.Assert(err, gc.ErrorMatches, "boom")
+	_, err = os.Stat(path)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+
+	err = f.fill(path, func() error {
+		return ioutil.WriteFile(path, []byte("tools"), 0644)
+	})
+	c.Assert(err, gc.IsNil)
+	err = f.fill(path, func() error {
+		c.Fatalf("cached file fetched again")
+		return nil
+	})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *cacheFillSuite) TestDifferentPathsFetchConcurrently(c *gc.C) {
+	dir := c.MkDir()
+	f := newCacheFiller()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- f.fill(filepath.Join(dir, "a"), func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	// A fetch of another path is not held up by the first.
+	err := f.fill(filepath.Join(dir, "b"), func() error { return nil })
+	c.Assert(err, gc.IsNil)
+	close(release)
+	c.Assert(<-done, gc.IsNil)
+}
@@ -29,7 +29,8 @@ import (
 	"github.com/juju/juju/state/api/params"
 )
 
-// charmsHandler handles charm upload through HTTPS in the API server.
+// charmsHandler handles charm upload and download through HTTPS in the
+// API server.
 type charmsHandler struct {
 	httpHandler
 	dataDir string
@@ -40,7 +41,12 @@ type charmsHandler struct {
 type bundleContentSenderFunc func(w http.ResponseWriter, r *http.Request, bundle *charm.Bundle)
 
 func (h *charmsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if err := h.authenticate(r); err != nil {
+	authenticate := h.authenticate
+	if r.Method == "GET" {
+		// Agents may download charms, but only users may upload them.
+		authenticate = h.authenticateAgentOrUser
+	}
+	if err := authenticate(r); err != nil {
 		h.authError(w, h)
 		return
 	}
@@ -62,13 +68,16 @@ func (h *charmsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "GET":
 		// Retrieve or list charm files.
 		// Requires "url" (charm URL) and an optional "file" (the path to the
-		// charm file) to be included in the query.
+		// charm file, or "*" for the whole archive) to be included in the query.
 		if charmArchivePath, filePath, err := h.processGet(r); err != nil {
 			// An error occurred retrieving the charm bundle.
 			h.sendError(w, http.StatusBadRequest, err.Error())
 		} else if filePath == "" {
 			// The client requested the list of charm files.
 			sendBundleContent(w, r, charmArchivePath, h.manifestSender)
+		} else if filePath == "*" {
+			// The client requested the whole charm archive.
+			sendBundleContent(w, r, charmArchivePath, h.archiveSender)
 		} else {
 			// The client requested a specific file.
 			sendBundleContent(w, r, charmArchivePath, h.fileSender(filePath))
@@ -117,6 +126,28 @@ func (h *charmsHandler) manifestSender(w http.ResponseWriter, r *http.Request, b
 	h.sendJSON(w, http.StatusOK, &params.CharmsResponse{Files: manifest.SortedValues()})
 }
 
+// archiveSender sends the whole charm archive to the client. Range requests
+// are honoured, so interrupted downloads can be resumed.
+func (h *charmsHandler) archiveSender(w http.ResponseWriter, r *http.Request, bundle *charm.Bundle) {
+	f, err := os.Open(bundle.Path)
+	if err != nil {
+		http.Error(
+			w, fmt.Sprintf("unable to read archive in %q: %v", bundle.Path, err),
+			http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(
+			w, fmt.Sprintf("unable to read archive in %q: %v", bundle.Path, err),
+			http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	http.ServeContent(w, r, filepath.Base(bundle.Path), info.ModTime(), f)
+}
+
 // fileSender returns a bundleContentSenderFunc which is responsible for sending
 // the contents of filePath included in the given charm bundle. If filePath does
 // not identify a file or a symlink, a 403 forbidden error is returned.
@@ -402,21 +433,30 @@ func (h *charmsHandler) processGet(r *http.Request) (string, string, error) {
 	name := charm.Quote(curl)
 	charmArchivePath := filepath.Join(h.dataDir, "charm-get-cache", name+".zip")
 
-	// Check if the charm archive is already in the cache.
-	if _, err := os.Stat(charmArchivePath); os.IsNotExist(err) {
-		// Download the charm archive and save it to the cache.
-		if err = h.downloadCharm(name, charmArchivePath); err != nil {
-			return "", "", fmt.Errorf("unable to retrieve and save the charm: %v", err)
+	// Download the charm archive and save it to the cache, unless it
+	// is already there.
+	err := cacheFills.fill(charmArchivePath, func() error {
+		if err := h.downloadCharm(curl, name, charmArchivePath); err != nil {
+			return fmt.Errorf("unable to retrieve and save the charm: %v", err)
 		}
-	} else if err != nil {
-		return "", "", fmt.Errorf("cannot access the charms cache: %v", err)
+		return nil
+	})
+	if err != nil {
+		return "", "", err
 	}
 	return charmArchivePath, filePath, nil
 }
 
 // downloadCharm downloads the given charm name from the provider storage and
-// saves the corresponding zip archive to the given charmArchivePath.
-func (h *charmsHandler) downloadCharm(name, charmArchivePath string) error {
+// saves the corresponding zip archive to the given charmArchivePath. If the
+// charm is known to state, the archive's SHA256 hash is checked against the
+// one recorded there.
+func (h *charmsHandler) downloadCharm(curl, name, charmArchivePath string) error {
+	expectedSHA256, err := h.charmBundleSHA256(curl)
+	if err != nil {
+		return err
+	}
+
 	// Get the provider storage.
 	storage, err := environs.GetStorage(h.state)
 	if err != nil {
@@ -433,6 +473,12 @@ func (h *charmsHandler) downloadCharm(name, charmArchivePath string) error {
 	if err != nil {
 		return errors.Annotate(err, "cannot read charm data")
 	}
+	if expectedSHA256 != "" {
+		hash := sha256.Sum256(data)
+		if actualSHA256 := hex.EncodeToString(hash[:]); actualSHA256 != expectedSHA256 {
+			return fmt.Errorf("sha256 mismatch: expected %s, got %s", expectedSHA256, actualSHA256)
+		}
+	}
 	// In order to avoid races, the archive is saved in a temporary file which
 	// is then atomically renamed. The temporary file is created in the
 	// charm cache directory so that we can safely assume the rename source and
@@ -455,3 +501,20 @@ func (h *charmsHandler) downloadCharm(name, charmArchivePath string) error {
 	}
 	return nil
 }
+
+// charmBundleSHA256 returns the SHA256 hash of the archive of the charm
+// with the given URL, as recorded in state. An empty string is returned
+// if the charm is not known to state.
+func (h *charmsHandler) charmBundleSHA256(curl string) (string, error) {
+	parsedURL, err := charm.ParseURL(curl)
+	if err != nil {
+		return "", fmt.Errorf("invalid charm URL %q: %v", curl, err)
+	}
+	ch, err := h.state.Charm(parsedURL)
+	if errors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Annotate(err, "cannot get charm from state")
+	}
+	return ch.BundleSha256(), nil
+}
@@ -120,23 +120,34 @@ func (s *charmsSuite) TestRequiresPOSTorGET(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusMethodNotAllowed, `unsupported method: "PUT"`)
 }
 
-func (s *charmsSuite) TestAuthRequiresUser(c *gc.C) {
-	// Add a machine and try to login.
+func (s *charmsSuite) addMachineAgent(c *gc.C) (tag, password string) {
 	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
 	c.Assert(err, gc.IsNil)
 	err = machine.SetProvisioned("foo", "fake_nonce", nil)
 	c.Assert(err, gc.IsNil)
-	password, err := utils.RandomPassword()
+	password, err = utils.RandomPassword()
 	c.Assert(err, gc.IsNil)
 	err = machine.SetPassword(password)
 	c.Assert(err, gc.IsNil)
+	return machine.Tag().String(), password
+}
 
-	resp, err := s.sendRequest(c, machine.Tag().String(), password, "GET", s.charmsURI(c, ""), "", nil)
+func (s *charmsSuite) TestUploadRequiresUser(c *gc.C) {
+	// Add a machine and try to upload.
+	tag, password := s.addMachineAgent(c)
+	resp, err := s.sendRequest(c, tag, password, "POST", s.charmsURI(c, ""), "", nil)
 	c.Assert(err, gc.IsNil)
 	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
 
 	// Now try a user login.
-	resp, err = s.authRequest(c, "GET", s.charmsURI(c, ""), "", nil)
+	resp, err = s.authRequest(c, "POST", s.charmsURI(c, ""), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected series=URL argument")
+}
+
+func (s *charmsSuite) TestGetAllowsAgents(c *gc.C) {
+	tag, password := s.addMachineAgent(c)
+	resp, err := s.sendRequest(c, tag, password, "GET", s.charmsURI(c, ""), "", nil)
 	c.Assert(err, gc.IsNil)
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected url=CharmURL query argument")
 }
@@ -501,6 +512,51 @@ func (s *charmsSuite) TestGetUsesCache(c *gc.C) {
 	s.assertGetFileResponse(c, resp, contents, "application/javascript")
 }
 
+func (s *charmsSuite) TestGetReturnsArchive(c *gc.C) {
+	// Add the dummy charm.
+	ch := charmtesting.Charms.Bundle(c.MkDir(), "dummy")
+	_, err := s.uploadRequest(
+		c, s.charmsURI(c, "?series=quantal"), true, ch.Path)
+	c.Assert(err, gc.IsNil)
+	r, err := s.Conn.Environ.Storage().Get(charm.Quote("local:quantal/dummy-1"))
+	c.Assert(err, gc.IsNil)
+	defer r.Close()
+	expectedData, err := ioutil.ReadAll(r)
+	c.Assert(err, gc.IsNil)
+
+	// Ensure an agent can retrieve the whole archive.
+	tag, password := s.addMachineAgent(c)
+	uri := s.charmsURI(c, "?url=local:quantal/dummy-1&file=*")
+	resp, err := s.sendRequest(c, tag, password, "GET", uri, "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertGetFileResponse(c, resp, string(expectedData), "application/zip")
+}
+
+func (s *charmsSuite) TestGetRejectsChecksumMismatch(c *gc.C) {
+	// Add the dummy charm, then replace it in provider storage.
+	ch := charmtesting.Charms.Bundle(c.MkDir(), "dummy")
+	_, err := s.uploadRequest(
+		c, s.charmsURI(c, "?series=quantal"), true, ch.Path)
+	c.Assert(err, gc.IsNil)
+	data := []byte("not the uploaded charm")
+	err = s.Conn.Environ.Storage().Put(
+		charm.Quote("local:quantal/dummy-1"), bytes.NewReader(data), int64(len(data)))
+	c.Assert(err, gc.IsNil)
+
+	// Ensure the corrupted archive is neither served nor cached.
+	uri := s.charmsURI(c, "?url=local:quantal/dummy-1&file=*")
+	resp, err := s.authRequest(c, "GET", uri, "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(
+		c, resp, http.StatusBadRequest,
+		"unable to retrieve and save the charm: sha256 mismatch: .*",
+	)
+	charmArchivePath := filepath.Join(
+		s.DataDir(), "charm-get-cache", charm.Quote("local:quantal/dummy-1")+".zip")
+	_, err = os.Stat(charmArchivePath)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
 func (s *charmsSuite) charmsURL(c *gc.C, query string) *url.URL {
 	uri := s.baseURL(c)
 	uri.Path += "/charms"
@@ -0,0 +1,91 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// RotateAgentPasswords asks the agents of the given machines and
+// units to change their passwords, as when an agent's credentials may
+// have been compromised. A machine's units and containers are assumed
+// to be compromised along with it, so their agents are asked too.
+// Each agent changes its password as soon as it notices the request.
+func (c *Client) RotateAgentPasswords(args params.RotateAgentPasswordsArgs) (params.RotateAgentPasswordsResults, error) {
+	var results params.RotateAgentPasswordsResults
+	for _, name := range args.Agents {
+		var tags []string
+		var err error
+		switch {
+		case names.IsMachine(name):
+			tags, err = c.rotateMachinePasswords(name)
+		case names.IsUnit(name):
+			tags, err = c.rotateUnitPassword(name)
+		default:
+			err = errors.NotValidf("agent %q", name)
+		}
+		if err != nil {
+			return params.RotateAgentPasswordsResults{}, err
+		}
+		results.Agents = append(results.Agents, tags...)
+	}
+	return results, nil
+}
+
+// rotateMachinePasswords asks the agents of the machine with the
+// given id, and of its units and containers, to change their
+// passwords, and returns their tags.
+func (c *Client) rotateMachinePasswords(id string) ([]string, error) {
+	machine, err := c.api.state.Machine(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := machine.RequestPasswordRotation(); err != nil {
+		return nil, err
+	}
+	tags := []string{machine.Tag().String()}
+	units, err := machine.Units()
+	if err != nil {
+		return nil, err
+	}
+	for _, unit := range units {
+		if unit.Life() == state.Dead {
+			// Dead agents have already stopped.
+			continue
+		}
+		if err := unit.RequestPasswordRotation(); err != nil {
+			return nil, err
+		}
+		tags = append(tags, unit.Tag().String())
+	}
+	containers, err := machine.Containers()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range containers {
+		containerTags, err := c.rotateMachinePasswords(id)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, containerTags...)
+	}
+	return tags, nil
+}
+
+// rotateUnitPassword asks the agent of the named unit to change its
+// password, and returns its tag.
+func (c *Client) rotateUnitPassword(name string) ([]string, error) {
+	unit, err := c.api.state.Unit(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := unit.RequestPasswordRotation(); err != nil {
+		return nil, err
+	}
+	return []string{unit.Tag().String()}, nil
+}
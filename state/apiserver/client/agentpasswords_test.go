@@ -0,0 +1,69 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+)
+
+type agentPasswordsSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&agentPasswordsSuite{})
+
+func (s *agentPasswordsSuite) addUnit(c *gc.C, service *state.Service, m *state.Machine) *state.Unit {
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(m)
+	c.Assert(err, gc.IsNil)
+	return unit
+}
+
+func (s *agentPasswordsSuite) TestRotateAgentPasswords(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	m0, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	u0 := s.addUnit(c, wordpress, m0)
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, m0.Id(), instance.LXC)
+	c.Assert(err, gc.IsNil)
+	u1 := s.addUnit(c, wordpress, container)
+	m1, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	u2 := s.addUnit(c, wordpress, m1)
+
+	agents, err := s.APIState.Client().RotateAgentPasswords(m0.Id(), u2.Name())
+	c.Assert(err, gc.IsNil)
+	c.Assert(agents, jc.DeepEquals, []string{
+		"machine-0", "unit-wordpress-0", "machine-0-lxc-0", "unit-wordpress-1", "unit-wordpress-2",
+	})
+
+	for _, entity := range []interface {
+		Refresh() error
+		PasswordRotationRequested() bool
+	}{m0, u0, container, u1, u2} {
+		err := entity.Refresh()
+		c.Assert(err, gc.IsNil)
+		c.Assert(entity.PasswordRotationRequested(), gc.Equals, true)
+	}
+	err = m1.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(m1.PasswordRotationRequested(), gc.Equals, false)
+}
+
+func (s *agentPasswordsSuite) TestRotateAgentPasswordsErrors(c *gc.C) {
+	_, err := s.APIState.Client().RotateAgentPasswords("42")
+	c.Assert(err, gc.ErrorMatches, "machine 42 not found")
+	_, err = s.APIState.Client().RotateAgentPasswords("foo/0")
+	c.Assert(err, gc.ErrorMatches, `unit "foo/0" not found`)
+	_, err = s.APIState.Client().RotateAgentPasswords("foo")
+	c.Assert(err, gc.ErrorMatches, `agent "foo" not valid`)
+}
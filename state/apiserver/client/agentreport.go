@@ -0,0 +1,78 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/agent"
+	agenttools "github.com/juju/juju/agent/tools"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// agentReportTimeout holds how long to wait for an agent report
+// before giving up.
+var agentReportTimeout = time.Minute
+
+// AgentReport returns a report on the running agent of a machine or
+// unit. The report is fetched from the agent's introspection socket
+// by running "jujud introspect" on the agent's machine.
+func (c *Client) AgentReport(args params.AgentReportArgs) (params.AgentReport, error) {
+	var result params.AgentReport
+	var machineId, agentTag string
+	switch {
+	case names.IsMachine(args.Agent):
+		machineId = args.Agent
+		agentTag = names.NewMachineTag(args.Agent).String()
+	case names.IsUnit(args.Agent):
+		unit, err := c.api.state.Unit(args.Agent)
+		if err != nil {
+			return result, err
+		}
+		machineId, err = unit.AssignedMachineId()
+		if err != nil {
+			return result, err
+		}
+		agentTag = unit.Tag().String()
+	default:
+		return result, errors.NotValidf("agent %q", args.Agent)
+	}
+	machine, err := c.api.state.Machine(machineId)
+	if err != nil {
+		return result, err
+	}
+	execParams := remoteParamsForMachine(machine, introspectCommand(machine, agentTag, args.Goroutines), agentReportTimeout)
+	runResults := ParallelExecute(c.getDataDir(), []*RemoteExec{execParams})
+	run := runResults.Results[0]
+	if run.Error != "" {
+		return result, fmt.Errorf("cannot get report from %s: %s", agentTag, run.Error)
+	}
+	if run.Code != 0 {
+		return result, fmt.Errorf("cannot get report from %s: %s", agentTag, strings.TrimSpace(string(run.Stderr)))
+	}
+	if err := json.Unmarshal(run.Stdout, &result); err != nil {
+		return result, fmt.Errorf("cannot parse report from %s: %v", agentTag, err)
+	}
+	return result, nil
+}
+
+// introspectCommand returns the command that reports on the agent
+// with the given tag when run on the given machine.
+func introspectCommand(machine *state.Machine, agentTag string, goroutines bool) string {
+	jujud := path.Join(agenttools.ToolsDir(agent.DefaultDataDir, machine.Tag().String()), "jujud")
+	command := fmt.Sprintf("sudo -n %s introspect --format json", utils.ShQuote(jujud))
+	if goroutines {
+		command += " --goroutines"
+	}
+	return command + " " + agentTag
+}
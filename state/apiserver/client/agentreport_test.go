@@ -0,0 +1,113 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+type agentReportSuite struct {
+	baseSuite
+	commandFile string
+}
+
+var _ = gc.Suite(&agentReportSuite{})
+
+const fakeReport = `{"Agent":"unit-wordpress-0","Version":"1.2.3-trusty-amd64",` +
+	`"Config":{"tag":"unit-wordpress-0"},` +
+	`"Workers":[{"Id":"api","State":"running","Restarts":1,"LastError":"boom",` +
+	`"Workers":[{"Id":"uniter","State":"restarting"}]}]}`
+
+// mockSSH installs a fake ssh command that records the command it is
+// given in s.commandFile, writes the given output to stdout and exits
+// with the given code.
+func (s *agentReportSuite) mockSSH(c *gc.C, output string, code int) {
+	testbin := c.MkDir()
+	s.commandFile = filepath.Join(testbin, "command")
+	script := fmt.Sprintf("#!/bin/bash\ncat > %s\necho '%s'\nexit %d\n", s.commandFile, output, code)
+	err := ioutil.WriteFile(filepath.Join(testbin, "ssh"), []byte(script), 0755)
+	c.Assert(err, gc.IsNil)
+	s.PatchEnvPathPrepend(testbin)
+}
+
+func (s *agentReportSuite) addUnit(c *gc.C) *state.Unit {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetAddresses(network.NewAddress("10.0.0.1", network.ScopeUnknown))
+	c.Assert(err, gc.IsNil)
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	return unit
+}
+
+func (s *agentReportSuite) TestAgentReport(c *gc.C) {
+	s.addUnit(c)
+	s.mockSSH(c, fakeReport, 0)
+
+	report, err := s.APIState.Client().AgentReport("wordpress/0", true)
+	c.Assert(err, gc.IsNil)
+	c.Assert(report, jc.DeepEquals, params.AgentReport{
+		Agent:   "unit-wordpress-0",
+		Version: "1.2.3-trusty-amd64",
+		Config:  map[string]string{"tag": "unit-wordpress-0"},
+		Workers: []params.WorkerStatus{{
+			Id:        "api",
+			State:     "running",
+			Restarts:  1,
+			LastError: "boom",
+			Workers: []params.WorkerStatus{{
+				Id:    "uniter",
+				State: "restarting",
+			}},
+		}},
+	})
+	command, err := ioutil.ReadFile(s.commandFile)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(command), gc.Equals,
+		"sudo -n '/var/lib/juju/tools/machine-0/jujud' introspect --format json --goroutines unit-wordpress-0\n")
+}
+
+func (s *agentReportSuite) TestAgentReportMachine(c *gc.C) {
+	s.addUnit(c)
+	s.mockSSH(c, `{"Agent":"machine-0"}`, 0)
+
+	report, err := s.APIState.Client().AgentReport("0", false)
+	c.Assert(err, gc.IsNil)
+	c.Assert(report.Agent, gc.Equals, "machine-0")
+	command, err := ioutil.ReadFile(s.commandFile)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(command), gc.Equals,
+		"sudo -n '/var/lib/juju/tools/machine-0/jujud' introspect --format json machine-0\n")
+}
+
+func (s *agentReportSuite) TestAgentReportCommandFails(c *gc.C) {
+	s.addUnit(c)
+	s.mockSSH(c, "", 1)
+
+	_, err := s.APIState.Client().AgentReport("wordpress/0", false)
+	c.Assert(err, gc.ErrorMatches, "cannot get report from unit-wordpress-0: .*")
+}
+
+func (s *agentReportSuite) TestAgentReportNotFound(c *gc.C) {
+	_, err := s.APIState.Client().AgentReport("wordpress/0", false)
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/0" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *agentReportSuite) TestAgentReportInvalidAgent(c *gc.C) {
+	_, err := s.APIState.Client().AgentReport("service-wordpress", false)
+	c.Assert(err, gc.ErrorMatches, `agent "service-wordpress" not valid`)
+}
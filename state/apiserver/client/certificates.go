@@ -0,0 +1,120 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// certExpiryWarningPeriod holds how long before a certificate
+// expires that status starts warning about it.
+var certExpiryWarningPeriod = 30 * 24 * time.Hour
+
+// RotateCertificates replaces the CA certificates trusted by agents,
+// the state server certificate, or both. Agents pick up the new CA
+// certificates and state servers restart with the new state server
+// certificate as they notice the change.
+//
+// The state server certificate must be valid with respect to the
+// resulting CA certificates, so that agents can still connect once
+// they have both. To replace the CA, first add the new CA certificate
+// alongside the old one, wait for agents to pick it up, and then
+// replace the state server certificate with one signed by the new
+// CA and drop the old CA certificate.
+func (c *Client) RotateCertificates(args params.RotateCertificatesArgs) error {
+	envConfig, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return err
+	}
+	caCert, _ := envConfig.CACert()
+	if args.CACert != "" {
+		if _, err := cert.ParseCerts(args.CACert); err != nil {
+			return errors.Annotate(err, "cannot parse CA certificate")
+		}
+		caCert = args.CACert
+	}
+	info, err := c.api.state.StateServingInfo()
+	if err != nil {
+		return err
+	}
+	if args.Cert != "" || args.PrivateKey != "" {
+		if _, _, err := cert.ParseCertAndKey(args.Cert, args.PrivateKey); err != nil {
+			return errors.Annotate(err, "cannot parse state server certificate and key")
+		}
+		info.Cert, info.PrivateKey = args.Cert, args.PrivateKey
+	}
+	if err := cert.Verify(info.Cert, caCert, time.Now()); err != nil {
+		return fmt.Errorf("state server certificate not valid for CA certificate: %v", err)
+	}
+	if args.Cert != "" {
+		if err := c.api.state.SetStateServingInfo(info); err != nil {
+			return err
+		}
+	}
+	if args.CACert != "" {
+		// Any CA private key left in the environment configuration
+		// belongs to the old CA, so it is removed along with it.
+		attrs := map[string]interface{}{"ca-cert": caCert}
+		if err := c.api.state.UpdateEnvironConfig(attrs, []string{"ca-private-key"}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// certificateWarnings returns a warning for each CA certificate and
+// the state server certificate that has expired or will expire
+// within certExpiryWarningPeriod of now.
+func certificateWarnings(st *state.State, now time.Time) ([]string, error) {
+	envConfig, err := st.EnvironConfig()
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	if caCert, ok := envConfig.CACert(); ok {
+		caCerts, err := cert.ParseCerts(caCert)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot parse CA certificate")
+		}
+		for _, caCert := range caCerts {
+			if w := expiryWarning("CA certificate", caCert.NotAfter, now); w != "" {
+				warnings = append(warnings, w)
+			}
+		}
+	}
+	info, err := st.StateServingInfo()
+	if errors.IsNotFound(err) {
+		return warnings, nil
+	} else if err != nil {
+		return nil, err
+	}
+	srvCert, err := cert.ParseCert(info.Cert)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot parse state server certificate")
+	}
+	if w := expiryWarning("state server certificate", srvCert.NotAfter, now); w != "" {
+		warnings = append(warnings, w)
+	}
+	return warnings, nil
+}
+
+// expiryWarning returns a warning about the named certificate if it
+// expires within certExpiryWarningPeriod of now, or "" otherwise.
+func expiryWarning(name string, expiry, now time.Time) string {
+	expiryStr := expiry.UTC().Format(time.RFC3339)
+	switch {
+	case !expiry.After(now):
+		return fmt.Sprintf("%s expired at %s; run juju rotate-certs", name, expiryStr)
+	case expiry.Before(now.Add(certExpiryWarningPeriod)):
+		return fmt.Sprintf("%s expires at %s; run juju rotate-certs", name, expiryStr)
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/client"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type certificatesSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&certificatesSuite{})
+
+func newServerCert(c *gc.C, caCert, caKey string) (string, string) {
+	var noHostnames []string
+	srvCert, srvKey, err := cert.NewServer(caCert, caKey, time.Now().AddDate(1, 0, 0), noHostnames)
+	c.Assert(err, gc.IsNil)
+	return srvCert, srvKey
+}
+
+func (s *certificatesSuite) assertCACert(c *gc.C, expect string) {
+	envConfig, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	caCert, _ := envConfig.CACert()
+	c.Assert(caCert, gc.Equals, expect)
+}
+
+func (s *certificatesSuite) TestRotateStateServerCert(c *gc.C) {
+	srvCert, srvKey := newServerCert(c, coretesting.CACert, coretesting.CAKey)
+	err := s.APIState.Client().RotateCertificates(params.RotateCertificatesArgs{
+		Cert:       srvCert,
+		PrivateKey: srvKey,
+	})
+	c.Assert(err, gc.IsNil)
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Cert, gc.Equals, srvCert)
+	c.Assert(info.PrivateKey, gc.Equals, srvKey)
+	c.Assert(info.SharedSecret, gc.Equals, "really, really secret")
+	s.assertCACert(c, coretesting.CACert)
+}
+
+func (s *certificatesSuite) TestRotateCACert(c *gc.C) {
+	newCACert, newCAKey, err := cert.NewCA("foo", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+
+	// First trust both the old and new CAs.
+	bundle := coretesting.CACert + newCACert
+	err = s.APIState.Client().RotateCertificates(params.RotateCertificatesArgs{
+		CACert: bundle,
+	})
+	c.Assert(err, gc.IsNil)
+	s.assertCACert(c, bundle)
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Cert, gc.Equals, coretesting.ServerCert)
+
+	// Then switch to a state server certificate signed by the new
+	// CA and drop the old one.
+	srvCert, srvKey := newServerCert(c, newCACert, newCAKey)
+	err = s.APIState.Client().RotateCertificates(params.RotateCertificatesArgs{
+		CACert:     newCACert,
+		Cert:       srvCert,
+		PrivateKey: srvKey,
+	})
+	c.Assert(err, gc.IsNil)
+	s.assertCACert(c, newCACert)
+	info, err = s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Cert, gc.Equals, srvCert)
+}
+
+func (s *certificatesSuite) TestRotateUntrustedStateServerCert(c *gc.C) {
+	newCACert, newCAKey, err := cert.NewCA("foo", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	srvCert, srvKey := newServerCert(c, newCACert, newCAKey)
+	err = s.APIState.Client().RotateCertificates(params.RotateCertificatesArgs{
+		Cert:       srvCert,
+		PrivateKey: srvKey,
+	})
+	c.Assert(err, gc.ErrorMatches, "state server certificate not valid for CA certificate: .*")
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Cert, gc.Equals, coretesting.ServerCert)
+}
+
+func (s *certificatesSuite) TestRotateCACertNotTrustingStateServer(c *gc.C) {
+	newCACert, _, err := cert.NewCA("foo", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	err = s.APIState.Client().RotateCertificates(params.RotateCertificatesArgs{
+		CACert: newCACert,
+	})
+	c.Assert(err, gc.ErrorMatches, "state server certificate not valid for CA certificate: .*")
+	s.assertCACert(c, coretesting.CACert)
+}
+
+func (s *certificatesSuite) TestRotateInvalidCertificates(c *gc.C) {
+	err := s.APIState.Client().RotateCertificates(params.RotateCertificatesArgs{
+		CACert: "bad",
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot parse CA certificate: no certificates found")
+	err = s.APIState.Client().RotateCertificates(params.RotateCertificatesArgs{
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.CAKey,
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot parse state server certificate and key: .*")
+}
+
+func (s *certificatesSuite) TestStatusWarnings(c *gc.C) {
+	status, err := s.APIState.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Warnings, gc.HasLen, 0)
+
+	// The testing certificates expire in ten years.
+	s.PatchValue(client.CertExpiryWarningPeriod, 11*365*24*time.Hour)
+	status, err = s.APIState.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Warnings, gc.HasLen, 2)
+	c.Assert(status.Warnings[0], gc.Matches, "CA certificate expires at .*; run juju rotate-certs")
+	c.Assert(status.Warnings[1], gc.Matches, "state server certificate expires at .*; run juju rotate-certs")
+}
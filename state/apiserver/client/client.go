@@ -284,6 +284,30 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 	if err != nil {
 		return err
 	}
+	var bindings map[string]string
+	if len(args.Bindings) > 0 {
+		bindings = make(map[string]string)
+		for endpoint, tag := range args.Bindings {
+			t, err := names.ParseNetworkTag(tag)
+			if err != nil {
+				return err
+			}
+			bindings[endpoint] = t.Id()
+		}
+	}
+	var storage map[string]state.StorageDirective
+	if len(args.Storage) > 0 {
+		if err := c.validateStorage(args.Storage); err != nil {
+			return err
+		}
+		storage = make(map[string]state.StorageDirective)
+		for name, directive := range args.Storage {
+			storage[name] = state.StorageDirective{
+				Pool: directive.Pool,
+				Size: directive.Size,
+			}
+		}
+	}
 
 	_, err = juju.DeployService(c.api.state,
 		juju.DeployServiceParams{
@@ -296,10 +320,33 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 			Constraints:    args.Constraints,
 			ToMachineSpec:  args.ToMachineSpec,
 			Networks:       requestedNetworks,
+			Bindings:       bindings,
+			Storage:        storage,
 		})
 	return err
 }
 
+// validateStorage returns an error if the environment's provider cannot
+// create the volumes described by the given storage directives, so that
+// they are rejected when the service is deployed rather than when its
+// units' volumes are provisioned.
+func (c *Client) validateStorage(storage map[string]params.StorageDirective) error {
+	envConfig, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return err
+	}
+	env, err := environs.New(envConfig)
+	if err != nil {
+		return errors.Annotate(err, "cannot access environment")
+	}
+	for name, directive := range storage {
+		if err := environs.ValidateStoragePool(env, directive.Pool); err != nil {
+			return fmt.Errorf("cannot use storage %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
 // ServiceDeployWithNetworks works exactly like ServiceDeploy, but
 // allows specifying networks to include or exclude on the machine
 // where the charm gets deployed (either with args.Network or with
@@ -308,6 +355,20 @@ func (c *Client) ServiceDeployWithNetworks(args params.ServiceDeploy) error {
 	return c.ServiceDeploy(args)
 }
 
+// ServiceDeployWithBindings works exactly like ServiceDeployWithNetworks,
+// but also binds relation endpoints of the service to networks with
+// args.Bindings.
+func (c *Client) ServiceDeployWithBindings(args params.ServiceDeploy) error {
+	return c.ServiceDeploy(args)
+}
+
+// ServiceDeployWithStorage works exactly like ServiceDeployWithBindings,
+// but also requests block storage volumes for each unit of the service
+// with args.Storage.
+func (c *Client) ServiceDeployWithStorage(args params.ServiceDeploy) error {
+	return c.ServiceDeploy(args)
+}
+
 // ServiceUpdate updates the service attributes, including charm URL,
 // minimum number of units, settings and constraints.
 // All parameters in params.ServiceUpdate except the service name are optional.
@@ -318,7 +379,7 @@ func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
 	}
 	// Set the charm for the given service.
 	if args.CharmUrl != "" {
-		if err = c.serviceSetCharm(service, args.CharmUrl, args.ForceCharmUrl); err != nil {
+		if err = c.serviceSetCharm(service, args.CharmUrl, args.ForceCharmUrl, 0); err != nil {
 			return err
 		}
 	}
@@ -346,7 +407,7 @@ func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
 }
 
 // serviceSetCharm sets the charm for the given service.
-func (c *Client) serviceSetCharm(service *state.Service, url string, force bool) error {
+func (c *Client) serviceSetCharm(service *state.Service, url string, force bool, batchSize int) error {
 	curl, err := charm.ParseURL(url)
 	if err != nil {
 		return err
@@ -356,17 +417,26 @@ func (c *Client) serviceSetCharm(service *state.Service, url string, force bool)
 		// Charms should be added before trying to use them, with
 		// AddCharm or AddLocalCharm API calls. When they're not,
 		// we're reverting to 1.16 compatibility mode.
-		return c.serviceSetCharm1dot16(service, curl, force)
+		return c.serviceSetCharm1dot16(service, curl, force, batchSize)
 	}
 	if err != nil {
 		return err
 	}
-	return service.SetCharm(sch, force)
+	return setServiceCharm(service, sch, force, batchSize)
+}
+
+// setServiceCharm sets the charm for the given service. If batchSize
+// is positive, existing units are upgraded in batches of that size.
+func setServiceCharm(service *state.Service, ch *state.Charm, force bool, batchSize int) error {
+	if batchSize > 0 {
+		return service.SetCharmRolling(ch, force, batchSize)
+	}
+	return service.SetCharm(ch, force)
 }
 
 // serviceSetCharm1dot16 sets the charm for the given service in 1.16
 // compatibility mode. Remove this when support for 1.16 is dropped.
-func (c *Client) serviceSetCharm1dot16(service *state.Service, curl *charm.URL, force bool) error {
+func (c *Client) serviceSetCharm1dot16(service *state.Service, curl *charm.URL, force bool, batchSize int) error {
 	if curl.Schema != "cs" {
 		return fmt.Errorf(`charm url has unsupported schema %q`, curl.Schema)
 	}
@@ -381,7 +451,7 @@ func (c *Client) serviceSetCharm1dot16(service *state.Service, curl *charm.URL,
 	if err != nil {
 		return err
 	}
-	return service.SetCharm(ch, force)
+	return setServiceCharm(service, ch, force, batchSize)
 }
 
 // serviceSetSettingsYAML updates the settings for the given service,
@@ -439,7 +509,30 @@ func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) error {
 	if err != nil {
 		return err
 	}
-	return c.serviceSetCharm(service, args.CharmUrl, args.Force)
+	return c.serviceSetCharm(service, args.CharmUrl, args.Force, 0)
+}
+
+// ServiceSetCharmRolling sets the charm for a given service, upgrading
+// existing units at most BatchSize at a time.
+func (c *Client) ServiceSetCharmRolling(args params.ServiceSetCharmRolling) error {
+	if args.BatchSize < 1 {
+		return fmt.Errorf("batch size must be positive")
+	}
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	return c.serviceSetCharm(service, args.CharmUrl, args.Force, args.BatchSize)
+}
+
+// ServiceResumeCharmUpgrade resumes a rolling charm upgrade of the
+// given service that was halted because a unit failed to upgrade.
+func (c *Client) ServiceResumeCharmUpgrade(args params.ServiceResumeCharmUpgrade) error {
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	return service.ResumeCharmUpgrade()
 }
 
 // addServiceUnits adds a given number of units to a service.
@@ -1123,7 +1216,15 @@ func (c *Client) ensureAvailabilitySingle(spec params.StateServersSpec) (params.
 		}
 		series = templateMachine.Series()
 	}
-	changes, err := c.api.state.EnsureAvailability(spec.NumStateServers, spec.Constraints, series)
+	remove := make([]string, len(spec.RemoveMachines))
+	for i, machineTag := range spec.RemoveMachines {
+		tag, err := names.ParseMachineTag(machineTag)
+		if err != nil {
+			return params.StateServersChanges{}, err
+		}
+		remove[i] = tag.Id()
+	}
+	changes, err := c.api.state.EnsureAvailability(spec.NumStateServers, spec.Constraints, series, remove...)
 	if err != nil {
 		return params.StateServersChanges{}, err
 	}
@@ -741,6 +741,68 @@ func (s *clientSuite) TestClientServiceDeployWithNetworks(c *gc.C) {
 	c.Assert(serviceCons, gc.DeepEquals, cons)
 }
 
+func (s *clientSuite) TestClientServiceDeployWithBindings(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, _ := addCharm(c, store, "mysql")
+
+	err := s.APIState.Client().ServiceDeployWithBindings(
+		curl.String(), "service", 1, "", constraints.Value{}, "",
+		nil, map[string]string{"server": "dbnet"},
+	)
+	c.Assert(err, gc.ErrorMatches, `"dbnet" is not a valid tag`)
+
+	err = s.APIState.Client().ServiceDeployWithBindings(
+		curl.String(), "service", 1, "", constraints.Value{}, "",
+		[]string{"network-net1"}, map[string]string{"server": "network-dbnet"},
+	)
+	c.Assert(err, gc.IsNil)
+	service, err := s.State.Service("service")
+	c.Assert(err, gc.IsNil)
+	networks, err := service.Networks()
+	c.Assert(err, gc.IsNil)
+	c.Assert(networks, gc.DeepEquals, []string{"net1", "dbnet"})
+	c.Assert(service.EndpointBindings(), gc.DeepEquals, map[string]string{"server": "dbnet"})
+}
+
+func (s *clientSuite) TestClientServiceDeployWithStorage(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, _ := addCharm(c, store, "mysql")
+
+	err := s.APIState.Client().ServiceDeployWithStorage(
+		curl.String(), "service", 1, "", constraints.Value{}, "", nil, nil,
+		map[string]params.StorageDirective{"data": {Pool: "loop", Size: 1024}},
+	)
+	c.Assert(err, gc.IsNil)
+	service, err := s.State.Service("service")
+	c.Assert(err, gc.IsNil)
+	c.Assert(service.StorageDirectives(), gc.DeepEquals, map[string]state.StorageDirective{
+		"data": {Pool: "loop", Size: 1024},
+	})
+	units, err := service.AllUnits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.HasLen, 1)
+	volumes, err := units[0].Volumes()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.HasLen, 1)
+	c.Assert(volumes[0].Name(), gc.Equals, "data")
+}
+
+func (s *clientSuite) TestClientServiceDeployWithUnsupportedStoragePool(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, _ := addCharm(c, store, "mysql")
+
+	err := s.APIState.Client().ServiceDeployWithStorage(
+		curl.String(), "service", 1, "", constraints.Value{}, "", nil, nil,
+		map[string]params.StorageDirective{"data": {Pool: "ebs", Size: 1024}},
+	)
+	c.Assert(err, gc.ErrorMatches, `cannot use storage "data": storage pool "ebs" not supported`)
+	_, err = s.State.Service("service")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *clientSuite) assertPrincipalDeployed(c *gc.C, serviceName string, curl *charm.URL, forced bool, bundle charm.Charm, cons constraints.Value) *state.Service {
 	service, err := s.State.Service(serviceName)
 	c.Assert(err, gc.IsNil)
@@ -1145,6 +1207,49 @@ func (s *clientSuite) TestClientServiceSetCharmForce(c *gc.C) {
 	c.Assert(force, gc.Equals, true)
 }
 
+func (s *clientSuite) TestClientServiceSetCharmRolling(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, _ := addCharm(c, store, "dummy")
+	err := s.APIState.Client().ServiceDeploy(
+		curl.String(), "service", 3, "", constraints.Value{}, "",
+	)
+	c.Assert(err, gc.IsNil)
+	addCharm(c, store, "wordpress")
+	err = s.APIState.Client().ServiceSetCharmRolling(
+		"service", "cs:precise/wordpress-3", false, 2,
+	)
+	c.Assert(err, gc.IsNil)
+
+	service, err := s.State.Service("service")
+	c.Assert(err, gc.IsNil)
+	charm, _, err := service.Charm()
+	c.Assert(err, gc.IsNil)
+	c.Assert(charm.URL().String(), gc.Equals, "cs:precise/wordpress-3")
+	upgrade, ok := service.CharmUpgrade()
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(upgrade.BatchSize, gc.Equals, 2)
+}
+
+func (s *clientSuite) TestClientServiceSetCharmRollingBadBatchSize(c *gc.C) {
+	err := s.APIState.Client().ServiceSetCharmRolling(
+		"service", "cs:precise/wordpress-3", false, 0,
+	)
+	c.Assert(err, gc.ErrorMatches, "batch size must be positive")
+}
+
+func (s *clientSuite) TestClientServiceResumeCharmUpgrade(c *gc.C) {
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := s.APIState.Client().ServiceResumeCharmUpgrade("wordpress")
+	c.Assert(err, gc.ErrorMatches, `cannot resume charm upgrade for service "wordpress": no charm upgrade in progress`)
+
+	ch := s.AddTestingCharm(c, "wordpress")
+	err = service.SetCharmRolling(ch, false, 1)
+	c.Assert(err, gc.IsNil)
+	err = s.APIState.Client().ServiceResumeCharmUpgrade("wordpress")
+	c.Assert(err, gc.IsNil)
+}
+
 func (s *clientSuite) TestClientServiceSetCharmInvalidService(c *gc.C) {
 	_, restore := makeMockCharmStore()
 	defer restore()
@@ -2391,8 +2496,46 @@ func (s *clientSuite) TestClientEnsureAvailabilityErrors(c *gc.C) {
 	c.Assert(ensureAvailabilityResult.Added, gc.DeepEquals, []string{"machine-1", "machine-2"})
 	c.Assert(ensureAvailabilityResult.Removed, gc.HasLen, 0)
 
-	_, err = s.APIState.Client().EnsureAvailability(1, emptyCons, defaultSeries)
-	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: cannot reduce state server count")
+	_, err = s.APIState.Client().EnsureAvailability(0, emptyCons, defaultSeries, "42")
+	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: machine 42 is not a state server")
+}
+
+func (s *clientSuite) TestClientEnsureAvailabilityRemove(c *gc.C) {
+	_, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	pingerA := s.setAgentPresence(c, "0")
+	defer assertKill(c, pingerA)
+
+	ensureAvailabilityResult, err := s.APIState.Client().EnsureAvailability(3, emptyCons, defaultSeries)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ensureAvailabilityResult.Added, gc.DeepEquals, []string{"machine-1", "machine-2"})
+	pingerB := s.setAgentPresence(c, "1")
+	defer assertKill(c, pingerB)
+	pingerC := s.setAgentPresence(c, "2")
+	defer assertKill(c, pingerC)
+
+	// Machine 1 has not been given a vote, so it is removed at once,
+	// and replaced.
+	ensureAvailabilityResult, err = s.APIState.Client().EnsureAvailability(0, emptyCons, defaultSeries, "1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ensureAvailabilityResult.Maintained, gc.DeepEquals, []string{"machine-0", "machine-2"})
+	c.Assert(ensureAvailabilityResult.Added, gc.DeepEquals, []string{"machine-3"})
+	c.Assert(ensureAvailabilityResult.Removed, gc.DeepEquals, []string{"machine-1"})
+
+	m1, err := s.State.Machine("1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(m1.IsManager(), jc.IsFalse)
+	c.Assert(m1.Life(), gc.Equals, state.Dying)
+
+	// Reducing the count removes the most recently added machines.
+	pingerD := s.setAgentPresence(c, "3")
+	defer assertKill(c, pingerD)
+	ensureAvailabilityResult, err = s.APIState.Client().EnsureAvailability(1, emptyCons, defaultSeries)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ensureAvailabilityResult.Removed, gc.DeepEquals, []string{"machine-3", "machine-2"})
+	info, err := s.State.StateServerInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.VotingMachineIds, gc.DeepEquals, []string{"0"})
 }
 
 func (s *clientSuite) TestAPIHostPorts(c *gc.C) {
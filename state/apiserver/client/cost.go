@@ -0,0 +1,137 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// EnvironmentCost returns the instance type and estimated hourly cost
+// of every provisioned top level machine in the environment. The cost
+// of each machine is divided equally between the principal units on
+// it and its containers to give the cost of each service.
+func (c *Client) EnvironmentCost() (params.EnvironmentCost, error) {
+	var result params.EnvironmentCost
+	envConfig, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return result, err
+	}
+	env, err := environs.New(envConfig)
+	if err != nil {
+		return result, err
+	}
+	coster, ok := env.(environs.InstanceCoster)
+	if !ok {
+		return result, errors.NotSupportedf("cost reporting for provider %q", envConfig.Type())
+	}
+	machines, err := c.api.state.AllMachines()
+	if err != nil {
+		return result, err
+	}
+	var hosts []*state.Machine
+	var ids []instance.Id
+	for _, m := range machines {
+		if _, isContainer := m.ParentId(); isContainer {
+			continue
+		}
+		id, err := m.InstanceId()
+		if state.IsNotProvisionedError(err) {
+			continue
+		} else if err != nil {
+			return result, err
+		}
+		hosts = append(hosts, m)
+		ids = append(ids, id)
+	}
+	costs := make([]environs.InstanceCost, len(ids))
+	if len(ids) > 0 {
+		found, err := coster.InstanceCosts(ids)
+		switch err {
+		case nil, environs.ErrPartialInstances:
+			costs = found
+		case environs.ErrNoInstances:
+		default:
+			return result, err
+		}
+	}
+	serviceCosts := make(map[string]float64)
+	for i, m := range hosts {
+		units, err := c.principalUnits(m)
+		if err != nil {
+			return result, err
+		}
+		machineCost := params.MachineCost{
+			Machine:      m.Id(),
+			InstanceId:   ids[i],
+			InstanceType: costs[i].InstanceType,
+			HourlyCost:   float64(costs[i].Cost) / 1000,
+			Units:        units,
+		}
+		if hc, err := m.HardwareCharacteristics(); err == nil {
+			machineCost.Hardware = hc.String()
+		}
+		result.Machines = append(result.Machines, machineCost)
+		result.HourlyCost += machineCost.HourlyCost
+		for _, unit := range units {
+			service := names.UnitService(unit)
+			serviceCosts[service] += machineCost.HourlyCost / float64(len(units))
+		}
+	}
+	for service, cost := range serviceCosts {
+		result.Services = append(result.Services, params.ServiceCost{
+			Service:    service,
+			HourlyCost: cost,
+		})
+	}
+	sort.Sort(serviceCostsByName(result.Services))
+	return result, nil
+}
+
+// principalUnits returns the names of the principal units on the
+// given machine and, recursively, on its containers.
+func (c *Client) principalUnits(m *state.Machine) ([]string, error) {
+	units, err := m.Units()
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, unit := range units {
+		if unit.IsPrincipal() {
+			result = append(result, unit.Name())
+		}
+	}
+	containers, err := m.Containers()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range containers {
+		container, err := c.api.state.Machine(id)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		containerUnits, err := c.principalUnits(container)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, containerUnits...)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+type serviceCostsByName []params.ServiceCost
+
+func (s serviceCostsByName) Len() int           { return len(s) }
+func (s serviceCostsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s serviceCostsByName) Less(i, j int) bool { return s[i].Service < s[j].Service }
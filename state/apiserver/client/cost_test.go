@@ -0,0 +1,109 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+type costSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&costSuite{})
+
+func (s *costSuite) addMachine(c *gc.C) *state.Machine {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	return m
+}
+
+func (s *costSuite) provision(c *gc.C, m *state.Machine, instanceType string, cost uint64) string {
+	inst, hc := testing.AssertStartInstance(c, s.Conn.Environ, m.Id())
+	dummy.SetInstanceCost(inst, instanceType, cost)
+	err := m.SetProvisioned(inst.Id(), "fake_nonce", hc)
+	c.Assert(err, gc.IsNil)
+	return hc.String()
+}
+
+func (s *costSuite) addUnit(c *gc.C, service *state.Service, m *state.Machine) {
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(m)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *costSuite) TestEnvironmentCost(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+
+	// The cost of m1 is shared between its unit and the unit in its
+	// container.
+	m1 := s.addMachine(c)
+	hw1 := s.provision(c, m1, "m1.large", 500)
+	s.addUnit(c, wordpress, m1)
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, m1.Id(), instance.LXC)
+	c.Assert(err, gc.IsNil)
+	s.addUnit(c, mysql, container)
+
+	m2 := s.addMachine(c)
+	hw2 := s.provision(c, m2, "m1.small", 250)
+	s.addUnit(c, mysql, m2)
+
+	// The provider knows nothing of m3's instance.
+	m3 := s.addMachine(c)
+	err = m3.SetProvisioned("i-unknown", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+
+	// Unprovisioned machines are left out.
+	s.addMachine(c)
+
+	result, err := s.APIState.Client().EnvironmentCost()
+	c.Assert(err, gc.IsNil)
+	inst1, err := m1.InstanceId()
+	c.Assert(err, gc.IsNil)
+	inst2, err := m2.InstanceId()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, jc.DeepEquals, params.EnvironmentCost{
+		Machines: []params.MachineCost{{
+			Machine:      m1.Id(),
+			InstanceId:   inst1,
+			InstanceType: "m1.large",
+			Hardware:     hw1,
+			HourlyCost:   0.5,
+			Units:        []string{"mysql/0", "wordpress/0"},
+		}, {
+			Machine:      m2.Id(),
+			InstanceId:   inst2,
+			InstanceType: "m1.small",
+			Hardware:     hw2,
+			HourlyCost:   0.25,
+			Units:        []string{"mysql/1"},
+		}, {
+			Machine:    m3.Id(),
+			InstanceId: "i-unknown",
+		}},
+		Services: []params.ServiceCost{
+			{Service: "mysql", HourlyCost: 0.5},
+			{Service: "wordpress", HourlyCost: 0.25},
+		},
+		HourlyCost: 0.75,
+	})
+}
+
+func (s *costSuite) TestEnvironmentCostNoMachines(c *gc.C) {
+	result, err := s.APIState.Client().EnvironmentCost()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, jc.DeepEquals, params.EnvironmentCost{})
+}
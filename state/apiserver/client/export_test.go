@@ -6,3 +6,7 @@ package client
 var ParseSettingsCompatible = parseSettingsCompatible
 var RemoteParamsForMachine = remoteParamsForMachine
 var GetAllUnitNames = getAllUnitNames
+var CertExpiryWarningPeriod = &certExpiryWarningPeriod
+var ReplicaSetStatus = &replicaSetStatus
+var ReplicaSetMembers = &replicaSetMembers
+var StepDownPrimary = &stepDownPrimary
@@ -72,6 +72,14 @@ var operationPermTests = []struct {
 	about: "Client.ServiceDeployWithNetworks",
 	op:    opClientServiceDeployWithNetworks,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ServiceDeployWithBindings",
+	op:    opClientServiceDeployWithBindings,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ServiceDeployWithStorage",
+	op:    opClientServiceDeployWithStorage,
+	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.ServiceUpdate",
 	op:    opClientServiceUpdate,
@@ -80,6 +88,70 @@ var operationPermTests = []struct {
 	about: "Client.ServiceSetCharm",
 	op:    opClientServiceSetCharm,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ServiceSetCharmRolling",
+	op:    opClientServiceSetCharmRolling,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ServiceResumeCharmUpgrade",
+	op:    opClientServiceResumeCharmUpgrade,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.AddSchedule",
+	op:    opClientAddSchedule,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ListSchedules",
+	op:    opClientListSchedules,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.PauseSchedule",
+	op:    opClientPauseSchedule,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.RemoveSchedule",
+	op:    opClientRemoveSchedule,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.EnvironmentCost",
+	op:    opClientEnvironmentCost,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.AgentReport",
+	op:    opClientAgentReport,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.RotateCertificates",
+	op:    opClientRotateCertificates,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.RotateAgentPasswords",
+	op:    opClientRotateAgentPasswords,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.StateServers",
+	op:    opClientStateServers,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.StepDownPrimary",
+	op:    opClientStepDownPrimary,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.DemoteStateServer",
+	op:    opClientDemoteStateServer,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.UpgradeProgress",
+	op:    opClientUpgradeProgress,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.SkipUpgradeStep",
+	op:    opClientSkipUpgradeStep,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.AbortUpgrade",
+	op:    opClientAbortUpgrade,
+	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.GetAnnotations",
 	op:    opClientGetAnnotations,
@@ -333,6 +405,22 @@ func opClientServiceDeployWithNetworks(c *gc.C, st *api.State, mst *state.State)
 	return func() {}, err
 }
 
+func opClientServiceDeployWithBindings(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceDeployWithBindings("mad:bad/url-1", "x", 1, "", constraints.Value{}, "", nil, nil)
+	if err.Error() == `charm URL has invalid schema: "mad:bad/url-1"` {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientServiceDeployWithStorage(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceDeployWithStorage("mad:bad/url-1", "x", 1, "", constraints.Value{}, "", nil, nil, nil)
+	if err.Error() == `charm URL has invalid schema: "mad:bad/url-1"` {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientServiceUpdate(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	args := params.ServiceUpdate{
 		ServiceName:     "no-such-charm",
@@ -356,6 +444,126 @@ func opClientServiceSetCharm(c *gc.C, st *api.State, mst *state.State) (func(),
 	return func() {}, err
 }
 
+func opClientServiceSetCharmRolling(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceSetCharmRolling("nosuch", "local:quantal/wordpress", false, 1)
+	if params.IsCodeNotFound(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientServiceResumeCharmUpgrade(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceResumeCharmUpgrade("nosuch")
+	if params.IsCodeNotFound(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientAddSchedule(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	id, err := st.Client().AddSchedule("wordpress", "@hourly", "ls")
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		schedule, err := mst.Schedule(id)
+		c.Assert(err, gc.IsNil)
+		err = schedule.Remove()
+		c.Assert(err, gc.IsNil)
+	}, nil
+}
+
+func opClientListSchedules(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ListSchedules()
+	return func() {}, err
+}
+
+func opClientPauseSchedule(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().PauseSchedule("99")
+	if params.IsCodeNotFound(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientRemoveSchedule(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().RemoveSchedule("99")
+	if params.IsCodeNotFound(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientEnvironmentCost(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().EnvironmentCost()
+	return func() {}, err
+}
+
+func opClientAgentReport(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().AgentReport("99", false)
+	if params.IsCodeNotFound(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientRotateCertificates(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().RotateCertificates(params.RotateCertificatesArgs{})
+	return func() {}, err
+}
+
+func opClientRotateAgentPasswords(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().RotateAgentPasswords()
+	return func() {}, err
+}
+
+// The test mongo server is not run as a replica set, so the replica
+// set operations below fail even when permitted.
+func opClientStateServers(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().StateServers()
+	if err != nil && !params.IsCodeUnauthorized(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientStepDownPrimary(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().StepDownPrimary()
+	if err != nil && !params.IsCodeUnauthorized(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientDemoteStateServer(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().DemoteStateServer("99")
+	if err != nil && !params.IsCodeUnauthorized(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientUpgradeProgress(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().UpgradeProgress()
+	return func() {}, err
+}
+
+func opClientSkipUpgradeStep(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().SkipUpgradeStep("99")
+	if err != nil && !params.IsCodeUnauthorized(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientAbortUpgrade(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().AbortUpgrade()
+	if err != nil && !params.IsCodeUnauthorized(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientAddServiceUnits(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	_, err := st.Client().AddServiceUnits("nosuch", 1, "")
 	if params.IsCodeNotFound(err) {
@@ -0,0 +1,82 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"github.com/juju/juju/state/api/params"
+)
+
+// AddSchedule adds a schedule that runs a command periodically on a
+// unit, or on every unit of a service.
+func (c *Client) AddSchedule(args params.AddSchedule) (params.AddScheduleResult, error) {
+	schedule, err := c.api.state.AddSchedule(args.Target, args.Spec, args.Command)
+	if err != nil {
+		return params.AddScheduleResult{}, err
+	}
+	return params.AddScheduleResult{Id: schedule.Id()}, nil
+}
+
+// ListSchedules returns all the schedules in the environment, along
+// with the most recent run of each on every unit.
+func (c *Client) ListSchedules() (params.ScheduleInfos, error) {
+	schedules, err := c.api.state.AllSchedules()
+	if err != nil {
+		return params.ScheduleInfos{}, err
+	}
+	result := params.ScheduleInfos{
+		Schedules: make([]params.ScheduleInfo, len(schedules)),
+	}
+	for i, schedule := range schedules {
+		runs, err := schedule.Runs()
+		if err != nil {
+			return params.ScheduleInfos{}, err
+		}
+		info := params.ScheduleInfo{
+			Id:      schedule.Id(),
+			Target:  schedule.Target(),
+			Spec:    schedule.Spec(),
+			Command: schedule.Command(),
+			Paused:  schedule.Paused(),
+		}
+		for _, run := range runs {
+			info.Runs = append(info.Runs, params.ScheduleRunInfo{
+				Unit:   run.Unit,
+				Time:   run.Time,
+				Code:   run.Code,
+				Output: run.Output,
+				Error:  run.Error,
+			})
+		}
+		result.Schedules[i] = info
+	}
+	return result, nil
+}
+
+// PauseSchedule stops a schedule's command being run until the
+// schedule is resumed.
+func (c *Client) PauseSchedule(args params.ScheduleId) error {
+	schedule, err := c.api.state.Schedule(args.Id)
+	if err != nil {
+		return err
+	}
+	return schedule.Pause()
+}
+
+// ResumeSchedule resumes a paused schedule.
+func (c *Client) ResumeSchedule(args params.ScheduleId) error {
+	schedule, err := c.api.state.Schedule(args.Id)
+	if err != nil {
+		return err
+	}
+	return schedule.Resume()
+}
+
+// RemoveSchedule removes a schedule.
+func (c *Client) RemoveSchedule(args params.ScheduleId) error {
+	schedule, err := c.api.state.Schedule(args.Id)
+	if err != nil {
+		return err
+	}
+	return schedule.Remove()
+}
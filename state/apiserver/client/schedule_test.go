@@ -0,0 +1,82 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+type scheduleSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&scheduleSuite{})
+
+func (s *scheduleSuite) TestAddListSchedules(c *gc.C) {
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+
+	client := s.APIState.Client()
+	id, err := client.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	when := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	err = unit.SetScheduleRun(id, state.ScheduleRun{Time: when, Code: 2, Output: "oops"})
+	c.Assert(err, gc.IsNil)
+
+	schedules, err := client.ListSchedules()
+	c.Assert(err, gc.IsNil)
+	c.Assert(schedules, gc.HasLen, 1)
+	c.Assert(schedules[0].Runs, gc.HasLen, 1)
+	c.Assert(schedules[0].Runs[0].Time.Equal(when), jc.IsTrue)
+	schedules[0].Runs[0].Time = when
+	c.Assert(schedules[0], jc.DeepEquals, params.ScheduleInfo{
+		Id:      id,
+		Target:  "wordpress",
+		Spec:    "@hourly",
+		Command: "ls",
+		Runs: []params.ScheduleRunInfo{{
+			Unit:   "wordpress/0",
+			Time:   when,
+			Code:   2,
+			Output: "oops",
+		}},
+	})
+}
+
+func (s *scheduleSuite) TestAddScheduleError(c *gc.C) {
+	_, err := s.APIState.Client().AddSchedule("wordpress", "bad", "ls")
+	c.Assert(err, gc.ErrorMatches, `cannot add schedule for "wordpress": invalid schedule "bad": expected 5 fields, got 1`)
+}
+
+func (s *scheduleSuite) TestPauseResumeRemoveSchedule(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	schedule, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+
+	client := s.APIState.Client()
+	err = client.PauseSchedule(schedule.Id())
+	c.Assert(err, gc.IsNil)
+	err = schedule.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(schedule.Paused(), jc.IsTrue)
+
+	err = client.ResumeSchedule(schedule.Id())
+	c.Assert(err, gc.IsNil)
+	err = schedule.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(schedule.Paused(), jc.IsFalse)
+
+	err = client.RemoveSchedule(schedule.Id())
+	c.Assert(err, gc.IsNil)
+	err = client.RemoveSchedule(schedule.Id())
+	c.Assert(err, gc.ErrorMatches, `schedule "`+schedule.Id()+`" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
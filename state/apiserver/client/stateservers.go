@@ -0,0 +1,135 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/replicaset"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// The replica set operations are variables so that they can be
+// replaced in tests, which do not run mongo as a replica set.
+var (
+	replicaSetStatus  = replicaset.CurrentStatus
+	replicaSetMembers = replicaset.CurrentMembers
+	stepDownPrimary   = replicaset.StepDownPrimary
+)
+
+// StateServers returns the state of each state server's member of the
+// mongo replica set, and the most recent decision made by the worker
+// that maintains the replica set, with its reasons.
+func (c *Client) StateServers() (params.StateServersStatus, error) {
+	var result params.StateServersStatus
+	st := c.api.state
+	info, err := st.StateServerInfo()
+	if err != nil {
+		return result, err
+	}
+	session := st.MongoSession()
+	status, err := replicaSetStatus(session)
+	if err != nil {
+		return result, err
+	}
+	members, err := replicaSetMembers(session)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot get replica set members")
+	}
+	statuses := make(map[int]replicaset.MemberStatus)
+	var primary *replicaset.MemberStatus
+	for i, memberStatus := range status.Members {
+		statuses[memberStatus.Id] = memberStatus
+		if memberStatus.State == replicaset.PrimaryState {
+			primary = &status.Members[i]
+		}
+	}
+	machineMembers := make(map[string]replicaset.Member)
+	for _, member := range members {
+		if id, ok := member.Tags[replicaset.JujuMachineKey]; ok {
+			machineMembers[id] = member
+		}
+	}
+	shown := make(map[int]bool)
+	for _, id := range info.MachineIds {
+		m, err := st.Machine(id)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return result, err
+		}
+		member, ok := machineMembers[id]
+		if ok {
+			shown[member.Id] = true
+		}
+		result.Members = append(result.Members, stateServerMember(m, member, statuses, primary))
+	}
+	// Members with no state server machine are shown too, as the
+	// worker cannot remove them while they vote.
+	for _, member := range members {
+		if !shown[member.Id] {
+			result.Members = append(result.Members, stateServerMember(nil, member, statuses, primary))
+		}
+	}
+	decision, err := st.PeerGroupDecision()
+	if err == nil {
+		result.Decision = &params.PeerGroupDecision{
+			Time:    decision.Time,
+			Changed: decision.Changed,
+			Reasons: decision.Reasons,
+			Error:   decision.Error,
+		}
+	} else if !errors.IsNotFound(err) {
+		return result, err
+	}
+	return result, nil
+}
+
+// stateServerMember describes the given state server machine and
+// replica set member; either may be missing.
+func stateServerMember(
+	m *state.Machine,
+	member replicaset.Member,
+	statuses map[int]replicaset.MemberStatus,
+	primary *replicaset.MemberStatus,
+) params.StateServerMember {
+	var result params.StateServerMember
+	if m != nil {
+		result.MachineId = m.Id()
+		result.WantsVote = m.WantsVote()
+		result.HasVote = m.HasVote()
+	}
+	if member.Address == "" {
+		return result
+	}
+	result.Address = member.Address
+	result.Voting = member.Votes == nil || *member.Votes > 0
+	status, ok := statuses[member.Id]
+	if !ok {
+		return result
+	}
+	result.State = status.State.String()
+	result.Healthy = status.Healthy
+	result.Message = status.ErrMsg
+	if primary != nil && status.Id != primary.Id && status.Optime.Before(primary.Optime) {
+		result.OptimeLag = primary.Optime.Sub(status.Optime)
+	}
+	return result
+}
+
+// StepDownPrimary asks the primary of the state servers' mongo replica
+// set to step down, so that another state server is elected primary.
+func (c *Client) StepDownPrimary() error {
+	return stepDownPrimary(c.api.state.MongoSession())
+}
+
+// DemoteStateServer removes the vote of a state server in the mongo
+// replica set. The vote is removed by the worker that maintains the
+// replica set once another state server can take it, so that the
+// number of votes stays odd; "juju state-servers" shows why it has
+// not been removed yet.
+func (c *Client) DemoteStateServer(args params.DemoteStateServerArgs) error {
+	return c.api.state.DemoteStateServer(args.MachineId)
+}
@@ -0,0 +1,132 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"gopkg.in/mgo.v2"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/replicaset"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/client"
+)
+
+type stateServersSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&stateServersSuite{})
+
+func (s *stateServersSuite) addStateServers(c *gc.C, n int) []*state.Machine {
+	machines := make([]*state.Machine, n)
+	for i := range machines {
+		m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+		c.Assert(err, gc.IsNil)
+		machines[i] = m
+	}
+	return machines
+}
+
+func (s *stateServersSuite) TestStateServers(c *gc.C) {
+	machines := s.addStateServers(c, 2)
+	err := machines[0].SetHasVote(true)
+	c.Assert(err, gc.IsNil)
+
+	noVotes := 0
+	s.PatchValue(client.ReplicaSetMembers, func(*mgo.Session) ([]replicaset.Member, error) {
+		return []replicaset.Member{{
+			Id:      1,
+			Address: "0.1.2.10:37017",
+			Tags:    map[string]string{replicaset.JujuMachineKey: "0"},
+		}, {
+			Id:      2,
+			Address: "0.1.2.11:37017",
+			Tags:    map[string]string{replicaset.JujuMachineKey: "1"},
+			Votes:   &noVotes,
+		}, {
+			Id:      3,
+			Address: "0.1.2.12:37017",
+			Votes:   &noVotes,
+		}}, nil
+	})
+	optime := time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.PatchValue(client.ReplicaSetStatus, func(*mgo.Session) (*replicaset.Status, error) {
+		return &replicaset.Status{
+			Members: []replicaset.MemberStatus{{
+				Id:      1,
+				Healthy: true,
+				State:   replicaset.PrimaryState,
+				Optime:  optime,
+			}, {
+				Id:      2,
+				Healthy: true,
+				State:   replicaset.SecondaryState,
+				Optime:  optime.Add(-5 * time.Second),
+			}, {
+				Id:     3,
+				ErrMsg: "no route to host",
+				State:  replicaset.DownState,
+			}},
+		}, nil
+	})
+	decision := state.PeerGroupDecision{
+		Time:    optime,
+		Reasons: []string{`machine "1" wants to vote but is not ready`},
+	}
+	err = s.State.SetPeerGroupDecision(decision)
+	c.Assert(err, gc.IsNil)
+
+	result, err := s.APIState.Client().StateServers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Members, jc.DeepEquals, []params.StateServerMember{{
+		MachineId: "0",
+		Address:   "0.1.2.10:37017",
+		State:     "PRIMARY",
+		Healthy:   true,
+		WantsVote: true,
+		HasVote:   true,
+		Voting:    true,
+	}, {
+		MachineId: "1",
+		Address:   "0.1.2.11:37017",
+		State:     "SECONDARY",
+		Healthy:   true,
+		WantsVote: true,
+		OptimeLag: 5 * time.Second,
+	}, {
+		Address: "0.1.2.12:37017",
+		State:   "DOWN",
+		Message: "no route to host",
+	}})
+	c.Assert(result.Decision, gc.NotNil)
+	c.Assert(result.Decision.Time.Equal(optime), jc.IsTrue)
+	c.Assert(result.Decision.Reasons, jc.DeepEquals, decision.Reasons)
+}
+
+func (s *stateServersSuite) TestStepDownPrimary(c *gc.C) {
+	called := false
+	s.PatchValue(client.StepDownPrimary, func(*mgo.Session) error {
+		called = true
+		return nil
+	})
+	err := s.APIState.Client().StepDownPrimary()
+	c.Assert(err, gc.IsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *stateServersSuite) TestDemoteStateServer(c *gc.C) {
+	machines := s.addStateServers(c, 3)
+	err := s.APIState.Client().DemoteStateServer(machines[1].Id())
+	c.Assert(err, gc.IsNil)
+	err = machines[1].Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(machines[1].WantsVote(), jc.IsFalse)
+
+	err = s.APIState.Client().DemoteStateServer(machines[1].Id())
+	c.Assert(err, gc.ErrorMatches, "cannot demote state server 1: machine is already demoted")
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/errors"
@@ -56,6 +57,13 @@ func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
 	if context.networks, err = fetchNetworks(conn.State); err != nil {
 		return noStatus, err
 	}
+	if context.hookLockWaits, err = conn.State.AllHookLockWaits(); err != nil {
+		return noStatus, err
+	}
+	warnings, err := certificateWarnings(conn.State, time.Now())
+	if err != nil {
+		return noStatus, err
+	}
 
 	return api.Status{
 		EnvironmentName: conn.Environ.Name(),
@@ -63,6 +71,7 @@ func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
 		Services:        context.processServices(),
 		Networks:        context.processNetworks(),
 		Relations:       context.processRelations(),
+		Warnings:        warnings,
 	}, nil
 }
 
@@ -90,6 +99,10 @@ type statusContext struct {
 	units        map[string]map[string]*state.Unit
 	networks     map[string]*state.Network
 	latestCharms map[charm.URL]string
+
+	// hookLockWaits holds the units' waits for the hook lock,
+	// keyed by unit name.
+	hookLockWaits map[string]*state.HookLockWait
 }
 
 type unitMatcher struct {
@@ -525,6 +538,23 @@ func (context *statusContext) processService(service *state.Service) (status api
 	if service.IsPrincipal() {
 		status.Units = context.processUnits(context.units[service.Name()], serviceCharmURL.String())
 	}
+	if upgrade, ok := service.CharmUpgrade(); ok {
+		status.CharmUpgrade = &api.CharmUpgradeStatus{
+			BatchSize: upgrade.BatchSize,
+			Halted:    upgrade.Halted,
+			Message:   upgrade.Message,
+		}
+		for name, unitStatus := range status.Units {
+			unit := context.unitByName(name)
+			progress, err := service.CharmUpgradeStatus(unit)
+			if err != nil {
+				status.Err = err
+				return
+			}
+			unitStatus.CharmUpgrade = string(progress)
+			status.Units[name] = unitStatus
+		}
+	}
 	return status
 }
 
@@ -552,6 +582,16 @@ func (context *statusContext) processUnit(unit *state.Unit, serviceCharm string)
 	status.AgentVersion = status.Agent.Version
 	status.Life = status.Agent.Life
 	status.Err = status.Agent.Err
+	// A wait recorded by an agent that has since died is stale.
+	if wait := context.hookLockWaits[unit.Name()]; wait != nil && status.AgentState != params.StatusDown {
+		status.HookLockWait = &api.HookLockWaitStatus{
+			Holder:     wait.Holder,
+			HolderHook: wait.HolderHook,
+			Hook:       wait.Hook,
+			Queued:     wait.Queued,
+			Since:      wait.Since,
+		}
+	}
 	if subUnits := unit.SubordinateNames(); len(subUnits) > 0 {
 		status.Subordinates = make(map[string]api.UnitStatus)
 		for _, name := range subUnits {
@@ -8,6 +8,8 @@ import (
 
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
 )
 
 type statusSuite struct {
@@ -57,3 +59,32 @@ func (s *statusSuite) TestLegacyStatus(c *gc.C) {
 	}
 	c.Check(resultMachine.InstanceId, gc.Equals, instanceId)
 }
+
+func (s *statusSuite) TestFullStatusHookLockWait(c *gc.C) {
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	err = unit.SetHookLockWait(state.HookLockWait{Holder: "mysql/0", Hook: "install"})
+	c.Assert(err, gc.IsNil)
+
+	// The unit's agent is down, so the wait it recorded is stale.
+	client := s.APIState.Client()
+	status, err := client.Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Services["wordpress"].Units["wordpress/0"].HookLockWait, gc.IsNil)
+
+	pinger, err := unit.SetAgentPresence()
+	c.Assert(err, gc.IsNil)
+	defer pinger.Kill()
+	s.BackingState.StartSync()
+	err = unit.WaitAgentPresence(coretesting.LongWait)
+	c.Assert(err, gc.IsNil)
+	status, err = client.Status(nil)
+	c.Assert(err, gc.IsNil)
+	wait := status.Services["wordpress"].Units["wordpress/0"].HookLockWait
+	c.Assert(wait, gc.NotNil)
+	c.Assert(wait.Holder, gc.Equals, "mysql/0")
+	c.Assert(wait.Hook, gc.Equals, "install")
+}
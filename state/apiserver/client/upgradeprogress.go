@@ -0,0 +1,93 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// UpgradeProgress returns the progress of the upgrade to the
+// environment's agent version of each provisioned machine agent.
+// Agents that have not started the upgrade are returned with no steps.
+func (c *Client) UpgradeProgress() (params.UpgradeProgressResults, error) {
+	var results params.UpgradeProgressResults
+	st := c.api.state
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return results, err
+	}
+	agentVersion, ok := cfg.AgentVersion()
+	if !ok {
+		return results, fmt.Errorf("incomplete environment configuration")
+	}
+	all, err := st.AllUpgradeProgress()
+	if err != nil {
+		return results, err
+	}
+	progressByAgent := make(map[string]state.UpgradeProgress)
+	for _, progress := range all {
+		progressByAgent[progress.Agent] = progress
+	}
+	machines, err := st.AllMachines()
+	if err != nil {
+		return results, err
+	}
+	for _, m := range machines {
+		if m.Life() == state.Dead {
+			continue
+		}
+		if _, err := m.InstanceId(); state.IsNotProvisionedError(err) {
+			continue
+		} else if err != nil {
+			return results, err
+		}
+		tag := m.Tag().String()
+		result := params.UpgradeProgress{
+			Agent:     tag,
+			ToVersion: agentVersion,
+		}
+		if progress, ok := progressByAgent[tag]; ok && progress.ToVersion == agentVersion {
+			result.Completed = progress.Completed
+			result.Updated = progress.Updated
+			result.Skip = progress.Skip
+			for _, step := range progress.Steps {
+				result.Steps = append(result.Steps, params.UpgradeStep(step))
+			}
+		}
+		results.Agents = append(results.Agents, result)
+	}
+	return results, nil
+}
+
+// SkipUpgradeStep asks a machine agent to skip the upgrade step that
+// has failed, rather than retrying it, and returns the step's
+// description.
+func (c *Client) SkipUpgradeStep(args params.SkipUpgradeStepArgs) (params.SkipUpgradeStepResult, error) {
+	if !names.IsMachine(args.Agent) {
+		return params.SkipUpgradeStepResult{}, errors.NotValidf("machine id %q", args.Agent)
+	}
+	tag := names.NewMachineTag(args.Agent).String()
+	description, err := c.api.state.SkipFailedUpgradeStep(tag)
+	if err != nil {
+		return params.SkipUpgradeStepResult{}, err
+	}
+	return params.SkipUpgradeStepResult{Step: description}, nil
+}
+
+// AbortUpgrade aborts the upgrade under way, as long as its database
+// upgrade steps have not been committed, reverting the environment's
+// agent version so that the agents roll back to their previous tools.
+func (c *Client) AbortUpgrade() (params.AbortUpgradeResult, error) {
+	previousVersion, err := c.api.state.AbortUpgrade()
+	if err != nil {
+		return params.AbortUpgradeResult{}, err
+	}
+	return params.AbortUpgradeResult{Version: previousVersion}, nil
+}
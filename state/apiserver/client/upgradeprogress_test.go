@@ -0,0 +1,156 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/version"
+)
+
+type upgradeProgressSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&upgradeProgressSuite{})
+
+func (s *upgradeProgressSuite) addMachine(c *gc.C, provisioned bool) *state.Machine {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	if provisioned {
+		err = m.SetProvisioned(instance.Id("i-"+m.Id()), "fake_nonce", nil)
+		c.Assert(err, gc.IsNil)
+	}
+	return m
+}
+
+func (s *upgradeProgressSuite) agentVersion(c *gc.C) version.Number {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	agentVersion, ok := cfg.AgentVersion()
+	c.Assert(ok, jc.IsTrue)
+	return agentVersion
+}
+
+func (s *upgradeProgressSuite) TestUpgradeProgress(c *gc.C) {
+	agentVersion := s.agentVersion(c)
+	m0 := s.addMachine(c, true)
+	m1 := s.addMachine(c, true)
+	m2 := s.addMachine(c, true)
+	s.addMachine(c, false)
+
+	steps := []state.UpgradeStep{{
+		Target:      "hostMachine",
+		Description: "step 1",
+		Status:      params.UpgradeStepDone,
+	}, {
+		Target:      "hostMachine",
+		Description: "step 2",
+		Status:      params.UpgradeStepFailed,
+		Error:       "boom",
+	}}
+	err := s.State.SetUpgradeProgress(state.UpgradeProgress{
+		Agent:     m0.Tag().String(),
+		ToVersion: agentVersion,
+		Steps:     steps,
+	})
+	c.Assert(err, gc.IsNil)
+	// Progress of an upgrade to another version is not shown.
+	err = s.State.SetUpgradeProgress(state.UpgradeProgress{
+		Agent:     m1.Tag().String(),
+		ToVersion: version.MustParse("0.0.1"),
+		Steps:     steps,
+		Completed: true,
+	})
+	c.Assert(err, gc.IsNil)
+
+	results, err := s.APIState.Client().UpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 3)
+	c.Assert(results[0].Updated.IsZero(), jc.IsFalse)
+	results[0].Updated = results[1].Updated
+	c.Assert(results, jc.DeepEquals, []params.UpgradeProgress{{
+		Agent:     m0.Tag().String(),
+		ToVersion: agentVersion,
+		Steps: []params.UpgradeStep{{
+			Target:      "hostMachine",
+			Description: "step 1",
+			Status:      params.UpgradeStepDone,
+		}, {
+			Target:      "hostMachine",
+			Description: "step 2",
+			Status:      params.UpgradeStepFailed,
+			Error:       "boom",
+		}},
+	}, {
+		Agent:     m1.Tag().String(),
+		ToVersion: agentVersion,
+	}, {
+		Agent:     m2.Tag().String(),
+		ToVersion: agentVersion,
+	}})
+}
+
+func (s *upgradeProgressSuite) TestSkipUpgradeStep(c *gc.C) {
+	m := s.addMachine(c, true)
+	err := s.State.SetUpgradeProgress(state.UpgradeProgress{
+		Agent:     m.Tag().String(),
+		ToVersion: s.agentVersion(c),
+		Steps: []state.UpgradeStep{{
+			Target:      "hostMachine",
+			Description: "step 1",
+			Status:      params.UpgradeStepFailed,
+			Error:       "boom",
+		}},
+	})
+	c.Assert(err, gc.IsNil)
+
+	step, err := s.APIState.Client().SkipUpgradeStep(m.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(step, gc.Equals, "step 1")
+	progress, err := s.State.UpgradeProgress(m.Tag().String())
+	c.Assert(err, gc.IsNil)
+	c.Assert(progress.Skip, jc.DeepEquals, []string{"step 1"})
+
+	results, err := s.APIState.Client().UpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Skip, jc.DeepEquals, []string{"step 1"})
+}
+
+func (s *upgradeProgressSuite) TestSkipUpgradeStepErrors(c *gc.C) {
+	_, err := s.APIState.Client().SkipUpgradeStep("foo")
+	c.Assert(err, gc.ErrorMatches, `machine id "foo" not valid`)
+	_, err = s.APIState.Client().SkipUpgradeStep("42")
+	c.Assert(err, gc.ErrorMatches, "cannot skip upgrade step of machine-42: upgrade progress not found")
+}
+
+func (s *upgradeProgressSuite) TestAbortUpgrade(c *gc.C) {
+	m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	err = m.SetProvisioned(instance.Id("i-"+m.Id()), "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	from := s.agentVersion(c)
+	to := from
+	to.Minor++
+	err = statetesting.SetAgentVersion(s.State, to)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.EnsureUpgradeInfo(m.Id(), from, to)
+	c.Assert(err, gc.IsNil)
+
+	reverted, err := s.APIState.Client().AbortUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(reverted, gc.Equals, from)
+	c.Assert(s.agentVersion(c), gc.Equals, from)
+}
+
+func (s *upgradeProgressSuite) TestAbortUpgradeNoUpgrade(c *gc.C) {
+	_, err := s.APIState.Client().AbortUpgrade()
+	c.Assert(err, gc.ErrorMatches, "cannot abort upgrade: no upgrade is under way")
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"sync"
+)
+
+// downloadLimiter limits the number of downloads served at once.
+// The limit is given on each acquisition, so that changes to it take
+// effect at once.
+type downloadLimiter struct {
+	mu     sync.Mutex
+	active int
+
+	// released is closed, and replaced, whenever a download
+	// finishes.
+	released chan struct{}
+}
+
+// toolsDownloads limits the tools downloads served by the API server.
+var toolsDownloads = newDownloadLimiter()
+
+func newDownloadLimiter() *downloadLimiter {
+	return &downloadLimiter{released: make(chan struct{})}
+}
+
+// acquire waits until fewer than limit downloads are being served,
+// and reports whether the caller may serve its download, which it must
+// then release. If limit is zero, downloads are not limited. If a
+// value is received on abort while waiting, acquire returns false.
+func (l *downloadLimiter) acquire(limit int, abort <-chan bool) bool {
+	for {
+		l.mu.Lock()
+		if limit <= 0 || l.active < limit {
+			l.active++
+			l.mu.Unlock()
+			return true
+		}
+		released := l.released
+		l.mu.Unlock()
+		select {
+		case <-released:
+		case <-abort:
+			return false
+		}
+	}
+}
+
+// release records that a download has finished.
+func (l *downloadLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	close(l.released)
+	l.released = make(chan struct{})
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+)
+
+type downloadLimiterSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&downloadLimiterSuite{})
+
+func (s *downloadLimiterSuite) TestUnlimited(c *gc.C) {
+	l := newDownloadLimiter()
+	for i := 0; i < 10; i++ {
+		c.Assert(l.acquire(0, nil), jc.IsTrue)
+	}
+}
+
+func (s *downloadLimiterSuite) TestWaitsForRelease(c *gc.C) {
+	l := newDownloadLimiter()
+	c.Assert(l.acquire(2, nil), jc.IsTrue)
+	c.Assert(l.acquire(2, nil), jc.IsTrue)
+	acquired := make(chan bool, 1)
+	go func() {
+		acquired <- l.acquire(2, nil)
+	}()
+	select {
+	case <-acquired:
+		c.Fatalf("download served beyond the limit")
+	case <-time.After(testing.ShortWait):
+	}
+	l.release()
+	select {
+	case ok := <-acquired:
+		c.Assert(ok, jc.IsTrue)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("download not served after another finished")
+	}
+}
+
+func (s *downloadLimiterSuite) TestAbort(c *gc.C) {
+	l := newDownloadLimiter()
+	c.Assert(l.acquire(1, nil), jc.IsTrue)
+	abort := make(chan bool, 1)
+	acquired := make(chan bool, 1)
+	go func() {
+		acquired <- l.acquire(1, abort)
+	}()
+	abort <- true
+	select {
+	case ok := <-acquired:
+		c.Assert(ok, jc.IsFalse)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("waiting download not aborted")
+	}
+	// The aborted download does not count against the limit.
+	l.release()
+	c.Assert(l.acquire(1, nil), jc.IsTrue)
+}
@@ -54,3 +54,10 @@ func TestingSrvRoot(st *state.State) *srvRoot {
 		objectCache: make(map[objectKey]reflect.Value),
 	}
 }
+
+// HoldToolsDownload takes one of the API server's tools download slots
+// until the returned function is called.
+func HoldToolsDownload() (release func()) {
+	toolsDownloads.acquire(0, nil)
+	return toolsDownloads.release
+}
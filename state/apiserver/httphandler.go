@@ -28,7 +28,19 @@ type httpHandler struct {
 
 // authenticate parses HTTP basic authentication and authorizes the
 // request by looking up the provided tag and password against state.
+// Only users are allowed.
 func (h *httpHandler) authenticate(r *http.Request) error {
+	return h.authenticateAs(r, false)
+}
+
+// authenticateAgentOrUser is like authenticate, but also allows machine
+// and unit agents to authenticate. It is used for requests that only
+// read from the environment, such as tools and charm downloads.
+func (h *httpHandler) authenticateAgentOrUser(r *http.Request) error {
+	return h.authenticateAs(r, true)
+}
+
+func (h *httpHandler) authenticateAs(r *http.Request, allowAgents bool) error {
 	parts := strings.Fields(r.Header.Get("Authorization"))
 	if len(parts) != 2 || parts[0] != "Basic" {
 		// Invalid header format or no header provided.
@@ -44,8 +56,17 @@ func (h *httpHandler) authenticate(r *http.Request) error {
 	if len(tagPass) != 2 {
 		return fmt.Errorf("invalid request format")
 	}
-	// Only allow users, not agents.
-	if _, err := names.ParseUserTag(tagPass[0]); err != nil {
+	kind, err := names.TagKind(tagPass[0])
+	if err != nil {
+		return common.ErrBadCreds
+	}
+	switch kind {
+	case names.UserTagKind:
+	case names.MachineTagKind, names.UnitTagKind:
+		if !allowAgents {
+			return common.ErrBadCreds
+		}
+	default:
 		return common.ErrBadCreds
 	}
 	// Ensure the credentials are correct.
@@ -215,6 +215,12 @@ func (p *ProvisionerAPI) ContainerManagerConfig(args params.ContainerManagerConf
 			cfg["use-aufs"] = fmt.Sprint(useLxcCloneAufs)
 		}
 	}
+	switch args.Type {
+	case instance.LXC, instance.KVM, instance.DOCKER:
+		if ratio, ok := config.ContainerOvercommitRatio(); ok {
+			cfg[container.ConfigOvercommitRatio] = fmt.Sprint(ratio)
+		}
+	}
 	result.ManagerConfig = cfg
 	return result, nil
 }
@@ -1151,6 +1151,19 @@ func (s *withoutStateServerSuite) TestContainerManagerConfig(c *gc.C) {
 	})
 }
 
+func (s *withoutStateServerSuite) TestContainerManagerConfigOvercommitRatio(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"container-overcommit-ratio": 1.5,
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	for _, ctype := range []instance.ContainerType{instance.LXC, instance.KVM, instance.DOCKER} {
+		args := params.ContainerManagerConfigParams{Type: ctype}
+		results, err := s.provisioner.ContainerManagerConfig(args)
+		c.Check(err, gc.IsNil)
+		c.Check(results.ManagerConfig[container.ConfigOvercommitRatio], gc.Equals, "1.5")
+	}
+}
+
 func (s *withoutStateServerSuite) TestContainerConfig(c *gc.C) {
 	attrs := map[string]interface{}{
 		"http-proxy": "http://proxy.example.com:9000",
@@ -12,8 +12,12 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/filestorage"
 	"github.com/juju/juju/environs/sync"
@@ -24,13 +28,20 @@ import (
 	"github.com/juju/juju/version"
 )
 
-// toolsHandler handles tool upload through HTTPS in the API server.
+// toolsHandler handles tool upload and download through HTTPS in the
+// API server.
 type toolsHandler struct {
 	httpHandler
+	dataDir string
 }
 
 func (h *toolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if err := h.authenticate(r); err != nil {
+	authenticate := h.authenticate
+	if r.Method == "GET" {
+		// Agents may download tools, but only users may upload them.
+		authenticate = h.authenticateAgentOrUser
+	}
+	if err := authenticate(r); err != nil {
 		h.authError(w, h)
 		return
 	}
@@ -52,6 +63,36 @@ func (h *toolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			Tools: agentTools,
 			DisableSSLHostnameVerification: disableSSLHostnameVerification,
 		})
+	case "GET":
+		envConfig, err := h.state.EnvironConfig()
+		if err != nil {
+			h.sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// Agents beyond the environment's tools download
+		// concurrency wait for others to finish. The limit covers
+		// fetching tools missing from the cache as well as
+		// serving them.
+		var abort <-chan bool
+		if notifier, ok := w.(http.CloseNotifier); ok {
+			abort = notifier.CloseNotify()
+		}
+		if !toolsDownloads.acquire(envConfig.ToolsDownloadConcurrency(), abort) {
+			// The client has gone away.
+			return
+		}
+		defer toolsDownloads.release()
+		// Retrieve a tools tarball, from the cache if possible.
+		// Requires a "binaryVersion" query specifying the tools to get.
+		toolsPath, err := h.processGet(r)
+		if err, ok := err.(*toolsFetchError); ok {
+			h.sendError(w, err.statusCode, err.Error())
+			return
+		} else if err != nil {
+			h.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.sendTools(w, r, toolsPath)
 	default:
 		h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("unsupported method: %q", r.Method))
 	}
@@ -75,6 +116,134 @@ func (h *toolsHandler) sendError(w http.ResponseWriter, statusCode int, message
 	return h.sendJSON(w, statusCode, &params.ToolsResult{Error: err})
 }
 
+// sendTools sends the tools tarball located at toolsPath to the client.
+// Range requests are honoured, so interrupted downloads can be resumed.
+func (h *toolsHandler) sendTools(w http.ResponseWriter, r *http.Request, toolsPath string) {
+	f, err := os.Open(toolsPath)
+	if err != nil {
+		http.Error(
+			w, fmt.Sprintf("unable to read tools in %q: %v", toolsPath, err),
+			http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(
+			w, fmt.Sprintf("unable to read tools in %q: %v", toolsPath, err),
+			http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-tar-gz")
+	http.ServeContent(w, r, filepath.Base(toolsPath), info.ModTime(), f)
+}
+
+// processGet handles a tools GET request after authentication.
+// It returns the path of the cached tools tarball, fetching it from
+// the environment's tools source if it is not already in the cache.
+// Concurrent requests for the same missing tools fetch them once.
+func (h *toolsHandler) processGet(r *http.Request) (string, error) {
+	binaryVersionParam := r.URL.Query().Get("binaryVersion")
+	if binaryVersionParam == "" {
+		return "", fmt.Errorf("expected binaryVersion argument")
+	}
+	toolsVersion, err := version.ParseBinary(binaryVersionParam)
+	if err != nil {
+		return "", fmt.Errorf("invalid tools version %q: %v", binaryVersionParam, err)
+	}
+	toolsPath := filepath.Join(h.dataDir, "tools-get-cache", toolsVersion.String()+".tgz")
+	err = cacheFills.fill(toolsPath, func() error {
+		return h.downloadTools(toolsVersion, toolsPath)
+	})
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err, ok := err.(*toolsFetchError); ok {
+			statusCode = err.statusCode
+		}
+		err = fmt.Errorf("unable to retrieve and save the tools: %v", err)
+		return "", &toolsFetchError{statusCode, err}
+	}
+	return toolsPath, nil
+}
+
+// toolsFetchError holds a failure to fetch tools into the cache, and
+// the HTTP status code it is reported with. Agents only retry downloads
+// that fail with a server error, so failures of the environment's tools
+// source, which may be transient, must not be reported as bad requests.
+type toolsFetchError struct {
+	statusCode int
+	error
+}
+
+// downloadTools fetches the tools with the given version from the
+// environment's tools source and saves them to toolsPath, after checking
+// their size and SHA256 hash against the tools metadata.
+func (h *toolsHandler) downloadTools(toolsVersion version.Binary, toolsPath string) error {
+	envConfig, err := h.state.EnvironConfig()
+	if err != nil {
+		return &toolsFetchError{http.StatusServiceUnavailable, errors.Annotate(err, "cannot get environment config")}
+	}
+	env, err := environs.New(envConfig)
+	if err != nil {
+		return &toolsFetchError{http.StatusServiceUnavailable, errors.Annotate(err, "cannot access environment")}
+	}
+	agentTools, err := envtools.FindExactTools(
+		env, toolsVersion.Number, toolsVersion.Series, toolsVersion.Arch)
+	if errors.IsNotFound(err) {
+		return &toolsFetchError{http.StatusNotFound, err}
+	} else if err != nil {
+		return &toolsFetchError{http.StatusBadGateway, err}
+	}
+	verify := utils.VerifySSLHostnames
+	if !envConfig.SSLHostnameVerification() {
+		verify = utils.NoVerifySSLHostnames
+	}
+	resp, err := utils.GetHTTPClient(verify).Get(agentTools.URL)
+	if err != nil {
+		return &toolsFetchError{http.StatusBadGateway, errors.Annotatef(err, "cannot get %s", agentTools.URL)}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("cannot get %s: bad http response: %v", agentTools.URL, resp.Status)
+		return &toolsFetchError{http.StatusBadGateway, err}
+	}
+
+	// As with charms, the tarball is saved in a temporary file in the
+	// cache directory, which is then atomically renamed.
+	cacheDir := filepath.Dir(toolsPath)
+	if err = os.MkdirAll(cacheDir, 0755); err != nil {
+		return errors.Annotate(err, "cannot create the tools cache")
+	}
+	tempFile, err := ioutil.TempFile(cacheDir, "tools")
+	if err != nil {
+		return errors.Annotate(err, "cannot create tools temp file")
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tempFile, hash), resp.Body)
+	if err != nil {
+		return &toolsFetchError{http.StatusBadGateway, errors.Annotate(err, "error processing tools download")}
+	}
+	if agentTools.Size != 0 && size != agentTools.Size {
+		err := fmt.Errorf("size mismatch: expected %d bytes, got %d", agentTools.Size, size)
+		return &toolsFetchError{http.StatusBadGateway, err}
+	}
+	if agentTools.SHA256 != "" {
+		if sha256sum := fmt.Sprintf("%x", hash.Sum(nil)); sha256sum != agentTools.SHA256 {
+			err := fmt.Errorf("sha256 mismatch: expected %s, got %s", agentTools.SHA256, sha256sum)
+			return &toolsFetchError{http.StatusBadGateway, err}
+		}
+	}
+	if err = tempFile.Close(); err != nil {
+		return errors.Annotate(err, "error processing tools download")
+	}
+	if err = os.Rename(tempFile.Name(), toolsPath); err != nil {
+		return errors.Annotate(err, "error renaming the tools tarball")
+	}
+	return nil
+}
+
 // processPost handles a charm upload POST request after authentication.
 func (h *toolsHandler) processPost(r *http.Request) (*tools.Tools, bool, error) {
 	query := r.URL.Query()
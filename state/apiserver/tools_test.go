@@ -4,13 +4,18 @@
 package apiserver_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
+	"time"
 
+	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
@@ -18,6 +23,8 @@ import (
 	toolstesting "github.com/juju/juju/environs/tools/testing"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver"
+	coretesting "github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
 )
@@ -207,6 +214,134 @@ func (s *toolsSuite) TestUploadFakeSeries(c *gc.C) {
 	}
 }
 
+func (s *toolsSuite) addMachineAgent(c *gc.C) (tag, password string) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetProvisioned("foo", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	password, err = utils.RandomPassword()
+	c.Assert(err, gc.IsNil)
+	err = machine.SetPassword(password)
+	c.Assert(err, gc.IsNil)
+	return machine.Tag().String(), password
+}
+
+func (s *toolsSuite) TestDownloadAllowsAgents(c *gc.C) {
+	tag, password := s.addMachineAgent(c)
+	resp, err := s.sendRequest(c, tag, password, "GET", s.toolsURI(c, ""), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected binaryVersion argument")
+}
+
+func (s *toolsSuite) TestDownloadFetchesAndCachesTools(c *gc.C) {
+	// Upload some fake tools to provider storage.
+	_, vers, toolPath := s.setupToolsForUpload(c)
+	resp, err := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers.String()), true, toolPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+	expectedData, err := ioutil.ReadFile(toolPath)
+	c.Assert(err, gc.IsNil)
+
+	// An agent can download them through the API server.
+	tag, password := s.addMachineAgent(c)
+	uri := s.toolsURI(c, "?binaryVersion="+vers.String())
+	resp, err = s.sendRequest(c, tag, password, "GET", uri, "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertGetFileResponse(c, resp, string(expectedData), "application/x-tar-gz")
+
+	// And a copy is kept in the cache.
+	cachedData, err := ioutil.ReadFile(filepath.Join(
+		s.DataDir(), "tools-get-cache", vers.String()+".tgz"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(cachedData, gc.DeepEquals, expectedData)
+}
+
+func (s *toolsSuite) TestDownloadFetchesWithinConcurrencyLimit(c *gc.C) {
+	_, vers, toolPath := s.setupToolsForUpload(c)
+	resp, err := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers.String()), true, toolPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"tools-download-concurrency": 1,
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+
+	// While another download is being served, tools missing from the
+	// cache are not fetched from provider storage.
+	release := apiserver.HoldToolsDownload()
+	done := make(chan *http.Response)
+	go func() {
+		resp, err := s.authRequest(c, "GET", s.toolsURI(c, "?binaryVersion="+vers.String()), "", nil)
+		c.Check(err, gc.IsNil)
+		done <- resp
+	}()
+	cachePath := filepath.Join(s.DataDir(), "tools-get-cache", vers.String()+".tgz")
+	select {
+	case <-done:
+		c.Fatalf("download served beyond the concurrency limit")
+	case <-time.After(coretesting.ShortWait):
+	}
+	_, err = os.Stat(cachePath)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+
+	release()
+	select {
+	case resp := <-done:
+		c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+		resp.Body.Close()
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("download not served after the limit was lifted")
+	}
+	c.Assert(cachePath, jc.IsNonEmptyFile)
+}
+
+func (s *toolsSuite) TestDownloadUsesCache(c *gc.C) {
+	vers := version.MustParseBinary("1.9.0-quantal-amd64")
+	cacheDir := filepath.Join(s.DataDir(), "tools-get-cache")
+	err := os.MkdirAll(cacheDir, 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(cacheDir, vers.String()+".tgz"), []byte("cached"), 0644)
+	c.Assert(err, gc.IsNil)
+
+	resp, err := s.authRequest(c, "GET", s.toolsURI(c, "?binaryVersion="+vers.String()), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertGetFileResponse(c, resp, "cached", "application/x-tar-gz")
+}
+
+func (s *toolsSuite) TestDownloadMissingTools(c *gc.C) {
+	vers := version.MustParseBinary("1.9.0-quantal-amd64")
+	resp, err := s.authRequest(c, "GET", s.toolsURI(c, "?binaryVersion="+vers.String()), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(
+		c, resp, http.StatusNotFound,
+		"unable to retrieve and save the tools: .*")
+}
+
+func (s *toolsSuite) TestDownloadRejectsChecksumMismatch(c *gc.C) {
+	// Upload some fake tools, then corrupt them in provider storage.
+	_, vers, toolPath := s.setupToolsForUpload(c)
+	resp, err := s.uploadRequest(
+		c, s.toolsURI(c, "?binaryVersion="+vers.String()), true, toolPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+	data, err := ioutil.ReadFile(toolPath)
+	c.Assert(err, gc.IsNil)
+	data[len(data)-1]++
+	err = s.Conn.Environ.Storage().Put(
+		tools.StorageName(vers), bytes.NewReader(data), int64(len(data)))
+	c.Assert(err, gc.IsNil)
+
+	resp, err = s.authRequest(c, "GET", s.toolsURI(c, "?binaryVersion="+vers.String()), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(
+		c, resp, http.StatusBadGateway,
+		"unable to retrieve and save the tools: sha256 mismatch: .*")
+	_, err = os.Stat(filepath.Join(s.DataDir(), "tools-get-cache", vers.String()+".tgz"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
 func (s *toolsSuite) toolsURL(c *gc.C, query string) *url.URL {
 	uri := s.baseURL(c)
 	uri.Path += "/tools"
@@ -6,8 +6,6 @@
 package uniter
 
 import (
-	"fmt"
-
 	"github.com/juju/charm"
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -308,6 +306,9 @@ func (u *UniterAPI) HasSubordinates(args params.Entities) (params.BoolResults, e
 }
 
 // CharmURL returns the charm URL for all given units or services.
+// The charm URL returned for a service is the one the authenticated
+// unit should be running, which differs from the service's charm when
+// the unit is waiting to be upgraded by a rolling charm upgrade.
 func (u *UniterAPI) CharmURL(args params.Entities) (params.StringBoolResults, error) {
 	result := params.StringBoolResults{
 		Results: make([]params.StringBoolResult, len(args.Entities)),
@@ -323,10 +324,9 @@ func (u *UniterAPI) CharmURL(args params.Entities) (params.StringBoolResults, er
 			var unitOrService state.Entity
 			unitOrService, err = u.st.FindEntity(entity.Tag)
 			if err == nil {
-				charmURLer := unitOrService.(interface {
-					CharmURL() (*charm.URL, bool)
-				})
-				curl, ok := charmURLer.CharmURL()
+				var curl *charm.URL
+				var ok bool
+				curl, ok, err = u.charmURL(unitOrService)
 				if curl != nil {
 					result.Results[i].Result = curl.String()
 					result.Results[i].Ok = ok
@@ -338,6 +338,27 @@ func (u *UniterAPI) CharmURL(args params.Entities) (params.StringBoolResults, er
 	return result, nil
 }
 
+// charmURL returns the charm URL of the given unit or service, as seen
+// by the authenticated unit.
+func (u *UniterAPI) charmURL(unitOrService state.Entity) (*charm.URL, bool, error) {
+	service, ok := unitOrService.(*state.Service)
+	if !ok {
+		curl, ok := unitOrService.(interface {
+			CharmURL() (*charm.URL, bool)
+		}).CharmURL()
+		return curl, ok, nil
+	}
+	unit, err := u.getUnit(u.auth.GetAuthTag().String())
+	if errors.IsNotFound(err) {
+		curl, force := service.CharmURL()
+		return curl, force, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	curl, force := service.CharmURLForUnit(unit)
+	return curl, force, nil
+}
+
 // SetCharmURL sets the charm URL for each given unit. An error will
 // be returned if a unit is dead, or the charm URL is not know.
 func (u *UniterAPI) SetCharmURL(args params.EntitiesCharmURL) (params.ErrorResults, error) {
@@ -366,6 +387,192 @@ func (u *UniterAPI) SetCharmURL(args params.EntitiesCharmURL) (params.ErrorResul
 	return result, nil
 }
 
+// SetHookLockWait records that each given unit is waiting for its
+// machine's hook execution lock.
+func (u *UniterAPI) SetHookLockWait(args params.HookLockWaits) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Waits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, wait := range args.Waits {
+		err := common.ErrPerm
+		if canAccess(wait.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(wait.Tag)
+			if err == nil {
+				err = unit.SetHookLockWait(state.HookLockWait{
+					Holder:     wait.Holder,
+					HolderHook: wait.HolderHook,
+					Hook:       wait.Hook,
+					Queued:     wait.Queued,
+					Since:      wait.Since,
+				})
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// ClearHookLockWait records that each given unit is no longer waiting
+// for its machine's hook execution lock.
+func (u *UniterAPI) ClearHookLockWait(args params.Entities) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = unit.ClearHookLockWait()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (u *UniterAPI) watchOneUnitSchedules(tag string) (string, error) {
+	unit, err := u.getUnit(tag)
+	if err != nil {
+		return "", err
+	}
+	watch := unit.WatchSchedules()
+	// Consume the initial event.
+	if _, ok := <-watch.Changes(); ok {
+		return u.resources.Register(watch), nil
+	}
+	return "", watcher.MustErr(watch)
+}
+
+// WatchSchedules returns a NotifyWatcher for observing changes to the
+// schedules that apply to each given unit.
+func (u *UniterAPI) WatchSchedules(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.NotifyWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		watcherId := ""
+		if canAccess(entity.Tag) {
+			watcherId, err = u.watchOneUnitSchedules(entity.Tag)
+		}
+		result.Results[i].NotifyWatcherId = watcherId
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// Schedules returns the schedules whose commands should be run on each
+// given unit.
+func (u *UniterAPI) Schedules(args params.Entities) (params.UnitSchedulesResults, error) {
+	result := params.UnitSchedulesResults{
+		Results: make([]params.UnitSchedulesResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.UnitSchedulesResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				var schedules []*state.Schedule
+				schedules, err = unit.Schedules()
+				for _, schedule := range schedules {
+					result.Results[i].Schedules = append(result.Results[i].Schedules, params.UnitSchedule{
+						Id:      schedule.Id(),
+						Spec:    schedule.Spec(),
+						Command: schedule.Command(),
+						Paused:  schedule.Paused(),
+					})
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetScheduleRuns records the results of running scheduled commands
+// on each given unit.
+func (u *UniterAPI) SetScheduleRuns(args params.ScheduleRuns) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Runs)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, run := range args.Runs {
+		err := common.ErrPerm
+		if canAccess(run.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(run.Tag)
+			if err == nil {
+				err = unit.SetScheduleRun(run.ScheduleId, state.ScheduleRun{
+					Time:   run.Time,
+					Code:   run.Code,
+					Output: run.Output,
+					Error:  run.Error,
+				})
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// Volumes returns the block storage volumes of each given unit.
+func (u *UniterAPI) Volumes(args params.Entities) (params.UnitVolumesResults, error) {
+	result := params.UnitVolumesResults{
+		Results: make([]params.UnitVolumesResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.UnitVolumesResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				var volumes []*state.Volume
+				volumes, err = unit.Volumes()
+				for _, volume := range volumes {
+					status, info := volume.Status()
+					result.Results[i].Volumes = append(result.Results[i].Volumes, params.UnitVolume{
+						Name:       volume.Name(),
+						Pool:       volume.Pool(),
+						Size:       volume.Size(),
+						Status:     string(status),
+						StatusInfo: info,
+						Device:     volume.Device(),
+					})
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // OpenPort sets the policy of the port with protocol an number to be
 // opened, for all given units.
 func (u *UniterAPI) OpenPort(args params.EntitiesPorts) (params.ErrorResults, error) {
@@ -822,17 +1029,15 @@ func (u *UniterAPI) LeaveScope(args params.RelationUnits) (params.ErrorResults,
 	return result, nil
 }
 
-func convertRelationSettings(settings map[string]interface{}) (params.RelationSettings, error) {
+// convertRelationSettings returns the given settings as
+// params.RelationSettings. Values are usually strings, but structured
+// values set by charms are passed through unchanged.
+func convertRelationSettings(settings map[string]interface{}) params.RelationSettings {
 	result := make(params.RelationSettings)
 	for k, v := range settings {
-		// All relation settings should be strings.
-		sval, ok := v.(string)
-		if !ok {
-			return nil, fmt.Errorf("unexpected relation setting %q: expected string, got %T", k, v)
-		}
-		result[k] = sval
+		result[k] = v
 	}
-	return result, nil
+	return result
 }
 
 // ReadSettings returns the local settings of each given set of
@@ -851,7 +1056,7 @@ func (u *UniterAPI) ReadSettings(args params.RelationUnits) (params.RelationSett
 			var settings *state.Settings
 			settings, err = relUnit.Settings()
 			if err == nil {
-				result.Results[i].Settings, err = convertRelationSettings(settings.Map())
+				result.Results[i].Settings = convertRelationSettings(settings.Map())
 			}
 		}
 		result.Results[i].Error = common.ServerError(err)
@@ -859,6 +1064,97 @@ func (u *UniterAPI) ReadSettings(args params.RelationUnits) (params.RelationSett
 	return result, nil
 }
 
+// RelationPrivateAddress returns the private address of each given
+// unit in the given relation. If the unit's relation endpoint is bound
+// to a network, the unit's address on that network is returned.
+func (u *UniterAPI) RelationPrivateAddress(args params.RelationUnits) (params.StringResults, error) {
+	result := params.StringResults{
+		Results: make([]params.StringResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			address, ok := relUnit.PrivateAddress()
+			if ok {
+				result.Results[i].Result = address
+			} else {
+				err = common.NoAddressSetError(arg.Unit, "private")
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// RelationNetwork returns details of the network that each given
+// unit's relation endpoint is bound to.
+func (u *UniterAPI) RelationNetwork(args params.RelationUnits) (params.RelationNetworkResults, error) {
+	result := params.RelationNetworkResults{
+		Results: make([]params.RelationNetworkResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.RelationNetworkResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		rel, unit, err := u.getRelationAndUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			result.Results[i].Network, err = u.relationNetwork(rel, unit)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (u *UniterAPI) relationNetwork(rel *state.Relation, unit *state.Unit) (params.RelationNetwork, error) {
+	relUnit, err := rel.Unit(unit)
+	if err != nil {
+		return params.RelationNetwork{}, err
+	}
+	networkName, bound, err := relUnit.NetworkBinding()
+	if err != nil {
+		return params.RelationNetwork{}, err
+	}
+	if !bound {
+		return params.RelationNetwork{}, errors.NotFoundf("network binding for endpoint %q", relUnit.Endpoint().Name)
+	}
+	result := params.RelationNetwork{NetworkName: networkName}
+	result.Address, _ = relUnit.PrivateAddress()
+	network, err := u.st.Network(networkName)
+	if err == nil {
+		result.ProviderId = string(network.ProviderId())
+		result.CIDR = network.CIDR()
+		result.VLANTag = network.VLANTag()
+	} else if !errors.IsNotFound(err) {
+		return params.RelationNetwork{}, err
+	}
+	machineId, err := unit.AssignedMachineId()
+	if state.IsNotAssigned(err) {
+		return result, nil
+	} else if err != nil {
+		return params.RelationNetwork{}, err
+	}
+	machine, err := u.st.Machine(machineId)
+	if err != nil {
+		return params.RelationNetwork{}, err
+	}
+	ifaces, err := machine.NetworkInterfaces()
+	if err != nil {
+		return params.RelationNetwork{}, err
+	}
+	for _, iface := range ifaces {
+		if iface.NetworkName() == networkName {
+			result.InterfaceName = iface.InterfaceName()
+			break
+		}
+	}
+	return result, nil
+}
+
 func (u *UniterAPI) checkRemoteUnit(relUnit *state.RelationUnit, remoteUnitTag string) (string, error) {
 	// Make sure the unit is indeed remote.
 	if remoteUnitTag == u.auth.GetAuthTag().String() {
@@ -902,7 +1198,7 @@ func (u *UniterAPI) ReadRemoteSettings(args params.RelationUnitPairs) (params.Re
 				var settings map[string]interface{}
 				settings, err = relUnit.ReadSettings(remoteUnit)
 				if err == nil {
-					result.Results[i].Settings, err = convertRelationSettings(settings)
+					result.Results[i].Settings = convertRelationSettings(settings)
 				}
 			}
 		}
@@ -929,12 +1225,15 @@ func (u *UniterAPI) UpdateSettings(args params.RelationUnitsSettings) (params.Er
 			settings, err = relUnit.Settings()
 			if err == nil {
 				for k, v := range arg.Settings {
-					if v == "" {
+					if v == nil || v == "" {
 						settings.Delete(k)
 					} else {
 						settings.Set(k, v)
 					}
 				}
+				err = params.RelationSettings(settings.Map()).CheckSize()
+			}
+			if err == nil {
 				_, err = settings.Write()
 			}
 		}
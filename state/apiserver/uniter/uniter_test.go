@@ -4,11 +4,14 @@
 package uniter_test
 
 import (
+	"strings"
 	stdtesting "testing"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
+	"gopkg.in/mgo.v2/bson"
 	gc "launchpad.net/gocheck"
 
 	envtesting "github.com/juju/juju/environs/testing"
@@ -653,6 +656,178 @@ func (s *uniterSuite) TestSetCharmURL(c *gc.C) {
 	c.Assert(ok, jc.IsTrue)
 }
 
+func (s *uniterSuite) TestSetClearHookLockWait(c *gc.C) {
+	since := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	args := params.HookLockWaits{Waits: []params.HookLockWait{
+		{Tag: "unit-mysql-0", Holder: "wordpress/0", Hook: "install", Since: since},
+		{Tag: "unit-wordpress-0", Holder: "mysql/0", HolderHook: "start", Hook: "install", Queued: []string{"db-relation-joined"}, Since: since},
+		{Tag: "unit-foo-42", Holder: "mysql/0", Hook: "install", Since: since},
+	}}
+	result, err := s.uniter.SetHookLockWait(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	wait, err := s.wordpressUnit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait, gc.NotNil)
+	c.Assert(wait.Holder, gc.Equals, "mysql/0")
+	c.Assert(wait.HolderHook, gc.Equals, "start")
+	c.Assert(wait.Queued, gc.DeepEquals, []string{"db-relation-joined"})
+	c.Assert(wait.Since.Equal(since), jc.IsTrue)
+
+	clearArgs := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err = s.uniter.ClearHookLockWait(clearArgs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	wait, err = s.wordpressUnit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait, gc.IsNil)
+}
+
+func (s *uniterSuite) TestSchedules(c *gc.C) {
+	schedule, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSchedule("mysql", "@hourly", "df")
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.Schedules(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, jc.DeepEquals, params.UnitSchedulesResults{
+		Results: []params.UnitSchedulesResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Schedules: []params.UnitSchedule{{
+				Id:      schedule.Id(),
+				Spec:    "@hourly",
+				Command: "ls",
+			}}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) TestVolumes(c *gc.C) {
+	service := s.AddTestingService(c, "storage", s.AddTestingCharm(c, "mysql"))
+	err := service.SetStorageDirectives(map[string]state.StorageDirective{
+		"data": {Pool: "loop", Size: 1024},
+	})
+	c.Assert(err, gc.IsNil)
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	volumes, err := unit.Volumes()
+	c.Assert(err, gc.IsNil)
+	err = volumes[0].SetProvisioned("vol-0", 2048)
+	c.Assert(err, gc.IsNil)
+	err = volumes[0].SetAttached("inst-0", "/dev/loop0")
+	c.Assert(err, gc.IsNil)
+
+	storageAuthorizer := s.authorizer
+	storageAuthorizer.Tag = unit.Tag()
+	storageUniter, err := uniter.NewUniterAPI(s.State, s.resources, storageAuthorizer)
+	c.Assert(err, gc.IsNil)
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-wordpress-0"},
+		{Tag: unit.Tag().String()},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := storageUniter.Volumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, jc.DeepEquals, params.UnitVolumesResults{
+		Results: []params.UnitVolumesResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Volumes: []params.UnitVolume{{
+				Name:   "data",
+				Pool:   "loop",
+				Size:   2048,
+				Status: "attached",
+				Device: "/dev/loop0",
+			}}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) TestWatchSchedules(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.WatchSchedules(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.NotifyWatchResults{
+		Results: []params.NotifyWatchResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{NotifyWatcherId: "1"},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the resource was registered and stop when done
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	_, err = s.State.AddSchedule("wordpress/0", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
+
+func (s *uniterSuite) TestSetScheduleRuns(c *gc.C) {
+	schedule, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	when := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	args := params.ScheduleRuns{Runs: []params.ScheduleRun{
+		{Tag: "unit-mysql-0", ScheduleId: schedule.Id(), Time: when},
+		{Tag: "unit-wordpress-0", ScheduleId: schedule.Id(), Time: when, Code: 1, Output: "oops"},
+		{Tag: "unit-wordpress-0", ScheduleId: "42", Time: when},
+		{Tag: "unit-foo-42", ScheduleId: schedule.Id(), Time: when},
+	}}
+	result, err := s.uniter.SetScheduleRuns(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{&params.Error{
+				Message: `cannot set result of schedule "42" for unit "wordpress/0": schedule "42" not found`,
+			}},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	runs, err := schedule.Runs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(runs, gc.HasLen, 1)
+	c.Assert(runs[0].Unit, gc.Equals, "wordpress/0")
+	c.Assert(runs[0].Code, gc.Equals, 1)
+	c.Assert(runs[0].Output, gc.Equals, "oops")
+}
+
 func (s *uniterSuite) TestOpenPort(c *gc.C) {
 	openedPorts := s.wordpressUnit.OpenedPorts()
 	c.Assert(openedPorts, gc.HasLen, 0)
@@ -1143,13 +1318,117 @@ func (s *uniterSuite) TestReadSettings(c *gc.C) {
 	})
 }
 
-func (s *uniterSuite) TestReadSettingsWithNonStringValuesFails(c *gc.C) {
+// setUpBoundUnit adds a unit of a service whose "db" endpoint is
+// bound to the "dbnet" network, related to mysql, and returns a uniter
+// API authorized as that unit.
+func (s *uniterSuite) setUpBoundUnit(c *gc.C) (*uniter.UniterAPI, *state.Relation, *state.Unit) {
+	_, err := s.State.AddNetwork(state.NetworkInfo{"dbnet", "provider-dbnet", "10.1.0.0/16", 42})
+	c.Assert(err, gc.IsNil)
+	service := s.AddTestingServiceWithNetworks(c, "bound", s.wpCharm, []string{"dbnet"})
+	err = service.SetEndpointBindings(map[string]string{"db": "dbnet"})
+	c.Assert(err, gc.IsNil)
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(s.machine1)
+	c.Assert(err, gc.IsNil)
+	err = s.machine1.SetAddresses(
+		network.NewAddress("192.168.0.1", network.ScopeCloudLocal),
+		network.NewAddress("10.1.0.5", network.ScopeCloudLocal),
+	)
+	c.Assert(err, gc.IsNil)
+	_, err = s.machine1.AddNetworkInterface(state.NetworkInterfaceInfo{
+		MACAddress:    "aa:bb:cc:dd:ee:f0",
+		InterfaceName: "eth1.42",
+		NetworkName:   "dbnet",
+	})
+	c.Assert(err, gc.IsNil)
+	rel := s.addRelation(c, "bound", "mysql")
+
+	authorizer := s.authorizer
+	authorizer.Tag = unit.Tag()
+	authorizer.Entity = unit
+	api, err := uniter.NewUniterAPI(s.State, s.resources, authorizer)
+	c.Assert(err, gc.IsNil)
+	return api, rel, unit
+}
+
+func (s *uniterSuite) TestRelationPrivateAddress(c *gc.C) {
+	api, boundRel, _ := s.setUpBoundUnit(c)
+	rel := s.addRelation(c, "wordpress", "mysql")
+	err := s.machine0.SetAddresses(network.NewAddress("1.2.3.4", network.ScopeCloudLocal))
+	c.Assert(err, gc.IsNil)
+
+	// The wordpress unit is not bound, so it gets its usual address.
+	args := params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: rel.Tag().String(), Unit: "unit-wordpress-0"},
+		{Relation: rel.Tag().String(), Unit: "unit-mysql-0"},
+		{Relation: "relation-42", Unit: "unit-wordpress-0"},
+	}}
+	result, err := s.uniter.RelationPrivateAddress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StringResults{
+		Results: []params.StringResult{
+			{Result: "1.2.3.4"},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// The bound unit gets its address on the bound network.
+	args = params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: boundRel.Tag().String(), Unit: "unit-bound-0"},
+	}}
+	result, err = api.RelationPrivateAddress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StringResults{
+		Results: []params.StringResult{{Result: "10.1.0.5"}},
+	})
+}
+
+func (s *uniterSuite) TestRelationNetwork(c *gc.C) {
+	api, boundRel, _ := s.setUpBoundUnit(c)
+	rel := s.addRelation(c, "wordpress", "mysql")
+
+	args := params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: rel.Tag().String(), Unit: "unit-wordpress-0"},
+		{Relation: rel.Tag().String(), Unit: "unit-mysql-0"},
+	}}
+	result, err := s.uniter.RelationNetwork(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.RelationNetworkResults{
+		Results: []params.RelationNetworkResult{
+			{Error: apiservertesting.NotFoundError(`network binding for endpoint "db"`)},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	args = params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: boundRel.Tag().String(), Unit: "unit-bound-0"},
+	}}
+	result, err = api.RelationNetwork(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.RelationNetworkResults{
+		Results: []params.RelationNetworkResult{{
+			Network: params.RelationNetwork{
+				NetworkName:   "dbnet",
+				ProviderId:    "provider-dbnet",
+				CIDR:          "10.1.0.0/16",
+				VLANTag:       42,
+				InterfaceName: "eth1.42",
+				Address:       "10.1.0.5",
+			},
+		}},
+	})
+}
+
+func (s *uniterSuite) TestReadSettingsWithNonStringValues(c *gc.C) {
 	rel := s.addRelation(c, "wordpress", "mysql")
 	relUnit, err := rel.Unit(s.wordpressUnit)
 	c.Assert(err, gc.IsNil)
 	settings := map[string]interface{}{
-		"other":        "things",
-		"invalid-bool": false,
+		"other": "things",
+		"bool":  false,
+		"list":  []interface{}{"a", "b"},
 	}
 	err = relUnit.EnterScope(settings)
 	c.Assert(err, gc.IsNil)
@@ -1158,12 +1437,11 @@ func (s *uniterSuite) TestReadSettingsWithNonStringValuesFails(c *gc.C) {
 	args := params.RelationUnits{RelationUnits: []params.RelationUnit{
 		{Relation: rel.Tag().String(), Unit: "unit-wordpress-0"},
 	}}
-	expectErr := `unexpected relation setting "invalid-bool": expected string, got bool`
 	result, err := s.uniter.ReadSettings(args)
 	c.Assert(err, gc.IsNil)
 	c.Assert(result, gc.DeepEquals, params.RelationSettingsResults{
 		Results: []params.RelationSettingsResult{
-			{Error: &params.Error{Message: expectErr}},
+			{Settings: params.RelationSettings(settings)},
 		},
 	})
 }
@@ -1257,13 +1535,14 @@ func (s *uniterSuite) TestReadRemoteSettings(c *gc.C) {
 	c.Assert(result, gc.DeepEquals, expect)
 }
 
-func (s *uniterSuite) TestReadRemoteSettingsWithNonStringValuesFails(c *gc.C) {
+func (s *uniterSuite) TestReadRemoteSettingsWithNonStringValues(c *gc.C) {
 	rel := s.addRelation(c, "wordpress", "mysql")
 	relUnit, err := rel.Unit(s.mysqlUnit)
 	c.Assert(err, gc.IsNil)
 	settings := map[string]interface{}{
-		"other":        "things",
-		"invalid-bool": false,
+		"other": "things",
+		"bool":  false,
+		"list":  []interface{}{"a", "b"},
 	}
 	err = relUnit.EnterScope(settings)
 	c.Assert(err, gc.IsNil)
@@ -1274,12 +1553,11 @@ func (s *uniterSuite) TestReadRemoteSettingsWithNonStringValuesFails(c *gc.C) {
 		LocalUnit:  "unit-wordpress-0",
 		RemoteUnit: "unit-mysql-0",
 	}}}
-	expectErr := `unexpected relation setting "invalid-bool": expected string, got bool`
 	result, err := s.uniter.ReadRemoteSettings(args)
 	c.Assert(err, gc.IsNil)
 	c.Assert(result, gc.DeepEquals, params.RelationSettingsResults{
 		Results: []params.RelationSettingsResult{
-			{Error: &params.Error{Message: expectErr}},
+			{Settings: params.RelationSettings(settings)},
 		},
 	})
 }
@@ -1338,6 +1616,59 @@ func (s *uniterSuite) TestUpdateSettings(c *gc.C) {
 	})
 }
 
+func (s *uniterSuite) TestUpdateSettingsStructuredValues(c *gc.C) {
+	rel := s.addRelation(c, "wordpress", "mysql")
+	relUnit, err := rel.Unit(s.wordpressUnit)
+	c.Assert(err, gc.IsNil)
+	err = relUnit.EnterScope(map[string]interface{}{"some": "settings"})
+	c.Assert(err, gc.IsNil)
+
+	args := params.RelationUnitsSettings{RelationUnits: []params.RelationUnitSettings{{
+		Relation: rel.Tag().String(),
+		Unit:     "unit-wordpress-0",
+		Settings: params.RelationSettings{
+			"some":   nil,
+			"nested": map[string]interface{}{"port": 8080},
+		},
+	}}}
+	result, err := s.uniter.UpdateSettings(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{nil}},
+	})
+
+	readSettings, err := relUnit.ReadSettings(s.wordpressUnit.Name())
+	c.Assert(err, gc.IsNil)
+	c.Assert(readSettings, gc.DeepEquals, map[string]interface{}{
+		"nested": bson.M{"port": 8080},
+	})
+}
+
+func (s *uniterSuite) TestUpdateSettingsTooLarge(c *gc.C) {
+	rel := s.addRelation(c, "wordpress", "mysql")
+	relUnit, err := rel.Unit(s.wordpressUnit)
+	c.Assert(err, gc.IsNil)
+	err = relUnit.EnterScope(map[string]interface{}{"some": "settings"})
+	c.Assert(err, gc.IsNil)
+
+	args := params.RelationUnitsSettings{RelationUnits: []params.RelationUnitSettings{{
+		Relation: rel.Tag().String(),
+		Unit:     "unit-wordpress-0",
+		Settings: params.RelationSettings{
+			"big": strings.Repeat("x", params.MaxRelationSettingsSize),
+		},
+	}}}
+	result, err := s.uniter.UpdateSettings(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.ErrorMatches, "relation settings too large: .*")
+
+	// Nothing was written.
+	readSettings, err := relUnit.ReadSettings(s.wordpressUnit.Name())
+	c.Assert(err, gc.IsNil)
+	c.Assert(readSettings, gc.DeepEquals, map[string]interface{}{"some": "settings"})
+}
+
 func (s *uniterSuite) TestWatchRelationUnits(c *gc.C) {
 	// Add a relation between wordpress and mysql and enter scope with
 	// mysqlUnit.
@@ -0,0 +1,44 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgrader
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// AbortedUpgradeGetter implements the AbortedUpgrade method shared by
+// the upgrader facades.
+type AbortedUpgradeGetter struct {
+	st *state.State
+}
+
+// NewAbortedUpgradeGetter returns a new AbortedUpgradeGetter.
+func NewAbortedUpgradeGetter(st *state.State) *AbortedUpgradeGetter {
+	return &AbortedUpgradeGetter{st: st}
+}
+
+// AbortedUpgrade reports whether the most recent upgrade of the
+// environment was aborted, and if so, the versions it was between.
+// Agents use it to tell an aborted upgrade, after which they should
+// switch back to their previous tools, from a desired version that is
+// only transiently out of date.
+func (g *AbortedUpgradeGetter) AbortedUpgrade() (params.AbortedUpgradeResult, error) {
+	info, err := g.st.UpgradeInfo()
+	if errors.IsNotFound(err) {
+		return params.AbortedUpgradeResult{}, nil
+	} else if err != nil {
+		return params.AbortedUpgradeResult{}, err
+	}
+	if info.Status() != state.UpgradeAborted {
+		return params.AbortedUpgradeResult{}, nil
+	}
+	return params.AbortedUpgradeResult{
+		Aborted: true,
+		From:    info.PreviousVersion(),
+		To:      info.TargetVersion(),
+	}, nil
+}
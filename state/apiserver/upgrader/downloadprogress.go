@@ -0,0 +1,99 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgrader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/version"
+)
+
+// ToolsDownloadProgressSetter implements the SetToolsDownloadProgress
+// method shared by the upgrader facades.
+type ToolsDownloadProgressSetter struct {
+	st           state.EntityFinder
+	getCanModify common.GetAuthFunc
+}
+
+// NewToolsDownloadProgressSetter returns a new
+// ToolsDownloadProgressSetter. The GetAuthFunc will be used on each
+// invocation of SetToolsDownloadProgress to determine current
+// permissions.
+func NewToolsDownloadProgressSetter(st state.EntityFinder, getCanModify common.GetAuthFunc) *ToolsDownloadProgressSetter {
+	return &ToolsDownloadProgressSetter{
+		st:           st,
+		getCanModify: getCanModify,
+	}
+}
+
+// SetToolsDownloadProgress reports the progress of each given agent's
+// download of new tools in the status info of its entity. The progress
+// is only reported while the entity is started, so that it does not
+// hide any other status, and it is cleared when the download is done.
+func (s *ToolsDownloadProgressSetter) SetToolsDownloadProgress(args params.ToolsDownloadProgressArgs) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Agents)),
+	}
+	if len(args.Agents) == 0 {
+		return results, nil
+	}
+	canModify, err := s.getCanModify()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, progress := range args.Agents {
+		err := common.ErrPerm
+		if canModify(progress.Tag) {
+			err = s.setOneProgress(progress)
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (s *ToolsDownloadProgressSetter) setOneProgress(progress params.ToolsDownloadProgress) error {
+	entity0, err := s.st.FindEntity(progress.Tag)
+	if err != nil {
+		return err
+	}
+	entity, ok := entity0.(interface {
+		state.StatusGetter
+		state.StatusSetter
+	})
+	if !ok {
+		return common.NotSupportedError(progress.Tag, "reporting download progress")
+	}
+	status, info, data, err := entity.Status()
+	if err != nil {
+		return err
+	}
+	prefix := downloadProgressPrefix(progress.Version)
+	if progress.Done {
+		// Only the progress reported earlier is cleared; any
+		// status set since then is left alone.
+		if !strings.HasPrefix(info, prefix) {
+			return nil
+		}
+		return entity.SetStatus(status, "", data)
+	}
+	if status != params.StatusStarted {
+		return nil
+	}
+	if progress.Total <= 0 {
+		info = fmt.Sprintf("%s%d bytes received", prefix, progress.Received)
+	} else {
+		info = fmt.Sprintf("%s%d%% of %d bytes", prefix, progress.Received*100/progress.Total, progress.Total)
+	}
+	return entity.SetStatus(status, info, data)
+}
+
+// downloadProgressPrefix returns the start of the status info that
+// describes the progress of a download of the given tools.
+func downloadProgressPrefix(vers version.Binary) string {
+	return fmt.Sprintf("downloading tools %s: ", vers)
+}
@@ -18,6 +18,8 @@ import (
 // UnitUpgraderAPI provides access to the UnitUpgrader API facade.
 type UnitUpgraderAPI struct {
 	*common.ToolsSetter
+	*ToolsDownloadProgressSetter
+	*AbortedUpgradeGetter
 
 	st         *state.State
 	resources  *common.Resources
@@ -38,10 +40,12 @@ func NewUnitUpgraderAPI(
 		return authorizer.AuthOwner, nil
 	}
 	return &UnitUpgraderAPI{
-		ToolsSetter: common.NewToolsSetter(st, getCanWrite),
-		st:          st,
-		resources:   resources,
-		authorizer:  authorizer,
+		ToolsSetter:                 common.NewToolsSetter(st, getCanWrite),
+		ToolsDownloadProgressSetter: NewToolsDownloadProgressSetter(st, getCanWrite),
+		AbortedUpgradeGetter:        NewAbortedUpgradeGetter(st),
+		st:                          st,
+		resources:                   resources,
+		authorizer:                  authorizer,
 	}, nil
 }
 
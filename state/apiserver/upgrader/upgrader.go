@@ -53,12 +53,16 @@ type Upgrader interface {
 	DesiredVersion(args params.Entities) (params.VersionResults, error)
 	Tools(args params.Entities) (params.ToolsResults, error)
 	SetTools(args params.EntitiesVersion) (params.ErrorResults, error)
+	SetToolsDownloadProgress(args params.ToolsDownloadProgressArgs) (params.ErrorResults, error)
+	AbortedUpgrade() (params.AbortedUpgradeResult, error)
 }
 
 // UpgraderAPI provides access to the Upgrader API facade.
 type UpgraderAPI struct {
 	*common.ToolsGetter
 	*common.ToolsSetter
+	*ToolsDownloadProgressSetter
+	*AbortedUpgradeGetter
 
 	st         *state.State
 	resources  *common.Resources
@@ -78,11 +82,13 @@ func NewUpgraderAPI(
 		return authorizer.AuthOwner, nil
 	}
 	return &UpgraderAPI{
-		ToolsGetter: common.NewToolsGetter(st, getCanReadWrite),
-		ToolsSetter: common.NewToolsSetter(st, getCanReadWrite),
-		st:          st,
-		resources:   resources,
-		authorizer:  authorizer,
+		ToolsGetter:                 common.NewToolsGetter(st, getCanReadWrite),
+		ToolsSetter:                 common.NewToolsSetter(st, getCanReadWrite),
+		ToolsDownloadProgressSetter: NewToolsDownloadProgressSetter(st, getCanReadWrite),
+		AbortedUpgradeGetter:        NewAbortedUpgradeGetter(st),
+		st:                          st,
+		resources:                   resources,
+		authorizer:                  authorizer,
 	}, nil
 }
 
@@ -233,6 +233,106 @@ func (s *upgraderSuite) TestSetTools(c *gc.C) {
 	c.Check(realTools.URL, gc.Equals, "")
 }
 
+func (s *upgraderSuite) setToolsDownloadProgress(c *gc.C, received, total int64) {
+	args := params.ToolsDownloadProgressArgs{
+		Agents: []params.ToolsDownloadProgress{{
+			Tag:      s.rawMachine.Tag().String(),
+			Version:  version.MustParseBinary("5.4.3-precise-amd64"),
+			Received: received,
+			Total:    total,
+		}},
+	}
+	results, err := s.upgrader.SetToolsDownloadProgress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+}
+
+func (s *upgraderSuite) assertMachineStatus(c *gc.C, expectStatus params.Status, expectInfo string) {
+	status, info, _, err := s.rawMachine.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, expectStatus)
+	c.Assert(info, gc.Equals, expectInfo)
+}
+
+func (s *upgraderSuite) TestSetToolsDownloadProgress(c *gc.C) {
+	err := s.rawMachine.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	s.setToolsDownloadProgress(c, 100, 400)
+	s.assertMachineStatus(c, params.StatusStarted, "downloading tools 5.4.3-precise-amd64: 25% of 400 bytes")
+	s.setToolsDownloadProgress(c, 100, -1)
+	s.assertMachineStatus(c, params.StatusStarted, "downloading tools 5.4.3-precise-amd64: 100 bytes received")
+}
+
+func (s *upgraderSuite) TestSetToolsDownloadProgressKeepsOtherStatus(c *gc.C) {
+	err := s.rawMachine.SetStatus(params.StatusError, "broken", nil)
+	c.Assert(err, gc.IsNil)
+	s.setToolsDownloadProgress(c, 100, 400)
+	s.assertMachineStatus(c, params.StatusError, "broken")
+}
+
+func (s *upgraderSuite) TestSetToolsDownloadProgressDone(c *gc.C) {
+	err := s.rawMachine.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	s.setToolsDownloadProgress(c, 100, 400)
+	args := params.ToolsDownloadProgressArgs{
+		Agents: []params.ToolsDownloadProgress{{
+			Tag:     s.rawMachine.Tag().String(),
+			Version: version.MustParseBinary("5.4.3-precise-amd64"),
+			Done:    true,
+		}},
+	}
+	results, err := s.upgrader.SetToolsDownloadProgress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	s.assertMachineStatus(c, params.StatusStarted, "")
+
+	// Status set since the progress was reported is left alone.
+	err = s.rawMachine.SetStatus(params.StatusError, "broken", nil)
+	c.Assert(err, gc.IsNil)
+	results, err = s.upgrader.SetToolsDownloadProgress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	s.assertMachineStatus(c, params.StatusError, "broken")
+}
+
+func (s *upgraderSuite) TestSetToolsDownloadProgressRefusesWrongAgent(c *gc.C) {
+	anAuthorizer := s.authorizer
+	anAuthorizer.Tag = names.NewMachineTag("12354")
+	anUpgrader, err := upgrader.NewUpgraderAPI(s.State, s.resources, anAuthorizer)
+	c.Check(err, gc.IsNil)
+	args := params.ToolsDownloadProgressArgs{
+		Agents: []params.ToolsDownloadProgress{{Tag: s.rawMachine.Tag().String()}},
+	}
+	results, err := anUpgrader.SetToolsDownloadProgress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+}
+
+func (s *upgraderSuite) TestAbortedUpgrade(c *gc.C) {
+	result, err := s.upgrader.AbortedUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.AbortedUpgradeResult{})
+
+	from, to := version.MustParse("1.20.0"), version.MustParse("1.21.0")
+	_, err = s.State.EnsureUpgradeInfo(s.apiMachine.Id(), from, to)
+	c.Assert(err, gc.IsNil)
+	result, err = s.upgrader.AbortedUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.AbortedUpgradeResult{})
+
+	_, err = s.State.AbortUpgrade()
+	c.Assert(err, gc.IsNil)
+	result, err = s.upgrader.AbortedUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.AbortedUpgradeResult{
+		Aborted: true,
+		From:    from,
+		To:      to,
+	})
+}
+
 func (s *upgraderSuite) TestDesiredVersionNothing(c *gc.C) {
 	// Not an error to watch nothing
 	results, err := s.upgrader.DesiredVersion(params.Entities{})
@@ -0,0 +1,294 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state/api/params"
+)
+
+// charmUpgradeDoc records the progress of a rolling charm upgrade. It
+// is stored within the service document, so that changes to it are
+// seen by everything watching the service.
+type charmUpgradeDoc struct {
+	BatchSize int
+	// Released holds the names of the units that have been allowed
+	// to upgrade to the service's charm.
+	Released []string
+	Halted   bool
+	Message  string
+}
+
+// CharmUpgrade describes a rolling charm upgrade in progress.
+type CharmUpgrade struct {
+	// BatchSize holds the maximum number of units that are upgraded
+	// at once.
+	BatchSize int
+
+	// Released holds the names of the units that have been allowed
+	// to upgrade to the service's charm.
+	Released []string
+
+	// Halted is true if the upgrade has been stopped because a unit
+	// failed to upgrade; Message describes the failure.
+	Halted  bool
+	Message string
+}
+
+// CharmUpgradeUnitStatus describes the progress of a single unit in a
+// rolling charm upgrade.
+type CharmUpgradeUnitStatus string
+
+const (
+	// CharmUpgradePending indicates the unit has not yet been
+	// allowed to upgrade.
+	CharmUpgradePending CharmUpgradeUnitStatus = "pending"
+
+	// CharmUpgradeUpgrading indicates the unit has been allowed to
+	// upgrade, but has not yet settled on the new charm.
+	CharmUpgradeUpgrading CharmUpgradeUnitStatus = "upgrading"
+
+	// CharmUpgradeUpgraded indicates the unit is running the new
+	// charm, with its agent started.
+	CharmUpgradeUpgraded CharmUpgradeUnitStatus = "upgraded"
+
+	// CharmUpgradeFailed indicates the unit encountered an error
+	// while upgrading.
+	CharmUpgradeFailed CharmUpgradeUnitStatus = "failed"
+)
+
+// charmUpgradeOp returns the operation that records the given rolling
+// upgrade on the service, or removes any record if upgrade is nil.
+func charmUpgradeOp(serviceName string, upgrade *charmUpgradeDoc) txn.Op {
+	update := bson.D{{"$unset", bson.D{{"charmupgrade", nil}}}}
+	if upgrade != nil {
+		update = bson.D{{"$set", bson.D{{"charmupgrade", upgrade}}}}
+	}
+	return txn.Op{
+		C:      servicesC,
+		Id:     serviceName,
+		Assert: isAliveDoc,
+		Update: update,
+	}
+}
+
+// SetCharmRolling changes the charm for the service, like SetCharm,
+// but existing units are upgraded at most batchSize at a time. Each
+// batch is released by AdvanceCharmUpgrade once the previous batch has
+// settled. If the service is already being upgraded to the same charm,
+// the batch size is changed and a halted upgrade is resumed.
+func (s *Service) SetCharmRolling(ch *Charm, force bool, batchSize int) error {
+	if batchSize < 1 {
+		return fmt.Errorf("cannot set charm for service %q: batch size must be positive", s)
+	}
+	upgrade := &charmUpgradeDoc{BatchSize: batchSize}
+	if s.doc.CharmUpgrade != nil && *s.doc.CharmURL == *ch.URL() {
+		upgrade.Released = s.doc.CharmUpgrade.Released
+	}
+	return s.setCharm(ch, force, upgrade)
+}
+
+// CharmUpgrade returns details of the service's rolling charm upgrade,
+// and whether one is in progress.
+func (s *Service) CharmUpgrade() (CharmUpgrade, bool) {
+	doc := s.doc.CharmUpgrade
+	if doc == nil {
+		return CharmUpgrade{}, false
+	}
+	return CharmUpgrade{
+		BatchSize: doc.BatchSize,
+		Released:  append([]string(nil), doc.Released...),
+		Halted:    doc.Halted,
+		Message:   doc.Message,
+	}, true
+}
+
+// isReleased reports whether the named unit may upgrade to the
+// service's charm.
+func (s *Service) isReleased(unitName string) bool {
+	if s.doc.CharmUpgrade == nil {
+		return true
+	}
+	for _, name := range s.doc.CharmUpgrade.Released {
+		if name == unitName {
+			return true
+		}
+	}
+	return false
+}
+
+// CharmURLForUnit returns the charm URL that the given unit of the
+// service should be running, and whether the unit should upgrade even
+// when in an error state. Units that have not yet been released by a
+// rolling charm upgrade should continue running their current charm.
+func (s *Service) CharmURLForUnit(u *Unit) (curl *charm.URL, force bool) {
+	if unitCharm, ok := u.CharmURL(); ok && !s.isReleased(u.Name()) {
+		return unitCharm, false
+	}
+	return s.CharmURL()
+}
+
+// CharmUpgradeStatus returns the progress of the given unit of the
+// service in the current rolling charm upgrade.
+func (s *Service) CharmUpgradeStatus(u *Unit) (CharmUpgradeUnitStatus, error) {
+	if !s.isReleased(u.Name()) {
+		if curl, _ := u.CharmURL(); curl == nil || *curl != *s.doc.CharmURL {
+			return CharmUpgradePending, nil
+		}
+	}
+	settled, failure, err := s.unitSettled(u)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case failure != "":
+		return CharmUpgradeFailed, nil
+	case settled:
+		return CharmUpgradeUpgraded, nil
+	}
+	return CharmUpgradeUpgrading, nil
+}
+
+// unitSettled returns whether the unit has settled on the service's
+// charm: that is, it is running the charm and its agent is started.
+// If the unit is in an error state, a description of the failure is
+// returned.
+func (s *Service) unitSettled(u *Unit) (settled bool, failure string, err error) {
+	if u.Life() != Alive {
+		// Units going away are not waited for.
+		return true, "", nil
+	}
+	status, info, _, err := u.Status()
+	if err != nil {
+		return false, "", err
+	}
+	if status == params.StatusError {
+		return false, fmt.Sprintf("unit %q failed: %s", u.Name(), info), nil
+	}
+	curl, _ := u.CharmURL()
+	if curl == nil || *curl != *s.doc.CharmURL || status != params.StatusStarted {
+		return false, "", nil
+	}
+	alive, err := u.AgentPresence()
+	if err != nil {
+		return false, "", err
+	}
+	return alive, "", nil
+}
+
+// AdvanceCharmUpgrade progresses the service's rolling charm upgrade,
+// if there is one. When all released units have settled on the new
+// charm, the next batch of units is released; when no units remain,
+// the upgrade is complete and its record is removed. If any released
+// unit is found in an error state, the upgrade is halted.
+func (s *Service) AdvanceCharmUpgrade() (err error) {
+	defer errors.Maskf(&err, "cannot advance charm upgrade for service %q", s)
+	service := &Service{st: s.st, doc: s.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := service.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		upgrade := service.doc.CharmUpgrade
+		if upgrade == nil || upgrade.Halted || service.doc.Life != Alive {
+			return nil, jujutxn.ErrNoOperations
+		}
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, err
+		}
+		sort.Sort(unitsByName(units))
+		var candidates []string
+		for _, u := range units {
+			if !service.isReleased(u.Name()) {
+				if curl, _ := u.CharmURL(); curl != nil && *curl != *service.doc.CharmURL && u.Life() == Alive {
+					candidates = append(candidates, u.Name())
+				}
+				continue
+			}
+			settled, failure, err := service.unitSettled(u)
+			if err != nil {
+				return nil, err
+			}
+			if failure != "" {
+				logger.Warningf("halting charm upgrade of service %q: %s", service, failure)
+				halted := *upgrade
+				halted.Halted = true
+				halted.Message = failure
+				return service.charmUpgradeOps(&halted), nil
+			}
+			if !settled {
+				// Wait for the current batch.
+				return nil, jujutxn.ErrNoOperations
+			}
+		}
+		if len(candidates) == 0 {
+			logger.Infof("charm upgrade of service %q complete", service)
+			return service.charmUpgradeOps(nil), nil
+		}
+		if len(candidates) > upgrade.BatchSize {
+			candidates = candidates[:upgrade.BatchSize]
+		}
+		logger.Infof("charm upgrade of service %q releasing units %v", service, candidates)
+		next := *upgrade
+		next.Released = append(append([]string(nil), upgrade.Released...), candidates...)
+		return service.charmUpgradeOps(&next), nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return err
+	}
+	return s.Refresh()
+}
+
+// ResumeCharmUpgrade resumes a halted rolling charm upgrade.
+func (s *Service) ResumeCharmUpgrade() (err error) {
+	defer errors.Maskf(&err, "cannot resume charm upgrade for service %q", s)
+	service := &Service{st: s.st, doc: s.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := service.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		upgrade := service.doc.CharmUpgrade
+		if upgrade == nil {
+			return nil, errors.New("no charm upgrade in progress")
+		}
+		if !upgrade.Halted {
+			return nil, jujutxn.ErrNoOperations
+		}
+		resumed := *upgrade
+		resumed.Halted = false
+		resumed.Message = ""
+		return service.charmUpgradeOps(&resumed), nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return err
+	}
+	return s.Refresh()
+}
+
+// charmUpgradeOps returns the operations that replace the service's
+// rolling upgrade record, asserting that the service has not changed
+// since s was read.
+func (s *Service) charmUpgradeOps(upgrade *charmUpgradeDoc) []txn.Op {
+	op := charmUpgradeOp(s.doc.Name, upgrade)
+	op.Assert = append(isAliveDoc, bson.DocElem{"txn-revno", s.doc.TxnRevno})
+	return []txn.Op{op}
+}
+
+type unitsByName []*Unit
+
+func (u unitsByName) Len() int           { return len(u) }
+func (u unitsByName) Less(i, j int) bool { return u[i].Name() < u[j].Name() }
+func (u unitsByName) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
@@ -0,0 +1,222 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/presence"
+)
+
+type CharmUpgradeSuite struct {
+	ConnSuite
+	charm    *state.Charm
+	newCharm *state.Charm
+	service  *state.Service
+	units    []*state.Unit
+	pingers  []*presence.Pinger
+}
+
+var _ = gc.Suite(&CharmUpgradeSuite{})
+
+func (s *CharmUpgradeSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.charm = s.AddTestingCharm(c, "mysql")
+	s.newCharm = s.AddMetaCharm(c, "mysql", metaBase, 2)
+	s.service = s.AddTestingService(c, "mysql", s.charm)
+	s.units = nil
+	s.pingers = nil
+	for i := 0; i < 3; i++ {
+		unit, err := s.service.AddUnit()
+		c.Assert(err, gc.IsNil)
+		s.settle(c, unit, s.charm)
+		pinger, err := unit.SetAgentPresence()
+		c.Assert(err, gc.IsNil)
+		s.units = append(s.units, unit)
+		s.pingers = append(s.pingers, pinger)
+	}
+	s.State.StartSync()
+}
+
+func (s *CharmUpgradeSuite) TearDownTest(c *gc.C) {
+	for _, pinger := range s.pingers {
+		c.Check(pinger.Stop(), gc.IsNil)
+	}
+	s.ConnSuite.TearDownTest(c)
+}
+
+// settle makes the unit look as if its agent has finished upgrading
+// to the given charm.
+func (s *CharmUpgradeSuite) settle(c *gc.C, unit *state.Unit, ch *state.Charm) {
+	err := unit.SetCharmURL(ch.URL())
+	c.Assert(err, gc.IsNil)
+	err = unit.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *CharmUpgradeSuite) assertReleased(c *gc.C, expect ...string) {
+	upgrade, ok := s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(upgrade.Released, jc.DeepEquals, expect)
+}
+
+func (s *CharmUpgradeSuite) assertUnitCharm(c *gc.C, unit *state.Unit, ch *state.Charm) {
+	curl, force := s.service.CharmURLForUnit(unit)
+	c.Assert(curl, gc.DeepEquals, ch.URL())
+	c.Assert(force, jc.IsFalse)
+}
+
+func (s *CharmUpgradeSuite) TestSetCharmRolling(c *gc.C) {
+	err := s.service.SetCharmRolling(s.newCharm, false, 2)
+	c.Assert(err, gc.IsNil)
+	upgrade, ok := s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(upgrade, gc.DeepEquals, state.CharmUpgrade{BatchSize: 2})
+
+	// New units get the new charm, but existing units keep their
+	// current charm until released.
+	curl, _ := s.service.CharmURL()
+	c.Assert(curl, gc.DeepEquals, s.newCharm.URL())
+	for _, unit := range s.units {
+		s.assertUnitCharm(c, unit, s.charm)
+	}
+
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.assertReleased(c, "mysql/0", "mysql/1")
+	s.assertUnitCharm(c, s.units[0], s.newCharm)
+	s.assertUnitCharm(c, s.units[1], s.newCharm)
+	s.assertUnitCharm(c, s.units[2], s.charm)
+
+	// Nothing more is released until the batch has settled.
+	s.settle(c, s.units[0], s.newCharm)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.assertReleased(c, "mysql/0", "mysql/1")
+
+	s.settle(c, s.units[1], s.newCharm)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.assertReleased(c, "mysql/0", "mysql/1", "mysql/2")
+	s.assertUnitCharm(c, s.units[2], s.newCharm)
+
+	// Once every unit has settled, the upgrade is complete.
+	s.settle(c, s.units[2], s.newCharm)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	_, ok = s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsFalse)
+
+	err = s.service.Refresh()
+	c.Assert(err, gc.IsNil)
+	_, ok = s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *CharmUpgradeSuite) TestSetCharmRollingBadBatchSize(c *gc.C) {
+	err := s.service.SetCharmRolling(s.newCharm, false, 0)
+	c.Assert(err, gc.ErrorMatches, `cannot set charm for service "mysql": batch size must be positive`)
+	_, ok := s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *CharmUpgradeSuite) TestSetCharmAbandonsRollingUpgrade(c *gc.C) {
+	err := s.service.SetCharmRolling(s.newCharm, false, 1)
+	c.Assert(err, gc.IsNil)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.assertReleased(c, "mysql/0")
+
+	err = s.service.SetCharm(s.newCharm, false)
+	c.Assert(err, gc.IsNil)
+	_, ok := s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsFalse)
+	for _, unit := range s.units {
+		s.assertUnitCharm(c, unit, s.newCharm)
+	}
+}
+
+func (s *CharmUpgradeSuite) TestAdvanceCharmUpgradeHaltsOnError(c *gc.C) {
+	err := s.service.SetCharmRolling(s.newCharm, false, 1)
+	c.Assert(err, gc.IsNil)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.assertReleased(c, "mysql/0")
+
+	err = s.units[0].SetCharmURL(s.newCharm.URL())
+	c.Assert(err, gc.IsNil)
+	err = s.units[0].SetStatus(params.StatusError, `hook failed: "upgrade-charm"`, nil)
+	c.Assert(err, gc.IsNil)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	upgrade, ok := s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(upgrade, jc.DeepEquals, state.CharmUpgrade{
+		BatchSize: 1,
+		Released:  []string{"mysql/0"},
+		Halted:    true,
+		Message:   `unit "mysql/0" failed: hook failed: "upgrade-charm"`,
+	})
+	status, err := s.service.CharmUpgradeStatus(s.units[0])
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, state.CharmUpgradeFailed)
+
+	// A halted upgrade does not advance, even once the unit recovers.
+	s.settle(c, s.units[0], s.newCharm)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	upgrade, _ = s.service.CharmUpgrade()
+	c.Assert(upgrade.Halted, jc.IsTrue)
+	s.assertReleased(c, "mysql/0")
+
+	err = s.service.ResumeCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	upgrade, _ = s.service.CharmUpgrade()
+	c.Assert(upgrade.Halted, jc.IsFalse)
+	c.Assert(upgrade.Message, gc.Equals, "")
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.assertReleased(c, "mysql/0", "mysql/1")
+}
+
+func (s *CharmUpgradeSuite) TestResumeCharmUpgradeNotInProgress(c *gc.C) {
+	err := s.service.ResumeCharmUpgrade()
+	c.Assert(err, gc.ErrorMatches, `cannot resume charm upgrade for service "mysql": no charm upgrade in progress`)
+}
+
+func (s *CharmUpgradeSuite) TestSetCharmRollingSameCharmKeepsProgress(c *gc.C) {
+	err := s.service.SetCharmRolling(s.newCharm, false, 1)
+	c.Assert(err, gc.IsNil)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.assertReleased(c, "mysql/0")
+
+	err = s.service.SetCharmRolling(s.newCharm, false, 2)
+	c.Assert(err, gc.IsNil)
+	upgrade, ok := s.service.CharmUpgrade()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(upgrade.BatchSize, gc.Equals, 2)
+	c.Assert(upgrade.Released, jc.DeepEquals, []string{"mysql/0"})
+}
+
+func (s *CharmUpgradeSuite) TestCharmUpgradeStatus(c *gc.C) {
+	err := s.service.SetCharmRolling(s.newCharm, false, 2)
+	c.Assert(err, gc.IsNil)
+	err = s.service.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.settle(c, s.units[1], s.newCharm)
+
+	for i, expect := range []state.CharmUpgradeUnitStatus{
+		state.CharmUpgradeUpgrading,
+		state.CharmUpgradeUpgraded,
+		state.CharmUpgradePending,
+	} {
+		status, err := s.service.CharmUpgradeStatus(s.units[i])
+		c.Assert(err, gc.IsNil)
+		c.Check(status, gc.Equals, expect)
+	}
+}
@@ -19,6 +19,7 @@ const (
 	cleanupUnitsForDyingService        cleanupKind = "units"
 	cleanupDyingUnit                   cleanupKind = "dyingUnit"
 	cleanupRemovedUnit                 cleanupKind = "removedUnit"
+	cleanupRemovedService              cleanupKind = "removedService"
 	cleanupServicesForDyingEnvironment cleanupKind = "services"
 	cleanupForceDestroyedMachine       cleanupKind = "machine"
 )
@@ -77,6 +78,8 @@ func (st *State) Cleanup() error {
 			err = st.cleanupDyingUnit(doc.Prefix)
 		case cleanupRemovedUnit:
 			err = st.cleanupRemovedUnit(doc.Prefix)
+		case cleanupRemovedService:
+			err = st.cleanupSchedules(doc.Prefix)
 		case cleanupServicesForDyingEnvironment:
 			err = st.cleanupServicesForDyingEnvironment()
 		case cleanupForceDestroyedMachine:
@@ -206,7 +209,10 @@ func (st *State) cleanupRemovedUnit(name string) error {
 			return err
 		}
 	}
-	return nil
+	if err := st.releaseUnitVolumes(name); err != nil {
+		return err
+	}
+	return st.cleanupSchedules(name)
 }
 
 // cleanupForceDestroyedMachine systematically destroys and removes all entities
@@ -26,6 +26,7 @@ type constraintsDoc struct {
 	Container    *instance.ContainerType
 	Tags         *[]string `bson:",omitempty"`
 	Networks     *[]string `bson:",omitempty"`
+	SpotPrice    *string   `bson:",omitempty"`
 }
 
 func (doc constraintsDoc) value() constraints.Value {
@@ -39,6 +40,7 @@ func (doc constraintsDoc) value() constraints.Value {
 		Container:    doc.Container,
 		Tags:         doc.Tags,
 		Networks:     doc.Networks,
+		SpotPrice:    doc.SpotPrice,
 	}
 }
 
@@ -53,6 +55,7 @@ func newConstraintsDoc(cons constraints.Value) constraintsDoc {
 		Container:    cons.Container,
 		Tags:         cons.Tags,
 		Networks:     cons.Networks,
+		SpotPrice:    cons.SpotPrice,
 	}
 }
 
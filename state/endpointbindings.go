@@ -0,0 +1,132 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/network"
+)
+
+// SetEndpointBindings binds relation endpoints of the service to named
+// networks, replacing any existing bindings. The keys of bindings are
+// endpoint names, and the values network names. Each network must be
+// one of the service's requested networks, so that it is configured
+// on every machine hosting a unit of the service.
+func (s *Service) SetEndpointBindings(bindings map[string]string) (err error) {
+	defer errors.Maskf(&err, "cannot set endpoint bindings for service %q", s)
+	requested, err := s.Networks()
+	if err != nil {
+		return err
+	}
+	for endpoint, networkName := range bindings {
+		if _, err := s.Endpoint(endpoint); err != nil {
+			return err
+		}
+		if !names.IsNetwork(networkName) {
+			return fmt.Errorf("invalid network name %q", networkName)
+		}
+		if !containsString(requested, networkName) {
+			return fmt.Errorf("network %q is not one of the service's networks", networkName)
+		}
+	}
+	update := bson.D{{"$set", bson.D{{"bindings", bindings}}}}
+	if len(bindings) == 0 {
+		update = bson.D{{"$unset", bson.D{{"bindings", nil}}}}
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     s.doc.Name,
+		Assert: isAliveDoc,
+		Update: update,
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, errNotAlive)
+	}
+	s.doc.Bindings = copyBindings(bindings)
+	return nil
+}
+
+// EndpointBindings returns the networks the service's relation
+// endpoints are bound to, keyed by endpoint name.
+func (s *Service) EndpointBindings() map[string]string {
+	return copyBindings(s.doc.Bindings)
+}
+
+// EndpointBinding returns the name of the network the given relation
+// endpoint of the service is bound to, and whether it is bound.
+func (s *Service) EndpointBinding(endpoint string) (string, bool) {
+	networkName, ok := s.doc.Bindings[endpoint]
+	return networkName, ok
+}
+
+func copyBindings(bindings map[string]string) map[string]string {
+	if len(bindings) == 0 {
+		return nil
+	}
+	result := make(map[string]string)
+	for k, v := range bindings {
+		result[k] = v
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// PrivateAddressOnNetwork returns the private address of the unit on
+// the named network and whether it is valid. Addresses are on the
+// network if they are recorded as such, or if they lie within the
+// network's CIDR.
+func (u *Unit) PrivateAddressOnNetwork(networkName string) (string, bool) {
+	var cidr *net.IPNet
+	if nw, err := u.st.Network(networkName); err == nil && nw.CIDR() != "" {
+		_, cidr, _ = net.ParseCIDR(nw.CIDR())
+	} else if err != nil && !errors.IsNotFound(err) {
+		unitLogger.Errorf("unit %v cannot get network %q: %v", u, networkName, err)
+	}
+	var addresses []network.Address
+	for _, addr := range u.addressesOfMachine() {
+		switch {
+		case addr.NetworkName == networkName:
+		case addr.NetworkName == "" && cidr != nil && cidrContains(cidr, addr.Value):
+		default:
+			continue
+		}
+		addresses = append(addresses, addr)
+	}
+	var privateAddress string
+	if len(addresses) > 0 {
+		privateAddress = network.SelectInternalAddress(addresses, false)
+	}
+	return privateAddress, privateAddress != ""
+}
+
+func cidrContains(cidr *net.IPNet, value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && cidr.Contains(ip)
+}
+
+// NetworkBinding returns the name of the network the relation unit's
+// endpoint is bound to, and whether it is bound.
+func (ru *RelationUnit) NetworkBinding() (string, bool, error) {
+	service, err := ru.unit.Service()
+	if err != nil {
+		return "", false, err
+	}
+	networkName, ok := service.EndpointBinding(ru.endpoint.Name)
+	return networkName, ok, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+type EndpointBindingsSuite struct {
+	ConnSuite
+	mysql *state.Service
+}
+
+var _ = gc.Suite(&EndpointBindingsSuite{})
+
+func (s *EndpointBindingsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingServiceWithNetworks(c, "mysql", s.AddTestingCharm(c, "mysql"), []string{"dbnet", "public"})
+	_, err := s.State.AddNetwork(state.NetworkInfo{"dbnet", "dbnet", "10.1.0.0/16", 0})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *EndpointBindingsSuite) TestSetEndpointBindings(c *gc.C) {
+	c.Assert(s.mysql.EndpointBindings(), gc.HasLen, 0)
+	err := s.mysql.SetEndpointBindings(map[string]string{"server": "dbnet"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.EndpointBindings(), jc.DeepEquals, map[string]string{"server": "dbnet"})
+	networkName, ok := s.mysql.EndpointBinding("server")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(networkName, gc.Equals, "dbnet")
+
+	mysql, err := s.State.Service("mysql")
+	c.Assert(err, gc.IsNil)
+	c.Assert(mysql.EndpointBindings(), jc.DeepEquals, map[string]string{"server": "dbnet"})
+
+	err = mysql.SetEndpointBindings(nil)
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.EndpointBindings(), gc.HasLen, 0)
+	_, ok = s.mysql.EndpointBinding("server")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *EndpointBindingsSuite) TestSetEndpointBindingsErrors(c *gc.C) {
+	for i, test := range []struct {
+		bindings map[string]string
+		err      string
+	}{{
+		bindings: map[string]string{"nonsense": "dbnet"},
+		err:      `service "mysql" has no "nonsense" relation`,
+	}, {
+		bindings: map[string]string{"server": "$bad"},
+		err:      `invalid network name "\$bad"`,
+	}, {
+		bindings: map[string]string{"server": "other"},
+		err:      `network "other" is not one of the service's networks`,
+	}} {
+		c.Logf("test %d: %v", i, test.bindings)
+		err := s.mysql.SetEndpointBindings(test.bindings)
+		c.Check(err, gc.ErrorMatches, `cannot set endpoint bindings for service "mysql": `+test.err)
+	}
+	c.Assert(s.mysql.EndpointBindings(), gc.HasLen, 0)
+}
+
+func (s *EndpointBindingsSuite) TestSetEndpointBindingsDeadService(c *gc.C) {
+	err := s.mysql.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.SetEndpointBindings(map[string]string{"server": "dbnet"})
+	c.Assert(err, gc.ErrorMatches, `cannot set endpoint bindings for service "mysql": not found or not alive`)
+}
+
+func (s *EndpointBindingsSuite) addUnitWithAddresses(c *gc.C) *state.Unit {
+	unit, err := s.mysql.AddUnit()
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetAddresses(
+		network.NewAddress("192.168.0.5", network.ScopeCloudLocal),
+		network.NewAddress("10.1.2.3", network.ScopeCloudLocal),
+		network.Address{
+			Value:       "172.16.0.9",
+			Type:        network.IPv4Address,
+			NetworkName: "public",
+			Scope:       network.ScopeCloudLocal,
+		},
+	)
+	c.Assert(err, gc.IsNil)
+	return unit
+}
+
+func (s *EndpointBindingsSuite) TestPrivateAddressOnNetwork(c *gc.C) {
+	unit := s.addUnitWithAddresses(c)
+
+	// The address is matched by the network's CIDR...
+	address, ok := unit.PrivateAddressOnNetwork("dbnet")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(address, gc.Equals, "10.1.2.3")
+
+	// ...or by its recorded network name.
+	address, ok = unit.PrivateAddressOnNetwork("public")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(address, gc.Equals, "172.16.0.9")
+
+	_, ok = unit.PrivateAddressOnNetwork("missing")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *EndpointBindingsSuite) TestRelationUnitPrivateAddress(c *gc.C) {
+	unit := s.addUnitWithAddresses(c)
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, gc.IsNil)
+
+	_, bound, err := ru.NetworkBinding()
+	c.Assert(err, gc.IsNil)
+	c.Assert(bound, jc.IsFalse)
+	address, ok := ru.PrivateAddress()
+	c.Assert(ok, jc.IsTrue)
+	defaultAddress, _ := unit.PrivateAddress()
+	c.Assert(address, gc.Equals, defaultAddress)
+
+	err = s.mysql.SetEndpointBindings(map[string]string{"server": "dbnet"})
+	c.Assert(err, gc.IsNil)
+	networkName, bound, err := ru.NetworkBinding()
+	c.Assert(err, gc.IsNil)
+	c.Assert(bound, jc.IsTrue)
+	c.Assert(networkName, gc.Equals, "dbnet")
+	address, ok = ru.PrivateAddress()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(address, gc.Equals, "10.1.2.3")
+}
@@ -0,0 +1,198 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// HookLockWait describes a unit agent waiting to acquire the machine's
+// hook execution lock, which is held by another agent.
+type HookLockWait struct {
+	// Holder describes the current holder of the lock; usually
+	// the name of a unit.
+	Holder string
+
+	// HolderHook holds the name of the hook the holder is running,
+	// if known.
+	HolderHook string
+
+	// Hook holds the name of the hook the waiting unit will run once
+	// it acquires the lock.
+	Hook string
+
+	// Queued holds the names of the hooks the waiting unit has queued
+	// to run after Hook, in order.
+	Queued []string
+
+	// Since holds the time the unit started waiting.
+	Since time.Time
+}
+
+// hookLockWaitDoc records a unit waiting for the hook lock. The _id
+// field is the global key of the unit.
+type hookLockWaitDoc struct {
+	Id         string `bson:"_id"`
+	Holder     string
+	HolderHook string
+	Hook       string
+	Queued     []string
+	Since      time.Time
+}
+
+// removeHookLockWaitOp returns the operation needed to remove the hook
+// lock wait document associated with the given globalKey.
+func removeHookLockWaitOp(st *State, globalKey string) txn.Op {
+	return txn.Op{
+		C:      hookLockWaitsC,
+		Id:     globalKey,
+		Remove: true,
+	}
+}
+
+func (u *Unit) getHookLockWait() (*hookLockWaitDoc, error) {
+	waits, closer := u.st.getCollection(hookLockWaitsC)
+	defer closer()
+
+	var doc hookLockWaitDoc
+	err := waits.FindId(u.globalKey()).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// AllHookLockWaits returns details of every unit's wait for the hook
+// lock, keyed by unit name. Units that are not waiting are omitted.
+func (st *State) AllHookLockWaits() (map[string]*HookLockWait, error) {
+	waits, closer := st.getCollection(hookLockWaitsC)
+	defer closer()
+
+	var docs []hookLockWaitDoc
+	if err := waits.Find(nil).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get hook lock waits: %v", err)
+	}
+	prefix := unitGlobalKey("")
+	result := make(map[string]*HookLockWait)
+	for _, doc := range docs {
+		if !strings.HasPrefix(doc.Id, prefix) {
+			continue
+		}
+		result[strings.TrimPrefix(doc.Id, prefix)] = &HookLockWait{
+			Holder:     doc.Holder,
+			HolderHook: doc.HolderHook,
+			Hook:       doc.Hook,
+			Queued:     doc.Queued,
+			Since:      doc.Since,
+		}
+	}
+	return result, nil
+}
+
+// HookLockWait returns details of the unit's wait for the hook lock,
+// or nil if it is not waiting.
+func (u *Unit) HookLockWait() (*HookLockWait, error) {
+	doc, err := u.getHookLockWait()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get hook lock wait for unit %q: %v", u, err)
+	}
+	if doc == nil {
+		return nil, nil
+	}
+	return &HookLockWait{
+		Holder:     doc.Holder,
+		HolderHook: doc.HolderHook,
+		Hook:       doc.Hook,
+		Queued:     doc.Queued,
+		Since:      doc.Since,
+	}, nil
+}
+
+// SetHookLockWait records that the unit is waiting for the hook lock.
+func (u *Unit) SetHookLockWait(wait HookLockWait) (err error) {
+	defer errors.Maskf(&err, "cannot set hook lock wait for unit %q", u)
+	if wait.Holder == "" {
+		return fmt.Errorf("lock holder not specified")
+	}
+	doc := hookLockWaitDoc{
+		Id:         u.globalKey(),
+		Holder:     wait.Holder,
+		HolderHook: wait.HolderHook,
+		Hook:       wait.Hook,
+		Queued:     wait.Queued,
+		Since:      wait.Since.UTC(),
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := u.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		if u.doc.Life == Dead {
+			return nil, errNotAlive
+		}
+		existing, err := u.getHookLockWait()
+		if err != nil {
+			return nil, err
+		}
+		unitOp := txn.Op{
+			C:      unitsC,
+			Id:     u.doc.Name,
+			Assert: notDeadDoc,
+		}
+		if existing == nil {
+			return []txn.Op{unitOp, {
+				C:      hookLockWaitsC,
+				Id:     doc.Id,
+				Assert: txn.DocMissing,
+				Insert: doc,
+			}}, nil
+		}
+		return []txn.Op{unitOp, {
+			C:      hookLockWaitsC,
+			Id:     doc.Id,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"holder", doc.Holder},
+				{"holderhook", doc.HolderHook},
+				{"hook", doc.Hook},
+				{"queued", doc.Queued},
+				{"since", doc.Since},
+			}}},
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
+
+// ClearHookLockWait records that the unit is no longer waiting for
+// the hook lock.
+func (u *Unit) ClearHookLockWait() (err error) {
+	defer errors.Maskf(&err, "cannot clear hook lock wait for unit %q", u)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		existing, err := u.getHookLockWait()
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:      hookLockWaitsC,
+			Id:     u.globalKey(),
+			Assert: txn.DocExists,
+			Remove: true,
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
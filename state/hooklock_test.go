@@ -0,0 +1,120 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type HookLockWaitSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&HookLockWaitSuite{})
+
+func (s *HookLockWaitSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	var err error
+	s.unit, err = service.AddUnit()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *HookLockWaitSuite) TestSetHookLockWait(c *gc.C) {
+	wait, err := s.unit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait, gc.IsNil)
+
+	since := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	expect := state.HookLockWait{
+		Holder:     "mysql/3",
+		HolderHook: "config-changed",
+		Hook:       "install",
+		Queued:     []string{"db-relation-joined", "db-relation-changed"},
+		Since:      since,
+	}
+	err = s.unit.SetHookLockWait(expect)
+	c.Assert(err, gc.IsNil)
+	wait, err = s.unit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait.Since.Equal(since), gc.Equals, true)
+	wait.Since = since
+	c.Assert(*wait, gc.DeepEquals, expect)
+
+	// A second wait replaces the first.
+	expect.Holder = "logging/0"
+	expect.HolderHook = "start"
+	expect.Queued = []string{"db-relation-changed"}
+	err = s.unit.SetHookLockWait(expect)
+	c.Assert(err, gc.IsNil)
+	wait, err = s.unit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait.Holder, gc.Equals, "logging/0")
+	c.Assert(wait.HolderHook, gc.Equals, "start")
+	c.Assert(wait.Queued, gc.DeepEquals, []string{"db-relation-changed"})
+
+	err = s.unit.ClearHookLockWait()
+	c.Assert(err, gc.IsNil)
+	wait, err = s.unit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait, gc.IsNil)
+
+	// Clearing again is not an error.
+	err = s.unit.ClearHookLockWait()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *HookLockWaitSuite) TestSetHookLockWaitNoHolder(c *gc.C) {
+	err := s.unit.SetHookLockWait(state.HookLockWait{Hook: "install"})
+	c.Assert(err, gc.ErrorMatches, `cannot set hook lock wait for unit "wordpress/0": lock holder not specified`)
+}
+
+func (s *HookLockWaitSuite) TestSetHookLockWaitDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetHookLockWait(state.HookLockWait{Holder: "mysql/3", Hook: "install"})
+	c.Assert(err, gc.ErrorMatches, `cannot set hook lock wait for unit "wordpress/0": not found or not alive`)
+}
+
+func (s *HookLockWaitSuite) TestAllHookLockWaits(c *gc.C) {
+	waits, err := s.State.AllHookLockWaits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(waits, gc.HasLen, 0)
+
+	other, err := s.unit.Service()
+	c.Assert(err, gc.IsNil)
+	unit1, err := other.AddUnit()
+	c.Assert(err, gc.IsNil)
+	_, err = other.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetHookLockWait(state.HookLockWait{Holder: "mysql/3", Hook: "install"})
+	c.Assert(err, gc.IsNil)
+	err = unit1.SetHookLockWait(state.HookLockWait{Holder: "wordpress/0", Hook: "start"})
+	c.Assert(err, gc.IsNil)
+
+	waits, err = s.State.AllHookLockWaits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(waits, gc.HasLen, 2)
+	c.Assert(waits["wordpress/0"].Holder, gc.Equals, "mysql/3")
+	c.Assert(waits["wordpress/0"].Hook, gc.Equals, "install")
+	c.Assert(waits["wordpress/1"].Holder, gc.Equals, "wordpress/0")
+	c.Assert(waits["wordpress/1"].Hook, gc.Equals, "start")
+}
+
+func (s *HookLockWaitSuite) TestRemoveUnitRemovesHookLockWait(c *gc.C) {
+	err := s.unit.SetHookLockWait(state.HookLockWait{Holder: "mysql/3", Hook: "install"})
+	c.Assert(err, gc.IsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, gc.IsNil)
+	wait, err := s.unit.HookLockWait()
+	c.Assert(err, gc.IsNil)
+	c.Assert(wait, gc.IsNil)
+}
@@ -105,8 +105,15 @@ type machineDoc struct {
 	Jobs          []MachineJob
 	NoVote        bool
 	HasVote       bool
-	PasswordHash  string
-	Clean         bool
+	// Retiring is set when the state server is being removed by
+	// EnsureAvailability; it loses its JobManageEnviron job and is
+	// destroyed once it no longer has a vote.
+	Retiring     bool `bson:",omitempty"`
+	PasswordHash string
+	Clean        bool
+	// PasswordRotationRequested is set when the machine agent
+	// has been asked to change its password.
+	PasswordRotationRequested bool `bson:",omitempty"`
 	// We store 2 different sets of addresses for the machine, obtained
 	// from different sources.
 	// Addresses is the set of addresses obtained by asking the provider.
@@ -246,20 +253,56 @@ func (m *Machine) HasVote() bool {
 // SetHasVote sets whether the machine is currently a voting
 // member of the replica set. It should only be called
 // from the worker that maintains the replica set.
+//
+// A retiring state server that loses its vote also loses its
+// JobManageEnviron job, and the machine is destroyed.
 func (m *Machine) SetHasVote(hasVote bool) error {
-	ops := []txn.Op{{
-		C:      machinesC,
-		Id:     m.doc.Id,
-		Assert: notDeadDoc,
-		Update: bson.D{{"$set", bson.D{{"hasvote", hasVote}}}},
-	}}
-	if err := m.st.runTransaction(ops); err != nil {
-		return fmt.Errorf("cannot set HasVote of machine %v: %v", m, onAbort(err, ErrDead))
+	var retire bool
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		// The machine is read afresh, as it may have started
+		// retiring since this copy was read.
+		current, err := m.st.Machine(m.doc.Id)
+		if errors.IsNotFound(err) {
+			return nil, ErrDead
+		} else if err != nil {
+			return nil, err
+		}
+		if current.doc.Life == Dead {
+			return nil, ErrDead
+		}
+		retire = !hasVote && current.doc.Retiring
+		op := txn.Op{
+			C:      machinesC,
+			Id:     m.doc.Id,
+			Assert: append(bson.D{{"retiring", bson.D{{"$ne", true}}}}, notDeadDoc...),
+			Update: bson.D{{"$set", bson.D{{"hasvote", hasVote}}}},
+		}
+		if current.doc.Retiring {
+			op.Assert = append(bson.D{{"retiring", true}}, notDeadDoc...)
+		}
+		if !retire {
+			return []txn.Op{op}, nil
+		}
+		return append([]txn.Op{op}, retireStateServerOps(m.doc.Id)...), nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return fmt.Errorf("cannot set HasVote of machine %v: %v", m, err)
 	}
 	m.doc.HasVote = hasVote
+	if retire {
+		m.st.destroyRetiredStateServer(m.doc.Id)
+	}
 	return nil
 }
 
+// IsRetiring reports whether the machine is a state server that is
+// being removed by EnsureAvailability. It keeps its JobManageEnviron
+// job until the worker that maintains the replica set has removed
+// its vote.
+func (m *Machine) IsRetiring() bool {
+	return m.doc.Retiring
+}
+
 // IsManager returns true if the machine has JobManageEnviron.
 func (m *Machine) IsManager() bool {
 	return hasJob(m.doc.Jobs, JobManageEnviron)
@@ -351,15 +394,41 @@ func (m *Machine) setPasswordHash(passwordHash string) error {
 		C:      machinesC,
 		Id:     m.doc.Id,
 		Assert: notDeadDoc,
-		Update: bson.D{{"$set", bson.D{{"passwordhash", passwordHash}}}},
+		Update: bson.D{{"$set", bson.D{
+			{"passwordhash", passwordHash},
+			{"passwordrotationrequested", false},
+		}}},
 	}}
 	if err := m.st.runTransaction(ops); err != nil {
 		return fmt.Errorf("cannot set password of machine %v: %v", m, onAbort(err, ErrDead))
 	}
 	m.doc.PasswordHash = passwordHash
+	m.doc.PasswordRotationRequested = false
 	return nil
 }
 
+// RequestPasswordRotation asks the machine agent to change its
+// password. The request is satisfied when the password is next set.
+func (m *Machine) RequestPasswordRotation() error {
+	ops := []txn.Op{{
+		C:      machinesC,
+		Id:     m.doc.Id,
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{{"passwordrotationrequested", true}}}},
+	}}
+	if err := m.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot request password rotation for machine %v: %v", m, onAbort(err, ErrDead))
+	}
+	m.doc.PasswordRotationRequested = true
+	return nil
+}
+
+// PasswordRotationRequested returns whether the machine agent has
+// been asked to change its password and has not yet done so.
+func (m *Machine) PasswordRotationRequested() bool {
+	return m.doc.PasswordRotationRequested
+}
+
 // Return the underlying PasswordHash stored in the database. Used by the test
 // suite to check that the PasswordHash gets properly updated to new values
 // when compatibility mode is detected.
@@ -453,6 +522,14 @@ func (m *Machine) Containers() ([]string, error) {
 	return nil, err
 }
 
+// Principals returns the names of the principal units assigned to the
+// machine.
+func (m *Machine) Principals() []string {
+	principals := make([]string, len(m.doc.Principals))
+	copy(principals, m.doc.Principals)
+	return principals
+}
+
 // ParentId returns the Id of the host machine if this machine is a container.
 func (m *Machine) ParentId() (string, bool) {
 	parentId := ParentId(m.Id())
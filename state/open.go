@@ -140,6 +140,10 @@ var indexes = []struct {
 	{networkInterfacesC, []string{"macaddress", "networkname"}, true},
 	{networkInterfacesC, []string{"networkname"}, false},
 	{networkInterfacesC, []string{"machineid"}, false},
+	{schedulesC, []string{"target"}, false},
+	{scheduleRunsC, []string{"scheduleid"}, false},
+	{scheduleRunsC, []string{"unit"}, false},
+	{volumesC, []string{"unit"}, false},
 }
 
 // The capped collection used for transaction logs defaults to 10MB.
@@ -329,7 +333,14 @@ func (st *State) createStateServingInfoDoc() error {
 }
 
 // CACert returns the certificate used to validate the state connection.
+// While the environment's CA is being replaced, this is the bundle of
+// old and new CA certificates held in the environment configuration.
 func (st *State) CACert() string {
+	if cfg, err := st.EnvironConfig(); err == nil {
+		if caCert, ok := cfg.CACert(); ok {
+			return caCert
+		}
+	}
 	return st.info.CACert
 }
 
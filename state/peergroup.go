@@ -0,0 +1,152 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// peerGroupDecisionKey is the key of the document in the state
+// servers collection that records the peer group worker's most
+// recent decision.
+const peerGroupDecisionKey = "peerGroupDecision"
+
+// PeerGroupDecision describes the most recent decision made by the
+// worker that maintains the mongo replica set of the state servers.
+type PeerGroupDecision struct {
+	// Time holds when the decision was made.
+	Time time.Time
+
+	// Changed holds whether the replica set was changed.
+	Changed bool
+
+	// Reasons explains the decision, holding what the worker did
+	// to each member of the replica set and why, and why any
+	// changes that might be expected were not made.
+	Reasons []string
+
+	// Error holds the reason the replica set could not be
+	// changed, if it could not.
+	Error string
+}
+
+type peerGroupDecisionDoc struct {
+	Id      string `bson:"_id"`
+	Time    time.Time
+	Changed bool
+	Reasons []string
+	Error   string
+}
+
+// SetPeerGroupDecision records the most recent decision of the
+// worker that maintains the mongo replica set.
+func (st *State) SetPeerGroupDecision(decision PeerGroupDecision) error {
+	doc := peerGroupDecisionDoc{
+		Id:      peerGroupDecisionKey,
+		Time:    decision.Time,
+		Changed: decision.Changed,
+		Reasons: decision.Reasons,
+		Error:   decision.Error,
+	}
+	stateServers, closer := st.getCollection(stateServersC)
+	defer closer()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		count, err := stateServers.FindId(peerGroupDecisionKey).Count()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return []txn.Op{{
+				C:      stateServersC,
+				Id:     peerGroupDecisionKey,
+				Assert: txn.DocMissing,
+				Insert: doc,
+			}}, nil
+		}
+		return []txn.Op{{
+			C:      stateServersC,
+			Id:     peerGroupDecisionKey,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"time", doc.Time},
+				{"changed", doc.Changed},
+				{"reasons", doc.Reasons},
+				{"error", doc.Error},
+			}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return fmt.Errorf("cannot set peer group decision: %v", err)
+	}
+	return nil
+}
+
+// PeerGroupDecision returns the most recent decision of the worker
+// that maintains the mongo replica set. It returns an error satisfying
+// errors.IsNotFound if no decision has been recorded.
+func (st *State) PeerGroupDecision() (PeerGroupDecision, error) {
+	stateServers, closer := st.getCollection(stateServersC)
+	defer closer()
+
+	var doc peerGroupDecisionDoc
+	err := stateServers.FindId(peerGroupDecisionKey).One(&doc)
+	if err == mgo.ErrNotFound {
+		return PeerGroupDecision{}, errors.NotFoundf("peer group decision")
+	} else if err != nil {
+		return PeerGroupDecision{}, fmt.Errorf("cannot get peer group decision: %v", err)
+	}
+	return PeerGroupDecision{
+		Time:    doc.Time,
+		Changed: doc.Changed,
+		Reasons: doc.Reasons,
+		Error:   doc.Error,
+	}, nil
+}
+
+// DemoteStateServer records that the state server machine with the
+// given id should no longer vote in the mongo replica set. The worker
+// that maintains the replica set removes its vote once another state
+// server is ready to take it, so that the number of votes stays odd;
+// the machine remains a non-voting state server until then, and after.
+// The last voting state server cannot be demoted.
+func (st *State) DemoteStateServer(id string) (err error) {
+	defer errors.Maskf(&err, "cannot demote state server %s", id)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		m, err := st.Machine(id)
+		if err != nil {
+			return nil, err
+		}
+		if !m.IsManager() {
+			return nil, fmt.Errorf("machine is not a state server")
+		}
+		if !m.WantsVote() {
+			return nil, fmt.Errorf("machine is already demoted")
+		}
+		info, err := st.StateServerInfo()
+		if err != nil {
+			return nil, err
+		}
+		if len(info.VotingMachineIds) <= 1 {
+			return nil, fmt.Errorf("machine is the only voting state server")
+		}
+		return []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.Id,
+			Assert: bson.D{{"jobs", JobManageEnviron}, {"novote", false}},
+			Update: bson.D{{"$set", bson.D{{"novote", true}}}},
+		}, {
+			C:      stateServersC,
+			Id:     environGlobalKey,
+			Assert: bson.D{{"votingmachineids", info.VotingMachineIds}},
+			Update: bson.D{{"$pull", bson.D{{"votingmachineids", m.doc.Id}}}},
+		}}, nil
+	}
+	return st.run(buildTxn)
+}
@@ -39,7 +39,15 @@ func (ru *RelationUnit) Endpoint() Endpoint {
 }
 
 // PrivateAddress returns the private address of the unit and whether it is valid.
+// If the relation endpoint is bound to a network, the address is the unit's
+// address on that network.
 func (ru *RelationUnit) PrivateAddress() (string, bool) {
+	networkName, bound, err := ru.NetworkBinding()
+	if err != nil {
+		unitLogger.Errorf("unit %v cannot get network binding: %v", ru.unit, err)
+	} else if bound {
+		return ru.unit.PrivateAddressOnNetwork(networkName)
+	}
 	return ru.unit.PrivateAddress()
 }
 
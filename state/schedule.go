@@ -0,0 +1,419 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/utils/cron"
+)
+
+// maxScheduleRunOutput holds the maximum number of bytes of output
+// recorded for a scheduled command; only the end of any longer output
+// is kept, starting at a character boundary.
+const maxScheduleRunOutput = 4096
+
+// scheduleDoc represents a command run periodically on a unit, or on
+// every unit of a service.
+type scheduleDoc struct {
+	Id      string `bson:"_id"`
+	Target  string
+	Spec    string
+	Command string
+	Paused  bool
+}
+
+// scheduleRunDoc records the most recent run of a scheduled command
+// on a unit. The _id field is the schedule id and the unit name,
+// separated by "#".
+type scheduleRunDoc struct {
+	Id         string `bson:"_id"`
+	ScheduleId string
+	Unit       string
+	Time       time.Time
+	Code       int
+	Output     string
+	Error      string
+}
+
+// Schedule represents a command run periodically, in a hook context,
+// on a unit or on every unit of a service. Only shell commands can be
+// scheduled, not actions, because nothing yet runs queued actions.
+type Schedule struct {
+	st  *State
+	doc scheduleDoc
+}
+
+// ScheduleRun describes the most recent run of a scheduled command on
+// a unit.
+type ScheduleRun struct {
+	// Unit holds the name of the unit that ran the command.
+	Unit string
+
+	// Time holds the time the command was started.
+	Time time.Time
+
+	// Code holds the exit code of the command.
+	Code int
+
+	// Output holds the end of the command's combined output.
+	Output string
+
+	// Error holds a description of any error that prevented the
+	// command from being run.
+	Error string
+}
+
+func scheduleRunId(scheduleId, unitName string) string {
+	return scheduleId + "#" + unitName
+}
+
+// AddSchedule adds a schedule that runs the given command on the
+// target at the times described by the cron-style spec. The target
+// must be the name of a unit or of a service; in the latter case the
+// command is run on every unit of the service.
+func (st *State) AddSchedule(target, spec, command string) (schedule *Schedule, err error) {
+	defer errors.Maskf(&err, "cannot add schedule for %q", target)
+	if _, err := cron.Parse(spec); err != nil {
+		return nil, err
+	}
+	if command == "" {
+		return nil, fmt.Errorf("no command specified")
+	}
+	var targetOp txn.Op
+	switch {
+	case names.IsUnit(target):
+		unit, err := st.Unit(target)
+		if err != nil {
+			return nil, err
+		}
+		if unit.Life() != Alive {
+			return nil, fmt.Errorf("unit is not alive")
+		}
+		targetOp = txn.Op{C: unitsC, Id: target, Assert: isAliveDoc}
+	case names.IsService(target):
+		service, err := st.Service(target)
+		if err != nil {
+			return nil, err
+		}
+		if service.Life() != Alive {
+			return nil, fmt.Errorf("service is not alive")
+		}
+		targetOp = txn.Op{C: servicesC, Id: target, Assert: isAliveDoc}
+	default:
+		return nil, fmt.Errorf("target must be a unit or a service")
+	}
+	seq, err := st.sequence("schedule")
+	if err != nil {
+		return nil, err
+	}
+	doc := scheduleDoc{
+		Id:      strconv.Itoa(seq),
+		Target:  target,
+		Spec:    spec,
+		Command: command,
+	}
+	ops := []txn.Op{targetOp, {
+		C:      schedulesC,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, fmt.Errorf("target is not alive")
+	} else if err != nil {
+		return nil, err
+	}
+	return &Schedule{st: st, doc: doc}, nil
+}
+
+// Schedule returns the schedule with the given id.
+func (st *State) Schedule(id string) (*Schedule, error) {
+	schedules, closer := st.getCollection(schedulesC)
+	defer closer()
+
+	var doc scheduleDoc
+	err := schedules.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("schedule %q", id)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get schedule %q: %v", id, err)
+	}
+	return &Schedule{st: st, doc: doc}, nil
+}
+
+// AllSchedules returns all the schedules in the environment.
+func (st *State) AllSchedules() ([]*Schedule, error) {
+	return st.findSchedules(nil)
+}
+
+func (st *State) findSchedules(sel bson.D) ([]*Schedule, error) {
+	schedules, closer := st.getCollection(schedulesC)
+	defer closer()
+
+	var docs []scheduleDoc
+	if err := schedules.Find(sel).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get schedules: %v", err)
+	}
+	result := make([]*Schedule, len(docs))
+	for i, doc := range docs {
+		result[i] = &Schedule{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// Id returns the schedule's id.
+func (s *Schedule) Id() string {
+	return s.doc.Id
+}
+
+// Target returns the name of the unit or service the command is run
+// on.
+func (s *Schedule) Target() string {
+	return s.doc.Target
+}
+
+// Spec returns the cron-style specification of when the command is
+// run.
+func (s *Schedule) Spec() string {
+	return s.doc.Spec
+}
+
+// Command returns the command run by the schedule.
+func (s *Schedule) Command() string {
+	return s.doc.Command
+}
+
+// Paused returns whether the schedule is paused; the commands of
+// paused schedules are not run.
+func (s *Schedule) Paused() bool {
+	return s.doc.Paused
+}
+
+// Refresh refreshes the contents of the schedule from the underlying
+// state.
+func (s *Schedule) Refresh() error {
+	schedule, err := s.st.Schedule(s.doc.Id)
+	if err != nil {
+		return err
+	}
+	s.doc = schedule.doc
+	return nil
+}
+
+// Pause stops the schedule's command being run until Resume is called.
+func (s *Schedule) Pause() error {
+	return s.setPaused(true)
+}
+
+// Resume resumes a paused schedule.
+func (s *Schedule) Resume() error {
+	return s.setPaused(false)
+}
+
+func (s *Schedule) setPaused(paused bool) error {
+	ops := []txn.Op{{
+		C:      schedulesC,
+		Id:     s.doc.Id,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"paused", paused}}}},
+	}}
+	if err := s.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("schedule %q", s.doc.Id)
+	} else if err != nil {
+		return fmt.Errorf("cannot update schedule %q: %v", s.doc.Id, err)
+	}
+	s.doc.Paused = paused
+	return nil
+}
+
+// Remove removes the schedule, along with the record of its runs. It
+// is not an error to remove a schedule that has already been removed.
+func (s *Schedule) Remove() error {
+	ops, err := s.st.removeSchedulesOps(bson.D{{"_id", s.doc.Id}})
+	if err != nil {
+		return fmt.Errorf("cannot remove schedule %q: %v", s.doc.Id, err)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := s.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot remove schedule %q: %v", s.doc.Id, err)
+	}
+	return nil
+}
+
+// Runs returns the most recent run of the schedule's command on each
+// unit that has run it.
+func (s *Schedule) Runs() ([]ScheduleRun, error) {
+	runs, closer := s.st.getCollection(scheduleRunsC)
+	defer closer()
+
+	var docs []scheduleRunDoc
+	err := runs.Find(bson.D{{"scheduleid", s.doc.Id}}).Sort("unit").All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get runs of schedule %q: %v", s.doc.Id, err)
+	}
+	result := make([]ScheduleRun, len(docs))
+	for i, doc := range docs {
+		result[i] = ScheduleRun{
+			Unit:   doc.Unit,
+			Time:   doc.Time,
+			Code:   doc.Code,
+			Output: doc.Output,
+			Error:  doc.Error,
+		}
+	}
+	return result, nil
+}
+
+// removeSchedulesOps returns the operations needed to remove the
+// schedules matching sel, and the record of their runs.
+func (st *State) removeSchedulesOps(sel bson.D) ([]txn.Op, error) {
+	schedules, err := st.findSchedules(sel)
+	if err != nil {
+		return nil, err
+	}
+	runs, closer := st.getCollection(scheduleRunsC)
+	defer closer()
+
+	var ops []txn.Op
+	for _, schedule := range schedules {
+		ops = append(ops, txn.Op{
+			C:      schedulesC,
+			Id:     schedule.doc.Id,
+			Remove: true,
+		})
+		var docs []scheduleRunDoc
+		err := runs.Find(bson.D{{"scheduleid", schedule.doc.Id}}).Select(bson.D{{"_id", 1}}).All(&docs)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			ops = append(ops, txn.Op{
+				C:      scheduleRunsC,
+				Id:     doc.Id,
+				Remove: true,
+			})
+		}
+	}
+	return ops, nil
+}
+
+// cleanupSchedules removes the schedules that target the named unit or
+// service, and the runs recorded by a unit of that name.
+func (st *State) cleanupSchedules(target string) error {
+	ops, err := st.removeSchedulesOps(bson.D{{"target", target}})
+	if err != nil {
+		return err
+	}
+	if names.IsUnit(target) {
+		runs, closer := st.getCollection(scheduleRunsC)
+		defer closer()
+		var docs []scheduleRunDoc
+		err := runs.Find(bson.D{{"unit", target}}).Select(bson.D{{"_id", 1}}).All(&docs)
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			ops = append(ops, txn.Op{
+				C:      scheduleRunsC,
+				Id:     doc.Id,
+				Remove: true,
+			})
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return st.runTransaction(ops)
+}
+
+// Schedules returns the schedules whose commands should be run on the
+// unit: those that target the unit itself, or its service.
+func (u *Unit) Schedules() ([]*Schedule, error) {
+	sel := bson.D{{"target", bson.D{{"$in", []string{u.doc.Name, u.doc.Service}}}}}
+	return u.st.findSchedules(sel)
+}
+
+// SetScheduleRun records the result of running the command of the
+// schedule with the given id on the unit, replacing any earlier record.
+func (u *Unit) SetScheduleRun(scheduleId string, run ScheduleRun) (err error) {
+	defer errors.Maskf(&err, "cannot set result of schedule %q for unit %q", scheduleId, u)
+	output := run.Output
+	if len(output) > maxScheduleRunOutput {
+		// Cut after, not within, the character at the limit.
+		start := len(output) - maxScheduleRunOutput
+		for start < len(output) && !utf8.RuneStart(output[start]) {
+			start++
+		}
+		output = output[start:]
+	}
+	doc := scheduleRunDoc{
+		Id:         scheduleRunId(scheduleId, u.doc.Name),
+		ScheduleId: scheduleId,
+		Unit:       u.doc.Name,
+		Time:       run.Time.UTC(),
+		Code:       run.Code,
+		Output:     output,
+		Error:      run.Error,
+	}
+	runs, closer := u.st.getCollection(scheduleRunsC)
+	defer closer()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if _, err := u.st.Schedule(scheduleId); err != nil {
+				return nil, err
+			}
+			if err := u.Refresh(); err != nil {
+				return nil, err
+			}
+			if u.doc.Life == Dead {
+				return nil, errNotAlive
+			}
+		}
+		ops := []txn.Op{{
+			C:      unitsC,
+			Id:     u.doc.Name,
+			Assert: notDeadDoc,
+		}, {
+			C:      schedulesC,
+			Id:     scheduleId,
+			Assert: txn.DocExists,
+		}}
+		count, err := runs.FindId(doc.Id).Count()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return append(ops, txn.Op{
+				C:      scheduleRunsC,
+				Id:     doc.Id,
+				Assert: txn.DocMissing,
+				Insert: doc,
+			}), nil
+		}
+		return append(ops, txn.Op{
+			C:      scheduleRunsC,
+			Id:     doc.Id,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"time", doc.Time},
+				{"code", doc.Code},
+				{"output", doc.Output},
+				{"error", doc.Error},
+			}}},
+		}), nil
+	}
+	return u.st.run(buildTxn)
+}
@@ -0,0 +1,252 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/testing"
+)
+
+type ScheduleSuite struct {
+	ConnSuite
+	service *state.Service
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&ScheduleSuite{})
+
+func (s *ScheduleSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	var err error
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *ScheduleSuite) TestAddSchedule(c *gc.C) {
+	schedule, err := s.State.AddSchedule("wordpress/0", "*/5 * * * *", "logrotate -f /etc/logrotate.conf")
+	c.Assert(err, gc.IsNil)
+	c.Assert(schedule.Target(), gc.Equals, "wordpress/0")
+	c.Assert(schedule.Spec(), gc.Equals, "*/5 * * * *")
+	c.Assert(schedule.Command(), gc.Equals, "logrotate -f /etc/logrotate.conf")
+	c.Assert(schedule.Paused(), jc.IsFalse)
+
+	other, err := s.State.AddSchedule("wordpress", "@daily", "backup")
+	c.Assert(err, gc.IsNil)
+	c.Assert(other.Id(), gc.Not(gc.Equals), schedule.Id())
+
+	got, err := s.State.Schedule(schedule.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.Command(), gc.Equals, schedule.Command())
+
+	all, err := s.State.AllSchedules()
+	c.Assert(err, gc.IsNil)
+	c.Assert(all, gc.HasLen, 2)
+}
+
+func (s *ScheduleSuite) TestAddScheduleErrors(c *gc.C) {
+	_, err := s.State.AddSchedule("wordpress/0", "* * *", "ls")
+	c.Assert(err, gc.ErrorMatches, `cannot add schedule for "wordpress/0": invalid schedule "\* \* \*": expected 5 fields, got 3`)
+	_, err = s.State.AddSchedule("wordpress/0", "@hourly", "")
+	c.Assert(err, gc.ErrorMatches, `cannot add schedule for "wordpress/0": no command specified`)
+	_, err = s.State.AddSchedule("wordpress/9", "@hourly", "ls")
+	c.Assert(err, gc.ErrorMatches, `cannot add schedule for "wordpress/9": unit "wordpress/9" not found`)
+	_, err = s.State.AddSchedule("mysql", "@hourly", "ls")
+	c.Assert(err, gc.ErrorMatches, `cannot add schedule for "mysql": service "mysql" not found`)
+	_, err = s.State.AddSchedule("machine-0", "@hourly", "ls")
+	c.Assert(err, gc.ErrorMatches, `cannot add schedule for "machine-0": target must be a unit or a service`)
+
+	err = s.service.Destroy()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.ErrorMatches, `cannot add schedule for "wordpress": service is not alive`)
+}
+
+func (s *ScheduleSuite) TestPauseResume(c *gc.C) {
+	schedule, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	err = schedule.Pause()
+	c.Assert(err, gc.IsNil)
+	c.Assert(schedule.Paused(), jc.IsTrue)
+	got, err := s.State.Schedule(schedule.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.Paused(), jc.IsTrue)
+
+	err = schedule.Resume()
+	c.Assert(err, gc.IsNil)
+	err = got.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.Paused(), jc.IsFalse)
+}
+
+func (s *ScheduleSuite) TestRemove(c *gc.C) {
+	schedule, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetScheduleRun(schedule.Id(), state.ScheduleRun{Time: time.Now()})
+	c.Assert(err, gc.IsNil)
+
+	err = schedule.Remove()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Schedule(schedule.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	runs, err := schedule.Runs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(runs, gc.HasLen, 0)
+
+	// Removing again is not an error.
+	err = schedule.Remove()
+	c.Assert(err, gc.IsNil)
+	err = schedule.Pause()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ScheduleSuite) TestUnitSchedules(c *gc.C) {
+	unit1, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	forService, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	forUnit, err := s.State.AddSchedule("wordpress/1", "@daily", "df")
+	c.Assert(err, gc.IsNil)
+
+	schedules, err := s.unit.Schedules()
+	c.Assert(err, gc.IsNil)
+	c.Assert(scheduleIds(schedules), jc.SameContents, []string{forService.Id()})
+	schedules, err = unit1.Schedules()
+	c.Assert(err, gc.IsNil)
+	c.Assert(scheduleIds(schedules), jc.SameContents, []string{forService.Id(), forUnit.Id()})
+}
+
+func (s *ScheduleSuite) TestSetScheduleRun(c *gc.C) {
+	schedule, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	when := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	err = s.unit.SetScheduleRun(schedule.Id(), state.ScheduleRun{
+		Time:   when,
+		Code:   1,
+		Output: "oops",
+	})
+	c.Assert(err, gc.IsNil)
+	runs, err := schedule.Runs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(runs, gc.HasLen, 1)
+	c.Assert(runs[0].Time.Equal(when), jc.IsTrue)
+	runs[0].Time = when
+	c.Assert(runs[0], gc.DeepEquals, state.ScheduleRun{
+		Unit:   "wordpress/0",
+		Time:   when,
+		Code:   1,
+		Output: "oops",
+	})
+
+	// A later run replaces the earlier one, and long output is
+	// truncated to its end.
+	output := strings.Repeat("x", 5000) + "done"
+	err = s.unit.SetScheduleRun(schedule.Id(), state.ScheduleRun{
+		Time:   when.Add(time.Hour),
+		Output: output,
+	})
+	c.Assert(err, gc.IsNil)
+	runs, err = schedule.Runs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(runs, gc.HasLen, 1)
+	c.Assert(runs[0].Code, gc.Equals, 0)
+	c.Assert(runs[0].Output, gc.HasLen, 4096)
+	c.Assert(strings.HasSuffix(runs[0].Output, "done"), jc.IsTrue)
+
+	// Multi-byte characters are not split.
+	err = s.unit.SetScheduleRun(schedule.Id(), state.ScheduleRun{
+		Time:   when.Add(2 * time.Hour),
+		Output: strings.Repeat("é", 2500) + "!",
+	})
+	c.Assert(err, gc.IsNil)
+	runs, err = schedule.Runs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(runs[0].Output, gc.Equals, strings.Repeat("é", 2047)+"!")
+}
+
+func (s *ScheduleSuite) TestSetScheduleRunNoSchedule(c *gc.C) {
+	err := s.unit.SetScheduleRun("42", state.ScheduleRun{Time: time.Now()})
+	c.Assert(err, gc.ErrorMatches, `cannot set result of schedule "42" for unit "wordpress/0": schedule "42" not found`)
+}
+
+func (s *ScheduleSuite) TestSchedulesRemovedWithUnitAndService(c *gc.C) {
+	forService, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	forUnit, err := s.State.AddSchedule("wordpress/0", "@daily", "df")
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetScheduleRun(forService.Id(), state.ScheduleRun{Time: time.Now()})
+	c.Assert(err, gc.IsNil)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Schedule(forUnit.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	runs, err := forService.Runs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(runs, gc.HasLen, 0)
+
+	err = s.service.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Schedule(forService.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ScheduleSuite) TestWatchSchedules(c *gc.C) {
+	_, err := s.State.AddSchedule("wordpress", "@hourly", "ls")
+	c.Assert(err, gc.IsNil)
+	w := s.unit.WatchSchedules()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	// Schedules for the unit are seen.
+	schedule, err := s.State.AddSchedule("wordpress/0", "@daily", "df")
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+	err = schedule.Pause()
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	// Schedules for other entities are not.
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	other, err := s.State.AddSchedule("mysql", "@daily", "df")
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+	err = other.Remove()
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+
+	// Nor are runs.
+	err = s.unit.SetScheduleRun(schedule.Id(), state.ScheduleRun{Time: time.Now()})
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+
+	err = schedule.Remove()
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	testing.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func scheduleIds(schedules []*state.Schedule) []string {
+	ids := make([]string, len(schedules))
+	for i, schedule := range schedules {
+		ids[i] = schedule.Id()
+	}
+	return ids
+}
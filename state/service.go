@@ -44,7 +44,10 @@ type serviceDoc struct {
 	Exposed       bool
 	MinUnits      int
 	OwnerTag      string
-	TxnRevno      int64 `bson:"txn-revno"`
+	CharmUpgrade  *charmUpgradeDoc            `bson:",omitempty"`
+	Bindings      map[string]string           `bson:",omitempty"`
+	Storage       map[string]StorageDirective `bson:",omitempty"`
+	TxnRevno      int64                       `bson:"txn-revno"`
 }
 
 func newService(st *State, doc *serviceDoc) *Service {
@@ -228,6 +231,7 @@ func (s *Service) removeOps(asserts bson.D) []txn.Op {
 	}}
 	ops = append(ops, removeRequestedNetworksOp(s.st, s.globalKey()))
 	ops = append(ops, removeConstraintsOp(s.st, s.globalKey()))
+	ops = append(ops, s.st.newCleanupOp(cleanupRemovedService, s.doc.Name))
 	return append(ops, annotationRemoveOp(s.st, s.globalKey()))
 }
 
@@ -464,8 +468,15 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 
 // SetCharm changes the charm for the service. New units will be started with
 // this charm, and existing units will be upgraded to use it. If force is true,
-// units will be upgraded even if they are in an error state.
+// units will be upgraded even if they are in an error state. Any rolling
+// upgrade in progress is abandoned, and all units are upgraded at once.
 func (s *Service) SetCharm(ch *Charm, force bool) (err error) {
+	return s.setCharm(ch, force, nil)
+}
+
+// setCharm implements SetCharm and SetCharmRolling. If upgrade is not
+// nil, it is recorded as the service's rolling charm upgrade.
+func (s *Service) setCharm(ch *Charm, force bool, upgrade *charmUpgradeDoc) (err error) {
 	services, closer := s.st.getCollection(servicesC)
 	defer closer()
 	settings := services.Database.C(settingsC)
@@ -507,11 +518,12 @@ func (s *Service) SetCharm(ch *Charm, force bool) (err error) {
 				return nil, err
 			}
 		}
-		return ops, nil
+		return append(ops, charmUpgradeOp(s.doc.Name, upgrade)), nil
 	}
 	if err = s.st.run(buildTxn); err == nil {
 		s.doc.CharmURL = ch.URL()
 		s.doc.ForceCharm = force
+		s.doc.CharmUpgrade = upgrade
 		return nil
 	}
 	return err
@@ -615,6 +627,11 @@ func (s *Service) addUnitOps(principalName string, asserts bson.D) (string, []tx
 		}
 		ops = append(ops, createConstraintsOp(s.st, globalKey, cons))
 	}
+	volumeOps, err := s.addUnitVolumeOps(name)
+	if err != nil {
+		return "", nil, err
+	}
+	ops = append(ops, volumeOps...)
 	return name, ops, nil
 }
 
@@ -685,6 +702,7 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 	},
 		removeConstraintsOp(s.st, u.globalKey()),
 		removeStatusOp(s.st, u.globalKey()),
+		removeHookLockWaitOp(s.st, u.globalKey()),
 		annotationRemoveOp(s.st, u.globalKey()),
 		s.st.newCleanupOp(cleanupRemovedUnit, u.doc.Name),
 	)
@@ -5,6 +5,7 @@ package state
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -139,7 +140,9 @@ func (c *Settings) Write() ([]ItemChange, error) {
 	for key := range cacheKeys(c.disk, c.core) {
 		old, ondisk := c.disk[key]
 		new, incore := c.core[key]
-		if new == old {
+		// Values may be structured (maps or slices), which cannot
+		// be compared with ==.
+		if ondisk == incore && reflect.DeepEqual(new, old) {
 			continue
 		}
 		var change ItemChange
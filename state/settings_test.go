@@ -7,6 +7,7 @@ import (
 	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
+	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 	gc "launchpad.net/gocheck"
 
@@ -206,6 +207,31 @@ func (s *SettingsSuite) TestSetItem(c *gc.C) {
 	c.Assert(mgoData, gc.DeepEquals, options)
 }
 
+func (s *SettingsSuite) TestSetStructuredItem(c *gc.C) {
+	node, err := createSettings(s.state, s.key, nil)
+	c.Assert(err, gc.IsNil)
+	nested := map[string]interface{}{"hosts": []interface{}{"a", "b"}}
+	node.Set("nested", nested)
+	changes, err := node.Write()
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes, gc.DeepEquals, []ItemChange{
+		{ItemAdded, "nested", nil, nested},
+	})
+
+	// Setting an equal structured value is not a change.
+	node.Set("nested", map[string]interface{}{"hosts": []interface{}{"a", "b"}})
+	changes, err = node.Write()
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes, gc.HasLen, 0)
+
+	// Check MongoDB state.
+	nodeTwo, err := readSettings(s.state, s.key)
+	c.Assert(err, gc.IsNil)
+	value, found := nodeTwo.Get("nested")
+	c.Assert(found, gc.Equals, true)
+	c.Assert(value, gc.DeepEquals, bson.M{"hosts": []interface{}{"a", "b"}})
+}
+
 func (s *SettingsSuite) TestSetItemEscape(c *gc.C) {
 	// Check that Set works as expected.
 	node, err := createSettings(s.state, s.key, nil)
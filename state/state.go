@@ -64,8 +64,14 @@ const (
 	cleanupsC          = "cleanups"
 	annotationsC       = "annotations"
 	statusesC          = "statuses"
+	hookLockWaitsC     = "hooklockwaits"
+	schedulesC         = "schedules"
+	scheduleRunsC      = "scheduleruns"
+	volumesC           = "volumes"
 	stateServersC      = "stateServers"
 	openedPortsC       = "openedPorts"
+	upgradeProgressC   = "upgradeprogress"
+	upgradeInfoC       = "upgradeInfo"
 
 	// These collections are used by the mgo transaction runner.
 	txnLogC = "txns.log"
@@ -2282,6 +2282,39 @@ func testSetPassword(c *gc.C, getEntity func() (state.Authenticator, error)) {
 	}
 }
 
+type passwordRotator interface {
+	state.Authenticator
+	RequestPasswordRotation() error
+	PasswordRotationRequested() bool
+}
+
+func testRequestPasswordRotation(c *gc.C, getEntity func() (passwordRotator, error)) {
+	e, err := getEntity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(e.PasswordRotationRequested(), jc.IsFalse)
+
+	err = e.RequestPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	c.Assert(e.PasswordRotationRequested(), jc.IsTrue)
+	e2, err := getEntity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(e2.PasswordRotationRequested(), jc.IsTrue)
+
+	// Setting the password satisfies the request.
+	err = e2.SetPassword(goodPassword)
+	c.Assert(err, gc.IsNil)
+	c.Assert(e2.PasswordRotationRequested(), jc.IsFalse)
+	err = e.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(e.PasswordRotationRequested(), jc.IsFalse)
+
+	if le, ok := e.(lifer); ok {
+		testWhenDying(c, le, noErr, deadErr, func() error {
+			return e.RequestPasswordRotation()
+		})
+	}
+}
+
 func testSetAgentCompatPassword(c *gc.C, entity state.Authenticator) {
 	// In Juju versions 1.16 and older we used UserPasswordHash(password,CompatSalt)
 	// for Machine and Unit agents. This was determined to be overkill
@@ -3246,6 +3279,65 @@ func (s *StateSuite) TestEnsureAvailabilityDemotesUnavailableMachines(c *gc.C) {
 	c.Assert(m3.IsManager(), jc.IsTrue)
 }
 
+func (s *StateSuite) TestDemoteStateServer(c *gc.C) {
+	_, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	err = s.State.DemoteStateServer("1")
+	c.Assert(err, gc.IsNil)
+	s.assertStateServerInfo(c, []string{"0", "1", "2"}, []string{"0", "2"})
+	m1, err := s.State.Machine("1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(m1.WantsVote(), jc.IsFalse)
+	c.Assert(m1.IsManager(), jc.IsTrue)
+
+	err = s.State.DemoteStateServer("1")
+	c.Assert(err, gc.ErrorMatches, "cannot demote state server 1: machine is already demoted")
+	err = s.State.DemoteStateServer("2")
+	c.Assert(err, gc.IsNil)
+	err = s.State.DemoteStateServer("0")
+	c.Assert(err, gc.ErrorMatches, "cannot demote state server 0: machine is the only voting state server")
+	s.assertStateServerInfo(c, []string{"0", "1", "2"}, []string{"0"})
+}
+
+func (s *StateSuite) TestDemoteStateServerErrors(c *gc.C) {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = s.State.DemoteStateServer(m.Id())
+	c.Assert(err, gc.ErrorMatches, "cannot demote state server 0: machine is not a state server")
+	err = s.State.DemoteStateServer("42")
+	c.Assert(err, gc.ErrorMatches, "cannot demote state server 42: machine 42 not found")
+}
+
+func (s *StateSuite) TestPeerGroupDecision(c *gc.C) {
+	_, err := s.State.PeerGroupDecision()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	decision := state.PeerGroupDecision{
+		Time:    time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC),
+		Changed: true,
+		Reasons: []string{`giving machine "1" a vote`},
+	}
+	err = s.State.SetPeerGroupDecision(decision)
+	c.Assert(err, gc.IsNil)
+	obtained, err := s.State.PeerGroupDecision()
+	c.Assert(err, gc.IsNil)
+	obtained.Time = obtained.Time.UTC()
+	c.Assert(obtained, jc.DeepEquals, decision)
+
+	decision = state.PeerGroupDecision{
+		Time:  time.Date(2014, 6, 1, 12, 1, 0, 0, time.UTC),
+		Error: "cannot set replica set",
+	}
+	err = s.State.SetPeerGroupDecision(decision)
+	c.Assert(err, gc.IsNil)
+	obtained, err = s.State.PeerGroupDecision()
+	c.Assert(err, gc.IsNil)
+	c.Assert(obtained.Time.Equal(decision.Time), jc.IsTrue)
+	c.Assert(obtained.Changed, jc.IsFalse)
+	c.Assert(obtained.Reasons, gc.HasLen, 0)
+	c.Assert(obtained.Error, gc.Equals, "cannot set replica set")
+}
+
 func (s *StateSuite) TestEnsureAvailabilityPromotesAvailableMachines(c *gc.C) {
 	changes, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal")
 	c.Assert(err, gc.IsNil)
@@ -3453,16 +3545,121 @@ func (s *StateSuite) TestEnsureAvailabilityConcurrentMore(c *gc.C) {
 	// This call to EnsureAvailability will initially attempt to allocate
 	// machines 0..2, and fail due to the concurrent change. It will then
 	// find that the number of voting machines in state is greater than
-	// what we're attempting to ensure, and fail.
+	// what we're attempting to ensure, and remove the two most recently
+	// added machines, which do not have a vote yet.
 	changes, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal")
-	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: cannot reduce state server count")
+	c.Assert(err, gc.IsNil)
 	c.Assert(changes.Added, gc.HasLen, 0)
+	c.Assert(changes.Removed, jc.SameContents, []string{"6", "7"})
+	expected := []string{"3", "4", "5"}
+	s.assertStateServerInfo(c, expected, expected)
 
 	// Machine 0 should never have been created.
 	_, err = s.State.Machine("0")
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *StateSuite) addVotingStateServers(c *gc.C, n int) {
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return true, nil
+	})
+	changes, err := s.State.EnsureAvailability(n, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes.Added, gc.HasLen, n)
+	for _, id := range changes.Added {
+		m, err := s.State.Machine(id)
+		c.Assert(err, gc.IsNil)
+		err = m.SetHasVote(true)
+		c.Assert(err, gc.IsNil)
+	}
+}
+
+func (s *StateSuite) assertRetired(c *gc.C, id string) {
+	m, err := s.State.Machine(id)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.IsManager(), jc.IsFalse)
+	c.Assert(m.IsRetiring(), jc.IsFalse)
+	c.Assert(m.Life(), gc.Equals, state.Dying)
+}
+
+func (s *StateSuite) TestEnsureAvailabilityReducesCount(c *gc.C) {
+	s.addVotingStateServers(c, 5)
+	changes, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes.Added, gc.HasLen, 0)
+	c.Assert(changes.Demoted, jc.SameContents, []string{"3", "4"})
+	c.Assert(changes.Maintained, jc.SameContents, []string{"0", "1", "2"})
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3", "4"}, []string{"0", "1", "2"})
+
+	// The demoted machines keep their job until they lose their vote.
+	m4, err := s.State.Machine("4")
+	c.Assert(err, gc.IsNil)
+	c.Assert(m4.IsManager(), jc.IsTrue)
+	c.Assert(m4.WantsVote(), jc.IsFalse)
+	c.Assert(m4.IsRetiring(), jc.IsTrue)
+
+	// Calling EnsureAvailability again changes nothing.
+	changes, err = s.State.EnsureAvailability(3, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes.Demoted, gc.HasLen, 0)
+	c.Assert(changes.Removed, gc.HasLen, 0)
+
+	// The machine is retired and destroyed once its vote is removed.
+	err = m4.SetHasVote(false)
+	c.Assert(err, gc.IsNil)
+	s.assertRetired(c, "4")
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"0", "1", "2"})
+}
+
+func (s *StateSuite) TestEnsureAvailabilityReducesCountRemovesMachinesWithoutVote(c *gc.C) {
+	s.addVotingStateServers(c, 3)
+	changes, err := s.State.EnsureAvailability(5, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes.Added, gc.DeepEquals, []string{"3", "4"})
+
+	// Machines 3 and 4 have not been given a vote yet, so they are
+	// removed in preference to the others, and at once.
+	changes, err = s.State.EnsureAvailability(3, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes.Demoted, gc.HasLen, 0)
+	c.Assert(changes.Removed, jc.SameContents, []string{"3", "4"})
+	s.assertStateServerInfo(c, []string{"0", "1", "2"}, []string{"0", "1", "2"})
+	s.assertRetired(c, "3")
+	s.assertRetired(c, "4")
+}
+
+func (s *StateSuite) TestEnsureAvailabilityRemovesGivenMachines(c *gc.C) {
+	s.addVotingStateServers(c, 3)
+	changes, err := s.State.EnsureAvailability(0, constraints.Value{}, "quantal", "1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes.Added, gc.DeepEquals, []string{"3"})
+	c.Assert(changes.Demoted, gc.DeepEquals, []string{"1"})
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"0", "2", "3"})
+
+	// The machine is not promoted again while it is retiring.
+	changes, err = s.State.EnsureAvailability(0, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes.Promoted, gc.HasLen, 0)
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"0", "2", "3"})
+
+	m1, err := s.State.Machine("1")
+	c.Assert(err, gc.IsNil)
+	err = m1.SetHasVote(false)
+	c.Assert(err, gc.IsNil)
+	s.assertRetired(c, "1")
+	s.assertStateServerInfo(c, []string{"0", "2", "3"}, []string{"0", "2", "3"})
+}
+
+func (s *StateSuite) TestEnsureAvailabilityRemoveErrors(c *gc.C) {
+	s.addVotingStateServers(c, 3)
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.EnsureAvailability(0, constraints.Value{}, "quantal", m.Id())
+	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: machine 3 is not a state server")
+	_, err = s.State.EnsureAvailability(0, constraints.Value{}, "quantal", "42")
+	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: machine 42 is not a state server")
+}
+
 func (s *StateSuite) TestStateServingInfo(c *gc.C) {
 	info, err := s.State.StateServingInfo()
 	c.Assert(err, gc.ErrorMatches, "state serving info not found")
@@ -3684,6 +3881,34 @@ func (s *StateSuite) TestWatchAPIHostPorts(c *gc.C) {
 	wc.AssertClosed()
 }
 
+func (s *StateSuite) TestWatchStateServingInfo(c *gc.C) {
+	w := s.State.WatchStateServingInfo()
+	defer statetesting.AssertStop(c, w)
+
+	// Initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	info := params.StateServingInfo{
+		APIPort:    69,
+		StatePort:  80,
+		Cert:       "Some cert",
+		PrivateKey: "Some key",
+	}
+	err := s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	info.Cert = "Some other cert"
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	// Stop, check closed.
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *StateSuite) TestUnitActionsFindsRightActions(c *gc.C) {
 	// Add simple service and two units
 	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
@@ -78,6 +78,9 @@ type unitDoc struct {
 	Life         Life
 	TxnRevno     int64 `bson:"txn-revno"`
 	PasswordHash string
+	// PasswordRotationRequested is set when the unit agent
+	// has been asked to change its password.
+	PasswordRotationRequested bool `bson:",omitempty"`
 
 	// No longer used - to be removed.
 	PublicAddress  string
@@ -223,16 +226,42 @@ func (u *Unit) setPasswordHash(passwordHash string) error {
 		C:      unitsC,
 		Id:     u.doc.Name,
 		Assert: notDeadDoc,
-		Update: bson.D{{"$set", bson.D{{"passwordhash", passwordHash}}}},
+		Update: bson.D{{"$set", bson.D{
+			{"passwordhash", passwordHash},
+			{"passwordrotationrequested", false},
+		}}},
 	}}
 	err := u.st.runTransaction(ops)
 	if err != nil {
 		return fmt.Errorf("cannot set password of unit %q: %v", u, onAbort(err, ErrDead))
 	}
 	u.doc.PasswordHash = passwordHash
+	u.doc.PasswordRotationRequested = false
 	return nil
 }
 
+// RequestPasswordRotation asks the unit agent to change its
+// password. The request is satisfied when the password is next set.
+func (u *Unit) RequestPasswordRotation() error {
+	ops := []txn.Op{{
+		C:      unitsC,
+		Id:     u.doc.Name,
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{{"passwordrotationrequested", true}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot request password rotation for unit %q: %v", u, onAbort(err, ErrDead))
+	}
+	u.doc.PasswordRotationRequested = true
+	return nil
+}
+
+// PasswordRotationRequested returns whether the unit agent has
+// been asked to change its password and has not yet done so.
+func (u *Unit) PasswordRotationRequested() bool {
+	return u.doc.PasswordRotationRequested
+}
+
 // Return the underlying PasswordHash stored in the database. Used by the test
 // suite to check that the PasswordHash gets properly updated to new values
 // when compatibility mode is detected.
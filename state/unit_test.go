@@ -648,6 +648,13 @@ func (s *UnitSuite) TestSetPassword(c *gc.C) {
 	})
 }
 
+func (s *UnitSuite) TestRequestPasswordRotation(c *gc.C) {
+	preventUnitDestroyRemove(c, s.unit)
+	testRequestPasswordRotation(c, func() (passwordRotator, error) {
+		return s.State.Unit(s.unit.Name())
+	})
+}
+
 func (s *UnitSuite) TestSetAgentCompatPassword(c *gc.C) {
 	e, err := s.State.Unit(s.unit.Name())
 	c.Assert(err, gc.IsNil)
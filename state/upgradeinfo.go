@@ -0,0 +1,470 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils/set"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/version"
+)
+
+// UpgradeStatus describes the stage reached by the coordinated upgrade
+// of the state servers.
+type UpgradeStatus string
+
+const (
+	// UpgradePending indicates that the state servers are waiting
+	// for each other to reach the new version.
+	UpgradePending UpgradeStatus = "pending"
+
+	// UpgradeRunning indicates that one state server, holding the
+	// database upgrade lock, is running the database upgrade steps.
+	UpgradeRunning UpgradeStatus = "running"
+
+	// UpgradeFinishing indicates that the database has been
+	// upgraded, and the state servers are running their own upgrade
+	// steps.
+	UpgradeFinishing UpgradeStatus = "finishing"
+
+	// UpgradeComplete indicates that all the state servers have
+	// completed the upgrade.
+	UpgradeComplete UpgradeStatus = "complete"
+
+	// UpgradeAborted indicates that the upgrade was aborted before
+	// the database upgrade steps were committed, and the environment
+	// agent version reverted to the previous version.
+	UpgradeAborted UpgradeStatus = "aborted"
+)
+
+// currentUpgradeId is the id of the document in the upgrade info
+// collection that coordinates the current upgrade.
+const currentUpgradeId = "current"
+
+// upgradeInfoDoc coordinates the upgrade of the state servers to a
+// new version.
+type upgradeInfoDoc struct {
+	Id              string `bson:"_id"`
+	PreviousVersion version.Number
+	TargetVersion   version.Number
+	Status          UpgradeStatus
+	Started         time.Time
+
+	// StateServersReady holds the ids of the state servers that
+	// have reached the target version.
+	StateServersReady []string
+
+	// StateServersDone holds the ids of the state servers that have
+	// completed their upgrade steps.
+	StateServersDone []string
+
+	// DatabaseMaster holds the id of the state server that holds the
+	// database upgrade lock.
+	DatabaseMaster string
+}
+
+// UpgradeInfo represents the coordinated upgrade of the state servers
+// to a new version. The state servers wait for each other to reach
+// the new version; then one of them takes the database upgrade lock
+// and runs the upgrade steps that change the database, exactly once,
+// before the others run their own upgrade steps.
+type UpgradeInfo struct {
+	st  *State
+	doc upgradeInfoDoc
+}
+
+// PreviousVersion returns the version the state servers are upgrading
+// from.
+func (info *UpgradeInfo) PreviousVersion() version.Number {
+	return info.doc.PreviousVersion
+}
+
+// TargetVersion returns the version the state servers are upgrading
+// to.
+func (info *UpgradeInfo) TargetVersion() version.Number {
+	return info.doc.TargetVersion
+}
+
+// Status returns the stage reached by the upgrade.
+func (info *UpgradeInfo) Status() UpgradeStatus {
+	return info.doc.Status
+}
+
+// Started returns when the upgrade was started.
+func (info *UpgradeInfo) Started() time.Time {
+	return info.doc.Started
+}
+
+// StateServersReady returns the ids of the state servers that have
+// reached the target version.
+func (info *UpgradeInfo) StateServersReady() []string {
+	return info.doc.StateServersReady
+}
+
+// StateServersDone returns the ids of the state servers that have
+// completed their upgrade steps.
+func (info *UpgradeInfo) StateServersDone() []string {
+	return info.doc.StateServersDone
+}
+
+// DatabaseMaster returns the id of the state server that holds the
+// database upgrade lock, if any.
+func (info *UpgradeInfo) DatabaseMaster() string {
+	return info.doc.DatabaseMaster
+}
+
+// Refresh refreshes the contents of the UpgradeInfo from the
+// underlying state.
+func (info *UpgradeInfo) Refresh() error {
+	doc, err := info.st.currentUpgradeInfoDoc()
+	if err != nil {
+		return err
+	}
+	info.doc = *doc
+	return nil
+}
+
+// Watch returns a watcher that notifies of changes to the upgrade.
+func (info *UpgradeInfo) Watch() NotifyWatcher {
+	return newEntityWatcher(info.st, upgradeInfoC, currentUpgradeId)
+}
+
+func (st *State) currentUpgradeInfoDoc() (*upgradeInfoDoc, error) {
+	upgradeInfos, closer := st.getCollection(upgradeInfoC)
+	defer closer()
+
+	var doc upgradeInfoDoc
+	err := upgradeInfos.FindId(currentUpgradeId).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("current upgrade info")
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read upgrade info: %v", err)
+	}
+	return &doc, nil
+}
+
+// UpgradeInfo returns the coordinated upgrade of the state servers
+// that is under way, or that was completed most recently. It returns
+// an error satisfying errors.IsNotFound if there has been none.
+func (st *State) UpgradeInfo() (*UpgradeInfo, error) {
+	doc, err := st.currentUpgradeInfoDoc()
+	if err != nil {
+		return nil, err
+	}
+	return &UpgradeInfo{st: st, doc: *doc}, nil
+}
+
+// EnsureUpgradeInfo records that the state server with the given
+// machine id has reached the target version, starting a coordinated
+// upgrade from the previous version if none is under way, and returns
+// the upgrade. It fails if an upgrade to another version is under way,
+// or if the upgrade to the target version has been aborted.
+func (st *State) EnsureUpgradeInfo(machineId string, previousVersion, targetVersion version.Number) (info *UpgradeInfo, err error) {
+	defer errors.Maskf(&err, "cannot ensure upgrade info for state server %s", machineId)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		stateServerInfo, err := st.StateServerInfo()
+		if err != nil {
+			return nil, err
+		}
+		if !set.NewStrings(stateServerInfo.MachineIds...).Contains(machineId) {
+			return nil, fmt.Errorf("machine %s is not a state server", machineId)
+		}
+		doc, err := st.currentUpgradeInfoDoc()
+		if errors.IsNotFound(err) {
+			return []txn.Op{{
+				C:      upgradeInfoC,
+				Id:     currentUpgradeId,
+				Assert: txn.DocMissing,
+				Insert: newUpgradeInfoDoc(machineId, previousVersion, targetVersion),
+			}}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if doc.TargetVersion != targetVersion {
+			if doc.Status != UpgradeComplete && doc.Status != UpgradeAborted {
+				return nil, fmt.Errorf("an upgrade to %s is under way", doc.TargetVersion)
+			}
+			// The previous upgrade has completed or been aborted;
+			// start a new one.
+			newDoc := newUpgradeInfoDoc(machineId, previousVersion, targetVersion)
+			return []txn.Op{{
+				C:  upgradeInfoC,
+				Id: currentUpgradeId,
+				Assert: bson.D{
+					{"targetversion", doc.TargetVersion},
+					{"status", doc.Status},
+				},
+				Update: bson.D{{"$set", bson.D{
+					{"previousversion", newDoc.PreviousVersion},
+					{"targetversion", newDoc.TargetVersion},
+					{"status", newDoc.Status},
+					{"started", newDoc.Started},
+					{"stateserversready", newDoc.StateServersReady},
+					{"stateserversdone", newDoc.StateServersDone},
+					{"databasemaster", newDoc.DatabaseMaster},
+				}}},
+			}}, nil
+		}
+		if doc.Status == UpgradeAborted {
+			return nil, fmt.Errorf("upgrade to %s has been aborted", doc.TargetVersion)
+		}
+		if set.NewStrings(doc.StateServersReady...).Contains(machineId) {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:      upgradeInfoC,
+			Id:     currentUpgradeId,
+			Assert: bson.D{{"targetversion", targetVersion}},
+			Update: bson.D{{"$addToSet", bson.D{{"stateserversready", machineId}}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return nil, err
+	}
+	return st.UpgradeInfo()
+}
+
+func newUpgradeInfoDoc(machineId string, previousVersion, targetVersion version.Number) upgradeInfoDoc {
+	return upgradeInfoDoc{
+		Id:                currentUpgradeId,
+		PreviousVersion:   previousVersion,
+		TargetVersion:     targetVersion,
+		Status:            UpgradePending,
+		Started:           time.Now(),
+		StateServersReady: []string{machineId},
+		StateServersDone:  []string{},
+	}
+}
+
+// PendingStateServers returns the ids of the provisioned state servers
+// that have not yet reached the target version.
+func (info *UpgradeInfo) PendingStateServers() ([]string, error) {
+	stateServerInfo, err := info.st.StateServerInfo()
+	if err != nil {
+		return nil, err
+	}
+	ready := set.NewStrings(info.doc.StateServersReady...)
+	var pending []string
+	for _, id := range stateServerInfo.MachineIds {
+		if ready.Contains(id) {
+			continue
+		}
+		m, err := info.st.Machine(id)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if m.Life() == Dead {
+			continue
+		}
+		if _, err := m.InstanceId(); IsNotProvisionedError(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		pending = append(pending, id)
+	}
+	return pending, nil
+}
+
+// AcquireDatabaseLock takes the database upgrade lock for the state
+// server with the given machine id, so that it runs the database
+// upgrade steps, and reports whether it holds the lock. The lock can
+// only be taken while the upgrade is pending, and is kept by the same
+// state server until it calls SetDatabaseUpgradeDone, so that it can
+// retry failed steps.
+func (info *UpgradeInfo) AcquireDatabaseLock(machineId string) (acquired bool, err error) {
+	defer errors.Maskf(&err, "cannot acquire database upgrade lock")
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := info.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		if info.doc.Status != UpgradePending {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:  upgradeInfoC,
+			Id: currentUpgradeId,
+			Assert: bson.D{
+				{"targetversion", info.doc.TargetVersion},
+				{"status", UpgradePending},
+			},
+			Update: bson.D{{"$set", bson.D{
+				{"status", UpgradeRunning},
+				{"databasemaster", machineId},
+			}}},
+		}}, nil
+	}
+	if err := info.st.run(buildTxn); err != nil {
+		return false, err
+	}
+	if err := info.Refresh(); err != nil {
+		return false, err
+	}
+	return info.doc.Status == UpgradeRunning && info.doc.DatabaseMaster == machineId, nil
+}
+
+// TakeOverDatabaseLock moves the database upgrade lock to the state
+// server with the given machine id from the state server last seen
+// holding it, and reports whether it holds the lock. It is used when
+// the holder has gone away while running the database upgrade steps;
+// as with a retry by the holder itself, the new holder runs the steps
+// again from the start.
+func (info *UpgradeInfo) TakeOverDatabaseLock(machineId string) (acquired bool, err error) {
+	defer errors.Maskf(&err, "cannot take over database upgrade lock")
+	if info.doc.Status != UpgradeRunning {
+		return false, fmt.Errorf("database upgrade steps are not being run")
+	}
+	ops := []txn.Op{{
+		C:  upgradeInfoC,
+		Id: currentUpgradeId,
+		Assert: bson.D{
+			{"targetversion", info.doc.TargetVersion},
+			{"status", UpgradeRunning},
+			{"databasemaster", info.doc.DatabaseMaster},
+		},
+		Update: bson.D{{"$set", bson.D{{"databasemaster", machineId}}}},
+	}}
+	if err := info.st.runTransaction(ops); err != nil && err != txn.ErrAborted {
+		return false, err
+	}
+	if err := info.Refresh(); err != nil {
+		return false, err
+	}
+	return info.doc.Status == UpgradeRunning && info.doc.DatabaseMaster == machineId, nil
+}
+
+// SetDatabaseUpgradeDone records that the state server with the given
+// machine id, holding the database upgrade lock, has run the database
+// upgrade steps, and releases the lock.
+func (info *UpgradeInfo) SetDatabaseUpgradeDone(machineId string) (err error) {
+	defer errors.Maskf(&err, "cannot complete database upgrade")
+	ops := []txn.Op{{
+		C:  upgradeInfoC,
+		Id: currentUpgradeId,
+		Assert: bson.D{
+			{"targetversion", info.doc.TargetVersion},
+			{"status", UpgradeRunning},
+			{"databasemaster", machineId},
+		},
+		Update: bson.D{{"$set", bson.D{{"status", UpgradeFinishing}}}},
+	}}
+	if err := info.st.runTransaction(ops); err == txn.ErrAborted {
+		return fmt.Errorf("state server %s does not hold the database upgrade lock", machineId)
+	} else if err != nil {
+		return err
+	}
+	return info.Refresh()
+}
+
+// SetStateServerDone records that the state server with the given
+// machine id has completed its upgrade steps. Once all the state
+// servers that took part in the upgrade have done so, the upgrade is
+// complete.
+func (info *UpgradeInfo) SetStateServerDone(machineId string) (err error) {
+	defer errors.Maskf(&err, "cannot complete upgrade of state server %s", machineId)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := info.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		switch info.doc.Status {
+		case UpgradeFinishing:
+		case UpgradeComplete:
+			return nil, jujutxn.ErrNoOperations
+		default:
+			return nil, fmt.Errorf("database upgrade has not completed")
+		}
+		done := set.NewStrings(info.doc.StateServersDone...)
+		if done.Contains(machineId) {
+			return nil, jujutxn.ErrNoOperations
+		}
+		done.Add(machineId)
+		update := bson.D{{"stateserversdone", done.SortedValues()}}
+		if set.NewStrings(info.doc.StateServersReady...).Difference(done).IsEmpty() {
+			update = append(update, bson.DocElem{"status", UpgradeComplete})
+		}
+		// State servers are only ever added to the done list, so
+		// asserting its size ensures that the last state server to
+		// finish completes the upgrade.
+		return []txn.Op{{
+			C:  upgradeInfoC,
+			Id: currentUpgradeId,
+			Assert: bson.D{
+				{"targetversion", info.doc.TargetVersion},
+				{"status", UpgradeFinishing},
+				{"stateserversdone", bson.D{{"$size", len(info.doc.StateServersDone)}}},
+			},
+			Update: bson.D{{"$set", update}},
+		}}, nil
+	}
+	if err := info.st.run(buildTxn); err != nil {
+		return err
+	}
+	return info.Refresh()
+}
+
+// AbortUpgrade aborts the coordinated upgrade under way, as long as
+// its database upgrade steps have not started, and reverts the
+// environment agent version to the version upgraded from, so that the
+// agents switch back to their previous tools. It returns the version
+// reverted to.
+func (st *State) AbortUpgrade() (previousVersion version.Number, err error) {
+	defer errors.Maskf(&err, "cannot abort upgrade")
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := st.currentUpgradeInfoDoc()
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("no upgrade is under way")
+		} else if err != nil {
+			return nil, err
+		}
+		switch doc.Status {
+		case UpgradePending:
+		case UpgradeComplete:
+			return nil, fmt.Errorf("no upgrade is under way")
+		case UpgradeAborted:
+			return nil, fmt.Errorf("upgrade to %s has already been aborted", doc.TargetVersion)
+		case UpgradeRunning:
+			// Each database upgrade step commits its own changes,
+			// so the database may already be partly upgraded.
+			return nil, fmt.Errorf("database upgrade steps for %s are being run", doc.TargetVersion)
+		default:
+			return nil, fmt.Errorf("database upgrade steps for %s have already been committed", doc.TargetVersion)
+		}
+		settings, err := readSettings(st, environGlobalKey)
+		if err != nil {
+			return nil, err
+		}
+		previousVersion = doc.PreviousVersion
+		return []txn.Op{{
+			C:  upgradeInfoC,
+			Id: currentUpgradeId,
+			Assert: bson.D{
+				{"targetversion", doc.TargetVersion},
+				{"status", doc.Status},
+			},
+			Update: bson.D{{"$set", bson.D{{"status", UpgradeAborted}}}},
+		}, {
+			C:      settingsC,
+			Id:     environGlobalKey,
+			Assert: bson.D{{"txn-revno", settings.txnRevno}},
+			Update: bson.D{{"$set", bson.D{{"agent-version", previousVersion.String()}}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return version.Number{}, err
+	}
+	return previousVersion, nil
+}
@@ -0,0 +1,256 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/version"
+)
+
+type UpgradeInfoSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&UpgradeInfoSuite{})
+
+var (
+	upgradeFrom = version.MustParse("1.20.0")
+	upgradeTo   = version.MustParse("1.21.0")
+)
+
+func (s *UpgradeInfoSuite) addStateServers(c *gc.C, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+		c.Assert(err, gc.IsNil)
+		err = m.SetProvisioned(instance.Id("i-"+m.Id()), "fake_nonce", nil)
+		c.Assert(err, gc.IsNil)
+		ids[i] = m.Id()
+	}
+	return ids
+}
+
+func (s *UpgradeInfoSuite) TestEnsureUpgradeInfo(c *gc.C) {
+	ids := s.addStateServers(c, 3)
+	_, err := s.State.UpgradeInfo()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	info, err := s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.PreviousVersion(), gc.Equals, upgradeFrom)
+	c.Assert(info.TargetVersion(), gc.Equals, upgradeTo)
+	c.Assert(info.Status(), gc.Equals, state.UpgradePending)
+	c.Assert(info.Started().IsZero(), jc.IsFalse)
+	c.Assert(info.StateServersReady(), jc.DeepEquals, []string{ids[0]})
+	pending, err := info.PendingStateServers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(pending, jc.DeepEquals, []string{ids[1], ids[2]})
+
+	info, err = s.State.EnsureUpgradeInfo(ids[1], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	info, err = s.State.EnsureUpgradeInfo(ids[1], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.StateServersReady(), jc.DeepEquals, []string{ids[0], ids[1]})
+	pending, err = info.PendingStateServers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(pending, jc.DeepEquals, []string{ids[2]})
+
+	_, err = s.State.EnsureUpgradeInfo(ids[2], upgradeFrom, version.MustParse("1.22.0"))
+	c.Assert(err, gc.ErrorMatches, "cannot ensure upgrade info for state server 2: an upgrade to 1.21.0 is under way")
+}
+
+func (s *UpgradeInfoSuite) TestEnsureUpgradeInfoNotStateServer(c *gc.C) {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.EnsureUpgradeInfo(m.Id(), upgradeFrom, upgradeTo)
+	c.Assert(err, gc.ErrorMatches, "cannot ensure upgrade info for state server 0: machine 0 is not a state server")
+}
+
+func (s *UpgradeInfoSuite) TestPendingStateServersIgnoresUnprovisioned(c *gc.C) {
+	ids := s.addStateServers(c, 1)
+	_, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	info, err := s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	pending, err := info.PendingStateServers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(pending, gc.HasLen, 0)
+}
+
+func (s *UpgradeInfoSuite) TestUpgradeSequence(c *gc.C) {
+	ids := s.addStateServers(c, 2)
+	info0, err := s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	info1, err := s.State.EnsureUpgradeInfo(ids[1], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+
+	err = info1.SetStateServerDone(ids[1])
+	c.Assert(err, gc.ErrorMatches, "cannot complete upgrade of state server 1: database upgrade has not completed")
+
+	// Only one state server takes the lock.
+	acquired, err := info1.AcquireDatabaseLock(ids[1])
+	c.Assert(err, gc.IsNil)
+	c.Assert(acquired, jc.IsTrue)
+	acquired, err = info0.AcquireDatabaseLock(ids[0])
+	c.Assert(err, gc.IsNil)
+	c.Assert(acquired, jc.IsFalse)
+	c.Assert(info0.Status(), gc.Equals, state.UpgradeRunning)
+	c.Assert(info0.DatabaseMaster(), gc.Equals, ids[1])
+
+	err = info0.SetDatabaseUpgradeDone(ids[0])
+	c.Assert(err, gc.ErrorMatches, "cannot complete database upgrade: state server 0 does not hold the database upgrade lock")
+	err = info1.SetDatabaseUpgradeDone(ids[1])
+	c.Assert(err, gc.IsNil)
+	c.Assert(info1.Status(), gc.Equals, state.UpgradeFinishing)
+
+	err = info0.Refresh()
+	c.Assert(err, gc.IsNil)
+	err = info0.SetStateServerDone(ids[0])
+	c.Assert(err, gc.IsNil)
+	c.Assert(info0.Status(), gc.Equals, state.UpgradeFinishing)
+	err = info1.SetStateServerDone(ids[1])
+	c.Assert(err, gc.IsNil)
+	c.Assert(info1.Status(), gc.Equals, state.UpgradeComplete)
+	c.Assert(info1.StateServersDone(), jc.DeepEquals, []string{ids[0], ids[1]})
+
+	// A new upgrade replaces the completed one.
+	next := version.MustParse("1.22.0")
+	info, err := s.State.EnsureUpgradeInfo(ids[0], upgradeTo, next)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.TargetVersion(), gc.Equals, next)
+	c.Assert(info.Status(), gc.Equals, state.UpgradePending)
+	c.Assert(info.StateServersReady(), jc.DeepEquals, []string{ids[0]})
+	c.Assert(info.StateServersDone(), gc.HasLen, 0)
+	c.Assert(info.DatabaseMaster(), gc.Equals, "")
+}
+
+func (s *UpgradeInfoSuite) TestTakeOverDatabaseLock(c *gc.C) {
+	ids := s.addStateServers(c, 3)
+	info0, err := s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	_, err = info0.TakeOverDatabaseLock(ids[0])
+	c.Assert(err, gc.ErrorMatches, "cannot take over database upgrade lock: database upgrade steps are not being run")
+
+	info1, err := s.State.EnsureUpgradeInfo(ids[1], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	info2, err := s.State.EnsureUpgradeInfo(ids[2], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	acquired, err := info0.AcquireDatabaseLock(ids[0])
+	c.Assert(err, gc.IsNil)
+	c.Assert(acquired, jc.IsTrue)
+
+	// Only one state server takes the lock over from the holder.
+	err = info1.Refresh()
+	c.Assert(err, gc.IsNil)
+	err = info2.Refresh()
+	c.Assert(err, gc.IsNil)
+	acquired, err = info1.TakeOverDatabaseLock(ids[1])
+	c.Assert(err, gc.IsNil)
+	c.Assert(acquired, jc.IsTrue)
+	acquired, err = info2.TakeOverDatabaseLock(ids[2])
+	c.Assert(err, gc.IsNil)
+	c.Assert(acquired, jc.IsFalse)
+	c.Assert(info2.DatabaseMaster(), gc.Equals, ids[1])
+
+	// The previous holder can no longer complete the steps.
+	err = info0.SetDatabaseUpgradeDone(ids[0])
+	c.Assert(err, gc.ErrorMatches, "cannot complete database upgrade: state server 0 does not hold the database upgrade lock")
+	err = info1.SetDatabaseUpgradeDone(ids[1])
+	c.Assert(err, gc.IsNil)
+	c.Assert(info1.Status(), gc.Equals, state.UpgradeFinishing)
+}
+
+func (s *UpgradeInfoSuite) assertAgentVersion(c *gc.C, expect version.Number) {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	agentVersion, ok := cfg.AgentVersion()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(agentVersion, gc.Equals, expect)
+}
+
+func (s *UpgradeInfoSuite) TestAbortUpgradeNoUpgrade(c *gc.C) {
+	_, err := s.State.AbortUpgrade()
+	c.Assert(err, gc.ErrorMatches, "cannot abort upgrade: no upgrade is under way")
+}
+
+func (s *UpgradeInfoSuite) TestAbortUpgradeWhilePending(c *gc.C) {
+	ids := s.addStateServers(c, 2)
+	err := statetesting.SetAgentVersion(s.State, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+
+	reverted, err := s.State.AbortUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(reverted, gc.Equals, upgradeFrom)
+	s.assertAgentVersion(c, upgradeFrom)
+	info, err := s.State.UpgradeInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Status(), gc.Equals, state.UpgradeAborted)
+
+	_, err = s.State.AbortUpgrade()
+	c.Assert(err, gc.ErrorMatches, "cannot abort upgrade: upgrade to 1.21.0 has already been aborted")
+
+	// State servers still running the aborted version cannot join it.
+	_, err = s.State.EnsureUpgradeInfo(ids[1], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.ErrorMatches, "cannot ensure upgrade info for state server 1: upgrade to 1.21.0 has been aborted")
+
+	// A new upgrade replaces the aborted one.
+	next := version.MustParse("1.22.0")
+	info, err = s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, next)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.TargetVersion(), gc.Equals, next)
+	c.Assert(info.Status(), gc.Equals, state.UpgradePending)
+}
+
+func (s *UpgradeInfoSuite) TestAbortUpgradeWhileRunningDatabaseSteps(c *gc.C) {
+	ids := s.addStateServers(c, 1)
+	err := statetesting.SetAgentVersion(s.State, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	info, err := s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	acquired, err := info.AcquireDatabaseLock(ids[0])
+	c.Assert(err, gc.IsNil)
+	c.Assert(acquired, jc.IsTrue)
+
+	// The database may already be partly upgraded, so the upgrade
+	// cannot be aborted.
+	_, err = s.State.AbortUpgrade()
+	c.Assert(err, gc.ErrorMatches, "cannot abort upgrade: database upgrade steps for 1.21.0 are being run")
+	s.assertAgentVersion(c, upgradeTo)
+	info, err = s.State.UpgradeInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Status(), gc.Equals, state.UpgradeRunning)
+
+	// And the database upgrade steps can still be committed.
+	err = info.SetDatabaseUpgradeDone(ids[0])
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *UpgradeInfoSuite) TestAbortUpgradeAfterDatabaseSteps(c *gc.C) {
+	ids := s.addStateServers(c, 1)
+	err := statetesting.SetAgentVersion(s.State, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	info, err := s.State.EnsureUpgradeInfo(ids[0], upgradeFrom, upgradeTo)
+	c.Assert(err, gc.IsNil)
+	_, err = info.AcquireDatabaseLock(ids[0])
+	c.Assert(err, gc.IsNil)
+	err = info.SetDatabaseUpgradeDone(ids[0])
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.State.AbortUpgrade()
+	c.Assert(err, gc.ErrorMatches, "cannot abort upgrade: database upgrade steps for 1.21.0 have already been committed")
+	s.assertAgentVersion(c, upgradeTo)
+
+	err = info.SetStateServerDone(ids[0])
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AbortUpgrade()
+	c.Assert(err, gc.ErrorMatches, "cannot abort upgrade: no upgrade is under way")
+}
@@ -0,0 +1,216 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/version"
+)
+
+// UpgradeStep describes the progress of an upgrade step run by an
+// agent.
+type UpgradeStep struct {
+	// Target holds the type of machine the step is run for.
+	Target string
+
+	// Description holds the step's description.
+	Description string
+
+	// Status holds the progress of the step.
+	Status params.UpgradeStepStatus
+
+	// Error holds the error with which the step failed, if it did.
+	Error string
+}
+
+// UpgradeProgress describes the progress of an agent's upgrade to a
+// version.
+type UpgradeProgress struct {
+	// Agent holds the tag of the agent.
+	Agent string
+
+	// ToVersion holds the version the agent is upgrading to.
+	ToVersion version.Number
+
+	// Steps holds the upgrade steps to run, in order.
+	Steps []UpgradeStep
+
+	// Completed holds whether the upgrade has completed.
+	Completed bool
+
+	// Updated holds when the progress was last recorded.
+	Updated time.Time
+
+	// Skip holds the descriptions of the failed steps that the
+	// user has asked the agent to skip.
+	Skip []string
+}
+
+// upgradeProgressDoc records the progress of an agent's upgrade. The
+// _id field is the agent's tag.
+type upgradeProgressDoc struct {
+	Id        string `bson:"_id"`
+	ToVersion version.Number
+	Steps     []UpgradeStep
+	Completed bool
+	Updated   time.Time
+	Skip      []string
+}
+
+func (doc *upgradeProgressDoc) progress() UpgradeProgress {
+	return UpgradeProgress{
+		Agent:     doc.Id,
+		ToVersion: doc.ToVersion,
+		Steps:     doc.Steps,
+		Completed: doc.Completed,
+		Updated:   doc.Updated,
+		Skip:      doc.Skip,
+	}
+}
+
+// SetUpgradeProgress records the progress of an agent's upgrade. The
+// Skip field is ignored: the steps to skip are only changed by
+// SkipFailedUpgradeStep, and are forgotten when the agent starts
+// upgrading to a different version.
+func (st *State) SetUpgradeProgress(progress UpgradeProgress) error {
+	upgrades, closer := st.getCollection(upgradeProgressC)
+	defer closer()
+
+	updated := time.Now()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		var current upgradeProgressDoc
+		err := upgrades.FindId(progress.Agent).One(&current)
+		if err == mgo.ErrNotFound {
+			return []txn.Op{{
+				C:      upgradeProgressC,
+				Id:     progress.Agent,
+				Assert: txn.DocMissing,
+				Insert: upgradeProgressDoc{
+					Id:        progress.Agent,
+					ToVersion: progress.ToVersion,
+					Steps:     progress.Steps,
+					Completed: progress.Completed,
+					Updated:   updated,
+				},
+			}}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		set := bson.D{
+			{"toversion", progress.ToVersion},
+			{"steps", progress.Steps},
+			{"completed", progress.Completed},
+			{"updated", updated},
+		}
+		if current.ToVersion != progress.ToVersion {
+			set = append(set, bson.DocElem{"skip", []string(nil)})
+		}
+		return []txn.Op{{
+			C:      upgradeProgressC,
+			Id:     progress.Agent,
+			Assert: bson.D{{"toversion", current.ToVersion}},
+			Update: bson.D{{"$set", set}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return fmt.Errorf("cannot set upgrade progress of %s: %v", progress.Agent, err)
+	}
+	return nil
+}
+
+// UpgradeProgress returns the progress of the upgrade of the agent
+// with the given tag. It returns an error satisfying errors.IsNotFound
+// if the agent has not recorded any progress.
+func (st *State) UpgradeProgress(agent string) (UpgradeProgress, error) {
+	upgrades, closer := st.getCollection(upgradeProgressC)
+	defer closer()
+
+	var doc upgradeProgressDoc
+	err := upgrades.FindId(agent).One(&doc)
+	if err == mgo.ErrNotFound {
+		return UpgradeProgress{}, errors.NotFoundf("upgrade progress of %s", agent)
+	} else if err != nil {
+		return UpgradeProgress{}, fmt.Errorf("cannot get upgrade progress of %s: %v", agent, err)
+	}
+	return doc.progress(), nil
+}
+
+// AllUpgradeProgress returns the progress of the upgrades of all the
+// agents that have recorded any, ordered by agent tag.
+func (st *State) AllUpgradeProgress() ([]UpgradeProgress, error) {
+	upgrades, closer := st.getCollection(upgradeProgressC)
+	defer closer()
+
+	var docs []upgradeProgressDoc
+	if err := upgrades.Find(nil).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get upgrade progress: %v", err)
+	}
+	result := make([]UpgradeProgress, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.progress()
+	}
+	sort.Sort(upgradeProgressByAgent(result))
+	return result, nil
+}
+
+type upgradeProgressByAgent []UpgradeProgress
+
+func (p upgradeProgressByAgent) Len() int           { return len(p) }
+func (p upgradeProgressByAgent) Less(i, j int) bool { return p[i].Agent < p[j].Agent }
+func (p upgradeProgressByAgent) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// SkipFailedUpgradeStep asks the agent with the given tag to skip the
+// upgrade step that has failed, rather than retrying it, and returns
+// the step's description. The agent skips the step the next time it
+// retries its upgrade.
+func (st *State) SkipFailedUpgradeStep(agent string) (description string, err error) {
+	defer errors.Maskf(&err, "cannot skip upgrade step of %s", agent)
+	upgrades, closer := st.getCollection(upgradeProgressC)
+	defer closer()
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		var doc upgradeProgressDoc
+		err := upgrades.FindId(agent).One(&doc)
+		if err == mgo.ErrNotFound {
+			return nil, errors.NotFoundf("upgrade progress")
+		} else if err != nil {
+			return nil, err
+		}
+		description = ""
+		for _, step := range doc.Steps {
+			if step.Status == params.UpgradeStepFailed {
+				description = step.Description
+				break
+			}
+		}
+		if description == "" {
+			return nil, fmt.Errorf("no upgrade step has failed")
+		}
+		return []txn.Op{{
+			C:  upgradeProgressC,
+			Id: agent,
+			Assert: bson.D{
+				{"toversion", doc.ToVersion},
+				{"steps", bson.D{{"$elemMatch", bson.D{
+					{"description", description},
+					{"status", params.UpgradeStepFailed},
+				}}}},
+			},
+			Update: bson.D{{"$addToSet", bson.D{{"skip", description}}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return "", err
+	}
+	return description, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/version"
+)
+
+type UpgradeProgressSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&UpgradeProgressSuite{})
+
+func failedProgress(agent string, toVersion version.Number) state.UpgradeProgress {
+	return state.UpgradeProgress{
+		Agent:     agent,
+		ToVersion: toVersion,
+		Steps: []state.UpgradeStep{{
+			Target:      "stateServer",
+			Description: "step 1",
+			Status:      params.UpgradeStepDone,
+		}, {
+			Target:      "stateServer",
+			Description: "step 2",
+			Status:      params.UpgradeStepFailed,
+			Error:       "boom",
+		}, {
+			Target:      "hostMachine",
+			Description: "step 3",
+			Status:      params.UpgradeStepPending,
+		}},
+	}
+}
+
+func (s *UpgradeProgressSuite) TestSetUpgradeProgress(c *gc.C) {
+	_, err := s.State.UpgradeProgress("machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "upgrade progress of machine-0 not found")
+
+	progress := failedProgress("machine-0", version.MustParse("1.21.0"))
+	err = s.State.SetUpgradeProgress(progress)
+	c.Assert(err, gc.IsNil)
+	obtained, err := s.State.UpgradeProgress("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(obtained.Updated.IsZero(), jc.IsFalse)
+	obtained.Updated = progress.Updated
+	c.Assert(obtained, jc.DeepEquals, progress)
+
+	progress.Steps = progress.Steps[:1]
+	progress.Completed = true
+	err = s.State.SetUpgradeProgress(progress)
+	c.Assert(err, gc.IsNil)
+	obtained, err = s.State.UpgradeProgress("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(obtained.Steps, jc.DeepEquals, progress.Steps)
+	c.Assert(obtained.Completed, jc.IsTrue)
+}
+
+func (s *UpgradeProgressSuite) TestAllUpgradeProgress(c *gc.C) {
+	all, err := s.State.AllUpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(all, gc.HasLen, 0)
+
+	for _, agent := range []string{"machine-1", "machine-0"} {
+		err := s.State.SetUpgradeProgress(failedProgress(agent, version.MustParse("1.21.0")))
+		c.Assert(err, gc.IsNil)
+	}
+	all, err = s.State.AllUpgradeProgress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(all, gc.HasLen, 2)
+	c.Assert(all[0].Agent, gc.Equals, "machine-0")
+	c.Assert(all[1].Agent, gc.Equals, "machine-1")
+}
+
+func (s *UpgradeProgressSuite) TestSkipFailedUpgradeStep(c *gc.C) {
+	progress := failedProgress("machine-0", version.MustParse("1.21.0"))
+	err := s.State.SetUpgradeProgress(progress)
+	c.Assert(err, gc.IsNil)
+
+	description, err := s.State.SkipFailedUpgradeStep("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(description, gc.Equals, "step 2")
+	obtained, err := s.State.UpgradeProgress("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(obtained.Skip, jc.DeepEquals, []string{"step 2"})
+
+	// Recording progress to the same version keeps the steps
+	// to skip.
+	progress.Steps[1].Status = params.UpgradeStepSkipped
+	err = s.State.SetUpgradeProgress(progress)
+	c.Assert(err, gc.IsNil)
+	obtained, err = s.State.UpgradeProgress("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(obtained.Skip, jc.DeepEquals, []string{"step 2"})
+
+	_, err = s.State.SkipFailedUpgradeStep("machine-0")
+	c.Assert(err, gc.ErrorMatches, "cannot skip upgrade step of machine-0: no upgrade step has failed")
+
+	// Upgrading to another version forgets them.
+	err = s.State.SetUpgradeProgress(failedProgress("machine-0", version.MustParse("1.22.0")))
+	c.Assert(err, gc.IsNil)
+	obtained, err = s.State.UpgradeProgress("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(obtained.Skip, gc.HasLen, 0)
+}
+
+func (s *UpgradeProgressSuite) TestSkipFailedUpgradeStepNotFound(c *gc.C) {
+	_, err := s.State.SkipFailedUpgradeStep("machine-0")
+	c.Assert(err, gc.ErrorMatches, "cannot skip upgrade step of machine-0: upgrade progress not found")
+}
@@ -0,0 +1,391 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/instance"
+)
+
+// StorageDirective describes a block storage volume required by each
+// unit of a service.
+type StorageDirective struct {
+	// Pool holds the name of the storage pool the volume is created
+	// in. The only pool is "loop", supported by the local provider.
+	Pool string
+
+	// Size holds the size of the volume in MiB.
+	Size uint64
+}
+
+var validStorageName = regexp.MustCompile("^[a-z][a-z0-9-]*$")
+
+// SetStorageDirectives sets the block storage volumes required by each
+// unit of the service, keyed by storage name. It must be called before
+// any units are added to the service.
+func (s *Service) SetStorageDirectives(directives map[string]StorageDirective) (err error) {
+	defer errors.Maskf(&err, "cannot set storage for service %q", s)
+	for name, directive := range directives {
+		if !validStorageName.MatchString(name) {
+			return fmt.Errorf("invalid storage name %q", name)
+		}
+		if directive.Pool == "" {
+			return fmt.Errorf("no pool specified for storage %q", name)
+		}
+		if directive.Size == 0 {
+			return fmt.Errorf("no size specified for storage %q", name)
+		}
+	}
+	update := bson.D{{"$set", bson.D{{"storage", directives}}}}
+	if len(directives) == 0 {
+		update = bson.D{{"$unset", bson.D{{"storage", nil}}}}
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     s.doc.Name,
+		Assert: append(isAliveDoc, bson.DocElem{"unitcount", 0}),
+		Update: update,
+	}}
+	if err := s.st.runTransaction(ops); err == txn.ErrAborted {
+		return fmt.Errorf("service is not alive or has units")
+	} else if err != nil {
+		return err
+	}
+	s.doc.Storage = copyStorageDirectives(directives)
+	return nil
+}
+
+// StorageDirectives returns the block storage volumes required by each
+// unit of the service, keyed by storage name.
+func (s *Service) StorageDirectives() map[string]StorageDirective {
+	return copyStorageDirectives(s.doc.Storage)
+}
+
+func copyStorageDirectives(directives map[string]StorageDirective) map[string]StorageDirective {
+	if len(directives) == 0 {
+		return nil
+	}
+	result := make(map[string]StorageDirective)
+	for k, v := range directives {
+		result[k] = v
+	}
+	return result
+}
+
+// addUnitVolumeOps returns the operations needed to record the volumes
+// required by the named new unit of the service.
+func (s *Service) addUnitVolumeOps(unitName string) ([]txn.Op, error) {
+	var storageNames []string
+	for name := range s.doc.Storage {
+		storageNames = append(storageNames, name)
+	}
+	sort.Strings(storageNames)
+	var ops []txn.Op
+	for _, name := range storageNames {
+		directive := s.doc.Storage[name]
+		seq, err := s.st.sequence("volume")
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, txn.Op{
+			C:      volumesC,
+			Id:     strconv.Itoa(seq),
+			Assert: txn.DocMissing,
+			Insert: volumeDoc{
+				Id:   strconv.Itoa(seq),
+				Name: name,
+				Unit: unitName,
+				Pool: directive.Pool,
+				Size: directive.Size,
+			},
+		})
+	}
+	return ops, nil
+}
+
+// VolumeStatus describes the state of a volume.
+type VolumeStatus string
+
+const (
+	// VolumePending indicates that the volume has yet to be
+	// created or attached.
+	VolumePending VolumeStatus = "pending"
+
+	// VolumeAttached indicates that the volume is attached to the
+	// instance of its unit's machine.
+	VolumeAttached VolumeStatus = "attached"
+
+	// VolumeDetached indicates that the volume has been detached
+	// from the instance of its released unit.
+	VolumeDetached VolumeStatus = "detached"
+
+	// VolumeError indicates that the last attempt to create, attach
+	// or detach the volume failed.
+	VolumeError VolumeStatus = "error"
+)
+
+// volumeDoc represents a block storage volume required by a unit.
+type volumeDoc struct {
+	Id         string `bson:"_id"`
+	Name       string
+	Unit       string
+	Pool       string
+	Size       uint64
+	Released   bool
+	VolumeId   string
+	InstanceId instance.Id
+	Device     string
+	Error      string
+}
+
+// Volume represents a block storage volume required by a unit. Volumes
+// outlive their units: when a unit is removed, its volumes are
+// released, and detached from its instance, but not destroyed.
+type Volume struct {
+	st  *State
+	doc volumeDoc
+}
+
+// Volume returns the volume with the given id.
+func (st *State) Volume(id string) (*Volume, error) {
+	volumes, closer := st.getCollection(volumesC)
+	defer closer()
+
+	var doc volumeDoc
+	err := volumes.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("volume %q", id)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get volume %q: %v", id, err)
+	}
+	return &Volume{st: st, doc: doc}, nil
+}
+
+// AllVolumes returns all the volumes in the environment, including
+// those that have been released.
+func (st *State) AllVolumes() ([]*Volume, error) {
+	return st.findVolumes(nil)
+}
+
+func (st *State) findVolumes(sel bson.D) ([]*Volume, error) {
+	volumes, closer := st.getCollection(volumesC)
+	defer closer()
+
+	var docs []volumeDoc
+	if err := volumes.Find(sel).Sort("name").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get volumes: %v", err)
+	}
+	result := make([]*Volume, len(docs))
+	for i, doc := range docs {
+		result[i] = &Volume{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// Volumes returns the volumes of the unit that have not been released.
+func (u *Unit) Volumes() ([]*Volume, error) {
+	return u.st.findVolumes(bson.D{{"unit", u.doc.Name}, {"released", false}})
+}
+
+// releaseUnitVolumes releases the volumes of the named unit, so that
+// they are detached from its instance.
+func (st *State) releaseUnitVolumes(unitName string) error {
+	volumes, err := st.findVolumes(bson.D{{"unit", unitName}, {"released", false}})
+	if err != nil {
+		return err
+	}
+	var ops []txn.Op
+	for _, v := range volumes {
+		ops = append(ops, txn.Op{
+			C:      volumesC,
+			Id:     v.doc.Id,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"released", true}}}},
+		})
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return st.runTransaction(ops)
+}
+
+// Id returns the volume's id.
+func (v *Volume) Id() string {
+	return v.doc.Id
+}
+
+// Name returns the name of the unit's storage held by the volume.
+func (v *Volume) Name() string {
+	return v.doc.Name
+}
+
+// Unit returns the name of the unit the volume was created for.
+func (v *Volume) Unit() string {
+	return v.doc.Unit
+}
+
+// Pool returns the name of the storage pool the volume is created in.
+func (v *Volume) Pool() string {
+	return v.doc.Pool
+}
+
+// Size returns the size of the volume in MiB. Once the volume has been
+// created, this is the size reported by the provider.
+func (v *Volume) Size() uint64 {
+	return v.doc.Size
+}
+
+// Released returns whether the volume's unit has been removed.
+func (v *Volume) Released() bool {
+	return v.doc.Released
+}
+
+// VolumeId returns the provider-specific id of the volume, or the
+// empty string if it has not yet been created.
+func (v *Volume) VolumeId() string {
+	return v.doc.VolumeId
+}
+
+// InstanceId returns the id of the instance the volume is attached to,
+// or the empty string if it is not attached.
+func (v *Volume) InstanceId() instance.Id {
+	return v.doc.InstanceId
+}
+
+// Device returns the name of the block device through which the
+// attached volume is accessed on its instance.
+func (v *Volume) Device() string {
+	return v.doc.Device
+}
+
+// Status returns the status of the volume, along with the reason for
+// any error.
+func (v *Volume) Status() (VolumeStatus, string) {
+	switch {
+	case v.doc.Error != "":
+		return VolumeError, v.doc.Error
+	case v.doc.InstanceId != "":
+		return VolumeAttached, ""
+	case v.doc.VolumeId != "" && v.doc.Released:
+		return VolumeDetached, ""
+	}
+	return VolumePending, ""
+}
+
+// Refresh refreshes the contents of the volume from the underlying
+// state.
+func (v *Volume) Refresh() error {
+	volume, err := v.st.Volume(v.doc.Id)
+	if err != nil {
+		return err
+	}
+	v.doc = volume.doc
+	return nil
+}
+
+// SetProvisioned records that the volume has been created by the
+// provider, with the given id and size.
+func (v *Volume) SetProvisioned(volumeId string, size uint64) (err error) {
+	defer errors.Maskf(&err, "cannot set volume %q as provisioned", v.doc.Id)
+	if volumeId == "" {
+		return fmt.Errorf("volume id is empty")
+	}
+	ops := []txn.Op{{
+		C:      volumesC,
+		Id:     v.doc.Id,
+		Assert: bson.D{{"volumeid", ""}},
+		Update: bson.D{{"$set", bson.D{
+			{"volumeid", volumeId},
+			{"size", size},
+			{"error", ""},
+		}}},
+	}}
+	if err := v.st.runTransaction(ops); err == txn.ErrAborted {
+		return fmt.Errorf("already provisioned or removed")
+	} else if err != nil {
+		return err
+	}
+	v.doc.VolumeId = volumeId
+	v.doc.Size = size
+	v.doc.Error = ""
+	return nil
+}
+
+// SetAttached records that the volume has been attached to the given
+// instance, where it is accessed through the named block device.
+func (v *Volume) SetAttached(instId instance.Id, device string) (err error) {
+	defer errors.Maskf(&err, "cannot set volume %q as attached", v.doc.Id)
+	ops := []txn.Op{{
+		C:      volumesC,
+		Id:     v.doc.Id,
+		Assert: bson.D{{"volumeid", bson.D{{"$ne", ""}}}},
+		Update: bson.D{{"$set", bson.D{
+			{"instanceid", instId},
+			{"device", device},
+			{"error", ""},
+		}}},
+	}}
+	if err := v.st.runTransaction(ops); err == txn.ErrAborted {
+		return fmt.Errorf("not provisioned or removed")
+	} else if err != nil {
+		return err
+	}
+	v.doc.InstanceId = instId
+	v.doc.Device = device
+	v.doc.Error = ""
+	return nil
+}
+
+// SetDetached records that the volume has been detached from its
+// instance.
+func (v *Volume) SetDetached() (err error) {
+	defer errors.Maskf(&err, "cannot set volume %q as detached", v.doc.Id)
+	ops := []txn.Op{{
+		C:      volumesC,
+		Id:     v.doc.Id,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"instanceid", ""},
+			{"device", ""},
+			{"error", ""},
+		}}},
+	}}
+	if err := v.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("volume %q", v.doc.Id)
+	} else if err != nil {
+		return err
+	}
+	v.doc.InstanceId = ""
+	v.doc.Device = ""
+	v.doc.Error = ""
+	return nil
+}
+
+// SetError records that the last attempt to create, attach or detach
+// the volume failed for the given reason.
+func (v *Volume) SetError(info string) (err error) {
+	defer errors.Maskf(&err, "cannot set error of volume %q", v.doc.Id)
+	ops := []txn.Op{{
+		C:      volumesC,
+		Id:     v.doc.Id,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"error", info}}}},
+	}}
+	if err := v.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("volume %q", v.doc.Id)
+	} else if err != nil {
+		return err
+	}
+	v.doc.Error = info
+	return nil
+}
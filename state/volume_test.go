@@ -0,0 +1,189 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/testing"
+)
+
+type VolumeSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&VolumeSuite{})
+
+func (s *VolumeSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+func (s *VolumeSuite) TestSetStorageDirectives(c *gc.C) {
+	directives := map[string]state.StorageDirective{
+		"data": {Pool: "loop", Size: 102400},
+		"logs": {Pool: "loop", Size: 1024},
+	}
+	err := s.service.SetStorageDirectives(directives)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.service.StorageDirectives(), jc.DeepEquals, directives)
+	err = s.service.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.service.StorageDirectives(), jc.DeepEquals, directives)
+
+	err = s.service.SetStorageDirectives(nil)
+	c.Assert(err, gc.IsNil)
+	err = s.service.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.service.StorageDirectives(), gc.HasLen, 0)
+}
+
+func (s *VolumeSuite) TestSetStorageDirectivesErrors(c *gc.C) {
+	for _, test := range []struct {
+		directives map[string]state.StorageDirective
+		err        string
+	}{{
+		directives: map[string]state.StorageDirective{"Data": {Pool: "loop", Size: 1024}},
+		err:        `invalid storage name "Data"`,
+	}, {
+		directives: map[string]state.StorageDirective{"data": {Size: 1024}},
+		err:        `no pool specified for storage "data"`,
+	}, {
+		directives: map[string]state.StorageDirective{"data": {Pool: "loop"}},
+		err:        `no size specified for storage "data"`,
+	}} {
+		err := s.service.SetStorageDirectives(test.directives)
+		c.Check(err, gc.ErrorMatches, `cannot set storage for service "mysql": `+test.err)
+	}
+
+	_, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = s.service.SetStorageDirectives(map[string]state.StorageDirective{"data": {Pool: "loop", Size: 1024}})
+	c.Assert(err, gc.ErrorMatches, `cannot set storage for service "mysql": service is not alive or has units`)
+}
+
+func (s *VolumeSuite) TestUnitVolumes(c *gc.C) {
+	err := s.service.SetStorageDirectives(map[string]state.StorageDirective{
+		"data": {Pool: "loop", Size: 102400},
+		"logs": {Pool: "loop", Size: 1024},
+	})
+	c.Assert(err, gc.IsNil)
+	unit0, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	unit1, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+
+	volumes, err := unit0.Volumes()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.HasLen, 2)
+	c.Assert(volumes[0].Name(), gc.Equals, "data")
+	c.Assert(volumes[0].Unit(), gc.Equals, "mysql/0")
+	c.Assert(volumes[0].Pool(), gc.Equals, "loop")
+	c.Assert(volumes[0].Size(), gc.Equals, uint64(102400))
+	c.Assert(volumes[1].Name(), gc.Equals, "logs")
+	status, info := volumes[0].Status()
+	c.Assert(status, gc.Equals, state.VolumePending)
+	c.Assert(info, gc.Equals, "")
+
+	volumes, err = unit1.Volumes()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.HasLen, 2)
+	all, err := s.State.AllVolumes()
+	c.Assert(err, gc.IsNil)
+	c.Assert(all, gc.HasLen, 4)
+}
+
+func (s *VolumeSuite) TestVolumeLifecycle(c *gc.C) {
+	err := s.service.SetStorageDirectives(map[string]state.StorageDirective{
+		"data": {Pool: "loop", Size: 1024},
+	})
+	c.Assert(err, gc.IsNil)
+	unit, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	volumes, err := unit.Volumes()
+	c.Assert(err, gc.IsNil)
+	volume := volumes[0]
+
+	err = volume.SetAttached("inst-0", "/dev/loop0")
+	c.Assert(err, gc.ErrorMatches, `cannot set volume "\d+" as attached: not provisioned or removed`)
+
+	err = volume.SetError("kaboom")
+	c.Assert(err, gc.IsNil)
+	status, info := volume.Status()
+	c.Assert(status, gc.Equals, state.VolumeError)
+	c.Assert(info, gc.Equals, "kaboom")
+
+	err = volume.SetProvisioned("vol-0", 1100)
+	c.Assert(err, gc.IsNil)
+	err = volume.SetProvisioned("vol-1", 1100)
+	c.Assert(err, gc.ErrorMatches, `cannot set volume "\d+" as provisioned: already provisioned or removed`)
+	status, _ = volume.Status()
+	c.Assert(status, gc.Equals, state.VolumePending)
+
+	err = volume.SetAttached(instance.Id("inst-0"), "/dev/loop0")
+	c.Assert(err, gc.IsNil)
+	err = volume.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.VolumeId(), gc.Equals, "vol-0")
+	c.Assert(volume.Size(), gc.Equals, uint64(1100))
+	c.Assert(volume.InstanceId(), gc.Equals, instance.Id("inst-0"))
+	c.Assert(volume.Device(), gc.Equals, "/dev/loop0")
+	status, _ = volume.Status()
+	c.Assert(status, gc.Equals, state.VolumeAttached)
+
+	// Removing the unit releases, but does not remove, its volumes.
+	err = unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	err = volume.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.Released(), jc.IsTrue)
+
+	err = volume.SetDetached()
+	c.Assert(err, gc.IsNil)
+	err = volume.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.InstanceId(), gc.Equals, instance.Id(""))
+	c.Assert(volume.Device(), gc.Equals, "")
+	status, _ = volume.Status()
+	c.Assert(status, gc.Equals, state.VolumeDetached)
+}
+
+func (s *VolumeSuite) TestVolumeNotFound(c *gc.C) {
+	_, err := s.State.Volume("42")
+	c.Assert(err, gc.ErrorMatches, `volume "42" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *VolumeSuite) TestWatchVolumes(c *gc.C) {
+	err := s.service.SetStorageDirectives(map[string]state.StorageDirective{
+		"data": {Pool: "loop", Size: 1024},
+	})
+	c.Assert(err, gc.IsNil)
+	w := s.State.WatchVolumes()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	unit, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	volumes, err := unit.Volumes()
+	c.Assert(err, gc.IsNil)
+	err = volumes[0].SetProvisioned("vol-0", 1024)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	testing.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -1203,6 +1203,13 @@ func (st *State) WatchAPIHostPorts() NotifyWatcher {
 	return newEntityWatcher(st, stateServersC, apiHostPortsKey)
 }
 
+// WatchStateServingInfo returns a NotifyWatcher that notifies
+// when the state serving information, including the state server
+// certificate, changes.
+func (st *State) WatchStateServingInfo() NotifyWatcher {
+	return newEntityWatcher(st, stateServersC, stateServingInfoKey)
+}
+
 // WatchConfigSettings returns a watcher for observing changes to the
 // unit's service configuration settings. The unit must have a charm URL
 // set before this method is called, and the returned watcher will be
@@ -1564,6 +1571,177 @@ func (w *cleanupWatcher) loop() (err error) {
 	}
 }
 
+// collectionWatcher notifies of changes to any document in a
+// collection.
+type collectionWatcher struct {
+	commonWatcher
+	coll string
+	out  chan struct{}
+}
+
+var _ Watcher = (*collectionWatcher)(nil)
+
+// WatchVolumes returns a watcher that notifies of changes to any of the
+// volumes in the environment.
+func (st *State) WatchVolumes() NotifyWatcher {
+	return newCollectionWatcher(st, volumesC)
+}
+
+// WatchMachines returns a watcher that notifies of changes to any of
+// the machines in the environment, including changes to the units
+// assigned to them.
+func (st *State) WatchMachines() NotifyWatcher {
+	return newCollectionWatcher(st, machinesC)
+}
+
+func newCollectionWatcher(st *State, coll string) NotifyWatcher {
+	w := &collectionWatcher{
+		commonWatcher: commonWatcher{st: st},
+		coll:          coll,
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *collectionWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+func (w *collectionWatcher) loop() error {
+	in := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.coll, in)
+	defer w.st.watcher.UnwatchCollection(w.coll, in)
+
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			if _, ok := collect(ch, in, w.tomb.Dying()); !ok {
+				return tomb.ErrDying
+			}
+			out = w.out
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
+// scheduleWatcher notifies of changes to the schedules that apply to
+// a unit.
+type scheduleWatcher struct {
+	commonWatcher
+	targets []string
+	known   set.Strings
+	out     chan struct{}
+}
+
+var _ Watcher = (*scheduleWatcher)(nil)
+
+// WatchSchedules returns a watcher that notifies of changes to the
+// schedules whose commands should be run on the unit.
+func (u *Unit) WatchSchedules() NotifyWatcher {
+	w := &scheduleWatcher{
+		commonWatcher: commonWatcher{st: u.st},
+		targets:       []string{u.doc.Name, u.doc.Service},
+		known:         make(set.Strings),
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *scheduleWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+// isRelevant reports whether the schedule with the given id applies
+// to the watched unit, or did when last seen.
+func (w *scheduleWatcher) isRelevant(id string, exists bool) (bool, error) {
+	if w.known.Contains(id) {
+		if !exists {
+			w.known.Remove(id)
+		}
+		return true, nil
+	}
+	if !exists {
+		return false, nil
+	}
+	schedules, closer := w.st.getCollection(schedulesC)
+	defer closer()
+	var doc scheduleDoc
+	err := schedules.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, target := range w.targets {
+		if doc.Target == target {
+			w.known.Add(id)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (w *scheduleWatcher) loop() error {
+	in := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(schedulesC, in)
+	defer w.st.watcher.UnwatchCollection(schedulesC, in)
+
+	schedules, closer := w.st.getCollection(schedulesC)
+	var docs []scheduleDoc
+	err := schedules.Find(bson.D{{"target", bson.D{{"$in", w.targets}}}}).Select(bson.D{{"_id", 1}}).All(&docs)
+	closer()
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		w.known.Add(doc.Id)
+	}
+
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			updates, ok := collect(ch, in, w.tomb.Dying())
+			if !ok {
+				return tomb.ErrDying
+			}
+			for id, exists := range updates {
+				relevant, err := w.isRelevant(id.(string), exists)
+				if err != nil {
+					return err
+				}
+				if relevant {
+					out = w.out
+				}
+			}
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
 // actionWatcher notifies of changes in the actions collection.
 type actionWatcher struct {
 	commonWatcher
@@ -18,7 +18,7 @@ func stepsFor118() []Step {
 		},
 		&upgradeStep{
 			description: "update rsyslog port",
-			targets:     []Target{StateServer},
+			targets:     []Target{DatabaseMaster},
 			run:         updateRsyslogPort,
 		},
 		&upgradeStep{
@@ -28,7 +28,7 @@ func stepsFor118() []Step {
 		},
 		&upgradeStep{
 			description: "remove deprecated environment config settings",
-			targets:     []Target{StateServer},
+			targets:     []Target{DatabaseMaster},
 			run:         processDeprecatedEnvSettings,
 		},
 		&upgradeStep{
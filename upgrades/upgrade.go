@@ -11,6 +11,7 @@ import (
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/version"
 )
 
@@ -54,6 +55,11 @@ const (
 
 	// StateServer is a machine participating in a Juju state server cluster.
 	StateServer = Target("stateServer")
+
+	// DatabaseMaster is the one state server that runs the steps
+	// which upgrade the database, once all the state servers have
+	// reached the new version. It is not matched by AllMachines.
+	DatabaseMaster = Target("databaseMaster")
 )
 
 // upgradeToVersion encapsulates the steps which need to be run to
@@ -88,6 +94,19 @@ type Context interface {
 	AgentConfig() agent.ConfigSetter
 }
 
+// Progress records the progress of the upgrade steps as they are run,
+// so that it can be reported to the user, and tells which failed
+// steps the user has asked to skip.
+type Progress interface {
+	// SkipStep returns whether the given step should be skipped
+	// rather than run.
+	SkipStep(target Target, step Step) bool
+
+	// SetStepStatus records the status of the given step, and the
+	// error with which it failed, if it did.
+	SetStepStatus(target Target, step Step, status params.UpgradeStepStatus, err error)
+}
+
 // upgradeContext is a default Context implementation.
 type upgradeContext struct {
 	// Work in progress........
@@ -139,11 +158,27 @@ func AreUpgradesDefined(from version.Number) bool {
 	return newUpgradeOpsIterator(from, version.Current.Number).Next()
 }
 
+// PendingSteps returns the upgrade steps that would be run to upgrade
+// from the "from" version to the "to" version on the "target" type of
+// machine, in the order they would be run.
+func PendingSteps(from, to version.Number, target Target) []Step {
+	var steps []Step
+	for ops := newUpgradeOpsIterator(from, to); ops.Next(); {
+		for _, step := range ops.Get().Steps() {
+			if validTarget(target, step) {
+				steps = append(steps, step)
+			}
+		}
+	}
+	return steps
+}
+
 // PerformUpgrade runs the business logic needed to upgrade the current "from" version to this
-// version of Juju on the "target" type of machine.
-func PerformUpgrade(from version.Number, target Target, context Context) error {
+// version of Juju on the "target" type of machine. The progress of
+// each step is recorded with progress.
+func PerformUpgrade(from version.Number, target Target, context Context, progress Progress) error {
 	for ops := newUpgradeOpsIterator(from, version.Current.Number); ops.Next(); {
-		if err := runUpgradeSteps(context, target, ops.Get()); err != nil {
+		if err := runUpgradeSteps(context, target, ops.Get(), progress); err != nil {
 			return err
 		}
 	}
@@ -194,8 +229,19 @@ func (it *upgradeOpsIterator) Get() Operation {
 	return it.allOps[it.current]
 }
 
-// validTarget returns true if target is in step.Targets().
+// validTarget returns true if target is in step.Targets(). Steps for
+// all machines are run on every target but DatabaseMaster, so that
+// they are not run twice on the state server holding the database
+// upgrade lock.
 func validTarget(target Target, step Step) bool {
+	if target == DatabaseMaster {
+		for _, opTarget := range step.Targets() {
+			if opTarget == DatabaseMaster {
+				return true
+			}
+		}
+		return false
+	}
 	for _, opTarget := range step.Targets() {
 		if opTarget == AllMachines || target == opTarget {
 			return true
@@ -208,20 +254,28 @@ func validTarget(target Target, step Step) bool {
 // As soon as any error is encountered, the operation is aborted since
 // subsequent steps may required successful completion of earlier ones.
 // The steps must be idempotent so that the entire upgrade operation can
-// be retried.
-func runUpgradeSteps(context Context, target Target, upgradeOp Operation) *upgradeError {
+// be retried. Steps that progress says should be skipped are not run.
+func runUpgradeSteps(context Context, target Target, upgradeOp Operation, progress Progress) *upgradeError {
 	for _, step := range upgradeOp.Steps() {
 		if !validTarget(target, step) {
 			continue
 		}
+		if progress.SkipStep(target, step) {
+			logger.Warningf("skipping upgrade step on target %q: %v", target, step.Description())
+			progress.SetStepStatus(target, step, params.UpgradeStepSkipped, nil)
+			continue
+		}
 		logger.Infof("running upgrade step on target %q: %v", target, step.Description())
+		progress.SetStepStatus(target, step, params.UpgradeStepRunning, nil)
 		if err := step.Run(context); err != nil {
 			logger.Errorf("upgrade step %q failed: %v", step.Description(), err)
+			progress.SetStepStatus(target, step, params.UpgradeStepFailed, err)
 			return &upgradeError{
 				description: step.Description(),
 				err:         err,
 			}
 		}
+		progress.SetStepStatus(target, step, params.UpgradeStepDone, nil)
 	}
 	logger.Infof("All upgrade steps completed successfully")
 	return nil
@@ -5,6 +5,7 @@ package upgrades_test
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 	stdtesting "testing"
@@ -99,6 +100,23 @@ func (c *mockContext) AgentConfig() agent.ConfigSetter {
 	return c.agentConfig
 }
 
+type mockProgress struct {
+	skip     map[string]bool
+	statuses []string
+}
+
+func (p *mockProgress) SkipStep(target upgrades.Target, step upgrades.Step) bool {
+	return p.skip[step.Description()]
+}
+
+func (p *mockProgress) SetStepStatus(target upgrades.Target, step upgrades.Step, status params.UpgradeStepStatus, err error) {
+	msg := fmt.Sprintf("%s: %s %s", target, step.Description(), status)
+	if err != nil {
+		msg += ": " + err.Error()
+	}
+	p.statuses = append(p.statuses, msg)
+}
+
 type mockAgentConfig struct {
 	agent.ConfigSetter
 	dataDir      string
@@ -193,6 +211,7 @@ func upgradeOperations() []upgrades.Operation {
 				&mockUpgradeStep{"step 1 - 1.20.0", targets(upgrades.AllMachines)},
 				&mockUpgradeStep{"step 2 - 1.20.0", targets(upgrades.HostMachine)},
 				&mockUpgradeStep{"step 3 - 1.20.0", targets(upgrades.StateServer)},
+				&mockUpgradeStep{"step 4 - 1.20.0", targets(upgrades.DatabaseMaster)},
 			},
 		},
 	}
@@ -299,6 +318,13 @@ var upgradeTests = []upgradeTest{
 		target:        upgrades.StateServer,
 		expectedSteps: []string{"step 1 - 1.20.0", "step 3 - 1.20.0"},
 	},
+	{
+		about:         "allMachines does not match databaseMaster",
+		fromVersion:   "1.18.1",
+		toVersion:     "1.20.0",
+		target:        upgrades.DatabaseMaster,
+		expectedSteps: []string{"step 4 - 1.20.0"},
+	},
 	{
 		about:         "error aborts, subsequent steps not run",
 		fromVersion:   "1.10.0",
@@ -333,7 +359,7 @@ func (s *upgradeSuite) TestPerformUpgrade(c *gc.C) {
 		vers := version.Current
 		vers.Number = toVersion
 		s.PatchValue(&version.Current, vers)
-		err := upgrades.PerformUpgrade(fromVersion, test.target, ctx)
+		err := upgrades.PerformUpgrade(fromVersion, test.target, ctx, &mockProgress{})
 		if test.err == "" {
 			c.Check(err, gc.IsNil)
 		} else {
@@ -343,6 +369,48 @@ func (s *upgradeSuite) TestPerformUpgrade(c *gc.C) {
 	}
 }
 
+func (s *upgradeSuite) TestPerformUpgradeRecordsProgress(c *gc.C) {
+	s.PatchValue(upgrades.UpgradeOperations, upgradeOperations)
+	vers := version.Current
+	vers.Number = version.MustParse("1.18.0")
+	s.PatchValue(&version.Current, vers)
+
+	ctx := &mockContext{}
+	progress := &mockProgress{}
+	err := upgrades.PerformUpgrade(version.MustParse("1.10.0"), upgrades.HostMachine, ctx, progress)
+	c.Assert(err, gc.ErrorMatches, "step 2 error: upgrade error occurred")
+	c.Assert(progress.statuses, jc.DeepEquals, []string{
+		"hostMachine: step 1 - 1.12.0 running",
+		"hostMachine: step 1 - 1.12.0 done",
+		"hostMachine: step 2 error running",
+		"hostMachine: step 2 error failed: upgrade error occurred",
+	})
+
+	// A failed step the user has asked to skip is not run again.
+	ctx = &mockContext{}
+	progress = &mockProgress{skip: map[string]bool{"step 2 error": true}}
+	err = upgrades.PerformUpgrade(version.MustParse("1.10.0"), upgrades.HostMachine, ctx, progress)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ctx.messages, jc.DeepEquals, []string{
+		"step 1 - 1.12.0", "step 3", "step 1 - 1.16.0", "step 2 - 1.16.0",
+		"step 1 - 1.17.0", "step 1 - 1.17.1", "step 1 - 1.18.0",
+	})
+	c.Assert(progress.statuses[:4], jc.DeepEquals, []string{
+		"hostMachine: step 1 - 1.12.0 running",
+		"hostMachine: step 1 - 1.12.0 done",
+		"hostMachine: step 2 error skipped",
+		"hostMachine: step 3 running",
+	})
+}
+
+func (s *upgradeSuite) TestPendingSteps(c *gc.C) {
+	s.PatchValue(upgrades.UpgradeOperations, upgradeOperations)
+	steps := upgrades.PendingSteps(version.MustParse("1.17.0"), version.MustParse("1.20.0"), upgrades.StateServer)
+	assertExpectedSteps(c, steps, []string{"step 2 - 1.17.1", "step 2 - 1.18.0", "step 1 - 1.20.0", "step 3 - 1.20.0"})
+	steps = upgrades.PendingSteps(version.MustParse("1.18.0"), version.MustParse("1.18.0"), upgrades.HostMachine)
+	c.Assert(steps, gc.HasLen, 0)
+}
+
 func (s *upgradeSuite) TestUpgradeOperationsOrdered(c *gc.C) {
 	var previous version.Number
 	for i, utv := range (*upgrades.UpgradeOperations)() {
@@ -0,0 +1,190 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The cron package parses cron-style schedule specifications and
+// calculates when they next fall due.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec holds a parsed schedule specification. A specification has
+// five space-separated fields: minute (0-59), hour (0-23), day of
+// month (1-31), month (1-12) and day of week (0-6, Sunday is 0 or 7).
+// Each field may be "*", a number, a range ("1-5"), or a
+// comma-separated list of those; any of those but a plain number may
+// be followed by a step ("*/15", "0-30/10"). The predefined schedules
+// @yearly, @annually, @monthly, @weekly, @daily and @hourly are also
+// accepted.
+//
+// As with the traditional cron, if both the day of month and the day
+// of week are restricted, a time matches when either of them does.
+type Spec struct {
+	spec                         string
+	minute, hour, dom, month     uint64
+	dow                          uint64
+	domRestricted, dowRestricted bool
+}
+
+var predefined = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type fieldRange struct {
+	name     string
+	min, max int
+}
+
+var fieldRanges = []fieldRange{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse parses the given schedule specification.
+func Parse(spec string) (*Spec, error) {
+	expanded := strings.TrimSpace(spec)
+	if strings.HasPrefix(expanded, "@") {
+		var ok bool
+		if expanded, ok = predefined[expanded]; !ok {
+			return nil, fmt.Errorf("invalid schedule %q: unknown predefined schedule", spec)
+		}
+	}
+	fields := strings.Fields(expanded)
+	if len(fields) != len(fieldRanges) {
+		return nil, fmt.Errorf("invalid schedule %q: expected %d fields, got %d", spec, len(fieldRanges), len(fields))
+	}
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseField(field, fieldRanges[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", spec, err)
+		}
+		bits[i] = b
+	}
+	s := &Spec{
+		spec:          spec,
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: !strings.HasPrefix(fields[2], "*"),
+		dowRestricted: !strings.HasPrefix(fields[4], "*"),
+	}
+	// Sunday may be written as 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// parseField returns the set of values matched by the given field, as
+// a bit set.
+func parseField(field string, r fieldRange) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			expr = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %s field %q", r.name, part)
+			}
+		}
+		lo, hi := r.min, r.max
+		switch {
+		case expr == "*":
+		case strings.Contains(expr, "-"):
+			bounds := strings.SplitN(expr, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], r); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(bounds[1], r); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field %q", r.name, part)
+			}
+		default:
+			if expr != part {
+				return 0, fmt.Errorf("invalid step in %s field %q: step requires a range", r.name, part)
+			}
+			v, err := parseValue(expr, r)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, r fieldRange) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", r.name, s)
+	}
+	if v < r.min || v > r.max {
+		return 0, fmt.Errorf("%s %d out of range %d-%d", r.name, v, r.min, r.max)
+	}
+	return v, nil
+}
+
+// String returns the specification as it was given to Parse.
+func (s *Spec) String() string {
+	return s.spec
+}
+
+// maxSearch bounds the search for the next matching time; a spec such
+// as "0 0 31 2 *" never matches.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the first time after t that matches the specification,
+// to a resolution of one minute. It returns the zero time if the
+// specification never matches.
+func (s *Spec) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Spec) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
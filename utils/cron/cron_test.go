@@ -0,0 +1,104 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cron_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/utils/cron"
+)
+
+type cronSuite struct{}
+
+var _ = gc.Suite(&cronSuite{})
+
+// 2014-06-04 10:30 is a Wednesday.
+var base = time.Date(2014, 6, 4, 10, 30, 15, 0, time.UTC)
+
+var nextTests = []struct {
+	spec   string
+	expect time.Time
+}{{
+	spec:   "* * * * *",
+	expect: time.Date(2014, 6, 4, 10, 31, 0, 0, time.UTC),
+}, {
+	spec:   "*/15 * * * *",
+	expect: time.Date(2014, 6, 4, 10, 45, 0, 0, time.UTC),
+}, {
+	spec:   "0 3 * * *",
+	expect: time.Date(2014, 6, 5, 3, 0, 0, 0, time.UTC),
+}, {
+	spec:   "15,45 9-17 * * 1-5",
+	expect: time.Date(2014, 6, 4, 10, 45, 0, 0, time.UTC),
+}, {
+	spec:   "0 0 * * 7",
+	expect: time.Date(2014, 6, 8, 0, 0, 0, 0, time.UTC),
+}, {
+	spec:   "0 0 1 * 1",
+	expect: time.Date(2014, 6, 9, 0, 0, 0, 0, time.UTC),
+}, {
+	spec:   "0 12 29 2 *",
+	expect: time.Date(2016, 2, 29, 12, 0, 0, 0, time.UTC),
+}, {
+	spec:   "@hourly",
+	expect: time.Date(2014, 6, 4, 11, 0, 0, 0, time.UTC),
+}, {
+	spec:   "@monthly",
+	expect: time.Date(2014, 7, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	spec:   "0 0 31 2 *",
+	expect: time.Time{},
+}}
+
+func (*cronSuite) TestNext(c *gc.C) {
+	for i, test := range nextTests {
+		c.Logf("test %d: %q", i, test.spec)
+		spec, err := cron.Parse(test.spec)
+		c.Assert(err, gc.IsNil)
+		c.Check(spec.String(), gc.Equals, test.spec)
+		c.Check(spec.Next(base), gc.DeepEquals, test.expect)
+	}
+}
+
+var parseErrorTests = []struct {
+	spec string
+	err  string
+}{{
+	spec: "",
+	err:  `invalid schedule "": expected 5 fields, got 0`,
+}, {
+	spec: "* * * *",
+	err:  `invalid schedule "\* \* \* \*": expected 5 fields, got 4`,
+}, {
+	spec: "@fortnightly",
+	err:  `invalid schedule "@fortnightly": unknown predefined schedule`,
+}, {
+	spec: "60 * * * *",
+	err:  `invalid schedule "60 \* \* \* \*": minute 60 out of range 0-59`,
+}, {
+	spec: "* * 0 * *",
+	err:  `invalid schedule "\* \* 0 \* \*": day of month 0 out of range 1-31`,
+}, {
+	spec: "x * * * *",
+	err:  `invalid schedule "x \* \* \* \*": invalid minute "x"`,
+}, {
+	spec: "*/0 * * * *",
+	err:  `invalid schedule "\*/0 \* \* \* \*": invalid step in minute field "\*/0"`,
+}, {
+	spec: "5/10 * * * *",
+	err:  `invalid schedule "5/10 \* \* \* \*": invalid step in minute field "5/10": step requires a range`,
+}, {
+	spec: "* 5-2 * * *",
+	err:  `invalid schedule "\* 5-2 \* \* \*": invalid range in hour field "5-2"`,
+}}
+
+func (*cronSuite) TestParseErrors(c *gc.C) {
+	for i, test := range parseErrorTests {
+		c.Logf("test %d: %q", i, test.spec)
+		_, err := cron.Parse(test.spec)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cron_test
+
+import (
+	"testing"
+
+	gc "launchpad.net/gocheck"
+)
+
+func TestPackage(t *testing.T) {
+	gc.TestingT(t)
+}
@@ -0,0 +1,138 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package certupdater
+
+import (
+	"fmt"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.certupdater")
+
+// CACertUpdater is responsible for propagating the CA certificates
+// that agents trust.
+//
+// In practice, CACertUpdater is used by machine and unit agents to
+// watch the CA certificates in the environment configuration and
+// write any changes to the agent's config file, so that the agent
+// keeps trusting the state servers while the CA is being replaced.
+type CACertUpdater struct {
+	getter CACertGetter
+	setter CACertSetter
+}
+
+// CACertGetter is an interface that is provided to NewCACertUpdater
+// which can be used to watch for CA certificate changes.
+type CACertGetter interface {
+	CACert() (string, error)
+	WatchCACert() (watcher.NotifyWatcher, error)
+}
+
+// CACertSetter is an interface that is provided to NewCACertUpdater
+// whose SetCACert method will be invoked whenever the CA certificates
+// change.
+type CACertSetter interface {
+	CACert() string
+	SetCACert(caCert string) error
+}
+
+// NewCACertUpdater returns a worker.Worker that watches for changes
+// to the CA certificates and then sets them on the CACertSetter.
+func NewCACertUpdater(getter CACertGetter, setter CACertSetter) worker.Worker {
+	return worker.NewNotifyWorker(&CACertUpdater{
+		getter: getter,
+		setter: setter,
+	})
+}
+
+func (u *CACertUpdater) SetUp() (watcher.NotifyWatcher, error) {
+	return u.getter.WatchCACert()
+}
+
+func (u *CACertUpdater) Handle() error {
+	caCert, err := u.getter.CACert()
+	if err != nil {
+		return fmt.Errorf("error getting CA certificate: %v", err)
+	}
+	if caCert == "" || caCert == u.setter.CACert() {
+		return nil
+	}
+	if err := u.setter.SetCACert(caCert); err != nil {
+		return fmt.Errorf("error setting CA certificate: %v", err)
+	}
+	logger.Infof("CA certificate updated")
+	return nil
+}
+
+func (u *CACertUpdater) TearDown() error {
+	return nil
+}
+
+// StateServingInfoUpdater is responsible for propagating the state
+// server certificate.
+//
+// In practice, StateServingInfoUpdater is used by state server machine
+// agents to watch the state serving information and, when the state
+// server certificate changes, write it to the agent's config file and
+// restart the agent so that the API and mongo servers use it.
+type StateServingInfoUpdater struct {
+	getter StateServingInfoGetter
+	setter StateServingInfoSetter
+}
+
+// StateServingInfoGetter is an interface that is provided to
+// NewStateServingInfoUpdater which can be used to watch for state
+// serving information changes.
+type StateServingInfoGetter interface {
+	StateServingInfo() (params.StateServingInfo, error)
+	WatchStateServingInfo() (watcher.NotifyWatcher, error)
+}
+
+// StateServingInfoSetter is an interface that is provided to
+// NewStateServingInfoUpdater whose SetStateServingInfo method will
+// be invoked whenever the state server certificate changes.
+type StateServingInfoSetter interface {
+	StateServingInfo() (params.StateServingInfo, bool)
+	SetStateServingInfo(info params.StateServingInfo) error
+}
+
+// NewStateServingInfoUpdater returns a worker.Worker that watches
+// for changes to the state server certificate and then sets the state
+// serving information on the StateServingInfoSetter. The worker
+// stops with worker.ErrRestartAgent after any change.
+func NewStateServingInfoUpdater(getter StateServingInfoGetter, setter StateServingInfoSetter) worker.Worker {
+	return worker.NewNotifyWorker(&StateServingInfoUpdater{
+		getter: getter,
+		setter: setter,
+	})
+}
+
+func (u *StateServingInfoUpdater) SetUp() (watcher.NotifyWatcher, error) {
+	return u.getter.WatchStateServingInfo()
+}
+
+func (u *StateServingInfoUpdater) Handle() error {
+	info, err := u.getter.StateServingInfo()
+	if err != nil {
+		return fmt.Errorf("error getting state serving info: %v", err)
+	}
+	current, ok := u.setter.StateServingInfo()
+	if ok && info.Cert == current.Cert && info.PrivateKey == current.PrivateKey {
+		return nil
+	}
+	if err := u.setter.SetStateServingInfo(info); err != nil {
+		return fmt.Errorf("error setting state serving info: %v", err)
+	}
+	logger.Infof("state server certificate updated; restarting agent")
+	return worker.ErrRestartAgent
+}
+
+func (u *StateServingInfoUpdater) TearDown() error {
+	return nil
+}
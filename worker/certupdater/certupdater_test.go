@@ -0,0 +1,131 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package certupdater_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/certupdater"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type CertUpdaterSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&CertUpdaterSuite{})
+
+type caCertSetter struct {
+	caCert  string
+	changes chan string
+}
+
+func (s *caCertSetter) CACert() string {
+	return s.caCert
+}
+
+func (s *caCertSetter) SetCACert(caCert string) error {
+	s.caCert = caCert
+	s.changes <- caCert
+	return nil
+}
+
+func (s *CertUpdaterSuite) TestCACertStartStop(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	w := certupdater.NewCACertUpdater(st.Agent(), &caCertSetter{caCert: coretesting.CACert})
+	w.Kill()
+	c.Assert(w.Wait(), gc.IsNil)
+}
+
+func (s *CertUpdaterSuite) TestCACertChange(c *gc.C) {
+	setter := &caCertSetter{
+		caCert:  coretesting.CACert,
+		changes: make(chan string, 1),
+	}
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	w := certupdater.NewCACertUpdater(st.Agent(), setter)
+	defer func() { c.Assert(w.Wait(), gc.IsNil) }()
+	defer w.Kill()
+
+	// The initial CA certificate is the one already set,
+	// so SetCACert is not called.
+	s.BackingState.StartSync()
+	select {
+	case <-time.After(coretesting.ShortWait):
+	case caCert := <-setter.changes:
+		c.Fatalf("unexpected CA certificate change %q", caCert)
+	}
+
+	bundle := coretesting.CACert + coretesting.CACert
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"ca-cert": bundle}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetCACert to be called")
+	case caCert := <-setter.changes:
+		c.Assert(caCert, gc.Equals, bundle)
+	}
+}
+
+type servingInfoSetter struct {
+	info    params.StateServingInfo
+	changes chan params.StateServingInfo
+}
+
+func (s *servingInfoSetter) StateServingInfo() (params.StateServingInfo, bool) {
+	return s.info, true
+}
+
+func (s *servingInfoSetter) SetStateServingInfo(info params.StateServingInfo) error {
+	s.info = info
+	s.changes <- info
+	return nil
+}
+
+func (s *CertUpdaterSuite) TestStateServingInfoChange(c *gc.C) {
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	setter := &servingInfoSetter{
+		info:    info,
+		changes: make(chan params.StateServingInfo, 1),
+	}
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobManageEnviron)
+	w := certupdater.NewStateServingInfoUpdater(st.Agent(), setter)
+	defer w.Kill()
+
+	// Changes other than to the certificate are ignored.
+	info.SharedSecret = "another secret"
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	select {
+	case <-time.After(coretesting.ShortWait):
+	case info := <-setter.changes:
+		c.Fatalf("unexpected state serving info change %#v", info)
+	}
+
+	info.Cert = coretesting.ServerCert + "\n"
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetStateServingInfo to be called")
+	case newInfo := <-setter.changes:
+		c.Assert(newInfo, gc.DeepEquals, info)
+	}
+	c.Assert(w.Wait(), gc.Equals, worker.ErrRestartAgent)
+}
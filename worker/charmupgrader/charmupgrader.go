@@ -0,0 +1,92 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmupgrader
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.charmupgrader")
+
+// interval sets how often rolling charm upgrades are checked for
+// progress.
+var interval = 10 * time.Second
+
+// CharmUpgrader advances rolling charm upgrades, releasing each batch
+// of units once the previous batch has settled on the new charm.
+type CharmUpgrader struct {
+	st   *state.State
+	tomb tomb.Tomb
+}
+
+var _ worker.Worker = (*CharmUpgrader)(nil)
+
+// NewCharmUpgrader returns a worker that periodically advances the
+// rolling charm upgrades of all services.
+func NewCharmUpgrader(st *state.State) *CharmUpgrader {
+	cu := &CharmUpgrader{st: st}
+	go func() {
+		defer cu.tomb.Done()
+		cu.tomb.Kill(cu.loop())
+	}()
+	return cu
+}
+
+func (cu *CharmUpgrader) String() string {
+	return "charm upgrader"
+}
+
+// Kill is defined on the worker.Worker interface.
+func (cu *CharmUpgrader) Kill() {
+	cu.tomb.Kill(nil)
+}
+
+// Wait is defined on the worker.Worker interface.
+func (cu *CharmUpgrader) Wait() error {
+	return cu.tomb.Wait()
+}
+
+// Stop stops the worker.
+func (cu *CharmUpgrader) Stop() error {
+	cu.tomb.Kill(nil)
+	return cu.tomb.Wait()
+}
+
+func (cu *CharmUpgrader) loop() error {
+	for {
+		if err := cu.advanceUpgrades(); err != nil {
+			return err
+		}
+		select {
+		case <-cu.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(interval):
+		}
+	}
+}
+
+// advanceUpgrades advances the charm upgrade of every service that
+// has one in progress. Failure to advance a single service is logged,
+// but does not stop the worker.
+func (cu *CharmUpgrader) advanceUpgrades() error {
+	services, err := cu.st.AllServices()
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		if _, ok := service.CharmUpgrade(); !ok {
+			continue
+		}
+		if err := service.AdvanceCharmUpgrade(); err != nil {
+			logger.Errorf("%v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmupgrader_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/charmupgrader"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type charmUpgraderSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&charmUpgraderSuite{})
+
+func (s *charmUpgraderSuite) TestAdvancesRollingUpgrade(c *gc.C) {
+	s.PatchValue(charmupgrader.Interval, coretesting.ShortWait)
+
+	oldCharm := s.AddTestingCharm(c, "upgrade1")
+	newCharm := s.AddTestingCharm(c, "upgrade2")
+	service := s.AddTestingService(c, "upgradetest", oldCharm)
+	var units []*state.Unit
+	for i := 0; i < 2; i++ {
+		unit, err := service.AddUnit()
+		c.Assert(err, gc.IsNil)
+		err = unit.SetCharmURL(oldCharm.URL())
+		c.Assert(err, gc.IsNil)
+		pinger, err := unit.SetAgentPresence()
+		c.Assert(err, gc.IsNil)
+		defer pinger.Stop()
+		units = append(units, unit)
+	}
+	s.State.StartSync()
+	err := service.SetCharmRolling(newCharm, false, 1)
+	c.Assert(err, gc.IsNil)
+
+	cu := charmupgrader.NewCharmUpgrader(s.State)
+	defer func() { c.Assert(worker.Stop(cu), gc.IsNil) }()
+
+	waitReleased := func(expect ...string) {
+		timeout := time.After(coretesting.LongWait)
+		for {
+			select {
+			case <-time.After(coretesting.ShortWait):
+				err := service.Refresh()
+				c.Assert(err, gc.IsNil)
+				upgrade, ok := service.CharmUpgrade()
+				if len(expect) == 0 && !ok {
+					return
+				}
+				if ok && len(upgrade.Released) == len(expect) {
+					c.Assert(upgrade.Released, jc.DeepEquals, expect)
+					return
+				}
+			case <-timeout:
+				c.Fatalf("timed out waiting for units %v to be released", expect)
+			}
+		}
+	}
+	waitReleased("upgradetest/0")
+	for i, unit := range units {
+		err := unit.SetCharmURL(newCharm.URL())
+		c.Assert(err, gc.IsNil)
+		err = unit.SetStatus(params.StatusStarted, "", nil)
+		c.Assert(err, gc.IsNil)
+		if i == 0 {
+			waitReleased("upgradetest/0", "upgradetest/1")
+		}
+	}
+	waitReleased()
+}
@@ -20,6 +20,11 @@ import (
 
 var ErrTerminateAgent = errors.New("agent should be terminated")
 
+// ErrRestartAgent is returned by workers to cause the agent to exit
+// so that it is restarted, for example to pick up a new state server
+// certificate.
+var ErrRestartAgent = errors.New("agent should be restarted")
+
 var loadedInvalid = func() {}
 
 var logger = loggo.GetLogger("juju.worker")
@@ -0,0 +1,177 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instancetagger
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.instancetagger")
+
+// RetryDelay is the time after which the instance tagger tries again
+// to tag instances that could not be tagged.
+var RetryDelay = 30 * time.Second
+
+type instanceTagger struct {
+	st       *state.State
+	tomb     tomb.Tomb
+	observer *worker.EnvironObserver
+	envUUID  string
+
+	// tagged holds the tags most recently set on the instance of
+	// each machine, keyed by machine id.
+	tagged map[string]map[string]string
+}
+
+// NewInstanceTagger returns a worker that keeps the tags of the
+// environment's instances up to date: every instance is tagged with
+// the environment's UUID, its machine's id and the units assigned to
+// the machine, as well as with the tags in the environment's
+// resource-tags setting.
+func NewInstanceTagger(st *state.State) worker.Worker {
+	t := &instanceTagger{
+		st:     st,
+		tagged: make(map[string]map[string]string),
+	}
+	go func() {
+		defer t.tomb.Done()
+		t.tomb.Kill(t.loop())
+	}()
+	return t
+}
+
+func (t *instanceTagger) Kill() {
+	t.tomb.Kill(nil)
+}
+
+func (t *instanceTagger) Wait() error {
+	return t.tomb.Wait()
+}
+
+func (t *instanceTagger) loop() (err error) {
+	env, err := t.st.Environment()
+	if err != nil {
+		return err
+	}
+	t.envUUID = env.UUID()
+	t.observer, err = worker.NewEnvironObserver(t.st)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		obsErr := worker.Stop(t.observer)
+		if err == nil {
+			err = obsErr
+		}
+	}()
+	tagger, ok := t.observer.Environ().(environs.InstanceTagger)
+	if !ok {
+		logger.Infof("%q provider does not support instance tagging", t.observer.Environ().Config().Type())
+		<-t.tomb.Dying()
+		return tomb.ErrDying
+	}
+	machinesw := t.st.WatchMachines()
+	defer watcher.Stop(machinesw, &t.tomb)
+	configw := t.st.WatchForEnvironConfigChanges()
+	defer watcher.Stop(configw, &t.tomb)
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-machinesw.Changes():
+			if !ok {
+				return watcher.MustErr(machinesw)
+			}
+		case _, ok := <-configw.Changes():
+			if !ok {
+				return watcher.MustErr(configw)
+			}
+		case <-retry:
+		}
+		done, err := t.tagInstances(tagger)
+		if err != nil {
+			return err
+		}
+		retry = nil
+		if !done {
+			retry = time.After(RetryDelay)
+		}
+	}
+}
+
+// tagInstances sets the tags of every provisioned machine's instance
+// that are not already up to date, and reports whether all of them
+// now are.
+func (t *instanceTagger) tagInstances(tagger environs.InstanceTagger) (bool, error) {
+	cfg, err := t.st.EnvironConfig()
+	if err != nil {
+		return false, err
+	}
+	machines, err := t.st.AllMachines()
+	if err != nil {
+		return false, err
+	}
+	// Units assigned to containers are hosted by the instance of
+	// the top-level machine.
+	units := make(map[string][]string)
+	for _, m := range machines {
+		hostId := state.TopParentId(m.Id())
+		units[hostId] = append(units[hostId], m.Principals()...)
+	}
+	allDone := true
+	seen := make(map[string]bool)
+	for _, m := range machines {
+		if _, ok := m.ParentId(); ok || m.Life() == state.Dead {
+			continue
+		}
+		seen[m.Id()] = true
+		done, err := t.tagInstance(tagger, m, units[m.Id()], cfg)
+		if err != nil {
+			logger.Errorf("cannot tag instance of machine %s: %v", m.Id(), err)
+		}
+		if err != nil || !done {
+			allDone = false
+		}
+	}
+	for id := range t.tagged {
+		if !seen[id] {
+			delete(t.tagged, id)
+		}
+	}
+	return allDone, nil
+}
+
+// tagInstance sets the tags of the machine's instance, if it has one,
+// and reports whether they are up to date.
+func (t *instanceTagger) tagInstance(tagger environs.InstanceTagger, m *state.Machine, units []string, cfg *config.Config) (bool, error) {
+	instId, err := m.InstanceId()
+	if state.IsNotProvisionedError(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	sort.Strings(units)
+	tags := environs.InstanceTags(t.envUUID, m.Id(), units, cfg)
+	if reflect.DeepEqual(t.tagged[m.Id()], tags) {
+		return true, nil
+	}
+	if err := tagger.TagInstance(instId, tags); err != nil {
+		return false, err
+	}
+	logger.Debugf("tagged instance %s of machine %s", instId, m.Id())
+	t.tagged[m.Id()] = tags
+	return true, nil
+}
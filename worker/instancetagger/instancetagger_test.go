@@ -0,0 +1,103 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instancetagger_test
+
+import (
+	"reflect"
+	stdtesting "testing"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/instancetagger"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type InstanceTaggerSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&InstanceTaggerSuite{})
+
+func (s *InstanceTaggerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(&instancetagger.RetryDelay, 10*time.Millisecond)
+}
+
+func (s *InstanceTaggerSuite) waitTags(c *gc.C, inst instance.Instance, expected map[string]string) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if reflect.DeepEqual(dummy.InstanceTags(inst), expected) {
+			return
+		}
+	}
+	c.Assert(dummy.InstanceTags(inst), jc.DeepEquals, expected)
+}
+
+func (s *InstanceTaggerSuite) TestTagInstances(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"resource-tags": "team=ops"}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, s.Conn.Environ, machine.Id())
+	err = machine.SetProvisioned(inst.Id(), "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+
+	t := instancetagger.NewInstanceTagger(s.State)
+	defer func() { c.Assert(worker.Stop(t), gc.IsNil) }()
+	s.waitTags(c, inst, map[string]string{
+		"team":               "ops",
+		"juju-resource-tags": "team",
+		"juju-env-uuid":      env.UUID(),
+		"juju-machine-id":    machine.Id(),
+		"juju-units":         "",
+	})
+
+	// Units assigned to the machine, or to containers on it, are
+	// added as they are assigned.
+	unit, err := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql")).AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, machine.Id(), instance.LXC)
+	c.Assert(err, gc.IsNil)
+	unit, err = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress")).AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(container)
+	c.Assert(err, gc.IsNil)
+	s.waitTags(c, inst, map[string]string{
+		"team":               "ops",
+		"juju-resource-tags": "team",
+		"juju-env-uuid":      env.UUID(),
+		"juju-machine-id":    machine.Id(),
+		"juju-units":         "mysql/0,wordpress/0",
+	})
+
+	// Changes to the user-defined tags are applied too, and tags
+	// no longer wanted are removed.
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{"resource-tags": "owner=bob"}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	s.waitTags(c, inst, map[string]string{
+		"owner":              "bob",
+		"juju-resource-tags": "owner",
+		"juju-env-uuid":      env.UUID(),
+		"juju-machine-id":    machine.Id(),
+		"juju-units":         "mysql/0,wordpress/0",
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package interruptionwatcher
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.interruptionwatcher")
+
+// PollInterval is the time between checks for interrupted instances.
+var PollInterval = time.Minute
+
+type interruptionWatcher struct {
+	st   *state.State
+	tomb tomb.Tomb
+}
+
+// NewInterruptionWatcher returns a worker that periodically asks the
+// environment's provider which instances it has terminated, or is
+// about to terminate, of its own accord (as happens to outbid EC2 spot
+// instances), and sets the status of their machines to error so that
+// the interruption is visible in status.
+func NewInterruptionWatcher(st *state.State) worker.Worker {
+	w := &interruptionWatcher{st: st}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *interruptionWatcher) Kill() {
+	w.tomb.Kill(nil)
+}
+
+func (w *interruptionWatcher) Wait() error {
+	return w.tomb.Wait()
+}
+
+func (w *interruptionWatcher) loop() (err error) {
+	observer, err := worker.NewEnvironObserver(w.st)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		obsErr := worker.Stop(observer)
+		if err == nil {
+			err = obsErr
+		}
+	}()
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(PollInterval):
+		}
+		reporter, ok := observer.Environ().(environs.InterruptionReporter)
+		if !ok {
+			continue
+		}
+		if err := w.check(reporter); err != nil {
+			return err
+		}
+	}
+}
+
+// check asks the reporter which of the instances of the machines in
+// state have been interrupted, and sets the status of those machines
+// to error, with the reason for the interruption.
+func (w *interruptionWatcher) check(reporter environs.InterruptionReporter) error {
+	machines, err := w.st.AllMachines()
+	if err != nil {
+		return err
+	}
+	provisioned := make(map[instance.Id]*state.Machine)
+	var ids []instance.Id
+	for _, m := range machines {
+		instId, err := m.InstanceId()
+		if state.IsNotProvisionedError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if m.Life() == state.Dead {
+			continue
+		}
+		provisioned[instId] = m
+		ids = append(ids, instId)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	interrupted, err := reporter.InterruptedInstances(ids)
+	if err != nil {
+		logger.Errorf("cannot get interrupted instances: %v", err)
+		return nil
+	}
+	for instId, reason := range interrupted {
+		m, ok := provisioned[instId]
+		if !ok {
+			continue
+		}
+		info := "instance interrupted: " + reason
+		status, statusInfo, _, err := m.Status()
+		if err != nil {
+			return err
+		}
+		if status == params.StatusError && statusInfo == info {
+			continue
+		}
+		logger.Warningf("instance %s of machine %s interrupted: %s", instId, m.Id(), reason)
+		if err := m.SetStatus(params.StatusError, info, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
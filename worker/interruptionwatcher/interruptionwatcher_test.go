@@ -0,0 +1,71 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package interruptionwatcher_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/interruptionwatcher"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type InterruptionWatcherSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&InterruptionWatcherSuite{})
+
+func (s *InterruptionWatcherSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(&interruptionwatcher.PollInterval, 10*time.Millisecond)
+}
+
+func (s *InterruptionWatcherSuite) addProvisionedMachine(c *gc.C) (*state.Machine, instance.Instance) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, s.Conn.Environ, machine.Id())
+	err = machine.SetProvisioned(inst.Id(), "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	return machine, inst
+}
+
+func (s *InterruptionWatcherSuite) TestMarksInterruptedMachines(c *gc.C) {
+	machine0, inst0 := s.addProvisionedMachine(c)
+	machine1, _ := s.addProvisionedMachine(c)
+
+	w := interruptionwatcher.NewInterruptionWatcher(s.State)
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+
+	dummy.InterruptInstance(inst0, "outbid")
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		status, _, _, err := machine0.Status()
+		c.Assert(err, gc.IsNil)
+		if status == params.StatusError {
+			break
+		}
+	}
+	status, info, _, err := machine0.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusError)
+	c.Assert(info, gc.Equals, "instance interrupted: outbid")
+
+	status, _, _, err = machine1.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusStarted)
+}
@@ -0,0 +1,204 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package introspection provides a worker that serves a report on the
+// state of a running agent over a local unix socket, so that the
+// agent's workers, goroutines and configuration can be inspected
+// without restarting it.
+package introspection
+
+import (
+	"bytes"
+	"net"
+	"net/rpc"
+	"os"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.introspection")
+
+// SocketFile is the name of the introspection socket in an agent's
+// directory.
+const SocketFile = "introspection.socket"
+
+const reportEndpoint = "Introspection.Report"
+
+// connectionTimeout holds the time a client connection is allowed to
+// remain open, so that a stuck client cannot prevent the worker from
+// stopping.
+var connectionTimeout = time.Minute
+
+// processStarted holds approximately when the agent process started.
+var processStarted = time.Now()
+
+// Report holds the report on an agent served by the introspection
+// worker.
+type Report struct {
+	Agent   string
+	Version string
+
+	// Started holds when the agent process started.
+	Started time.Time
+
+	// Config holds a summary of the agent's configuration.
+	Config map[string]string
+
+	// Workers holds the status of the agent's workers.
+	Workers []worker.WorkerStatus
+
+	// Goroutines holds the stacks of all the agent's goroutines, if
+	// they were asked for.
+	Goroutines string
+}
+
+// ReportArgs holds the arguments to a report request.
+type ReportArgs struct {
+	Goroutines bool
+}
+
+// Config holds the configuration of an introspection worker.
+type Config struct {
+	// Agent holds the tag of the agent.
+	Agent string
+
+	// SocketPath holds the path of the unix socket to listen on.
+	SocketPath string
+
+	// Reporter reports the status of the agent's workers. It
+	// may be nil.
+	Reporter worker.Reporter
+
+	// ConfigSummary returns a summary of the agent's configuration.
+	// It must not include any secrets. It may be nil.
+	ConfigSummary func() map[string]string
+}
+
+// Introspection holds the methods that are called over the rpc
+// connection.
+type Introspection struct {
+	config Config
+}
+
+// Report returns a report on the agent.
+func (s *Introspection) Report(args ReportArgs, report *Report) error {
+	*report = Report{
+		Agent:   s.config.Agent,
+		Version: version.Current.String(),
+		Started: processStarted,
+	}
+	if s.config.ConfigSummary != nil {
+		report.Config = s.config.ConfigSummary()
+	}
+	if s.config.Reporter != nil {
+		report.Workers = s.config.Reporter.WorkerStatus()
+	}
+	if args.Goroutines {
+		var buf bytes.Buffer
+		if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+			return err
+		}
+		report.Goroutines = buf.String()
+	}
+	return nil
+}
+
+type introspectionWorker struct {
+	tomb     tomb.Tomb
+	listener net.Listener
+	server   *rpc.Server
+}
+
+// NewWorker returns a worker that serves reports on the agent over
+// the unix socket at config.SocketPath. Only the owner of the agent
+// process may connect to the socket.
+func NewWorker(config Config) (worker.Worker, error) {
+	server := rpc.NewServer()
+	if err := server.Register(&Introspection{config}); err != nil {
+		return nil, err
+	}
+	// In case the unix socket is present, delete it.
+	if err := os.Remove(config.SocketPath); err != nil {
+		logger.Tracef("ignoring error on removing %q: %v", config.SocketPath, err)
+	}
+	listener, err := net.Listen("unix", config.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(config.SocketPath, 0700); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	logger.Debugf("introspection listening on unix:%s", config.SocketPath)
+	w := &introspectionWorker{
+		listener: listener,
+		server:   server,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+func (w *introspectionWorker) loop() error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	go func() {
+		<-w.tomb.Dying()
+		w.listener.Close()
+	}()
+	for {
+		conn, err := w.listener.Accept()
+		if err != nil {
+			select {
+			case <-w.tomb.Dying():
+				return tomb.ErrDying
+			default:
+				return err
+			}
+		}
+		if err := conn.SetDeadline(time.Now().Add(connectionTimeout)); err != nil {
+			logger.Warningf("cannot set introspection connection deadline: %v", err)
+			conn.Close()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.server.ServeConn(conn)
+		}()
+	}
+}
+
+// Kill implements worker.Worker.Kill.
+func (w *introspectionWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait implements worker.Worker.Wait.
+func (w *introspectionWorker) Wait() error {
+	return w.tomb.Wait()
+}
+
+// Query asks the introspection worker listening on the given unix
+// socket for a report on its agent.
+func Query(socketPath string, args ReportArgs) (*Report, error) {
+	client, err := rpc.Dial("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	var report Report
+	if err := client.Call(reportEndpoint, args, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package introspection_test
+
+import (
+	"net"
+	"path/filepath"
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/introspection"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type introspectionSuite struct {
+	testing.BaseSuite
+	socketPath string
+}
+
+var _ = gc.Suite(&introspectionSuite{})
+
+func (s *introspectionSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.socketPath = filepath.Join(c.MkDir(), introspection.SocketFile)
+}
+
+type fakeReporter []worker.WorkerStatus
+
+func (r fakeReporter) WorkerStatus() []worker.WorkerStatus {
+	return r
+}
+
+func (s *introspectionSuite) startWorker(c *gc.C, reporter worker.Reporter) worker.Worker {
+	w, err := introspection.NewWorker(introspection.Config{
+		Agent:      "machine-1",
+		SocketPath: s.socketPath,
+		Reporter:   reporter,
+		ConfigSummary: func() map[string]string {
+			return map[string]string{"data-dir": "/var/lib/juju"}
+		},
+	})
+	c.Assert(err, gc.IsNil)
+	return w
+}
+
+func (s *introspectionSuite) TestReport(c *gc.C) {
+	status := []worker.WorkerStatus{{
+		Id:        "api",
+		State:     worker.WorkerRestarting,
+		Restarts:  2,
+		LastError: "connection refused",
+	}, {
+		Id:    "upgrader",
+		State: worker.WorkerRunning,
+	}}
+	w := s.startWorker(c, fakeReporter(status))
+	defer worker.Stop(w)
+
+	report, err := introspection.Query(s.socketPath, introspection.ReportArgs{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(report.Agent, gc.Equals, "machine-1")
+	c.Assert(report.Version, gc.Equals, version.Current.String())
+	c.Assert(report.Started.IsZero(), gc.Equals, false)
+	c.Assert(report.Config, gc.DeepEquals, map[string]string{"data-dir": "/var/lib/juju"})
+	c.Assert(report.Workers, gc.DeepEquals, status)
+	c.Assert(report.Goroutines, gc.Equals, "")
+}
+
+func (s *introspectionSuite) TestReportGoroutines(c *gc.C) {
+	w := s.startWorker(c, nil)
+	defer worker.Stop(w)
+
+	report, err := introspection.Query(s.socketPath, introspection.ReportArgs{Goroutines: true})
+	c.Assert(err, gc.IsNil)
+	c.Assert(report.Workers, gc.HasLen, 0)
+	c.Assert(report.Goroutines, gc.Matches, "(?s)goroutine [0-9]+ .*")
+}
+
+func (s *introspectionSuite) TestStopWithStuckClient(c *gc.C) {
+	s.PatchValue(introspection.ConnectionTimeout, 100*time.Millisecond)
+	w := s.startWorker(c, nil)
+	conn, err := net.Dial("unix", s.socketPath)
+	c.Assert(err, gc.IsNil)
+	defer conn.Close()
+
+	// The client never sends a request, but the worker still stops.
+	stopped := make(chan error, 1)
+	go func() { stopped <- worker.Stop(w) }()
+	select {
+	case err := <-stopped:
+		c.Assert(err, gc.IsNil)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("worker did not stop")
+	}
+}
+
+func (s *introspectionSuite) TestStop(c *gc.C) {
+	w := s.startWorker(c, nil)
+	c.Assert(worker.Stop(w), gc.IsNil)
+
+	_, err := introspection.Query(s.socketPath, introspection.ReportArgs{})
+	c.Assert(err, gc.NotNil)
+}
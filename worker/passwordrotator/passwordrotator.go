@@ -0,0 +1,184 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package passwordrotator
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/agent"
+	apiwatcher "github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.passwordrotator")
+
+// RotationInterval holds how often agents change their passwords
+// when not asked to do so.
+var RotationInterval = 30 * 24 * time.Hour
+
+// PasswordSetter is implemented by the API entity whose password
+// is changed.
+type PasswordSetter interface {
+	SetPassword(password string) error
+}
+
+// ConfigChanger is implemented by agents, and is used to record the
+// agent's new password.
+type ConfigChanger interface {
+	ChangeConfig(func(agent.ConfigSetter)) error
+}
+
+// ChangePassword generates a new password for the agent, records it
+// in the agent's configuration along with oldPassword, which must be
+// the password the agent is currently able to log in with, and then
+// sets it on the entity. It returns the new password.
+//
+// The configuration is changed *before* the entity password, so that
+// we never successfully change the entity's password but fail to write
+// the configuration, thus locking the agent out completely. If the
+// entity password is not changed, the agent falls back to oldPassword
+// when it next connects, and changes its password again then.
+func ChangePassword(changer ConfigChanger, entity PasswordSetter, oldPassword string) (string, error) {
+	newPassword, err := utils.RandomPassword()
+	if err != nil {
+		return "", err
+	}
+	if err := changer.ChangeConfig(func(c agent.ConfigSetter) {
+		c.SetPassword(newPassword)
+		c.SetOldPassword(oldPassword)
+		c.SetValue(agent.PasswordChanged, time.Now().UTC().Format(time.RFC3339))
+	}); err != nil {
+		return "", err
+	}
+	if err := entity.SetPassword(newPassword); err != nil {
+		return "", err
+	}
+	return newPassword, nil
+}
+
+// RotationGetter is implemented by the API facade used to find out
+// when the agent has been asked to change its password.
+type RotationGetter interface {
+	PasswordRotationRequired() (bool, error)
+	WatchPasswordRotation() (apiwatcher.NotifyWatcher, error)
+}
+
+// Agent is implemented by the machine and unit agents.
+type Agent interface {
+	ConfigChanger
+	CurrentConfig() agent.Config
+}
+
+type passwordRotator struct {
+	tomb   tomb.Tomb
+	getter RotationGetter
+	entity PasswordSetter
+	agent  Agent
+}
+
+// NewPasswordRotator returns a worker that changes the agent's
+// password every RotationInterval, and whenever the agent is asked to
+// do so (see "juju rotate-agent-passwords").
+//
+// If the password cannot be changed once the agent's configuration
+// has been written, the worker stops with worker.ErrRestartAgent, so
+// that the agent reconnects using its old password and changes its
+// password again then. A state server agent also restarts once its
+// password has been changed, as its mongo password changes with it and
+// its direct connection to state would otherwise keep using the old
+// one.
+func NewPasswordRotator(getter RotationGetter, entity PasswordSetter, agent Agent) worker.Worker {
+	r := &passwordRotator{
+		getter: getter,
+		entity: entity,
+		agent:  agent,
+	}
+	go func() {
+		defer r.tomb.Done()
+		r.tomb.Kill(r.loop())
+	}()
+	return r
+}
+
+func (r *passwordRotator) Kill() {
+	r.tomb.Kill(nil)
+}
+
+func (r *passwordRotator) Wait() error {
+	return r.tomb.Wait()
+}
+
+func (r *passwordRotator) loop() error {
+	w, err := r.getter.WatchPasswordRotation()
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop(w, &r.tomb)
+	for {
+		due, err := r.nextRotation()
+		if err != nil {
+			return err
+		}
+		select {
+		case <-r.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.MustErr(w)
+			}
+			required, err := r.getter.PasswordRotationRequired()
+			if err != nil {
+				return err
+			}
+			if !required {
+				continue
+			}
+			logger.Infof("password change requested")
+		case <-time.After(due.Sub(time.Now())):
+			logger.Infof("password last changed more than %v ago", RotationInterval)
+		}
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+}
+
+// nextRotation returns when the agent's password is next due to be
+// changed. If the agent has no record of when its password last
+// changed, the time is recorded as now.
+func (r *passwordRotator) nextRotation() (time.Time, error) {
+	value := r.agent.CurrentConfig().Value(agent.PasswordChanged)
+	changed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		changed = time.Now().UTC()
+		if err := r.agent.ChangeConfig(func(c agent.ConfigSetter) {
+			c.SetValue(agent.PasswordChanged, changed.Format(time.RFC3339))
+		}); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return changed.Add(RotationInterval), nil
+}
+
+func (r *passwordRotator) rotate() error {
+	oldPassword := r.agent.CurrentConfig().APIInfo().Password
+	if _, err := ChangePassword(r.agent, r.entity, oldPassword); err != nil {
+		// The new password may have been recorded without being
+		// set, in which case only the old password is now valid,
+		// and the agent must reconnect with it.
+		logger.Errorf("cannot change password: %v", err)
+		return worker.ErrRestartAgent
+	}
+	logger.Infof("password changed")
+	if _, ok := r.agent.CurrentConfig().StateServingInfo(); ok {
+		logger.Infof("restarting to connect to state with the new password")
+		return worker.ErrRestartAgent
+	}
+	return nil
+}
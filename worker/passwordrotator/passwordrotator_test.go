@@ -0,0 +1,186 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package passwordrotator_test
+
+import (
+	"fmt"
+	"sync"
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/agent"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/passwordrotator"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type PasswordRotatorSuite struct {
+	jujutesting.JujuConnSuite
+	st      *api.State
+	machine *state.Machine
+	agent   *fakeAgent
+}
+
+var _ = gc.Suite(&PasswordRotatorSuite{})
+
+type fakeAgent struct {
+	mu     sync.Mutex
+	config agent.ConfigSetterWriter
+}
+
+func (a *fakeAgent) ChangeConfig(change func(agent.ConfigSetter)) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	change(a.config)
+	return nil
+}
+
+func (a *fakeAgent) CurrentConfig() agent.Config {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.config.Clone()
+}
+
+func (s *PasswordRotatorSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.st, s.machine = s.OpenAPIAsNewMachine(c)
+	config, err := agent.NewAgentConfig(agent.AgentConfigParams{
+		DataDir:           c.MkDir(),
+		Tag:               s.machine.Tag().String(),
+		UpgradedToVersion: version.Current.Number,
+		Password:          "initial-password",
+		APIAddresses:      []string{"localhost:1234"},
+		CACert:            coretesting.CACert,
+	})
+	c.Assert(err, gc.IsNil)
+	config.SetPassword("current-password")
+	s.agent = &fakeAgent{config: config}
+}
+
+func (s *PasswordRotatorSuite) newRotator(c *gc.C) worker.Worker {
+	entity, err := s.st.Agent().Entity(s.machine.Tag())
+	c.Assert(err, gc.IsNil)
+	return passwordrotator.NewPasswordRotator(s.st.Agent(), entity, s.agent)
+}
+
+func (s *PasswordRotatorSuite) waitForPassword(c *gc.C) string {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		password := s.agent.CurrentConfig().APIInfo().Password
+		if password != "current-password" {
+			return password
+		}
+		s.BackingState.StartSync()
+	}
+	c.Fatalf("timed out waiting for password change")
+	panic("unreachable")
+}
+
+func (s *PasswordRotatorSuite) assertPasswordChanged(c *gc.C, password string) {
+	config := s.agent.CurrentConfig()
+	c.Assert(config.OldPassword(), gc.Equals, "current-password")
+	_, err := time.Parse(time.RFC3339, config.Value(agent.PasswordChanged))
+	c.Assert(err, gc.IsNil)
+	err = s.machine.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.machine.PasswordValid(password), gc.Equals, true)
+	c.Assert(s.machine.PasswordRotationRequested(), gc.Equals, false)
+}
+
+func (s *PasswordRotatorSuite) TestStartStop(c *gc.C) {
+	w := s.newRotator(c)
+	w.Kill()
+	c.Assert(w.Wait(), gc.IsNil)
+
+	// The time of the last change is recorded when first seen.
+	_, err := time.Parse(time.RFC3339, s.agent.CurrentConfig().Value(agent.PasswordChanged))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.agent.CurrentConfig().APIInfo().Password, gc.Equals, "current-password")
+}
+
+func (s *PasswordRotatorSuite) TestRotationRequested(c *gc.C) {
+	w := s.newRotator(c)
+	defer func() { c.Assert(w.Wait(), gc.IsNil) }()
+	defer w.Kill()
+
+	err := s.machine.RequestPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	password := s.waitForPassword(c)
+	s.assertPasswordChanged(c, password)
+}
+
+func (s *PasswordRotatorSuite) TestRotationDue(c *gc.C) {
+	s.PatchValue(&passwordrotator.RotationInterval, time.Hour)
+	changed := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	s.agent.config.SetValue(agent.PasswordChanged, changed)
+
+	w := s.newRotator(c)
+	defer func() { c.Assert(w.Wait(), gc.IsNil) }()
+	defer w.Kill()
+
+	password := s.waitForPassword(c)
+	s.assertPasswordChanged(c, password)
+}
+
+func (s *PasswordRotatorSuite) TestStateServerRestartsAfterRotation(c *gc.C) {
+	s.agent.config.SetStateServingInfo(params.StateServingInfo{
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.ServerKey,
+		StatePort:  1234,
+		APIPort:    1235,
+	})
+	w := s.newRotator(c)
+	defer w.Kill()
+
+	err := s.machine.RequestPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	c.Assert(w.Wait(), gc.Equals, worker.ErrRestartAgent)
+
+	// The password was changed before the agent restarts.
+	password := s.agent.CurrentConfig().APIInfo().Password
+	c.Assert(password, gc.Not(gc.Equals), "current-password")
+	s.assertPasswordChanged(c, password)
+}
+
+type failingEntity struct{}
+
+func (failingEntity) SetPassword(string) error {
+	return fmt.Errorf("boom")
+}
+
+func (s *PasswordRotatorSuite) TestRotationFailure(c *gc.C) {
+	w := passwordrotator.NewPasswordRotator(s.st.Agent(), failingEntity{}, s.agent)
+	defer w.Kill()
+
+	err := s.machine.RequestPasswordRotation()
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	c.Assert(w.Wait(), gc.Equals, worker.ErrRestartAgent)
+
+	// The new password is recorded, but the agent can fall back
+	// to the password it was using.
+	config := s.agent.CurrentConfig()
+	c.Assert(config.APIInfo().Password, gc.Not(gc.Equals), "current-password")
+	c.Assert(config.OldPassword(), gc.Equals, "current-password")
+}
+
+func (s *PasswordRotatorSuite) TestChangePassword(c *gc.C) {
+	entity, err := s.st.Agent().Entity(s.machine.Tag())
+	c.Assert(err, gc.IsNil)
+	password, err := passwordrotator.ChangePassword(s.agent, entity, "current-password")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.agent.CurrentConfig().APIInfo().Password, gc.Equals, password)
+	s.assertPasswordChanged(c, password)
+}
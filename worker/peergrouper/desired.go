@@ -13,7 +13,7 @@ import (
 )
 
 // jujuMachineTag is the key for the tag where we save the member's juju machine id.
-const jujuMachineTag = "juju-machine-id"
+const jujuMachineTag = replicaset.JujuMachineKey
 
 var logger = loggo.GetLogger("juju.worker.peergrouper")
 
@@ -23,6 +23,18 @@ type peerGroupInfo struct {
 	machines map[string]*machine // id -> machine
 	statuses []replicaset.MemberStatus
 	members  []replicaset.Member
+
+	// reasons holds an explanation of each decision
+	// made by desiredPeerGroup.
+	reasons []string
+}
+
+// notef records a reason for the decisions made by
+// desiredPeerGroup.
+func (info *peerGroupInfo) notef(f string, a ...interface{}) {
+	reason := fmt.Sprintf(f, a...)
+	logger.Debugf("%s", reason)
+	info.reasons = append(info.reasons, reason)
 }
 
 // desiredPeerGroup returns the mongo peer group according to the given
@@ -60,6 +72,7 @@ func desiredPeerGroup(info *peerGroupInfo) ([]replicaset.Member, map[*machine]bo
 		if member.Votes == nil || *member.Votes > 0 {
 			return nil, nil, fmt.Errorf("voting non-machine member %#v found in peer group", member)
 		}
+		info.notef("removing non-voting member %q with no state server machine", member.Address)
 		changed = true
 	}
 
@@ -73,14 +86,29 @@ func desiredPeerGroup(info *peerGroupInfo) ([]replicaset.Member, map[*machine]bo
 		machineVoting[m] = member != nil && isVotingMember(member)
 	}
 	setVoting := func(m *machine, voting bool) {
+		if voting {
+			info.notef("giving machine %q a vote", m.id)
+		} else if machineVoting[m] {
+			info.notef("removing the vote of machine %q", m.id)
+		}
 		setMemberVoting(members[m], voting)
 		machineVoting[m] = voting
 		changed = true
 	}
 	adjustVotes(toRemoveVote, toAddVote, setVoting)
+	for _, m := range toAddVote {
+		if !machineVoting[m] {
+			info.notef("machine %q is ready to vote, but is not given a vote so that the number of votes stays odd", m.id)
+		}
+	}
+	for _, m := range toRemoveVote {
+		if machineVoting[m] {
+			info.notef("machine %q keeps its vote so that the number of votes stays odd", m.id)
+		}
+	}
 
-	addNewMembers(members, toKeep, maxId, setVoting)
-	if updateAddresses(members, info.machines) {
+	addNewMembers(info, members, toKeep, maxId, setVoting)
+	if updateAddresses(info, members, info.machines) {
 		changed = true
 	}
 	if !changed {
@@ -112,7 +140,7 @@ func possiblePeerGroupChanges(
 	statuses := info.statusesMap(members)
 
 	logger.Debugf("assessing possible peer group changes:")
-	for _, m := range info.machines {
+	for _, m := range sortedMachines(info.machines) {
 		member := members[m]
 		isVoting := member != nil && isVotingMember(member)
 		switch {
@@ -124,7 +152,11 @@ func possiblePeerGroupChanges(
 				logger.Debugf("machine %q is a potential voter", m.id)
 				toAddVote = append(toAddVote, m)
 			} else {
-				logger.Debugf("machine %q is not ready (has status: %v)", m.id, ok)
+				if ok {
+					info.notef("machine %q wants to vote but is not ready (state %v, healthy %v)", m.id, status.State, status.Healthy)
+				} else {
+					info.notef("machine %q wants to vote but has no replica set status", m.id)
+				}
 				toKeep = append(toKeep, m)
 			}
 		case !m.wantsVote && isVoting:
@@ -148,16 +180,19 @@ func possiblePeerGroupChanges(
 
 // updateAddresses updates the members' addresses from the machines' addresses.
 // It reports whether any changes have been made.
-func updateAddresses(members map[*machine]*replicaset.Member, machines map[string]*machine) bool {
+func updateAddresses(info *peerGroupInfo, members map[*machine]*replicaset.Member, machines map[string]*machine) bool {
 	changed := false
 	// Make sure all members' machine addresses are up to date.
-	for _, m := range machines {
+	for _, m := range sortedMachines(machines) {
 		hp := m.mongoHostPort()
 		if hp == "" {
 			continue
 		}
 		// TODO ensure that replicaset works correctly with IPv6 [host]:port addresses.
 		if hp != members[m].Address {
+			if members[m].Address != "" {
+				info.notef("changing the address of machine %q to %q", m.id, hp)
+			}
 			members[m].Address = hp
 			changed = true
 		}
@@ -204,6 +239,7 @@ func adjustVotes(toRemoveVote, toAddVote []*machine, setVoting func(*machine, bo
 // maxId upwards. It calls setVoting to set the voting
 // status of each new member.
 func addNewMembers(
+	info *peerGroupInfo,
 	members map[*machine]*replicaset.Member,
 	toKeep []*machine,
 	maxId int,
@@ -215,6 +251,7 @@ func addNewMembers(
 			// This machine was not previously in the members list,
 			// so add it (as non-voting). We maintain the
 			// id manually to make it easier for tests.
+			info.notef("adding machine %q to the peer group", m.id)
 			maxId++
 			member := &replicaset.Member{
 				Tags: map[string]string{
@@ -225,7 +262,7 @@ func addNewMembers(
 			members[m] = member
 			setVoting(m, false)
 		} else if !hasAddress {
-			logger.Debugf("ignoring machine %q with no address", m.id)
+			info.notef("ignoring machine %q with no address", m.id)
 		}
 	}
 }
@@ -247,6 +284,18 @@ func setMemberVoting(member *replicaset.Member, voting bool) {
 	}
 }
 
+// sortedMachines returns the given machines sorted by id, so that
+// they are dealt with, and any reasons recorded, in a deterministic
+// order.
+func sortedMachines(machines map[string]*machine) []*machine {
+	sorted := make([]*machine, 0, len(machines))
+	for _, m := range machines {
+		sorted = append(sorted, m)
+	}
+	sort.Sort(byId(sorted))
+	return sorted
+}
+
 type byId []*machine
 
 func (l byId) Len() int           { return len(l) }
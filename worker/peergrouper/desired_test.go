@@ -229,6 +229,80 @@ func (*desiredPeerGroupSuite) TestDesiredPeerGroup(c *gc.C) {
 	}
 }
 
+var desiredPeerGroupReasonsTests = []struct {
+	about         string
+	machines      []*machine
+	statuses      []replicaset.MemberStatus
+	members       []replicaset.Member
+	expectReasons []string
+}{{
+	about:    "single machine, no change",
+	machines: mkMachines("11v"),
+	members:  mkMembers("1v"),
+	statuses: mkStatuses("1p"),
+}, {
+	about:    "new machine with no associated member",
+	machines: mkMachines("11v 12v"),
+	members:  mkMembers("1v"),
+	statuses: mkStatuses("1p"),
+	expectReasons: []string{
+		`machine "12" wants to vote but has no replica set status`,
+		`adding machine "12" to the peer group`,
+	},
+}, {
+	about:    "two machines have become ready to vote but one is not healthy",
+	machines: mkMachines("11v 12v 13v"),
+	members:  mkMembers("1v 2 3"),
+	statuses: mkStatuses("1p 2s 3sH"),
+	expectReasons: []string{
+		`machine "13" wants to vote but is not ready (state SECONDARY, healthy false)`,
+		`machine "12" is ready to vote, but is not given a vote so that the number of votes stays odd`,
+	},
+}, {
+	about:    "one machine ready to lose vote with no others",
+	machines: mkMachines("11 12v 13v"),
+	members:  mkMembers("1v 2v 3v"),
+	statuses: mkStatuses("1p 2s 3s"),
+	expectReasons: []string{
+		`machine "11" keeps its vote so that the number of votes stays odd`,
+	},
+}, {
+	about:    "a candidate can take the vote of a non-candidate",
+	machines: mkMachines("11v 12v 13 14v"),
+	members:  mkMembers("1v 2v 3v 4"),
+	statuses: mkStatuses("1p 2s 3s 4s"),
+	expectReasons: []string{
+		`removing the vote of machine "13"`,
+		`giving machine "14" a vote`,
+	},
+}, {
+	about:    "machine removed as state server",
+	machines: mkMachines("11v"),
+	members:  mkMembers("1v 2"),
+	statuses: mkStatuses("1p 2s"),
+	expectReasons: []string{
+		`removing non-voting member "0.1.2.12:1234" with no state server machine`,
+	},
+}}
+
+func (*desiredPeerGroupSuite) TestDesiredPeerGroupReasons(c *gc.C) {
+	for i, test := range desiredPeerGroupReasonsTests {
+		c.Logf("\ntest %d: %s", i, test.about)
+		machineMap := make(map[string]*machine)
+		for _, m := range test.machines {
+			machineMap[m.id] = m
+		}
+		info := &peerGroupInfo{
+			machines: machineMap,
+			statuses: test.statuses,
+			members:  test.members,
+		}
+		_, _, err := desiredPeerGroup(info)
+		c.Assert(err, gc.IsNil)
+		c.Assert(info.reasons, jc.DeepEquals, test.expectReasons)
+	}
+}
+
 func countVotes(members []replicaset.Member) int {
 	tot := 0
 	for _, m := range members {
@@ -30,6 +30,7 @@ type fakeState struct {
 	mu           sync.Mutex
 	machines     map[string]*fakeMachine
 	stateServers voyeur.Value // of *state.StateServerInfo
+	decisions    voyeur.Value // of []state.PeerGroupDecision
 	session      *fakeMongoSession
 	check        func(st *fakeState) error
 }
@@ -230,6 +231,17 @@ func (st *fakeState) WatchStateServerInfo() state.NotifyWatcher {
 	return WatchValue(&st.stateServers)
 }
 
+func (st *fakeState) SetPeerGroupDecision(decision state.PeerGroupDecision) error {
+	if err := errorFor("State.SetPeerGroupDecision"); err != nil {
+		return err
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	decisions, _ := st.decisions.Get().([]state.PeerGroupDecision)
+	st.decisions.Set(append(decisions, decision))
+	return nil
+}
+
 type fakeMachine struct {
 	mu      sync.Mutex
 	val     voyeur.Value // of machineDoc
@@ -5,6 +5,7 @@ package peergrouper
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
@@ -24,6 +25,7 @@ type stateInterface interface {
 	WatchStateServerInfo() state.NotifyWatcher
 	StateServerInfo() (*state.StateServerInfo, error)
 	MongoSession() mongoSession
+	SetPeerGroupDecision(decision state.PeerGroupDecision) error
 }
 
 type stateMachine interface {
@@ -109,6 +111,10 @@ type pgWorker struct {
 	// publisher holds the implementation of the API
 	// address publisher.
 	publisher publisherInterface
+
+	// lastDecision holds the decision most recently
+	// recorded in the state.
+	lastDecision *state.PeerGroupDecision
 }
 
 // New returns a new worker that maintains the mongo replica set
@@ -268,12 +274,21 @@ type replicaSetError struct {
 }
 
 // updateReplicaset sets the current replica set members, and applies the
-// given voting status to machines in the state.
-func (w *pgWorker) updateReplicaset() error {
+// given voting status to machines in the state. The reasons for any
+// changes, and any error, are recorded in the state.
+func (w *pgWorker) updateReplicaset() (err error) {
 	info, err := w.peerGroupInfo()
 	if err != nil {
 		return err
 	}
+	var decision state.PeerGroupDecision
+	defer func() {
+		decision.Reasons = info.reasons
+		if err != nil {
+			decision.Error = err.Error()
+		}
+		w.recordDecision(decision)
+	}()
 	members, voting, err := desiredPeerGroup(info)
 	if err != nil {
 		return fmt.Errorf("cannot compute desired peer group: %v", err)
@@ -328,6 +343,7 @@ func (w *pgWorker) updateReplicaset() error {
 			return &replicaSetError{err}
 		}
 		logger.Infof("successfully changed replica set to %#v", members)
+		decision.Changed = true
 	}
 	if err := setHasVote(removed, false); err != nil {
 		return err
@@ -335,6 +351,25 @@ func (w *pgWorker) updateReplicaset() error {
 	return nil
 }
 
+// recordDecision records the given decision in the state, unless it
+// is the same as the decision last recorded. Failure to record the
+// decision is logged but otherwise ignored, as it does not affect
+// the replica set.
+func (w *pgWorker) recordDecision(decision state.PeerGroupDecision) {
+	if last := w.lastDecision; last != nil &&
+		!decision.Changed && !last.Changed &&
+		decision.Error == last.Error &&
+		reflect.DeepEqual(decision.Reasons, last.Reasons) {
+		return
+	}
+	decision.Time = time.Now()
+	if err := w.st.SetPeerGroupDecision(decision); err != nil {
+		logger.Errorf("cannot record peer group decision: %v", err)
+		return
+	}
+	w.lastDecision = &decision
+}
+
 // start runs the given loop function until it returns.
 // When it returns, the receiving pgWorker is killed with
 // the returned error.
@@ -15,6 +15,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker"
@@ -504,6 +505,76 @@ func (s *workerSuite) TestWorkerPublishesInstanceIds(c *gc.C) {
 }
 
 // mustNext waits for w's value to be set and returns it.
+// nextDecision waits for the worker to record a decision after
+// the first n, and returns it.
+func nextDecision(c *gc.C, w *voyeur.Watcher, n int) state.PeerGroupDecision {
+	for {
+		decisions := mustNext(c, w).([]state.PeerGroupDecision)
+		if len(decisions) > n {
+			return decisions[n]
+		}
+	}
+}
+
+func (s *workerSuite) TestRecordsDecision(c *gc.C) {
+	s.PatchValue(&pollInterval, 5*time.Millisecond)
+
+	st := newFakeState()
+	initState(c, st, 3)
+	decisionWatcher := st.decisions.Watch()
+
+	w := newWorker(st, noPublisher{})
+	defer func() {
+		c.Check(worker.Stop(w), gc.IsNil)
+	}()
+
+	// The worker adds the new machines to the peer group.
+	decision := nextDecision(c, decisionWatcher, 0)
+	c.Assert(decision.Changed, jc.IsTrue)
+	c.Assert(decision.Error, gc.Equals, "")
+	c.Assert(decision.Time.IsZero(), jc.IsFalse)
+	c.Assert(decision.Reasons, jc.DeepEquals, []string{
+		`machine "11" wants to vote but has no replica set status`,
+		`machine "12" wants to vote but has no replica set status`,
+		`adding machine "11" to the peer group`,
+		`adding machine "12" to the peer group`,
+	})
+
+	// The new members are not ready to vote yet. The same
+	// decision is recorded only once.
+	decision = nextDecision(c, decisionWatcher, 1)
+	c.Assert(decision.Changed, jc.IsFalse)
+	c.Assert(decision.Reasons, jc.DeepEquals, []string{
+		`machine "11" wants to vote but has no replica set status`,
+		`machine "12" wants to vote but has no replica set status`,
+	})
+
+	// Once the new members are ready, they are given votes.
+	st.session.setStatus(mkStatuses("0p 1s 2s"))
+	decision = nextDecision(c, decisionWatcher, 2)
+	c.Assert(decision.Changed, jc.IsTrue)
+	c.Assert(decision.Reasons, jc.DeepEquals, []string{
+		`giving machine "11" a vote`,
+		`giving machine "12" a vote`,
+	})
+}
+
+func (s *workerSuite) TestRecordsReplicaSetError(c *gc.C) {
+	st := newFakeState()
+	initState(c, st, 3)
+	decisionWatcher := st.decisions.Watch()
+	setErrorFor("Session.Set", errors.New("sample"))
+
+	w := newWorker(st, noPublisher{})
+	defer func() {
+		c.Check(worker.Stop(w), gc.IsNil)
+	}()
+
+	decision := nextDecision(c, decisionWatcher, 0)
+	c.Assert(decision.Changed, jc.IsFalse)
+	c.Assert(decision.Error, gc.Equals, "sample")
+}
+
 func mustNext(c *gc.C, w *voyeur.Watcher) (val interface{}) {
 	done := make(chan bool)
 	go func() {
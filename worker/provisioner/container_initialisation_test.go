@@ -15,6 +15,7 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/docker"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
@@ -58,6 +59,7 @@ func (s *ContainerSetupSuite) SetUpTest(c *gc.C) {
 	s.CommonProvisionerSuite.setupEnvironmentManager(c)
 	aptCmdChan := s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
 	s.aptCmdChan = aptCmdChan
+	s.PatchValue(&docker.VerifyVersion, func() error { return nil })
 
 	// Set up provisioner for the state machine.
 	s.agentConfig = s.AgentConfigForTag(c, "machine-0")
@@ -146,7 +148,7 @@ func (s *ContainerSetupSuite) TestContainerProvisionerStarted(c *gc.C) {
 			Constraints: s.defaultConstraints,
 		})
 		c.Assert(err, gc.IsNil)
-		err = m.SetSupportedContainers([]instance.ContainerType{instance.LXC, instance.KVM})
+		err = m.SetSupportedContainers(instance.ContainerTypes...)
 		c.Assert(err, gc.IsNil)
 		err = m.SetAgentVersion(version.Current)
 		c.Assert(err, gc.IsNil)
@@ -204,6 +206,7 @@ func (s *ContainerSetupSuite) TestContainerInitialised(c *gc.C) {
 	}{
 		{instance.LXC, []string{"--target-release", "precise-updates/cloud-tools", "lxc", "cloud-image-utils"}},
 		{instance.KVM, []string{"uvtool-libvirt", "uvtool"}},
+		{instance.DOCKER, []string{"docker.io"}},
 	} {
 		s.assertContainerInitialised(c, test.ctype, test.packages)
 	}
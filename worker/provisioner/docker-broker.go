@@ -0,0 +1,112 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"fmt"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/docker"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/tools"
+)
+
+var dockerLogger = loggo.GetLogger("juju.provisioner.docker")
+
+var _ environs.InstanceBroker = (*dockerBroker)(nil)
+var _ tools.HasTools = (*dockerBroker)(nil)
+
+func NewDockerBroker(
+	api APICalls,
+	tools *tools.Tools,
+	agentConfig agent.Config,
+	managerConfig container.ManagerConfig,
+) (environs.InstanceBroker, error) {
+	manager, err := docker.NewContainerManager(managerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerBroker{
+		manager:     manager,
+		api:         api,
+		tools:       tools,
+		agentConfig: agentConfig,
+	}, nil
+}
+
+type dockerBroker struct {
+	manager     container.Manager
+	api         APICalls
+	tools       *tools.Tools
+	agentConfig agent.Config
+}
+
+func (broker *dockerBroker) Tools(series string) tools.List {
+	seriesTools := *broker.tools
+	seriesTools.Version.Series = series
+	return tools.List{&seriesTools}
+}
+
+// StartInstance is specified in the Broker interface.
+func (broker *dockerBroker) StartInstance(args environs.StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, []network.Info, error) {
+	if args.MachineConfig.HasNetworks() {
+		return nil, nil, nil, fmt.Errorf("starting docker containers with networks is not supported yet.")
+	}
+	machineId := args.MachineConfig.MachineId
+	dockerLogger.Infof("starting docker container for machineId: %s", machineId)
+
+	// Docker containers are always attached to docker's own bridge.
+	var network *container.NetworkConfig
+
+	series := args.Tools.OneSeries()
+	args.MachineConfig.MachineContainerType = instance.DOCKER
+	args.MachineConfig.Tools = args.Tools[0]
+
+	config, err := broker.api.ContainerConfig()
+	if err != nil {
+		dockerLogger.Errorf("failed to get container config: %v", err)
+		return nil, nil, nil, err
+	}
+	if err := environs.PopulateMachineConfig(
+		args.MachineConfig,
+		config.ProviderType,
+		config.AuthorizedKeys,
+		config.SSLHostnameVerification,
+		config.Proxy,
+		config.AptProxy,
+	); err != nil {
+		dockerLogger.Errorf("failed to populate machine config: %v", err)
+		return nil, nil, nil, err
+	}
+
+	inst, hardware, err := broker.manager.CreateContainer(args.MachineConfig, series, network)
+	if err != nil {
+		dockerLogger.Errorf("failed to start container: %v", err)
+		return nil, nil, nil, err
+	}
+	dockerLogger.Infof("started docker container for machineId: %s, %s, %s", machineId, inst.Id(), hardware.String())
+	return inst, hardware, nil, nil
+}
+
+// StopInstances shuts down the given instances.
+func (broker *dockerBroker) StopInstances(ids ...instance.Id) error {
+	for _, id := range ids {
+		dockerLogger.Infof("stopping docker container for instance: %s", id)
+		if err := broker.manager.DestroyContainer(id); err != nil {
+			dockerLogger.Errorf("container did not stop: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// AllInstances only returns running containers.
+func (broker *dockerBroker) AllInstances() (result []instance.Instance, err error) {
+	return broker.manager.ListContainers()
+}
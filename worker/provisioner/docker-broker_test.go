@@ -0,0 +1,87 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner_test
+
+import (
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	dockertesting "github.com/juju/juju/container/docker/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	instancetest "github.com/juju/juju/instance/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker/provisioner"
+)
+
+type dockerBrokerSuite struct {
+	dockertesting.TestSuite
+	broker environs.InstanceBroker
+}
+
+var _ = gc.Suite(&dockerBrokerSuite{})
+
+func (s *dockerBrokerSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	tools := &coretools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+	}
+	agentConfig, err := agent.NewAgentConfig(
+		agent.AgentConfigParams{
+			DataDir:           "/not/used/here",
+			Tag:               "tag",
+			UpgradedToVersion: version.Current.Number,
+			Password:          "dummy-secret",
+			Nonce:             "nonce",
+			APIAddresses:      []string{"10.0.0.1:1234"},
+			CACert:            coretesting.CACert,
+		})
+	c.Assert(err, gc.IsNil)
+	managerConfig := container.ManagerConfig{container.ConfigName: "juju"}
+	s.broker, err = provisioner.NewDockerBroker(&fakeAPI{}, tools, agentConfig, managerConfig)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *dockerBrokerSuite) startInstance(c *gc.C, machineId string) instance.Instance {
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig := environs.NewMachineConfig(machineId, "fake-nonce", nil, stateInfo, apiInfo)
+	possibleTools := s.broker.(coretools.HasTools).Tools("trusty")
+	inst, _, _, err := s.broker.StartInstance(environs.StartInstanceParams{
+		Constraints:   constraints.Value{},
+		Tools:         possibleTools,
+		MachineConfig: machineConfig,
+	})
+	c.Assert(err, gc.IsNil)
+	return inst
+}
+
+func (s *dockerBrokerSuite) TestStartInstance(c *gc.C) {
+	inst := s.startInstance(c, "1/docker/0")
+	c.Assert(inst.Id(), gc.Equals, instance.Id("juju-machine-1-docker-0"))
+	script := filepath.Join(s.ContainerDir, string(inst.Id()), "container-init.sh")
+	c.Assert(script, jc.IsNonEmptyFile)
+}
+
+func (s *dockerBrokerSuite) TestStopInstance(c *gc.C) {
+	docker0 := s.startInstance(c, "1/docker/0")
+	docker1 := s.startInstance(c, "1/docker/1")
+
+	err := s.broker.StopInstances(docker0.Id())
+	c.Assert(err, gc.IsNil)
+	results, err := s.broker.AllInstances()
+	c.Assert(err, gc.IsNil)
+	instancetest.MatchInstances(c, results, docker1)
+	c.Assert(filepath.Join(s.ContainerDir, string(docker0.Id())), jc.DoesNotExist)
+	c.Assert(filepath.Join(s.RemovedDir, string(docker0.Id())), jc.IsDirectory)
+}
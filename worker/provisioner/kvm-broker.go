@@ -76,6 +76,7 @@ func (broker *kvmBroker) StartInstance(args environs.StartInstanceParams) (insta
 	// TODO: series doesn't necessarily need to be the same as the host.
 	series := args.Tools.OneSeries()
 	args.MachineConfig.MachineContainerType = instance.KVM
+	args.MachineConfig.Constraints = args.Constraints
 	args.MachineConfig.Tools = args.Tools[0]
 
 	config, err := broker.api.ContainerConfig()
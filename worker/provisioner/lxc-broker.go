@@ -73,6 +73,7 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (insta
 
 	series := args.Tools.OneSeries()
 	args.MachineConfig.MachineContainerType = instance.LXC
+	args.MachineConfig.Constraints = args.Constraints
 	args.MachineConfig.Tools = args.Tools[0]
 
 	config, err := broker.api.ContainerConfig()
@@ -5,6 +5,7 @@ package worker
 
 import (
 	"errors"
+	"sort"
 	"time"
 
 	"launchpad.net/tomb"
@@ -31,6 +32,44 @@ type Runner interface {
 	StopWorker(id string) error
 }
 
+// The states of a worker reported in WorkerStatus.
+const (
+	WorkerStarting   = "starting"
+	WorkerRunning    = "running"
+	WorkerRestarting = "restarting"
+	WorkerStopping   = "stopping"
+)
+
+// WorkerStatus describes a worker run by a Runner.
+type WorkerStatus struct {
+	Id    string
+	State string
+
+	// Started holds when the worker last started, if it is running.
+	Started time.Time
+
+	// Restarts holds the number of times the worker has been
+	// restarted, and RestartAt when it will next be restarted if
+	// it is waiting to do so.
+	Restarts  int
+	RestartAt time.Time
+
+	// LastError holds the most recent error returned by the worker,
+	// and LastErrorTime when it was returned.
+	LastError     string
+	LastErrorTime time.Time
+
+	// Workers holds the status of the workers run by the worker,
+	// if it is itself a Reporter.
+	Workers []WorkerStatus
+}
+
+// Reporter is implemented by workers that can report the status of
+// the workers they run.
+type Reporter interface {
+	WorkerStatus() []WorkerStatus
+}
+
 // runner runs a set of workers, restarting them as necessary
 // when they fail.
 type runner struct {
@@ -39,11 +78,15 @@ type runner struct {
 	stopc         chan string
 	donec         chan doneInfo
 	startedc      chan startInfo
+	statusc       chan chan []workerSnapshot
 	isFatal       func(error) bool
 	moreImportant func(err0, err1 error) bool
 }
 
-var _ Runner = (*runner)(nil)
+var (
+	_ Runner   = (*runner)(nil)
+	_ Reporter = (*runner)(nil)
+)
 
 type startReq struct {
 	id    string
@@ -60,6 +103,11 @@ type doneInfo struct {
 	err error
 }
 
+type workerSnapshot struct {
+	status WorkerStatus
+	worker Worker
+}
+
 // NewRunner creates a new Runner.  When a worker finishes, if its error
 // is deemed fatal (determined by calling isFatal), all the other workers
 // will be stopped and the runner itself will finish.  Of all the fatal errors
@@ -76,6 +124,7 @@ func NewRunner(isFatal func(error) bool, moreImportant func(err0, err1 error) bo
 		stopc:         make(chan string),
 		donec:         make(chan doneInfo),
 		startedc:      make(chan startInfo),
+		statusc:       make(chan chan []workerSnapshot),
 		isFatal:       isFatal,
 		moreImportant: moreImportant,
 	}
@@ -118,6 +167,28 @@ func (runner *runner) StopWorker(id string) error {
 	return ErrDead
 }
 
+// WorkerStatus returns the status of each of the runner's workers,
+// sorted by id. It returns nil if the runner is not running.
+func (runner *runner) WorkerStatus() []WorkerStatus {
+	reply := make(chan []workerSnapshot, 1)
+	select {
+	case runner.statusc <- reply:
+	case <-runner.tomb.Dead():
+		return nil
+	}
+	snapshots := <-reply
+	result := make([]WorkerStatus, len(snapshots))
+	for i, snapshot := range snapshots {
+		result[i] = snapshot.status
+		// Ask the worker about its own workers only now, so that
+		// the runner is not held up waiting for it.
+		if reporter, ok := snapshot.worker.(Reporter); ok {
+			result[i].Workers = reporter.WorkerStatus()
+		}
+	}
+	return result
+}
+
 func (runner *runner) Wait() error {
 	return runner.tomb.Wait()
 }
@@ -138,6 +209,30 @@ type workerInfo struct {
 	worker       Worker
 	restartDelay time.Duration
 	stopping     bool
+
+	// The following fields are reported by WorkerStatus.
+	state         string
+	started       time.Time
+	restarts      int
+	restartAt     time.Time
+	lastErr       error
+	lastErrorTime time.Time
+}
+
+// snapshot returns the status of the worker.
+func (info *workerInfo) snapshot(id string) workerSnapshot {
+	status := WorkerStatus{
+		Id:            id,
+		State:         info.state,
+		Started:       info.started,
+		Restarts:      info.restarts,
+		RestartAt:     info.restartAt,
+		LastErrorTime: info.lastErrorTime,
+	}
+	if info.lastErr != nil {
+		status.LastError = info.lastErr.Error()
+	}
+	return workerSnapshot{status, info.worker}
 }
 
 func (runner *runner) run() error {
@@ -173,6 +268,7 @@ func (runner *runner) run() error {
 				workers[req.id] = &workerInfo{
 					start:        req.start,
 					restartDelay: RestartDelay,
+					state:        WorkerStarting,
 				}
 				go runner.runWorker(0, req.id, req.start)
 				break
@@ -194,6 +290,11 @@ func (runner *runner) run() error {
 		case info := <-runner.startedc:
 			workerInfo := workers[info.id]
 			workerInfo.worker = info.worker
+			if workerInfo.start != nil {
+				workerInfo.state = WorkerRunning
+			}
+			workerInfo.started = time.Now()
+			workerInfo.restartAt = time.Time{}
 			if isDying {
 				killWorker(info.id, workerInfo)
 			}
@@ -203,6 +304,8 @@ func (runner *runner) run() error {
 				info.err = errors.New("unexpected quit")
 			}
 			if info.err != nil {
+				workerInfo.lastErr = info.err
+				workerInfo.lastErrorTime = time.Now()
 				if runner.isFatal(info.err) {
 					logger.Errorf("fatal %q: %v", info.id, info.err)
 					if finalError == nil || runner.moreImportant(info.err, finalError) {
@@ -225,7 +328,23 @@ func (runner *runner) run() error {
 				break
 			}
 			go runner.runWorker(workerInfo.restartDelay, info.id, workerInfo.start)
+			workerInfo.worker = nil
+			workerInfo.started = time.Time{}
+			workerInfo.restarts++
+			if workerInfo.restartDelay > 0 {
+				workerInfo.state = WorkerRestarting
+				workerInfo.restartAt = time.Now().Add(workerInfo.restartDelay)
+			} else {
+				workerInfo.state = WorkerStarting
+			}
 			workerInfo.restartDelay = RestartDelay
+		case reply := <-runner.statusc:
+			snapshots := make([]workerSnapshot, 0, len(workers))
+			for id, info := range workers {
+				snapshots = append(snapshots, info.snapshot(id))
+			}
+			sort.Sort(snapshotsById(snapshots))
+			reply <- snapshots
 		}
 	}
 }
@@ -243,9 +362,16 @@ func killWorker(id string, info *workerInfo) {
 		info.worker = nil
 	}
 	info.stopping = true
+	info.state = WorkerStopping
 	info.start = nil
 }
 
+type snapshotsById []workerSnapshot
+
+func (s snapshotsById) Len() int           { return len(s) }
+func (s snapshotsById) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s snapshotsById) Less(i, j int) bool { return s[i].status.Id < s[j].status.Id }
+
 // runWorker starts the given worker after waiting for the given delay.
 func (runner *runner) runWorker(delay time.Duration, id string, start func() (Worker, error)) {
 	if delay > 0 {
@@ -203,6 +203,77 @@ func (*runnerSuite) TestStopWorkerWhenDead(c *gc.C) {
 	c.Assert(runner.StopWorker("foo"), gc.Equals, worker.ErrDead)
 }
 
+// waitStatus waits until check returns true for the status reported
+// by the given runner, and returns that status.
+func waitStatus(c *gc.C, runner worker.Runner, check func([]worker.WorkerStatus) bool) []worker.WorkerStatus {
+	reporter := runner.(worker.Reporter)
+	for a := testing.LongAttempt.Start(); a.Next(); {
+		status := reporter.WorkerStatus()
+		if check(status) {
+			return status
+		}
+	}
+	c.Fatalf("timed out waiting for worker status")
+	panic("unreachable")
+}
+
+func (*runnerSuite) TestWorkerStatus(c *gc.C) {
+	worker.RestartDelay = time.Hour
+	runner := worker.NewRunner(noneFatal, noImportance)
+	defer worker.Stop(runner)
+	starter := newTestWorkerStarter()
+	err := runner.StartWorker("id", testWorkerStart(starter))
+	c.Assert(err, gc.IsNil)
+	starter.assertStarted(c, true)
+
+	status := waitStatus(c, runner, func(status []worker.WorkerStatus) bool {
+		return len(status) == 1 && status[0].State == worker.WorkerRunning
+	})
+	c.Assert(status[0].Id, gc.Equals, "id")
+	c.Assert(status[0].Started.IsZero(), gc.Equals, false)
+	c.Assert(status[0].Restarts, gc.Equals, 0)
+	c.Assert(status[0].LastError, gc.Equals, "")
+
+	starter.die <- fmt.Errorf("an error")
+	starter.assertStarted(c, false)
+	status = waitStatus(c, runner, func(status []worker.WorkerStatus) bool {
+		return len(status) == 1 && status[0].State == worker.WorkerRestarting
+	})
+	c.Assert(status[0].Started.IsZero(), gc.Equals, true)
+	c.Assert(status[0].Restarts, gc.Equals, 1)
+	c.Assert(status[0].RestartAt.After(time.Now()), gc.Equals, true)
+	c.Assert(status[0].LastError, gc.Equals, "an error")
+	c.Assert(status[0].LastErrorTime.IsZero(), gc.Equals, false)
+}
+
+func (*runnerSuite) TestWorkerStatusNested(c *gc.C) {
+	runner := worker.NewRunner(noneFatal, noImportance)
+	defer worker.Stop(runner)
+	starter := newTestWorkerStarter()
+	err := runner.StartWorker("outer", func() (worker.Worker, error) {
+		inner := worker.NewRunner(noneFatal, noImportance)
+		if err := inner.StartWorker("inner", testWorkerStart(starter)); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	})
+	c.Assert(err, gc.IsNil)
+	starter.assertStarted(c, true)
+
+	status := waitStatus(c, runner, func(status []worker.WorkerStatus) bool {
+		return len(status) == 1 && len(status[0].Workers) == 1 &&
+			status[0].Workers[0].State == worker.WorkerRunning
+	})
+	c.Assert(status[0].Id, gc.Equals, "outer")
+	c.Assert(status[0].Workers[0].Id, gc.Equals, "inner")
+}
+
+func (*runnerSuite) TestWorkerStatusWhenDead(c *gc.C) {
+	runner := worker.NewRunner(allFatal, noImportance)
+	c.Assert(worker.Stop(runner), gc.IsNil)
+	c.Assert(runner.(worker.Reporter).WorkerStatus(), gc.IsNil)
+}
+
 func (*runnerSuite) TestAllWorkersStoppedWhenOneDiesWithFatalError(c *gc.C) {
 	runner := worker.NewRunner(allFatal, noImportance)
 	var starters []*testWorkerStarter
@@ -0,0 +1,196 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.storageprovisioner")
+
+// RetryDelay is the time after which the storage provisioner tries
+// again to provision volumes that could not be provisioned, either
+// because of an error or because their units' machines are not yet
+// provisioned.
+var RetryDelay = 10 * time.Second
+
+type storageProvisioner struct {
+	st       *state.State
+	tomb     tomb.Tomb
+	observer *worker.EnvironObserver
+}
+
+// NewStorageProvisioner returns a worker that creates the volumes
+// required by units, attaches them to the instances of the units'
+// machines, and detaches them when the units are removed.
+func NewStorageProvisioner(st *state.State) worker.Worker {
+	p := &storageProvisioner{st: st}
+	go func() {
+		defer p.tomb.Done()
+		p.tomb.Kill(p.loop())
+	}()
+	return p
+}
+
+func (p *storageProvisioner) Kill() {
+	p.tomb.Kill(nil)
+}
+
+func (p *storageProvisioner) Wait() error {
+	return p.tomb.Wait()
+}
+
+func (p *storageProvisioner) loop() (err error) {
+	p.observer, err = worker.NewEnvironObserver(p.st)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		obsErr := worker.Stop(p.observer)
+		if err == nil {
+			err = obsErr
+		}
+	}()
+	w := p.st.WatchVolumes()
+	defer watcher.Stop(w, &p.tomb)
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-p.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.MustErr(w)
+			}
+		case <-retry:
+		}
+		done, err := p.provisionVolumes()
+		if err != nil {
+			return err
+		}
+		retry = nil
+		if !done {
+			retry = time.After(RetryDelay)
+		}
+	}
+}
+
+// provisionVolumes brings every volume up to date, and reports whether
+// all of them are.
+func (p *storageProvisioner) provisionVolumes() (bool, error) {
+	volumes, err := p.st.AllVolumes()
+	if err != nil {
+		return false, err
+	}
+	allDone := true
+	for _, v := range volumes {
+		done, err := p.provisionVolume(v)
+		if err != nil {
+			logger.Errorf("cannot provision volume %s: %v", v.Id(), err)
+			// Only record changes, so that our own updates
+			// do not cause us to try again immediately.
+			if status, info := v.Status(); status != state.VolumeError || info != err.Error() {
+				if err := v.SetError(err.Error()); err != nil && !errors.IsNotFound(err) {
+					return false, err
+				}
+			}
+		}
+		if err != nil || !done {
+			allDone = false
+		}
+	}
+	return allDone, nil
+}
+
+// provisionVolume creates and attaches the volume if its unit is
+// assigned to a provisioned machine, or detaches it if its unit has
+// been removed. It reports whether the volume is up to date.
+func (p *storageProvisioner) provisionVolume(v *state.Volume) (bool, error) {
+	if v.Released() {
+		if v.InstanceId() == "" {
+			return true, nil
+		}
+		source, err := p.volumeSource()
+		if err != nil {
+			return false, err
+		}
+		if err := source.DetachVolume(v.VolumeId(), v.InstanceId()); err != nil {
+			return false, err
+		}
+		logger.Infof("detached volume %s (%s) from instance %s", v.Id(), v.VolumeId(), v.InstanceId())
+		return true, v.SetDetached()
+	}
+	if v.InstanceId() != "" {
+		return true, nil
+	}
+	unit, err := p.st.Unit(v.Unit())
+	if errors.IsNotFound(err) {
+		// The unit has been removed, and its volumes will
+		// shortly be released.
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	machineId, err := unit.AssignedMachineId()
+	if state.IsNotAssigned(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	machine, err := p.st.Machine(machineId)
+	if err != nil {
+		return false, err
+	}
+	instId, err := machine.InstanceId()
+	if state.IsNotProvisionedError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	source, err := p.volumeSource()
+	if err != nil {
+		return false, err
+	}
+	if v.VolumeId() == "" {
+		volume, err := source.CreateVolume(environs.VolumeParams{
+			Name:       "volume-" + v.Id(),
+			Pool:       v.Pool(),
+			Size:       v.Size(),
+			InstanceId: instId,
+		})
+		if err != nil {
+			return false, err
+		}
+		logger.Infof("created volume %s (%s) in pool %q", v.Id(), volume.Id, v.Pool())
+		if err := v.SetProvisioned(volume.Id, volume.Size); err != nil {
+			return false, err
+		}
+	}
+	device, err := source.AttachVolume(v.VolumeId(), instId)
+	if err != nil {
+		return false, err
+	}
+	logger.Infof("attached volume %s (%s) to instance %s as %s", v.Id(), v.VolumeId(), instId, device)
+	return true, v.SetAttached(instId, device)
+}
+
+// volumeSource returns the current environment as a VolumeSource.
+func (p *storageProvisioner) volumeSource() (environs.VolumeSource, error) {
+	environ := p.observer.Environ()
+	source, ok := environ.(environs.VolumeSource)
+	if !ok {
+		return nil, fmt.Errorf("%q provider does not support storage volumes", environ.Config().Type())
+	}
+	return source, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/storageprovisioner"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type StorageProvisionerSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&StorageProvisionerSuite{})
+
+func (s *StorageProvisionerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(&storageprovisioner.RetryDelay, 10*time.Millisecond)
+}
+
+// addUnit adds a unit of a service requiring a volume in the given
+// pool, assigned to a new machine, and returns the unit's machine and
+// volume.
+func (s *StorageProvisionerSuite) addUnit(c *gc.C, pool string) (*state.Machine, *state.Volume) {
+	service := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	err := service.SetStorageDirectives(map[string]state.StorageDirective{
+		"data": {Pool: pool, Size: 1024},
+	})
+	c.Assert(err, gc.IsNil)
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToNewMachine()
+	c.Assert(err, gc.IsNil)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.Machine(machineId)
+	c.Assert(err, gc.IsNil)
+	volumes, err := unit.Volumes()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.HasLen, 1)
+	return machine, volumes[0]
+}
+
+func (s *StorageProvisionerSuite) waitStatus(c *gc.C, v *state.Volume, status state.VolumeStatus) string {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := v.Refresh()
+		c.Assert(err, gc.IsNil)
+		if got, info := v.Status(); got == status {
+			return info
+		}
+	}
+	got, info := v.Status()
+	c.Fatalf("timed out waiting for volume status %q; got %q (%s)", status, got, info)
+	panic("unreachable")
+}
+
+func (s *StorageProvisionerSuite) TestProvisionVolume(c *gc.C) {
+	machine, volume := s.addUnit(c, "loop")
+	p := storageprovisioner.NewStorageProvisioner(s.State)
+	defer func() { c.Assert(worker.Stop(p), gc.IsNil) }()
+
+	// Nothing is done until the unit's machine is provisioned.
+	time.Sleep(coretesting.ShortWait)
+	err := volume.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.VolumeId(), gc.Equals, "")
+
+	inst, _ := testing.AssertStartInstance(c, s.Conn.Environ, machine.Id())
+	err = machine.SetProvisioned(inst.Id(), "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	s.waitStatus(c, volume, state.VolumeAttached)
+	c.Assert(volume.VolumeId(), gc.Not(gc.Equals), "")
+	c.Assert(volume.InstanceId(), gc.Equals, inst.Id())
+	c.Assert(volume.Device(), gc.Matches, "/dev/loop[0-9]+")
+	attached, err := dummy.VolumeAttachment(s.Conn.Environ, volume.VolumeId())
+	c.Assert(err, gc.IsNil)
+	c.Assert(attached, gc.Equals, inst.Id())
+
+	// When the unit is removed, the volume is detached but kept.
+	unit, err := s.State.Unit(volume.Unit())
+	c.Assert(err, gc.IsNil)
+	err = unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	s.waitStatus(c, volume, state.VolumeDetached)
+	attached, err = dummy.VolumeAttachment(s.Conn.Environ, volume.VolumeId())
+	c.Assert(err, gc.IsNil)
+	c.Assert(attached, gc.Equals, instance.Id(""))
+}
+
+func (s *StorageProvisionerSuite) TestUnsupportedPool(c *gc.C) {
+	machine, volume := s.addUnit(c, "ebs")
+	inst, _ := testing.AssertStartInstance(c, s.Conn.Environ, machine.Id())
+	err := machine.SetProvisioned(inst.Id(), "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+
+	p := storageprovisioner.NewStorageProvisioner(s.State)
+	defer func() { c.Assert(worker.Stop(p), gc.IsNil) }()
+	info := s.waitStatus(c, volume, state.VolumeError)
+	c.Assert(info, gc.Equals, `storage pool "ebs" not supported`)
+}
@@ -5,6 +5,7 @@ package charm
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 
@@ -13,17 +14,22 @@ import (
 	"github.com/juju/utils"
 
 	"github.com/juju/juju/downloader"
+	"github.com/juju/juju/state/api"
 )
 
 // BundlesDir is responsible for storing and retrieving charm bundles
 // identified by state charms.
 type BundlesDir struct {
-	path string
+	path    string
+	apiInfo *api.Info
 }
 
 // NewBundlesDir returns a new BundlesDir which uses path for storage.
-func NewBundlesDir(path string) *BundlesDir {
-	return &BundlesDir{path}
+// If apiInfo is not nil and holds an address, charms are downloaded
+// through the API server when possible, rather than directly from their
+// archive URLs.
+func NewBundlesDir(path string, apiInfo *api.Info) *BundlesDir {
+	return &BundlesDir{path, apiInfo}
 }
 
 // Read returns a charm bundle from the directory. If no bundle exists yet,
@@ -42,30 +48,74 @@ func (d *BundlesDir) Read(info BundleInfo, abort <-chan struct{}) (Bundle, error
 }
 
 // download fetches the supplied charm and checks that it has the correct sha256
-// hash, then copies it into the directory. If a value is received on abort, the
-// download will be stopped.
+// hash, then copies it into the directory. The charm is fetched through the
+// API server if possible, and from its archive URL otherwise. If a value is
+// received on abort, the download will be stopped.
 func (d *BundlesDir) download(info BundleInfo, abort <-chan struct{}) (err error) {
 	archiveURL, disableSSLHostnameVerification, err := info.ArchiveURL()
 	if err != nil {
 		return err
 	}
-	defer errors.Maskf(&err, "failed to download charm %q from %q", info.URL(), archiveURL)
 	dir := d.downloadsPath()
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
+	if d.apiInfo != nil && len(d.apiInfo.Addrs) > 0 {
+		aurl := api.CharmArchiveURL(d.apiInfo.Addrs[0], info.URL())
+		err = d.fetchFromAPIServer(info, aurl, dir, abort)
+		if err == nil {
+			return nil
+		} else if err == errAborted {
+			errors.Maskf(&err, "failed to download charm %q from %q", info.URL(), aurl)
+			return err
+		}
+		logger.Warningf("cannot download %s through the API server: %v", info.URL(), err)
+	}
+	defer errors.Maskf(&err, "failed to download charm %q from %q", info.URL(), archiveURL)
 	aurl := archiveURL.String()
 	logger.Infof("downloading %s from %s", info.URL(), aurl)
 	if disableSSLHostnameVerification {
 		logger.Infof("SSL hostname verification disabled")
 	}
-	dl := downloader.New(aurl, dir, disableSSLHostnameVerification)
+	return d.fetch(info, aurl, dir, disableSSLHostnameVerification, nil, nil, abort)
+}
+
+// fetchFromAPIServer downloads the supplied charm from aurl on the API
+// server. The server's certificate is verified before the agent's
+// credentials are sent to it.
+func (d *BundlesDir) fetchFromAPIServer(info BundleInfo, aurl, dir string, abort <-chan struct{}) error {
+	client, err := api.NewHTTPClient(d.apiInfo)
+	if err != nil {
+		return err
+	}
+	header := api.AuthHeader(d.apiInfo.Tag, d.apiInfo.Password)
+	logger.Infof("downloading %s from %s", info.URL(), aurl)
+	return d.fetch(info, aurl, dir, utils.VerifySSLHostnames, client, header, abort)
+}
+
+var errAborted = fmt.Errorf("aborted")
+
+// fetch downloads the supplied charm from aurl into dir, and checks that it
+// has the correct sha256 hash before copying it into the directory. If client
+// is not nil it is used to make the requests, which carry the given headers.
+func (d *BundlesDir) fetch(
+	info BundleInfo,
+	aurl, dir string,
+	hostnameVerification utils.SSLHostnameVerification,
+	client *http.Client,
+	header http.Header,
+	abort <-chan struct{},
+) error {
+	dl := downloader.NewWithOptions(aurl, dir, hostnameVerification, downloader.Options{
+		Client: client,
+		Header: header,
+	})
 	defer dl.Stop()
 	for {
 		select {
 		case <-abort:
 			logger.Infof("download aborted")
-			return fmt.Errorf("aborted")
+			return errAborted
 		case st := <-dl.Done():
 			if st.Err != nil {
 				return st.Err
@@ -32,8 +32,10 @@ type BundlesDirSuite struct {
 	gitjujutesting.HTTPSuite
 	testing.JujuConnSuite
 
-	st     *api.State
-	uniter *uniter.State
+	st       *api.State
+	uniter   *uniter.State
+	unitTag  string
+	password string
 }
 
 var _ = gc.Suite(&BundlesDirSuite{})
@@ -62,7 +64,9 @@ func (s *BundlesDirSuite) SetUpTest(c *gc.C) {
 	err = unit.SetPassword(password)
 	c.Assert(err, gc.IsNil)
 
-	s.st = s.OpenAPIAs(c, unit.Tag().String(), password)
+	s.unitTag = unit.Tag().String()
+	s.password = password
+	s.st = s.OpenAPIAs(c, s.unitTag, password)
 	c.Assert(s.st, gc.NotNil)
 	s.uniter = s.st.Uniter()
 	c.Assert(s.uniter, gc.NotNil)
@@ -93,7 +97,7 @@ func (s *BundlesDirSuite) AddCharm(c *gc.C) (*uniter.Charm, *state.Charm, []byte
 func (s *BundlesDirSuite) TestGet(c *gc.C) {
 	basedir := c.MkDir()
 	bunsdir := filepath.Join(basedir, "random", "bundles")
-	d := charm.NewBundlesDir(bunsdir)
+	d := charm.NewBundlesDir(bunsdir, nil)
 
 	// Check it doesn't get created until it's needed.
 	_, err := os.Stat(bunsdir)
@@ -144,6 +148,40 @@ func (s *BundlesDirSuite) TestGet(c *gc.C) {
 	}
 }
 
+func (s *BundlesDirSuite) TestGetThroughAPIServer(c *gc.C) {
+	apiInfo := s.APIInfo(c)
+	apiInfo.Tag = s.unitTag
+	apiInfo.Password = s.password
+	d := charm.NewBundlesDir(filepath.Join(c.MkDir(), "bundles"), apiInfo)
+	apiCharm, sch, bundata := s.AddCharm(c)
+
+	// Put the charm in the API server's cache; the archive URL
+	// is not used, as no response is prepared for it.
+	cacheDir := filepath.Join(s.DataDir(), "charm-get-cache")
+	err := os.MkdirAll(cacheDir, 0755)
+	c.Assert(err, gc.IsNil)
+	cachePath := filepath.Join(cacheDir, corecharm.Quote(sch.URL().String())+".zip")
+	err = ioutil.WriteFile(cachePath, bundata, 0644)
+	c.Assert(err, gc.IsNil)
+
+	ch, err := d.Read(apiCharm, nil)
+	c.Assert(err, gc.IsNil)
+	assertCharm(c, ch, sch)
+}
+
+func (s *BundlesDirSuite) TestGetFallsBackToArchiveURL(c *gc.C) {
+	apiInfo := s.APIInfo(c)
+	apiInfo.Tag = s.unitTag
+	apiInfo.Password = "wrong password"
+	d := charm.NewBundlesDir(filepath.Join(c.MkDir(), "bundles"), apiInfo)
+	apiCharm, sch, bundata := s.AddCharm(c)
+
+	gitjujutesting.Server.Response(200, nil, bundata)
+	ch, err := d.Read(apiCharm, nil)
+	c.Assert(err, gc.IsNil)
+	assertCharm(c, ch, sch)
+}
+
 func readHash(c *gc.C, path string) ([]byte, string) {
 	data, err := ioutil.ReadFile(path)
 	c.Assert(err, gc.IsNil)
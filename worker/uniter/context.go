@@ -108,7 +108,13 @@ func NewHookContext(unit *uniter.Unit, id, uuid, envName string,
 	if err != nil && !params.IsCodeNoAddressSet(err) {
 		return nil, err
 	}
-	ctx.privateAddress, err = unit.PrivateAddress()
+	if relation, found := relations[relationId]; found {
+		// The hook's relation may be bound to a network, in which
+		// case the unit's address on that network is used.
+		ctx.privateAddress, err = relation.ru.PrivateAddress()
+	} else {
+		ctx.privateAddress, err = unit.PrivateAddress()
+	}
 	if err != nil && !params.IsCodeNoAddressSet(err) {
 		return nil, err
 	}
@@ -139,6 +145,10 @@ func (ctx *HookContext) OwnerTag() string {
 	return ctx.serviceOwner
 }
 
+func (ctx *HookContext) StorageVolumes() ([]params.UnitVolume, error) {
+	return ctx.unit.Volumes()
+}
+
 func (ctx *HookContext) ConfigSettings() (charm.Settings, error) {
 	if ctx.configSettings == nil {
 		var err error
@@ -426,6 +436,10 @@ func (ctx *ContextRelation) Settings() (jujuc.Settings, error) {
 	return ctx.settings, nil
 }
 
+func (ctx *ContextRelation) Network() (params.RelationNetwork, error) {
+	return ctx.ru.Network()
+}
+
 func (ctx *ContextRelation) ReadSettings(unit string) (settings params.RelationSettings, err error) {
 	settings, member := ctx.members[unit]
 	if settings == nil {
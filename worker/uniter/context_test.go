@@ -742,7 +742,7 @@ func convertSettings(settings params.RelationSettings) map[string]interface{} {
 func convertMap(settingsMap map[string]interface{}) params.RelationSettings {
 	result := make(params.RelationSettings)
 	for k, v := range settingsMap {
-		result[k] = v.(string)
+		result[k] = v
 	}
 	return result
 }
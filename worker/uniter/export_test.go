@@ -7,6 +7,8 @@ import (
 	"github.com/juju/utils/proxy"
 )
 
+var HookLockReportDelay = &hookLockReportDelay
+
 func SetUniterObserver(u *Uniter, observer UniterExecutionObserver) {
 	u.observer = observer
 }
@@ -16,3 +18,8 @@ func (u *Uniter) GetProxyValues() proxy.Settings {
 	defer u.proxyMutex.Unlock()
 	return u.proxy
 }
+
+var (
+	NewScheduler = newScheduler
+	SchedulerNow = &schedulerNow
+)
@@ -284,6 +284,68 @@ func (s *FilterSuite) TestCharmUpgradeEvents(c *gc.C) {
 	assertNoChange()
 }
 
+func (s *FilterSuite) TestRollingCharmUpgradeEvents(c *gc.C) {
+	oldCharm := s.AddTestingCharm(c, "upgrade1")
+	svc := s.AddTestingService(c, "upgradetest", oldCharm)
+	// Units are released in name order, so the other unit goes first.
+	other, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = other.SetCharmURL(oldCharm.URL())
+	c.Assert(err, gc.IsNil)
+
+	s.APILogin(c, unit)
+
+	f, err := newFilter(s.uniter, unit.Tag().String())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, f)
+	err = f.SetCharm(oldCharm.URL())
+	c.Assert(err, gc.IsNil)
+
+	assertNoChange := func() {
+		s.BackingState.StartSync()
+		select {
+		case sch := <-f.UpgradeEvents():
+			c.Fatalf("unexpected %#v", sch)
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+	assertNoChange()
+
+	// A rolling upgrade generates no event until the unit is released.
+	newCharm := s.AddTestingCharm(c, "upgrade2")
+	err = svc.SetCharmRolling(newCharm, false, 1)
+	c.Assert(err, gc.IsNil)
+	assertNoChange()
+
+	// The other unit is released first, which is still not relevant.
+	err = svc.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	upgrade, _ := svc.CharmUpgrade()
+	c.Assert(upgrade.Released, gc.DeepEquals, []string{other.Name()})
+	assertNoChange()
+
+	// Once the other unit has settled, our unit is released.
+	pinger, err := other.SetAgentPresence()
+	c.Assert(err, gc.IsNil)
+	defer pinger.Stop()
+	err = other.SetCharmURL(newCharm.URL())
+	c.Assert(err, gc.IsNil)
+	err = other.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	err = svc.AdvanceCharmUpgrade()
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	select {
+	case upgradeCharm := <-f.UpgradeEvents():
+		c.Assert(upgradeCharm, gc.DeepEquals, newCharm.URL())
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out")
+	}
+}
+
 func (s *FilterSuite) TestConfigEvents(c *gc.C) {
 	f, err := newFilter(s.uniter, s.unit.Tag().String())
 	c.Assert(err, gc.IsNil)
@@ -56,6 +56,10 @@ type Context interface {
 
 	// OwnerTag returns the owner of the service the executing units belongs to
 	OwnerTag() string
+
+	// StorageVolumes returns the block storage volumes of the executing
+	// unit.
+	StorageVolumes() ([]params.UnitVolume, error)
 }
 
 // ContextRelation expresses the capabilities of a hook with respect to a relation.
@@ -82,12 +86,16 @@ type ContextRelation interface {
 
 	// ReadSettings returns the settings of any remote unit in the relation.
 	ReadSettings(unit string) (params.RelationSettings, error)
+
+	// Network returns details of the network the local unit's endpoint
+	// in the relation is bound to.
+	Network() (params.RelationNetwork, error)
 }
 
 // Settings is implemented by types that manipulate unit settings.
 type Settings interface {
 	Map() params.RelationSettings
-	Set(string, string)
+	Set(string, interface{})
 	Delete(string)
 }
 
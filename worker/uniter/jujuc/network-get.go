@@ -0,0 +1,100 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/state/api/params"
+)
+
+const networkGetDoc = `
+network-get prints details of the network the unit's endpoint in a relation
+is bound to, as chosen when the service was deployed. If a key is given, only
+that detail is printed; otherwise all details are printed. Valid keys are
+"network", "provider-id", "cidr", "vlan-tag", "interface" and "address".
+If the relation's endpoint is not bound to a network, an error is returned.
+`
+
+// networkGetKeys holds the keys printed by network-get, in order.
+var networkGetKeys = []string{
+	"network", "provider-id", "cidr", "vlan-tag", "interface", "address",
+}
+
+// NetworkGetCommand implements the network-get command.
+type NetworkGetCommand struct {
+	cmd.CommandBase
+	ctx        Context
+	RelationId int
+	Key        string
+	out        cmd.Output
+}
+
+func NewNetworkGetCommand(ctx Context) cmd.Command {
+	return &NetworkGetCommand{ctx: ctx}
+}
+
+func (c *NetworkGetCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "network-get",
+		Args:    "[<key>]",
+		Purpose: "get the network a relation is bound to",
+		Doc:     networkGetDoc,
+	}
+}
+
+func (c *NetworkGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.Var(newRelationIdValue(c.ctx, &c.RelationId), "r", "specify a relation by id")
+}
+
+func (c *NetworkGetCommand) Init(args []string) error {
+	if c.RelationId == -1 {
+		return fmt.Errorf("no relation id specified")
+	}
+	c.Key = ""
+	if len(args) > 0 {
+		c.Key = args[0]
+		valid := false
+		for _, key := range networkGetKeys {
+			if c.Key == key {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown key %q", c.Key)
+		}
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *NetworkGetCommand) Run(ctx *cmd.Context) error {
+	r, found := c.ctx.Relation(c.RelationId)
+	if !found {
+		return fmt.Errorf("unknown relation id")
+	}
+	network, err := r.Network()
+	if params.IsCodeNotFound(err) {
+		return fmt.Errorf("relation %s is not bound to a network", r.FakeId())
+	} else if err != nil {
+		return err
+	}
+	details := map[string]interface{}{
+		"network":     network.NetworkName,
+		"provider-id": network.ProviderId,
+		"cidr":        network.CIDR,
+		"vlan-tag":    network.VLANTag,
+		"interface":   network.InterfaceName,
+		"address":     network.Address,
+	}
+	if c.Key != "" {
+		return c.out.Write(ctx, details[c.Key])
+	}
+	return c.out.Write(ctx, details)
+}
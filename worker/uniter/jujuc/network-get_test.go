@@ -0,0 +1,122 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type NetworkGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&NetworkGetSuite{})
+
+func (s *NetworkGetSuite) SetUpTest(c *gc.C) {
+	s.ContextSuite.SetUpTest(c)
+	s.rels[1].network = &params.RelationNetwork{
+		NetworkName:   "dbnet",
+		ProviderId:    "provider-dbnet",
+		CIDR:          "10.1.0.0/16",
+		VLANTag:       42,
+		InterfaceName: "eth1.42",
+		Address:       "10.1.0.5",
+	}
+}
+
+var networkGetTests = []struct {
+	summary string
+	relid   int
+	args    []string
+	code    int
+	out     string
+}{{
+	summary: "no default relation",
+	relid:   -1,
+	code:    2,
+	out:     "no relation id specified",
+}, {
+	summary: "unknown key",
+	relid:   1,
+	args:    []string{"bad-key"},
+	code:    2,
+	out:     `unknown key "bad-key"`,
+}, {
+	summary: "too many args",
+	relid:   1,
+	args:    []string{"cidr", "address"},
+	code:    2,
+	out:     `unrecognized args: \["address"\]`,
+}, {
+	summary: "unbound relation",
+	relid:   0,
+	code:    1,
+	out:     "relation peer0:0 is not bound to a network",
+}, {
+	summary: "single key",
+	relid:   1,
+	args:    []string{"address"},
+	out:     "10.1.0.5",
+}, {
+	summary: "explicit relation",
+	relid:   0,
+	args:    []string{"-r", "peer1:1", "interface"},
+	out:     "eth1.42",
+}, {
+	summary: "all details",
+	relid:   1,
+	args:    []string{"--format", "json"},
+	out:     `{"address":"10.1.0.5","cidr":"10.1.0.0/16","interface":"eth1.42","network":"dbnet","provider-id":"provider-dbnet","vlan-tag":42}`,
+}}
+
+func (s *NetworkGetSuite) TestNetworkGet(c *gc.C) {
+	for i, t := range networkGetTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, t.relid, "")
+		com, err := jujuc.NewCommand(hctx, "network-get")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		if code == 0 {
+			c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+			c.Check(bufferString(ctx.Stdout), gc.Equals, t.out+"\n")
+		} else {
+			c.Check(bufferString(ctx.Stdout), gc.Equals, "")
+			c.Check(bufferString(ctx.Stderr), gc.Matches, "error: "+t.out+"\n")
+		}
+	}
+}
+
+func (s *NetworkGetSuite) TestHelp(c *gc.C) {
+	hctx := s.GetHookContext(c, 1, "")
+	com, err := jujuc.NewCommand(hctx, "network-get")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"--help"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, `usage: network-get [options] [<key>]
+purpose: get the network a relation is bound to
+
+options:
+--format  (= smart)
+    specify output format (json|smart|yaml)
+-o, --output (= "")
+    specify an output file
+-r  (= peer1:1)
+    specify a relation by id
+
+network-get prints details of the network the unit's endpoint in a relation
+is bound to, as chosen when the service was deployed. If a key is given, only
+that detail is printed; otherwise all details are printed. Valid keys are
+"network", "provider-id", "cidr", "vlan-tag", "interface" and "address".
+If the relation's endpoint is not bound to a network, an error is returned.
+`)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+}
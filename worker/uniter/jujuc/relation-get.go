@@ -31,6 +31,8 @@ func (c *RelationGetCommand) Info() *cmd.Info {
 	doc := `
 relation-get prints the value of a unit's relation setting, specified by key.
 If no key is given, or if the key is "-", all keys and values will be printed.
+Structured values, as set by relation-set --format or --file, are printed
+in the requested format, so they can be decoded by the hook.
 `
 	if name, found := c.ctx.RemoteUnitName(); found {
 		args = "[<key> [<unit id>]]"
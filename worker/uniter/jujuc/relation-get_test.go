@@ -26,6 +26,9 @@ func (s *RelationGetSuite) SetUpTest(c *gc.C) {
 	s.rels[0].units["u/0"]["private-address"] = "foo: bar\n"
 	s.rels[1].units["m/0"] = Settings{"pew": "pew\npew\n"}
 	s.rels[1].units["u/1"] = Settings{"value": "12345"}
+	s.rels[1].units["u/2"] = Settings{
+		"tls": map[string]interface{}{"port": 443, "hosts": []interface{}{"a", "b"}},
+	}
 }
 
 var relationGetTests = []struct {
@@ -177,6 +180,21 @@ var relationGetTests = []struct {
 		relid:   1,
 		args:    []string{"missing", "u/1", "--format", "yaml"},
 		out:     ``,
+	}, {
+		summary: "json formatting of structured values",
+		relid:   1,
+		args:    []string{"-", "u/2", "--format", "json"},
+		out:     `{"tls":{"hosts":["a","b"],"port":443}}`,
+	}, {
+		summary: "json formatting of structured value",
+		relid:   1,
+		args:    []string{"tls", "u/2", "--format", "json"},
+		out:     `{"hosts":["a","b"],"port":443}`,
+	}, {
+		summary: "yaml formatting of structured value",
+		relid:   1,
+		args:    []string{"tls", "u/2", "--format", "yaml"},
+		out:     "hosts:\n- a\n- b\nport: 443",
 	},
 }
 
@@ -218,6 +236,8 @@ options:
 
 relation-get prints the value of a unit's relation setting, specified by key.
 If no key is given, or if the key is "-", all keys and values will be printed.
+Structured values, as set by relation-set --format or --file, are printed
+in the requested format, so they can be decoded by the hook.
 %s`[1:]
 
 var relationGetHelpTests = []struct {
@@ -4,25 +4,48 @@
 package jujuc
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	goyaml "gopkg.in/yaml.v1"
 	"launchpad.net/gnuflag"
 )
 
+const relationSetDoc = `
+relation-set writes the local unit's settings for some relation. If no
+relation is specified then the current relation is used. Settings given
+as key=value arguments override those read from a file, and setting a
+key to an empty value deletes it.
+
+By default values are plain strings. If --format is yaml or json, values
+given on the command line are decoded in that format, so structured
+values (maps, lists, numbers and booleans) can be set.
+
+If --file is given, settings are read from the named file ("-" for
+stdin), which must contain a single map of keys to values, encoded as
+--format specifies (yaml by default).
+
+The total size of a unit's settings in a relation, encoded as JSON,
+may not exceed 64KiB.
+`
+
 // RelationSetCommand implements the relation-set command.
 type RelationSetCommand struct {
 	cmd.CommandBase
-	ctx        Context
-	RelationId int
-	Settings   map[string]string
-	formatFlag string // deprecated
+	ctx          Context
+	RelationId   int
+	Settings     map[string]interface{}
+	settingsFile string
+	format       string
+	badFormat    string
 }
 
 func NewRelationSetCommand(ctx Context) cmd.Command {
-	return &RelationSetCommand{ctx: ctx, Settings: map[string]string{}}
+	return &RelationSetCommand{ctx: ctx, Settings: map[string]interface{}{}}
 }
 
 func (c *RelationSetCommand) Info() *cmd.Info {
@@ -30,46 +53,185 @@ func (c *RelationSetCommand) Info() *cmd.Info {
 		Name:    "relation-set",
 		Args:    "key=value [key=value ...]",
 		Purpose: "set relation settings",
+		Doc:     relationSetDoc,
 	}
 }
 
 func (c *RelationSetCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(newRelationIdValue(c.ctx, &c.RelationId), "r", "specify a relation by id")
-	f.StringVar(&c.formatFlag, "format", "", "deprecated format flag")
+	f.StringVar(&c.settingsFile, "file", "", "file containing settings to set")
+	f.StringVar(&c.format, "format", "", "format of values to set (json|yaml)")
 }
 
 func (c *RelationSetCommand) Init(args []string) error {
 	if c.RelationId == -1 {
 		return fmt.Errorf("no relation id specified")
 	}
+	switch c.format {
+	case "", "yaml", "json":
+	default:
+		// The --format flag used to be accepted and ignored, so
+		// unknown formats are ignored with a warning rather than
+		// breaking existing charms.
+		c.badFormat, c.format = c.format, ""
+	}
 	for _, kv := range args {
 		parts := strings.SplitN(kv, "=", 2)
 		if len(parts) != 2 || len(parts[0]) == 0 {
 			return fmt.Errorf(`expected "key=value", got %q`, kv)
 		}
-		c.Settings[parts[0]] = parts[1]
+		value, err := c.decodeValue(parts[1])
+		if err != nil {
+			return fmt.Errorf("cannot parse value for %q: %v", parts[0], err)
+		}
+		c.Settings[parts[0]] = value
 	}
 	return nil
 }
 
+// decodeValue returns the setting value represented by s, according
+// to the requested format. The empty string always signifies deletion.
+func (c *RelationSetCommand) decodeValue(s string) (interface{}, error) {
+	if c.format == "" || s == "" {
+		return s, nil
+	}
+	var value interface{}
+	if err := c.unmarshal([]byte(s), &value); err != nil {
+		return nil, err
+	}
+	return normalizeSettingValue(value)
+}
+
+func (c *RelationSetCommand) unmarshal(data []byte, v interface{}) error {
+	if c.format == "json" {
+		return json.Unmarshal(data, v)
+	}
+	return goyaml.Unmarshal(data, v)
+}
+
+// readSettingsFile returns the settings held in the file named by
+// c.settingsFile.
+func (c *RelationSetCommand) readSettingsFile(ctx *cmd.Context) (map[string]interface{}, error) {
+	var data []byte
+	var err error
+	if c.settingsFile == "-" {
+		data, err = ioutil.ReadAll(ctx.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(ctx.AbsPath(c.settingsFile))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := c.unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	settings := make(map[string]interface{})
+	switch value := value.(type) {
+	case nil:
+	case map[interface{}]interface{}:
+		for k, v := range value {
+			settings[fmt.Sprint(k)] = v
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			settings[k] = v
+		}
+	default:
+		return nil, fmt.Errorf("expected a map of settings, got %T", value)
+	}
+	for k, v := range settings {
+		nv, err := normalizeSettingValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("bad value for %q: %v", k, err)
+		}
+		settings[k] = nv
+	}
+	return settings, nil
+}
+
 func (c *RelationSetCommand) Run(ctx *cmd.Context) (err error) {
-	if c.formatFlag != "" {
-		fmt.Fprintf(ctx.Stderr, "--format flag deprecated for command %q", c.Info().Name)
+	if c.badFormat != "" {
+		fmt.Fprintf(ctx.Stderr, "ignoring unknown format %q for command %q", c.badFormat, c.Info().Name)
 	}
 	r, found := c.ctx.Relation(c.RelationId)
 	if !found {
 		return fmt.Errorf("unknown relation id")
 	}
+	changes := map[string]interface{}{}
+	if c.settingsFile != "" {
+		fileSettings, err := c.readSettingsFile(ctx)
+		if err != nil {
+			return errors.Annotatef(err, "cannot read settings from %q", c.settingsFile)
+		}
+		for k, v := range fileSettings {
+			changes[k] = v
+		}
+	}
+	for k, v := range c.Settings {
+		changes[k] = v
+	}
 	settings, err := r.Settings()
 	if err != nil {
 		return errors.Annotate(err, "cannot read relation settings")
 	}
-	for k, v := range c.Settings {
-		if v != "" {
-			settings.Set(k, v)
+	// Check the size of the result before changing anything.
+	result := settings.Map()
+	for k, v := range changes {
+		if v == nil || v == "" {
+			delete(result, k)
 		} else {
+			result[k] = v
+		}
+	}
+	if err := result.CheckSize(); err != nil {
+		return err
+	}
+	for k, v := range changes {
+		if v == nil || v == "" {
 			settings.Delete(k)
+		} else {
+			settings.Set(k, v)
 		}
 	}
 	return nil
 }
+
+// normalizeSettingValue converts maps decoded from YAML, which have
+// interface{} keys, to maps with string keys, so that the value can be
+// encoded as JSON and stored in state. Keys of maps within a value may
+// not contain "." or "$", because they cannot be stored.
+func normalizeSettingValue(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for k, v := range value {
+			m[fmt.Sprint(k)] = v
+		}
+		return normalizeSettingValue(m)
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for k, v := range value {
+			if strings.ContainsAny(k, ".$") {
+				return nil, fmt.Errorf(`invalid key %q: keys within values may not contain "." or "$"`, k)
+			}
+			nv, err := normalizeSettingValue(v)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = nv
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, v := range value {
+			nv, err := normalizeSettingValue(v)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = nv
+		}
+		return result, nil
+	}
+	return value, nil
+}
@@ -4,11 +4,16 @@
 package jujuc_test
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 
 	"github.com/juju/cmd"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/testing"
 	"github.com/juju/juju/worker/uniter/jujuc"
 )
@@ -38,10 +43,28 @@ usage: relation-set [options] key=value [key=value ...]
 purpose: set relation settings
 
 options:
+--file (= "")
+    file containing settings to set
 --format (= "")
-    deprecated format flag
+    format of values to set (json|yaml)
 -r  (= %s)
     specify a relation by id
+
+relation-set writes the local unit's settings for some relation. If no
+relation is specified then the current relation is used. Settings given
+as key=value arguments override those read from a file, and setting a
+key to an empty value deletes it.
+
+By default values are plain strings. If --format is yaml or json, values
+given on the command line are decoded in that format, so structured
+values (maps, lists, numbers and booleans) can be set.
+
+If --file is given, settings are read from the named file ("-" for
+stdin), which must contain a single map of keys to values, encoded as
+--format specifies (yaml by default).
+
+The total size of a unit's settings in a relation, encoded as JSON,
+may not exceed 64KiB.
 `[1:], t.expect))
 		c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
 	}
@@ -52,7 +75,7 @@ var relationSetInitTests = []struct {
 	args     []string
 	err      string
 	relid    int
-	settings map[string]string
+	settings map[string]interface{}
 }{
 	{
 	// compatibility: 0 args is valid.
@@ -114,37 +137,60 @@ var relationSetInitTests = []struct {
 		ctxrelid: 1,
 		args:     []string{"foo="},
 		relid:    1,
-		settings: map[string]string{"foo": ""},
+		settings: map[string]interface{}{"foo": ""},
 	}, {
 		ctxrelid: 1,
 		args:     []string{"foo='"},
 		relid:    1,
-		settings: map[string]string{"foo": "'"},
+		settings: map[string]interface{}{"foo": "'"},
 	}, {
 		ctxrelid: 1,
 		args:     []string{"foo=bar"},
 		relid:    1,
-		settings: map[string]string{"foo": "bar"},
+		settings: map[string]interface{}{"foo": "bar"},
 	}, {
 		ctxrelid: 1,
 		args:     []string{"foo=bar=baz=qux"},
 		relid:    1,
-		settings: map[string]string{"foo": "bar=baz=qux"},
+		settings: map[string]interface{}{"foo": "bar=baz=qux"},
 	}, {
 		ctxrelid: 1,
 		args:     []string{"foo=foo: bar"},
 		relid:    1,
-		settings: map[string]string{"foo": "foo: bar"},
+		settings: map[string]interface{}{"foo": "foo: bar"},
 	}, {
 		ctxrelid: 0,
 		args:     []string{"-r", "1", "foo=bar"},
 		relid:    1,
-		settings: map[string]string{"foo": "bar"},
+		settings: map[string]interface{}{"foo": "bar"},
 	}, {
 		ctxrelid: 1,
 		args:     []string{"foo=123", "bar=true", "baz=4.5", "qux="},
 		relid:    1,
-		settings: map[string]string{"foo": "123", "bar": "true", "baz": "4.5", "qux": ""},
+		settings: map[string]interface{}{"foo": "123", "bar": "true", "baz": "4.5", "qux": ""},
+	}, {
+		ctxrelid: 1,
+		args:     []string{"--format", "yaml", "foo=123", "bar=true", "baz=[a, b]", "qux="},
+		relid:    1,
+		settings: map[string]interface{}{"foo": 123, "bar": true, "baz": []interface{}{"a", "b"}, "qux": ""},
+	}, {
+		ctxrelid: 1,
+		args:     []string{"--format", "yaml", "foo={a: {b: c}}"},
+		relid:    1,
+		settings: map[string]interface{}{"foo": map[string]interface{}{"a": map[string]interface{}{"b": "c"}}},
+	}, {
+		ctxrelid: 1,
+		args:     []string{"--format", "json", `foo={"a": [1, "b"]}`, "bar=false"},
+		relid:    1,
+		settings: map[string]interface{}{"foo": map[string]interface{}{"a": []interface{}{float64(1), "b"}}, "bar": false},
+	}, {
+		ctxrelid: 1,
+		args:     []string{"--format", "json", "foo=bar"},
+		err:      `cannot parse value for "foo": invalid character 'b' looking for beginning of value`,
+	}, {
+		ctxrelid: 1,
+		args:     []string{"--format", "yaml", "foo={a.b: c}"},
+		err:      `cannot parse value for "foo": invalid key "a.b": keys within values may not contain "." or "\$"`,
 	},
 }
 
@@ -161,7 +207,7 @@ func (s *RelationSetSuite) TestInit(c *gc.C) {
 			c.Assert(rset.RelationId, gc.Equals, t.relid)
 			settings := t.settings
 			if settings == nil {
-				settings = map[string]string{}
+				settings = map[string]interface{}{}
 			}
 			c.Assert(rset.Settings, gc.DeepEquals, settings)
 		} else {
@@ -172,18 +218,24 @@ func (s *RelationSetSuite) TestInit(c *gc.C) {
 
 // Tests start with a relation with the settings {"base": "value"}
 var relationSetRunTests = []struct {
-	change map[string]string
+	change map[string]interface{}
 	expect Settings
 }{
 	{
-		map[string]string{"base": ""},
+		map[string]interface{}{"base": ""},
+		Settings{},
+	}, {
+		map[string]interface{}{"base": nil},
 		Settings{},
 	}, {
-		map[string]string{"foo": "bar"},
+		map[string]interface{}{"foo": "bar"},
 		Settings{"base": "value", "foo": "bar"},
 	}, {
-		map[string]string{"base": "changed"},
+		map[string]interface{}{"base": "changed"},
 		Settings{"base": "changed"},
+	}, {
+		map[string]interface{}{"foo": []interface{}{"a", 1}},
+		Settings{"base": "value", "foo": []interface{}{"a", 1}},
 	},
 }
 
@@ -213,7 +265,7 @@ func (s *RelationSetSuite) TestRun(c *gc.C) {
 	}
 }
 
-func (s *RelationSetSuite) TestRunDeprecationWarning(c *gc.C) {
+func (s *RelationSetSuite) TestRunUnknownFormatWarning(c *gc.C) {
 	hctx := s.GetHookContext(c, 0, "")
 	com, _ := jujuc.NewCommand(hctx, "relation-set")
 	// The rel= is needed to make this a valid command.
@@ -221,5 +273,94 @@ func (s *RelationSetSuite) TestRunDeprecationWarning(c *gc.C) {
 
 	c.Assert(err, gc.IsNil)
 	c.Assert(testing.Stdout(ctx), gc.Equals, "")
-	c.Assert(testing.Stderr(ctx), gc.Equals, "--format flag deprecated for command \"relation-set\"")
+	c.Assert(testing.Stderr(ctx), gc.Equals, `ignoring unknown format "foo" for command "relation-set"`)
+}
+
+var relationSetFileTests = []struct {
+	about   string
+	args    []string
+	content string
+	expect  Settings
+	err     string
+}{{
+	about:   "yaml file",
+	content: "foo: bar\nhosts: [a, b]\ntls: {port: 443}\nbase:\n",
+	expect: Settings{
+		"foo":   "bar",
+		"hosts": []interface{}{"a", "b"},
+		"tls":   map[string]interface{}{"port": 443},
+	},
+}, {
+	about:   "json file",
+	args:    []string{"--format", "json"},
+	content: `{"foo": "bar", "tls": {"port": 443}}`,
+	expect: Settings{
+		"base": "value",
+		"foo":  "bar",
+		"tls":  map[string]interface{}{"port": float64(443)},
+	},
+}, {
+	about:   "arguments override file",
+	args:    []string{"foo=baz"},
+	content: "foo: bar\n",
+	expect:  Settings{"base": "value", "foo": "baz"},
+}, {
+	about:   "empty file",
+	content: "",
+	expect:  Settings{"base": "value"},
+}, {
+	about:   "not a map",
+	content: "[a, b]\n",
+	err:     `cannot read settings from "settings.yaml": expected a map of settings, got \[\]interface \{\}`,
+}, {
+	about:   "invalid nested key",
+	content: "foo: {a$b: c}\n",
+	err:     `cannot read settings from "settings.yaml": bad value for "foo": invalid key "a\$b": .*`,
+}, {
+	about:   "too large",
+	content: "foo: " + strings.Repeat("x", params.MaxRelationSettingsSize) + "\n",
+	err:     `relation settings too large: \d+ bytes exceeds limit of 65536 bytes`,
+}}
+
+func (s *RelationSetSuite) TestRunFile(c *gc.C) {
+	hctx := s.GetHookContext(c, 1, "")
+	for i, t := range relationSetFileTests {
+		c.Logf("test %d: %s", i, t.about)
+		basic := Settings{"base": "value"}
+		hctx.rels[1].units["u/0"] = basic
+
+		ctx := testing.Context(c)
+		path := filepath.Join(ctx.Dir, "settings.yaml")
+		err := ioutil.WriteFile(path, []byte(t.content), 0644)
+		c.Assert(err, gc.IsNil)
+
+		com, err := jujuc.NewCommand(hctx, "relation-set")
+		c.Assert(err, gc.IsNil)
+		args := append([]string{"--file", "settings.yaml"}, t.args...)
+		code := cmd.Main(com, ctx, args)
+		if t.err != "" {
+			c.Check(code, gc.Equals, 1)
+			c.Check(bufferString(ctx.Stderr), gc.Matches, "error: "+t.err+"\n")
+			c.Check(hctx.rels[1].units["u/0"], gc.DeepEquals, Settings{"base": "value"})
+			continue
+		}
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(hctx.rels[1].units["u/0"], gc.DeepEquals, t.expect)
+	}
+}
+
+func (s *RelationSetSuite) TestRunFileStdin(c *gc.C) {
+	hctx := s.GetHookContext(c, 1, "")
+	hctx.rels[1].units["u/0"] = Settings{"base": "value"}
+	com, err := jujuc.NewCommand(hctx, "relation-set")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	ctx.Stdin = bytes.NewBufferString("cert: {chain: [one, two]}\n")
+	code := cmd.Main(com, ctx, []string{"--file", "-"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(hctx.rels[1].units["u/0"], gc.DeepEquals, Settings{
+		"base": "value",
+		"cert": map[string]interface{}{"chain": []interface{}{"one", "two"}},
+	})
 }
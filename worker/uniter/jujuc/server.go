@@ -27,11 +27,13 @@ var newCommands = map[string]func(Context) cmd.Command{
 	"close-port":    NewClosePortCommand,
 	"config-get":    NewConfigGetCommand,
 	"juju-log":      NewJujuLogCommand,
+	"network-get":   NewNetworkGetCommand,
 	"open-port":     NewOpenPortCommand,
 	"relation-get":  NewRelationGetCommand,
 	"relation-ids":  NewRelationIdsCommand,
 	"relation-list": NewRelationListCommand,
 	"relation-set":  NewRelationSetCommand,
+	"storage-get":   NewStorageGetCommand,
 	"unit-get":      NewUnitGetCommand,
 	"owner-get":     NewOwnerGetCommand,
 }
@@ -199,6 +199,7 @@ var newCommandTests = []struct {
 	{"relation-ids", ""},
 	{"relation-list", ""},
 	{"relation-set", ""},
+	{"storage-get", ""},
 	{"unit-get", ""},
 	{"random", "unknown command: random"},
 }
@@ -0,0 +1,100 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+)
+
+const storageGetDoc = `
+storage-get prints details of the named block storage volume of the unit,
+as specified when the service was deployed. If a key is given, only that
+detail is printed; otherwise all details are printed. Valid keys are "pool",
+"size" (in MiB), "status" and "device". The device is the block device
+through which the volume is accessed, and is only set once the volume's
+status is "attached". Volumes are neither formatted nor mounted; the charm
+must create a filesystem on the device and mount it itself.
+`
+
+// storageGetKeys holds the keys printed by storage-get, in order.
+var storageGetKeys = []string{"pool", "size", "status", "device"}
+
+// StorageGetCommand implements the storage-get command.
+type StorageGetCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	Name string
+	Key  string
+	out  cmd.Output
+}
+
+func NewStorageGetCommand(ctx Context) cmd.Command {
+	return &StorageGetCommand{ctx: ctx}
+}
+
+func (c *StorageGetCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "storage-get",
+		Args:    "<name> [<key>]",
+		Purpose: "get details of a storage volume",
+		Doc:     storageGetDoc,
+	}
+}
+
+func (c *StorageGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *StorageGetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no storage name specified")
+	}
+	c.Name, args = args[0], args[1:]
+	c.Key = ""
+	if len(args) > 0 {
+		c.Key = args[0]
+		valid := false
+		for _, key := range storageGetKeys {
+			if c.Key == key {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown key %q", c.Key)
+		}
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *StorageGetCommand) Run(ctx *cmd.Context) error {
+	volumes, err := c.ctx.StorageVolumes()
+	if err != nil {
+		return err
+	}
+	for _, volume := range volumes {
+		if volume.Name != c.Name {
+			continue
+		}
+		status := volume.Status
+		if volume.StatusInfo != "" {
+			status += ": " + volume.StatusInfo
+		}
+		details := map[string]interface{}{
+			"pool":   volume.Pool,
+			"size":   volume.Size,
+			"status": status,
+			"device": volume.Device,
+		}
+		if c.Key != "" {
+			return c.out.Write(ctx, details[c.Key])
+		}
+		return c.out.Write(ctx, details)
+	}
+	return fmt.Errorf("unit has no storage %q", c.Name)
+}
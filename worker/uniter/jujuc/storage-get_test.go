@@ -0,0 +1,84 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type StorageGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&StorageGetSuite{})
+
+var storageGetTests = []struct {
+	summary string
+	args    []string
+	code    int
+	out     string
+}{{
+	summary: "no name",
+	code:    2,
+	out:     "no storage name specified",
+}, {
+	summary: "unknown key",
+	args:    []string{"data", "bad-key"},
+	code:    2,
+	out:     `unknown key "bad-key"`,
+}, {
+	summary: "too many args",
+	args:    []string{"data", "pool", "size"},
+	code:    2,
+	out:     `unrecognized args: \["size"\]`,
+}, {
+	summary: "unknown storage",
+	args:    []string{"logs"},
+	code:    1,
+	out:     `unit has no storage "logs"`,
+}, {
+	summary: "single key",
+	args:    []string{"data", "device"},
+	out:     "/dev/loop0",
+}, {
+	summary: "all details",
+	args:    []string{"--format", "json", "data"},
+	out:     `{"device":"/dev/loop0","pool":"loop","size":1024,"status":"attached"}`,
+}}
+
+func (s *StorageGetSuite) TestStorageGet(c *gc.C) {
+	for i, t := range storageGetTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, "storage-get")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		if code == 0 {
+			c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+			c.Check(bufferString(ctx.Stdout), gc.Equals, t.out+"\n")
+		} else {
+			c.Check(bufferString(ctx.Stdout), gc.Equals, "")
+			c.Check(bufferString(ctx.Stderr), gc.Matches, "error: "+t.out+"\n")
+		}
+	}
+}
+
+func (s *StorageGetSuite) TestErrorStatus(c *gc.C) {
+	s.volumes[0].Status = "error"
+	s.volumes[0].StatusInfo = "kaboom"
+	s.volumes[0].Device = ""
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "storage-get")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"data", "status"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, "error: kaboom\n")
+}
@@ -32,7 +32,8 @@ func bufferString(w io.Writer) string {
 
 type ContextSuite struct {
 	testing.BaseSuite
-	rels map[int]*ContextRelation
+	rels    map[int]*ContextRelation
+	volumes []params.UnitVolume
 }
 
 func (s *ContextSuite) SetUpTest(c *gc.C) {
@@ -53,6 +54,13 @@ func (s *ContextSuite) SetUpTest(c *gc.C) {
 			},
 		},
 	}
+	s.volumes = []params.UnitVolume{{
+		Name:   "data",
+		Pool:   "loop",
+		Size:   1024,
+		Status: "attached",
+		Device: "/dev/loop0",
+	}}
 }
 
 func (s *ContextSuite) GetHookContext(c *gc.C, relid int, remote string) *Context {
@@ -61,9 +69,10 @@ func (s *ContextSuite) GetHookContext(c *gc.C, relid int, remote string) *Contex
 		c.Assert(found, gc.Equals, true)
 	}
 	return &Context{
-		relid:  relid,
-		remote: remote,
-		rels:   s.rels,
+		relid:   relid,
+		remote:  remote,
+		rels:    s.rels,
+		volumes: s.volumes,
 	}
 }
 
@@ -79,10 +88,11 @@ func setSettings(c *gc.C, ru *state.RelationUnit, settings map[string]interface{
 }
 
 type Context struct {
-	ports  set.Strings
-	relid  int
-	remote string
-	rels   map[int]*ContextRelation
+	ports   set.Strings
+	relid   int
+	remote  string
+	rels    map[int]*ContextRelation
+	volumes []params.UnitVolume
 }
 
 func (c *Context) UnitName() string {
@@ -142,10 +152,15 @@ func (c *Context) OwnerTag() string {
 	return "test-owner"
 }
 
+func (c *Context) StorageVolumes() ([]params.UnitVolume, error) {
+	return c.volumes, nil
+}
+
 type ContextRelation struct {
-	id    int
-	name  string
-	units map[string]Settings
+	id      int
+	name    string
+	units   map[string]Settings
+	network *params.RelationNetwork
 }
 
 func (r *ContextRelation) Id() int {
@@ -181,6 +196,16 @@ func (r *ContextRelation) ReadSettings(name string) (params.RelationSettings, er
 	return s.Map(), nil
 }
 
+func (r *ContextRelation) Network() (params.RelationNetwork, error) {
+	if r.network == nil {
+		return params.RelationNetwork{}, &params.Error{
+			Code:    params.CodeNotFound,
+			Message: fmt.Sprintf("network binding for endpoint %q not found", r.name),
+		}
+	}
+	return *r.network, nil
+}
+
 type Settings params.RelationSettings
 
 func (s Settings) Get(k string) (interface{}, bool) {
@@ -188,7 +213,7 @@ func (s Settings) Get(k string) (interface{}, bool) {
 	return v, f
 }
 
-func (s Settings) Set(k, v string) {
+func (s Settings) Set(k string, v interface{}) {
 	s[k] = v
 }
 
@@ -18,6 +18,7 @@ import (
 // DyingHookQueue.
 type HookQueue interface {
 	hookQueue()
+	Pending() []hook.Info
 	Stop() error
 }
 
@@ -38,6 +39,9 @@ type AliveHookQueue struct {
 	out        chan<- hook.Info
 	relationId int
 
+	// pending receives requests for the hooks still queued.
+	pending chan chan []hook.Info
+
 	// info holds information about all units that were added to the
 	// queue and haven't had a "relation-departed" event popped. This
 	// means the unit may be in info and not currently in the queue
@@ -89,6 +93,7 @@ func NewAliveHookQueue(initial *State, out chan<- hook.Info, w RelationUnitsWatc
 		w:          w,
 		out:        out,
 		relationId: initial.RelationId,
+		pending:    make(chan chan []hook.Info),
 		info:       map[string]*unitInfo{},
 	}
 	go q.loop(initial)
@@ -146,6 +151,8 @@ func (q *AliveHookQueue) loop(initial *State) {
 			q.update(ch)
 		case out <- next:
 			q.pop()
+		case reply := <-q.pending:
+			reply <- q.queued()
 		}
 	}
 }
@@ -154,6 +161,41 @@ func (q *AliveHookQueue) hookQueue() {
 	panic("interface sentinel method, do not call")
 }
 
+// Pending returns the hooks that the AliveHookQueue has yet to send,
+// in the order it will send them.
+func (q *AliveHookQueue) Pending() []hook.Info {
+	return pendingHooks(q.pending, &q.tomb)
+}
+
+// queued returns the hooks still in the queue, in order.
+func (q *AliveHookQueue) queued() []hook.Info {
+	var queued []hook.Info
+	if q.changedPending != "" {
+		queued = append(queued, q.hookInfo(hooks.RelationChanged, q.changedPending))
+	}
+	for info := q.head; info != nil; info = info.next {
+		if info.unit == q.changedPending && info.hookKind == hooks.RelationChanged {
+			// This will be dropped when the pending changed is popped.
+			continue
+		}
+		queued = append(queued, q.hookInfo(info.hookKind, info.unit))
+	}
+	return queued
+}
+
+// pendingHooks asks a hook queue's loop, by sending a reply channel
+// on requests, for the hooks it has yet to send. It returns nil once
+// the queue has stopped.
+func pendingHooks(requests chan<- chan []hook.Info, t *tomb.Tomb) []hook.Info {
+	reply := make(chan []hook.Info, 1)
+	select {
+	case requests <- reply:
+		return <-reply
+	case <-t.Dead():
+		return nil
+	}
+}
+
 // Stop stops the AliveHookQueue and returns any errors encountered during
 // operation or while shutting down.
 func (q *AliveHookQueue) Stop() error {
@@ -239,12 +281,17 @@ func (q *AliveHookQueue) next() hook.Info {
 		unit = q.head.unit
 		kind = q.head.hookKind
 	}
-	version := q.info[unit].version
+	return q.hookInfo(kind, unit)
+}
+
+// hookInfo returns a hook.Info for the given kind of hook for the
+// named unit. It will panic if the unit is not in q.info.
+func (q *AliveHookQueue) hookInfo(kind hooks.Kind, unit string) hook.Info {
 	return hook.Info{
 		Kind:          kind,
 		RelationId:    q.relationId,
 		RemoteUnit:    unit,
-		ChangeVersion: version,
+		ChangeVersion: q.info[unit].version,
 	}
 }
 
@@ -312,6 +359,7 @@ type DyingHookQueue struct {
 	relationId     int
 	members        map[string]int64
 	changedPending string
+	pending        chan chan []hook.Info
 }
 
 // NewDyingHookQueue returns a new DyingHookQueue that shuts down the state in
@@ -322,6 +370,7 @@ func NewDyingHookQueue(initial *State, out chan<- hook.Info) *DyingHookQueue {
 		relationId:     initial.RelationId,
 		members:        map[string]int64{},
 		changedPending: initial.ChangedPending,
+		pending:        make(chan chan []hook.Info),
 	}
 	for m, v := range initial.Members {
 		q.members[m] = v
@@ -334,12 +383,9 @@ func (q *DyingHookQueue) loop() {
 	defer q.tomb.Done()
 
 	// Honour any expected relation-changed hook.
+	var queued []hook.Info
 	if q.changedPending != "" {
-		select {
-		case <-q.tomb.Dying():
-			return
-		case q.out <- q.hookInfo(hooks.RelationChanged, q.changedPending):
-		}
+		queued = append(queued, q.hookInfo(hooks.RelationChanged, q.changedPending))
 	}
 
 	// Depart in consistent order, mainly for testing purposes.
@@ -349,19 +395,22 @@ func (q *DyingHookQueue) loop() {
 	}
 	sort.Strings(departs)
 	for _, unit := range departs {
+		queued = append(queued, q.hookInfo(hooks.RelationDeparted, unit))
+	}
+
+	// Finally break the relation.
+	queued = append(queued, hook.Info{Kind: hooks.RelationBroken, RelationId: q.relationId})
+
+	for len(queued) > 0 {
 		select {
 		case <-q.tomb.Dying():
 			return
-		case q.out <- q.hookInfo(hooks.RelationDeparted, unit):
+		case q.out <- queued[0]:
+			queued = queued[1:]
+		case reply := <-q.pending:
+			reply <- queued
 		}
 	}
-
-	// Finally break the relation.
-	select {
-	case <-q.tomb.Dying():
-		return
-	case q.out <- hook.Info{Kind: hooks.RelationBroken, RelationId: q.relationId}:
-	}
 	q.tomb.Kill(nil)
 	return
 }
@@ -382,6 +431,12 @@ func (q *DyingHookQueue) hookQueue() {
 	panic("interface sentinel method, do not call")
 }
 
+// Pending returns the hooks that the DyingHookQueue has yet to send,
+// in the order it will send them.
+func (q *DyingHookQueue) Pending() []hook.Info {
+	return pendingHooks(q.pending, &q.tomb)
+}
+
 // Stop stops the DyingHookQueue and returns any errors encountered
 // during operation or while shutting down.
 func (q *DyingHookQueue) Stop() error {
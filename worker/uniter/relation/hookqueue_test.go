@@ -213,6 +213,42 @@ func (s *HookQueueSuite) TestDyingHookQueue(c *gc.C) {
 	}
 }
 
+func (s *HookQueueSuite) TestAliveHookQueuePending(c *gc.C) {
+	out := make(chan hook.Info)
+	in := make(chan params.RelationUnitsChange)
+	ruw := &RUW{in, false}
+	q := relation.NewAliveHookQueue(&relation.State{21345, nil, ""}, out, ruw)
+	send{msi{"u/0": 0, "u/1": 3}, nil}.check(c, in, out)
+	c.Assert(q.Pending(), gc.DeepEquals, []hook.Info{
+		{Kind: hooks.RelationJoined, RelationId: 21345, RemoteUnit: "u/0", ChangeVersion: 0},
+		{Kind: hooks.RelationJoined, RelationId: 21345, RemoteUnit: "u/1", ChangeVersion: 3},
+	})
+	expect{hooks.RelationJoined, "u/0", 0}.check(c, in, out)
+	c.Assert(q.Pending(), gc.DeepEquals, []hook.Info{
+		{Kind: hooks.RelationChanged, RelationId: 21345, RemoteUnit: "u/0", ChangeVersion: 0},
+		{Kind: hooks.RelationJoined, RelationId: 21345, RemoteUnit: "u/1", ChangeVersion: 3},
+	})
+	q.Stop()
+	c.Assert(q.Pending(), gc.IsNil)
+}
+
+func (s *HookQueueSuite) TestDyingHookQueuePending(c *gc.C) {
+	out := make(chan hook.Info)
+	q := relation.NewDyingHookQueue(&relation.State{21345, msi{"u/0": 3}, "u/0"}, out)
+	c.Assert(q.Pending(), gc.DeepEquals, []hook.Info{
+		{Kind: hooks.RelationChanged, RelationId: 21345, RemoteUnit: "u/0", ChangeVersion: 3},
+		{Kind: hooks.RelationDeparted, RelationId: 21345, RemoteUnit: "u/0", ChangeVersion: 3},
+		{Kind: hooks.RelationBroken, RelationId: 21345},
+	})
+	expect{hooks.RelationChanged, "u/0", 3}.check(c, nil, out)
+	c.Assert(q.Pending(), gc.DeepEquals, []hook.Info{
+		{Kind: hooks.RelationDeparted, RelationId: 21345, RemoteUnit: "u/0", ChangeVersion: 3},
+		{Kind: hooks.RelationBroken, RelationId: 21345},
+	})
+	q.Stop()
+	c.Assert(q.Pending(), gc.IsNil)
+}
+
 // RUW exists entirely to send RelationUnitsChanged events to a tested
 // HookQueue in a synchronous and predictable fashion.
 type RUW struct {
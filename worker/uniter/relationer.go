@@ -123,6 +123,15 @@ func (r *Relationer) StopHooks() error {
 	return queue.Stop()
 }
 
+// PendingHooks returns the hooks the relationer has yet to send on its
+// hooks channel, in the order it will send them.
+func (r *Relationer) PendingHooks() []hook.Info {
+	if r.queue == nil {
+		return nil
+	}
+	return r.queue.Pending()
+}
+
 // PrepareHook checks that the relation is in a state such that it makes
 // sense to execute the supplied hook, and ensures that the relation context
 // contains the latest relation state as communicated in the hook.Info. It
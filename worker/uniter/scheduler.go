@@ -0,0 +1,189 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"time"
+
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/state/api/params"
+	apiwatcher "github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/utils/cron"
+)
+
+// scheduleUnit holds the methods of a unit used by the scheduler.
+type scheduleUnit interface {
+	WatchSchedules() (apiwatcher.NotifyWatcher, error)
+	Schedules() ([]params.UnitSchedule, error)
+	SetScheduleRun(scheduleId string, started time.Time, code int, output, errMessage string) error
+}
+
+// schedulerNow returns the current time; it is patched in tests.
+var schedulerNow = time.Now
+
+// scheduledCommand holds a schedule whose command is due to be run.
+type scheduledCommand struct {
+	id      string
+	command string
+	spec    *cron.Spec
+	next    time.Time
+}
+
+// scheduler runs the commands of the unit's schedules, in a hook
+// context, when they fall due, and records the results in state.
+type scheduler struct {
+	tomb     tomb.Tomb
+	unit     scheduleUnit
+	runner   CommandRunner
+	commands []*scheduledCommand
+}
+
+// newScheduler starts a scheduler that runs the unit's scheduled
+// commands with the given runner.
+func newScheduler(unit scheduleUnit, runner CommandRunner) *scheduler {
+	s := &scheduler{
+		unit:   unit,
+		runner: runner,
+	}
+	go func() {
+		defer s.tomb.Done()
+		s.tomb.Kill(s.loop())
+	}()
+	return s
+}
+
+func (s *scheduler) Kill() {
+	s.tomb.Kill(nil)
+}
+
+func (s *scheduler) Stop() error {
+	s.tomb.Kill(nil)
+	return s.tomb.Wait()
+}
+
+func (s *scheduler) Wait() error {
+	return s.tomb.Wait()
+}
+
+func (s *scheduler) loop() error {
+	w, err := s.unit.WatchSchedules()
+	if params.IsCodeNotImplemented(err) {
+		// Older state servers do not support schedules.
+		logger.Infof("scheduled commands not supported by the state server")
+		<-s.tomb.Dying()
+		return tomb.ErrDying
+	} else if err != nil {
+		return err
+	}
+	defer watcher.Stop(w, &s.tomb)
+	for {
+		var due <-chan time.Time
+		if next := s.nextDue(); !next.IsZero() {
+			due = time.After(next.Sub(schedulerNow()))
+		}
+		select {
+		case <-s.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.MustErr(w)
+			}
+			if err := s.refresh(); err != nil {
+				return err
+			}
+		case <-due:
+			s.runDue()
+		}
+	}
+}
+
+// refresh reads the unit's schedules, retaining the next run time of
+// any that have not changed.
+func (s *scheduler) refresh() error {
+	schedules, err := s.unit.Schedules()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*scheduledCommand)
+	for _, command := range s.commands {
+		existing[command.id] = command
+	}
+	// Schedules are specified in UTC.
+	now := schedulerNow().UTC()
+	s.commands = nil
+	for _, schedule := range schedules {
+		if schedule.Paused {
+			continue
+		}
+		if command, ok := existing[schedule.Id]; ok && command.spec.String() == schedule.Spec && command.command == schedule.Command {
+			s.commands = append(s.commands, command)
+			continue
+		}
+		spec, err := cron.Parse(schedule.Spec)
+		if err != nil {
+			// The spec was validated when the schedule was added,
+			// so this should never happen.
+			logger.Errorf("ignoring schedule %s: %v", schedule.Id, err)
+			continue
+		}
+		s.commands = append(s.commands, &scheduledCommand{
+			id:      schedule.Id,
+			command: schedule.Command,
+			spec:    spec,
+			next:    spec.Next(now),
+		})
+	}
+	return nil
+}
+
+// nextDue returns the earliest time at which a scheduled command is
+// due, or the zero time if there is none.
+func (s *scheduler) nextDue() time.Time {
+	var next time.Time
+	for _, command := range s.commands {
+		if command.next.IsZero() {
+			continue
+		}
+		if next.IsZero() || command.next.Before(next) {
+			next = command.next
+		}
+	}
+	return next
+}
+
+// runDue runs every command that is due, and works out when each
+// will next be run.
+func (s *scheduler) runDue() {
+	for _, command := range s.commands {
+		if command.next.IsZero() || command.next.After(schedulerNow()) {
+			continue
+		}
+		s.run(command)
+		command.next = command.spec.Next(schedulerNow().UTC())
+	}
+}
+
+// run runs the given command and records the result.
+func (s *scheduler) run(command *scheduledCommand) {
+	logger.Infof("running schedule %s: %s", command.id, command.command)
+	started := schedulerNow()
+	var code int
+	var output, errMessage string
+	result, err := s.runner.RunCommands(command.command)
+	if result != nil {
+		code = result.Code
+		output = string(result.Stdout) + string(result.Stderr)
+	}
+	if err != nil {
+		errMessage = err.Error()
+		logger.Warningf("cannot run schedule %s: %v", command.id, err)
+	}
+	err = s.unit.SetScheduleRun(command.id, started, code, output, errMessage)
+	if err != nil {
+		// The schedule may have been removed while the command ran.
+		logger.Warningf("cannot record run of schedule %s: %v", command.id, err)
+	}
+}
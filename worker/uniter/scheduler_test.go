@@ -0,0 +1,185 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/utils/exec"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	apiwatcher "github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter"
+)
+
+type SchedulerSuite struct {
+	testing.BaseSuite
+	unit   *fakeScheduleUnit
+	runner *fakeScheduleRunner
+}
+
+var _ = gc.Suite(&SchedulerSuite{})
+
+func (s *SchedulerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	// Run the clock from just before a minute boundary, so that
+	// "every minute" schedules fall due almost at once, and not
+	// again during the test.
+	base := time.Date(2014, 7, 1, 12, 0, 59, 800e6, time.UTC)
+	start := time.Now()
+	s.PatchValue(uniter.SchedulerNow, func() time.Time {
+		return base.Add(time.Since(start))
+	})
+	s.unit = &fakeScheduleUnit{
+		changes: make(chan struct{}, 1),
+		runs:    make(chan params.ScheduleRun, 10),
+	}
+	s.runner = &fakeScheduleRunner{commands: make(chan string, 10)}
+}
+
+func (s *SchedulerSuite) TestRunsDueCommands(c *gc.C) {
+	s.unit.schedules = []params.UnitSchedule{
+		{Id: "1", Spec: "* * * * *", Command: "echo hello"},
+		{Id: "2", Spec: "* * * * *", Command: "echo paused", Paused: true},
+		{Id: "3", Spec: "@daily", Command: "echo later"},
+	}
+	s.unit.changes <- struct{}{}
+	scheduler := uniter.NewScheduler(s.unit, s.runner)
+	defer func() { c.Assert(scheduler.Stop(), gc.IsNil) }()
+
+	select {
+	case command := <-s.runner.commands:
+		c.Assert(command, gc.Equals, "echo hello")
+	case <-time.After(testing.LongWait):
+		c.Fatalf("command not run")
+	}
+	select {
+	case run := <-s.unit.runs:
+		c.Assert(run.ScheduleId, gc.Equals, "1")
+		c.Assert(run.Code, gc.Equals, 0)
+		c.Assert(run.Output, gc.Equals, "ran echo hello\n")
+		c.Assert(run.Error, gc.Equals, "")
+	case <-time.After(testing.LongWait):
+		c.Fatalf("run not recorded")
+	}
+	select {
+	case command := <-s.runner.commands:
+		c.Fatalf("unexpected command run: %q", command)
+	case <-time.After(testing.ShortWait):
+	}
+}
+
+func (s *SchedulerSuite) TestSchedulesAreInUTC(c *gc.C) {
+	// With the clock in a local time zone ahead of UTC, a command
+	// scheduled for 12:00 UTC falls due almost at once, rather than
+	// at 12:00 local time.
+	local := time.FixedZone("UTC+5:30", 5*60*60+30*60)
+	s.PatchValue(&time.Local, local)
+	base := time.Date(2014, 7, 1, 11, 59, 59, 800e6, time.UTC).In(local)
+	start := time.Now()
+	s.PatchValue(uniter.SchedulerNow, func() time.Time {
+		return base.Add(time.Since(start))
+	})
+	s.unit.schedules = []params.UnitSchedule{
+		{Id: "1", Spec: "0 12 * * *", Command: "echo noon"},
+	}
+	s.unit.changes <- struct{}{}
+	scheduler := uniter.NewScheduler(s.unit, s.runner)
+	defer func() { c.Assert(scheduler.Stop(), gc.IsNil) }()
+
+	select {
+	case command := <-s.runner.commands:
+		c.Assert(command, gc.Equals, "echo noon")
+	case <-time.After(testing.LongWait):
+		c.Fatalf("command not run")
+	}
+}
+
+func (s *SchedulerSuite) TestRecordsErrors(c *gc.C) {
+	s.unit.schedules = []params.UnitSchedule{
+		{Id: "1", Spec: "* * * * *", Command: "fail"},
+	}
+	s.unit.changes <- struct{}{}
+	scheduler := uniter.NewScheduler(s.unit, s.runner)
+	defer func() { c.Assert(scheduler.Stop(), gc.IsNil) }()
+
+	select {
+	case run := <-s.unit.runs:
+		c.Assert(run.ScheduleId, gc.Equals, "1")
+		c.Assert(run.Error, gc.Equals, "cannot run fail")
+	case <-time.After(testing.LongWait):
+		c.Fatalf("run not recorded")
+	}
+}
+
+func (s *SchedulerSuite) TestNotImplemented(c *gc.C) {
+	s.unit.watchErr = &params.Error{Code: params.CodeNotImplemented, Message: "not implemented"}
+	scheduler := uniter.NewScheduler(s.unit, s.runner)
+	select {
+	case <-time.After(testing.ShortWait):
+	case command := <-s.runner.commands:
+		c.Fatalf("unexpected command run: %q", command)
+	}
+	c.Assert(scheduler.Stop(), gc.IsNil)
+}
+
+type fakeScheduleUnit struct {
+	changes   chan struct{}
+	schedules []params.UnitSchedule
+	runs      chan params.ScheduleRun
+	watchErr  error
+}
+
+func (u *fakeScheduleUnit) WatchSchedules() (apiwatcher.NotifyWatcher, error) {
+	if u.watchErr != nil {
+		return nil, u.watchErr
+	}
+	return &fakeNotifyWatcher{u.changes}, nil
+}
+
+func (u *fakeScheduleUnit) Schedules() ([]params.UnitSchedule, error) {
+	return u.schedules, nil
+}
+
+func (u *fakeScheduleUnit) SetScheduleRun(id string, started time.Time, code int, output, errMessage string) error {
+	u.runs <- params.ScheduleRun{
+		ScheduleId: id,
+		Time:       started,
+		Code:       code,
+		Output:     output,
+		Error:      errMessage,
+	}
+	return nil
+}
+
+type fakeNotifyWatcher struct {
+	changes chan struct{}
+}
+
+func (w *fakeNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *fakeNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (w *fakeNotifyWatcher) Err() error {
+	return nil
+}
+
+type fakeScheduleRunner struct {
+	commands chan string
+}
+
+func (r *fakeScheduleRunner) RunCommands(commands string) (*exec.ExecResponse, error) {
+	r.commands <- commands
+	if commands == "fail" {
+		return nil, fmt.Errorf("cannot run %s", commands)
+	}
+	return &exec.ExecResponse{Stdout: []byte("ran " + commands + "\n")}, nil
+}
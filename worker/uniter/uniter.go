@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +25,7 @@ import (
 
 	"github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/api/uniter"
 	apiwatcher "github.com/juju/juju/state/api/watcher"
@@ -68,6 +70,7 @@ type Uniter struct {
 	envName       string
 
 	dataDir      string
+	apiInfo      *api.Info
 	baseDir      string
 	toolsDir     string
 	relationsDir string
@@ -90,11 +93,13 @@ type Uniter struct {
 
 // NewUniter creates a new Uniter which will install, run, and upgrade
 // a charm on behalf of the unit with the given unitTag, by executing
-// hooks and operations provoked by changes in st.
-func NewUniter(st *uniter.State, unitTag string, dataDir string, hookLock *fslock.Lock) *Uniter {
+// hooks and operations provoked by changes in st. If apiInfo is not nil,
+// charms are downloaded through the API server it describes when possible.
+func NewUniter(st *uniter.State, unitTag string, dataDir string, apiInfo *api.Info, hookLock *fslock.Lock) *Uniter {
 	u := &Uniter{
 		st:       st,
 		dataDir:  dataDir,
+		apiInfo:  apiInfo,
 		hookLock: hookLock,
 	}
 	go func() {
@@ -131,6 +136,13 @@ func (u *Uniter) loop(unitTag string) (err error) {
 		u.tomb.Kill(u.f.Wait())
 	}()
 
+	// Run scheduled commands as they fall due.
+	scheduler := newScheduler(u.unit, u)
+	defer watcher.Stop(scheduler, &u.tomb)
+	go func() {
+		u.tomb.Kill(scheduler.Wait())
+	}()
+
 	// Run modes until we encounter an error.
 	mode := ModeContinue
 	for err == nil {
@@ -175,6 +187,11 @@ func (u *Uniter) init(unitTag string) (err error) {
 	if err = u.setupLocks(); err != nil {
 		return err
 	}
+	// A previous run of the agent may have died while waiting for the
+	// hook lock, leaving its wait recorded.
+	if err := u.unit.ClearHookLockWait(); err != nil && !params.IsCodeNotImplemented(err) {
+		return err
+	}
 	u.toolsDir = tools.ToolsDir(u.dataDir, unitTag)
 	if err := EnsureJujucSymlinks(u.toolsDir); err != nil {
 		return err
@@ -200,7 +217,7 @@ func (u *Uniter) init(unitTag string) (err error) {
 	u.relationHooks = make(chan hook.Info)
 	u.charmPath = filepath.Join(u.baseDir, "charm")
 	deployerPath := filepath.Join(u.baseDir, "state", "deployer")
-	bundles := charm.NewBundlesDir(filepath.Join(u.baseDir, "state", "bundles"))
+	bundles := charm.NewBundlesDir(filepath.Join(u.baseDir, "state", "bundles"), u.apiInfo)
 	u.deployer, err = charm.NewDeployer(u.charmPath, deployerPath, bundles)
 	if err != nil {
 		return fmt.Errorf("cannot create deployer: %v", err)
@@ -350,7 +367,17 @@ func (u *Uniter) getHookContext(hctxId string, relationId int, remoteUnitName st
 		remoteUnitName, ctxRelations, apiAddrs, ownerTag, proxySettings)
 }
 
-func (u *Uniter) acquireHookLock(message string) (err error) {
+// hookLockReportDelay is how long the uniter waits for the hook
+// execution lock before recording in state that it is blocked.
+var hookLockReportDelay = 5 * time.Second
+
+// acquireHookLock acquires the machine's hook execution lock in order
+// to run hookName. If queued is not nil, it is called to find the hooks
+// the unit has queued behind hookName, so that they can be recorded
+// along with the wait.
+func (u *Uniter) acquireHookLock(message, hookName string, queued func() []string) (err error) {
+	started := time.Now()
+	reported := ""
 	// We want to make sure we don't block forever when locking, but take the
 	// tomb into account.
 	checkTomb := func() error {
@@ -360,12 +387,60 @@ func (u *Uniter) acquireHookLock(message string) (err error) {
 		default:
 			// no-op to fall through to return.
 		}
+		if time.Since(started) >= hookLockReportDelay {
+			if held := u.hookLock.Message(); held != "" {
+				var pending []string
+				if queued != nil {
+					pending = queued()
+				}
+				// Report again whenever the holder or the queue changes.
+				if wait := held + "\n" + strings.Join(pending, "\n"); wait != reported {
+					u.reportHookLockWait(held, hookName, pending, started)
+					reported = wait
+				}
+			}
+		}
 		return nil
 	}
-	if err = u.hookLock.LockWithFunc(message, checkTomb); err != nil {
-		return err
+	err = u.hookLock.LockWithFunc(message, checkTomb)
+	if reported != "" {
+		if err := u.unit.ClearHookLockWait(); err != nil && !params.IsCodeNotImplemented(err) {
+			logger.Warningf("cannot clear hook lock wait: %v", err)
+		}
 	}
-	return nil
+	return err
+}
+
+// reportHookLockWait records in state that the unit has been waiting
+// since the given time to run hookName, followed by the queued hooks,
+// blocked by the holder of the hook lock as described by message.
+func (u *Uniter) reportHookLockWait(message, hookName string, queued []string, since time.Time) {
+	holder, holderHook := parseHookLockMessage(message)
+	logger.Infof("waiting for hook lock held by %s (%s)", holder, holderHook)
+	err := u.unit.SetHookLockWait(holder, holderHook, hookName, queued, since)
+	if err != nil && !params.IsCodeNotImplemented(err) {
+		logger.Warningf("cannot record hook lock wait: %v", err)
+	}
+}
+
+// parseHookLockMessage returns the holder of the hook lock, and what
+// it is running, from the lock's message.
+func parseHookLockMessage(message string) (holder, holderHook string) {
+	parts := strings.SplitN(message, ":", 2)
+	holder = strings.TrimSpace(parts[0])
+	if len(parts) < 2 {
+		return holder, ""
+	}
+	activity := strings.TrimSpace(parts[1])
+	switch {
+	case strings.HasPrefix(activity, "running hook "):
+		holderHook = strings.Trim(strings.TrimPrefix(activity, "running hook "), `"`)
+	case activity == "running commands":
+		holderHook = "juju-run"
+	default:
+		holderHook = activity
+	}
+	return holder, holderHook
 }
 
 func (u *Uniter) startJujucServer(context *HookContext) (*jujuc.Server, string, error) {
@@ -394,7 +469,9 @@ func (u *Uniter) RunCommands(commands string) (results *exec.ExecResponse, err e
 	logger.Tracef("run commands: %s", commands)
 	hctxId := fmt.Sprintf("%s:run-commands:%d", u.unit.Name(), u.rand.Int63())
 	lockMessage := fmt.Sprintf("%s: running commands", u.unit.Name())
-	if err = u.acquireHookLock(lockMessage); err != nil {
+	// The relationers belong to the uniter's own goroutine, so the
+	// hooks queued behind the commands are not reported.
+	if err = u.acquireHookLock(lockMessage, "juju-run", nil); err != nil {
 		return nil, err
 	}
 	defer u.hookLock.Unlock()
@@ -458,7 +535,7 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), hookName, u.rand.Int63())
 
 	lockMessage := fmt.Sprintf("%s: running hook %q", u.unit.Name(), hookName)
-	if err = u.acquireHookLock(lockMessage); err != nil {
+	if err = u.acquireHookLock(lockMessage, hookName, u.queuedHooks); err != nil {
 		return err
 	}
 	defer u.hookLock.Unlock()
@@ -521,6 +598,25 @@ func (u *Uniter) commitHook(hi hook.Info) error {
 	return nil
 }
 
+// queuedHooks returns the full names of the relation hooks queued to
+// run, ordered by relation id.
+func (u *Uniter) queuedHooks() []string {
+	ids := make([]int, 0, len(u.relationers))
+	for id := range u.relationers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	var queued []string
+	for _, id := range ids {
+		r := u.relationers[id]
+		name := r.ru.Endpoint().Name
+		for _, hi := range r.PendingHooks() {
+			queued = append(queued, fmt.Sprintf("%s-%s", name, hi.Kind))
+		}
+	}
+	return queued
+}
+
 // currentHookName returns the current full hook name.
 func (u *Uniter) currentHookName() string {
 	hookInfo := u.s.Hook
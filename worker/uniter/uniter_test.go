@@ -489,9 +489,58 @@ var hookSynchronizationTests = []uniterTest{
 		waitUnit{status: params.StatusStarted},
 		waitHooks{"install", "config-changed", "start"},
 	),
+	ut(
+		"verify waiting for a held lock is recorded",
+		acquireHookSyncLock{`u/1: running hook "config-changed"`},
+		createCharm{},
+		serveCharm{},
+		ensureStateWorker{},
+		createServiceAndUnit{},
+		startUniter{},
+		waitAddresses{},
+		waitHookLockWait{&state.HookLockWait{
+			Holder:     "u/1",
+			HolderHook: "config-changed",
+			Hook:       "install",
+		}},
+		releaseHookSyncLock,
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"install", "config-changed", "start"},
+		waitHookLockWait{},
+	),
+	ut(
+		"verify a wait left by a previous run is cleared",
+		createCharm{},
+		serveCharm{},
+		ensureStateWorker{},
+		createServiceAndUnit{},
+		setHookLockWait{state.HookLockWait{Holder: "u/1", Hook: "install"}},
+		startUniter{},
+		waitAddresses{},
+		waitHookLockWait{},
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"install", "config-changed", "start"},
+	),
+	ut(
+		"verify hooks queued behind a held lock are recorded",
+		quickStart{},
+		addRelation{waitJoin: true},
+		acquireHookSyncLock{`u/1: running hook "config-changed"`},
+		addRelationUnit{},
+		waitHookLockWait{&state.HookLockWait{
+			Holder:     "u/1",
+			HolderHook: "config-changed",
+			Hook:       "db-relation-joined",
+			Queued:     []string{"db-relation-changed"},
+		}},
+		releaseHookSyncLock,
+		waitHooks{"db-relation-joined mysql/0 db:0", "db-relation-changed mysql/0 db:0"},
+		waitHookLockWait{},
+	),
 }
 
 func (s *UniterSuite) TestUniterHookSynchronisation(c *gc.C) {
+	s.PatchValue(uniter.HookLockReportDelay, time.Duration(0))
 	s.runUniterTests(c, hookSynchronizationTests)
 }
 
@@ -1496,7 +1545,7 @@ func (s startUniter) step(c *gc.C, ctx *context) {
 	locksDir := filepath.Join(ctx.dataDir, "locks")
 	lock, err := fslock.NewLock(locksDir, "uniter-hook-execution")
 	c.Assert(err, gc.IsNil)
-	ctx.uniter = uniter.NewUniter(ctx.s.uniter, s.unitTag, ctx.dataDir, lock)
+	ctx.uniter = uniter.NewUniter(ctx.s.uniter, s.unitTag, ctx.dataDir, nil, lock)
 	uniter.SetUniterObserver(ctx.uniter, ctx)
 }
 
@@ -2197,6 +2246,40 @@ var verifyHookSyncLockLocked = custom{func(c *gc.C, ctx *context) {
 	c.Assert(lock.IsLocked(), jc.IsTrue)
 }}
 
+type setHookLockWait struct {
+	wait state.HookLockWait
+}
+
+func (s setHookLockWait) step(c *gc.C, ctx *context) {
+	err := ctx.unit.SetHookLockWait(s.wait)
+	c.Assert(err, gc.IsNil)
+}
+
+type waitHookLockWait struct {
+	expect *state.HookLockWait
+}
+
+func (s waitHookLockWait) step(c *gc.C, ctx *context) {
+	timeout := time.After(worstCase)
+	for {
+		wait, err := ctx.unit.HookLockWait()
+		c.Assert(err, gc.IsNil)
+		if wait != nil {
+			// The time the wait started is not predictable.
+			wait.Since = time.Time{}
+		}
+		if ok, _ := jc.DeepEquals.Check([]interface{}{wait, s.expect}, nil); ok {
+			return
+		}
+		c.Logf("hook lock wait: %#v", wait)
+		select {
+		case <-time.After(coretesting.ShortWait):
+		case <-timeout:
+			c.Fatalf("never got expected hook lock wait %#v", s.expect)
+		}
+	}
+}
+
 type setProxySettings proxy.Settings
 
 func (s setProxySettings) step(c *gc.C, ctx *context) {
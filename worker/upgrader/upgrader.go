@@ -6,14 +6,19 @@ package upgrader
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/agent"
 	agenttools "github.com/juju/juju/agent/tools"
+	"github.com/juju/juju/downloader"
+	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/upgrader"
 	"github.com/juju/juju/state/watcher"
 	coretools "github.com/juju/juju/tools"
@@ -26,6 +31,10 @@ var retryAfter = func() <-chan time.Time {
 	return time.After(5 * time.Second)
 }
 
+// progressInterval holds how often the progress of a tools download
+// is reported.
+var progressInterval = 10 * time.Second
+
 var logger = loggo.GetLogger("juju.worker.upgrader")
 
 // Upgrader represents a worker that watches the state for upgrade
@@ -35,6 +44,7 @@ type Upgrader struct {
 	st      *upgrader.State
 	dataDir string
 	tag     string
+	apiInfo *api.Info
 }
 
 // NewUpgrader returns a new upgrader worker. It watches changes to the
@@ -42,12 +52,15 @@ type Upgrader struct {
 // download the tools for any new version into the given data directory.  If
 // an upgrade is needed, the worker will exit with an UpgradeReadyError
 // holding details of the requested upgrade. The tools will have been
-// downloaded and unpacked.
+// downloaded and unpacked. Tools are fetched through the API server
+// when possible, falling back to the tools' own URL; the API server
+// limits the number of agents downloading tools at once.
 func NewUpgrader(st *upgrader.State, agentConfig agent.Config) *Upgrader {
 	u := &Upgrader{
 		st:      st,
 		dataDir: agentConfig.DataDir(),
 		tag:     agentConfig.Tag(),
+		apiInfo: agentConfig.APIInfo(),
 	}
 	go func() {
 		defer u.tomb.Done()
@@ -121,6 +134,16 @@ func (u *Upgrader) loop() error {
 		}
 		if wantVersion == version.Current.Number {
 			continue
+		} else if previousTools := u.rollbackTools(wantVersion); previousTools != nil {
+			// The upgrade has been aborted; switch back to the
+			// tools we ran before, which are still on disk.
+			logger.Infof("rolling back from %v to %v", version.Current, previousTools.Version)
+			return &UpgradeReadyError{
+				OldTools:  version.Current,
+				NewTools:  previousTools.Version,
+				AgentName: u.tag,
+				DataDir:   u.dataDir,
+			}
 		} else if !allowedTargetVersion(version.Current.Number, wantVersion) {
 			// See also bug #1299802 where when upgrading from
 			// 1.16 to 1.18 there is a race condition that can
@@ -159,25 +182,145 @@ func (u *Upgrader) loop() error {
 	}
 }
 
+// rollbackTools returns the tools the agent ran before its last
+// upgrade if the upgrade to the current version has been aborted and
+// the previous tools have the wanted version; otherwise it returns nil.
+// A wanted version that merely matches the previous tools is not
+// enough, as the desired version may be transiently out of date (see
+// bug #1299802).
+func (u *Upgrader) rollbackTools(wantVersion version.Number) *coretools.Tools {
+	previousTools, err := agenttools.PreviousAgentTools(u.dataDir, u.tag)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		logger.Warningf("cannot read previous tools: %v", err)
+		return nil
+	}
+	if previousTools.Version.Number != wantVersion {
+		return nil
+	}
+	aborted, err := u.st.AbortedUpgrade()
+	if err != nil {
+		logger.Warningf("cannot check whether the upgrade was aborted: %v", err)
+		return nil
+	}
+	if !aborted.Aborted || aborted.To != version.Current.Number || aborted.From != wantVersion {
+		return nil
+	}
+	return previousTools
+}
+
 func (u *Upgrader) ensureTools(agentTools *coretools.Tools, hostnameVerification utils.SSLHostnameVerification) error {
 	if _, err := agenttools.ReadTools(u.dataDir, agentTools.Version); err == nil {
 		// Tools have already been downloaded
 		return nil
 	}
-	logger.Infof("fetching tools from %q", agentTools.URL)
-	client := utils.GetHTTPClient(hostnameVerification)
-	resp, err := client.Get(agentTools.URL)
-	if err != nil {
+	// Whether the download succeeds or fails, the progress reported
+	// for it should not linger in the agent's status.
+	defer u.clearProgress(agentTools.Version)
+	// The data received by a failed download is kept, so that the
+	// next attempt resumes from it rather than starting over.
+	dir := filepath.Join(u.dataDir, "downloads", u.tag)
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad HTTP response: %v", resp.Status)
+	var file *os.File
+	if u.apiInfo != nil && len(u.apiInfo.Addrs) > 0 && agentTools.SHA256 != "" {
+		// The API server caches the tools, which saves every agent
+		// going to the tools' source.
+		var err error
+		file, err = u.downloadFromAPIServer(dir, agentTools)
+		if err != nil {
+			logger.Warningf("cannot fetch tools through the API server: %v", err)
+		}
+	}
+	if file == nil {
+		logger.Infof("fetching tools from %q", agentTools.URL)
+		var err error
+		file, err = u.download(agentTools.URL, dir, hostnameVerification, agentTools, nil, nil)
+		if err != nil {
+			return err
+		}
 	}
-	err = agenttools.UnpackTools(u.dataDir, agentTools, resp.Body)
+	defer func() {
+		file.Close()
+		if err := os.Remove(file.Name()); err != nil {
+			logger.Warningf("cannot remove downloaded tools: %v", err)
+		}
+	}()
+	err := agenttools.UnpackTools(u.dataDir, agentTools, file)
 	if err != nil {
 		return fmt.Errorf("cannot unpack tools: %v", err)
 	}
 	logger.Infof("unpacked tools %s to %s", agentTools.Version, u.dataDir)
 	return nil
 }
+
+// downloadFromAPIServer downloads the given tools through the API
+// server into dir, trying each of the API server addresses in turn.
+// The server's certificate is verified before the agent's credentials
+// are sent to it.
+func (u *Upgrader) downloadFromAPIServer(dir string, agentTools *coretools.Tools) (*os.File, error) {
+	client, err := api.NewHTTPClient(u.apiInfo)
+	if err != nil {
+		return nil, err
+	}
+	header := api.AuthHeader(u.apiInfo.Tag, u.apiInfo.Password)
+	for _, addr := range u.apiInfo.Addrs {
+		url := api.ToolsURL(addr, agentTools.Version)
+		logger.Infof("fetching tools from %q", url)
+		var file *os.File
+		file, err = u.download(url, dir, utils.VerifySSLHostnames, agentTools, client, header)
+		if err == nil {
+			return file, nil
+		}
+		logger.Warningf("cannot fetch tools from %q: %v", url, err)
+	}
+	return nil, err
+}
+
+// download downloads the given tools from url into dir. If client is
+// not nil it is used to make the requests, which carry the given
+// headers.
+func (u *Upgrader) download(
+	url, dir string,
+	hostnameVerification utils.SSLHostnameVerification,
+	agentTools *coretools.Tools,
+	client *http.Client,
+	header http.Header,
+) (*os.File, error) {
+	dl := downloader.NewWithOptions(url, dir, hostnameVerification, downloader.Options{
+		Size:     agentTools.Size,
+		SHA256:   agentTools.SHA256,
+		Progress: u.progressReporter(agentTools.Version),
+		Header:   header,
+		Client:   client,
+	})
+	defer dl.Stop()
+	status := <-dl.Done()
+	return status.File, status.Err
+}
+
+// progressReporter returns a function that reports the progress of
+// the download of the given tools to the agent's status, at most once
+// every progressInterval and when the download completes.
+func (u *Upgrader) progressReporter(vers version.Binary) func(received, total int64) {
+	var lastReport time.Time
+	return func(received, total int64) {
+		if received != total && time.Since(lastReport) < progressInterval {
+			return
+		}
+		lastReport = time.Now()
+		if err := u.st.SetToolsDownloadProgress(u.tag, vers, received, total); err != nil {
+			logger.Warningf("cannot report tools download progress: %v", err)
+		}
+	}
+}
+
+// clearProgress clears the progress reported for the download of the
+// given tools from the agent's status.
+func (u *Upgrader) clearProgress(vers version.Binary) {
+	if err := u.st.ClearToolsDownloadProgress(u.tag, vers); err != nil {
+		logger.Warningf("cannot clear tools download progress: %v", err)
+	}
+}
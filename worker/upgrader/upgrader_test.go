@@ -5,6 +5,7 @@ package upgrader_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	stdtesting "testing"
@@ -23,6 +24,7 @@ import (
 	"github.com/juju/juju/provider/dummy"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
 	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
@@ -64,6 +66,8 @@ type mockConfig struct {
 	agent.Config
 	tag     string
 	datadir string
+	values  map[string]string
+	apiInfo *api.Info
 }
 
 func (mock *mockConfig) Tag() string {
@@ -74,6 +78,17 @@ func (mock *mockConfig) DataDir() string {
 	return mock.datadir
 }
 
+func (mock *mockConfig) Value(key string) string {
+	return mock.values[key]
+}
+
+func (mock *mockConfig) APIInfo() *api.Info {
+	if mock.apiInfo == nil {
+		return &api.Info{}
+	}
+	return mock.apiInfo
+}
+
 func agentConfig(tag, datadir string) agent.Config {
 	return &mockConfig{tag: tag, datadir: datadir}
 }
@@ -151,6 +166,145 @@ func (s *UpgraderSuite) TestUpgraderUpgradesImmediately(c *gc.C) {
 	envtesting.CheckTools(c, foundTools, newTools)
 }
 
+func (s *UpgraderSuite) TestUpgraderClearsDownloadProgress(c *gc.C) {
+	stor := s.Conn.Environ.Storage()
+	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
+	s.PatchValue(&version.Current, oldTools.Version)
+	newTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.5-precise-amd64"))[0]
+	err := statetesting.SetAgentVersion(s.State, newTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+	err = s.machine.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+
+	u := s.makeUpgrader(c)
+	err = u.Stop()
+	envtesting.CheckUpgraderReadyError(c, err, &upgrader.UpgradeReadyError{
+		AgentName: s.machine.Tag().String(),
+		OldTools:  oldTools.Version,
+		NewTools:  newTools.Version,
+		DataDir:   s.DataDir(),
+	})
+	// The progress reported during the download is cleared once it
+	// has finished.
+	status, info, _, err := s.machine.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusStarted)
+	c.Assert(info, gc.Equals, "")
+
+	// The downloaded data is not kept once the tools are unpacked.
+	infos, err := ioutil.ReadDir(filepath.Join(s.DataDir(), "downloads", s.machine.Tag().String()))
+	c.Assert(err, gc.IsNil)
+	c.Assert(infos, gc.HasLen, 0)
+}
+
+// machineAPIInfo returns API connection information that
+// authenticates as the upgrader's machine, with the given password.
+func (s *UpgraderSuite) machineAPIInfo(c *gc.C, password string) *api.Info {
+	info := s.APIInfo(c)
+	info.Tag = s.machine.Tag().String()
+	info.Password = password
+	return info
+}
+
+func (s *UpgraderSuite) TestUpgraderFetchesToolsThroughAPIServer(c *gc.C) {
+	s.assertFetchesToolsThroughAPIServer(c, func(addrs []string) []string {
+		return addrs
+	})
+}
+
+func (s *UpgraderSuite) TestUpgraderTriesEachAPIServerAddress(c *gc.C) {
+	// The first API server is down, so the tools are fetched
+	// through the next one.
+	s.assertFetchesToolsThroughAPIServer(c, func(addrs []string) []string {
+		return append([]string{"localhost:1"}, addrs...)
+	})
+}
+
+// assertFetchesToolsThroughAPIServer checks that the upgrader fetches
+// tools that are only in the API server's cache, when given the API
+// server addresses returned by mungeAddrs.
+func (s *UpgraderSuite) assertFetchesToolsThroughAPIServer(c *gc.C, mungeAddrs func([]string) []string) {
+	stor := s.Conn.Environ.Storage()
+	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
+	s.PatchValue(&version.Current, oldTools.Version)
+	newTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.5-precise-amd64"))[0]
+	err := statetesting.SetAgentVersion(s.State, newTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+
+	// Move the tools from provider storage into the API server's
+	// cache, so they can only be fetched through the API server.
+	r, err := stor.Get(envtools.StorageName(newTools.Version))
+	c.Assert(err, gc.IsNil)
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	c.Assert(err, gc.IsNil)
+	cacheDir := filepath.Join(s.DataDir(), "tools-get-cache")
+	err = os.MkdirAll(cacheDir, 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(cacheDir, newTools.Version.String()+".tgz"), data, 0644)
+	c.Assert(err, gc.IsNil)
+	err = stor.Remove(envtools.StorageName(newTools.Version))
+	c.Assert(err, gc.IsNil)
+
+	password, err := utils.RandomPassword()
+	c.Assert(err, gc.IsNil)
+	err = s.machine.SetPassword(password)
+	c.Assert(err, gc.IsNil)
+	err = s.machine.SetAgentVersion(version.Current)
+	c.Assert(err, gc.IsNil)
+	apiInfo := s.machineAPIInfo(c, password)
+	apiInfo.Addrs = mungeAddrs(apiInfo.Addrs)
+	config := &mockConfig{
+		tag:     s.machine.Tag().String(),
+		datadir: s.DataDir(),
+		apiInfo: apiInfo,
+	}
+	u := upgrader.NewUpgrader(s.state.Upgrader(), config)
+	err = u.Stop()
+	envtesting.CheckUpgraderReadyError(c, err, &upgrader.UpgradeReadyError{
+		AgentName: s.machine.Tag().String(),
+		OldTools:  oldTools.Version,
+		NewTools:  newTools.Version,
+		DataDir:   s.DataDir(),
+	})
+	foundTools, err := agenttools.ReadTools(s.DataDir(), newTools.Version)
+	c.Assert(err, gc.IsNil)
+	envtesting.CheckTools(c, foundTools, newTools)
+}
+
+func (s *UpgraderSuite) TestUpgraderFallsBackToToolsURL(c *gc.C) {
+	stor := s.Conn.Environ.Storage()
+	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
+	s.PatchValue(&version.Current, oldTools.Version)
+	newTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.5-precise-amd64"))[0]
+	err := statetesting.SetAgentVersion(s.State, newTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+
+	// The API server refuses the download, so the tools
+	// are fetched from provider storage instead.
+	err = s.machine.SetAgentVersion(version.Current)
+	c.Assert(err, gc.IsNil)
+	config := &mockConfig{
+		tag:     s.machine.Tag().String(),
+		datadir: s.DataDir(),
+		apiInfo: s.machineAPIInfo(c, "wrong password"),
+	}
+	u := upgrader.NewUpgrader(s.state.Upgrader(), config)
+	err = u.Stop()
+	envtesting.CheckUpgraderReadyError(c, err, &upgrader.UpgradeReadyError{
+		AgentName: s.machine.Tag().String(),
+		OldTools:  oldTools.Version,
+		NewTools:  newTools.Version,
+		DataDir:   s.DataDir(),
+	})
+	foundTools, err := agenttools.ReadTools(s.DataDir(), newTools.Version)
+	c.Assert(err, gc.IsNil)
+	envtesting.CheckTools(c, foundTools, newTools)
+}
+
 func (s *UpgraderSuite) TestUpgraderRetryAndChanged(c *gc.C) {
 	stor := s.Conn.Environ.Storage()
 	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
@@ -166,6 +320,8 @@ func (s *UpgraderSuite) TestUpgraderRetryAndChanged(c *gc.C) {
 		return retryc
 	}
 	dummy.Poison(s.Conn.Environ.Storage(), envtools.StorageName(newTools.Version), fmt.Errorf("a non-fatal dose"))
+	err = s.machine.SetStatus(params.StatusStarted, "downloading tools 5.4.5-precise-amd64: 10% of 1000 bytes", nil)
+	c.Assert(err, gc.IsNil)
 	u := s.makeUpgrader(c)
 	defer u.Stop()
 
@@ -176,6 +332,10 @@ func (s *UpgraderSuite) TestUpgraderRetryAndChanged(c *gc.C) {
 			c.Fatalf("upgrader did not retry (attempt %d)", i)
 		}
 	}
+	// The progress of the failed downloads does not linger.
+	_, info, _, err := s.machine.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info, gc.Equals, "")
 
 	// Make it upgrade to some newer tools that can be
 	// downloaded ok; it should stop retrying, download
@@ -284,6 +444,72 @@ func (s *UpgraderSuite) TestUpgraderAllowsDowngradingPatchVersions(c *gc.C) {
 	envtesting.CheckTools(c, foundTools, downgradeTools)
 }
 
+func (s *UpgraderSuite) TestUpgraderRollsBackToPreviousTools(c *gc.C) {
+	stor := s.Conn.Environ.Storage()
+	prevTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.3.3-precise-amd64"))
+	origTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.3-precise-amd64"))[0]
+	s.PatchValue(&version.Current, origTools.Version)
+	err := statetesting.SetAgentVersion(s.State, origTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+
+	// Upgrade from the previous tools.
+	u := s.makeUpgrader(c)
+	err = upgrader.EnsureTools(u, origTools, utils.VerifySSLHostnames)
+	c.Assert(err, gc.IsNil)
+	c.Assert(u.Stop(), gc.IsNil)
+	agentName := s.machine.Tag().String()
+	_, err = agenttools.ChangeAgentTools(s.DataDir(), agentName, prevTools.Version)
+	c.Assert(err, gc.IsNil)
+	_, err = agenttools.ChangeAgentTools(s.DataDir(), agentName, origTools.Version)
+	c.Assert(err, gc.IsNil)
+
+	// Abort the upgrade; the previous tools are used from disk, even
+	// though they are no longer in storage and are older than the
+	// current minor version.
+	err = stor.Remove(envtools.StorageName(prevTools.Version))
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.EnsureUpgradeInfo(s.machine.Id(), prevTools.Version.Number, origTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+	reverted, err := s.State.AbortUpgrade()
+	c.Assert(err, gc.IsNil)
+	c.Assert(reverted, gc.Equals, prevTools.Version.Number)
+	u = s.makeUpgrader(c)
+	err = u.Stop()
+	envtesting.CheckUpgraderReadyError(c, err, &upgrader.UpgradeReadyError{
+		AgentName: agentName,
+		OldTools:  origTools.Version,
+		NewTools:  prevTools.Version,
+		DataDir:   s.DataDir(),
+	})
+}
+
+func (s *UpgraderSuite) TestUpgraderDoesNotRollBackWithoutAbort(c *gc.C) {
+	stor := s.Conn.Environ.Storage()
+	prevTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.3.3-precise-amd64"))
+	origTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.3-precise-amd64"))[0]
+	s.PatchValue(&version.Current, origTools.Version)
+	err := statetesting.SetAgentVersion(s.State, origTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+	agentName := s.machine.Tag().String()
+	_, err = agenttools.ChangeAgentTools(s.DataDir(), agentName, prevTools.Version)
+	c.Assert(err, gc.IsNil)
+	u := s.makeUpgrader(c)
+	err = upgrader.EnsureTools(u, origTools, utils.VerifySSLHostnames)
+	c.Assert(err, gc.IsNil)
+	c.Assert(u.Stop(), gc.IsNil)
+	_, err = agenttools.ChangeAgentTools(s.DataDir(), agentName, origTools.Version)
+	c.Assert(err, gc.IsNil)
+
+	// The desired version matches the previous tools, but no upgrade
+	// has been aborted, so the upgrader refuses to downgrade.
+	err = statetesting.SetAgentVersion(s.State, prevTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+	u = s.makeUpgrader(c)
+	c.Assert(u.Stop(), gc.IsNil)
+}
+
 type allowedTest struct {
 	current string
 	target  string